@@ -60,16 +60,63 @@ var (
 	carbonSeparatorByte  = byte('.')
 	carbonSeparatorBytes = []byte{carbonSeparatorByte}
 
+	// Used for parsing graphite tagged names, i.e. foo.bar;dc=us-east, into tags.
+	carbonTagSeparatorByte  = graphite.TaggedNameSeparator[0]
+	carbonTagValueSeparator = []byte(graphite.TagValueSeparator)
+	carbonReservedTagPrefix = []byte("__")
+	carbonReservedNameTag   = []byte(graphite.NameTag)
+	carbonTaggedPathTag     = []byte(graphite.TaggedPathTag)
+
 	errCannotGenerateTagsFromEmptyName = errors.New("cannot generate tags from empty name")
 	errIOptsMustBeSet                  = errors.New("carbon ingester options: instrument options must be st")
 	errWorkerPoolMustBeSet             = errors.New("carbon ingester options: worker pool must be set")
+	errInvalidProtocol                 = errors.New("carbon ingester options: invalid protocol")
+)
+
+// Protocol is a protocol carbon metrics are received with.
+type Protocol int
+
+const (
+	// PlaintextProtocol is the carbon plaintext line protocol over TCP.
+	PlaintextProtocol Protocol = iota
+	// PickleProtocol is the carbon pickle protocol over TCP, as used by
+	// carbon-relay to forward batches of metrics.
+	PickleProtocol
+	// UDPProtocol is the carbon plaintext line protocol over UDP, with any
+	// number of lines per datagram.
+	UDPProtocol
 )
 
+func (p Protocol) String() string {
+	switch p {
+	case PlaintextProtocol:
+		return "plaintext"
+	case PickleProtocol:
+		return "pickle"
+	case UDPProtocol:
+		return "udp"
+	default:
+		return "unknown"
+	}
+}
+
 // Options configures the ingester.
 type Options struct {
 	Debug             bool
 	InstrumentOptions instrument.Options
 	WorkerPool        xsync.PooledWorkerPool
+	// Protocol is the protocol the ingester receives metrics with, it
+	// determines how connections are scanned and tags the ingester metrics.
+	Protocol Protocol
+}
+
+// Ingester ingests carbon metrics from connections or UDP datagrams.
+type Ingester interface {
+	m3xserver.Handler
+
+	// HandlePacket ingests the carbon plaintext lines in a UDP datagram, the
+	// datagram may be reused once HandlePacket returns.
+	HandlePacket(packet []byte)
 }
 
 // CarbonIngesterRules contains the carbon ingestion rules.
@@ -87,6 +134,12 @@ func (o *Options) Validate() error {
 		return errWorkerPoolMustBeSet
 	}
 
+	switch o.Protocol {
+	case PlaintextProtocol, PickleProtocol, UDPProtocol:
+	default:
+		return errInvalidProtocol
+	}
+
 	return nil
 }
 
@@ -95,7 +148,7 @@ func NewIngester(
 	downsamplerAndWriter ingest.DownsamplerAndWriter,
 	rules CarbonIngesterRules,
 	opts Options,
-) (m3xserver.Handler, error) {
+) (Ingester, error) {
 	err := opts.Validate()
 	if err != nil {
 		return nil, err
@@ -133,7 +186,9 @@ func NewIngester(
 		logger:               opts.InstrumentOptions.Logger(),
 		tagOpts:              tagOpts,
 		metrics: newCarbonIngesterMetrics(
-			opts.InstrumentOptions.MetricsScope()),
+			opts.InstrumentOptions.MetricsScope().Tagged(map[string]string{
+				"protocol": opts.Protocol.String(),
+			})),
 
 		rules: compiledRules,
 
@@ -153,50 +208,99 @@ type ingester struct {
 	lineResourcesPool pool.ObjectPool
 }
 
+// metricScanner scans carbon metrics, it is implemented by the scanners of
+// each of the supported protocols.
+type metricScanner interface {
+	Scan() bool
+	Metric() ([]byte, time.Time, float64)
+	Err() error
+}
+
 func (i *ingester) Handle(conn net.Conn) {
 	var (
-		// Interfaces require a context be passed, but M3DB client already has timeouts
-		// built in and allocating a new context each time is expensive so we just pass
-		// the same context always and rely on M3DB client timeouts.
-		ctx    = context.Background()
 		wg     = sync.WaitGroup{}
-		s      = carbon.NewScanner(conn, i.opts.InstrumentOptions)
 		logger = i.opts.InstrumentOptions.Logger()
 	)
 
 	logger.Debug("handling new carbon ingestion connection")
-	for s.Scan() {
-		name, timestamp, value := s.Metric()
+	if i.opts.Protocol == PickleProtocol {
+		s := carbon.NewPickleScanner(conn, i.opts.InstrumentOptions)
+		i.ingestScanner(s, &s.MalformedCount, &wg)
+	} else {
+		s := carbon.NewScanner(conn, i.opts.InstrumentOptions)
+		i.ingestScanner(s, &s.MalformedCount, &wg)
+	}
 
-		resources := i.getLineResources()
-		// Copy name since scanner bytes are recycled.
-		resources.name = append(resources.name[:0], name...)
+	logger.Debugf("waiting for outstanding carbon ingestion writes to complete")
+	wg.Wait()
+	logger.Debugf("all outstanding writes completed, shutting down carbon ingestion handler")
 
-		wg.Add(1)
-		i.opts.WorkerPool.Go(func() {
-			ok := i.write(ctx, resources, timestamp, value)
-			if ok {
-				i.metrics.success.Inc(1)
-			}
-			// The contract is that after the DownsamplerAndWriter returns, any resources
-			// that it needed to hold onto have already been copied.
-			i.putLineResources(resources)
-			wg.Done()
-		})
+	// Don't close the connection, that is the server's responsibility.
+}
+
+func (i *ingester) HandlePacket(packet []byte) {
+	metrics, malformed := carbon.ParsePacket(packet)
+	i.metrics.malformed.Inc(int64(malformed))
+
+	// There is no connection to tie the writes to so don't wait for them, the
+	// names are copied before writing so the packet can still be reused.
+	for _, metric := range metrics {
+		i.ingestMetric(metric.Name, metric.Time, metric.Val, nil)
+	}
+}
 
-		i.metrics.malformed.Inc(int64(s.MalformedCount))
-		s.MalformedCount = 0
+func (i *ingester) ingestScanner(
+	s metricScanner,
+	malformedCount *int,
+	wg *sync.WaitGroup,
+) {
+	for s.Scan() {
+		name, timestamp, value := s.Metric()
+		i.ingestMetric(name, timestamp, value, wg)
+
+		i.metrics.malformed.Inc(int64(*malformedCount))
+		*malformedCount = 0
 	}
 
+	// Malformed metrics at the end of the input are not followed by a valid one.
+	i.metrics.malformed.Inc(int64(*malformedCount))
+	*malformedCount = 0
+
 	if err := s.Err(); err != nil {
-		logger.Errorf("encountered error during carbon ingestion when scanning connection: %s", err)
+		i.logger.Errorf("encountered error during carbon ingestion when scanning connection: %s", err)
 	}
+}
 
-	logger.Debugf("waiting for outstanding carbon ingestion writes to complete")
-	wg.Wait()
-	logger.Debugf("all outstanding writes completed, shutting down carbon ingestion handler")
+// ingestMetric schedules the write of a metric, the wait group (if any) is done
+// once the write has completed.
+func (i *ingester) ingestMetric(
+	name []byte,
+	timestamp time.Time,
+	value float64,
+	wg *sync.WaitGroup,
+) {
+	resources := i.getLineResources()
+	// Copy name since scanner and packet bytes are recycled.
+	resources.name = append(resources.name[:0], name...)
 
-	// Don't close the connection, that is the server's responsibility.
+	if wg != nil {
+		wg.Add(1)
+	}
+	i.opts.WorkerPool.Go(func() {
+		// Interfaces require a context be passed, but M3DB client already has timeouts
+		// built in and allocating a new context each time is expensive so we just pass
+		// the same context always and rely on M3DB client timeouts.
+		ok := i.write(context.Background(), resources, timestamp, value)
+		if ok {
+			i.metrics.success.Inc(1)
+		}
+		// The contract is that after the DownsamplerAndWriter returns, any resources
+		// that it needed to hold onto have already been copied.
+		i.putLineResources(resources)
+		if wg != nil {
+			wg.Done()
+		}
+	})
 }
 
 func (i *ingester) write(
@@ -215,7 +319,7 @@ func (i *ingester) write(
 	}
 
 	for _, rule := range i.rules {
-		if rule.rule.Pattern == graphite.MatchAllPattern || rule.regexp.Match(resources.name) {
+		if rule.match(resources.name) {
 			// Each rule should only have either mapping rules or storage policies so
 			// one of these should be a no-op.
 			downsampleAndStoragePolicies.DownsampleMappingRules = rule.mappingRules
@@ -291,6 +395,14 @@ type carbonIngesterMetrics struct {
 //      __g0__:foo
 //      __g1__:bar
 //      __g2__:baz
+// Graphite tagged names have their tags appended as is, along with their path
+// to tell them apart from untagged series, so that an input like:
+//      foo.bar;dc=us-east
+// becomes
+//      __g0__:foo
+//      __g1__:bar
+//      dc:us-east
+//      __gpath__:foo.bar
 func GenerateTagsFromName(
 	name []byte,
 	opts models.TagOptions,
@@ -313,11 +425,20 @@ func generateTagsFromName(
 	opts models.TagOptions,
 	tags []models.Tag,
 ) (models.Tags, error) {
-	if len(name) == 0 {
+	path, tagged := name, []byte(nil)
+	if idx := bytes.IndexByte(name, carbonTagSeparatorByte); idx >= 0 {
+		path, tagged = name[:idx], name[idx+1:]
+	}
+
+	if len(path) == 0 {
 		return models.EmptyTags(), errCannotGenerateTagsFromEmptyName
 	}
 
-	numTags := bytes.Count(name, carbonSeparatorBytes) + 1
+	numTags := bytes.Count(path, carbonSeparatorBytes) + 1
+	if tagged != nil {
+		// NB: account for the tagged path tag.
+		numTags += bytes.Count(tagged, []byte{carbonTagSeparatorByte}) + 2
+	}
 
 	if cap(tags) >= numTags {
 		tags = tags[:0]
@@ -327,16 +448,16 @@ func generateTagsFromName(
 
 	startIdx := 0
 	tagNum := 0
-	for i, charByte := range name {
+	for i, charByte := range path {
 		if charByte == carbonSeparatorByte {
-			if i+1 < len(name) && name[i+1] == carbonSeparatorByte {
+			if i+1 < len(path) && path[i+1] == carbonSeparatorByte {
 				return models.EmptyTags(),
 					fmt.Errorf("carbon metric: %s has duplicate separator", string(name))
 			}
 
 			tags = append(tags, models.Tag{
 				Name:  graphite.TagName(tagNum),
-				Value: name[startIdx:i],
+				Value: path[startIdx:i],
 			})
 			startIdx = i + 1
 			tagNum++
@@ -352,10 +473,29 @@ func generateTagsFromName(
 	// append baz, however, if the input was:
 	//      foo.bar.baz.
 	// then the foor loop would have appended foo, bar, and baz already.
-	if name[len(name)-1] != carbonSeparatorByte {
+	if path[len(path)-1] != carbonSeparatorByte {
 		tags = append(tags, models.Tag{
 			Name:  graphite.TagName(tagNum),
-			Value: name[startIdx:],
+			Value: path[startIdx:],
+		})
+	}
+
+	if tagged != nil {
+		for _, tag := range bytes.Split(tagged, []byte{carbonTagSeparatorByte}) {
+			parts := bytes.SplitN(tag, carbonTagValueSeparator, 2)
+			if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 ||
+				bytes.HasPrefix(parts[0], carbonReservedTagPrefix) ||
+				bytes.Equal(parts[0], carbonReservedNameTag) {
+				return models.EmptyTags(),
+					fmt.Errorf("carbon metric: %s has invalid tag: %s", string(name), string(tag))
+			}
+
+			tags = append(tags, models.Tag{Name: parts[0], Value: parts[1]})
+		}
+
+		tags = append(tags, models.Tag{
+			Name:  carbonTaggedPathTag,
+			Value: bytes.TrimSuffix(path, carbonSeparatorBytes),
 		})
 	}
 
@@ -390,6 +530,13 @@ func compileRules(rules CarbonIngesterRules) ([]ruleAndRegex, error) {
 			regexp: compiled,
 		}
 
+		if rule.AggregationPattern != "" {
+			compiledRule.aggregationRegexp, err = regexp.Compile(rule.AggregationPattern)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		if rule.Aggregation.EnabledOrDefault() {
 			compiledRule.mappingRules = []downsample.MappingRule{downsample.MappingRule{
 				Aggregations: []aggregation.Type{rule.Aggregation.TypeOrDefault()},
@@ -437,8 +584,19 @@ type lineResources struct {
 }
 
 type ruleAndRegex struct {
-	rule            config.CarbonIngesterRuleConfiguration
-	regexp          *regexp.Regexp
-	mappingRules    []downsample.MappingRule
-	storagePolicies []policy.StoragePolicy
+	rule              config.CarbonIngesterRuleConfiguration
+	regexp            *regexp.Regexp
+	aggregationRegexp *regexp.Regexp
+	mappingRules      []downsample.MappingRule
+	storagePolicies   []policy.StoragePolicy
+}
+
+func (r ruleAndRegex) match(name []byte) bool {
+	if r.rule.Pattern != graphite.MatchAllPattern && !r.regexp.Match(name) {
+		return false
+	}
+
+	return r.aggregationRegexp == nil ||
+		r.rule.AggregationPattern == graphite.MatchAllPattern ||
+		r.aggregationRegexp.Match(name)
 }
@@ -23,6 +23,7 @@ package ingestcarbon
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -47,6 +48,7 @@ import (
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/hydrogen18/stalecucumber"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -300,6 +302,85 @@ func TestIngesterHonorsPatterns(t *testing.T) {
 	}, found)
 }
 
+func TestIngesterHandlePickleConn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDownsamplerAndWriter, found := newCapturingDownsamplerAndWriter(ctrl)
+
+	batch := []interface{}{
+		[]interface{}{"foo.bar.baz", []interface{}{int64(1), float64(1)}},
+		[]interface{}{"foo..invalid", []interface{}{int64(2), float64(2)}},
+		[]interface{}{"foo.bar.qux", []interface{}{float64(3.5), int64(3)}},
+	}
+	var payload bytes.Buffer
+	_, err := stalecucumber.NewPickler(&payload).Pickle(batch)
+	require.NoError(t, err)
+
+	var packet bytes.Buffer
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(payload.Len()))
+	packet.Write(header)
+	packet.Write(payload.Bytes())
+
+	opts := testOptions
+	opts.Protocol = PickleProtocol
+	ingester, err := NewIngester(mockDownsamplerAndWriter, testRulesMatchAll, opts)
+	require.NoError(t, err)
+	ingester.Handle(&byteConn{b: &packet})
+
+	assertTestMetricsAreEqual(t, []testMetric{
+		{
+			metric:    []byte("foo.bar.baz"),
+			tags:      mustGenerateTagsFromName(t, []byte("foo.bar.baz")),
+			timestamp: 1,
+			value:     1,
+		},
+		{
+			metric:    []byte("foo.bar.qux"),
+			tags:      mustGenerateTagsFromName(t, []byte("foo.bar.qux")),
+			timestamp: 3,
+			value:     3,
+		},
+	}, found())
+}
+
+func TestIngesterHandlePacket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDownsamplerAndWriter, found := newCapturingDownsamplerAndWriter(ctrl)
+
+	opts := testOptions
+	opts.Protocol = UDPProtocol
+	ingester, err := NewIngester(mockDownsamplerAndWriter, testRulesMatchAll, opts)
+	require.NoError(t, err)
+
+	packet := []byte("foo.bar.baz 1 1\ngarbage\nfoo.bar.qux 2 2\n")
+	ingester.HandlePacket(packet)
+	// The packet may be reused once handled.
+	copy(packet, bytes.Repeat([]byte("x"), len(packet)))
+
+	expected := []testMetric{
+		{
+			metric:    []byte("foo.bar.baz"),
+			tags:      mustGenerateTagsFromName(t, []byte("foo.bar.baz")),
+			timestamp: 1,
+			value:     1,
+		},
+		{
+			metric:    []byte("foo.bar.qux"),
+			tags:      mustGenerateTagsFromName(t, []byte("foo.bar.qux")),
+			timestamp: 2,
+			value:     2,
+		},
+	}
+	waitForTestMetrics(t, len(expected), found)
+	assertTestMetricsAreEqual(t, expected, found())
+}
+
+func TestOptionsValidateProtocol(t *testing.T) {
+	opts := testOptions
+	opts.Protocol = Protocol(-1)
+	require.Equal(t, errInvalidProtocol, opts.Validate())
+}
+
 func TestGenerateTagsFromName(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -342,6 +423,42 @@ func TestGenerateTagsFromName(t *testing.T) {
 			expectedErr:  fmt.Errorf("carbon metric: foo.bar.baz.. has duplicate separator"),
 			expectedTags: []models.Tag{},
 		},
+		{
+			name: "foo.bar;host=a;dc=us-east",
+			id:   "foo.bar;dc=us-east;host=a",
+			expectedTags: []models.Tag{
+				{Name: graphite.TagName(0), Value: []byte("foo")},
+				{Name: graphite.TagName(1), Value: []byte("bar")},
+				{Name: []byte("host"), Value: []byte("a")},
+				{Name: []byte("dc"), Value: []byte("us-east")},
+				{Name: []byte("__gpath__"), Value: []byte("foo.bar")},
+			},
+		},
+		{
+			name:         ";dc=us-east",
+			expectedErr:  errCannotGenerateTagsFromEmptyName,
+			expectedTags: []models.Tag{},
+		},
+		{
+			name:         "foo;dc",
+			expectedErr:  fmt.Errorf("carbon metric: foo;dc has invalid tag: dc"),
+			expectedTags: []models.Tag{},
+		},
+		{
+			name:         "foo;dc=",
+			expectedErr:  fmt.Errorf("carbon metric: foo;dc= has invalid tag: dc="),
+			expectedTags: []models.Tag{},
+		},
+		{
+			name:         "foo;name=bar",
+			expectedErr:  fmt.Errorf("carbon metric: foo;name=bar has invalid tag: name=bar"),
+			expectedTags: []models.Tag{},
+		},
+		{
+			name:         "foo;__g1__=bar",
+			expectedErr:  fmt.Errorf("carbon metric: foo;__g1__=bar has invalid tag: __g1__=bar"),
+			expectedTags: []models.Tag{},
+		},
 	}
 
 	opts := models.NewTagOptions().SetIDSchemeType(models.TypeGraphite)
@@ -357,6 +474,48 @@ func TestGenerateTagsFromName(t *testing.T) {
 	}
 }
 
+// newCapturingDownsamplerAndWriter returns a mock that accepts all writes and a
+// function returning the metrics written so far.
+func newCapturingDownsamplerAndWriter(
+	ctrl *gomock.Controller,
+) (*ingest.MockDownsamplerAndWriter, func() []testMetric) {
+	var (
+		lock  sync.Mutex
+		found []testMetric
+	)
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().
+		Write(gomock.Any(), gomock.Any(), gomock.Any(), xtime.Second, gomock.Any()).DoAndReturn(func(
+		_ context.Context,
+		tags models.Tags,
+		dp ts.Datapoints,
+		unit xtime.Unit,
+		overrides ingest.WriteOptions,
+	) interface{} {
+		lock.Lock()
+		// Clone tags because they (and their underlying bytes) are pooled.
+		found = append(found, testMetric{
+			tags: tags.Clone(), timestamp: int(dp[0].Timestamp.Unix()), value: dp[0].Value})
+		lock.Unlock()
+		return nil
+	}).AnyTimes()
+
+	return mockDownsamplerAndWriter, func() []testMetric {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]testMetric(nil), found...)
+	}
+}
+
+// waitForTestMetrics waits for the writes that are not tied to a connection.
+func waitForTestMetrics(t *testing.T, n int, found func() []testMetric) {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(found()) < n {
+		require.True(t, time.Now().Before(deadline), "timed out waiting for writes")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // byteConn implements the net.Conn interface so that we can test the handler without
 // going over the network.
 type byteConn struct {
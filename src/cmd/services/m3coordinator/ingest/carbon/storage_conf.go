@@ -0,0 +1,398 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/query/graphite/graphite"
+)
+
+const (
+	storageConfPatternKey           = "pattern"
+	storageConfRetentionsKey        = "retentions"
+	storageConfXFilesFactorKey      = "xfilesfactor"
+	storageConfAggregationMethodKey = "aggregationmethod"
+)
+
+var (
+	defaultStorageConfAggregationMethod = aggregation.Mean
+
+	// storageConfAggregationMethods maps the graphite aggregation methods
+	// to the equivalent M3 aggregation types.
+	storageConfAggregationMethods = map[string]aggregation.Type{
+		"average": aggregation.Mean,
+		"sum":     aggregation.Sum,
+		"min":     aggregation.Min,
+		"max":     aggregation.Max,
+		"last":    aggregation.Last,
+	}
+
+	// storageConfUnits are the units of retention definitions in seconds, a
+	// unit is matched by any prefix of its name as done by whisper.
+	storageConfUnits = []struct {
+		name    string
+		seconds int64
+	}{
+		{name: "seconds", seconds: 1},
+		{name: "minutes", seconds: 60},
+		{name: "hours", seconds: 60 * 60},
+		{name: "days", seconds: 24 * 60 * 60},
+		{name: "weeks", seconds: 7 * 24 * 60 * 60},
+		{name: "years", seconds: 365 * 24 * 60 * 60},
+	}
+
+	storageConfRetentionPartRegex = regexp.MustCompile(`^(\d+)([a-z]*)$`)
+)
+
+// StorageSchema is a storage schema defined in a graphite storage-schemas.conf file.
+type StorageSchema struct {
+	Name       string
+	Pattern    string
+	Retentions []config.CarbonIngesterStoragePolicyConfiguration
+}
+
+// StorageAggregation is an aggregation defined in a graphite storage-aggregation.conf
+// file. M3 aggregates tiles that received any datapoints, which is the same as an
+// xFilesFactor of zero, so any other xFilesFactor is rejected.
+type StorageAggregation struct {
+	Name              string
+	Pattern           string
+	AggregationMethod aggregation.Type
+}
+
+// storageConfSection is a section of a graphite storage conf file.
+type storageConfSection struct {
+	name   string
+	values map[string]string
+}
+
+// parseStorageConf parses the sections of a graphite storage conf file, which
+// uses the python ConfigParser format.
+func parseStorageConf(r io.Reader) ([]storageConfSection, error) {
+	var (
+		sections []storageConfSection
+		scanner  = bufio.NewScanner(r)
+		lineNum  int
+	)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid section header: %s", lineNum, line)
+			}
+			sections = append(sections, storageConfSection{
+				name:   strings.TrimSpace(line[1 : len(line)-1]),
+				values: make(map[string]string),
+			})
+			continue
+		}
+
+		if len(sections) == 0 {
+			return nil, fmt.Errorf("line %d: value outside of a section: %s", lineNum, line)
+		}
+
+		idx := strings.IndexAny(line, "=:")
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %d: invalid value: %s", lineNum, line)
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:idx]))
+		sections[len(sections)-1].values[key] = strings.TrimSpace(line[idx+1:])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+func (s storageConfSection) pattern() (string, error) {
+	pattern, ok := s.values[storageConfPatternKey]
+	if !ok {
+		return "", fmt.Errorf("section %s: missing %s", s.name, storageConfPatternKey)
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", fmt.Errorf("section %s: invalid %s: %v", s.name, storageConfPatternKey, err)
+	}
+	return pattern, nil
+}
+
+// ParseStorageSchemas parses the storage schemas of a graphite storage-schemas.conf file.
+func ParseStorageSchemas(r io.Reader) ([]StorageSchema, error) {
+	sections, err := parseStorageConf(r)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]StorageSchema, 0, len(sections))
+	for _, section := range sections {
+		pattern, err := section.pattern()
+		if err != nil {
+			return nil, err
+		}
+
+		retentions, ok := section.values[storageConfRetentionsKey]
+		if !ok {
+			return nil, fmt.Errorf("section %s: missing %s", section.name, storageConfRetentionsKey)
+		}
+
+		schema := StorageSchema{Name: section.name, Pattern: pattern}
+		for _, def := range strings.Split(retentions, ",") {
+			policy, err := parseRetentionDef(def)
+			if err != nil {
+				return nil, fmt.Errorf("section %s: %v", section.name, err)
+			}
+			schema.Retentions = append(schema.Retentions, policy)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, nil
+}
+
+// parseRetentionDef parses a whisper retention definition such as 10s:6h or
+// 60:1440, where the retention is either a duration or a number of points.
+func parseRetentionDef(def string) (config.CarbonIngesterStoragePolicyConfiguration, error) {
+	parts := strings.Split(strings.TrimSpace(def), ":")
+	if len(parts) != 2 {
+		return config.CarbonIngesterStoragePolicyConfiguration{},
+			fmt.Errorf("invalid retention definition: %s", def)
+	}
+
+	precision, _, err := parseRetentionPart(parts[0])
+	if err != nil || precision <= 0 {
+		return config.CarbonIngesterStoragePolicyConfiguration{},
+			fmt.Errorf("invalid precision in retention definition: %s", def)
+	}
+
+	retention, isPoints, err := parseRetentionPart(parts[1])
+	if err != nil || retention <= 0 {
+		return config.CarbonIngesterStoragePolicyConfiguration{},
+			fmt.Errorf("invalid retention in retention definition: %s", def)
+	}
+	if isPoints {
+		retention *= precision
+	}
+
+	return config.CarbonIngesterStoragePolicyConfiguration{
+		Resolution: time.Duration(precision) * time.Second,
+		Retention:  time.Duration(retention) * time.Second,
+	}, nil
+}
+
+// parseRetentionPart parses a part of a retention definition into seconds, or a
+// plain number if there is no unit.
+func parseRetentionPart(part string) (int64, bool, error) {
+	matches := storageConfRetentionPartRegex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(part)))
+	if matches == nil {
+		return 0, false, fmt.Errorf("invalid retention part: %s", part)
+	}
+
+	value, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+
+	unit := matches[2]
+	if unit == "" {
+		return value, true, nil
+	}
+
+	for _, u := range storageConfUnits {
+		if strings.HasPrefix(u.name, unit) {
+			return value * u.seconds, false, nil
+		}
+	}
+
+	return 0, false, fmt.Errorf("invalid retention unit: %s", unit)
+}
+
+// ParseStorageAggregations parses the aggregations of a graphite storage-aggregation.conf file.
+func ParseStorageAggregations(r io.Reader) ([]StorageAggregation, error) {
+	sections, err := parseStorageConf(r)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregations := make([]StorageAggregation, 0, len(sections))
+	for _, section := range sections {
+		pattern, err := section.pattern()
+		if err != nil {
+			return nil, err
+		}
+
+		agg := StorageAggregation{
+			Name:              section.name,
+			Pattern:           pattern,
+			AggregationMethod: defaultStorageConfAggregationMethod,
+		}
+
+		if value, ok := section.values[storageConfXFilesFactorKey]; ok {
+			xFilesFactor, err := strconv.ParseFloat(value, 64)
+			if err != nil || xFilesFactor < 0 || xFilesFactor > 1 {
+				return nil, fmt.Errorf("section %s: invalid xFilesFactor: %s", section.name, value)
+			}
+			if xFilesFactor != 0 {
+				return nil, fmt.Errorf("section %s: unsupported xFilesFactor: %s, M3 aggregates "+
+					"every tile that received any datapoints so only 0 is supported",
+					section.name, value)
+			}
+		}
+
+		if value, ok := section.values[storageConfAggregationMethodKey]; ok {
+			agg.AggregationMethod, ok = storageConfAggregationMethods[strings.ToLower(value)]
+			if !ok {
+				return nil, fmt.Errorf("section %s: unsupported aggregationMethod: %s", section.name, value)
+			}
+		}
+
+		aggregations = append(aggregations, agg)
+	}
+
+	return aggregations, nil
+}
+
+// RulesFromStorageConf converts graphite storage schemas and aggregations into
+// carbon ingestion rules. Graphite matches the schema and the aggregation of a
+// metric independently, so a rule is generated for each pair of schema and
+// aggregation, ordered such that the first matching rule is the one with the
+// first matching schema and the first matching aggregation. Metrics that match
+// a schema but no aggregation are aggregated using the graphite default.
+func RulesFromStorageConf(
+	schemas []StorageSchema,
+	aggregations []StorageAggregation,
+) []config.CarbonIngesterRuleConfiguration {
+	var rules []config.CarbonIngesterRuleConfiguration
+	for _, schema := range schemas {
+		matchesAll := false
+		for _, agg := range aggregations {
+			rules = append(rules, newStorageConfRule(schema, agg.Pattern, agg.AggregationMethod))
+			if agg.Pattern == graphite.MatchAllPattern {
+				// Nothing after this aggregation can match.
+				matchesAll = true
+				break
+			}
+		}
+
+		if !matchesAll {
+			rules = append(rules, newStorageConfRule(schema, "", defaultStorageConfAggregationMethod))
+		}
+	}
+
+	return rules
+}
+
+func newStorageConfRule(
+	schema StorageSchema,
+	aggregationPattern string,
+	aggregationType aggregation.Type,
+) config.CarbonIngesterRuleConfiguration {
+	var (
+		enabled  = true
+		policies = make([]config.CarbonIngesterStoragePolicyConfiguration, len(schema.Retentions))
+	)
+	copy(policies, schema.Retentions)
+	return config.CarbonIngesterRuleConfiguration{
+		Pattern:            schema.Pattern,
+		AggregationPattern: aggregationPattern,
+		Aggregation: config.CarbonIngesterAggregationConfiguration{
+			Enabled: &enabled,
+			Type:    &aggregationType,
+		},
+		Policies: policies,
+	}
+}
+
+// LoadStorageConfRules loads carbon ingestion rules from a graphite storage-schemas.conf
+// file and, optionally, a storage-aggregation.conf file.
+func LoadStorageConfRules(
+	schemasFile string,
+	aggregationFile string,
+) ([]config.CarbonIngesterRuleConfiguration, error) {
+	f, err := os.Open(schemasFile)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := ParseStorageSchemas(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse storage schemas file %s: %v", schemasFile, err)
+	}
+
+	var aggregations []StorageAggregation
+	if aggregationFile != "" {
+		f, err := os.Open(aggregationFile)
+		if err != nil {
+			return nil, err
+		}
+		aggregations, err = ParseStorageAggregations(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse storage aggregation file %s: %v", aggregationFile, err)
+		}
+	}
+
+	return RulesFromStorageConf(schemas, aggregations), nil
+}
+
+// RequiredStoragePolicies returns the distinct storage policies of the rules,
+// each of which requires an aggregated namespace, sorted by resolution and
+// then retention.
+func RequiredStoragePolicies(
+	rules []config.CarbonIngesterRuleConfiguration,
+) []config.CarbonIngesterStoragePolicyConfiguration {
+	var (
+		seen     = make(map[config.CarbonIngesterStoragePolicyConfiguration]struct{})
+		policies []config.CarbonIngesterStoragePolicyConfiguration
+	)
+	for _, rule := range rules {
+		for _, policy := range rule.Policies {
+			if _, ok := seen[policy]; ok {
+				continue
+			}
+			seen[policy] = struct{}{}
+			policies = append(policies, policy)
+		}
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Resolution == policies[j].Resolution {
+			return policies[i].Retention < policies[j].Retention
+		}
+		return policies[i].Resolution < policies[j].Resolution
+	})
+	return policies
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/metrics/aggregation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testStorageSchemas = `
+# Schema definitions for Whisper files. Entries are scanned in order,
+# and first match wins.
+[carbon]
+pattern = ^carbon\.
+retentions = 60:90d
+
+[stats]
+pattern = ^stats\.
+retentions = 10s:6h,1min:7d,10min:5y
+
+[default_1min_for_1day]
+pattern = .*
+retentions = 60s:1d
+`
+
+	testStorageAggregation = `
+[min]
+pattern = \.min$
+xFilesFactor = 0
+aggregationMethod = min
+
+[count]
+pattern = \.count$
+xFilesFactor = 0
+aggregationMethod = sum
+
+; Average everything else.
+[default_average]
+pattern = .*
+xFilesFactor = 0
+aggregationMethod = average
+`
+)
+
+func TestParseStorageSchemas(t *testing.T) {
+	schemas, err := ParseStorageSchemas(strings.NewReader(testStorageSchemas))
+	require.NoError(t, err)
+	require.Equal(t, []StorageSchema{
+		{
+			Name:    "carbon",
+			Pattern: `^carbon\.`,
+			Retentions: []config.CarbonIngesterStoragePolicyConfiguration{
+				{Resolution: time.Minute, Retention: 90 * 24 * time.Hour},
+			},
+		},
+		{
+			Name:    "stats",
+			Pattern: `^stats\.`,
+			Retentions: []config.CarbonIngesterStoragePolicyConfiguration{
+				{Resolution: 10 * time.Second, Retention: 6 * time.Hour},
+				{Resolution: time.Minute, Retention: 7 * 24 * time.Hour},
+				{Resolution: 10 * time.Minute, Retention: 5 * 365 * 24 * time.Hour},
+			},
+		},
+		{
+			Name:    "default_1min_for_1day",
+			Pattern: ".*",
+			Retentions: []config.CarbonIngesterStoragePolicyConfiguration{
+				{Resolution: time.Minute, Retention: 24 * time.Hour},
+			},
+		},
+	}, schemas)
+}
+
+func TestParseRetentionDef(t *testing.T) {
+	tests := []struct {
+		def        string
+		resolution time.Duration
+		retention  time.Duration
+	}{
+		{def: "60:1440", resolution: time.Minute, retention: 24 * time.Hour},
+		{def: "10s:6h", resolution: 10 * time.Second, retention: 6 * time.Hour},
+		{def: "1m:2w", resolution: time.Minute, retention: 14 * 24 * time.Hour},
+		{def: "1h:100", resolution: time.Hour, retention: 100 * time.Hour},
+		{def: " 5min:1y ", resolution: 5 * time.Minute, retention: 365 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.def, func(t *testing.T) {
+			policy, err := parseRetentionDef(tt.def)
+			require.NoError(t, err)
+			assert.Equal(t, tt.resolution, policy.Resolution)
+			assert.Equal(t, tt.retention, policy.Retention)
+		})
+	}
+
+	for _, def := range []string{"", "60", "0:10", "1x:1d", "1s:-1", "a:b", "1s:1d:1y"} {
+		_, err := parseRetentionDef(def)
+		assert.Error(t, err, def)
+	}
+}
+
+func TestParseStorageSchemasErrors(t *testing.T) {
+	for _, conf := range []string{
+		"pattern = .*",
+		"[missing_pattern]\nretentions = 60:1440",
+		"[missing_retentions]\npattern = .*",
+		"[invalid_pattern]\npattern = (\nretentions = 60:1440",
+		"[invalid_header\npattern = .*",
+		"[invalid_value]\npattern",
+	} {
+		_, err := ParseStorageSchemas(strings.NewReader(conf))
+		assert.Error(t, err, conf)
+	}
+}
+
+func TestParseStorageAggregations(t *testing.T) {
+	aggregations, err := ParseStorageAggregations(strings.NewReader(testStorageAggregation))
+	require.NoError(t, err)
+	require.Equal(t, []StorageAggregation{
+		{Name: "min", Pattern: `\.min$`, AggregationMethod: aggregation.Min},
+		{Name: "count", Pattern: `\.count$`, AggregationMethod: aggregation.Sum},
+		{Name: "default_average", Pattern: ".*", AggregationMethod: aggregation.Mean},
+	}, aggregations)
+
+	aggregations, err = ParseStorageAggregations(strings.NewReader("[defaults]\npattern = foo"))
+	require.NoError(t, err)
+	require.Equal(t, []StorageAggregation{
+		{Name: "defaults", Pattern: "foo", AggregationMethod: aggregation.Mean},
+	}, aggregations)
+
+	for _, conf := range []string{
+		"[invalid_xff]\npattern = .*\nxFilesFactor = 2",
+		"[unsupported_xff]\npattern = .*\nxFilesFactor = 0.1",
+		"[graphite_default_xff]\npattern = .*\nxFilesFactor = 0.5",
+		"[unsupported_method]\npattern = .*\naggregationMethod = avg_zero",
+	} {
+		_, err := ParseStorageAggregations(strings.NewReader(conf))
+		assert.Error(t, err, conf)
+	}
+}
+
+func TestRulesFromStorageConf(t *testing.T) {
+	schemas, err := ParseStorageSchemas(strings.NewReader(testStorageSchemas))
+	require.NoError(t, err)
+	aggregations, err := ParseStorageAggregations(strings.NewReader(testStorageAggregation))
+	require.NoError(t, err)
+
+	rules := RulesFromStorageConf(schemas, aggregations)
+	// Each schema is paired with each aggregation, the match all aggregation
+	// makes the graphite default redundant.
+	require.Equal(t, 9, len(rules))
+	for i, rule := range rules {
+		schema, agg := schemas[i/3], aggregations[i%3]
+		assert.Equal(t, schema.Pattern, rule.Pattern)
+		assert.Equal(t, agg.Pattern, rule.AggregationPattern)
+		assert.Equal(t, agg.AggregationMethod, rule.Aggregation.TypeOrDefault())
+		assert.True(t, rule.Aggregation.EnabledOrDefault())
+		assert.Equal(t, schema.Retentions, rule.Policies)
+	}
+
+	// Without a match all aggregation the graphite default is appended.
+	rules = RulesFromStorageConf(schemas[:1], aggregations[:1])
+	require.Equal(t, 2, len(rules))
+	assert.Equal(t, `\.min$`, rules[0].AggregationPattern)
+	assert.Equal(t, aggregation.Min, rules[0].Aggregation.TypeOrDefault())
+	assert.Equal(t, "", rules[1].AggregationPattern)
+	assert.Equal(t, aggregation.Mean, rules[1].Aggregation.TypeOrDefault())
+
+	assert.Equal(t, []config.CarbonIngesterStoragePolicyConfiguration{
+		{Resolution: 10 * time.Second, Retention: 6 * time.Hour},
+		{Resolution: time.Minute, Retention: 24 * time.Hour},
+		{Resolution: time.Minute, Retention: 7 * 24 * time.Hour},
+		{Resolution: time.Minute, Retention: 90 * 24 * time.Hour},
+		{Resolution: 10 * time.Minute, Retention: 5 * 365 * 24 * time.Hour},
+	}, RequiredStoragePolicies(RulesFromStorageConf(schemas, aggregations)))
+}
+
+func TestRuleMatchesAggregationPattern(t *testing.T) {
+	schemas, err := ParseStorageSchemas(strings.NewReader(testStorageSchemas))
+	require.NoError(t, err)
+	aggregations, err := ParseStorageAggregations(strings.NewReader(testStorageAggregation))
+	require.NoError(t, err)
+
+	compiled, err := compileRules(CarbonIngesterRules{
+		Rules: RulesFromStorageConf(schemas, aggregations),
+	})
+	require.NoError(t, err)
+
+	firstMatch := func(name string) config.CarbonIngesterRuleConfiguration {
+		for _, rule := range compiled {
+			if rule.match([]byte(name)) {
+				return rule.rule
+			}
+		}
+		require.FailNow(t, "no rule matched: "+name)
+		return config.CarbonIngesterRuleConfiguration{}
+	}
+
+	rule := firstMatch("stats.foo.count")
+	assert.Equal(t, `^stats\.`, rule.Pattern)
+	assert.Equal(t, aggregation.Sum, rule.Aggregation.TypeOrDefault())
+
+	rule = firstMatch("carbon.agents.min")
+	assert.Equal(t, `^carbon\.`, rule.Pattern)
+	assert.Equal(t, aggregation.Min, rule.Aggregation.TypeOrDefault())
+
+	rule = firstMatch("foo.bar")
+	assert.Equal(t, ".*", rule.Pattern)
+	assert.Equal(t, aggregation.Mean, rule.Aggregation.TypeOrDefault())
+}
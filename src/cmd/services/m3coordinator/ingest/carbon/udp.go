@@ -0,0 +1,136 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"net"
+	"sync"
+
+	"github.com/m3db/m3x/instrument"
+	"github.com/m3db/m3x/log"
+)
+
+const (
+	udpNetwork = "udp"
+
+	// maxUDPPacketSize is the largest possible UDP datagram payload.
+	maxUDPPacketSize = 65535
+)
+
+// UDPServer is a server that ingests carbon plaintext metrics from the UDP
+// datagrams received on an address.
+type UDPServer struct {
+	sync.Mutex
+
+	address  string
+	ingester Ingester
+	logger   log.Logger
+
+	conn   net.PacketConn
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewUDPServer returns a new UDP server for an ingester, the ingester should
+// have been created with the UDPProtocol.
+func NewUDPServer(
+	address string,
+	ingester Ingester,
+	iOpts instrument.Options,
+) *UDPServer {
+	return &UDPServer{
+		address:  address,
+		ingester: ingester,
+		logger:   iOpts.Logger(),
+	}
+}
+
+// ListenAndServe listens on the server address and serves datagrams in the
+// background.
+func (s *UDPServer) ListenAndServe() error {
+	conn, err := net.ListenPacket(udpNetwork, s.address)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(conn)
+}
+
+// Serve serves the datagrams received on a connection in the background, the
+// connection is closed when the server is closed.
+func (s *UDPServer) Serve(conn net.PacketConn) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return conn.Close()
+	}
+
+	s.conn = conn
+	s.wg.Add(1)
+	go s.serve(conn)
+	return nil
+}
+
+func (s *UDPServer) serve(conn net.PacketConn) {
+	defer s.wg.Done()
+
+	buf := make([]byte, maxUDPPacketSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if s.isClosed() {
+				return
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				s.logger.Errorf("temporary error reading carbon UDP datagram: %v", err)
+				continue
+			}
+			s.logger.Errorf("could not read carbon UDP datagram, stopping: %v", err)
+			return
+		}
+
+		s.ingester.HandlePacket(buf[:n])
+	}
+}
+
+func (s *UDPServer) isClosed() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.closed
+}
+
+// Close closes the server connection and the ingester.
+func (s *UDPServer) Close() {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return
+	}
+	s.closed = true
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.Unlock()
+
+	s.wg.Wait()
+	s.ingester.Close()
+}
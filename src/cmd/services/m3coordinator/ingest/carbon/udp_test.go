@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"net"
+	"testing"
+
+	"github.com/m3db/m3x/instrument"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUDPServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDownsamplerAndWriter, found := newCapturingDownsamplerAndWriter(ctrl)
+
+	opts := testOptions
+	opts.Protocol = UDPProtocol
+	ingester, err := NewIngester(mockDownsamplerAndWriter, testRulesMatchAll, opts)
+	require.NoError(t, err)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewUDPServer(conn.LocalAddr().String(), ingester, instrument.NewOptions())
+	require.NoError(t, server.Serve(conn))
+	defer server.Close()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("foo.bar.baz 1 1\nfoo.bar.qux 2 2"))
+	require.NoError(t, err)
+	_, err = client.Write([]byte("foo.bar.quz 3 3\n"))
+	require.NoError(t, err)
+
+	expected := []testMetric{
+		{
+			metric:    []byte("foo.bar.baz"),
+			tags:      mustGenerateTagsFromName(t, []byte("foo.bar.baz")),
+			timestamp: 1,
+			value:     1,
+		},
+		{
+			metric:    []byte("foo.bar.qux"),
+			tags:      mustGenerateTagsFromName(t, []byte("foo.bar.qux")),
+			timestamp: 2,
+			value:     2,
+		},
+		{
+			metric:    []byte("foo.bar.quz"),
+			tags:      mustGenerateTagsFromName(t, []byte("foo.bar.quz")),
+			timestamp: 3,
+			value:     3,
+		},
+	}
+	waitForTestMetrics(t, len(expected), found)
+	assertTestMetricsAreEqual(t, expected, found())
+}
+
+func TestUDPServerClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDownsamplerAndWriter, _ := newCapturingDownsamplerAndWriter(ctrl)
+
+	opts := testOptions
+	opts.Protocol = UDPProtocol
+	ingester, err := NewIngester(mockDownsamplerAndWriter, testRulesMatchAll, opts)
+	require.NoError(t, err)
+
+	server := NewUDPServer("127.0.0.1:0", ingester, instrument.NewOptions())
+	require.NoError(t, server.ListenAndServe())
+	server.Close()
+	// Closing is idempotent.
+	server.Close()
+}
@@ -107,7 +107,8 @@ func (bsc BootstrapConfiguration) New(
 		SetInstrumentOptions(opts.InstrumentOptions()).
 		SetDatabaseBlockOptions(opts.DatabaseBlockOptions()).
 		SetSeriesCachePolicy(opts.SeriesCachePolicy()).
-		SetIndexMutableSegmentAllocator(mutableSegmentAllocator)
+		SetIndexMutableSegmentAllocator(mutableSegmentAllocator).
+		SetProgress(opts.BootstrapProgress())
 
 	fsOpts := opts.CommitLogOptions().FilesystemOptions()
 
@@ -152,7 +153,8 @@ func (bsc BootstrapConfiguration) New(
 				SetAdminClient(adminClient).
 				SetPersistManager(opts.PersistManager()).
 				SetDatabaseBlockRetrieverManager(opts.DatabaseBlockRetrieverManager()).
-				SetRuntimeOptionsManager(opts.RuntimeOptionsManager())
+				SetRuntimeOptionsManager(opts.RuntimeOptionsManager()).
+				SetFilesystemOptions(fsOpts)
 			bs, err = peers.NewPeersBootstrapperProvider(pOpts, bs)
 			if err != nil {
 				return nil, err
@@ -126,6 +126,9 @@ type DBConfiguration struct {
 	// The repair policy for repairing in-memory data.
 	Repair *RepairPolicy `yaml:"repair"`
 
+	// The replication policy for asynchronously replicating to a remote cluster.
+	Replication *ReplicationPolicy `yaml:"replication"`
+
 	// The pooling policy.
 	PoolingPolicy PoolingPolicy `yaml:"pooling"`
 
@@ -137,6 +140,10 @@ type DBConfiguration struct {
 
 	// Write new series asynchronously for fast ingestion of new ID bursts.
 	WriteNewSeriesAsync bool `yaml:"writeNewSeriesAsync"`
+
+	// The max size in bytes compressed write batches may decompress to, omit
+	// this to use the default of 64MiB.
+	MaxWriteBatchDecodedBytes int `yaml:"maxWriteBatchDecodedBytes" validate:"min=0"`
 }
 
 // InitDefaultsAndValidate initializes all default values and validates the Configuration.
@@ -262,6 +269,37 @@ type RepairPolicy struct {
 	CheckInterval time.Duration `yaml:"checkInterval" validate:"nonzero"`
 }
 
+// ReplicationPolicy is the replication policy.
+type ReplicationPolicy struct {
+	// Enabled or disabled.
+	Enabled bool `yaml:"enabled"`
+
+	// The client configuration for the remote cluster.
+	Client client.Configuration `yaml:"client"`
+
+	// The directory sealed commit log files are buffered in until they are
+	// replicated, must be on the same filesystem as the commit log directory.
+	BufferDirectory string `yaml:"bufferDirectory" validate:"nonzero"`
+
+	// The maximum number of commit log files to buffer.
+	MaxBufferedFiles int `yaml:"maxBufferedFiles" validate:"min=0"`
+
+	// The interval at which to scan for sealed commit log files.
+	ScanInterval time.Duration `yaml:"scanInterval" validate:"min=0"`
+
+	// The size of the queue of writes pending to the remote cluster.
+	QueueSize int `yaml:"queueSize" validate:"min=0"`
+
+	// The number of concurrent writers to the remote cluster.
+	WriteConcurrency int `yaml:"writeConcurrency" validate:"min=0"`
+
+	// The interval at which flushed blocks are reconciled with the remote cluster.
+	ReconcileInterval time.Duration `yaml:"reconcileInterval" validate:"min=0"`
+
+	// How far back from the most recently flushed block to reconcile.
+	ReconcileLookback time.Duration `yaml:"reconcileLookback" validate:"min=0"`
+}
+
 // HashingConfiguration is the configuration for hashing.
 type HashingConfiguration struct {
 	// Murmur32 seed value.
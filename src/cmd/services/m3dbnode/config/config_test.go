@@ -370,6 +370,8 @@ func TestConfiguration(t *testing.T) {
     backgroundHealthCheckFailThrottleFactor: 0.5
     hashing:
       seed: 42
+    peerBootstrap: null
+    writeBatching: null
   gcPercentage: 100
   writeNewSeriesLimitPerSecond: 1048576
   writeNewSeriesBackoffDuration: 2ms
@@ -418,6 +420,7 @@ func TestConfiguration(t *testing.T) {
     jitter: 1h0m0s
     throttle: 2m0s
     checkInterval: 1m0s
+  replication: null
   pooling:
     blockAllocSize: 16
     type: simple
@@ -606,6 +609,7 @@ func TestConfiguration(t *testing.T) {
   hashing:
     seed: 42
   writeNewSeriesAsync: true
+  maxWriteBatchDecodedBytes: 0
 coordinator: null
 `
 
@@ -30,10 +30,14 @@ import (
 	ingestm3msg "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/m3msg"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/server/m3msg"
 	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/query/accounting"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/rules"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/m3"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 	"github.com/m3db/m3/src/x/cost"
 	xdocs "github.com/m3db/m3/src/x/docs"
 	xconfig "github.com/m3db/m3x/config"
@@ -56,6 +60,12 @@ const (
 		"More information is available here: %s"
 
 	defaultQueryConversionCacheSize = 4096
+
+	defaultGraphiteFindCacheTTL      = 10 * time.Second
+	defaultGraphiteFindCacheMaxBytes = 16 * 1024 * 1024
+
+	// Same as the InfluxDB default max-body-size.
+	defaultInfluxDBMaxBodyBytes = 25000000
 )
 
 var (
@@ -115,9 +125,23 @@ type Configuration struct {
 	// Carbon is the carbon configuration.
 	Carbon *CarbonConfiguration `yaml:"carbon"`
 
+	// InfluxDB is the InfluxDB line protocol write endpoint configuration.
+	InfluxDB InfluxDBConfiguration `yaml:"influxdb"`
+
+	// Rules configures the evaluation of recording and alerting rules.
+	Rules *rules.Configuration `yaml:"rules"`
+
+	// TimeSharding configures concurrent time sharded fetches for long
+	// range queries.
+	TimeSharding *timeshard.Configuration `yaml:"timeSharding"`
+
 	// Limits specifies limits on per-query resource usage.
 	Limits LimitsConfiguration `yaml:"limits"`
 
+	// Accounting configures the slow query log and per caller query
+	// accounting and limits.
+	Accounting *accounting.Configuration `yaml:"accounting"`
+
 	// LookbackDuration determines the lookback duration for queries
 	LookbackDuration *time.Duration `yaml:"lookbackDuration"`
 
@@ -160,6 +184,12 @@ type ResultOptions struct {
 type CacheConfiguration struct {
 	// QueryConversion cache policy.
 	QueryConversion *QueryConversionCacheConfiguration `yaml:"queryConversion"`
+
+	// Results configures caching of range query results.
+	Results *cache.ResultsConfiguration `yaml:"results"`
+
+	// GraphiteFind configures caching of graphite find results.
+	GraphiteFind *GraphiteFindCacheConfiguration `yaml:"graphiteFind"`
 }
 
 // QueryConversionCacheConfiguration is the query conversion cache configuration.
@@ -196,6 +226,59 @@ func (q *QueryConversionCacheConfiguration) Validate() error {
 	return nil
 }
 
+// GraphiteFindCacheConfiguration is the graphite find results cache
+// configuration.
+type GraphiteFindCacheConfiguration struct {
+	// TTL is how long find results are cached, zero disables caching.
+	TTL *time.Duration `yaml:"ttl"`
+
+	// MaxBytes is the maximum size of the cached find results.
+	MaxBytes *int `yaml:"maxBytes"`
+}
+
+// GraphiteFindCacheConfiguration returns the graphite find cache
+// configuration or default if none is specified.
+func (c CacheConfiguration) GraphiteFindCacheConfiguration() GraphiteFindCacheConfiguration {
+	if c.GraphiteFind == nil {
+		return GraphiteFindCacheConfiguration{}
+	}
+
+	return *c.GraphiteFind
+}
+
+// TTLOrDefault returns the provided ttl or the default value if none is
+// provided.
+func (c *GraphiteFindCacheConfiguration) TTLOrDefault() time.Duration {
+	if c.TTL == nil {
+		return defaultGraphiteFindCacheTTL
+	}
+
+	return *c.TTL
+}
+
+// MaxBytesOrDefault returns the provided max size or the default value if
+// none is provided.
+func (c *GraphiteFindCacheConfiguration) MaxBytesOrDefault() int {
+	if c.MaxBytes == nil {
+		return defaultGraphiteFindCacheMaxBytes
+	}
+
+	return *c.MaxBytes
+}
+
+// Validate validates the GraphiteFindCacheConfiguration settings.
+func (c *GraphiteFindCacheConfiguration) Validate() error {
+	if c.TTL != nil && *c.TTL < 0 {
+		return fmt.Errorf("must provide a non-negative ttl for graphite find cache config, instead got: %v", *c.TTL)
+	}
+
+	if c.MaxBytes != nil && *c.MaxBytes <= 0 {
+		return fmt.Errorf("must provide a positive max bytes for graphite find cache config, instead got: %d", *c.MaxBytes)
+	}
+
+	return nil
+}
+
 // LimitsConfiguration represents limitations on resource usage in the query instance. Limits are split between per-query
 // and global limits.
 type LimitsConfiguration struct {
@@ -275,12 +358,42 @@ type CarbonConfiguration struct {
 	Ingester *CarbonIngesterConfiguration `yaml:"ingester"`
 }
 
+// InfluxDBConfiguration is the configuration for the InfluxDB line protocol
+// write endpoint.
+type InfluxDBConfiguration struct {
+	// MaxBodyBytes is the maximum size of a write request body after
+	// decompression, larger requests are rejected.
+	MaxBodyBytes *int64 `yaml:"maxBodyBytes"`
+}
+
+// MaxBodyBytesOrDefault returns the provided max body bytes or the default
+// value if none is provided.
+func (c InfluxDBConfiguration) MaxBodyBytesOrDefault() int64 {
+	if c.MaxBodyBytes == nil {
+		return defaultInfluxDBMaxBodyBytes
+	}
+
+	return *c.MaxBodyBytes
+}
+
 // CarbonIngesterConfiguration is the configuration struct for carbon ingestion.
 type CarbonIngesterConfiguration struct {
-	Debug          bool                              `yaml:"debug"`
-	ListenAddress  string                            `yaml:"listenAddress"`
-	MaxConcurrency int                               `yaml:"maxConcurrency"`
-	Rules          []CarbonIngesterRuleConfiguration `yaml:"rules"`
+	Debug         bool   `yaml:"debug"`
+	ListenAddress string `yaml:"listenAddress"`
+	// PickleListenAddress enables the carbon pickle protocol, as used by
+	// carbon-relay, on the given address when set.
+	PickleListenAddress string `yaml:"pickleListenAddress"`
+	// UDPListenAddress enables the carbon plaintext protocol over UDP on the
+	// given address when set.
+	UDPListenAddress string                            `yaml:"udpListenAddress"`
+	MaxConcurrency   int                               `yaml:"maxConcurrency"`
+	Rules            []CarbonIngesterRuleConfiguration `yaml:"rules"`
+	// StorageSchemasFile is a graphite storage-schemas.conf file to import
+	// rules from, they are applied after any rules defined in the config.
+	StorageSchemasFile string `yaml:"storageSchemasFile"`
+	// StorageAggregationFile is an optional graphite storage-aggregation.conf
+	// file that determines the aggregation of the imported rules.
+	StorageAggregationFile string `yaml:"storageAggregationFile"`
 }
 
 // LookbackDurationOrDefault validates the LookbackDuration
@@ -351,9 +464,13 @@ func (c *CarbonIngesterConfiguration) RulesOrDefault(namespaces m3.ClusterNamesp
 // CarbonIngesterRuleConfiguration is the configuration struct for a carbon
 // ingestion rule.
 type CarbonIngesterRuleConfiguration struct {
-	Pattern     string                                     `yaml:"pattern"`
-	Aggregation CarbonIngesterAggregationConfiguration     `yaml:"aggregation"`
-	Policies    []CarbonIngesterStoragePolicyConfiguration `yaml:"policies"`
+	Pattern string `yaml:"pattern"`
+	// AggregationPattern, if set, must also be matched for the rule to apply. It
+	// allows matching the aggregation independently of the storage policies,
+	// like graphite storage-aggregation.conf does.
+	AggregationPattern string                                     `yaml:"aggregationPattern"`
+	Aggregation        CarbonIngesterAggregationConfiguration     `yaml:"aggregation"`
+	Policies           []CarbonIngesterStoragePolicyConfiguration `yaml:"policies"`
 }
 
 // CarbonIngesterAggregationConfiguration is the configuration struct
@@ -0,0 +1,214 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package importer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/tools/whisper_import/whisper"
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// filesetKey identifies the fileset a series block is written to.
+type filesetKey struct {
+	namespace  string
+	shard      uint32
+	blockStart xtime.UnixNano
+}
+
+// filesetSeries is a series block encoded and ready to be written to a
+// fileset, it is kept compressed so that large whisper trees can be imported
+// without holding every datapoint in memory.
+type filesetSeries struct {
+	id       ident.ID
+	tags     ident.Tags
+	data     []byte
+	checksum uint32
+}
+
+// filesetBuilder accumulates the series blocks that are too far in the past to
+// be written through the session and writes them out as filesets.
+type filesetBuilder struct {
+	pathPrefix string
+	hashFn     sharding.HashFn
+	encOpts    encoding.Options
+
+	sync.Mutex
+	series map[filesetKey][]filesetSeries
+}
+
+func newFilesetBuilder(pathPrefix string, numShards int) *filesetBuilder {
+	return &filesetBuilder{
+		pathPrefix: pathPrefix,
+		hashFn:     sharding.DefaultHashFn(numShards),
+		encOpts:    encoding.NewOptions(),
+		series:     make(map[filesetKey][]filesetSeries),
+	}
+}
+
+// add encodes the points of a series, which must be sorted by time, into one
+// block per block start of the namespace.
+func (b *filesetBuilder) add(
+	ns Namespace,
+	id ident.ID,
+	tags ident.Tags,
+	points []whisper.Point,
+) error {
+	var (
+		shard   = b.hashFn(id)
+		encoded = make(map[filesetKey]filesetSeries)
+	)
+	for len(points) > 0 {
+		var (
+			blockStart = points[0].Timestamp.Truncate(ns.BlockSize)
+			blockEnd   = blockStart.Add(ns.BlockSize)
+			n          = sort.Search(len(points), func(i int) bool {
+				return !points[i].Timestamp.Before(blockEnd)
+			})
+			encoder = m3tsz.NewEncoder(blockStart, nil, m3tsz.DefaultIntOptimizationEnabled, b.encOpts)
+		)
+		for _, p := range points[:n] {
+			dp := ts.Datapoint{Timestamp: p.Timestamp, Value: p.Value}
+			if err := encoder.Encode(dp, xtime.Second, nil); err != nil {
+				encoder.Close()
+				return err
+			}
+		}
+		points = points[n:]
+
+		stream := encoder.Stream()
+		if stream == nil {
+			encoder.Close()
+			continue
+		}
+		segment, err := stream.Segment()
+		if err != nil {
+			encoder.Close()
+			return err
+		}
+
+		var data []byte
+		if segment.Head != nil {
+			data = append(data, segment.Head.Bytes()...)
+		}
+		if segment.Tail != nil {
+			data = append(data, segment.Tail.Bytes()...)
+		}
+		key := filesetKey{
+			namespace:  ns.ID,
+			shard:      shard,
+			blockStart: xtime.ToUnixNano(blockStart),
+		}
+		encoded[key] = filesetSeries{
+			id:       id,
+			tags:     tags,
+			data:     data,
+			checksum: digest.SegmentChecksum(segment),
+		}
+		encoder.Close()
+	}
+
+	b.Lock()
+	for key, series := range encoded {
+		b.series[key] = append(b.series[key], series)
+	}
+	b.Unlock()
+	return nil
+}
+
+// write writes out a fileset for each namespace, shard and block start that
+// series blocks were added for, returning the number of filesets written.
+func (b *filesetBuilder) write(namespaces []Namespace) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(b.series) == 0 {
+		return 0, nil
+	}
+
+	blockSizes := make(map[string]time.Duration, len(namespaces))
+	for _, ns := range namespaces {
+		blockSizes[ns.ID] = ns.BlockSize
+	}
+
+	keys := make([]filesetKey, 0, len(b.series))
+	for key := range b.series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		if keys[i].shard != keys[j].shard {
+			return keys[i].shard < keys[j].shard
+		}
+		return keys[i].blockStart < keys[j].blockStart
+	})
+
+	writer, err := fs.NewWriter(fs.NewOptions().SetFilePathPrefix(b.pathPrefix))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		err := writer.Open(fs.DataWriterOpenOptions{
+			FileSetType: persist.FileSetFlushType,
+			BlockSize:   blockSizes[key.namespace],
+			Identifier: fs.FileSetFileIdentifier{
+				Namespace:  ident.StringID(key.namespace),
+				Shard:      key.shard,
+				BlockStart: key.blockStart.ToTime(),
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		for _, series := range b.series[key] {
+			data := checked.NewBytes(series.data, nil)
+			data.IncRef()
+			err := writer.Write(series.id, series.tags, data, series.checksum)
+			data.DecRef()
+			if err != nil {
+				writer.Close()
+				return 0, err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return 0, err
+		}
+		delete(b.series, key)
+	}
+
+	return len(keys), nil
+}
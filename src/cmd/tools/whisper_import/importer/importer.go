@@ -0,0 +1,488 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package importer imports graphite whisper files into M3DB.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ingestcarbon "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/carbon"
+	"github.com/m3db/m3/src/cmd/tools/whisper_import/whisper"
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+	xsync "github.com/m3db/m3x/sync"
+	xtime "github.com/m3db/m3x/time"
+)
+
+const (
+	whisperFileExtension = ".wsp"
+
+	// taggedDirName is the directory graphite stores tagged series in, their
+	// files are named by a hash of the series name so they cannot be imported.
+	taggedDirName = "_tagged"
+)
+
+var (
+	errSessionMustBeSet          = errors.New("whisper importer options: session must be set")
+	errNamespacesMustBeSet       = errors.New("whisper importer options: namespaces must be set")
+	errIOptsMustBeSet            = errors.New("whisper importer options: instrument options must be set")
+	errConcurrencyMustBePos      = errors.New("whisper importer options: concurrency must be positive")
+	errWriteConcurrencyMustBePos = errors.New("whisper importer options: write concurrency must be positive")
+	errNumShardsMustBePos        = errors.New("whisper importer options: number of shards must be positive when writing filesets")
+	errMultipleUnaggregatedNss   = errors.New("whisper importer options: only one unaggregated namespace can be set")
+)
+
+// Namespace is a namespace whisper archives can be imported into.
+type Namespace struct {
+	ID string
+	// Resolution is the resolution of an aggregated namespace, it is zero for
+	// the unaggregated namespace.
+	Resolution time.Duration
+	// BufferPast is the buffer past of the namespace, M3DB rejects writes of
+	// datapoints older than it.
+	BufferPast time.Duration
+	// BlockSize is the block size of the namespace, it is required to write
+	// datapoints older than the buffer past to filesets.
+	BlockSize time.Duration
+}
+
+// Options configures the importer.
+type Options struct {
+	Session     client.Session
+	Namespaces  []Namespace
+	Concurrency int
+	// WriteConcurrency is the number of writes in flight through the session,
+	// the session batches concurrent writes per host.
+	WriteConcurrency  int
+	InstrumentOptions instrument.Options
+	// Until, if set, skips points at or after it, which avoids overwriting
+	// datapoints that have already been ingested by M3.
+	Until time.Time
+	// FilesetPathPrefix, if set, is the path prefix filesets are written to for
+	// datapoints older than the buffer past of their namespace. If it is not
+	// set the import fails on the first such datapoint.
+	FilesetPathPrefix string
+	// NumShards is the number of shards of the cluster, it is used to shard
+	// the series written to filesets.
+	NumShards int
+	// NowFn returns the current time, it defaults to time.Now.
+	NowFn func() time.Time
+}
+
+// Validate validates the options struct.
+func (o *Options) Validate() error {
+	if o.Session == nil {
+		return errSessionMustBeSet
+	}
+
+	if len(o.Namespaces) == 0 {
+		return errNamespacesMustBeSet
+	}
+
+	numUnaggregated := 0
+	for _, ns := range o.Namespaces {
+		if ns.ID == "" || ns.Resolution < 0 || ns.BufferPast < 0 {
+			return fmt.Errorf("whisper importer options: invalid namespace: %+v", ns)
+		}
+		if o.FilesetPathPrefix != "" && ns.BlockSize <= 0 {
+			return fmt.Errorf("whisper importer options: namespace %s must have a "+
+				"block size when writing filesets", ns.ID)
+		}
+		if ns.Resolution == 0 {
+			numUnaggregated++
+		}
+	}
+	if numUnaggregated > 1 {
+		return errMultipleUnaggregatedNss
+	}
+
+	if o.Concurrency <= 0 {
+		return errConcurrencyMustBePos
+	}
+
+	if o.WriteConcurrency <= 0 {
+		return errWriteConcurrencyMustBePos
+	}
+
+	if o.FilesetPathPrefix != "" && o.NumShards <= 0 {
+		return errNumShardsMustBePos
+	}
+
+	if o.InstrumentOptions == nil {
+		return errIOptsMustBeSet
+	}
+
+	return nil
+}
+
+// Result is the result of an import.
+type Result struct {
+	Files            int
+	FileErrors       int
+	SkippedArchives  int
+	Datapoints       int
+	DatapointErrors  int
+	SkippedTaggedDir bool
+	// FilesetDatapoints is the number of datapoints written to filesets.
+	FilesetDatapoints int
+	// Filesets is the number of filesets written.
+	Filesets int
+}
+
+// Importer imports whisper files into M3DB.
+type Importer struct {
+	opts      Options
+	tagOpts   models.TagOptions
+	nowFn     func() time.Time
+	writePool xsync.WorkerPool
+	filesets  *filesetBuilder
+
+	sync.Mutex
+	result Result
+	err    error
+}
+
+// NewImporter returns a new whisper importer.
+func NewImporter(opts Options) (*Importer, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	tagOpts := models.NewTagOptions().SetIDSchemeType(models.TypeGraphite)
+	if err := tagOpts.Validate(); err != nil {
+		return nil, err
+	}
+
+	nowFn := opts.NowFn
+	if nowFn == nil {
+		nowFn = time.Now
+	}
+
+	writePool := xsync.NewWorkerPool(opts.WriteConcurrency)
+	writePool.Init()
+
+	var filesets *filesetBuilder
+	if opts.FilesetPathPrefix != "" {
+		filesets = newFilesetBuilder(opts.FilesetPathPrefix, opts.NumShards)
+	}
+
+	return &Importer{
+		opts:      opts,
+		tagOpts:   tagOpts,
+		nowFn:     nowFn,
+		writePool: writePool,
+		filesets:  filesets,
+	}, nil
+}
+
+// Import walks the whisper directory tree at root and imports each whisper
+// file, using the path of the file relative to root as the metric name.
+// Errors importing individual files are logged and counted in the result.
+// Datapoints older than the buffer past of their namespace are written to
+// filesets once all files have been read, if no fileset path prefix is set the
+// import is aborted instead.
+func (i *Importer) Import(root string) (Result, error) {
+	var (
+		logger = i.opts.InstrumentOptions.Logger()
+		paths  = make(chan string, i.opts.Concurrency)
+		wg     sync.WaitGroup
+	)
+	for n := 0; n < i.opts.Concurrency; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				if i.aborted() != nil {
+					continue
+				}
+				if err := i.importFile(root, path); err != nil {
+					logger.Errorf("unable to import whisper file %s: %v", path, err)
+					i.Lock()
+					i.result.FileErrors++
+					i.Unlock()
+				}
+			}
+		}()
+	}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := i.aborted(); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == taggedDirName && filepath.Dir(path) == filepath.Clean(root) {
+				logger.Warnf("skipping tagged series directory %s, "+
+					"the series names cannot be recovered from the file names", path)
+				i.Lock()
+				i.result.SkippedTaggedDir = true
+				i.Unlock()
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if filepath.Ext(path) == whisperFileExtension {
+			paths <- path
+		}
+		return nil
+	})
+
+	close(paths)
+	wg.Wait()
+
+	if err == nil {
+		err = i.aborted()
+	}
+	if err == nil && i.filesets != nil {
+		var numFilesets int
+		numFilesets, err = i.filesets.write(i.opts.Namespaces)
+		i.Lock()
+		i.result.Filesets += numFilesets
+		i.Unlock()
+	}
+
+	i.Lock()
+	defer i.Unlock()
+	return i.result, err
+}
+
+func (i *Importer) abort(err error) {
+	i.Lock()
+	if i.err == nil {
+		i.err = err
+	}
+	i.Unlock()
+}
+
+func (i *Importer) aborted() error {
+	i.Lock()
+	defer i.Unlock()
+	return i.err
+}
+
+func (i *Importer) importFile(root, path string) error {
+	name, err := MetricName(root, path)
+	if err != nil {
+		return err
+	}
+
+	tags, err := ingestcarbon.GenerateTagsFromName([]byte(name), i.tagOpts)
+	if err != nil {
+		return err
+	}
+
+	var (
+		id        = ident.BytesID(tags.ID())
+		identTags = make([]ident.Tag, 0, tags.Len())
+	)
+	for _, tag := range tags.Tags {
+		identTags = append(identTags, ident.StringTag(string(tag.Name), string(tag.Value)))
+	}
+
+	f, err := whisper.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		result Result
+		now    = i.nowFn()
+	)
+	for idx, archive := range f.Archives {
+		ns, ok := SelectNamespace(i.opts.Namespaces, f.Archives, idx)
+		if !ok {
+			result.SkippedArchives++
+			continue
+		}
+
+		points, err := f.ReadArchive(idx)
+		if err != nil {
+			return err
+		}
+
+		var (
+			cutoff = now.Add(-ns.BufferPast)
+			past   []whisper.Point
+			recent []whisper.Point
+		)
+		for _, p := range points {
+			if math.IsNaN(p.Value) {
+				continue
+			}
+			if !i.opts.Until.IsZero() && !p.Timestamp.Before(i.opts.Until) {
+				continue
+			}
+			if p.Timestamp.Before(cutoff) {
+				past = append(past, p)
+				continue
+			}
+			recent = append(recent, p)
+		}
+
+		if len(past) > 0 {
+			if i.filesets == nil {
+				err := fmt.Errorf("whisper file %s archive %d (%s) has %d datapoints "+
+					"older than the buffer past %s of namespace %s which M3DB rejects, "+
+					"set a fileset path prefix to write them to filesets",
+					path, idx, archive.Resolution(), len(past), ns.BufferPast, ns.ID)
+				i.abort(err)
+				return err
+			}
+
+			// Blocks that have not been flushed by M3DB yet can neither be
+			// written to nor replaced by a fileset.
+			flushable := past[:0]
+			for _, p := range past {
+				if p.Timestamp.Truncate(ns.BlockSize).Add(ns.BlockSize).After(cutoff) {
+					result.DatapointErrors++
+					continue
+				}
+				flushable = append(flushable, p)
+			}
+			if n := len(past) - len(flushable); n > 0 {
+				i.opts.InstrumentOptions.Logger().Errorf(
+					"unable to import %d datapoints of whisper file %s archive %d (%s) to namespace %s, "+
+						"they are older than the buffer past but in a block that has not been flushed yet",
+					n, path, idx, archive.Resolution(), ns.ID)
+			}
+
+			if len(flushable) > 0 {
+				err := i.filesets.add(ns, id, ident.NewTags(identTags...), flushable)
+				if err != nil {
+					return err
+				}
+				result.FilesetDatapoints += len(flushable)
+			}
+		}
+
+		numErrors := i.writeRecent(ns, id, identTags, recent)
+		result.Datapoints += len(recent) - numErrors
+		if numErrors > 0 {
+			i.opts.InstrumentOptions.Logger().Errorf(
+				"unable to write %d datapoints of whisper file %s archive %d (%s) to namespace %s",
+				numErrors, path, idx, archive.Resolution(), ns.ID)
+			result.DatapointErrors += numErrors
+		}
+	}
+
+	i.Lock()
+	i.result.Files++
+	i.result.SkippedArchives += result.SkippedArchives
+	i.result.Datapoints += result.Datapoints
+	i.result.DatapointErrors += result.DatapointErrors
+	i.result.FilesetDatapoints += result.FilesetDatapoints
+	i.Unlock()
+	return nil
+}
+
+// writeRecent writes datapoints within the buffer past of the namespace through
+// the session, issuing the writes concurrently so that the session batches
+// them per host, and returns the number of writes that failed.
+func (i *Importer) writeRecent(
+	ns Namespace,
+	id ident.ID,
+	tags []ident.Tag,
+	points []whisper.Point,
+) int {
+	var (
+		nsID      = ident.StringID(ns.ID)
+		numErrors int64
+		wg        sync.WaitGroup
+	)
+	for _, p := range points {
+		p := p
+		wg.Add(1)
+		i.writePool.Go(func() {
+			defer wg.Done()
+			err := i.opts.Session.WriteTagged(nsID, id,
+				ident.NewTagsIterator(ident.NewTags(tags...)),
+				p.Timestamp, p.Value, xtime.Second, nil)
+			if err != nil {
+				atomic.AddInt64(&numErrors, 1)
+			}
+		})
+	}
+	wg.Wait()
+	return int(numErrors)
+}
+
+// MetricName returns the graphite metric name of a whisper file from its path
+// relative to the root of the whisper directory tree.
+func MetricName(root, path string) (string, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == "." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("whisper file %s is not within %s", path, root)
+	}
+
+	rel = strings.TrimSuffix(rel, whisperFileExtension)
+	return strings.Replace(rel, "/", ".", -1), nil
+}
+
+// SelectNamespace selects the namespace to import an archive of a whisper file
+// into. An archive is imported into the aggregated namespace with the same
+// resolution, otherwise the highest resolution archive is imported into the
+// unaggregated namespace if there is one. Archives are skipped if there is no
+// suitable namespace.
+func SelectNamespace(
+	namespaces []Namespace,
+	archives []whisper.ArchiveInfo,
+	idx int,
+) (Namespace, bool) {
+	resolution := archives[idx].Resolution()
+	for _, ns := range namespaces {
+		if ns.Resolution == resolution {
+			return ns, true
+		}
+	}
+
+	if idx != 0 {
+		return Namespace{}, false
+	}
+
+	for _, ns := range namespaces {
+		if ns.Resolution == 0 {
+			return ns, true
+		}
+	}
+
+	return Namespace{}, false
+}
@@ -0,0 +1,418 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/tools/whisper_import/whisper"
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testArchives = []whisper.ArchiveInfo{
+		{SecondsPerPoint: 10, Points: 6},
+		{SecondsPerPoint: 60, Points: 4},
+		{SecondsPerPoint: 3600, Points: 2},
+	}
+
+	testNamespaces = []Namespace{
+		{ID: "default", BufferPast: time.Hour, BlockSize: 2 * time.Hour},
+		{ID: "metrics_1m", Resolution: time.Minute, BufferPast: time.Hour, BlockSize: 2 * time.Hour},
+	}
+
+	testNow = time.Unix(1200, 0)
+)
+
+func testNowFn() time.Time {
+	return testNow
+}
+
+type testWrite struct {
+	namespace string
+	id        string
+	tags      map[string]string
+	timestamp int64
+	value     float64
+}
+
+func writeTestWhisperFile(t *testing.T, path string, points [][]whisper.Point) {
+	data, err := whisper.Encode(whisper.Header{
+		AggregationMethod: whisper.Average,
+		MaxRetention:      7200,
+		XFilesFactor:      0.5,
+		Archives:          testArchives,
+	}, points)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, data, 0644))
+}
+
+func TestImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "whisper_import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	points := [][]whisper.Point{
+		{
+			{Timestamp: time.Unix(1000, 0), Value: 1},
+			{Timestamp: time.Unix(1010, 0), Value: math.NaN()},
+			{Timestamp: time.Unix(1020, 0), Value: 3},
+		},
+		{
+			{Timestamp: time.Unix(960, 0), Value: 2},
+		},
+		{
+			{Timestamp: time.Unix(0, 0).Add(time.Hour), Value: 2},
+		},
+	}
+	writeTestWhisperFile(t, filepath.Join(dir, "foo", "bar.wsp"), points)
+	writeTestWhisperFile(t, filepath.Join(dir, "baz.wsp"), points[1:2])
+	writeTestWhisperFile(t, filepath.Join(dir, "_tagged", "abc", "def", "hash.wsp"), points)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "foo", "README"), nil, 0644))
+
+	var (
+		ctrl    = gomock.NewController(t)
+		session = client.NewMockSession(ctrl)
+		lock    sync.Mutex
+		writes  []testWrite
+	)
+	defer ctrl.Finish()
+
+	session.EXPECT().
+		WriteTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), xtime.Second, gomock.Any()).
+		DoAndReturn(func(
+			namespace, id ident.ID,
+			tags ident.TagIterator,
+			timestamp time.Time,
+			value float64,
+			_ xtime.Unit,
+			_ []byte,
+		) error {
+			write := testWrite{
+				namespace: namespace.String(),
+				id:        id.String(),
+				tags:      make(map[string]string),
+				timestamp: timestamp.Unix(),
+				value:     value,
+			}
+			for tags.Next() {
+				tag := tags.Current()
+				write.tags[tag.Name.String()] = tag.Value.String()
+			}
+			require.NoError(t, tags.Err())
+
+			lock.Lock()
+			writes = append(writes, write)
+			lock.Unlock()
+
+			if write.id == "baz" {
+				return errors.New("some_error")
+			}
+			return nil
+		}).AnyTimes()
+
+	importer, err := NewImporter(Options{
+		Session:           session,
+		Namespaces:        testNamespaces,
+		Concurrency:       2,
+		WriteConcurrency:  4,
+		InstrumentOptions: instrument.NewOptions(),
+		NowFn:             testNowFn,
+	})
+	require.NoError(t, err)
+
+	result, err := importer.Import(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Result{
+		Files:            2,
+		SkippedArchives:  2,
+		Datapoints:       3,
+		DatapointErrors:  1,
+		SkippedTaggedDir: true,
+	}, result)
+
+	sort.Slice(writes, func(i, j int) bool {
+		if writes[i].id == writes[j].id {
+			return writes[i].timestamp < writes[j].timestamp
+		}
+		return writes[i].id < writes[j].id
+	})
+	fooBarTags := map[string]string{"__g0__": "foo", "__g1__": "bar"}
+	assert.Equal(t, []testWrite{
+		{namespace: "default", id: "baz", tags: map[string]string{"__g0__": "baz"}, timestamp: 960, value: 2},
+		{namespace: "metrics_1m", id: "foo.bar", tags: fooBarTags, timestamp: 960, value: 2},
+		{namespace: "default", id: "foo.bar", tags: fooBarTags, timestamp: 1000, value: 1},
+		{namespace: "default", id: "foo.bar", tags: fooBarTags, timestamp: 1020, value: 3},
+	}, writes)
+}
+
+func TestImportUntil(t *testing.T) {
+	dir, err := ioutil.TempDir("", "whisper_import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestWhisperFile(t, filepath.Join(dir, "foo.wsp"), [][]whisper.Point{
+		{
+			{Timestamp: time.Unix(1000, 0), Value: 1},
+			{Timestamp: time.Unix(1010, 0), Value: 2},
+		},
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockSession(ctrl)
+	session.EXPECT().
+		WriteTagged(gomock.Any(), gomock.Any(), gomock.Any(), time.Unix(1000, 0), 1.0, xtime.Second, gomock.Any()).
+		Return(nil)
+
+	importer, err := NewImporter(Options{
+		Session:           session,
+		Namespaces:        testNamespaces,
+		Concurrency:       1,
+		WriteConcurrency:  1,
+		InstrumentOptions: instrument.NewOptions(),
+		Until:             time.Unix(1010, 0),
+		NowFn:             testNowFn,
+	})
+	require.NoError(t, err)
+
+	result, err := importer.Import(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Datapoints)
+}
+
+func TestImportTooPastWithoutFilesets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "whisper_import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestWhisperFile(t, filepath.Join(dir, "foo.wsp"), [][]whisper.Point{
+		{
+			{Timestamp: time.Unix(1000, 0), Value: 1},
+		},
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	importer, err := NewImporter(Options{
+		Session:           client.NewMockSession(ctrl),
+		Namespaces:        testNamespaces,
+		Concurrency:       1,
+		WriteConcurrency:  1,
+		InstrumentOptions: instrument.NewOptions(),
+		NowFn: func() time.Time {
+			return time.Unix(1000, 0).Add(2 * time.Hour)
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = importer.Import(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "older than the buffer past")
+}
+
+func TestImportFilesets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "whisper_import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filesetDir, err := ioutil.TempDir("", "whisper_import_filesets")
+	require.NoError(t, err)
+	defer os.RemoveAll(filesetDir)
+
+	blockStart := time.Unix(0, 0).Add(2 * time.Hour)
+	writeTestWhisperFile(t, filepath.Join(dir, "foo.wsp"), [][]whisper.Point{
+		{
+			{Timestamp: blockStart.Add(10 * time.Second), Value: 1},
+			{Timestamp: blockStart.Add(20 * time.Second), Value: 2},
+		},
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	importer, err := NewImporter(Options{
+		Session:           client.NewMockSession(ctrl),
+		Namespaces:        testNamespaces,
+		Concurrency:       1,
+		WriteConcurrency:  1,
+		InstrumentOptions: instrument.NewOptions(),
+		FilesetPathPrefix: filesetDir,
+		NumShards:         1,
+		NowFn: func() time.Time {
+			return blockStart.Add(4 * time.Hour)
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := importer.Import(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Result{
+		Files:             1,
+		SkippedArchives:   1,
+		FilesetDatapoints: 2,
+		Filesets:          1,
+	}, result)
+
+	reader, err := fs.NewReader(nil, fs.NewOptions().SetFilePathPrefix(filesetDir))
+	require.NoError(t, err)
+	require.NoError(t, reader.Open(fs.DataReaderOpenOptions{
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:  ident.StringID("default"),
+			Shard:      0,
+			BlockStart: blockStart,
+		},
+		FileSetType: persist.FileSetFlushType,
+	}))
+	defer reader.Close()
+
+	id, tags, data, _, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", id.String())
+	require.True(t, tags.Next())
+	assert.Equal(t, "__g0__", tags.Current().Name.String())
+	assert.Equal(t, "foo", tags.Current().Value.String())
+
+	iter := m3tsz.NewReaderIterator(bytes.NewReader(data.Bytes()),
+		m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions())
+	var values []float64
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		values = append(values, dp.Value)
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []float64{1, 2}, values)
+
+	_, _, _, _, err = reader.Read()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestMetricName(t *testing.T) {
+	name, err := MetricName("/var/lib/graphite/whisper", "/var/lib/graphite/whisper/foo/bar/baz.wsp")
+	require.NoError(t, err)
+	assert.Equal(t, "foo.bar.baz", name)
+
+	name, err = MetricName("/var/lib/graphite/whisper/", "/var/lib/graphite/whisper/foo.wsp")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", name)
+
+	_, err = MetricName("/var/lib/graphite/whisper", "/var/lib/graphite/foo.wsp")
+	require.Error(t, err)
+}
+
+func TestSelectNamespace(t *testing.T) {
+	ns, ok := SelectNamespace(testNamespaces, testArchives, 0)
+	require.True(t, ok)
+	assert.Equal(t, "default", ns.ID)
+
+	ns, ok = SelectNamespace(testNamespaces, testArchives, 1)
+	require.True(t, ok)
+	assert.Equal(t, "metrics_1m", ns.ID)
+
+	_, ok = SelectNamespace(testNamespaces, testArchives, 2)
+	require.False(t, ok)
+
+	// An aggregated namespace with the same resolution is preferred.
+	ns, ok = SelectNamespace(append([]Namespace{
+		{ID: "metrics_10s", Resolution: 10 * time.Second},
+	}, testNamespaces...), testArchives, 0)
+	require.True(t, ok)
+	assert.Equal(t, "metrics_10s", ns.ID)
+
+	_, ok = SelectNamespace(testNamespaces[1:], testArchives, 0)
+	require.False(t, ok)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valid := Options{
+		Session:           client.NewMockSession(ctrl),
+		Namespaces:        testNamespaces,
+		Concurrency:       1,
+		WriteConcurrency:  1,
+		InstrumentOptions: instrument.NewOptions(),
+	}
+	require.NoError(t, valid.Validate())
+
+	opts := valid
+	opts.Session = nil
+	require.Equal(t, errSessionMustBeSet, opts.Validate())
+
+	opts = valid
+	opts.Namespaces = nil
+	require.Equal(t, errNamespacesMustBeSet, opts.Validate())
+
+	opts = valid
+	opts.Namespaces = append(opts.Namespaces, Namespace{ID: "other"})
+	require.Equal(t, errMultipleUnaggregatedNss, opts.Validate())
+
+	opts = valid
+	opts.Namespaces = []Namespace{{ID: ""}}
+	require.Error(t, opts.Validate())
+
+	opts = valid
+	opts.Concurrency = 0
+	require.Equal(t, errConcurrencyMustBePos, opts.Validate())
+
+	opts = valid
+	opts.WriteConcurrency = 0
+	require.Equal(t, errWriteConcurrencyMustBePos, opts.Validate())
+
+	opts = valid
+	opts.InstrumentOptions = nil
+	require.Equal(t, errIOptsMustBeSet, opts.Validate())
+
+	opts = valid
+	opts.FilesetPathPrefix = "/tmp"
+	require.Equal(t, errNumShardsMustBePos, opts.Validate())
+
+	opts.NumShards = 1
+	require.NoError(t, opts.Validate())
+
+	opts.Namespaces = []Namespace{{ID: "default"}}
+	require.Error(t, opts.Validate())
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// whisper_import is a tool for importing graphite whisper files into M3DB.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/tools/whisper_import/importer"
+	"github.com/m3db/m3/src/dbnode/client"
+	xconfig "github.com/m3db/m3x/config"
+	"github.com/m3db/m3x/instrument"
+	xlog "github.com/m3db/m3x/log"
+)
+
+// configuration is the configuration of the M3DB client used to import.
+type configuration struct {
+	Client client.Configuration `yaml:"client"`
+}
+
+func main() {
+	var (
+		configFile  = flag.String("config", "", "M3DB client configuration file")
+		path        = flag.String("path", "", "Whisper directory tree to import [e.g. /var/lib/graphite/whisper]")
+		namespaces  = flag.String("namespaces", "", "Namespaces to import into, as name for the unaggregated namespace and name:resolution for aggregated namespaces, optionally followed by :blockSize to override the block size [e.g. default,metrics_1m:1m:24h]")
+		concurrency = flag.Int("concurrency", 16, "Number of whisper files imported concurrently")
+		writeConc   = flag.Int("write-concurrency", 256, "Number of datapoint writes in flight")
+		until       = flag.String("until", "", "Only import datapoints before this RFC3339 time (optional)")
+		bufferPast  = flag.Duration("buffer-past", 10*time.Minute, "Buffer past of the namespaces, older datapoints are written to filesets")
+		blockSize   = flag.Duration("block-size", 2*time.Hour, "Default block size of the namespaces")
+		filesetPath = flag.String("fileset-path-prefix", "", "Path prefix to write filesets of datapoints older than the buffer past to (optional)")
+		numShards   = flag.Int("num-shards", 0, "Number of shards of the cluster, required with -fileset-path-prefix")
+		log         = xlog.NewLogger(os.Stderr)
+	)
+	flag.Parse()
+
+	if *configFile == "" || *path == "" || *namespaces == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	nss, err := parseNamespaces(*namespaces, *bufferPast, *blockSize)
+	if err != nil {
+		log.Fatalf("invalid namespaces: %v", err)
+	}
+
+	var untilTime time.Time
+	if *until != "" {
+		untilTime, err = time.Parse(time.RFC3339, *until)
+		if err != nil {
+			log.Fatalf("invalid until time: %v", err)
+		}
+	}
+
+	var cfg configuration
+	if err := xconfig.LoadFile(&cfg, *configFile, xconfig.Options{}); err != nil {
+		log.Fatalf("unable to load config file: %v", err)
+	}
+
+	iOpts := instrument.NewOptions().SetLogger(log)
+	m3dbClient, err := cfg.Client.NewClient(client.ConfigurationParameters{
+		InstrumentOptions: iOpts,
+	})
+	if err != nil {
+		log.Fatalf("unable to create M3DB client: %v", err)
+	}
+
+	session, err := m3dbClient.NewSession()
+	if err != nil {
+		log.Fatalf("unable to create M3DB session: %v", err)
+	}
+	defer session.Close()
+
+	imp, err := importer.NewImporter(importer.Options{
+		Session:           session,
+		Namespaces:        nss,
+		Concurrency:       *concurrency,
+		WriteConcurrency:  *writeConc,
+		InstrumentOptions: iOpts,
+		Until:             untilTime,
+		FilesetPathPrefix: *filesetPath,
+		NumShards:         *numShards,
+	})
+	if err != nil {
+		log.Fatalf("unable to create importer: %v", err)
+	}
+
+	start := time.Now()
+	result, err := imp.Import(*path)
+	if err != nil {
+		log.Fatalf("unable to import whisper files: %v", err)
+	}
+
+	log.Infof("imported %d whisper files (%d errors) with %d datapoints (%d errors) in %s, skipped %d archives without a namespace",
+		result.Files, result.FileErrors, result.Datapoints, result.DatapointErrors,
+		time.Since(start), result.SkippedArchives)
+	if result.Filesets > 0 {
+		log.Infof("wrote %d datapoints older than the buffer past to %d filesets in %s",
+			result.FilesetDatapoints, result.Filesets, *filesetPath)
+	}
+	if result.FileErrors > 0 || result.DatapointErrors > 0 {
+		os.Exit(1)
+	}
+}
+
+// parseNamespaces parses namespaces of the form name for the unaggregated
+// namespace and name:resolution for aggregated namespaces, either optionally
+// followed by :blockSize, with an empty resolution for the unaggregated
+// namespace.
+func parseNamespaces(
+	value string,
+	bufferPast time.Duration,
+	blockSize time.Duration,
+) ([]importer.Namespace, error) {
+	var namespaces []importer.Namespace
+	for _, part := range strings.Split(value, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ":")
+		ns := importer.Namespace{
+			ID:         fields[0],
+			BufferPast: bufferPast,
+			BlockSize:  blockSize,
+		}
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("invalid namespace: %s", part)
+		}
+		if len(fields) > 1 && fields[1] != "" {
+			resolution, err := time.ParseDuration(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			if resolution <= 0 {
+				return nil, fmt.Errorf("resolution of namespace %s must be positive", ns.ID)
+			}
+			ns.Resolution = resolution
+		}
+		if len(fields) > 2 {
+			nsBlockSize, err := time.ParseDuration(fields[2])
+			if err != nil {
+				return nil, err
+			}
+			if nsBlockSize <= 0 {
+				return nil, fmt.Errorf("block size of namespace %s must be positive", ns.ID)
+			}
+			ns.BlockSize = nsBlockSize
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package whisper decodes graphite whisper database files.
+package whisper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	metadataSize    = 16
+	archiveInfoSize = 12
+	pointSize       = 12
+)
+
+var (
+	errNoArchives = errors.New("whisper file has no archives")
+)
+
+// AggregationMethod is the method a whisper file uses to aggregate points
+// into its lower resolution archives.
+type AggregationMethod uint32
+
+// The whisper aggregation methods.
+const (
+	Average AggregationMethod = iota + 1
+	Sum
+	Last
+	Max
+	Min
+	AvgZero
+	AbsMax
+	AbsMin
+)
+
+func (m AggregationMethod) String() string {
+	switch m {
+	case Average:
+		return "average"
+	case Sum:
+		return "sum"
+	case Last:
+		return "last"
+	case Max:
+		return "max"
+	case Min:
+		return "min"
+	case AvgZero:
+		return "avg_zero"
+	case AbsMax:
+		return "absmax"
+	case AbsMin:
+		return "absmin"
+	default:
+		return "unknown"
+	}
+}
+
+// Header is the header of a whisper file.
+type Header struct {
+	AggregationMethod AggregationMethod
+	MaxRetention      uint32
+	XFilesFactor      float32
+	Archives          []ArchiveInfo
+}
+
+// ArchiveInfo describes an archive of a whisper file, archives are ordered
+// from the highest to the lowest resolution.
+type ArchiveInfo struct {
+	Offset          uint32
+	SecondsPerPoint uint32
+	Points          uint32
+}
+
+// Resolution returns the resolution of the archive.
+func (a ArchiveInfo) Resolution() time.Duration {
+	return time.Duration(a.SecondsPerPoint) * time.Second
+}
+
+// Retention returns the retention of the archive.
+func (a ArchiveInfo) Retention() time.Duration {
+	return time.Duration(a.SecondsPerPoint) * time.Duration(a.Points) * time.Second
+}
+
+// Point is a point of a whisper archive.
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// File is an open whisper file.
+type File struct {
+	Header
+
+	r io.ReaderAt
+	c io.Closer
+}
+
+// Open opens and reads the header of a whisper file.
+func Open(path string) (*File, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := NewFile(fd)
+	if err != nil {
+		fd.Close()
+		return nil, err
+	}
+
+	f.c = fd
+	return f, nil
+}
+
+// NewFile reads the header of a whisper file from a reader.
+func NewFile(r io.ReaderAt) (*File, error) {
+	var metadata [metadataSize]byte
+	if _, err := r.ReadAt(metadata[:], 0); err != nil {
+		return nil, fmt.Errorf("unable to read whisper metadata: %v", err)
+	}
+
+	header := Header{
+		AggregationMethod: AggregationMethod(binary.BigEndian.Uint32(metadata[0:4])),
+		MaxRetention:      binary.BigEndian.Uint32(metadata[4:8]),
+		XFilesFactor:      math.Float32frombits(binary.BigEndian.Uint32(metadata[8:12])),
+	}
+
+	numArchives := binary.BigEndian.Uint32(metadata[12:16])
+	if numArchives == 0 {
+		return nil, errNoArchives
+	}
+
+	infos := make([]byte, int(numArchives)*archiveInfoSize)
+	if _, err := r.ReadAt(infos, metadataSize); err != nil {
+		return nil, fmt.Errorf("unable to read whisper archive info: %v", err)
+	}
+
+	header.Archives = make([]ArchiveInfo, 0, numArchives)
+	for i := 0; i < int(numArchives); i++ {
+		info := infos[i*archiveInfoSize:]
+		archive := ArchiveInfo{
+			Offset:          binary.BigEndian.Uint32(info[0:4]),
+			SecondsPerPoint: binary.BigEndian.Uint32(info[4:8]),
+			Points:          binary.BigEndian.Uint32(info[8:12]),
+		}
+		if archive.SecondsPerPoint == 0 || archive.Points == 0 {
+			return nil, fmt.Errorf("whisper archive %d is invalid: %+v", i, archive)
+		}
+		header.Archives = append(header.Archives, archive)
+	}
+
+	return &File{Header: header, r: r}, nil
+}
+
+// ReadArchive returns the points of an archive ordered by time. Archives are
+// circular buffers so only the points within the archive retention of its
+// latest point are returned, any others have been superseded. The latest point
+// is used rather than the current time so that files from a decommissioned
+// cluster can be read in full.
+func (f *File) ReadArchive(idx int) ([]Point, error) {
+	if idx < 0 || idx >= len(f.Archives) {
+		return nil, fmt.Errorf("whisper archive %d does not exist", idx)
+	}
+
+	archive := f.Archives[idx]
+	buf := make([]byte, int(archive.Points)*pointSize)
+	if _, err := f.r.ReadAt(buf, int64(archive.Offset)); err != nil {
+		return nil, fmt.Errorf("unable to read whisper archive %d: %v", idx, err)
+	}
+
+	var (
+		intervals = make([]uint32, 0, archive.Points)
+		values    = make([]float64, 0, archive.Points)
+		latest    uint32
+	)
+	for i := 0; i < int(archive.Points); i++ {
+		point := buf[i*pointSize:]
+		interval := binary.BigEndian.Uint32(point[0:4])
+		if interval == 0 {
+			// Never written.
+			continue
+		}
+		if interval > latest {
+			latest = interval
+		}
+		intervals = append(intervals, interval)
+		values = append(values, math.Float64frombits(binary.BigEndian.Uint64(point[4:12])))
+	}
+
+	var (
+		retention = int64(archive.SecondsPerPoint) * int64(archive.Points)
+		earliest  = int64(latest) - retention
+		points    = make([]Point, 0, len(intervals))
+	)
+	for i, interval := range intervals {
+		if int64(interval) <= earliest {
+			continue
+		}
+		points = append(points, Point{
+			Timestamp: time.Unix(int64(interval), 0),
+			Value:     values[i],
+		})
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
+	return points, nil
+}
+
+// Close closes the file if it was opened by Open.
+func (f *File) Close() error {
+	if f.c == nil {
+		return nil
+	}
+	return f.c.Close()
+}
+
+// Encode encodes a whisper file with a header and the points of each of its
+// archives, the archive offsets of the header are ignored. It is mostly useful
+// for testing as points are written to the slot for their timestamp.
+func Encode(header Header, points [][]Point) ([]byte, error) {
+	if len(header.Archives) == 0 {
+		return nil, errNoArchives
+	}
+	if len(points) > len(header.Archives) {
+		return nil, fmt.Errorf("points for %d archives but only %d archives",
+			len(points), len(header.Archives))
+	}
+
+	size := metadataSize + len(header.Archives)*archiveInfoSize
+	for _, archive := range header.Archives {
+		size += int(archive.Points) * pointSize
+	}
+
+	var (
+		buf    = make([]byte, size)
+		offset = metadataSize + len(header.Archives)*archiveInfoSize
+	)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(header.AggregationMethod))
+	binary.BigEndian.PutUint32(buf[4:8], header.MaxRetention)
+	binary.BigEndian.PutUint32(buf[8:12], math.Float32bits(header.XFilesFactor))
+	binary.BigEndian.PutUint32(buf[12:16], uint32(len(header.Archives)))
+	for i, archive := range header.Archives {
+		if archive.SecondsPerPoint == 0 || archive.Points == 0 {
+			return nil, fmt.Errorf("whisper archive %d is invalid: %+v", i, archive)
+		}
+
+		info := buf[metadataSize+i*archiveInfoSize:]
+		binary.BigEndian.PutUint32(info[0:4], uint32(offset))
+		binary.BigEndian.PutUint32(info[4:8], archive.SecondsPerPoint)
+		binary.BigEndian.PutUint32(info[8:12], archive.Points)
+
+		if i < len(points) {
+			for _, p := range points[i] {
+				interval := uint32(p.Timestamp.Unix())
+				slot := (interval / archive.SecondsPerPoint) % archive.Points
+				point := buf[offset+int(slot)*pointSize:]
+				binary.BigEndian.PutUint32(point[0:4], interval)
+				binary.BigEndian.PutUint64(point[4:12], math.Float64bits(p.Value))
+			}
+		}
+		offset += int(archive.Points) * pointSize
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package whisper
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHeader = Header{
+	AggregationMethod: Average,
+	MaxRetention:      4 * 60,
+	XFilesFactor:      0.5,
+	Archives: []ArchiveInfo{
+		{SecondsPerPoint: 10, Points: 6},
+		{SecondsPerPoint: 60, Points: 4},
+	},
+}
+
+func testPoints(start, step int64, values ...float64) []Point {
+	points := make([]Point, 0, len(values))
+	for i, v := range values {
+		points = append(points, Point{
+			Timestamp: time.Unix(start+int64(i)*step, 0),
+			Value:     v,
+		})
+	}
+	return points
+}
+
+func TestReadArchive(t *testing.T) {
+	var (
+		highRes = testPoints(1000, 10, 1, 2, 3, 4)
+		lowRes  = testPoints(960, 60, 2.5, 3.5)
+	)
+	data, err := Encode(testHeader, [][]Point{highRes, lowRes})
+	require.NoError(t, err)
+
+	f, err := NewFile(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, Average, f.AggregationMethod)
+	assert.Equal(t, uint32(240), f.MaxRetention)
+	assert.Equal(t, float32(0.5), f.XFilesFactor)
+	require.Equal(t, 2, len(f.Archives))
+	assert.Equal(t, 10*time.Second, f.Archives[0].Resolution())
+	assert.Equal(t, time.Minute, f.Archives[0].Retention())
+	assert.Equal(t, time.Minute, f.Archives[1].Resolution())
+	assert.Equal(t, 4*time.Minute, f.Archives[1].Retention())
+
+	points, err := f.ReadArchive(0)
+	require.NoError(t, err)
+	assert.Equal(t, highRes, points)
+
+	points, err = f.ReadArchive(1)
+	require.NoError(t, err)
+	assert.Equal(t, lowRes, points)
+
+	_, err = f.ReadArchive(2)
+	require.Error(t, err)
+}
+
+func TestReadArchiveWrapsAround(t *testing.T) {
+	// The six slots are written twice, with the first points of the second
+	// pass overwriting the first pass.
+	var (
+		firstPass  = testPoints(1200, 10, 1, 2, 3, 4, 5, 6)
+		secondPass = testPoints(1260, 10, 7, 8, 9)
+	)
+	data, err := Encode(testHeader, [][]Point{append(firstPass, secondPass...)})
+	require.NoError(t, err)
+
+	f, err := NewFile(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	points, err := f.ReadArchive(0)
+	require.NoError(t, err)
+	assert.Equal(t, append(firstPass[3:], secondPass...), points)
+
+	// Points older than the retention of the latest point are stale.
+	stale := testPoints(1000, 10, 1)
+	data, err = Encode(testHeader, [][]Point{append(stale, secondPass...)})
+	require.NoError(t, err)
+
+	f, err = NewFile(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	points, err = f.ReadArchive(0)
+	require.NoError(t, err)
+	assert.Equal(t, secondPass, points)
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "whisper")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data, err := Encode(testHeader, nil)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "foo.wsp")
+	require.NoError(t, ioutil.WriteFile(path, data, 0644))
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	points, err := f.ReadArchive(1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(points))
+}
+
+func TestNewFileErrors(t *testing.T) {
+	_, err := NewFile(bytes.NewReader([]byte("garbage")))
+	require.Error(t, err)
+
+	data, err := Encode(testHeader, nil)
+	require.NoError(t, err)
+
+	// No archives.
+	noArchives := append([]byte(nil), data...)
+	copy(noArchives[12:16], []byte{0, 0, 0, 0})
+	_, err = NewFile(bytes.NewReader(noArchives))
+	require.Equal(t, errNoArchives, err)
+
+	// Truncated archive info.
+	_, err = NewFile(bytes.NewReader(data[:metadataSize+archiveInfoSize]))
+	require.Error(t, err)
+
+	// Truncated archive.
+	f, err := NewFile(bytes.NewReader(data[:len(data)-1]))
+	require.NoError(t, err)
+	_, err = f.ReadArchive(1)
+	require.Error(t, err)
+}
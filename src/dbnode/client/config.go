@@ -24,11 +24,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/dbnode/x/tchannel"
 	"github.com/m3db/m3x/instrument"
@@ -79,6 +81,12 @@ type Configuration struct {
 
 	// HashingConfiguration is the configuration for hashing of IDs to shards.
 	HashingConfiguration *HashingConfiguration `yaml:"hashing"`
+
+	// PeerBootstrap is the configuration for streaming blocks from peers.
+	PeerBootstrap *PeerBootstrapConfiguration `yaml:"peerBootstrap"`
+
+	// WriteBatching is the configuration for batching writes sent to each node.
+	WriteBatching *WriteBatchingConfiguration `yaml:"writeBatching"`
 }
 
 // Validate validates the configuration.
@@ -109,6 +117,18 @@ func (c *Configuration) Validate() error {
 			*c.BackgroundHealthCheckFailThrottleFactor)
 	}
 
+	if c.PeerBootstrap != nil {
+		if err := c.PeerBootstrap.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.WriteBatching != nil {
+		if err := c.WriteBatching.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -118,6 +138,93 @@ type HashingConfiguration struct {
 	Seed uint32 `yaml:"seed"`
 }
 
+// PeerBootstrapConfiguration is the configuration for streaming
+// blocks from peers when bootstrapping.
+type PeerBootstrapConfiguration struct {
+	// BatchSize is the max number of blocks to fetch from a peer in one request.
+	BatchSize *int `yaml:"batchSize"`
+
+	// PeerLimitMbps is the bandwidth limit in megabits per second when
+	// streaming blocks from any single peer, zero disables the limit.
+	PeerLimitMbps *float64 `yaml:"peerLimitMbps"`
+
+	// AdaptiveBatchSize enables adapting the batch size to how
+	// quickly each peer responds, up to the batch size.
+	AdaptiveBatchSize *bool `yaml:"adaptiveBatchSize"`
+
+	// BatchTargetLatency is the latency of a single batch request
+	// that adaptive batch sizing aims for.
+	BatchTargetLatency *time.Duration `yaml:"batchTargetLatency"`
+}
+
+// Validate validates the peer bootstrap configuration.
+func (c *PeerBootstrapConfiguration) Validate() error {
+	if c.BatchSize != nil && *c.BatchSize <= 0 {
+		return fmt.Errorf("m3db client peerBootstrap batchSize was: %d but must be > 0", *c.BatchSize)
+	}
+
+	if c.PeerLimitMbps != nil && *c.PeerLimitMbps < 0 {
+		return fmt.Errorf("m3db client peerBootstrap peerLimitMbps was: %f but must be >= 0", *c.PeerLimitMbps)
+	}
+
+	if c.BatchTargetLatency != nil && *c.BatchTargetLatency <= 0 {
+		return fmt.Errorf("m3db client peerBootstrap batchTargetLatency was: %d but must be > 0", *c.BatchTargetLatency)
+	}
+
+	return nil
+}
+
+// WriteBatchingConfiguration is the configuration for batching
+// writes sent to each node.
+type WriteBatchingConfiguration struct {
+	// FlushSize is the number of queued ops that triggers a flush to a node.
+	FlushSize *int `yaml:"flushSize"`
+
+	// FlushMaxSize enables adaptive flushing when greater than the flush
+	// size, the flush size then grows up to this size under load.
+	FlushMaxSize *int `yaml:"flushMaxSize"`
+
+	// FlushShrinkLatency is the write batch latency below which adaptive
+	// flush sizes shrink back towards the flush size.
+	FlushShrinkLatency *time.Duration `yaml:"flushShrinkLatency"`
+
+	// Compression is the compression to use for tagged write batches
+	// sent to nodes that support it, one of "none" or "snappy".
+	Compression *string `yaml:"compression"`
+
+	// DedupTags enables deduplicating identical encoded tags across a
+	// tagged write batch sent to nodes that support it.
+	DedupTags *bool `yaml:"dedupTags"`
+}
+
+// Validate validates the write batching configuration.
+func (c *WriteBatchingConfiguration) Validate() error {
+	if c.FlushSize != nil && *c.FlushSize <= 0 {
+		return fmt.Errorf("m3db client writeBatching flushSize was: %d but must be > 0", *c.FlushSize)
+	}
+
+	if c.FlushMaxSize != nil && *c.FlushMaxSize < 0 {
+		return fmt.Errorf("m3db client writeBatching flushMaxSize was: %d but must be >= 0", *c.FlushMaxSize)
+	}
+
+	if c.Compression != nil {
+		if _, err := c.compression(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *WriteBatchingConfiguration) compression() (rpc.WriteBatchCompression, error) {
+	value, err := rpc.WriteBatchCompressionFromString(strings.ToUpper(*c.Compression))
+	if err != nil {
+		return 0, fmt.Errorf("m3db client writeBatching compression was: %s but must be one of none, snappy",
+			*c.Compression)
+	}
+	return value, nil
+}
+
 // ConfigurationParameters are optional parameters that can be specified
 // when creating a client from configuration, this is specified using
 // a struct so that adding fields do not cause breaking changes to callers.
@@ -249,6 +356,28 @@ func (c Configuration) NewAdminClient(
 		v = v.SetFetchRetrier(c.FetchRetry.NewRetrier(fetchRequestScope))
 	}
 
+	if wb := c.WriteBatching; wb != nil {
+		if wb.FlushSize != nil {
+			v = v.SetHostQueueOpsFlushSize(*wb.FlushSize)
+		}
+		if wb.FlushMaxSize != nil {
+			v = v.SetHostQueueOpsFlushMaxSize(*wb.FlushMaxSize)
+		}
+		if wb.FlushShrinkLatency != nil {
+			v = v.SetHostQueueOpsFlushShrinkLatency(*wb.FlushShrinkLatency)
+		}
+		if wb.Compression != nil {
+			compression, err := wb.compression()
+			if err != nil {
+				return nil, err
+			}
+			v = v.SetWriteBatchCompression(compression)
+		}
+		if wb.DedupTags != nil {
+			v = v.SetWriteTaggedBatchDedupTags(*wb.DedupTags)
+		}
+	}
+
 	encodingOpts := params.EncodingOptions
 	if encodingOpts == nil {
 		encodingOpts = encoding.NewOptions()
@@ -261,6 +390,20 @@ func (c Configuration) NewAdminClient(
 
 	// Apply programtic custom options last
 	opts := v.(AdminOptions)
+	if pb := c.PeerBootstrap; pb != nil {
+		if pb.BatchSize != nil {
+			opts = opts.SetFetchSeriesBlocksBatchSize(*pb.BatchSize)
+		}
+		if pb.PeerLimitMbps != nil {
+			opts = opts.SetFetchSeriesBlocksPeerLimitMbps(*pb.PeerLimitMbps)
+		}
+		if pb.AdaptiveBatchSize != nil {
+			opts = opts.SetFetchSeriesBlocksAdaptiveBatchSize(*pb.AdaptiveBatchSize)
+		}
+		if pb.BatchTargetLatency != nil {
+			opts = opts.SetFetchSeriesBlocksBatchTargetLatency(*pb.BatchTargetLatency)
+		}
+	}
 	for _, opt := range custom {
 		opts = opt(opts)
 	}
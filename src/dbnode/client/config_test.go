@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/topology"
 	xconfig "github.com/m3db/m3x/config"
 	"github.com/m3db/m3x/retry"
@@ -56,6 +57,17 @@ backgroundHealthCheckFailLimit: 4
 backgroundHealthCheckFailThrottleFactor: 0.5
 hashing:
   seed: 42
+peerBootstrap:
+  batchSize: 1024
+  peerLimitMbps: 100
+  adaptiveBatchSize: true
+  batchTargetLatency: 5s
+writeBatching:
+  flushSize: 128
+  flushMaxSize: 1024
+  flushShrinkLatency: 5ms
+  compression: snappy
+  dedupTags: true
 `
 
 	fd, err := ioutil.TempFile("", "config.yaml")
@@ -82,6 +94,12 @@ hashing:
 		num4                 = 4
 		numHalf              = 0.5
 		boolTrue             = true
+		num1024              = 1024
+		num100               = 100.0
+		second5              = 5 * time.Second
+		num128               = 128
+		snappy               = "snappy"
+		millis5              = 5 * time.Millisecond
 	)
 
 	expected := Configuration{
@@ -108,7 +126,36 @@ hashing:
 		HashingConfiguration: &HashingConfiguration{
 			Seed: 42,
 		},
+		PeerBootstrap: &PeerBootstrapConfiguration{
+			BatchSize:          &num1024,
+			PeerLimitMbps:      &num100,
+			AdaptiveBatchSize:  &boolTrue,
+			BatchTargetLatency: &second5,
+		},
+		WriteBatching: &WriteBatchingConfiguration{
+			FlushSize:          &num128,
+			FlushMaxSize:       &num1024,
+			FlushShrinkLatency: &millis5,
+			Compression:        &snappy,
+			DedupTags:          &boolTrue,
+		},
 	}
 
 	assert.Equal(t, expected, cfg)
 }
+
+func TestWriteBatchingConfigurationValidate(t *testing.T) {
+	var (
+		snappy  = "snappy"
+		unknown = "gzip"
+	)
+	cfg := WriteBatchingConfiguration{Compression: &snappy}
+	require.NoError(t, cfg.Validate())
+
+	compression, err := cfg.compression()
+	require.NoError(t, err)
+	assert.Equal(t, rpc.WriteBatchCompression_SNAPPY, compression)
+
+	cfg = WriteBatchingConfiguration{Compression: &unknown}
+	require.Error(t, cfg.Validate())
+}
@@ -23,20 +23,51 @@ package client
 import (
 	"fmt"
 	"math"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/pool"
 	xsync "github.com/m3db/m3x/sync"
 
+	"github.com/uber/tchannel-go"
 	"github.com/uber/tchannel-go/thrift"
 )
 
-const workerPoolKillProbability = 0.01
+const (
+	workerPoolKillProbability = 0.01
+
+	// writeBatchV2RecheckInterval is how long to wait before asking a node
+	// that did not advertise the requested write batch compression again,
+	// the node may have been upgraded since.
+	writeBatchV2RecheckInterval = time.Minute
+
+	// writeBatchV2ProbeErrorBackoff is how long to wait before asking a node
+	// for its supported write batch compressions again after asking failed.
+	writeBatchV2ProbeErrorBackoff = 5 * time.Second
+
+	// unknownMethodErrMsg is contained in the error returned by nodes for
+	// methods they do not implement.
+	unknownMethodErrMsg = "not found in service"
+
+	// writeLatencyDecay weights each write batch latency observed into the
+	// moving average, a higher value smooths out more of the variance.
+	writeLatencyDecay = 4
+)
+
+type writeBatchV2Support int
+
+const (
+	writeBatchV2SupportUnknown writeBatchV2Support = iota
+	writeBatchV2Supported
+	writeBatchV2Unsupported
+)
 
 type queue struct {
 	sync.WaitGroup
@@ -52,12 +83,21 @@ type queue struct {
 	writeTaggedBatchRawRequestElementArrayPool writeTaggedBatchRawRequestElementArrayPool
 	workerPool                                 xsync.PooledWorkerPool
 	size                                       int
+	minSize                                    int
+	maxSize                                    int
+	shrinkLatency                              time.Duration
+	writeLatency                               int64
 	ops                                        []op
 	opsSumSize                                 int
 	opsLastRotatedAt                           time.Time
 	opsArrayPool                               *opArrayPool
 	drainIn                                    chan []op
 	status                                     status
+
+	writeBatchV2Lock        sync.Mutex
+	writeBatchV2Support     writeBatchV2Support
+	writeBatchV2Probing     bool
+	writeBatchV2NextCheckAt time.Time
 }
 
 func newHostQueue(
@@ -91,6 +131,10 @@ func newHostQueue(
 	opts = opts.SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope))
 
 	size := opts.HostQueueOpsFlushSize()
+	maxSize := size
+	if opts.HostQueueOpsFlushMaxSize() > size {
+		maxSize = opts.HostQueueOpsFlushMaxSize()
+	}
 
 	opsArraysLen := opts.HostQueueOpsArrayPoolSize()
 	opArrayPoolOpts := pool.NewObjectPoolOptions().
@@ -98,7 +142,7 @@ func newHostQueue(
 		SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(
 			scope.SubScope("op-array-pool"),
 		))
-	opArrayPoolCapacity := int(math.Max(float64(size), float64(opts.WriteBatchSize())))
+	opArrayPoolCapacity := int(math.Max(float64(maxSize), float64(opts.WriteBatchSize())))
 	opArrayPool := newOpArrayPool(opArrayPoolOpts, opArrayPoolCapacity)
 	opArrayPool.Init()
 
@@ -111,11 +155,14 @@ func newHostQueue(
 		writeBatchRawRequestElementArrayPool:       hostQueueOpts.writeBatchRawRequestElementArrayPool,
 		writeTaggedBatchRawRequestPool:             hostQueueOpts.writeTaggedBatchRawRequestPool,
 		writeTaggedBatchRawRequestElementArrayPool: hostQueueOpts.writeTaggedBatchRawRequestElementArrayPool,
-		workerPool:   workerPool,
-		size:         size,
-		ops:          opArrayPool.Get(),
-		opsArrayPool: opArrayPool,
-		drainIn:      make(chan []op, opsArraysLen),
+		workerPool:    workerPool,
+		size:          size,
+		minSize:       size,
+		maxSize:       maxSize,
+		shrinkLatency: opts.HostQueueOpsFlushShrinkLatency(),
+		ops:           opArrayPool.Get(),
+		opsArrayPool:  opArrayPool,
+		drainIn:       make(chan []op, opsArraysLen),
 	}, nil
 }
 
@@ -174,6 +221,11 @@ func (q *queue) flushEvery(interval time.Duration) {
 			q.Unlock()
 			return
 		}
+		if latency := q.observedWriteLatency(); latency > 0 && latency < q.shrinkLatency {
+			// Write batches are completing quickly, shrink the flush
+			// size so that writes are not held back for larger batches
+			q.shrinkSizeWithLock()
+		}
 		needsDrain := q.rotateOpsWithLock()
 		// Need to hold lock while writing to the drainIn
 		// channel to ensure it has not been closed
@@ -200,6 +252,45 @@ func (q *queue) rotateOpsWithLock() []op {
 	return needsDrain
 }
 
+func (q *queue) growSizeWithLock() {
+	if q.size >= q.maxSize {
+		return
+	}
+	q.size *= 2
+	if q.size > q.maxSize {
+		q.size = q.maxSize
+	}
+}
+
+func (q *queue) shrinkSizeWithLock() {
+	if q.size <= q.minSize {
+		return
+	}
+	q.size /= 2
+	if q.size < q.minSize {
+		q.size = q.minSize
+	}
+}
+
+// observeWriteLatency folds the latency of a completed write batch into
+// the moving average used to decide when to shrink the flush size.
+func (q *queue) observeWriteLatency(latency time.Duration) {
+	for {
+		prev := atomic.LoadInt64(&q.writeLatency)
+		next := int64(latency)
+		if prev > 0 {
+			next = prev + (next-prev)/writeLatencyDecay
+		}
+		if atomic.CompareAndSwapInt64(&q.writeLatency, prev, next) {
+			return
+		}
+	}
+}
+
+func (q *queue) observedWriteLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&q.writeLatency))
+}
+
 func (q *queue) drain() {
 	var (
 		currWriteOpsByNamespace       namespaceWriteBatchOpsSlice
@@ -209,6 +300,12 @@ func (q *queue) drain() {
 
 	for ops := range q.drainIn {
 		opsLen := len(ops)
+		batchSize := writeBatchSize
+		if q.maxSize > q.minSize && opsLen > batchSize {
+			// With adaptive flush sizing send each flushed set of ops as
+			// a single batch per namespace so the batch grows with load
+			batchSize = opsLen
+		}
 		for i := 0; i < opsLen; i++ {
 			switch v := ops[i].(type) {
 			case *writeOperation:
@@ -226,7 +323,7 @@ func (q *queue) drain() {
 
 				currWriteOpsByNamespace.appendAt(idx, ops[i], &v.request)
 
-				if currWriteOpsByNamespace.lenAt(idx) == writeBatchSize {
+				if currWriteOpsByNamespace.lenAt(idx) == batchSize {
 					// Reached write batch limit, write async and reset
 					q.asyncWrite(namespace, currWriteOpsByNamespace[idx].ops,
 						currWriteOpsByNamespace[idx].elems)
@@ -247,7 +344,7 @@ func (q *queue) drain() {
 
 				currTaggedWriteOpsByNamespace.appendAt(idx, ops[i], &v.request)
 
-				if currTaggedWriteOpsByNamespace.lenAt(idx) == writeBatchSize {
+				if currTaggedWriteOpsByNamespace.lenAt(idx) == batchSize {
 					// Reached write batch limit, write async and reset
 					q.asyncTaggedWrite(namespace, currTaggedWriteOpsByNamespace[idx].ops,
 						currTaggedWriteOpsByNamespace[idx].elems)
@@ -333,7 +430,13 @@ func (q *queue) asyncTaggedWrite(
 		}
 
 		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
-		err = client.WriteTaggedBatchRaw(ctx, req)
+		start := q.nowFn()
+		if q.useWriteBatchV2(client) {
+			err = q.writeTaggedBatchRawV2(ctx, client, req)
+		} else {
+			err = client.WriteTaggedBatchRaw(ctx, req)
+		}
+		q.observeWriteLatency(q.nowFn().Sub(start))
 		if err == nil {
 			// All succeeded
 			callAllCompletionFns(ops, q.host, nil)
@@ -366,6 +469,88 @@ func (q *queue) asyncTaggedWrite(
 	})
 }
 
+func (q *queue) writeTaggedBatchRawV2(
+	ctx thrift.Context,
+	client rpc.TChanNode,
+	req *rpc.WriteTaggedBatchRawRequest,
+) error {
+	v2Req, err := convert.ToRPCWriteTaggedBatchRawV2Request(req.NameSpace,
+		req.Elements, q.opts.WriteBatchCompression())
+	if err != nil {
+		return err
+	}
+	err = client.WriteTaggedBatchRawV2(ctx, v2Req)
+	if isUnknownMethodError(err) {
+		// Renegotiate as the node was replaced with one that does not
+		// support the compressed write batch endpoint
+		q.writeBatchV2Lock.Lock()
+		q.writeBatchV2Support = writeBatchV2SupportUnknown
+		q.writeBatchV2NextCheckAt = time.Time{}
+		q.writeBatchV2Lock.Unlock()
+
+		// Retry the batch uncompressed so it is not failed for the switch
+		ctx, _ = thrift.NewContext(q.opts.WriteRequestTimeout())
+		return client.WriteTaggedBatchRaw(ctx, req)
+	}
+	return err
+}
+
+// useWriteBatchV2 returns whether tagged write batches should be sent
+// compressed and with deduplicated tags, negotiating support with the
+// node the first time and periodically after it was found unsupported.
+func (q *queue) useWriteBatchV2(client rpc.TChanNode) bool {
+	compression := q.opts.WriteBatchCompression()
+	if compression == rpc.WriteBatchCompression_NONE &&
+		!q.opts.WriteTaggedBatchDedupTags() {
+		return false
+	}
+
+	q.writeBatchV2Lock.Lock()
+	supported := q.writeBatchV2Support == writeBatchV2Supported
+	probe := !supported && !q.writeBatchV2Probing &&
+		!q.nowFn().Before(q.writeBatchV2NextCheckAt)
+	if probe {
+		q.writeBatchV2Probing = true
+	}
+	q.writeBatchV2Lock.Unlock()
+
+	if !probe {
+		return supported
+	}
+
+	// NB: the node is asked outside of the lock so that batches flushed
+	// concurrently are sent uncompressed rather than waiting for it.
+	ctx, _ := thrift.NewContext(q.opts.HostConnectTimeout())
+	result, err := client.Health(ctx)
+
+	q.writeBatchV2Lock.Lock()
+	defer q.writeBatchV2Lock.Unlock()
+
+	q.writeBatchV2Probing = false
+	if err != nil {
+		// Leave unknown and back off before asking the node again
+		q.writeBatchV2NextCheckAt = q.nowFn().Add(writeBatchV2ProbeErrorBackoff)
+		return false
+	}
+
+	q.writeBatchV2Support = writeBatchV2Unsupported
+	q.writeBatchV2NextCheckAt = q.nowFn().Add(writeBatchV2RecheckInterval)
+	for _, supported := range result.WriteBatchCompressions {
+		if supported == compression {
+			q.writeBatchV2Support = writeBatchV2Supported
+			break
+		}
+	}
+	return q.writeBatchV2Support == writeBatchV2Supported
+}
+
+// isUnknownMethodError returns whether the error was returned by a node
+// for a method it does not implement.
+func isUnknownMethodError(err error) bool {
+	sysErr, ok := err.(tchannel.SystemError)
+	return ok && strings.Contains(sysErr.Message(), unknownMethodErrMsg)
+}
+
 func (q *queue) asyncWrite(
 	namespace ident.ID,
 	ops []op,
@@ -397,7 +582,9 @@ func (q *queue) asyncWrite(
 		}
 
 		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
+		start := q.nowFn()
 		err = client.WriteBatchRaw(ctx, req)
+		q.observeWriteLatency(q.nowFn().Sub(start))
 		if err == nil {
 			// All succeeded
 			callAllCompletionFns(ops, q.host, nil)
@@ -596,6 +783,9 @@ func (q *queue) Enqueue(o op) error {
 	// If queue is full flush
 	if q.opsSumSize >= q.size {
 		needsDrain = q.rotateOpsWithLock()
+		// Queue filled before the flush interval elapsed, grow the flush
+		// size to send larger batches while under load
+		q.growSizeWithLock()
 	}
 	// Need to hold lock while writing to the drainIn
 	// channel to ensure it has not been closed
@@ -22,16 +22,20 @@ package client
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	"github.com/m3db/m3x/ident"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/tchannel-go"
 	"github.com/uber/tchannel-go/thrift"
 )
 
@@ -528,3 +532,240 @@ func testEncode(tags map[string]string) []byte {
 	}
 	return b
 }
+
+func TestHostQueueWriteTaggedBatchesCompressed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConnPool := NewMockconnectionPool(ctrl)
+
+	opts := newHostQueueTestOptions().
+		SetWriteBatchCompression(rpc.WriteBatchCompression_SNAPPY)
+	queue := newTestHostQueue(opts)
+	queue.connPool = mockConnPool
+
+	// Open
+	mockConnPool.EXPECT().Open()
+	queue.Open()
+	assert.Equal(t, statusOpen, queue.status)
+
+	// Prepare callback for writes
+	var (
+		results []hostQueueResult
+		wg      sync.WaitGroup
+	)
+	callback := func(r interface{}, err error) {
+		results = append(results, hostQueueResult{r, err})
+		wg.Done()
+	}
+
+	// Prepare writes with repeated tags
+	tags := map[string]string{"tag": "value"}
+	writes := []*writeTaggedOperation{
+		testWriteTaggedOp("testNs", "foo", tags, 1.0, 1000, rpc.TimeType_UNIX_SECONDS, callback),
+		testWriteTaggedOp("testNs", "bar", tags, 2.0, 2000, rpc.TimeType_UNIX_SECONDS, callback),
+		testWriteTaggedOp("testNs", "baz", tags, 3.0, 3000, rpc.TimeType_UNIX_SECONDS, callback),
+		testWriteTaggedOp("testNs", "qux", map[string]string{
+			"mas": "ter",
+		}, 4.0, 4000, rpc.TimeType_UNIX_SECONDS, callback),
+	}
+	wg.Add(len(writes))
+
+	// Prepare mocks for flush
+	mockClient := rpc.NewMockTChanNode(ctrl)
+	mockClient.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{
+		Ok: true,
+		WriteBatchCompressions: []rpc.WriteBatchCompression{
+			rpc.WriteBatchCompression_NONE,
+			rpc.WriteBatchCompression_SNAPPY,
+		},
+	}, nil)
+	writeBatch := func(ctx thrift.Context, req *rpc.WriteTaggedBatchRawV2Request) {
+		assert.Equal(t, rpc.WriteBatchCompression_SNAPPY, req.Compression)
+		decoded, err := convert.FromRPCWriteTaggedBatchRawV2Request(req, math.MaxInt32)
+		require.NoError(t, err)
+		assert.Equal(t, "testNs", string(decoded.NameSpace))
+		require.Equal(t, len(writes), len(decoded.Elements))
+		for i, write := range writes {
+			assert.Equal(t, write.request.ID, decoded.Elements[i].ID)
+			assert.Equal(t, write.request.Datapoint, decoded.Elements[i].Datapoint)
+			assert.Equal(t, write.request.EncodedTags, decoded.Elements[i].EncodedTags)
+		}
+	}
+	mockClient.EXPECT().WriteTaggedBatchRawV2(gomock.Any(), gomock.Any()).Do(writeBatch).Return(nil)
+
+	mockConnPool.EXPECT().NextClient().Return(mockClient, nil)
+
+	for _, write := range writes {
+		assert.NoError(t, queue.Enqueue(write))
+	}
+
+	// Wait for all writes
+	wg.Wait()
+
+	// Assert writes successful
+	assert.Equal(t, len(writes), len(results))
+	for _, result := range results {
+		assert.Nil(t, result.err)
+	}
+
+	// Close
+	var closeWg sync.WaitGroup
+	closeWg.Add(1)
+	mockConnPool.EXPECT().Close().Do(func() {
+		closeWg.Done()
+	})
+	queue.Close()
+	closeWg.Wait()
+}
+
+func TestHostQueueWriteTaggedBatchesCompressionUnsupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConnPool := NewMockconnectionPool(ctrl)
+
+	opts := newHostQueueTestOptions().
+		SetWriteTaggedBatchDedupTags(true)
+	queue := newTestHostQueue(opts)
+	queue.connPool = mockConnPool
+
+	// Open
+	mockConnPool.EXPECT().Open()
+	queue.Open()
+	assert.Equal(t, statusOpen, queue.status)
+
+	// Prepare callback for writes
+	var wg sync.WaitGroup
+	callback := func(r interface{}, err error) {
+		assert.NoError(t, err)
+		wg.Done()
+	}
+
+	// Two flushes of a full queue, only the first negotiates with the node
+	var writes []*writeTaggedOperation
+	for i := 0; i < 8; i++ {
+		writes = append(writes, testWriteTaggedOp("testNs", fmt.Sprintf("foo%d", i),
+			map[string]string{"tag": "value"}, float64(i), int64(1000+i),
+			rpc.TimeType_UNIX_SECONDS, callback))
+	}
+	wg.Add(len(writes))
+
+	// Prepare mocks for flush, node predates write batch compression
+	mockClient := rpc.NewMockTChanNode(ctrl)
+	mockClient.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	mockClient.EXPECT().WriteTaggedBatchRaw(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	mockConnPool.EXPECT().NextClient().Return(mockClient, nil).Times(2)
+
+	for _, write := range writes {
+		assert.NoError(t, queue.Enqueue(write))
+	}
+
+	// Wait for all writes
+	wg.Wait()
+
+	// Close
+	var closeWg sync.WaitGroup
+	closeWg.Add(1)
+	mockConnPool.EXPECT().Close().Do(func() {
+		closeWg.Done()
+	})
+	queue.Close()
+	closeWg.Wait()
+}
+
+func TestHostQueueWriteBatchV2Negotiation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newHostQueueTestOptions().
+		SetWriteBatchCompression(rpc.WriteBatchCompression_SNAPPY)
+	queue := newTestHostQueue(opts)
+
+	now := time.Now()
+	queue.nowFn = func() time.Time { return now }
+
+	mockClient := rpc.NewMockTChanNode(ctrl)
+
+	// Failing to ask the node backs off rather than asking with every batch
+	mockClient.EXPECT().Health(gomock.Any()).Return(nil, tchannel.ErrTimeout)
+	assert.False(t, queue.useWriteBatchV2(mockClient))
+	assert.False(t, queue.useWriteBatchV2(mockClient))
+
+	now = now.Add(writeBatchV2ProbeErrorBackoff)
+	mockClient.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{
+		Ok: true,
+		WriteBatchCompressions: []rpc.WriteBatchCompression{
+			rpc.WriteBatchCompression_SNAPPY,
+		},
+	}, nil)
+	assert.True(t, queue.useWriteBatchV2(mockClient))
+
+	// Timeouts do not renegotiate
+	req := &rpc.WriteTaggedBatchRawRequest{NameSpace: []byte("testNs")}
+	mockClient.EXPECT().WriteTaggedBatchRawV2(gomock.Any(), gomock.Any()).
+		Return(tchannel.ErrTimeout)
+	assert.Error(t, queue.writeTaggedBatchRawV2(nil, mockClient, req))
+	assert.True(t, queue.useWriteBatchV2(mockClient))
+
+	// Nodes that do not implement the endpoint are sent the batch
+	// uncompressed and asked again
+	mockClient.EXPECT().WriteTaggedBatchRawV2(gomock.Any(), gomock.Any()).
+		Return(tchannel.NewSystemError(tchannel.ErrCodeUnexpected,
+			"method writeTaggedBatchRawV2 not found in service Node"))
+	mockClient.EXPECT().WriteTaggedBatchRaw(gomock.Any(), req).Return(nil)
+	assert.NoError(t, queue.writeTaggedBatchRawV2(nil, mockClient, req))
+
+	mockClient.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	assert.False(t, queue.useWriteBatchV2(mockClient))
+	assert.False(t, queue.useWriteBatchV2(mockClient))
+}
+
+func TestHostQueueAdaptiveFlushSize(t *testing.T) {
+	opts := newHostQueueTestOptions().
+		SetHostQueueOpsFlushMaxSize(16)
+	queue := newTestHostQueue(opts)
+
+	assert.Equal(t, 4, queue.size)
+
+	// Grows under load up to the max
+	queue.growSizeWithLock()
+	assert.Equal(t, 8, queue.size)
+	queue.growSizeWithLock()
+	queue.growSizeWithLock()
+	assert.Equal(t, 16, queue.size)
+
+	// Shrinks down to the flush size
+	queue.shrinkSizeWithLock()
+	assert.Equal(t, 8, queue.size)
+	queue.shrinkSizeWithLock()
+	queue.shrinkSizeWithLock()
+	assert.Equal(t, 4, queue.size)
+
+	// Disabled without a max size
+	queue = newTestHostQueue(newHostQueueTestOptions())
+	queue.growSizeWithLock()
+	assert.Equal(t, 4, queue.size)
+}
+
+func TestHostQueueWriteLatency(t *testing.T) {
+	opts := newHostQueueTestOptions().
+		SetHostQueueOpsFlushShrinkLatency(10 * time.Millisecond)
+	queue := newTestHostQueue(opts)
+
+	assert.Equal(t, time.Duration(0), queue.observedWriteLatency())
+	assert.Equal(t, 10*time.Millisecond, queue.shrinkLatency)
+
+	// First observation is taken as is
+	queue.observeWriteLatency(20 * time.Millisecond)
+	assert.Equal(t, 20*time.Millisecond, queue.observedWriteLatency())
+
+	// Later observations are folded into the moving average
+	queue.observeWriteLatency(4 * time.Millisecond)
+	assert.Equal(t, 16*time.Millisecond, queue.observedWriteLatency())
+	for i := 0; i < 8; i++ {
+		queue.observeWriteLatency(4 * time.Millisecond)
+	}
+	assert.True(t, queue.observedWriteLatency() < queue.shrinkLatency)
+}
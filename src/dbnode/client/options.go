@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/x/serialize"
@@ -103,6 +104,21 @@ const (
 	// defaultHostQueueOpsFlushInterval is the default host queue flush interval
 	defaultHostQueueOpsFlushInterval = 5 * time.Millisecond
 
+	// defaultHostQueueOpsFlushMaxSize is the default host queue ops flush max size,
+	// the default of zero disables adaptive flush sizing
+	defaultHostQueueOpsFlushMaxSize = 0
+
+	// defaultHostQueueOpsFlushShrinkLatency is the default write batch latency
+	// below which adaptive flush sizes shrink
+	defaultHostQueueOpsFlushShrinkLatency = 10 * time.Millisecond
+
+	// defaultWriteBatchCompression is the default tagged write batch compression
+	defaultWriteBatchCompression = rpc.WriteBatchCompression_NONE
+
+	// defaultWriteTaggedBatchDedupTags is the default for deduplicating tags
+	// across a tagged write batch
+	defaultWriteTaggedBatchDedupTags = false
+
 	// defaultHostQueueOpsArrayPoolSize is the default host queue ops array pool size
 	defaultHostQueueOpsArrayPoolSize = 8
 
@@ -149,6 +165,18 @@ const (
 
 	// defaultFetchSeriesBlocksMetadataBatchTimeout is the default series blocks contents fetch timeout
 	defaultFetchSeriesBlocksBatchTimeout = 60 * time.Second
+
+	// defaultFetchSeriesBlocksPeerLimitMbps is the default per peer bandwidth limit
+	// when fetching series blocks, zero means unlimited
+	defaultFetchSeriesBlocksPeerLimitMbps = 0.0
+
+	// defaultFetchSeriesBlocksAdaptiveBatchSize is the default for whether to adapt
+	// the fetch series blocks batch size to peer response times
+	defaultFetchSeriesBlocksAdaptiveBatchSize = false
+
+	// defaultFetchSeriesBlocksBatchTargetLatency is the default target latency
+	// of a single fetch series blocks batch when adapting batch sizes
+	defaultFetchSeriesBlocksBatchTargetLatency = 5 * time.Second
 )
 
 var (
@@ -188,8 +216,12 @@ var (
 			SetJitter(true),
 	)
 
-	errNoTopologyInitializerSet    = errors.New("no topology initializer set")
-	errNoReaderIteratorAllocateSet = errors.New("no reader iterator allocator set, encoding not set")
+	errNoTopologyInitializerSet     = errors.New("no topology initializer set")
+	errNoReaderIteratorAllocateSet  = errors.New("no reader iterator allocator set, encoding not set")
+	errNegativePeerLimitMbps        = errors.New("fetch series blocks peer limit mbps is negative")
+	errNonPositiveBatchLatency      = errors.New("fetch series blocks batch target latency is not positive")
+	errHostQueueOpsFlushMaxSize     = errors.New("host queue ops flush max size is less than flush size")
+	errUnknownWriteBatchCompression = errors.New("unknown write batch compression")
 )
 
 type options struct {
@@ -230,6 +262,10 @@ type options struct {
 	fetchBatchSize                          int
 	identifierPool                          ident.Pool
 	hostQueueOpsFlushSize                   int
+	hostQueueOpsFlushMaxSize                int
+	hostQueueOpsFlushShrinkLatency          time.Duration
+	writeBatchCompression                   rpc.WriteBatchCompression
+	writeTaggedBatchDedupTags               bool
 	hostQueueOpsFlushInterval               time.Duration
 	hostQueueOpsArrayPoolSize               int
 	seriesIteratorPoolSize                  int
@@ -242,6 +278,9 @@ type options struct {
 	fetchSeriesBlocksMetadataBatchTimeout   time.Duration
 	fetchSeriesBlocksBatchTimeout           time.Duration
 	fetchSeriesBlocksBatchConcurrency       int
+	fetchSeriesBlocksPeerLimitMbps          float64
+	fetchSeriesBlocksAdaptiveBatchSize      bool
+	fetchSeriesBlocksBatchTargetLatency     time.Duration
 }
 
 // NewOptions creates a new set of client options with defaults
@@ -309,6 +348,10 @@ func newOptions() *options {
 		fetchBatchSize:                          defaultFetchBatchSize,
 		identifierPool:                          idPool,
 		hostQueueOpsFlushSize:                   defaultHostQueueOpsFlushSize,
+		hostQueueOpsFlushMaxSize:                defaultHostQueueOpsFlushMaxSize,
+		hostQueueOpsFlushShrinkLatency:          defaultHostQueueOpsFlushShrinkLatency,
+		writeBatchCompression:                   defaultWriteBatchCompression,
+		writeTaggedBatchDedupTags:               defaultWriteTaggedBatchDedupTags,
 		hostQueueOpsFlushInterval:               defaultHostQueueOpsFlushInterval,
 		hostQueueOpsArrayPoolSize:               defaultHostQueueOpsArrayPoolSize,
 		seriesIteratorPoolSize:                  defaultSeriesIteratorPoolSize,
@@ -320,6 +363,9 @@ func newOptions() *options {
 		fetchSeriesBlocksMetadataBatchTimeout:   defaultFetchSeriesBlocksMetadataBatchTimeout,
 		fetchSeriesBlocksBatchTimeout:           defaultFetchSeriesBlocksBatchTimeout,
 		fetchSeriesBlocksBatchConcurrency:       defaultFetchSeriesBlocksBatchConcurrency,
+		fetchSeriesBlocksPeerLimitMbps:          defaultFetchSeriesBlocksPeerLimitMbps,
+		fetchSeriesBlocksAdaptiveBatchSize:      defaultFetchSeriesBlocksAdaptiveBatchSize,
+		fetchSeriesBlocksBatchTargetLatency:     defaultFetchSeriesBlocksBatchTargetLatency,
 	}
 	return opts.SetEncodingM3TSZ().(*options)
 }
@@ -346,6 +392,19 @@ func (o *options) Validate() error {
 	); err != nil {
 		return err
 	}
+	if o.fetchSeriesBlocksPeerLimitMbps < 0 {
+		return errNegativePeerLimitMbps
+	}
+	if o.fetchSeriesBlocksBatchTargetLatency <= 0 {
+		return errNonPositiveBatchLatency
+	}
+	if o.hostQueueOpsFlushMaxSize > 0 &&
+		o.hostQueueOpsFlushMaxSize < o.hostQueueOpsFlushSize {
+		return errHostQueueOpsFlushMaxSize
+	}
+	if o.writeBatchCompression.String() == "<UNSET>" {
+		return errUnknownWriteBatchCompression
+	}
 	return topology.ValidateConnectConsistencyLevel(
 		o.clusterConnectConsistencyLevel,
 	)
@@ -749,6 +808,46 @@ func (o *options) HostQueueOpsFlushInterval() time.Duration {
 	return o.hostQueueOpsFlushInterval
 }
 
+func (o *options) SetHostQueueOpsFlushMaxSize(value int) Options {
+	opts := *o
+	opts.hostQueueOpsFlushMaxSize = value
+	return &opts
+}
+
+func (o *options) HostQueueOpsFlushMaxSize() int {
+	return o.hostQueueOpsFlushMaxSize
+}
+
+func (o *options) SetHostQueueOpsFlushShrinkLatency(value time.Duration) Options {
+	opts := *o
+	opts.hostQueueOpsFlushShrinkLatency = value
+	return &opts
+}
+
+func (o *options) HostQueueOpsFlushShrinkLatency() time.Duration {
+	return o.hostQueueOpsFlushShrinkLatency
+}
+
+func (o *options) SetWriteBatchCompression(value rpc.WriteBatchCompression) Options {
+	opts := *o
+	opts.writeBatchCompression = value
+	return &opts
+}
+
+func (o *options) WriteBatchCompression() rpc.WriteBatchCompression {
+	return o.writeBatchCompression
+}
+
+func (o *options) SetWriteTaggedBatchDedupTags(value bool) Options {
+	opts := *o
+	opts.writeTaggedBatchDedupTags = value
+	return &opts
+}
+
+func (o *options) WriteTaggedBatchDedupTags() bool {
+	return o.writeTaggedBatchDedupTags
+}
+
 func (o *options) SetHostQueueOpsArrayPoolSize(value int) Options {
 	opts := *o
 	opts.hostQueueOpsArrayPoolSize = value
@@ -848,3 +947,33 @@ func (o *options) SetFetchSeriesBlocksBatchConcurrency(value int) AdminOptions {
 func (o *options) FetchSeriesBlocksBatchConcurrency() int {
 	return o.fetchSeriesBlocksBatchConcurrency
 }
+
+func (o *options) SetFetchSeriesBlocksPeerLimitMbps(value float64) AdminOptions {
+	opts := *o
+	opts.fetchSeriesBlocksPeerLimitMbps = value
+	return &opts
+}
+
+func (o *options) FetchSeriesBlocksPeerLimitMbps() float64 {
+	return o.fetchSeriesBlocksPeerLimitMbps
+}
+
+func (o *options) SetFetchSeriesBlocksAdaptiveBatchSize(value bool) AdminOptions {
+	opts := *o
+	opts.fetchSeriesBlocksAdaptiveBatchSize = value
+	return &opts
+}
+
+func (o *options) FetchSeriesBlocksAdaptiveBatchSize() bool {
+	return o.fetchSeriesBlocksAdaptiveBatchSize
+}
+
+func (o *options) SetFetchSeriesBlocksBatchTargetLatency(value time.Duration) AdminOptions {
+	opts := *o
+	opts.fetchSeriesBlocksBatchTargetLatency = value
+	return &opts
+}
+
+func (o *options) FetchSeriesBlocksBatchTargetLatency() time.Duration {
+	return o.fetchSeriesBlocksBatchTargetLatency
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+)
+
+const (
+	bytesPerMegabit = 1024 * 1024 / 8
+
+	// peerStreamMinBatchSize is the smallest batch size adaptive
+	// batch sizing will shrink a peer's batch size to.
+	peerStreamMinBatchSize = 32
+
+	// peerStreamBatchSizeIncreaseDivisor determines how quickly the batch size
+	// grows back towards the max batch size, a fraction of the max batch size
+	// is added each time a full batch returns well within the target latency.
+	peerStreamBatchSizeIncreaseDivisor = 16
+)
+
+// peerStreamControl controls the rate at which blocks are streamed from a
+// single peer. It adapts the batch size to the latency of the peer's responses
+// (additive increase, multiplicative decrease) and paces fetches so that the
+// bytes received from the peer stay under a bandwidth limit.
+type peerStreamControl struct {
+	sync.Mutex
+
+	nowFn         clock.NowFn
+	limitMbps     float64
+	adaptive      bool
+	targetLatency time.Duration
+	minBatchSize  int
+	maxBatchSize  int
+	batchSize     int
+	allowedAt     time.Time
+}
+
+type peerStreamControlOptions struct {
+	nowFn         clock.NowFn
+	limitMbps     float64
+	adaptive      bool
+	targetLatency time.Duration
+	maxBatchSize  int
+}
+
+// newPeerStreamControl returns a new peer stream control, or nil if neither
+// a bandwidth limit nor adaptive batch sizing is enabled.
+func newPeerStreamControl(opts peerStreamControlOptions) *peerStreamControl {
+	if opts.limitMbps <= 0 && !opts.adaptive {
+		return nil
+	}
+	minBatchSize := peerStreamMinBatchSize
+	if minBatchSize > opts.maxBatchSize {
+		minBatchSize = opts.maxBatchSize
+	}
+	return &peerStreamControl{
+		nowFn:         opts.nowFn,
+		limitMbps:     opts.limitMbps,
+		adaptive:      opts.adaptive,
+		targetLatency: opts.targetLatency,
+		minBatchSize:  minBatchSize,
+		maxBatchSize:  opts.maxBatchSize,
+		batchSize:     opts.maxBatchSize,
+	}
+}
+
+// BatchSize returns the number of blocks to fetch in the next batch.
+func (c *peerStreamControl) BatchSize() int {
+	c.Lock()
+	v := c.batchSize
+	c.Unlock()
+	return v
+}
+
+// Throttled returns whether fetching from the peer is currently paused
+// to keep within the bandwidth limit.
+func (c *peerStreamControl) Throttled() bool {
+	c.Lock()
+	v := c.nowFn().Before(c.allowedAt)
+	c.Unlock()
+	return v
+}
+
+// BatchComplete records a completed batch fetch of the given number of
+// blocks which returned the given number of bytes and took the given time.
+func (c *peerStreamControl) BatchComplete(
+	numBlocks int,
+	numBytes int64,
+	took time.Duration,
+) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.adaptive {
+		switch {
+		case took > c.targetLatency:
+			c.batchSize /= 2
+			if c.batchSize < c.minBatchSize {
+				c.batchSize = c.minBatchSize
+			}
+		case took < c.targetLatency/2 && numBlocks >= c.batchSize:
+			increase := c.maxBatchSize / peerStreamBatchSizeIncreaseDivisor
+			if increase < 1 {
+				increase = 1
+			}
+			c.batchSize += increase
+			if c.batchSize > c.maxBatchSize {
+				c.batchSize = c.maxBatchSize
+			}
+		}
+	}
+
+	if c.limitMbps > 0 && numBytes > 0 {
+		// Pace fetches so that the transfer time at the bandwidth limit of
+		// all batches accumulates, batches fetched concurrently are accounted
+		// back to back.
+		transfer := time.Duration(float64(time.Second) *
+			float64(numBytes) / (c.limitMbps * bytesPerMegabit))
+		start := c.nowFn().Add(-took)
+		if c.allowedAt.Before(start) {
+			c.allowedAt = start
+		}
+		c.allowedAt = c.allowedAt.Add(transfer)
+	}
+}
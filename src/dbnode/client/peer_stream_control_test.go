@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3x/ident"
+	xsync "github.com/m3db/m3x/sync"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerStreamControlDisabled(t *testing.T) {
+	control := newPeerStreamControl(peerStreamControlOptions{
+		nowFn:        time.Now,
+		maxBatchSize: 128,
+	})
+	assert.Nil(t, control)
+}
+
+func TestPeerStreamControlAdaptiveBatchSize(t *testing.T) {
+	now := time.Now()
+	control := newPeerStreamControl(peerStreamControlOptions{
+		nowFn:         func() time.Time { return now },
+		adaptive:      true,
+		targetLatency: time.Second,
+		maxBatchSize:  1024,
+	})
+	require.NotNil(t, control)
+	assert.Equal(t, 1024, control.BatchSize())
+
+	// Slow batches halve the batch size down to the minimum
+	control.BatchComplete(1024, 0, 2*time.Second)
+	assert.Equal(t, 512, control.BatchSize())
+	for i := 0; i < 10; i++ {
+		control.BatchComplete(control.BatchSize(), 0, 2*time.Second)
+	}
+	assert.Equal(t, peerStreamMinBatchSize, control.BatchSize())
+
+	// Partial batches returning quickly do not grow the batch size
+	control.BatchComplete(1, 0, time.Millisecond)
+	assert.Equal(t, peerStreamMinBatchSize, control.BatchSize())
+
+	// Full batches returning quickly grow the batch size up to the max
+	control.BatchComplete(peerStreamMinBatchSize, 0, time.Millisecond)
+	assert.Equal(t, peerStreamMinBatchSize+64, control.BatchSize())
+	for i := 0; i < 100; i++ {
+		control.BatchComplete(control.BatchSize(), 0, time.Millisecond)
+	}
+	assert.Equal(t, 1024, control.BatchSize())
+
+	// Batches within the target latency but not well within it hold steady
+	control.BatchComplete(1024, 0, 750*time.Millisecond)
+	assert.Equal(t, 1024, control.BatchSize())
+	assert.False(t, control.Throttled())
+}
+
+func TestPeerStreamControlBandwidthLimit(t *testing.T) {
+	now := time.Now()
+	control := newPeerStreamControl(peerStreamControlOptions{
+		nowFn:         func() time.Time { return now },
+		limitMbps:     8,
+		targetLatency: time.Second,
+		maxBatchSize:  1024,
+	})
+	require.NotNil(t, control)
+	assert.False(t, control.Throttled())
+
+	// 8mbps is 1MiB per second, receiving 2MiB in 500ms should
+	// pause fetching for another 1.5s
+	control.BatchComplete(1024, 2*1024*1024, 500*time.Millisecond)
+	assert.True(t, control.Throttled())
+
+	now = now.Add(1400 * time.Millisecond)
+	assert.True(t, control.Throttled())
+
+	now = now.Add(100 * time.Millisecond)
+	assert.False(t, control.Throttled())
+
+	// Concurrent batches are accounted back to back
+	control.BatchComplete(1024, 1024*1024, 100*time.Millisecond)
+	control.BatchComplete(1024, 1024*1024, 100*time.Millisecond)
+	now = now.Add(1800 * time.Millisecond)
+	assert.True(t, control.Throttled())
+	now = now.Add(200 * time.Millisecond)
+	assert.False(t, control.Throttled())
+
+	// Batch size is not adapted when only limiting bandwidth
+	assert.Equal(t, 1024, control.BatchSize())
+}
+
+func TestPeerBlocksQueueBatchesAndBackpressureWhileThrottled(t *testing.T) {
+	var (
+		nowLock sync.Mutex
+		now     = time.Now()
+		nowFn   = func() time.Time {
+			nowLock.Lock()
+			defer nowLock.Unlock()
+			return now
+		}
+		batches = make(chan int, 8)
+		workers = xsync.NewWorkerPool(2)
+	)
+	workers.Init()
+
+	control := newPeerStreamControl(peerStreamControlOptions{
+		nowFn:        nowFn,
+		limitMbps:    1,
+		maxBatchSize: 2,
+	})
+	require.NotNil(t, control)
+
+	q := newPeerBlocksQueue(nil, 2, 0, workers, func(batch []receivedBlockMetadata) {
+		batches <- len(batch)
+	})
+	q.setStreamControl(control)
+
+	// Pause fetching for a second
+	control.BatchComplete(2, bytesPerMegabit, 0)
+	require.True(t, control.Throttled())
+
+	block := receivedBlockMetadata{id: ident.StringID("foo")}
+	q.enqueue(block, nil)
+	q.enqueue(block, nil)
+
+	// A full batch is waiting so enqueueing more blocks until it is drained
+	enqueued := make(chan struct{})
+	go func() {
+		q.enqueue(block, nil)
+		close(enqueued)
+	}()
+	select {
+	case <-enqueued:
+		require.FailNow(t, "expected enqueue to block while throttled")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	nowLock.Lock()
+	now = now.Add(time.Second)
+	nowLock.Unlock()
+	q.drain()
+	<-enqueued
+	assert.Equal(t, 2, <-batches)
+
+	q.drain()
+	assert.Equal(t, 1, <-batches)
+}
@@ -149,6 +149,9 @@ type session struct {
 	streamBlocksBatchSize            int
 	streamBlocksMetadataBatchTimeout time.Duration
 	streamBlocksBatchTimeout         time.Duration
+	streamBlocksPeerLimitMbps        float64
+	streamBlocksAdaptiveBatchSize    bool
+	streamBlocksBatchTargetLatency   time.Duration
 	metrics                          sessionMetrics
 }
 
@@ -312,6 +315,9 @@ func newSession(opts Options) (clientSession, error) {
 		s.streamBlocksBatchSize = opts.FetchSeriesBlocksBatchSize()
 		s.streamBlocksMetadataBatchTimeout = opts.FetchSeriesBlocksMetadataBatchTimeout()
 		s.streamBlocksBatchTimeout = opts.FetchSeriesBlocksBatchTimeout()
+		s.streamBlocksPeerLimitMbps = opts.FetchSeriesBlocksPeerLimitMbps()
+		s.streamBlocksAdaptiveBatchSize = opts.FetchSeriesBlocksAdaptiveBatchSize()
+		s.streamBlocksBatchTargetLatency = opts.FetchSeriesBlocksBatchTargetLatency()
 		s.streamBlocksRetrier = opts.StreamBlocksRetrier()
 	}
 
@@ -2349,11 +2355,23 @@ func (s *session) streamBlocksFromPeers(
 		size := peerBlocksBatchSize
 		workers := s.streamBlocksWorkers
 		drainEvery := 100 * time.Millisecond
+		control := newPeerStreamControl(peerStreamControlOptions{
+			nowFn:         s.nowFn,
+			limitMbps:     s.streamBlocksPeerLimitMbps,
+			adaptive:      s.streamBlocksAdaptiveBatchSize,
+			targetLatency: s.streamBlocksBatchTargetLatency,
+			maxBatchSize:  size,
+		})
 		queue := s.newPeerBlocksQueueFn(peer, size, drainEvery, workers,
 			func(batch []receivedBlockMetadata) {
-				s.streamBlocksBatchFromPeer(nsMetadata, shard, peer, batch, opts,
-					result, enqueueCh, s.streamBlocksRetrier, progress)
+				start := s.nowFn()
+				numBytes := s.streamBlocksBatchFromPeer(nsMetadata, shard, peer,
+					batch, opts, result, enqueueCh, s.streamBlocksRetrier, progress)
+				if control != nil {
+					control.BatchComplete(len(batch), numBytes, s.nowFn().Sub(start))
+				}
 			})
+		queue.setStreamControl(control)
 		peerQueues = append(peerQueues, queue)
 	}
 
@@ -2780,7 +2798,7 @@ func (s *session) streamBlocksBatchFromPeer(
 	enqueueCh enqueueChannel,
 	retrier xretry.Retrier,
 	m *streamFromPeersMetrics,
-) {
+) int64 {
 	// Prepare request
 	var (
 		req          = rpc.NewFetchBlocksRawRequest()
@@ -2808,7 +2826,7 @@ func (s *session) streamBlocksBatchFromPeer(
 	}
 	if reqBlocksLen == 0 {
 		// All blocks fell out of retention while streaming
-		return
+		return 0
 	}
 
 	// Attempt request
@@ -2836,11 +2854,14 @@ func (s *session) streamBlocksBatchFromPeer(
 			reqErrReason, nextRetryReattemptType, m)
 		m.fetchBlockError.Inc(int64(reqBlocksLen))
 		s.log.Debugf(blocksErr.Error())
-		return
+		return 0
 	}
 
 	// Parse and act on result
-	tooManyIDsLogged := false
+	var (
+		tooManyIDsLogged = false
+		numBytes         = fetchBlocksRawResultBytes(result)
+	)
 	for i := range result.Elements {
 		if i >= len(batch) {
 			m.fetchBlockError.Inc(int64(len(req.Elements[i].Starts)))
@@ -2937,6 +2958,26 @@ func (s *session) streamBlocksBatchFromPeer(
 			m.fetchBlockSuccess.Inc(1)
 		}
 	}
+
+	return numBytes
+}
+
+func fetchBlocksRawResultBytes(result *rpc.FetchBlocksRawResult_) int64 {
+	var numBytes int64
+	for _, elem := range result.Elements {
+		for _, block := range elem.Blocks {
+			if block.Segments == nil {
+				continue
+			}
+			if merged := block.Segments.Merged; merged != nil {
+				numBytes += int64(len(merged.Head) + len(merged.Tail))
+			}
+			for _, segment := range block.Segments.Unmerged {
+				numBytes += int64(len(segment.Head) + len(segment.Tail))
+			}
+		}
+	}
+	return numBytes
 }
 
 func (s *session) verifyFetchedBlock(block *rpc.Block) error {
@@ -3513,6 +3554,8 @@ type peerBlocksQueue struct {
 	maxQueueSize int
 	workers      xsync.WorkerPool
 	processFn    processFn
+	control      *peerStreamControl
+	drained      *sync.Cond
 }
 
 type newPeerBlocksQueueFn func(
@@ -3536,12 +3579,28 @@ func newPeerBlocksQueue(
 		workers:      workers,
 		processFn:    processFn,
 	}
+	q.drained = sync.NewCond(q)
 	if interval > 0 {
 		go q.drainEvery(interval)
 	}
 	return q
 }
 
+// setStreamControl sets the optional control used to adapt the batch size
+// and throttle fetching from the peer.
+func (q *peerBlocksQueue) setStreamControl(control *peerStreamControl) {
+	q.Lock()
+	q.control = control
+	q.Unlock()
+}
+
+func (q *peerBlocksQueue) batchSizeWithLock() int {
+	if q.control == nil {
+		return q.maxQueueSize
+	}
+	return q.control.BatchSize()
+}
+
 func (q *peerBlocksQueue) drainEvery(interval time.Duration) {
 	for {
 		q.Lock()
@@ -3559,6 +3618,7 @@ func (q *peerBlocksQueue) close() {
 	q.Lock()
 	defer q.Unlock()
 	q.closed = true
+	q.drained.Broadcast()
 }
 
 func (q *peerBlocksQueue) trackAssigned(amount int) {
@@ -3572,6 +3632,12 @@ func (q *peerBlocksQueue) trackCompleted(amount int) {
 func (q *peerBlocksQueue) enqueue(bl receivedBlockMetadata, doneFn func()) {
 	q.Lock()
 
+	// Apply backpressure while throttled and a batch is already waiting so
+	// that the queue does not grow unbounded, the periodic drain wakes us
+	for !q.closed && q.throttledWithLock() && len(q.queue) >= q.batchSizeWithLock() {
+		q.drained.Wait()
+	}
+
 	if len(q.queue) == 0 && cap(q.queue) < q.maxQueueSize {
 		// Lazy initialize queue
 		q.queue = make([]receivedBlockMetadata, 0, q.maxQueueSize)
@@ -3581,13 +3647,12 @@ func (q *peerBlocksQueue) enqueue(bl receivedBlockMetadata, doneFn func()) {
 		q.doneFns = make([]func(), 0, q.maxQueueSize)
 	}
 	q.queue = append(q.queue, bl)
-	if doneFn != nil {
-		q.doneFns = append(q.doneFns, doneFn)
-	}
+	// Keep the done callbacks aligned with the queue so batches can be split
+	q.doneFns = append(q.doneFns, doneFn)
 	q.trackAssigned(1)
 
 	// Determine if should drain immediately
-	if len(q.queue) < q.maxQueueSize {
+	if len(q.queue) < q.batchSizeWithLock() {
 		// Require more to fill up block
 		q.Unlock()
 		return
@@ -3603,24 +3668,44 @@ func (q *peerBlocksQueue) drain() {
 	q.Unlock()
 }
 
+func (q *peerBlocksQueue) throttledWithLock() bool {
+	return q.control != nil && q.control.Throttled()
+}
+
 func (q *peerBlocksQueue) drainWithLock() {
 	if len(q.queue) == 0 {
 		// None to drain
 		return
 	}
-	enqueued := q.queue
-	doneFns := q.doneFns
+	for len(q.queue) > 0 {
+		if q.throttledWithLock() {
+			// Over the bandwidth limit for the peer, the periodic
+			// drain will fetch the queued blocks once the pause elapses
+			return
+		}
+		size := q.batchSizeWithLock()
+		if size <= 0 || size > len(q.queue) {
+			size = len(q.queue)
+		}
+		enqueued := q.queue[:size:size]
+		doneFns := q.doneFns[:size:size]
+		q.queue = q.queue[size:]
+		q.doneFns = q.doneFns[size:]
+		q.workers.Go(func() {
+			q.processFn(enqueued)
+			// Call done callbacks
+			for i := range doneFns {
+				if doneFns[i] != nil {
+					doneFns[i]()
+				}
+			}
+			// Track completed blocks
+			q.trackCompleted(len(enqueued))
+		})
+	}
 	q.queue = nil
 	q.doneFns = nil
-	q.workers.Go(func() {
-		q.processFn(enqueued)
-		// Call done callbacks
-		for i := range doneFns {
-			doneFns[i]()
-		}
-		// Track completed blocks
-		q.trackCompleted(len(enqueued))
-	})
+	q.drained.Broadcast()
 }
 
 type peerBlocksQueues []*peerBlocksQueue
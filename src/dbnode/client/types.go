@@ -461,6 +461,38 @@ type Options interface {
 	// HostQueueOpsFlushInterval returns the hostQueueOpsFlushInterval.
 	HostQueueOpsFlushInterval() time.Duration
 
+	// SetHostQueueOpsFlushMaxSize sets the hostQueueOpsFlushMaxSize, when set
+	// greater than the hostQueueOpsFlushSize the flush size of each host queue
+	// adapts between the two, growing under load and shrinking while write
+	// batches complete within the hostQueueOpsFlushShrinkLatency.
+	SetHostQueueOpsFlushMaxSize(value int) Options
+
+	// HostQueueOpsFlushMaxSize returns the hostQueueOpsFlushMaxSize.
+	HostQueueOpsFlushMaxSize() int
+
+	// SetHostQueueOpsFlushShrinkLatency sets the hostQueueOpsFlushShrinkLatency,
+	// the write batch latency below which adaptive flush sizes shrink.
+	SetHostQueueOpsFlushShrinkLatency(value time.Duration) Options
+
+	// HostQueueOpsFlushShrinkLatency returns the hostQueueOpsFlushShrinkLatency.
+	HostQueueOpsFlushShrinkLatency() time.Duration
+
+	// SetWriteBatchCompression sets the compression to request for tagged
+	// write batches, it is only used against nodes that advertise support.
+	SetWriteBatchCompression(value rpc.WriteBatchCompression) Options
+
+	// WriteBatchCompression returns the writeBatchCompression.
+	WriteBatchCompression() rpc.WriteBatchCompression
+
+	// SetWriteTaggedBatchDedupTags sets whether to deduplicate identical encoded
+	// tags across a tagged write batch, it is only used against nodes that
+	// advertise support.
+	SetWriteTaggedBatchDedupTags(value bool) Options
+
+	// WriteTaggedBatchDedupTags returns whether to deduplicate identical encoded
+	// tags across a tagged write batch.
+	WriteTaggedBatchDedupTags() bool
+
 	// SetContextPool sets the contextPool.
 	SetContextPool(value context.Pool) Options
 
@@ -544,6 +576,31 @@ type AdminOptions interface {
 	// FetchSeriesBlocksBatchConcurrency gets the concurrency for fetching series blocks in batch.
 	FetchSeriesBlocksBatchConcurrency() int
 
+	// SetFetchSeriesBlocksPeerLimitMbps sets the bandwidth limit in megabits per second
+	// for fetching series blocks from any single peer, zero disables the limit.
+	SetFetchSeriesBlocksPeerLimitMbps(value float64) AdminOptions
+
+	// FetchSeriesBlocksPeerLimitMbps gets the bandwidth limit in megabits per second
+	// for fetching series blocks from any single peer, zero disables the limit.
+	FetchSeriesBlocksPeerLimitMbps() float64
+
+	// SetFetchSeriesBlocksAdaptiveBatchSize sets whether the batch size used to fetch
+	// series blocks from a peer adapts to how quickly that peer responds, the fetch
+	// series blocks batch size is used as the upper bound.
+	SetFetchSeriesBlocksAdaptiveBatchSize(value bool) AdminOptions
+
+	// FetchSeriesBlocksAdaptiveBatchSize gets whether the batch size used to fetch
+	// series blocks from a peer adapts to how quickly that peer responds.
+	FetchSeriesBlocksAdaptiveBatchSize() bool
+
+	// SetFetchSeriesBlocksBatchTargetLatency sets the latency that adaptive batch
+	// sizing aims for when fetching a batch of series blocks from a peer.
+	SetFetchSeriesBlocksBatchTargetLatency(value time.Duration) AdminOptions
+
+	// FetchSeriesBlocksBatchTargetLatency gets the latency that adaptive batch
+	// sizing aims for when fetching a batch of series blocks from a peer.
+	FetchSeriesBlocksBatchTargetLatency() time.Duration
+
 	// SetStreamBlocksRetrier sets the retrier for streaming blocks.
 	SetStreamBlocksRetrier(value xretry.Retrier) AdminOptions
 
@@ -58,12 +58,14 @@ service Node {
 	FetchBlocksMetadataRawV2Result fetchBlocksMetadataRawV2(1: FetchBlocksMetadataRawV2Request req) throws (1: Error err)
 	void writeBatchRaw(1: WriteBatchRawRequest req) throws (1: WriteBatchRawErrors err)
 	void writeTaggedBatchRaw(1: WriteTaggedBatchRawRequest req) throws (1: WriteBatchRawErrors err)
+	void writeTaggedBatchRawV2(1: WriteTaggedBatchRawV2Request req) throws (1: WriteBatchRawErrors err)
 	void repair() throws (1: Error err)
 	TruncateResult truncate(1: TruncateRequest req) throws (1: Error err)
 
 	// Management endpoints
 	NodeHealthResult health() throws (1: Error err)
 	NodeBootstrappedResult bootstrapped() throws (1: Error err)
+	NodeBootstrapProgressResult bootstrapProgress() throws (1: Error err)
 	NodePersistRateLimitResult getPersistRateLimit() throws (1: Error err)
 	NodePersistRateLimitResult setPersistRateLimit(1: NodeSetPersistRateLimitRequest req) throws (1: Error err)
 	NodeWriteNewSeriesAsyncResult getWriteNewSeriesAsync() throws (1: Error err)
@@ -240,6 +242,33 @@ struct WriteTaggedBatchRawRequestElement {
 	3: required Datapoint datapoint
 }
 
+enum WriteBatchCompression {
+	NONE,
+	SNAPPY
+}
+
+// WriteTaggedBatchRawV2Request carries the elements of a tagged write batch as
+// a WriteTaggedBatchRawV2Elements serialized with the binary protocol and then
+// compressed with the given compression.
+struct WriteTaggedBatchRawV2Request {
+	1: required binary nameSpace
+	2: required WriteBatchCompression compression
+	3: required binary elements
+}
+
+// WriteTaggedBatchRawV2Elements deduplicates the encoded tags of a batch, each
+// element references its encoded tags by index.
+struct WriteTaggedBatchRawV2Elements {
+	1: required list<binary> encodedTags
+	2: required list<WriteTaggedBatchRawV2Element> elements
+}
+
+struct WriteTaggedBatchRawV2Element {
+	1: required binary id
+	2: required i32 encodedTagsIndex
+	3: required Datapoint datapoint
+}
+
 struct WriteBatchRawError {
 	1: required i64 index
 	2: required Error err
@@ -257,10 +286,46 @@ struct NodeHealthResult {
 	1: required bool ok
 	2: required string status
 	3: required bool bootstrapped
+	4: optional list<WriteBatchCompression> writeBatchCompressions
 }
 
 struct NodeBootstrappedResult {}
 
+struct NodeBootstrapProgressResult {
+	1: required list<BootstrapPhaseProgress> phases
+	2: required list<BootstrapNamespaceProgress> namespaces
+}
+
+struct BootstrapPhaseProgress {
+	1: required string bootstrapper
+	2: required string nameSpace
+	3: required string phase
+	4: required i64 startUnixNanos
+	5: required i64 durationNanos
+	6: required bool done
+}
+
+struct BootstrapNamespaceProgress {
+	1: required string bootstrapper
+	2: required string nameSpace
+	3: required i64 startUnixNanos
+	4: required i64 rangesRequestedNanos
+	5: required i64 rangesFulfilledNanos
+	6: required i64 bytesRead
+	7: required i64 seriesLoaded
+	8: required i64 estimatedRemainingNanos
+	9: required list<BootstrapShardProgress> shards
+	10: required bool done
+}
+
+struct BootstrapShardProgress {
+	1: required i32 shard
+	2: required i64 rangesRequestedNanos
+	3: required i64 rangesFulfilledNanos
+	4: required i64 bytesRead
+	5: required i64 seriesLoaded
+}
+
 struct NodePersistRateLimitResult {
 	1: required bool limitEnabled
 	2: required double limitMbps
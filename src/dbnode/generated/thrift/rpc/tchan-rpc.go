@@ -47,6 +47,7 @@ type TChanCluster interface {
 type TChanNode interface {
 	Aggregate(ctx thrift.Context, req *AggregateQueryRequest) (*AggregateQueryResult_, error)
 	AggregateRaw(ctx thrift.Context, req *AggregateQueryRawRequest) (*AggregateQueryRawResult_, error)
+	BootstrapProgress(ctx thrift.Context) (*NodeBootstrapProgressResult_, error)
 	Bootstrapped(ctx thrift.Context) (*NodeBootstrappedResult_, error)
 	Fetch(ctx thrift.Context, req *FetchRequest) (*FetchResult_, error)
 	FetchBatchRaw(ctx thrift.Context, req *FetchBatchRawRequest) (*FetchBatchRawResult_, error)
@@ -69,6 +70,7 @@ type TChanNode interface {
 	WriteBatchRaw(ctx thrift.Context, req *WriteBatchRawRequest) error
 	WriteTagged(ctx thrift.Context, req *WriteTaggedRequest) error
 	WriteTaggedBatchRaw(ctx thrift.Context, req *WriteTaggedBatchRawRequest) error
+	WriteTaggedBatchRawV2(ctx thrift.Context, req *WriteTaggedBatchRawV2Request) error
 }
 
 // Implementation of a client and service handler.
@@ -511,6 +513,22 @@ func (c *tchanNodeClient) AggregateRaw(ctx thrift.Context, req *AggregateQueryRa
 	return resp.GetSuccess(), err
 }
 
+func (c *tchanNodeClient) BootstrapProgress(ctx thrift.Context) (*NodeBootstrapProgressResult_, error) {
+	var resp NodeBootstrapProgressResult
+	args := NodeBootstrapProgressArgs{}
+	success, err := c.client.Call(ctx, c.thriftService, "bootstrapProgress", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for bootstrapProgress")
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
 func (c *tchanNodeClient) Bootstrapped(ctx thrift.Context) (*NodeBootstrappedResult_, error) {
 	var resp NodeBootstrappedResult
 	args := NodeBootstrappedArgs{}
@@ -893,6 +911,24 @@ func (c *tchanNodeClient) WriteTaggedBatchRaw(ctx thrift.Context, req *WriteTagg
 	return err
 }
 
+func (c *tchanNodeClient) WriteTaggedBatchRawV2(ctx thrift.Context, req *WriteTaggedBatchRawV2Request) error {
+	var resp NodeWriteTaggedBatchRawV2Result
+	args := NodeWriteTaggedBatchRawV2Args{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "writeTaggedBatchRawV2", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for writeTaggedBatchRawV2")
+		}
+	}
+
+	return err
+}
+
 type tchanNodeServer struct {
 	handler TChanNode
 }
@@ -913,6 +949,7 @@ func (s *tchanNodeServer) Methods() []string {
 	return []string{
 		"aggregate",
 		"aggregateRaw",
+		"bootstrapProgress",
 		"bootstrapped",
 		"fetch",
 		"fetchBatchRaw",
@@ -935,6 +972,7 @@ func (s *tchanNodeServer) Methods() []string {
 		"writeBatchRaw",
 		"writeTagged",
 		"writeTaggedBatchRaw",
+		"writeTaggedBatchRawV2",
 	}
 }
 
@@ -944,6 +982,8 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 		return s.handleAggregate(ctx, protocol)
 	case "aggregateRaw":
 		return s.handleAggregateRaw(ctx, protocol)
+	case "bootstrapProgress":
+		return s.handleBootstrapProgress(ctx, protocol)
 	case "bootstrapped":
 		return s.handleBootstrapped(ctx, protocol)
 	case "fetch":
@@ -988,6 +1028,8 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 		return s.handleWriteTagged(ctx, protocol)
 	case "writeTaggedBatchRaw":
 		return s.handleWriteTaggedBatchRaw(ctx, protocol)
+	case "writeTaggedBatchRawV2":
+		return s.handleWriteTaggedBatchRawV2(ctx, protocol)
 
 	default:
 		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
@@ -1050,6 +1092,34 @@ func (s *tchanNodeServer) handleAggregateRaw(ctx thrift.Context, protocol athrif
 	return err == nil, &res, nil
 }
 
+func (s *tchanNodeServer) handleBootstrapProgress(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeBootstrapProgressArgs
+	var res NodeBootstrapProgressResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.BootstrapProgress(ctx)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleBootstrapped(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeBootstrappedArgs
 	var res NodeBootstrappedResult
@@ -1660,3 +1730,29 @@ func (s *tchanNodeServer) handleWriteTaggedBatchRaw(ctx thrift.Context, protocol
 
 	return err == nil, &res, nil
 }
+func (s *tchanNodeServer) handleWriteTaggedBatchRawV2(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeWriteTaggedBatchRawV2Args
+	var res NodeWriteTaggedBatchRawV2Result
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	err :=
+		s.handler.WriteTaggedBatchRawV2(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *WriteBatchRawErrors:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *WriteBatchRawErrors but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+	}
+
+	return err == nil, &res, nil
+}
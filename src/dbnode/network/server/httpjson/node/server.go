@@ -61,6 +61,9 @@ func (s *server) ListenAndServe() (ns.Close, error) {
 	if err := httpjson.RegisterHandlers(mux, s.service, s.opts); err != nil {
 		return nil, err
 	}
+	for pattern, handler := range s.opts.Handlers() {
+		mux.Handle(pattern, handler)
+	}
 
 	listener, err := net.Listen("tcp", s.address)
 	if err != nil {
@@ -0,0 +1,160 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package convert
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+
+	apachethrift "github.com/apache/thrift/lib/go/thrift"
+	"github.com/golang/snappy"
+)
+
+var (
+	errNilWriteTaggedBatchRawV2Request = errors.New("nil write tagged batch raw v2 request")
+)
+
+// ToRPCWriteTaggedBatchRawV2Request converts the elements of a tagged write
+// batch to a V2 request, deduplicating encoded tags across the batch and
+// compressing the serialized elements with the given compression.
+func ToRPCWriteTaggedBatchRawV2Request(
+	nameSpace []byte,
+	elems []*rpc.WriteTaggedBatchRawRequestElement,
+	compression rpc.WriteBatchCompression,
+) (*rpc.WriteTaggedBatchRawV2Request, error) {
+	var (
+		v2Elems = &rpc.WriteTaggedBatchRawV2Elements{
+			Elements: make([]*rpc.WriteTaggedBatchRawV2Element, 0, len(elems)),
+		}
+		tagsIndexes = make(map[string]int32, len(elems))
+	)
+	for _, elem := range elems {
+		index, ok := tagsIndexes[string(elem.EncodedTags)]
+		if !ok {
+			index = int32(len(v2Elems.EncodedTags))
+			tagsIndexes[string(elem.EncodedTags)] = index
+			v2Elems.EncodedTags = append(v2Elems.EncodedTags, elem.EncodedTags)
+		}
+		v2Elems.Elements = append(v2Elems.Elements, &rpc.WriteTaggedBatchRawV2Element{
+			ID:               elem.ID,
+			EncodedTagsIndex: index,
+			Datapoint:        elem.Datapoint,
+		})
+	}
+
+	data, err := apachethrift.NewTSerializer().Write(v2Elems)
+	if err != nil {
+		return nil, err
+	}
+
+	switch compression {
+	case rpc.WriteBatchCompression_NONE:
+	case rpc.WriteBatchCompression_SNAPPY:
+		data = snappy.Encode(nil, data)
+	default:
+		return nil, errUnknownWriteBatchCompression(compression)
+	}
+
+	return &rpc.WriteTaggedBatchRawV2Request{
+		NameSpace:   nameSpace,
+		Compression: compression,
+		Elements:    data,
+	}, nil
+}
+
+// FromRPCWriteTaggedBatchRawV2Request decompresses the elements of a V2 request
+// and expands them into a tagged write batch request. Compressed elements that
+// would decompress to more than maxDecodedBytes are rejected before decoding.
+func FromRPCWriteTaggedBatchRawV2Request(
+	req *rpc.WriteTaggedBatchRawV2Request,
+	maxDecodedBytes int,
+) (*rpc.WriteTaggedBatchRawRequest, error) {
+	if req == nil {
+		return nil, errNilWriteTaggedBatchRawV2Request
+	}
+
+	data := req.Elements
+	switch req.Compression {
+	case rpc.WriteBatchCompression_NONE:
+	case rpc.WriteBatchCompression_SNAPPY:
+		decodedLen, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if decodedLen > maxDecodedBytes {
+			return nil, fmt.Errorf(
+				"write tagged batch raw v2 elements decode to %d bytes, larger than the max of %d",
+				decodedLen, maxDecodedBytes)
+		}
+
+		decoded, err := snappy.Decode(nil, data)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	default:
+		return nil, errUnknownWriteBatchCompression(req.Compression)
+	}
+
+	v2Elems := rpc.NewWriteTaggedBatchRawV2Elements()
+	if err := apachethrift.NewTDeserializer().Read(v2Elems, data); err != nil {
+		return nil, err
+	}
+
+	var (
+		numTags  = len(v2Elems.EncodedTags)
+		tagsUsed = make([]bool, numTags)
+		elems    = make([]*rpc.WriteTaggedBatchRawRequestElement, 0, len(v2Elems.Elements))
+	)
+	for i, elem := range v2Elems.Elements {
+		index := int(elem.EncodedTagsIndex)
+		if index < 0 || index >= numTags {
+			return nil, fmt.Errorf(
+				"write tagged batch raw v2 element %d references encoded tags %d of %d",
+				i, index, numTags)
+		}
+
+		// NB: Each element owns its encoded tags as they are returned to the
+		// bytes pool once the request is finalized, so copy any shared tags.
+		encodedTags := v2Elems.EncodedTags[index]
+		if tagsUsed[index] {
+			encodedTags = append([]byte(nil), encodedTags...)
+		}
+		tagsUsed[index] = true
+
+		elems = append(elems, &rpc.WriteTaggedBatchRawRequestElement{
+			ID:          elem.ID,
+			EncodedTags: encodedTags,
+			Datapoint:   elem.Datapoint,
+		})
+	}
+
+	return &rpc.WriteTaggedBatchRawRequest{
+		NameSpace: req.NameSpace,
+		Elements:  elems,
+	}, nil
+}
+
+func errUnknownWriteBatchCompression(compression rpc.WriteBatchCompression) error {
+	return fmt.Errorf("unknown write batch compression: %v", compression)
+}
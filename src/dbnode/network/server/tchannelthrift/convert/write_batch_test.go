@@ -0,0 +1,121 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package convert_test
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMaxDecodedBytes = 1 << 20
+
+func TestWriteTaggedBatchRawV2RequestRoundTrip(t *testing.T) {
+	var (
+		fooTags = []byte("foo-encoded-tags")
+		barTags = []byte("bar-encoded-tags")
+		elems   = []*rpc.WriteTaggedBatchRawRequestElement{
+			{ID: []byte("foo"), EncodedTags: fooTags, Datapoint: &rpc.Datapoint{Timestamp: 1, Value: 1}},
+			{ID: []byte("bar"), EncodedTags: barTags, Datapoint: &rpc.Datapoint{Timestamp: 2, Value: 2}},
+			{ID: []byte("foo"), EncodedTags: fooTags, Datapoint: &rpc.Datapoint{Timestamp: 3, Value: 3}},
+		}
+	)
+
+	for _, compression := range []rpc.WriteBatchCompression{
+		rpc.WriteBatchCompression_NONE,
+		rpc.WriteBatchCompression_SNAPPY,
+	} {
+		req, err := convert.ToRPCWriteTaggedBatchRawV2Request([]byte("ns"), elems, compression)
+		require.NoError(t, err)
+		assert.Equal(t, compression, req.Compression)
+
+		v1, err := convert.FromRPCWriteTaggedBatchRawV2Request(req, testMaxDecodedBytes)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("ns"), v1.NameSpace)
+		require.Equal(t, len(elems), len(v1.Elements))
+		for i, elem := range elems {
+			assert.Equal(t, elem.ID, v1.Elements[i].ID)
+			assert.Equal(t, elem.EncodedTags, v1.Elements[i].EncodedTags)
+			assert.Equal(t, elem.Datapoint.Timestamp, v1.Elements[i].Datapoint.Timestamp)
+			assert.Equal(t, elem.Datapoint.Value, v1.Elements[i].Datapoint.Value)
+		}
+
+		// Shared encoded tags must not alias as each element owns its tags.
+		assert.False(t, &v1.Elements[0].EncodedTags[0] == &v1.Elements[2].EncodedTags[0])
+	}
+}
+
+func TestWriteTaggedBatchRawV2RequestDedupsEncodedTags(t *testing.T) {
+	tags := []byte("a-long-set-of-encoded-tags-shared-by-every-element-in-the-batch")
+	elems := make([]*rpc.WriteTaggedBatchRawRequestElement, 0, 100)
+	for i := 0; i < 100; i++ {
+		elems = append(elems, &rpc.WriteTaggedBatchRawRequestElement{
+			ID:          []byte("foo"),
+			EncodedTags: tags,
+			Datapoint:   &rpc.Datapoint{Timestamp: int64(i), Value: float64(i)},
+		})
+	}
+
+	req, err := convert.ToRPCWriteTaggedBatchRawV2Request([]byte("ns"), elems,
+		rpc.WriteBatchCompression_NONE)
+	require.NoError(t, err)
+	// Without deduplication the encoded tags alone would be larger than the request.
+	assert.True(t, len(req.Elements) < len(elems)*len(tags))
+}
+
+func TestWriteTaggedBatchRawV2RequestBadInput(t *testing.T) {
+	_, err := convert.FromRPCWriteTaggedBatchRawV2Request(nil, testMaxDecodedBytes)
+	assert.Error(t, err)
+
+	_, err = convert.FromRPCWriteTaggedBatchRawV2Request(&rpc.WriteTaggedBatchRawV2Request{
+		NameSpace:   []byte("ns"),
+		Compression: rpc.WriteBatchCompression_SNAPPY,
+		Elements:    []byte("not snappy"),
+	}, testMaxDecodedBytes)
+	assert.Error(t, err)
+
+	_, err = convert.ToRPCWriteTaggedBatchRawV2Request([]byte("ns"), nil,
+		rpc.WriteBatchCompression(42))
+	assert.Error(t, err)
+}
+
+func TestWriteTaggedBatchRawV2RequestTooLarge(t *testing.T) {
+	elems := []*rpc.WriteTaggedBatchRawRequestElement{{
+		ID:          []byte("foo"),
+		EncodedTags: make([]byte, 4096),
+		Datapoint:   &rpc.Datapoint{Timestamp: 1, Value: 1},
+	}}
+	req, err := convert.ToRPCWriteTaggedBatchRawV2Request([]byte("ns"), elems,
+		rpc.WriteBatchCompression_SNAPPY)
+	require.NoError(t, err)
+	// The zeroed tags compress well below the limit that is exceeded once decoded.
+	require.True(t, len(req.Elements) < 1024)
+
+	_, err = convert.FromRPCWriteTaggedBatchRawV2Request(req, 1024)
+	assert.Error(t, err)
+
+	_, err = convert.FromRPCWriteTaggedBatchRawV2Request(req, testMaxDecodedBytes)
+	assert.NoError(t, err)
+}
@@ -206,6 +206,10 @@ func NewService(db storage.Database, opts tchannelthrift.Options) rpc.TChanNode
 			Ok:           true,
 			Status:       "up",
 			Bootstrapped: false,
+			WriteBatchCompressions: []rpc.WriteBatchCompression{
+				rpc.WriteBatchCompression_NONE,
+				rpc.WriteBatchCompression_SNAPPY,
+			},
 		},
 	}
 
@@ -257,6 +261,57 @@ func (s *service) Bootstrapped(ctx thrift.Context) (*rpc.NodeBootstrappedResult_
 	return &rpc.NodeBootstrappedResult_{}, nil
 }
 
+// BootstrapProgress returns the phases the bootstrappers went through and how much
+// of each namespace and shard they have fulfilled, including an estimate of the
+// time remaining, so operators can tell what a node that is not yet bootstrapped
+// is spending its time on.
+func (s *service) BootstrapProgress(ctx thrift.Context) (*rpc.NodeBootstrapProgressResult_, error) {
+	var (
+		progress   = s.db.Options().BootstrapProgress()
+		phases     = progress.Phases()
+		namespaces = progress.Namespaces()
+		result     = &rpc.NodeBootstrapProgressResult_{
+			Phases:     make([]*rpc.BootstrapPhaseProgress, 0, len(phases)),
+			Namespaces: make([]*rpc.BootstrapNamespaceProgress, 0, len(namespaces)),
+		}
+	)
+	for _, phase := range phases {
+		result.Phases = append(result.Phases, &rpc.BootstrapPhaseProgress{
+			Bootstrapper:   phase.Bootstrapper,
+			NameSpace:      phase.Namespace,
+			Phase:          phase.Phase,
+			StartUnixNanos: phase.Start.UnixNano(),
+			DurationNanos:  int64(phase.Duration),
+			Done:           phase.Done,
+		})
+	}
+	for _, ns := range namespaces {
+		nsProgress := &rpc.BootstrapNamespaceProgress{
+			Bootstrapper:            ns.Bootstrapper,
+			NameSpace:               ns.Namespace,
+			StartUnixNanos:          ns.Start.UnixNano(),
+			RangesRequestedNanos:    int64(ns.RangesRequested),
+			RangesFulfilledNanos:    int64(ns.RangesFulfilled),
+			BytesRead:               ns.BytesRead,
+			SeriesLoaded:            ns.SeriesLoaded,
+			EstimatedRemainingNanos: int64(ns.EstimatedRemaining),
+			Done:                    ns.Done,
+			Shards:                  make([]*rpc.BootstrapShardProgress, 0, len(ns.Shards)),
+		}
+		for _, shard := range ns.Shards {
+			nsProgress.Shards = append(nsProgress.Shards, &rpc.BootstrapShardProgress{
+				Shard:                int32(shard.Shard),
+				RangesRequestedNanos: int64(shard.RangesRequested),
+				RangesFulfilledNanos: int64(shard.RangesFulfilled),
+				BytesRead:            shard.BytesRead,
+				SeriesLoaded:         shard.SeriesLoaded,
+			})
+		}
+		result.Namespaces = append(result.Namespaces, nsProgress)
+	}
+	return result, nil
+}
+
 func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryResult_, error) {
 	if s.isOverloaded() {
 		s.metrics.overloadRejected.Inc(1)
@@ -1096,6 +1151,23 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 	return nil
 }
 
+func (s *service) WriteTaggedBatchRawV2(tctx thrift.Context, req *rpc.WriteTaggedBatchRawV2Request) error {
+	if s.db.IsOverloaded() {
+		s.metrics.overloadRejected.Inc(1)
+		return tterrors.NewInternalError(errServerIsOverloaded)
+	}
+
+	v1Req, err := convert.FromRPCWriteTaggedBatchRawV2Request(req,
+		s.opts.MaxWriteBatchDecodedBytes())
+	if err != nil {
+		return tterrors.NewBadRequestError(err)
+	}
+
+	// The expanded request owns the decoded series IDs and encoded tags
+	// and returns them to the bytes pool once finalized.
+	return s.WriteTaggedBatchRaw(tctx, v1Req)
+}
+
 func (s *service) Repair(tctx thrift.Context) error {
 	callStart := s.nowFn()
 
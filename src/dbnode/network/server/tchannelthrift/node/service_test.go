@@ -35,6 +35,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -85,6 +86,10 @@ func TestServiceHealth(t *testing.T) {
 	assert.Equal(t, true, result.Ok)
 	assert.Equal(t, "up", result.Status)
 	assert.Equal(t, false, result.Bootstrapped)
+	assert.Equal(t, []rpc.WriteBatchCompression{
+		rpc.WriteBatchCompression_NONE,
+		rpc.WriteBatchCompression_SNAPPY,
+	}, result.WriteBatchCompressions)
 
 	// Assert bootstrapped true
 	mockDB.EXPECT().IsBootstrappedAndDurable().Return(true)
@@ -121,6 +126,69 @@ func TestServiceBootstrapped(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceBootstrapProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now      = time.Now()
+		nsID     = ident.StringID("metrics")
+		progress = result.NewProgress(func() time.Time { return now })
+		opts     = testStorageOpts.SetBootstrapProgress(progress)
+	)
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(opts).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	progress.StartPhase("commitlog", nsID, "read-commitlogs")
+	progress.RequestShards("commitlog", nsID, result.ShardTimeRanges{
+		3: xtime.Ranges{}.AddRange(xtime.Range{Start: now, End: now.Add(2 * time.Hour)}),
+	})
+	now = now.Add(time.Minute)
+	progress.UpdateShard("commitlog", nsID, 3, result.ShardProgressUpdate{
+		Fulfilled:    xtime.Ranges{}.AddRange(xtime.Range{Start: now, End: now.Add(time.Hour)}),
+		BytesRead:    2048,
+		SeriesLoaded: 20,
+	})
+
+	tctx, _ := thrift.NewContext(time.Minute)
+	res, err := service.BootstrapProgress(tctx)
+	require.NoError(t, err)
+	require.Equal(t, []*rpc.BootstrapPhaseProgress{
+		{
+			Bootstrapper:   "commitlog",
+			NameSpace:      "metrics",
+			Phase:          "read-commitlogs",
+			StartUnixNanos: now.Add(-time.Minute).UnixNano(),
+			DurationNanos:  int64(time.Minute),
+			Done:           false,
+		},
+	}, res.Phases)
+	require.Equal(t, []*rpc.BootstrapNamespaceProgress{
+		{
+			Bootstrapper:            "commitlog",
+			NameSpace:               "metrics",
+			StartUnixNanos:          now.Add(-time.Minute).UnixNano(),
+			RangesRequestedNanos:    int64(2 * time.Hour),
+			RangesFulfilledNanos:    int64(time.Hour),
+			BytesRead:               2048,
+			SeriesLoaded:            20,
+			EstimatedRemainingNanos: int64(time.Minute),
+			Shards: []*rpc.BootstrapShardProgress{
+				{
+					Shard:                3,
+					RangesRequestedNanos: int64(2 * time.Hour),
+					RangesFulfilledNanos: int64(time.Hour),
+					BytesRead:            2048,
+					SeriesLoaded:         20,
+				},
+			},
+		},
+	}, res.Namespaces)
+}
+
 func TestServiceQuery(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1757,6 +1825,99 @@ func TestServiceWriteTaggedBatchRawUnknownError(t *testing.T) {
 	require.Equal(t, convert.ToRPCError(unknownErr), err)
 }
 
+func TestServiceWriteTaggedBatchRawV2(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	mockDecoder := serialize.NewMockTagDecoder(ctrl)
+	mockDecoder.EXPECT().Reset(gomock.Any()).AnyTimes()
+	mockDecoder.EXPECT().Err().Return(nil).AnyTimes()
+	mockDecoder.EXPECT().Close().AnyTimes()
+	mockDecoderPool := serialize.NewMockTagDecoderPool(ctrl)
+	mockDecoderPool.EXPECT().Get().Return(mockDecoder).AnyTimes()
+
+	opts := tchannelthrift.NewOptions().
+		SetTagDecoderPool(mockDecoderPool)
+
+	service := NewService(mockDB, opts).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+
+	values := []struct {
+		id        string
+		tagEncode string
+		t         time.Time
+		v         float64
+	}{
+		{"foo", "a|b", time.Now().Truncate(time.Second), 12.34},
+		{"bar", "c|dd", time.Now().Truncate(time.Second), 42.42},
+		{"foo", "a|b", time.Now().Truncate(time.Second).Add(time.Second), 56.78},
+	}
+
+	writeBatch := ts.NewWriteBatch(len(values), ident.StringID(nsID), nil)
+	mockDB.EXPECT().
+		BatchWriter(ident.NewIDMatcher(nsID), len(values)).
+		Return(writeBatch, nil)
+
+	mockDB.EXPECT().
+		WriteTaggedBatch(ctx, ident.NewIDMatcher(nsID), writeBatch, gomock.Any()).
+		Return(nil)
+
+	var elements []*rpc.WriteTaggedBatchRawRequestElement
+	for _, w := range values {
+		elem := &rpc.WriteTaggedBatchRawRequestElement{
+			ID:          []byte(w.id),
+			EncodedTags: []byte(w.tagEncode),
+			Datapoint: &rpc.Datapoint{
+				Timestamp:         w.t.Unix(),
+				TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+				Value:             w.v,
+			},
+		}
+		elements = append(elements, elem)
+	}
+
+	req, err := convert.ToRPCWriteTaggedBatchRawV2Request([]byte(nsID), elements,
+		rpc.WriteBatchCompression_SNAPPY)
+	require.NoError(t, err)
+
+	mockDB.EXPECT().IsOverloaded().Return(false).Times(2)
+	err = service.WriteTaggedBatchRawV2(tctx, req)
+	require.NoError(t, err)
+}
+
+func TestServiceWriteTaggedBatchRawV2BadRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	err := service.WriteTaggedBatchRawV2(tctx, &rpc.WriteTaggedBatchRawV2Request{
+		NameSpace:   []byte("metrics"),
+		Compression: rpc.WriteBatchCompression_SNAPPY,
+		Elements:    []byte("not snappy"),
+	})
+	require.Error(t, err)
+	rpcErr, ok := err.(*rpc.Error)
+	require.True(t, ok)
+	assert.True(t, tterrors.IsBadRequestError(rpcErr))
+}
+
 func TestServiceRepair(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
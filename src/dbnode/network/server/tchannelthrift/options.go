@@ -26,12 +26,19 @@ import (
 	"github.com/m3db/m3x/pool"
 )
 
+const (
+	// defaultMaxWriteBatchDecodedBytes bounds the memory a single compressed
+	// write batch may decompress to.
+	defaultMaxWriteBatchDecodedBytes = 64 << 20
+)
+
 type options struct {
-	instrumentOpts           instrument.Options
-	blockMetadataV2Pool      BlockMetadataV2Pool
-	blockMetadataV2SlicePool BlockMetadataV2SlicePool
-	tagEncoderPool           serialize.TagEncoderPool
-	tagDecoderPool           serialize.TagDecoderPool
+	instrumentOpts            instrument.Options
+	blockMetadataV2Pool       BlockMetadataV2Pool
+	blockMetadataV2SlicePool  BlockMetadataV2SlicePool
+	tagEncoderPool            serialize.TagEncoderPool
+	tagDecoderPool            serialize.TagDecoderPool
+	maxWriteBatchDecodedBytes int
 }
 
 // NewOptions creates new options
@@ -47,11 +54,12 @@ func NewOptions() Options {
 	tagDecoderPool.Init()
 
 	return &options{
-		instrumentOpts:           instrument.NewOptions(),
-		blockMetadataV2Pool:      NewBlockMetadataV2Pool(nil),
-		blockMetadataV2SlicePool: NewBlockMetadataV2SlicePool(nil, 0),
-		tagEncoderPool:           tagEncoderPool,
-		tagDecoderPool:           tagDecoderPool,
+		instrumentOpts:            instrument.NewOptions(),
+		blockMetadataV2Pool:       NewBlockMetadataV2Pool(nil),
+		blockMetadataV2SlicePool:  NewBlockMetadataV2SlicePool(nil, 0),
+		tagEncoderPool:            tagEncoderPool,
+		tagDecoderPool:            tagDecoderPool,
+		maxWriteBatchDecodedBytes: defaultMaxWriteBatchDecodedBytes,
 	}
 }
 
@@ -104,3 +112,13 @@ func (o *options) SetTagDecoderPool(value serialize.TagDecoderPool) Options {
 func (o *options) TagDecoderPool() serialize.TagDecoderPool {
 	return o.tagDecoderPool
 }
+
+func (o *options) SetMaxWriteBatchDecodedBytes(value int) Options {
+	opts := *o
+	opts.maxWriteBatchDecodedBytes = value
+	return &opts
+}
+
+func (o *options) MaxWriteBatchDecodedBytes() int {
+	return o.maxWriteBatchDecodedBytes
+}
@@ -56,4 +56,12 @@ type Options interface {
 
 	// TagDecoderPool returns the tag encoder pool
 	TagDecoderPool() serialize.TagDecoderPool
+
+	// SetMaxWriteBatchDecodedBytes sets the max size compressed write batches
+	// may decompress to.
+	SetMaxWriteBatchDecodedBytes(value int) Options
+
+	// MaxWriteBatchDecodedBytes returns the max size compressed write batches
+	// may decompress to.
+	MaxWriteBatchDecodedBytes() int
 }
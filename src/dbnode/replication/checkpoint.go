@@ -0,0 +1,87 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package replication
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+const (
+	checkpointFileName = "checkpoint.json"
+	checkpointTempName = "checkpoint.json.tmp"
+)
+
+type fileCheckpointStore struct {
+	dir      string
+	filePerm os.FileMode
+}
+
+// NewFileCheckpointStore returns a checkpoint store that persists
+// checkpoints as a file in the given directory.
+func NewFileCheckpointStore(dir string, filePerm os.FileMode) CheckpointStore {
+	return &fileCheckpointStore{dir: dir, filePerm: filePerm}
+}
+
+func (s *fileCheckpointStore) Load() (Checkpoint, error) {
+	data, err := ioutil.ReadFile(path.Join(s.dir, checkpointFileName))
+	if os.IsNotExist(err) {
+		return Checkpoint{}, nil
+	}
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	var value Checkpoint
+	if err := json.Unmarshal(data, &value); err != nil {
+		return Checkpoint{}, err
+	}
+	return value, nil
+}
+
+func (s *fileCheckpointStore) Store(value Checkpoint) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename so that a crash mid write never
+	// leaves a truncated checkpoint behind.
+	tempPath := path.Join(s.dir, checkpointTempName)
+	fd, err := os.OpenFile(tempPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, s.filePerm)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(data); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Sync(); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path.Join(s.dir, checkpointFileName))
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package replication
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3x/instrument"
+	xretry "github.com/m3db/m3x/retry"
+)
+
+const (
+	defaultMaxBufferedFiles          = 128
+	defaultScanInterval              = 10 * time.Second
+	defaultQueueSize                 = 65536
+	defaultWriteConcurrency          = 16
+	defaultReconcileConsistencyLevel = topology.ReadConsistencyLevelMajority
+	defaultReconcileInterval         = time.Hour
+	defaultReconcileLookback         = 24 * time.Hour
+)
+
+var (
+	errNoBufferFilePathPrefix   = errors.New("no buffer file path prefix in replication options")
+	errInvalidMaxBufferedFiles  = errors.New("invalid max buffered files in replication options")
+	errInvalidScanInterval      = errors.New("invalid scan interval in replication options")
+	errInvalidQueueSize         = errors.New("invalid queue size in replication options")
+	errInvalidWriteConcurrency  = errors.New("invalid write concurrency in replication options")
+	errNoWriteRetrier           = errors.New("no write retrier in replication options")
+	errInvalidReconcileInterval = errors.New("invalid reconcile interval in replication options")
+	errInvalidReconcileLookback = errors.New("invalid reconcile lookback in replication options")
+	errOriginWithoutTopology    = errors.New("origin and topology map provider must be set together in replication options")
+)
+
+type options struct {
+	clockOpts                 clock.Options
+	instrumentOpts            instrument.Options
+	commitLogOpts             commitlog.Options
+	bufferFilePathPrefix      string
+	maxBufferedFiles          int
+	scanInterval              time.Duration
+	queueSize                 int
+	writeConcurrency          int
+	writeRetrier              xretry.Retrier
+	reconcileConsistencyLevel topology.ReadConsistencyLevel
+	reconcileInterval         time.Duration
+	reconcileLookback         time.Duration
+	namespacesFn              NamespacesFn
+	origin                    topology.Host
+	topologyMapProvider       topology.MapProvider
+}
+
+// NewOptions creates new replication options.
+func NewOptions() Options {
+	return &options{
+		clockOpts:                 clock.NewOptions(),
+		instrumentOpts:            instrument.NewOptions(),
+		commitLogOpts:             commitlog.NewOptions(),
+		maxBufferedFiles:          defaultMaxBufferedFiles,
+		scanInterval:              defaultScanInterval,
+		queueSize:                 defaultQueueSize,
+		writeConcurrency:          defaultWriteConcurrency,
+		writeRetrier:              xretry.NewRetrier(xretry.NewOptions()),
+		reconcileConsistencyLevel: defaultReconcileConsistencyLevel,
+		reconcileInterval:         defaultReconcileInterval,
+		reconcileLookback:         defaultReconcileLookback,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.bufferFilePathPrefix == "" {
+		return errNoBufferFilePathPrefix
+	}
+	if o.maxBufferedFiles <= 0 {
+		return errInvalidMaxBufferedFiles
+	}
+	if o.scanInterval <= 0 {
+		return errInvalidScanInterval
+	}
+	if o.queueSize <= 0 {
+		return errInvalidQueueSize
+	}
+	if o.writeConcurrency <= 0 {
+		return errInvalidWriteConcurrency
+	}
+	if o.writeRetrier == nil {
+		return errNoWriteRetrier
+	}
+	if o.reconcileInterval <= 0 {
+		return errInvalidReconcileInterval
+	}
+	if o.reconcileLookback <= 0 {
+		return errInvalidReconcileLookback
+	}
+	if (o.origin == nil) != (o.topologyMapProvider == nil) {
+		return errOriginWithoutTopology
+	}
+	return nil
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetCommitLogOptions(value commitlog.Options) Options {
+	opts := *o
+	opts.commitLogOpts = value
+	return &opts
+}
+
+func (o *options) CommitLogOptions() commitlog.Options {
+	return o.commitLogOpts
+}
+
+func (o *options) SetBufferFilePathPrefix(value string) Options {
+	opts := *o
+	opts.bufferFilePathPrefix = value
+	return &opts
+}
+
+func (o *options) BufferFilePathPrefix() string {
+	return o.bufferFilePathPrefix
+}
+
+func (o *options) SetMaxBufferedFiles(value int) Options {
+	opts := *o
+	opts.maxBufferedFiles = value
+	return &opts
+}
+
+func (o *options) MaxBufferedFiles() int {
+	return o.maxBufferedFiles
+}
+
+func (o *options) SetScanInterval(value time.Duration) Options {
+	opts := *o
+	opts.scanInterval = value
+	return &opts
+}
+
+func (o *options) ScanInterval() time.Duration {
+	return o.scanInterval
+}
+
+func (o *options) SetQueueSize(value int) Options {
+	opts := *o
+	opts.queueSize = value
+	return &opts
+}
+
+func (o *options) QueueSize() int {
+	return o.queueSize
+}
+
+func (o *options) SetWriteConcurrency(value int) Options {
+	opts := *o
+	opts.writeConcurrency = value
+	return &opts
+}
+
+func (o *options) WriteConcurrency() int {
+	return o.writeConcurrency
+}
+
+func (o *options) SetWriteRetrier(value xretry.Retrier) Options {
+	opts := *o
+	opts.writeRetrier = value
+	return &opts
+}
+
+func (o *options) WriteRetrier() xretry.Retrier {
+	return o.writeRetrier
+}
+
+func (o *options) SetReconcileConsistencyLevel(value topology.ReadConsistencyLevel) Options {
+	opts := *o
+	opts.reconcileConsistencyLevel = value
+	return &opts
+}
+
+func (o *options) ReconcileConsistencyLevel() topology.ReadConsistencyLevel {
+	return o.reconcileConsistencyLevel
+}
+
+func (o *options) SetReconcileInterval(value time.Duration) Options {
+	opts := *o
+	opts.reconcileInterval = value
+	return &opts
+}
+
+func (o *options) ReconcileInterval() time.Duration {
+	return o.reconcileInterval
+}
+
+func (o *options) SetReconcileLookback(value time.Duration) Options {
+	opts := *o
+	opts.reconcileLookback = value
+	return &opts
+}
+
+func (o *options) ReconcileLookback() time.Duration {
+	return o.reconcileLookback
+}
+
+func (o *options) SetNamespacesFn(value NamespacesFn) Options {
+	opts := *o
+	opts.namespacesFn = value
+	return &opts
+}
+
+func (o *options) NamespacesFn() NamespacesFn {
+	return o.namespacesFn
+}
+
+func (o *options) SetOrigin(value topology.Host) Options {
+	opts := *o
+	opts.origin = value
+	return &opts
+}
+
+func (o *options) Origin() topology.Host {
+	return o.origin
+}
+
+func (o *options) SetTopologyMapProvider(value topology.MapProvider) Options {
+	opts := *o
+	opts.topologyMapProvider = value
+	return &opts
+}
+
+func (o *options) TopologyMapProvider() topology.MapProvider {
+	return o.topologyMapProvider
+}
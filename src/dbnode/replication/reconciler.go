@@ -0,0 +1,236 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package replication
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3x/ident"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/uber-go/tally"
+)
+
+type reconcilerMetrics struct {
+	blocks              tally.Counter
+	mismatchedBlocks    tally.Counter
+	backfilledDatapoint tally.Counter
+	backfillErrors      tally.Counter
+}
+
+func newReconcilerMetrics(scope tally.Scope) reconcilerMetrics {
+	return reconcilerMetrics{
+		blocks:              scope.Counter("blocks"),
+		mismatchedBlocks:    scope.Counter("mismatched-blocks"),
+		backfilledDatapoint: scope.Counter("backfilled-datapoints"),
+		backfillErrors:      scope.Counter("backfill-errors"),
+	}
+}
+
+type blockKey struct {
+	id    string
+	start int64
+}
+
+type localBlock struct {
+	id        ident.ID
+	tags      ident.Tags
+	start     time.Time
+	checksums []uint32
+}
+
+type reconciler struct {
+	local   client.AdminClient
+	remote  client.AdminSession
+	opts    Options
+	logger  xlog.Logger
+	metrics reconcilerMetrics
+}
+
+// NewReconciler returns a new reconciler that compares blocks between the
+// local and the remote cluster and backfills the remote cluster from the
+// local cluster. The local session is only created on the first
+// reconciliation since the local cluster may not be available yet when the
+// reconciler is created.
+func NewReconciler(
+	local client.AdminClient,
+	remote client.AdminSession,
+	opts Options,
+) Reconciler {
+	iopts := opts.InstrumentOptions()
+	scope := iopts.MetricsScope().SubScope("replication").SubScope("reconcile")
+	return &reconciler{
+		local:   local,
+		remote:  remote,
+		opts:    opts,
+		logger:  iopts.Logger(),
+		metrics: newReconcilerMetrics(scope),
+	}
+}
+
+func (r *reconciler) Reconcile(
+	nsMetadata namespace.Metadata,
+	shard uint32,
+	start, end time.Time,
+) (ReconcileResult, error) {
+	var (
+		nsID  = nsMetadata.ID()
+		level = r.opts.ReconcileConsistencyLevel()
+		ropts = result.NewOptions()
+	)
+
+	local, err := r.local.DefaultAdminSession()
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+
+	localIter, err := local.FetchBlocksMetadataFromPeers(nsID, shard,
+		start, end, level, ropts)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+	localBlocks, err := collectLocalBlocks(localIter)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+
+	remoteIter, err := r.remote.FetchBlocksMetadataFromPeers(nsID, shard,
+		start, end, level, ropts)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+	remoteChecksums, err := collectChecksums(remoteIter)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+
+	var (
+		res       = ReconcileResult{NumBlocks: int64(len(localBlocks))}
+		blockSize = nsMetadata.Options().RetentionOptions().BlockSize()
+	)
+	for key, block := range localBlocks {
+		checksums, ok := remoteChecksums[key]
+		if ok && checksumsIntersect(block.checksums, checksums) {
+			continue
+		}
+
+		res.NumMismatchedBlocks++
+		n, err := r.backfill(local, nsID, block, block.start.Add(blockSize))
+		res.NumBackfilledDatapoints += n
+		if err != nil {
+			r.metrics.backfillErrors.Inc(1)
+			r.logger.Errorf("replication could not backfill series %s block %s: %v",
+				block.id.String(), block.start.String(), err)
+		}
+	}
+
+	r.metrics.blocks.Inc(res.NumBlocks)
+	r.metrics.mismatchedBlocks.Inc(res.NumMismatchedBlocks)
+	r.metrics.backfilledDatapoint.Inc(res.NumBackfilledDatapoints)
+	return res, nil
+}
+
+func (r *reconciler) backfill(
+	local client.AdminSession,
+	nsID ident.ID,
+	block *localBlock,
+	end time.Time,
+) (int64, error) {
+	iter, err := local.Fetch(nsID, block.id, block.start, end)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	var n int64
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		err := r.opts.WriteRetrier().Attempt(func() error {
+			return r.remote.WriteTagged(nsID, block.id,
+				ident.NewTagsIterator(block.tags), dp.Timestamp, dp.Value,
+				unit, annotation)
+		})
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, iter.Err()
+}
+
+// collectLocalBlocks collects the blocks returned by each local replica,
+// keeping every distinct checksum since replicas may legitimately differ.
+func collectLocalBlocks(iter client.PeerBlockMetadataIter) (map[blockKey]*localBlock, error) {
+	blocks := make(map[blockKey]*localBlock)
+	for iter.Next() {
+		_, metadata := iter.Current()
+		key := blockKey{id: metadata.ID.String(), start: metadata.Start.UnixNano()}
+		block, ok := blocks[key]
+		if !ok {
+			block = &localBlock{
+				id:    copyID(metadata.ID),
+				tags:  copyTags(metadata.Tags),
+				start: metadata.Start,
+			}
+			blocks[key] = block
+		}
+		if metadata.Checksum != nil {
+			block.checksums = append(block.checksums, *metadata.Checksum)
+		}
+	}
+	return blocks, iter.Err()
+}
+
+func collectChecksums(iter client.PeerBlockMetadataIter) (map[blockKey][]uint32, error) {
+	checksums := make(map[blockKey][]uint32)
+	for iter.Next() {
+		_, metadata := iter.Current()
+		key := blockKey{id: metadata.ID.String(), start: metadata.Start.UnixNano()}
+		if metadata.Checksum == nil {
+			if _, ok := checksums[key]; !ok {
+				checksums[key] = nil
+			}
+			continue
+		}
+		checksums[key] = append(checksums[key], *metadata.Checksum)
+	}
+	return checksums, iter.Err()
+}
+
+// checksumsIntersect returns whether the local and remote blocks share a
+// checksum, blocks without checksums on either side are considered equal
+// since only their presence can be compared.
+func checksumsIntersect(local, remote []uint32) bool {
+	if len(local) == 0 || len(remote) == 0 {
+		return true
+	}
+	for _, l := range local {
+		for _, r := range remote {
+			if l == r {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package replication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testBlock struct {
+	id       string
+	start    time.Time
+	checksum uint32
+}
+
+func newTestPeerIter(ctrl *gomock.Controller, blocks []testBlock) client.PeerBlockMetadataIter {
+	iter := client.NewMockPeerBlockMetadataIter(ctrl)
+	host := topology.NewHost("host", "addr")
+	calls := make([]*gomock.Call, 0, 2*len(blocks)+1)
+	for _, b := range blocks {
+		checksum := b.checksum
+		calls = append(calls,
+			iter.EXPECT().Next().Return(true),
+			iter.EXPECT().Current().Return(host, block.NewMetadata(ident.StringID(b.id),
+				ident.NewTags(ident.StringTag("name", b.id)), b.start, 1, &checksum, time.Time{})))
+	}
+	calls = append(calls, iter.EXPECT().Next().Return(false))
+	gomock.InOrder(calls...)
+	iter.EXPECT().Err().Return(nil)
+	return iter
+}
+
+func TestReconcilerBackfillsMismatchedBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nsID := ident.StringID("ns")
+	nsMetadata, err := namespace.NewMetadata(nsID, namespace.NewOptions())
+	require.NoError(t, err)
+
+	var (
+		blockSize = nsMetadata.Options().RetentionOptions().BlockSize()
+		start     = time.Now().Truncate(blockSize).Add(-2 * blockSize)
+		end       = start.Add(2 * blockSize)
+		local     = client.NewMockAdminSession(ctrl)
+		remote    = client.NewMockAdminSession(ctrl)
+		opts      = NewOptions()
+	)
+
+	local.EXPECT().
+		FetchBlocksMetadataFromPeers(nsID, uint32(1), start, end,
+			opts.ReconcileConsistencyLevel(), gomock.Any()).
+		Return(newTestPeerIter(ctrl, []testBlock{
+			{id: "matching", start: start, checksum: 1},
+			{id: "differing", start: start, checksum: 2},
+			{id: "missing", start: start, checksum: 3},
+		}), nil)
+	remote.EXPECT().
+		FetchBlocksMetadataFromPeers(nsID, uint32(1), start, end,
+			opts.ReconcileConsistencyLevel(), gomock.Any()).
+		Return(newTestPeerIter(ctrl, []testBlock{
+			{id: "matching", start: start, checksum: 1},
+			{id: "differing", start: start, checksum: 4},
+		}), nil)
+
+	for _, id := range []string{"differing", "missing"} {
+		iter := encoding.NewMockSeriesIterator(ctrl)
+		gomock.InOrder(
+			iter.EXPECT().Next().Return(true),
+			iter.EXPECT().Current().Return(ts.Datapoint{Timestamp: start, Value: 1},
+				xtime.Second, nil),
+			iter.EXPECT().Next().Return(false),
+		)
+		iter.EXPECT().Err().Return(nil)
+		iter.EXPECT().Close()
+		local.EXPECT().
+			Fetch(nsID, ident.NewIDMatcher(id), start, start.Add(blockSize)).
+			Return(iter, nil)
+		remote.EXPECT().
+			WriteTagged(nsID, ident.NewIDMatcher(id), gomock.Any(), start, 1.0,
+				xtime.Second, gomock.Any()).
+			Return(nil)
+	}
+
+	localClient := client.NewMockAdminClient(ctrl)
+	localClient.EXPECT().DefaultAdminSession().Return(local, nil)
+
+	r := NewReconciler(localClient, remote, opts)
+	res, err := r.Reconcile(nsMetadata, 1, start, end)
+	require.NoError(t, err)
+	require.Equal(t, ReconcileResult{
+		NumBlocks:               3,
+		NumMismatchedBlocks:     2,
+		NumBackfilledDatapoints: 2,
+	}, res)
+}
+
+func TestChecksumsIntersect(t *testing.T) {
+	require.True(t, checksumsIntersect([]uint32{1, 2}, []uint32{3, 2}))
+	require.False(t, checksumsIntersect([]uint32{1}, []uint32{2}))
+	require.True(t, checksumsIntersect(nil, []uint32{2}))
+}
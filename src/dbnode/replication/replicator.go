@@ -0,0 +1,702 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package replication
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/ident"
+	xlog "github.com/m3db/m3x/log"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+)
+
+var (
+	errReplicatorAlreadyStarted = errors.New("replicator already started")
+	errReplicatorNotStarted     = errors.New("replicator not started")
+	errReplicatorClosed         = errors.New("replicator closed")
+	errReconcilerNoTopology     = errors.New("reconciler requires a topology map provider and namespaces fn in replication options")
+
+	// bufferFileStart is the start encoded in the name of buffered files, the
+	// commit log no longer uses the start of file names and always sets it to
+	// zero as well.
+	bufferFileStart = time.Unix(0, 0)
+)
+
+type replicatorState int
+
+const (
+	replicatorNotStarted replicatorState = iota
+	replicatorStarted
+	replicatorClosed
+)
+
+type commitLogFilesFn func(commitlog.Options) (persist.CommitLogFiles, []commitlog.ErrorWithPath, error)
+
+type newIteratorFn func(commitlog.IteratorOpts) (commitlog.Iterator, []commitlog.ErrorWithPath, error)
+
+type linkFn func(oldname, newname string) error
+
+type replicatorMetrics struct {
+	bufferedFiles   tally.Counter
+	bufferFull      tally.Counter
+	skippedWrites   tally.Counter
+	reconcileErrors tally.Counter
+	replicatedFiles tally.Counter
+	failedFiles     tally.Counter
+	writeSuccess    tally.Counter
+	writeErrors     tally.Counter
+	pendingFiles    tally.Gauge
+	queueLength     tally.Gauge
+	lagSeconds      tally.Gauge
+}
+
+func newReplicatorMetrics(scope tally.Scope) replicatorMetrics {
+	fileScope := scope.SubScope("files")
+	writeScope := scope.SubScope("writes")
+	return replicatorMetrics{
+		bufferedFiles:   fileScope.Counter("buffered"),
+		bufferFull:      fileScope.Counter("buffer-full"),
+		skippedWrites:   writeScope.Counter("skipped"),
+		reconcileErrors: scope.SubScope("reconcile").Counter("errors"),
+		replicatedFiles: fileScope.Counter("replicated"),
+		failedFiles:     fileScope.Counter("failed"),
+		writeSuccess:    writeScope.Counter("success"),
+		writeErrors:     writeScope.Counter("errors"),
+		pendingFiles:    fileScope.Gauge("pending"),
+		queueLength:     writeScope.Gauge("queue-length"),
+		lagSeconds:      scope.Gauge("lag-seconds"),
+	}
+}
+
+type replicatorWrite struct {
+	namespace  ident.ID
+	id         ident.ID
+	tags       ident.Tags
+	datapoint  ts.Datapoint
+	unit       xtime.Unit
+	annotation ts.Annotation
+}
+
+// replicator buffers sealed commit log files by hard linking them into a
+// buffer directory so that they survive commit log cleanup, then replays
+// them against the remote cluster. Writes in M3DB are idempotent for a
+// given series and timestamp, so a file that fails to replicate part way
+// through is simply replayed in full on the next attempt. Every replica of a
+// shard has the same writes in its commit log, so only the writes of the
+// shards the local host leads are replicated. Gaps left by leadership
+// changes or by files removed before they could be buffered are backfilled
+// by periodically reconciling recently flushed blocks.
+type replicator struct {
+	sync.RWMutex
+
+	opts                Options
+	session             client.Session
+	reconciler          Reconciler
+	checkpoints         CheckpointStore
+	bufferCommitLogOpts commitlog.Options
+	nowFn               clock.NowFn
+	logger              xlog.Logger
+	metrics             replicatorMetrics
+
+	commitLogFilesFn commitLogFilesFn
+	newIteratorFn    newIteratorFn
+	linkFn           linkFn
+
+	state               replicatorState
+	checkpoint          Checkpoint
+	pendingFiles        int
+	lastReconcile       time.Time
+	lastReconcileResult ReconcileResult
+	lastWriteNanos      int64
+	queueLength         int64
+	closeCh             chan struct{}
+	wg                  sync.WaitGroup
+}
+
+// NewReplicator returns a new replicator that writes to the remote
+// cluster using the given session, the reconciler is optional.
+func NewReplicator(
+	session client.Session,
+	reconciler Reconciler,
+	opts Options,
+) (Replicator, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if reconciler != nil &&
+		(opts.TopologyMapProvider() == nil || opts.NamespacesFn() == nil) {
+		return nil, errReconcilerNoTopology
+	}
+
+	var (
+		clOpts    = opts.CommitLogOptions()
+		bufferFS  = clOpts.FilesystemOptions().SetFilePathPrefix(opts.BufferFilePathPrefix())
+		bufferOpt = clOpts.SetFilesystemOptions(bufferFS)
+		iopts     = opts.InstrumentOptions()
+		scope     = iopts.MetricsScope().SubScope("replication")
+	)
+	return &replicator{
+		opts:                opts,
+		session:             session,
+		reconciler:          reconciler,
+		checkpoints:         NewFileCheckpointStore(opts.BufferFilePathPrefix(), bufferFS.NewFileMode()),
+		bufferCommitLogOpts: bufferOpt,
+		nowFn:               opts.ClockOptions().NowFn(),
+		logger:              iopts.Logger(),
+		metrics:             newReplicatorMetrics(scope),
+		commitLogFilesFn:    commitlog.Files,
+		newIteratorFn:       commitlog.NewIterator,
+		linkFn:              os.Link,
+		closeCh:             make(chan struct{}),
+	}, nil
+}
+
+func (r *replicator) Start() error {
+	r.Lock()
+	defer r.Unlock()
+
+	switch r.state {
+	case replicatorStarted:
+		return errReplicatorAlreadyStarted
+	case replicatorClosed:
+		return errReplicatorClosed
+	}
+
+	dirMode := r.bufferCommitLogOpts.FilesystemOptions().NewDirectoryMode()
+	bufferDir := fs.CommitLogsDirPath(r.opts.BufferFilePathPrefix())
+	if err := os.MkdirAll(bufferDir, dirMode); err != nil {
+		return err
+	}
+
+	checkpoint, err := r.checkpoints.Load()
+	if err != nil {
+		return err
+	}
+
+	r.checkpoint = checkpoint
+	r.state = replicatorStarted
+	r.wg.Add(1)
+	go r.run()
+	if r.reconciler != nil {
+		r.wg.Add(1)
+		go r.reconcileLoop()
+	}
+	return nil
+}
+
+func (r *replicator) Status() Status {
+	r.RLock()
+	status := Status{
+		LastBufferedFile:    r.checkpoint.LastBufferedFile,
+		PendingFiles:        r.pendingFiles,
+		LastReconcile:       r.lastReconcile,
+		LastReconcileResult: r.lastReconcileResult,
+	}
+	r.RUnlock()
+
+	if lastWrite := atomic.LoadInt64(&r.lastWriteNanos); lastWrite > 0 {
+		status.Lag = r.nowFn().Sub(time.Unix(0, lastWrite))
+	}
+	return status
+}
+
+func (r *replicator) Close() error {
+	r.Lock()
+	if r.state != replicatorStarted {
+		r.Unlock()
+		return errReplicatorNotStarted
+	}
+	r.state = replicatorClosed
+	r.Unlock()
+
+	close(r.closeCh)
+	r.wg.Wait()
+	return nil
+}
+
+func (r *replicator) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.opts.ScanInterval())
+	defer ticker.Stop()
+
+	for {
+		if err := r.bufferSealedFiles(); err != nil {
+			r.logger.Errorf("replication could not buffer commit log files: %v", err)
+		}
+		if err := r.replicateBufferedFiles(); err != nil {
+			r.logger.Errorf("replication could not replicate commit log files: %v", err)
+		}
+		r.reportGauges()
+
+		select {
+		case <-r.closeCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *replicator) reportGauges() {
+	status := r.Status()
+	r.metrics.pendingFiles.Update(float64(status.PendingFiles))
+	r.metrics.lagSeconds.Update(status.Lag.Seconds())
+	r.metrics.queueLength.Update(float64(atomic.LoadInt64(&r.queueLength)))
+}
+
+// bufferSealedFiles hard links every sealed commit log file that has not
+// yet been buffered into the buffer directory. Once the buffer is full the
+// remaining files are left for a later scan rather than skipped.
+func (r *replicator) bufferSealedFiles() error {
+	files, _, err := r.commitLogFilesFn(r.opts.CommitLogOptions())
+	if err != nil {
+		return err
+	}
+
+	buffered, _, err := r.commitLogFilesFn(r.bufferCommitLogOpts)
+	if err != nil {
+		return err
+	}
+
+	r.RLock()
+	checkpoint := r.checkpoint
+	r.RUnlock()
+
+	sorted, err := sortedFiles(files)
+	if err != nil || len(sorted) == 0 {
+		return err
+	}
+
+	// The commit log only ever writes to the file with the highest index, so
+	// every other file is sealed. That file is always newer than any buffered
+	// file, so if its index is not higher then the commit log directory was
+	// reset and indexes restarted from zero.
+	active, sealed := sorted[len(sorted)-1], sorted[:len(sorted)-1]
+	if checkpoint.LastBufferedFile != "" && active.index <= checkpoint.LastBufferedIndex {
+		r.logger.Warnf("replication detected commit log index reset, "+
+			"last buffered index %d, active index %d",
+			checkpoint.LastBufferedIndex, active.index)
+		checkpoint.LastBufferedFile = ""
+		checkpoint.LastBufferedIndex = 0
+		if err := r.storeCheckpoint(checkpoint); err != nil {
+			return err
+		}
+	}
+
+	// Never reuse the sequence number of a file still in the buffer, such as
+	// files buffered before sequence numbers were checkpointed.
+	pending, err := sortedFiles(buffered)
+	if err != nil {
+		return err
+	}
+	if n := len(pending); n > 0 && pending[n-1].index > checkpoint.LastBufferSequence {
+		checkpoint.LastBufferSequence = pending[n-1].index
+	}
+
+	numBuffered := len(buffered)
+	for _, file := range sealed {
+		if !checkpoint.before(file) {
+			continue
+		}
+
+		if numBuffered >= r.opts.MaxBufferedFiles() {
+			// The local write path never waits on the remote cluster, files
+			// removed by commit log cleanup before there is room to buffer
+			// them are backfilled by the reconciler.
+			r.metrics.bufferFull.Inc(1)
+			r.logger.Warnf("replication buffer full, deferring commit log file %s",
+				file.file.FilePath)
+			return nil
+		}
+
+		// Commit log file names only hold their index which restarts when the
+		// commit log directory is reset, so buffered files are named by a
+		// sequence number that only ever increases instead. A file already
+		// existing at the target means the buffer and checkpoint disagree and
+		// is an error rather than data silently left unreplicated.
+		sequence := checkpoint.LastBufferSequence + 1
+		target := fs.CommitLogFilePath(r.opts.BufferFilePathPrefix(), bufferFileStart, sequence)
+		if err := r.linkFn(file.file.FilePath, target); err != nil {
+			return fmt.Errorf("could not buffer commit log file %s: %v",
+				file.file.FilePath, err)
+		}
+		numBuffered++
+		r.metrics.bufferedFiles.Inc(1)
+
+		checkpoint = Checkpoint{
+			LastBufferedFile:   file.file.FilePath,
+			LastBufferedIndex:  file.index,
+			LastBufferSequence: sequence,
+		}
+		if err := r.storeCheckpoint(checkpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *replicator) storeCheckpoint(checkpoint Checkpoint) error {
+	if err := r.checkpoints.Store(checkpoint); err != nil {
+		return err
+	}
+
+	r.Lock()
+	r.checkpoint = checkpoint
+	r.Unlock()
+	return nil
+}
+
+// commitLogFile is a commit log file with the index encoded in its name,
+// which is the commit log index for commit log files and the buffer sequence
+// number for buffered files.
+type commitLogFile struct {
+	file  persist.CommitLogFile
+	index int
+}
+
+// before returns whether the checkpoint precedes the commit log file.
+func (c Checkpoint) before(f commitLogFile) bool {
+	return c.LastBufferedFile == "" || f.index > c.LastBufferedIndex
+}
+
+// sortedFiles returns the commit log files ordered by the index in their name.
+func sortedFiles(files persist.CommitLogFiles) ([]commitLogFile, error) {
+	sorted := make([]commitLogFile, 0, len(files))
+	for _, file := range files {
+		_, index, err := fs.TimeAndIndexFromCommitlogFilename(file.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		sorted = append(sorted, commitLogFile{file: file, index: index})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].index < sorted[j].index
+	})
+	return sorted, nil
+}
+
+func (r *replicator) replicateBufferedFiles() error {
+	files, corrupt, err := r.commitLogFilesFn(r.bufferCommitLogOpts)
+	if err != nil {
+		return err
+	}
+
+	for _, errorWithPath := range corrupt {
+		r.logger.Errorf("replication skipping corrupt commit log file %s: %v",
+			errorWithPath.Path(), errorWithPath.Error())
+	}
+
+	sorted, err := sortedFiles(files)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range sorted {
+		file := f.file
+		r.Lock()
+		r.pendingFiles = len(sorted) - i
+		r.Unlock()
+
+		select {
+		case <-r.closeCh:
+			return nil
+		default:
+		}
+
+		if err := r.replicateFile(file); err != nil {
+			r.metrics.failedFiles.Inc(1)
+			// Files must be replicated in order, retry on the next scan.
+			return fmt.Errorf("could not replicate commit log file %s: %v",
+				file.FilePath, err)
+		}
+
+		if err := os.Remove(file.FilePath); err != nil {
+			return err
+		}
+		r.metrics.replicatedFiles.Inc(1)
+	}
+
+	r.Lock()
+	r.pendingFiles = 0
+	r.Unlock()
+	return nil
+}
+
+func (r *replicator) replicateFile(file persist.CommitLogFile) error {
+	shards, err := r.replicatedShards()
+	if err != nil {
+		return err
+	}
+
+	iter, _, err := r.newIteratorFn(commitlog.IteratorOpts{
+		CommitLogOptions: r.bufferCommitLogOpts,
+		FileFilterPredicate: func(f persist.CommitLogFile) bool {
+			return f.FilePath == file.FilePath
+		},
+		SeriesFilterPredicate: commitlog.ReadAllSeriesPredicate(),
+	})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	var (
+		writes    = make(chan replicatorWrite, r.opts.QueueSize())
+		numErrors int64
+		wg        sync.WaitGroup
+	)
+	for i := 0; i < r.opts.WriteConcurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for w := range writes {
+				atomic.AddInt64(&r.queueLength, -1)
+				if err := r.write(w); err != nil {
+					atomic.AddInt64(&numErrors, 1)
+				}
+			}
+		}()
+	}
+
+	for iter.Next() {
+		series, dp, unit, annotation := iter.Current()
+		if shards != nil {
+			if _, ok := shards[series.Shard]; !ok {
+				r.metrics.skippedWrites.Inc(1)
+				continue
+			}
+		}
+
+		// Sending to the channel blocks once the queue is full which applies
+		// backpressure to reading the commit log.
+		atomic.AddInt64(&r.queueLength, 1)
+		writes <- replicatorWrite{
+			namespace:  copyID(series.Namespace),
+			id:         copyID(series.ID),
+			tags:       copyTags(series.Tags),
+			datapoint:  dp,
+			unit:       unit,
+			annotation: append(ts.Annotation(nil), annotation...),
+		}
+	}
+	close(writes)
+	wg.Wait()
+
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if n := atomic.LoadInt64(&numErrors); n > 0 {
+		return fmt.Errorf("%d writes failed", n)
+	}
+	return nil
+}
+
+func (r *replicator) write(w replicatorWrite) error {
+	err := r.opts.WriteRetrier().Attempt(func() error {
+		return r.session.WriteTagged(w.namespace, w.id, ident.NewTagsIterator(w.tags),
+			w.datapoint.Timestamp, w.datapoint.Value, w.unit, w.annotation)
+	})
+	if err != nil {
+		r.metrics.writeErrors.Inc(1)
+		return err
+	}
+
+	r.metrics.writeSuccess.Inc(1)
+	nanos := w.datapoint.Timestamp.UnixNano()
+	for {
+		last := atomic.LoadInt64(&r.lastWriteNanos)
+		if nanos <= last || atomic.CompareAndSwapInt64(&r.lastWriteNanos, last, nanos) {
+			return nil
+		}
+	}
+}
+
+// replicatedShards returns the shards replicated by the local host, nil if
+// there is no topology and every shard is replicated.
+func (r *replicator) replicatedShards() (map[uint32]struct{}, error) {
+	provider := r.opts.TopologyMapProvider()
+	if provider == nil {
+		return nil, nil
+	}
+
+	topoMap, err := provider.TopologyMap()
+	if err != nil {
+		return nil, err
+	}
+	return leaderShards(topoMap, r.opts.Origin().ID()), nil
+}
+
+// leaderShards returns the shards led by a host. Each shard is led by one of
+// its available replicas, picked by the shard ID so that leadership is spread
+// evenly across the hosts, or by one of its replicas if none are available.
+func leaderShards(topoMap topology.Map, hostID string) map[uint32]struct{} {
+	leaders := make(map[uint32]struct{})
+	hostShardSet, ok := topoMap.LookupHostShardSet(hostID)
+	if !ok {
+		return leaders
+	}
+
+	for _, shardID := range hostShardSet.ShardSet().AllIDs() {
+		hosts, err := topoMap.RouteShard(shardID)
+		if err != nil {
+			continue
+		}
+
+		var all, available []string
+		for _, host := range hosts {
+			all = append(all, host.ID())
+			hss, ok := topoMap.LookupHostShardSet(host.ID())
+			if !ok {
+				continue
+			}
+			state, err := hss.ShardSet().LookupStateByID(shardID)
+			if err == nil && state == shard.Available {
+				available = append(available, host.ID())
+			}
+		}
+
+		candidates := available
+		if len(candidates) == 0 {
+			candidates = all
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		sort.Strings(candidates)
+		if candidates[int(shardID)%len(candidates)] == hostID {
+			leaders[shardID] = struct{}{}
+		}
+	}
+	return leaders
+}
+
+func (r *replicator) reconcileLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.opts.ReconcileInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.closeCh:
+			return
+		case <-ticker.C:
+		}
+
+		if err := r.reconcile(); err != nil {
+			r.metrics.reconcileErrors.Inc(1)
+			r.logger.Errorf("replication could not reconcile: %v", err)
+		}
+	}
+}
+
+// reconcile reconciles the flushed blocks within the reconcile lookback of
+// every shard led by the local host.
+func (r *replicator) reconcile() error {
+	namespaces, err := r.opts.NamespacesFn()()
+	if err != nil {
+		return err
+	}
+
+	shards, err := r.replicatedShards()
+	if err != nil {
+		return err
+	}
+
+	var (
+		now       = r.nowFn()
+		total     ReconcileResult
+		numErrors int
+	)
+	for _, nsMetadata := range namespaces {
+		var (
+			ropts     = nsMetadata.Options().RetentionOptions()
+			blockSize = ropts.BlockSize()
+			end       = now.Add(-ropts.BufferPast()).Truncate(blockSize)
+			start     = end.Add(-r.opts.ReconcileLookback()).Truncate(blockSize)
+		)
+		if !start.Before(end) {
+			continue
+		}
+
+		for shardID := range shards {
+			select {
+			case <-r.closeCh:
+				return nil
+			default:
+			}
+
+			res, err := r.reconciler.Reconcile(nsMetadata, shardID, start, end)
+			if err != nil {
+				numErrors++
+				r.logger.Errorf("replication could not reconcile namespace %s shard %d: %v",
+					nsMetadata.ID().String(), shardID, err)
+				continue
+			}
+			total.NumBlocks += res.NumBlocks
+			total.NumMismatchedBlocks += res.NumMismatchedBlocks
+			total.NumBackfilledDatapoints += res.NumBackfilledDatapoints
+		}
+	}
+
+	r.Lock()
+	r.lastReconcile = r.nowFn()
+	r.lastReconcileResult = total
+	r.Unlock()
+
+	if numErrors > 0 {
+		return fmt.Errorf("%d shards failed to reconcile", numErrors)
+	}
+	return nil
+}
+
+func copyID(id ident.ID) ident.ID {
+	return ident.BytesID(append([]byte(nil), id.Bytes()...))
+}
+
+func copyTags(tags ident.Tags) ident.Tags {
+	values := tags.Values()
+	copied := make([]ident.Tag, 0, len(values))
+	for _, tag := range values {
+		copied = append(copied, ident.Tag{
+			Name:  copyID(tag.Name),
+			Value: copyID(tag.Value),
+		})
+	}
+	return ident.NewTags(copied...)
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package replication
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/ident"
+	xretry "github.com/m3db/m3x/retry"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReplicator(t *testing.T, session client.Session) (*replicator, string) {
+	dir, err := ioutil.TempDir("", "replication-buffer")
+	require.NoError(t, err)
+
+	opts := NewOptions().
+		SetBufferFilePathPrefix(dir).
+		SetMaxBufferedFiles(2).
+		SetWriteConcurrency(2).
+		SetQueueSize(4).
+		SetWriteRetrier(xretry.NewRetrier(xretry.NewOptions().SetMaxRetries(0)))
+	r, err := NewReplicator(session, nil, opts)
+	require.NoError(t, err)
+
+	rep := r.(*replicator)
+	require.NoError(t, os.MkdirAll(fs.CommitLogsDirPath(dir), 0755))
+	return rep, dir
+}
+
+func sortedFilePaths(t *testing.T, files persist.CommitLogFiles) []string {
+	sorted, err := sortedFiles(files)
+	require.NoError(t, err)
+	var paths []string
+	for _, file := range sorted {
+		paths = append(paths, file.file.FilePath)
+	}
+	return paths
+}
+
+func TestSortedFilesOrderedByIndex(t *testing.T) {
+	files := persist.CommitLogFiles{
+		{FilePath: "/commitlogs/commitlog-0-10.db", Index: 10},
+		{FilePath: "/commitlogs/commitlog-0-2.db", Index: 2},
+		{FilePath: "/commitlogs/commitlog-0-9.db", Index: 9},
+	}
+	require.Equal(t, []string{
+		"/commitlogs/commitlog-0-2.db",
+		"/commitlogs/commitlog-0-9.db",
+		"/commitlogs/commitlog-0-10.db",
+	}, sortedFilePaths(t, files))
+	require.Empty(t, sortedFilePaths(t, nil))
+}
+
+func TestCheckpointBefore(t *testing.T) {
+	sorted, err := sortedFiles(persist.CommitLogFiles{
+		{FilePath: "/commitlogs/commitlog-0-6.db", Index: 6},
+		{FilePath: "/commitlogs/commitlog-0-7.db", Index: 7},
+		{FilePath: "/commitlogs/commitlog-0-8.db", Index: 8},
+	})
+	require.NoError(t, err)
+
+	checkpoint := Checkpoint{}
+	require.True(t, checkpoint.before(sorted[0]))
+
+	checkpoint = Checkpoint{
+		LastBufferedFile:   "/commitlogs/commitlog-0-7.db",
+		LastBufferedIndex:  7,
+		LastBufferSequence: 3,
+	}
+	require.False(t, checkpoint.before(sorted[0]))
+	require.False(t, checkpoint.before(sorted[1]))
+	require.True(t, checkpoint.before(sorted[2]))
+}
+
+func TestLeaderShards(t *testing.T) {
+	var (
+		shardSet = func(state shard.State, ids ...uint32) sharding.ShardSet {
+			set, err := sharding.NewShardSet(sharding.NewShards(ids, state),
+				sharding.DefaultHashFn(4))
+			require.NoError(t, err)
+			return set
+		}
+		hostA = topology.NewHost("a", "a:9000")
+		hostB = topology.NewHost("b", "b:9000")
+		hostC = topology.NewHost("c", "c:9000")
+	)
+	topoMap := topology.NewStaticMap(topology.NewStaticOptions().
+		SetReplicas(2).
+		SetShardSet(shardSet(shard.Available, 0, 1, 2, 3)).
+		SetHostShardSets([]topology.HostShardSet{
+			topology.NewHostShardSet(hostA, shardSet(shard.Available, 0, 1, 2, 3)),
+			topology.NewHostShardSet(hostB, shardSet(shard.Available, 0, 1)),
+			topology.NewHostShardSet(hostC, shardSet(shard.Initializing, 2, 3)),
+		}))
+
+	// Shards 0 and 1 alternate between a and b, c is initializing so a leads
+	// shards 2 and 3.
+	require.Equal(t, map[uint32]struct{}{0: {}, 2: {}, 3: {}}, leaderShards(topoMap, "a"))
+	require.Equal(t, map[uint32]struct{}{1: {}}, leaderShards(topoMap, "b"))
+	require.Empty(t, leaderShards(topoMap, "c"))
+	require.Empty(t, leaderShards(topoMap, "d"))
+}
+
+func TestReplicatorBufferSealedFiles(t *testing.T) {
+	r, dir := newTestReplicator(t, nil)
+	defer os.RemoveAll(dir)
+
+	source := persist.CommitLogFiles{
+		{FilePath: "/commitlogs/commitlog-0-1.db", Index: 1},
+		{FilePath: "/commitlogs/commitlog-0-2.db", Index: 2},
+		{FilePath: "/commitlogs/commitlog-0-3.db", Index: 3},
+		{FilePath: "/commitlogs/commitlog-0-4.db", Index: 4},
+	}
+	r.commitLogFilesFn = func(opts commitlog.Options) (persist.CommitLogFiles, []commitlog.ErrorWithPath, error) {
+		if opts == r.bufferCommitLogOpts {
+			return nil, nil, nil
+		}
+		return source, nil, nil
+	}
+
+	var linked []string
+	r.linkFn = func(oldname, newname string) error {
+		linked = append(linked, newname)
+		return nil
+	}
+
+	require.NoError(t, r.bufferSealedFiles())
+
+	// Max buffered files is two, the third sealed file is deferred until
+	// there is room and the active file is never buffered.
+	bufferDir := fs.CommitLogsDirPath(dir)
+	require.Equal(t, []string{
+		path.Join(bufferDir, "commitlog-0-1.db"),
+		path.Join(bufferDir, "commitlog-0-2.db"),
+	}, linked)
+	require.Equal(t, "/commitlogs/commitlog-0-2.db", r.Status().LastBufferedFile)
+
+	checkpoint, err := r.checkpoints.Load()
+	require.NoError(t, err)
+	require.Equal(t, "/commitlogs/commitlog-0-2.db", checkpoint.LastBufferedFile)
+
+	// Once the buffered files are replicated the deferred file is buffered.
+	linked = nil
+	r.commitLogFilesFn = func(opts commitlog.Options) (persist.CommitLogFiles, []commitlog.ErrorWithPath, error) {
+		if opts == r.bufferCommitLogOpts {
+			return nil, nil, nil
+		}
+		return source[2:], nil, nil
+	}
+	require.NoError(t, r.bufferSealedFiles())
+	require.Equal(t, []string{path.Join(bufferDir, "commitlog-0-3.db")}, linked)
+}
+
+func TestReplicatorBufferSealedFilesAfterIndexReset(t *testing.T) {
+	r, dir := newTestReplicator(t, nil)
+	defer os.RemoveAll(dir)
+
+	r.checkpoint = Checkpoint{
+		LastBufferedFile:   "/commitlogs/commitlog-0-7.db",
+		LastBufferedIndex:  7,
+		LastBufferSequence: 4,
+	}
+
+	// The commit log directory was reset so indexes restarted from zero, the
+	// sealed files are newer than the checkpoint despite their lower index.
+	r.commitLogFilesFn = func(opts commitlog.Options) (persist.CommitLogFiles, []commitlog.ErrorWithPath, error) {
+		if opts == r.bufferCommitLogOpts {
+			return nil, nil, nil
+		}
+		return persist.CommitLogFiles{
+			{FilePath: "/commitlogs/commitlog-0-0.db", Index: 0},
+			{FilePath: "/commitlogs/commitlog-0-1.db", Index: 1},
+			{FilePath: "/commitlogs/commitlog-0-2.db", Index: 2},
+		}, nil, nil
+	}
+
+	var linked []string
+	r.linkFn = func(oldname, newname string) error {
+		linked = append(linked, oldname+" "+newname)
+		return nil
+	}
+
+	require.NoError(t, r.bufferSealedFiles())
+
+	bufferDir := fs.CommitLogsDirPath(dir)
+	require.Equal(t, []string{
+		"/commitlogs/commitlog-0-0.db " + path.Join(bufferDir, "commitlog-0-5.db"),
+		"/commitlogs/commitlog-0-1.db " + path.Join(bufferDir, "commitlog-0-6.db"),
+	}, linked)
+
+	checkpoint, err := r.checkpoints.Load()
+	require.NoError(t, err)
+	require.Equal(t, Checkpoint{
+		LastBufferedFile:   "/commitlogs/commitlog-0-1.db",
+		LastBufferedIndex:  1,
+		LastBufferSequence: 6,
+	}, checkpoint)
+}
+
+func TestReplicatorBufferSealedFilesLinkCollision(t *testing.T) {
+	r, dir := newTestReplicator(t, nil)
+	defer os.RemoveAll(dir)
+
+	r.commitLogFilesFn = func(opts commitlog.Options) (persist.CommitLogFiles, []commitlog.ErrorWithPath, error) {
+		if opts == r.bufferCommitLogOpts {
+			return nil, nil, nil
+		}
+		return persist.CommitLogFiles{
+			{FilePath: "/commitlogs/commitlog-0-0.db", Index: 0},
+			{FilePath: "/commitlogs/commitlog-0-1.db", Index: 1},
+		}, nil, nil
+	}
+	r.linkFn = func(oldname, newname string) error {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: os.ErrExist}
+	}
+
+	// A pending buffered file must never be mistaken for the file to buffer.
+	require.Error(t, r.bufferSealedFiles())
+	require.Equal(t, Checkpoint{}, r.checkpoint)
+}
+
+func TestReplicatorBufferSealedFilesSkipsPendingSequences(t *testing.T) {
+	r, dir := newTestReplicator(t, nil)
+	defer os.RemoveAll(dir)
+
+	bufferDir := fs.CommitLogsDirPath(dir)
+	r.commitLogFilesFn = func(opts commitlog.Options) (persist.CommitLogFiles, []commitlog.ErrorWithPath, error) {
+		if opts == r.bufferCommitLogOpts {
+			return persist.CommitLogFiles{
+				{FilePath: path.Join(bufferDir, "commitlog-0-3.db"), Index: 3},
+			}, nil, nil
+		}
+		return persist.CommitLogFiles{
+			{FilePath: "/commitlogs/commitlog-0-10.db", Index: 10},
+			{FilePath: "/commitlogs/commitlog-0-11.db", Index: 11},
+		}, nil, nil
+	}
+
+	var linked []string
+	r.linkFn = func(oldname, newname string) error {
+		linked = append(linked, newname)
+		return nil
+	}
+
+	require.NoError(t, r.bufferSealedFiles())
+	require.Equal(t, []string{path.Join(bufferDir, "commitlog-0-4.db")}, linked)
+}
+
+func TestReplicatorReplicateFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockSession(ctrl)
+	r, dir := newTestReplicator(t, session)
+	defer os.RemoveAll(dir)
+
+	var (
+		now    = time.Now().Truncate(time.Second)
+		series = ts.Series{
+			Namespace: ident.StringID("ns"),
+			ID:        ident.StringID("foo"),
+			Tags:      ident.NewTags(ident.StringTag("bar", "baz")),
+		}
+		iter = commitlog.NewMockIterator(ctrl)
+	)
+	gomock.InOrder(
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(series,
+			ts.Datapoint{Timestamp: now, Value: 1}, xtime.Second, ts.Annotation(nil)),
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(series,
+			ts.Datapoint{Timestamp: now.Add(time.Second), Value: 2}, xtime.Second, ts.Annotation(nil)),
+		iter.EXPECT().Next().Return(false),
+	)
+	iter.EXPECT().Err().Return(nil)
+	iter.EXPECT().Close()
+	r.newIteratorFn = func(commitlog.IteratorOpts) (commitlog.Iterator, []commitlog.ErrorWithPath, error) {
+		return iter, nil, nil
+	}
+
+	session.EXPECT().
+		WriteTagged(ident.NewIDMatcher("ns"), ident.NewIDMatcher("foo"),
+			gomock.Any(), gomock.Any(), gomock.Any(), xtime.Second, gomock.Any()).
+		Return(nil).
+		Times(2)
+
+	require.NoError(t, r.replicateFile(persist.CommitLogFile{Index: 1}))
+	require.Equal(t, now.Add(time.Second).UnixNano(), r.lastWriteNanos)
+}
+
+func TestReplicatorReplicateFileWriteError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockSession(ctrl)
+	r, dir := newTestReplicator(t, session)
+	defer os.RemoveAll(dir)
+
+	iter := commitlog.NewMockIterator(ctrl)
+	gomock.InOrder(
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(ts.Series{
+			Namespace: ident.StringID("ns"),
+			ID:        ident.StringID("foo"),
+		}, ts.Datapoint{Timestamp: time.Now(), Value: 1}, xtime.Second, ts.Annotation(nil)),
+		iter.EXPECT().Next().Return(false),
+	)
+	iter.EXPECT().Err().Return(nil)
+	iter.EXPECT().Close()
+	r.newIteratorFn = func(commitlog.IteratorOpts) (commitlog.Iterator, []commitlog.ErrorWithPath, error) {
+		return iter, nil, nil
+	}
+
+	session.EXPECT().
+		WriteTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+			gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("unavailable"))
+
+	require.Error(t, r.replicateFile(persist.CommitLogFile{Index: 1}))
+	require.Equal(t, int64(0), r.lastWriteNanos)
+}
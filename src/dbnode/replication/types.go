@@ -0,0 +1,238 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package replication provides asynchronous replication of writes from a
+// local M3DB cluster to an independent remote cluster.
+package replication
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3x/instrument"
+	xretry "github.com/m3db/m3x/retry"
+)
+
+// Replicator asynchronously replicates sealed commit log files to a
+// remote cluster.
+type Replicator interface {
+	// Start starts buffering and replicating commit log files in the background.
+	Start() error
+
+	// Status returns the current replication status.
+	Status() Status
+
+	// Close stops replication, any buffered files are kept and replication
+	// resumes from them the next time the replicator is started.
+	Close() error
+}
+
+// Status describes the progress of a replicator.
+type Status struct {
+	// LastBufferedFile is the path of the most recent commit log file that
+	// has been durably buffered for replication.
+	LastBufferedFile string `json:"lastBufferedFile"`
+
+	// PendingFiles is the number of buffered commit log files that are
+	// yet to be replicated.
+	PendingFiles int `json:"pendingFiles"`
+
+	// Lag is the difference between now and the timestamp of the most
+	// recently replicated datapoint.
+	Lag time.Duration `json:"lag"`
+
+	// LastReconcile is the time the most recent reconciliation finished.
+	LastReconcile time.Time `json:"lastReconcile"`
+
+	// LastReconcileResult is the result of the most recent reconciliation
+	// summed over the reconciled shards.
+	LastReconcileResult ReconcileResult `json:"lastReconcileResult"`
+}
+
+// Checkpoint is the durable replication progress of a replicator. Commit
+// log indexes restart from zero when the commit log directory is reset, so
+// buffered files are named by a sequence number that never restarts.
+type Checkpoint struct {
+	// LastBufferedFile is the path of the most recent commit log file that
+	// has been buffered for replication, empty if none have been since the
+	// commit log indexes last restarted.
+	LastBufferedFile string `json:"lastBufferedFile"`
+
+	// LastBufferedIndex is the index of the most recent commit log file that
+	// has been buffered for replication.
+	LastBufferedIndex int `json:"lastBufferedIndex"`
+
+	// LastBufferSequence is the sequence number of the most recently buffered
+	// file, which names the file in the buffer directory.
+	LastBufferSequence int `json:"lastBufferSequence"`
+}
+
+// CheckpointStore loads and stores replication checkpoints.
+type CheckpointStore interface {
+	// Load returns the last stored checkpoint.
+	Load() (Checkpoint, error)
+
+	// Store durably persists a checkpoint.
+	Store(value Checkpoint) error
+}
+
+// Reconciler detects and backfills gaps between the local and the remote
+// cluster by comparing block checksums.
+type Reconciler interface {
+	// Reconcile compares the blocks of a shard in the given time range and
+	// writes any series whose blocks differ to the remote cluster.
+	Reconcile(
+		nsMetadata namespace.Metadata,
+		shard uint32,
+		start, end time.Time,
+	) (ReconcileResult, error)
+}
+
+// ReconcileResult is the result of reconciling a single shard.
+type ReconcileResult struct {
+	// NumBlocks is the number of local blocks compared.
+	NumBlocks int64 `json:"numBlocks"`
+
+	// NumMismatchedBlocks is the number of local blocks that were missing
+	// or had a differing checksum on the remote cluster.
+	NumMismatchedBlocks int64 `json:"numMismatchedBlocks"`
+
+	// NumBackfilledDatapoints is the number of datapoints written to the
+	// remote cluster.
+	NumBackfilledDatapoints int64 `json:"numBackfilledDatapoints"`
+}
+
+// NamespacesFn returns the namespaces to reconcile.
+type NamespacesFn func() ([]namespace.Metadata, error)
+
+// Options are the replication options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetClockOptions sets the clock options.
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrumentation options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrumentation options.
+	InstrumentOptions() instrument.Options
+
+	// SetCommitLogOptions sets the commit log options of the local node,
+	// used to locate the commit log files to replicate.
+	SetCommitLogOptions(value commitlog.Options) Options
+
+	// CommitLogOptions returns the commit log options of the local node.
+	CommitLogOptions() commitlog.Options
+
+	// SetBufferFilePathPrefix sets the file path prefix of the directory
+	// sealed commit log files are buffered in until they are replicated, it
+	// must be on the same filesystem as the commit log directory.
+	SetBufferFilePathPrefix(value string) Options
+
+	// BufferFilePathPrefix returns the file path prefix of the buffer directory.
+	BufferFilePathPrefix() string
+
+	// SetMaxBufferedFiles sets the maximum number of commit log files to
+	// buffer, once reached newly sealed files are left unbuffered until
+	// replication catches up, files removed by commit log cleanup in the
+	// meantime are recovered by the reconciler.
+	SetMaxBufferedFiles(value int) Options
+
+	// MaxBufferedFiles returns the maximum number of commit log files to buffer.
+	MaxBufferedFiles() int
+
+	// SetScanInterval sets the interval at which the commit log directory
+	// is scanned for newly sealed files.
+	SetScanInterval(value time.Duration) Options
+
+	// ScanInterval returns the commit log scan interval.
+	ScanInterval() time.Duration
+
+	// SetQueueSize sets the size of the queue of writes pending to be sent
+	// to the remote cluster, reading commit log files blocks while it is full.
+	SetQueueSize(value int) Options
+
+	// QueueSize returns the size of the queue of pending writes.
+	QueueSize() int
+
+	// SetWriteConcurrency sets the number of concurrent writers to the
+	// remote cluster.
+	SetWriteConcurrency(value int) Options
+
+	// WriteConcurrency returns the number of concurrent writers.
+	WriteConcurrency() int
+
+	// SetWriteRetrier sets the retrier used for writes to the remote cluster.
+	SetWriteRetrier(value xretry.Retrier) Options
+
+	// WriteRetrier returns the retrier used for writes to the remote cluster.
+	WriteRetrier() xretry.Retrier
+
+	// SetReconcileConsistencyLevel sets the read consistency level used
+	// when fetching blocks metadata for reconciliation.
+	SetReconcileConsistencyLevel(value topology.ReadConsistencyLevel) Options
+
+	// ReconcileConsistencyLevel returns the reconcile read consistency level.
+	ReconcileConsistencyLevel() topology.ReadConsistencyLevel
+
+	// SetReconcileInterval sets the interval at which flushed blocks are
+	// reconciled with the remote cluster.
+	SetReconcileInterval(value time.Duration) Options
+
+	// ReconcileInterval returns the reconcile interval.
+	ReconcileInterval() time.Duration
+
+	// SetReconcileLookback sets how far back from the most recently flushed
+	// block to reconcile.
+	SetReconcileLookback(value time.Duration) Options
+
+	// ReconcileLookback returns the reconcile lookback.
+	ReconcileLookback() time.Duration
+
+	// SetNamespacesFn sets the function returning the namespaces to reconcile.
+	SetNamespacesFn(value NamespacesFn) Options
+
+	// NamespacesFn returns the function returning the namespaces to reconcile.
+	NamespacesFn() NamespacesFn
+
+	// SetOrigin sets the local host, every shard is replicated by a single
+	// one of its replicas so that writes are not sent to the remote cluster
+	// once per replica.
+	SetOrigin(value topology.Host) Options
+
+	// Origin returns the local host.
+	Origin() topology.Host
+
+	// SetTopologyMapProvider sets the provider of the local topology used to
+	// determine the shards replicated by the local host, if not set every
+	// shard in the commit log is replicated.
+	SetTopologyMapProvider(value topology.MapProvider) Options
+
+	// TopologyMapProvider returns the local topology map provider.
+	TopologyMapProvider() topology.MapProvider
+}
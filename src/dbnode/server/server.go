@@ -45,6 +45,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/environment"
 	"github.com/m3db/m3/src/dbnode/kvconfig"
+	"github.com/m3db/m3/src/dbnode/network/server/httpjson"
 	hjcluster "github.com/m3db/m3/src/dbnode/network/server/httpjson/cluster"
 	hjnode "github.com/m3db/m3/src/dbnode/network/server/httpjson/node"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
@@ -53,6 +54,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/ratelimit"
+	"github.com/m3db/m3/src/dbnode/replication"
 	"github.com/m3db/m3/src/dbnode/retention"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage"
@@ -513,6 +515,9 @@ func Run(runOpts RunOptions) {
 		SetInstrumentOptions(opts.InstrumentOptions()).
 		SetTagEncoderPool(tagEncoderPool).
 		SetTagDecoderPool(tagDecoderPool)
+	if cfg.MaxWriteBatchDecodedBytes > 0 {
+		ttopts = ttopts.SetMaxWriteBatchDecodedBytes(cfg.MaxWriteBatchDecodedBytes)
+	}
 
 	// Set bootstrap options - We need to create a topology map provider from the
 	// same topology that will be passed to the cluster so that when we make
@@ -584,8 +589,25 @@ func Run(runOpts RunOptions) {
 	defer tchannelthriftClusterClose()
 	logger.Infof("cluster tchannelthrift: listening on %v", cfg.ClusterListenAddress)
 
+	httpjsonNodeOpts := httpjson.NewServerOptions()
+	if cfg.Replication != nil && cfg.Replication.Enabled {
+		replicator, err := newReplicator(*cfg.Replication, opts, db,
+			m3dbClient, origin, topoMapProvider)
+		if err != nil {
+			logger.Fatalf("could not create replicator: %v", err)
+		}
+		if err := replicator.Start(); err != nil {
+			logger.Fatalf("could not start replicator: %v", err)
+		}
+		defer replicator.Close()
+		httpjsonNodeOpts = httpjsonNodeOpts.SetHandlers(map[string]http.Handler{
+			replication.StatusURL: replication.NewStatusHandler(replicator),
+		})
+		logger.Infof("replication: buffering in %s", cfg.Replication.BufferDirectory)
+	}
+
 	httpjsonNodeClose, err := hjnode.NewServer(service,
-		cfg.HTTPNodeListenAddress, contextPool, nil).ListenAndServe()
+		cfg.HTTPNodeListenAddress, contextPool, httpjsonNodeOpts).ListenAndServe()
 	if err != nil {
 		logger.Fatalf("could not open httpjson interface on %s: %v",
 			cfg.HTTPNodeListenAddress, err)
@@ -965,6 +987,72 @@ func kvWatchBootstrappers(
 	}
 }
 
+func newReplicator(
+	policy config.ReplicationPolicy,
+	opts storage.Options,
+	db storage.Database,
+	localClient client.AdminClient,
+	origin topology.Host,
+	topoMapProvider topology.MapProvider,
+) (replication.Replicator, error) {
+	iopts := opts.InstrumentOptions()
+	remoteClient, err := policy.Client.NewAdminClient(client.ConfigurationParameters{
+		InstrumentOptions: iopts.
+			SetMetricsScope(iopts.MetricsScope().SubScope("m3dbclient-replication")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := remoteClient.NewAdminSession()
+	if err != nil {
+		return nil, err
+	}
+
+	namespacesFn := func() ([]namespace.Metadata, error) {
+		namespaces := db.Namespaces()
+		metadatas := make([]namespace.Metadata, 0, len(namespaces))
+		for _, ns := range namespaces {
+			md, err := namespace.NewMetadata(ns.ID(), ns.Options())
+			if err != nil {
+				return nil, err
+			}
+			metadatas = append(metadatas, md)
+		}
+		return metadatas, nil
+	}
+
+	ropts := replication.NewOptions().
+		SetClockOptions(opts.ClockOptions()).
+		SetInstrumentOptions(iopts).
+		SetCommitLogOptions(opts.CommitLogOptions()).
+		SetBufferFilePathPrefix(policy.BufferDirectory).
+		SetOrigin(origin).
+		SetTopologyMapProvider(topoMapProvider).
+		SetNamespacesFn(namespacesFn)
+	if policy.MaxBufferedFiles > 0 {
+		ropts = ropts.SetMaxBufferedFiles(policy.MaxBufferedFiles)
+	}
+	if policy.ScanInterval > 0 {
+		ropts = ropts.SetScanInterval(policy.ScanInterval)
+	}
+	if policy.QueueSize > 0 {
+		ropts = ropts.SetQueueSize(policy.QueueSize)
+	}
+	if policy.WriteConcurrency > 0 {
+		ropts = ropts.SetWriteConcurrency(policy.WriteConcurrency)
+	}
+	if policy.ReconcileInterval > 0 {
+		ropts = ropts.SetReconcileInterval(policy.ReconcileInterval)
+	}
+	if policy.ReconcileLookback > 0 {
+		ropts = ropts.SetReconcileLookback(policy.ReconcileLookback)
+	}
+
+	reconciler := replication.NewReconciler(localClient, session, ropts)
+	return replication.NewReplicator(session, reconciler, ropts)
+}
+
 func withEncodingAndPoolingOptions(
 	cfg config.DBConfiguration,
 	logger xlog.Logger,
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"sync"
 	"time"
 
@@ -57,10 +58,17 @@ var (
 
 const encoderChanBufSize = 1000
 
+const (
+	phaseReadSnapshotMetadata = "read-snapshot-metadata"
+	phaseReadCommitLogs       = "read-commitlogs"
+	phaseMergeSnapshots       = "read-snapshots-and-merge"
+)
+
 type newIteratorFn func(opts commitlog.IteratorOpts) (
 	iter commitlog.Iterator, corruptFiles []commitlog.ErrorWithPath, err error)
 type snapshotFilesFn func(filePathPrefix string, namespace ident.ID, shard uint32) (fs.FileSetFilesSlice, error)
 type newReaderFn func(bytesPool pool.CheckedBytesPool, opts fs.Options) (fs.DataFileSetReader, error)
+type snapshotMetadataFilesFn func(opts fs.Options) ([]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error)
 
 type commitLogSource struct {
 	opts Options
@@ -69,9 +77,10 @@ type commitLogSource struct {
 	// Filesystem inspection capture before node was started.
 	inspection fs.Inspection
 
-	newIteratorFn   newIteratorFn
-	snapshotFilesFn snapshotFilesFn
-	newReaderFn     newReaderFn
+	newIteratorFn           newIteratorFn
+	snapshotFilesFn         snapshotFilesFn
+	snapshotMetadataFilesFn snapshotMetadataFilesFn
+	newReaderFn             newReaderFn
 
 	metrics commitLogSourceDataAndIndexMetrics
 }
@@ -98,9 +107,10 @@ func newCommitLogSource(opts Options, inspection fs.Inspection) bootstrap.Source
 
 		inspection: inspection,
 
-		newIteratorFn:   commitlog.NewIterator,
-		snapshotFilesFn: fs.SnapshotFiles,
-		newReaderFn:     fs.NewReader,
+		newIteratorFn:           commitlog.NewIterator,
+		snapshotFilesFn:         fs.SnapshotFiles,
+		snapshotMetadataFilesFn: fs.SortedSnapshotMetadataFiles,
+		newReaderFn:             fs.NewReader,
 
 		metrics: newCommitLogSourceDataAndIndexMetrics(scope),
 	}
@@ -122,10 +132,8 @@ func (s *commitLogSource) AvailableData(
 	return s.availability(ns, shardsTimeRanges, runOpts)
 }
 
-// ReadData will read all commitlog files on disk, as well as as the latest snapshot for
-// each shard/block combination (if it exists) and merge them.
-// TODO(rartoul): Make this take the SnapshotMetadata files into account to reduce the
-// number of commitlogs / snapshots that we need to read.
+// ReadData will read the latest snapshot for each shard/block combination (if it exists)
+// and merge it with the commitlog files that contain writes the snapshots did not capture.
 func (s *commitLogSource) ReadData(
 	ns namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
@@ -141,10 +149,14 @@ func (s *commitLogSource) ReadData(
 		encounteredCorruptData = false
 		fsOpts                 = s.opts.CommitLogOptions().FilesystemOptions()
 		filePathPrefix         = fsOpts.FilePathPrefix()
+		progress               = s.opts.ResultOptions().Progress()
 	)
 	defer doneReadingData()
+	defer progress.Finish(CommitLogBootstrapperName, ns.ID())
 
 	// Determine which snapshot files are available.
+	progress.StartPhase(CommitLogBootstrapperName, ns.ID(), phaseReadSnapshotMetadata)
+	progress.RequestShards(CommitLogBootstrapperName, ns.ID(), shardsTimeRanges)
 	snapshotFilesByShard, err := s.snapshotFilesByShard(
 		ns.ID(), filePathPrefix, shardsTimeRanges)
 	if err != nil {
@@ -195,6 +207,7 @@ func (s *commitLogSource) ReadData(
 		s.log.Infof("datapointsRead: %d", datapointsRead)
 	}()
 
+	progress.StartPhase(CommitLogBootstrapperName, ns.ID(), phaseReadCommitLogs)
 	iter, corruptFiles, err := s.newIteratorFn(iterOpts)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create commit log iterator: %v", err)
@@ -283,6 +296,7 @@ func (s *commitLogSource) ReadData(
 	// Merge all the different encoders from the commit log that we created with
 	// the data that is available in the snapshot files.
 	s.log.Infof("starting merge...")
+	progress.StartPhase(CommitLogBootstrapperName, ns.ID(), phaseMergeSnapshots)
 	mergeStart := time.Now()
 	bootstrapResult, err := s.mergeAllShardsCommitLogEncodersAndSnapshots(
 		ns,
@@ -656,17 +670,97 @@ func (s *commitLogSource) newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
 		}
 	}
 
+	minCommitlogIndex, ok := s.minimumRequiredCommitlogIndex(mostRecentCompleteSnapshotByBlockShard)
+	if ok {
+		s.log.Infof(
+			"snapshots cover all commitlog files with index lower than: %d", minCommitlogIndex)
+	} else {
+		s.log.Infof("snapshots do not cover all shards and blocks, reading all commitlog files")
+		minCommitlogIndex = 0
+	}
+
 	return func(f persist.CommitLogFile) bool {
 		// Read all the commitlog files that were available on disk before the node started
-		// accepting writes.
-		// TODO(rartoul): Refactor this to take the SnapshotMetadata files into account to reduce
-		// the number of commitlog files that need to be read.
+		// accepting writes and that contain writes not captured by the snapshots.
 		commitlogFilesPresentBeforeStart := s.inspection.CommitLogFilesSet()
-		_, ok := commitlogFilesPresentBeforeStart[f.FilePath]
-		return ok
+		if _, ok := commitlogFilesPresentBeforeStart[f.FilePath]; !ok {
+			return false
+		}
+		return f.Index >= minCommitlogIndex
 	}, mostRecentCompleteSnapshotByBlockShard, nil
 }
 
+// minimumRequiredCommitlogIndex returns the index of the oldest commitlog file that
+// contains writes which were not captured by the most recent complete snapshot of
+// every shard/block combination. Every snapshot is tied to the commitlog file that
+// was active when it was taken using the snapshot metadata files, so each shard only
+// needs the commitlog files from that point onwards. Since commitlog files are shared
+// by all shards the minimum over all of them is returned. The second return value is
+// false if any shard/block combination can not be tied to a snapshot metadata file,
+// in which case all the commitlog files need to be read.
+func (s *commitLogSource) minimumRequiredCommitlogIndex(
+	mostRecentCompleteSnapshotByBlockShard map[xtime.UnixNano]map[uint32]fs.FileSetFile,
+) (int64, bool) {
+	snapshotMetadatas, metadataErrs, err := s.snapshotMetadataFilesFn(
+		s.opts.CommitLogOptions().FilesystemOptions())
+	if err != nil {
+		s.log.Errorf("unable to read snapshot metadata files: %v", err)
+		return 0, false
+	}
+	for _, metadataErr := range metadataErrs {
+		s.log.
+			WithFields(
+				xlog.NewField("metadataFilePath", metadataErr.MetadataFilePath),
+				xlog.NewField("checkpointFilePath", metadataErr.CheckpointFilePath),
+				xlog.NewField("error", metadataErr.Error.Error()),
+			).
+			Warn("skipping corrupt snapshot metadata file")
+	}
+
+	commitlogIndexBySnapshotID := make(map[string]int64, len(snapshotMetadatas))
+	for _, metadata := range snapshotMetadatas {
+		commitlogIndexBySnapshotID[metadata.ID.UUID.String()] = metadata.CommitlogIdentifier.Index
+	}
+
+	minIndexByShard := make(map[uint32]int64)
+	for block, mostRecentByShard := range mostRecentCompleteSnapshotByBlockShard {
+		for shard, mostRecent := range mostRecentByShard {
+			if mostRecent.CachedSnapshotID == nil {
+				// No snapshot for this shard/block combination so the commitlog is
+				// the only source of its data.
+				return 0, false
+			}
+
+			index, ok := commitlogIndexBySnapshotID[mostRecent.CachedSnapshotID.String()]
+			if !ok {
+				s.log.Debugf(
+					"no snapshot metadata for shard: %d and block: %s with snapshot ID: %s",
+					shard, block.ToTime().String(), mostRecent.CachedSnapshotID.String())
+				return 0, false
+			}
+
+			if existing, ok := minIndexByShard[shard]; !ok || index < existing {
+				minIndexByShard[shard] = index
+			}
+		}
+	}
+
+	if len(minIndexByShard) == 0 {
+		return 0, false
+	}
+
+	minIndex := int64(math.MaxInt64)
+	for shard, index := range minIndexByShard {
+		s.log.Debugf(
+			"shard: %d requires commitlog files with index greater than or equal to: %d",
+			shard, index)
+		if index < minIndex {
+			minIndex = index
+		}
+	}
+	return minIndex, true
+}
+
 func (s *commitLogSource) startM3TSZEncodingWorker(
 	ns namespace.Metadata,
 	runOpts bootstrap.RunOptions,
@@ -820,6 +914,7 @@ func (s *commitLogSource) mergeAllShardsCommitLogEncodersAndSnapshots(
 		// Controls how many shards can be merged in parallel
 		workerPool          = xsync.NewWorkerPool(s.opts.MergeShardsConcurrency())
 		bootstrapResultLock sync.Mutex
+		firstErr            error
 		wg                  sync.WaitGroup
 	)
 	workerPool.Init()
@@ -830,36 +925,58 @@ func (s *commitLogSource) mergeAllShardsCommitLogEncodersAndSnapshots(
 			continue
 		}
 
-		snapshotData, err := s.bootstrapShardSnapshots(
-			ns.ID(),
-			uint32(shard),
-			false,
-			shardsTimeRanges[uint32(shard)],
-			blockSize,
-			snapshotFiles[uint32(shard)],
-			mostRecentCompleteSnapshotByBlockShard,
-		)
-		if err != nil {
-			bootstrapResultLock.Lock()
-			// Mark the shard time ranges as unfulfilled so a subsequent bootstrapper
-			// has the chance to fulfill it.
-			bootstrapResult.Add(
+		// Read the snapshots and merge them with the commit log data, snapshots
+		// for different shards are read in parallel.
+		wg.Add(1)
+		shard, unmergedShard := shard, unmergedShard
+		mergeShardFunc := func() {
+			defer wg.Done()
+
+			snapshotData, err := s.bootstrapShardSnapshots(
+				ns.ID(),
 				uint32(shard),
-				result.NewShardResult(0, s.opts.ResultOptions()),
+				false,
 				shardsTimeRanges[uint32(shard)],
+				blockSize,
+				snapshotFiles[uint32(shard)],
+				mostRecentCompleteSnapshotByBlockShard,
 			)
-			bootstrapResultLock.Unlock()
-			return nil, err
-		}
+			if err != nil {
+				bootstrapResultLock.Lock()
+				// Mark the shard time ranges as unfulfilled so a subsequent bootstrapper
+				// has the chance to fulfill it.
+				bootstrapResult.Add(
+					uint32(shard),
+					result.NewShardResult(0, s.opts.ResultOptions()),
+					shardsTimeRanges[uint32(shard)],
+				)
+				if firstErr == nil {
+					firstErr = err
+				}
+				bootstrapResultLock.Unlock()
+				return
+			}
+
+			snapshotBytes := result.ShardResultDataBytes(snapshotData)
 
-		// Merge snapshot and commit log data
-		wg.Add(1)
-		shard, unmergedShard := shard, unmergedShard
-		mergeShardFunc := func() {
 			var shardResult result.ShardResult
 			shardResult, shardEmptyErrs[shard], shardErrs[shard] = s.mergeShardCommitLogEncodersAndSnapshots(
 				shard, snapshotData, unmergedShard, blockSize)
 
+			if shardEmptyErrs[shard] == 0 && shardErrs[shard] == 0 {
+				var numSeries int64
+				if shardResult != nil {
+					numSeries = shardResult.NumSeries()
+				}
+				s.opts.ResultOptions().Progress().UpdateShard(
+					CommitLogBootstrapperName, ns.ID(), uint32(shard),
+					result.ShardProgressUpdate{
+						Fulfilled:    shardsTimeRanges[uint32(shard)],
+						BytesRead:    snapshotBytes,
+						SeriesLoaded: numSeries,
+					})
+			}
+
 			if shardResult != nil && shardResult.NumSeries() > 0 {
 				// Prevent race conditions while updating bootstrapResult from multiple go-routines
 				bootstrapResultLock.Lock()
@@ -872,13 +989,15 @@ func (s *commitLogSource) mergeAllShardsCommitLogEncodersAndSnapshots(
 				}
 				bootstrapResultLock.Unlock()
 			}
-			wg.Done()
 		}
 		workerPool.Go(mergeShardFunc)
 	}
 
 	// Wait for all merge goroutines to complete
 	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
 	s.logMergeShardsOutcome(shardErrs, shardEmptyErrs)
 	return bootstrapResult, nil
 }
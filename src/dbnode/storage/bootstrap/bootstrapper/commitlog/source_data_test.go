@@ -46,6 +46,7 @@ import (
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -282,7 +283,8 @@ func TestItMergesSnapshotsAndCommitLogs(t *testing.T) {
 	defer ctrl.Finish()
 
 	var (
-		opts      = testDefaultOpts
+		progress  = result.NewProgress(time.Now)
+		opts      = testDefaultOpts.SetResultOptions(testDefaultOpts.ResultOptions().SetProgress(progress))
 		md        = testNsMetadata(t)
 		src       = newCommitLogSource(opts, fs.Inspection{}).(*commitLogSource)
 		blockSize = md.Options().RetentionOptions().BlockSize()
@@ -385,6 +387,93 @@ func TestItMergesSnapshotsAndCommitLogs(t *testing.T) {
 
 	require.NoError(t, verifyShardResultsAreCorrect(
 		expectedValues, blockSize, res.ShardResults(), opts))
+
+	var phases []string
+	for _, phase := range progress.Phases() {
+		require.Equal(t, CommitLogBootstrapperName, phase.Bootstrapper)
+		require.True(t, phase.Done)
+		phases = append(phases, phase.Phase)
+	}
+	require.Equal(t, []string{
+		phaseReadSnapshotMetadata, phaseReadCommitLogs, phaseMergeSnapshots,
+	}, phases)
+}
+
+func TestReadCommitLogPredicateSkipsCommitLogsCoveredBySnapshots(t *testing.T) {
+	var (
+		opts      = testDefaultOpts
+		md        = testNsMetadata(t)
+		blockSize = md.Options().RetentionOptions().BlockSize()
+		start     = time.Now().Truncate(blockSize).Add(-blockSize)
+		ranges    = xtime.Ranges{}.AddRange(xtime.Range{
+			Start: start,
+			End:   start.Add(blockSize),
+		})
+		targetRanges = result.ShardTimeRanges{0: ranges, 1: ranges}
+		snapshotIDs  = map[uint32]uuid.UUID{0: uuid.NewRandom(), 1: uuid.NewRandom()}
+		inspection   = fs.Inspection{
+			SortedCommitLogFiles: []string{"commitlog-0", "commitlog-1", "commitlog-2", "commitlog-3"},
+		}
+	)
+
+	src := newCommitLogSource(opts, inspection).(*commitLogSource)
+	src.snapshotFilesFn = func(filePathPrefix string, namespace ident.ID, shard uint32) (fs.FileSetFilesSlice, error) {
+		return fs.FileSetFilesSlice{
+			fs.FileSetFile{
+				ID: fs.FileSetFileIdentifier{
+					Namespace:  namespace,
+					BlockStart: start,
+					Shard:      shard,
+				},
+				AbsoluteFilepaths:  []string{"snapshots/checkpoint"},
+				CachedSnapshotTime: start.Add(time.Minute),
+				CachedSnapshotID:   snapshotIDs[shard],
+			},
+		}, nil
+	}
+	src.snapshotMetadataFilesFn = func(_ fs.Options) ([]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error) {
+		return []fs.SnapshotMetadata{
+			{
+				ID:                  fs.SnapshotMetadataIdentifier{Index: 0, UUID: snapshotIDs[0]},
+				CommitlogIdentifier: persist.CommitLogFile{Index: 1},
+			},
+			{
+				ID:                  fs.SnapshotMetadataIdentifier{Index: 1, UUID: snapshotIDs[1]},
+				CommitlogIdentifier: persist.CommitLogFile{Index: 2},
+			},
+		}, nil, nil
+	}
+
+	snapshotFilesByShard, err := src.snapshotFilesByShard(md.ID(), "", targetRanges)
+	require.NoError(t, err)
+
+	pred, _, err := src.newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
+		md, targetRanges, snapshotFilesByShard)
+	require.NoError(t, err)
+
+	// Shard 0 still requires the commitlog files starting at index 1.
+	require.False(t, pred(persist.CommitLogFile{FilePath: "commitlog-0", Index: 0}))
+	require.True(t, pred(persist.CommitLogFile{FilePath: "commitlog-1", Index: 1}))
+	require.True(t, pred(persist.CommitLogFile{FilePath: "commitlog-2", Index: 2}))
+	require.True(t, pred(persist.CommitLogFile{FilePath: "commitlog-3", Index: 3}))
+	// Commitlog files created after the node started are never read.
+	require.False(t, pred(persist.CommitLogFile{FilePath: "commitlog-4", Index: 4}))
+
+	// Without a snapshot metadata file for one of the snapshots all commitlog
+	// files need to be read.
+	src.snapshotMetadataFilesFn = func(_ fs.Options) ([]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error) {
+		return []fs.SnapshotMetadata{
+			{
+				ID:                  fs.SnapshotMetadataIdentifier{Index: 1, UUID: snapshotIDs[1]},
+				CommitlogIdentifier: persist.CommitLogFile{Index: 2},
+			},
+		}, nil, nil
+	}
+
+	pred, _, err = src.newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
+		md, targetRanges, snapshotFilesByShard)
+	require.NoError(t, err)
+	require.True(t, pred(persist.CommitLogFile{FilePath: "commitlog-0", Index: 0}))
 }
 
 type testValue struct {
@@ -135,6 +135,10 @@ func (s *fileSystemSource) ReadData(
 	shardsTimeRanges result.ShardTimeRanges,
 	runOpts bootstrap.RunOptions,
 ) (result.DataBootstrapResult, error) {
+	progress := s.opts.ResultOptions().Progress()
+	progress.RequestShards(FileSystemBootstrapperName, md.ID(), shardsTimeRanges)
+	defer progress.Finish(FileSystemBootstrapperName, md.ID())
+
 	r, err := s.read(md, shardsTimeRanges, bootstrapDataRunType, runOpts)
 	if err != nil {
 		return nil, err
@@ -488,6 +492,7 @@ func (s *fileSystemSource) loadShardReadersDataIntoShardResult(
 	var (
 		blockPool         = ropts.DatabaseBlockOptions().DatabaseBlockPool()
 		seriesCachePolicy = ropts.SeriesCachePolicy()
+		progress          = ropts.Progress()
 		indexBlockSegment segment.MutableSegment
 		timesWithErrors   []time.Time
 		shardResult       result.ShardResult
@@ -513,6 +518,7 @@ func (s *fileSystemSource) loadShardReadersDataIntoShardResult(
 				timeRange = r.Range()
 				start     = timeRange.Start
 				blockSize = ns.Options().RetentionOptions().BlockSize()
+				bytesRead int
 				err       error
 			)
 			switch run {
@@ -530,8 +536,10 @@ func (s *fileSystemSource) loadShardReadersDataIntoShardResult(
 			for i := 0; err == nil && i < numEntries; i++ {
 				switch run {
 				case bootstrapDataRunType:
-					err = s.readNextEntryAndRecordBlock(r, runResult, start, blockSize, shardResult,
+					var n int
+					n, err = s.readNextEntryAndRecordBlock(r, runResult, start, blockSize, shardResult,
 						shardRetriever, blockPool, seriesCachePolicy)
+					bytesRead += n
 				case bootstrapIndexRunType:
 					// We can just read the entry and index if performing an index run
 					err = s.readNextEntryAndIndex(r, runResult, indexBlockSegment)
@@ -572,6 +580,15 @@ func (s *fileSystemSource) loadShardReadersDataIntoShardResult(
 					ns.Options().IndexOptions())
 			}
 
+			if err == nil && run == bootstrapDataRunType {
+				progress.UpdateShard(FileSystemBootstrapperName, ns.ID(), shard,
+					result.ShardProgressUpdate{
+						Fulfilled:    xtime.Ranges{}.AddRange(timeRange),
+						BytesRead:    int64(bytesRead),
+						SeriesLoaded: int64(numEntries),
+					})
+			}
+
 			if err == nil {
 				remainingRanges.Subtract(result.ShardTimeRanges{
 					shard: xtime.Ranges{}.AddRange(timeRange),
@@ -623,7 +640,7 @@ func (s *fileSystemSource) readNextEntryAndRecordBlock(
 	shardRetriever block.DatabaseShardBlockRetriever,
 	blockPool block.DatabaseBlockPool,
 	seriesCachePolicy series.CachePolicy,
-) error {
+) (int, error) {
 	var (
 		seriesBlock = blockPool.Get()
 		id          ident.ID
@@ -638,7 +655,7 @@ func (s *fileSystemSource) readNextEntryAndRecordBlock(
 		err = fmt.Errorf("invalid series cache policy: %s", seriesCachePolicy.String())
 	}
 	if err != nil {
-		return fmt.Errorf("error reading data file: %v", err)
+		return 0, fmt.Errorf("error reading data file: %v", err)
 	}
 
 	var (
@@ -660,17 +677,20 @@ func (s *fileSystemSource) readNextEntryAndRecordBlock(
 	} else {
 		tags, err = convert.TagsFromTagsIter(id, tagsIter, s.idPool)
 		if err != nil {
-			return fmt.Errorf("unable to decode tags: %v", err)
+			return 0, fmt.Errorf("unable to decode tags: %v", err)
 		}
 	}
 	tagsIter.Close()
 
+	// Take the length before the segment takes ownership of the data.
+	dataLen := data.Len()
+
 	switch seriesCachePolicy {
 	case series.CacheAll:
 		seg := ts.NewSegment(data, nil, ts.FinalizeHead)
 		seriesBlock.Reset(blockStart, blockSize, seg)
 	default:
-		return fmt.Errorf("invalid series cache policy: %s", seriesCachePolicy.String())
+		return 0, fmt.Errorf("invalid series cache policy: %s", seriesCachePolicy.String())
 	}
 
 	if exists {
@@ -678,7 +698,7 @@ func (s *fileSystemSource) readNextEntryAndRecordBlock(
 	} else {
 		shardResult.AddBlock(id, tags, seriesBlock)
 	}
-	return nil
+	return dataLen, nil
 }
 
 func (s *fileSystemSource) readNextEntryAndIndex(
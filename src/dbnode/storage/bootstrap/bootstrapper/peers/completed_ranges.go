@@ -0,0 +1,184 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+const (
+	completedRangesDirName    = "bootstrap/peers"
+	completedRangesFileSuffix = ".json"
+	completedRangesTempSuffix = ".json.tmp"
+)
+
+// completedRanges is the on disk representation of the shard blocks
+// that have been streamed from peers and flushed to disk.
+type completedRanges struct {
+	Shards map[uint32][]int64 `json:"shards"`
+}
+
+// completedRangesStore records the shard blocks of a namespace that were
+// streamed from peers and successfully flushed, so that a bootstrap with
+// persistence that is interrupted can resume without streaming those
+// blocks again.
+type completedRangesStore struct {
+	sync.RWMutex
+
+	filePathPrefix string
+	dir            string
+	namespace      ident.ID
+	newFileMode    os.FileMode
+	newDirMode     os.FileMode
+	shards         map[uint32]map[xtime.UnixNano]struct{}
+}
+
+func newCompletedRangesStore(
+	fsOpts fs.Options,
+	namespace ident.ID,
+) *completedRangesStore {
+	return &completedRangesStore{
+		filePathPrefix: fsOpts.FilePathPrefix(),
+		dir:            path.Join(fsOpts.FilePathPrefix(), completedRangesDirName),
+		namespace:      namespace,
+		newFileMode:    fsOpts.NewFileMode(),
+		newDirMode:     fsOpts.NewDirectoryMode(),
+		shards:         make(map[uint32]map[xtime.UnixNano]struct{}),
+	}
+}
+
+func (s *completedRangesStore) filePath(suffix string) string {
+	return path.Join(s.dir, s.namespace.String()+suffix)
+}
+
+// load reads the blocks recorded by a previous bootstrap, if any.
+func (s *completedRangesStore) load() error {
+	data, err := ioutil.ReadFile(s.filePath(completedRangesFileSuffix))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var value completedRanges
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for shard, blockStarts := range value.Shards {
+		blocks := make(map[xtime.UnixNano]struct{}, len(blockStarts))
+		for _, blockStart := range blockStarts {
+			blocks[xtime.UnixNano(blockStart)] = struct{}{}
+		}
+		s.shards[shard] = blocks
+	}
+	return nil
+}
+
+// completed returns whether the block was recorded as flushed and
+// the fileset for the block still exists on disk.
+func (s *completedRangesStore) completed(shard uint32, blockStart time.Time) bool {
+	s.RLock()
+	_, ok := s.shards[shard][xtime.ToUnixNano(blockStart)]
+	s.RUnlock()
+	if !ok {
+		return false
+	}
+
+	exists, err := fs.DataFileSetExistsAt(s.filePathPrefix, s.namespace,
+		shard, blockStart)
+	return err == nil && exists
+}
+
+// add records that the block was flushed and persists the record.
+func (s *completedRangesStore) add(shard uint32, blockStart time.Time) error {
+	s.Lock()
+	defer s.Unlock()
+
+	blocks, ok := s.shards[shard]
+	if !ok {
+		blocks = make(map[xtime.UnixNano]struct{})
+		s.shards[shard] = blocks
+	}
+	blocks[xtime.ToUnixNano(blockStart)] = struct{}{}
+
+	value := completedRanges{Shards: make(map[uint32][]int64, len(s.shards))}
+	for shard, blocks := range s.shards {
+		blockStarts := make([]int64, 0, len(blocks))
+		for blockStart := range blocks {
+			blockStarts = append(blockStarts, int64(blockStart))
+		}
+		value.Shards[shard] = blockStarts
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, s.newDirMode); err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename so that a crash mid write never
+	// leaves a truncated record behind.
+	tempPath := s.filePath(completedRangesTempSuffix)
+	fd, err := os.OpenFile(tempPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, s.newFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(data); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Sync(); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath(completedRangesFileSuffix))
+}
+
+// clear removes the record once the bootstrap has completed.
+func (s *completedRangesStore) clear() error {
+	s.Lock()
+	s.shards = make(map[uint32]map[xtime.UnixNano]struct{})
+	s.Unlock()
+
+	err := os.Remove(s.filePath(completedRangesFileSuffix))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peers
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestEmptyFileSet(
+	t *testing.T,
+	fsOpts fs.Options,
+	shard uint32,
+	blockStart time.Time,
+	blockSize time.Duration,
+) {
+	w, err := fs.NewWriter(fsOpts)
+	require.NoError(t, err)
+	require.NoError(t, w.Open(fs.DataWriterOpenOptions{
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:  testNamespace,
+			Shard:      shard,
+			BlockStart: blockStart,
+		},
+		BlockSize: blockSize,
+	}))
+	require.NoError(t, w.Close())
+}
+
+func TestCompletedRangesStoreResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peers-completed-ranges")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		fsOpts    = fs.NewOptions().SetFilePathPrefix(dir)
+		blockSize = 2 * time.Hour
+		start     = time.Now().Truncate(blockSize).Add(-4 * blockSize)
+		next      = start.Add(blockSize)
+	)
+
+	store := newCompletedRangesStore(fsOpts, testNamespace)
+	require.NoError(t, store.load())
+	assert.False(t, store.completed(0, start))
+
+	// Recorded blocks only count as completed once the fileset exists
+	require.NoError(t, store.add(0, start))
+	require.NoError(t, store.add(1, next))
+	assert.False(t, store.completed(0, start))
+
+	writeTestEmptyFileSet(t, fsOpts, 0, start, blockSize)
+	writeTestEmptyFileSet(t, fsOpts, 1, start, blockSize)
+	assert.True(t, store.completed(0, start))
+	assert.False(t, store.completed(1, start))
+
+	// A new store resumes from the recorded blocks
+	resumed := newCompletedRangesStore(fsOpts, testNamespace)
+	require.NoError(t, resumed.load())
+	assert.True(t, resumed.completed(0, start))
+	assert.False(t, resumed.completed(0, next))
+	assert.False(t, resumed.completed(1, start))
+
+	writeTestEmptyFileSet(t, fsOpts, 1, next, blockSize)
+	assert.True(t, resumed.completed(1, next))
+
+	// Clearing removes the record
+	require.NoError(t, resumed.clear())
+	assert.False(t, resumed.completed(0, start))
+
+	cleared := newCompletedRangesStore(fsOpts, testNamespace)
+	require.NoError(t, cleared.load())
+	assert.False(t, cleared.completed(0, start))
+	require.NoError(t, cleared.clear())
+}
@@ -107,6 +107,7 @@ func (s *peersSource) ReadData(
 		blockRetriever    block.DatabaseBlockRetriever
 		shardRetrieverMgr block.DatabaseShardBlockRetrieverManager
 		persistFlush      persist.FlushPreparer
+		completed         *completedRangesStore
 		shouldPersist     = false
 		seriesCachePolicy = s.opts.ResultOptions().SeriesCachePolicy()
 		persistConfig     = opts.PersistConfig()
@@ -145,8 +146,22 @@ func (s *peersSource) ReadData(
 		blockRetriever = r
 		shardRetrieverMgr = block.NewDatabaseShardBlockRetrieverManager(r)
 		persistFlush = persist
+
+		// Resume from the blocks already flushed by a previous interrupted
+		// bootstrap, if the record can't be read then just stream everything.
+		completed = newCompletedRangesStore(s.opts.FilesystemOptions(), namespace)
+		if err := completed.load(); err != nil {
+			s.log.WithFields(
+				xlog.NewField("namespace", namespace.String()),
+				xlog.NewField("error", err.Error()),
+			).Warnf("peers bootstrapper could not load completed ranges")
+		}
 	}
 
+	progress := s.opts.ResultOptions().Progress()
+	progress.RequestShards(PeersBootstrapperName, namespace, shardsTimeRanges)
+	defer progress.Finish(PeersBootstrapperName, namespace)
+
 	result := result.NewDataBootstrapResult()
 	session, err := s.opts.AdminClient().DefaultAdminSession()
 	if err != nil {
@@ -176,8 +191,8 @@ func (s *peersSource) ReadData(
 		xlog.NewField("shouldPersist", shouldPersist),
 	).Infof("peers bootstrapper bootstrapping shards for ranges")
 	if shouldPersist {
-		go s.startPersistenceQueueWorkerLoop(
-			opts, persistenceWorkerDoneCh, persistenceQueue, persistFlush, result, &resultLock)
+		go s.startPersistenceQueueWorkerLoop(opts, persistenceWorkerDoneCh,
+			persistenceQueue, persistFlush, completed, result, &resultLock)
 	}
 
 	workers := xsync.NewWorkerPool(concurrency)
@@ -189,7 +204,7 @@ func (s *peersSource) ReadData(
 			defer wg.Done()
 			s.fetchBootstrapBlocksFromPeers(shard, ranges, nsMetadata, session,
 				resultOpts, result, &resultLock, shouldPersist, persistenceQueue,
-				shardRetrieverMgr, blockSize)
+				shardRetrieverMgr, completed, blockSize)
 		})
 	}
 
@@ -206,6 +221,16 @@ func (s *peersSource) ReadData(
 		if err != nil {
 			return nil, err
 		}
+
+		// Everything requested has been attempted, any unfulfilled ranges will
+		// be retried by the next bootstrap regardless so the record is no
+		// longer needed.
+		if err := completed.clear(); err != nil {
+			s.log.WithFields(
+				xlog.NewField("namespace", namespace.String()),
+				xlog.NewField("error", err.Error()),
+			).Warnf("peers bootstrapper could not clear completed ranges")
+		}
 	}
 
 	return result, nil
@@ -221,6 +246,7 @@ func (s *peersSource) startPersistenceQueueWorkerLoop(
 	doneCh chan struct{},
 	persistenceQueue chan persistenceFlush,
 	persistFlush persist.FlushPreparer,
+	completed *completedRangesStore,
 	bootstrapResult result.DataBootstrapResult,
 	lock *sync.Mutex,
 ) {
@@ -230,6 +256,14 @@ func (s *peersSource) startPersistenceQueueWorkerLoop(
 		err := s.flush(opts, persistFlush, flush.nsMetadata, flush.shard,
 			flush.shardRetrieverMgr, flush.shardResult, flush.timeRange)
 		if err == nil {
+			// Record the flushed block so an interrupted bootstrap can resume.
+			if err := completed.add(flush.shard, flush.timeRange.Start); err != nil {
+				s.log.WithFields(
+					xlog.NewField("shard", flush.shard),
+					xlog.NewField("error", err.Error()),
+				).Warnf("peers bootstrapper could not record completed range")
+			}
+
 			// Safe to add to the shared bootstrap result now.
 			lock.Lock()
 			bootstrapResult.Add(flush.shard, flush.shardResult, xtime.Ranges{})
@@ -267,6 +301,7 @@ func (s *peersSource) fetchBootstrapBlocksFromPeers(
 	shouldPersist bool,
 	persistenceQueue chan persistenceFlush,
 	shardRetrieverMgr block.DatabaseShardBlockRetrieverManager,
+	completed *completedRangesStore,
 	blockSize time.Duration,
 ) {
 	it := ranges.Iter()
@@ -275,6 +310,20 @@ func (s *peersSource) fetchBootstrapBlocksFromPeers(
 
 		for blockStart := currRange.Start; blockStart.Before(currRange.End); blockStart = blockStart.Add(blockSize) {
 			blockEnd := blockStart.Add(blockSize)
+			if shouldPersist && completed.completed(shard, blockStart) {
+				// Already flushed by a previous interrupted bootstrap, the
+				// block is served from disk by the block retriever.
+				s.log.WithFields(
+					xlog.NewField("shard", shard),
+					xlog.NewField("blockStart", blockStart),
+				).Info("peers bootstrapper skipping block flushed by previous bootstrap")
+				bopts.Progress().UpdateShard(PeersBootstrapperName, nsMetadata.ID(), shard,
+					result.ShardProgressUpdate{
+						Fulfilled: xtime.NewRanges(xtime.Range{Start: blockStart, End: blockEnd}),
+					})
+				continue
+			}
+
 			shardResult, err := session.FetchBootstrapBlocksFromPeers(
 				nsMetadata, shard, blockStart, blockEnd, bopts)
 
@@ -288,6 +337,13 @@ func (s *peersSource) fetchBootstrapBlocksFromPeers(
 				continue
 			}
 
+			bopts.Progress().UpdateShard(PeersBootstrapperName, nsMetadata.ID(), shard,
+				result.ShardProgressUpdate{
+					Fulfilled:    xtime.NewRanges(xtime.Range{Start: blockStart, End: blockEnd}),
+					BytesRead:    result.ShardResultDataBytes(shardResult),
+					SeriesLoaded: shardResult.NumSeries(),
+				})
+
 			if shouldPersist {
 				persistenceQueue <- persistenceFlush{
 					nsMetadata:        nsMetadata,
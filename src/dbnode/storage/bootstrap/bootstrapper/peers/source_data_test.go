@@ -22,11 +22,14 @@ package peers
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
@@ -238,9 +241,14 @@ func TestPeersSourceRunWithPersist(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
+		dir, err := ioutil.TempDir("", "peers-bootstrap-persist")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
 		testNsMd := testNamespaceMetadata(t)
 		resultOpts := testDefaultResultOpts.SetSeriesCachePolicy(cachePolicy)
-		opts := testDefaultOpts.SetResultOptions(resultOpts)
+		opts := testDefaultOpts.SetResultOptions(resultOpts).
+			SetFilesystemOptions(fs.NewOptions().SetFilePathPrefix(dir))
 		ropts := testNsMd.Options().RetentionOptions()
 		blockSize := ropts.BlockSize()
 
@@ -424,11 +432,16 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	dir, err := ioutil.TempDir("", "peers-bootstrap-persist")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
 	opts := testDefaultOpts.
 		SetResultOptions(testDefaultOpts.
 			ResultOptions().
 			SetSeriesCachePolicy(series.CacheRecentlyRead),
-		)
+		).
+		SetFilesystemOptions(fs.NewOptions().SetFilePathPrefix(dir))
 	testNsMd := testNamespaceMetadata(t)
 	ropts := testNsMd.Options().RetentionOptions()
 
@@ -23,6 +23,7 @@ package peers
 import (
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
@@ -100,4 +101,14 @@ type Options interface {
 
 	// RuntimeOptionsManagers returns the RuntimeOptionsManager.
 	RuntimeOptionsManager() m3dbruntime.OptionsManager
+
+	// SetFilesystemOptions sets the filesystem options used to record the
+	// blocks flushed when performing a bootstrap with persistence enabled,
+	// so that an interrupted bootstrap can resume where it left off.
+	SetFilesystemOptions(value fs.Options) Options
+
+	// FilesystemOptions returns the filesystem options used to record the
+	// blocks flushed when performing a bootstrap with persistence enabled,
+	// so that an interrupted bootstrap can resume where it left off.
+	FilesystemOptions() fs.Options
 }
@@ -38,6 +38,7 @@ type options struct {
 	newBlocksLen            int
 	seriesCachePolicy       series.CachePolicy
 	mutableSegmentAllocator MutableSegmentAllocator
+	progress                Progress
 }
 
 // NewOptions creates new bootstrap options
@@ -49,6 +50,7 @@ func NewOptions() Options {
 		newBlocksLen:            defaultNewBlocksLen,
 		seriesCachePolicy:       series.DefaultCachePolicy,
 		mutableSegmentAllocator: NewDefaultMutableSegmentAllocator(),
+		progress:                NewProgress(clock.NewOptions().NowFn()),
 	}
 }
 
@@ -111,3 +113,13 @@ func (o *options) SetIndexMutableSegmentAllocator(value MutableSegmentAllocator)
 func (o *options) IndexMutableSegmentAllocator() MutableSegmentAllocator {
 	return o.mutableSegmentAllocator
 }
+
+func (o *options) SetProgress(value Progress) Options {
+	opts := *o
+	opts.progress = value
+	return &opts
+}
+
+func (o *options) Progress() Progress {
+	return o.progress
+}
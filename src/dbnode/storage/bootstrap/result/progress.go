@@ -0,0 +1,356 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package result
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// PhaseProgress is the progress of a single phase of a bootstrapper
+// bootstrapping a namespace.
+type PhaseProgress struct {
+	Bootstrapper string
+	Namespace    string
+	Phase        string
+	Start        time.Time
+	Duration     time.Duration
+	Done         bool
+}
+
+// ShardProgress is the progress of a bootstrapper bootstrapping a shard
+// of a namespace.
+type ShardProgress struct {
+	Shard           uint32
+	RangesRequested time.Duration
+	RangesFulfilled time.Duration
+	BytesRead       int64
+	SeriesLoaded    int64
+}
+
+// NamespaceProgress is the progress of a bootstrapper bootstrapping a
+// namespace, summed over all of its shards.
+type NamespaceProgress struct {
+	Bootstrapper    string
+	Namespace       string
+	Start           time.Time
+	RangesRequested time.Duration
+	RangesFulfilled time.Duration
+	BytesRead       int64
+	SeriesLoaded    int64
+	// EstimatedRemaining is extrapolated from the rate at which ranges have
+	// been fulfilled so far and is negative while it can not be estimated,
+	// it is zero once the bootstrapper finished.
+	EstimatedRemaining time.Duration
+	// Done is whether the bootstrapper finished, the ranges it left
+	// unfulfilled are left to the next bootstrapper.
+	Done   bool
+	Shards []ShardProgress
+}
+
+// ShardProgressUpdate is an increment of the progress of a shard.
+type ShardProgressUpdate struct {
+	Fulfilled    xtime.Ranges
+	BytesRead    int64
+	SeriesLoaded int64
+}
+
+// Progress tracks the phases bootstrappers go through and how much of the
+// requested ranges they have fulfilled so that operators can tell whether
+// a bootstrap is stuck or merely slow.
+type Progress interface {
+	// StartPhase completes the current phase of the bootstrapper for the
+	// namespace, if any, and starts a new one. Starting a phase after the
+	// bootstrapper finished resets its progress.
+	StartPhase(bootstrapper string, namespace ident.ID, phase string)
+
+	// Finish completes the current phase of the bootstrapper for the namespace
+	// and marks its shard progress as done.
+	Finish(bootstrapper string, namespace ident.ID)
+
+	// Phases returns the progress of all phases, ordered by bootstrapper,
+	// namespace and the order the phases were started in.
+	Phases() []PhaseProgress
+
+	// RequestShards resets the shard progress of the bootstrapper for the
+	// namespace to the ranges it was asked to bootstrap.
+	RequestShards(bootstrapper string, namespace ident.ID, shardsTimeRanges ShardTimeRanges)
+
+	// UpdateShard increments the progress of the bootstrapper for a shard
+	// of the namespace.
+	UpdateShard(bootstrapper string, namespace ident.ID, shard uint32, update ShardProgressUpdate)
+
+	// Namespaces returns the progress of all namespaces, ordered by
+	// bootstrapper and namespace.
+	Namespaces() []NamespaceProgress
+}
+
+type progressKey struct {
+	bootstrapper string
+	namespace    string
+}
+
+type progressEntry struct {
+	phases   []PhaseProgress
+	finished bool
+}
+
+type namespaceProgressEntry struct {
+	start  time.Time
+	done   bool
+	shards map[uint32]*ShardProgress
+}
+
+type progress struct {
+	sync.RWMutex
+
+	nowFn      clock.NowFn
+	entries    map[progressKey]*progressEntry
+	namespaces map[progressKey]*namespaceProgressEntry
+}
+
+// NewProgress returns a new bootstrap progress tracker.
+func NewProgress(nowFn clock.NowFn) Progress {
+	return &progress{
+		nowFn:      nowFn,
+		entries:    make(map[progressKey]*progressEntry),
+		namespaces: make(map[progressKey]*namespaceProgressEntry),
+	}
+}
+
+func (p *progress) StartPhase(bootstrapper string, namespace ident.ID, phase string) {
+	key := progressKey{bootstrapper: bootstrapper, namespace: namespace.String()}
+	now := p.nowFn()
+
+	p.Lock()
+	defer p.Unlock()
+
+	entry, ok := p.entries[key]
+	if !ok || entry.finished {
+		entry = &progressEntry{}
+		p.entries[key] = entry
+	}
+	entry.completeCurrent(now)
+	entry.phases = append(entry.phases, PhaseProgress{
+		Bootstrapper: bootstrapper,
+		Namespace:    key.namespace,
+		Phase:        phase,
+		Start:        now,
+	})
+}
+
+func (p *progress) Finish(bootstrapper string, namespace ident.ID) {
+	key := progressKey{bootstrapper: bootstrapper, namespace: namespace.String()}
+	now := p.nowFn()
+
+	p.Lock()
+	defer p.Unlock()
+
+	if entry, ok := p.entries[key]; ok {
+		entry.completeCurrent(now)
+		entry.finished = true
+	}
+	if entry, ok := p.namespaces[key]; ok {
+		entry.done = true
+	}
+}
+
+func (p *progress) Phases() []PhaseProgress {
+	now := p.nowFn()
+
+	p.RLock()
+	keys := make([]progressKey, 0, len(p.entries))
+	for key := range p.entries {
+		keys = append(keys, key)
+	}
+	sortProgressKeys(keys)
+
+	var phases []PhaseProgress
+	for _, key := range keys {
+		for _, phase := range p.entries[key].phases {
+			if !phase.Done {
+				phase.Duration = now.Sub(phase.Start)
+			}
+			phases = append(phases, phase)
+		}
+	}
+	p.RUnlock()
+
+	return phases
+}
+
+func (e *progressEntry) completeCurrent(now time.Time) {
+	if len(e.phases) == 0 {
+		return
+	}
+	current := &e.phases[len(e.phases)-1]
+	if current.Done {
+		return
+	}
+	current.Done = true
+	current.Duration = now.Sub(current.Start)
+}
+
+func (p *progress) RequestShards(
+	bootstrapper string,
+	namespace ident.ID,
+	shardsTimeRanges ShardTimeRanges,
+) {
+	var (
+		key   = progressKey{bootstrapper: bootstrapper, namespace: namespace.String()}
+		entry = &namespaceProgressEntry{
+			start:  p.nowFn(),
+			shards: make(map[uint32]*ShardProgress, len(shardsTimeRanges)),
+		}
+	)
+	for shard, ranges := range shardsTimeRanges {
+		entry.shards[shard] = &ShardProgress{
+			Shard:           shard,
+			RangesRequested: rangesDuration(ranges),
+		}
+	}
+
+	p.Lock()
+	p.namespaces[key] = entry
+	p.Unlock()
+}
+
+func (p *progress) UpdateShard(
+	bootstrapper string,
+	namespace ident.ID,
+	shard uint32,
+	update ShardProgressUpdate,
+) {
+	key := progressKey{bootstrapper: bootstrapper, namespace: namespace.String()}
+
+	p.Lock()
+	defer p.Unlock()
+
+	entry, ok := p.namespaces[key]
+	if !ok {
+		entry = &namespaceProgressEntry{
+			start:  p.nowFn(),
+			shards: make(map[uint32]*ShardProgress),
+		}
+		p.namespaces[key] = entry
+	}
+	shardProgress, ok := entry.shards[shard]
+	if !ok {
+		shardProgress = &ShardProgress{Shard: shard}
+		entry.shards[shard] = shardProgress
+	}
+	shardProgress.RangesFulfilled += rangesDuration(update.Fulfilled)
+	shardProgress.BytesRead += update.BytesRead
+	shardProgress.SeriesLoaded += update.SeriesLoaded
+}
+
+func (p *progress) Namespaces() []NamespaceProgress {
+	now := p.nowFn()
+
+	p.RLock()
+	defer p.RUnlock()
+
+	keys := make([]progressKey, 0, len(p.namespaces))
+	for key := range p.namespaces {
+		keys = append(keys, key)
+	}
+	sortProgressKeys(keys)
+
+	namespaces := make([]NamespaceProgress, 0, len(keys))
+	for _, key := range keys {
+		entry := p.namespaces[key]
+		nsProgress := NamespaceProgress{
+			Bootstrapper: key.bootstrapper,
+			Namespace:    key.namespace,
+			Start:        entry.start,
+			Done:         entry.done,
+			Shards:       make([]ShardProgress, 0, len(entry.shards)),
+		}
+		for _, shardProgress := range entry.shards {
+			nsProgress.RangesRequested += shardProgress.RangesRequested
+			nsProgress.RangesFulfilled += shardProgress.RangesFulfilled
+			nsProgress.BytesRead += shardProgress.BytesRead
+			nsProgress.SeriesLoaded += shardProgress.SeriesLoaded
+			nsProgress.Shards = append(nsProgress.Shards, *shardProgress)
+		}
+		sort.Slice(nsProgress.Shards, func(i, j int) bool {
+			return nsProgress.Shards[i].Shard < nsProgress.Shards[j].Shard
+		})
+		if !entry.done {
+			nsProgress.EstimatedRemaining = estimateRemaining(
+				now.Sub(entry.start), nsProgress.RangesRequested, nsProgress.RangesFulfilled)
+		}
+		namespaces = append(namespaces, nsProgress)
+	}
+
+	return namespaces
+}
+
+// ShardResultDataBytes returns the size of the data of all the blocks in
+// a shard result.
+func ShardResultDataBytes(r ShardResult) int64 {
+	var size int64
+	for _, entry := range r.AllSeries().Iter() {
+		for _, block := range entry.Value().Blocks.AllBlocks() {
+			size += int64(block.Len())
+		}
+	}
+	return size
+}
+
+// estimateRemaining extrapolates the time remaining to fulfill the requested
+// ranges assuming the rest are fulfilled at the same rate as so far.
+func estimateRemaining(elapsed, requested, fulfilled time.Duration) time.Duration {
+	if fulfilled >= requested {
+		return 0
+	}
+	if fulfilled <= 0 {
+		return -1
+	}
+	remaining := float64(elapsed) * float64(requested-fulfilled) / float64(fulfilled)
+	return time.Duration(remaining)
+}
+
+func rangesDuration(ranges xtime.Ranges) time.Duration {
+	var (
+		total time.Duration
+		iter  = ranges.Iter()
+	)
+	for iter.Next() {
+		r := iter.Value()
+		total += r.End.Sub(r.Start)
+	}
+	return total
+}
+
+func sortProgressKeys(keys []progressKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].bootstrapper != keys[j].bootstrapper {
+			return keys[i].bootstrapper < keys[j].bootstrapper
+		}
+		return keys[i].namespace < keys[j].namespace
+	})
+}
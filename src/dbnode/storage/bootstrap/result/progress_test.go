@@ -0,0 +1,135 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package result
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressPhases(t *testing.T) {
+	var (
+		now   = time.Now()
+		nowFn = func() time.Time { return now }
+		ns    = ident.StringID("ns")
+		p     = NewProgress(nowFn)
+	)
+
+	p.StartPhase("commitlog", ns, "read-commitlogs")
+	now = now.Add(time.Minute)
+	p.StartPhase("commitlog", ns, "merge")
+	now = now.Add(time.Second)
+
+	phases := p.Phases()
+	require.Equal(t, 2, len(phases))
+	require.Equal(t, "read-commitlogs", phases[0].Phase)
+	require.True(t, phases[0].Done)
+	require.Equal(t, time.Minute, phases[0].Duration)
+	require.Equal(t, "merge", phases[1].Phase)
+	require.False(t, phases[1].Done)
+	require.Equal(t, time.Second, phases[1].Duration)
+
+	p.Finish("commitlog", ns)
+	now = now.Add(time.Hour)
+	phases = p.Phases()
+	require.True(t, phases[1].Done)
+	require.Equal(t, time.Second, phases[1].Duration)
+
+	// Starting a phase after finishing resets the progress.
+	p.StartPhase("commitlog", ns, "read-commitlogs")
+	phases = p.Phases()
+	require.Equal(t, 1, len(phases))
+	require.False(t, phases[0].Done)
+}
+
+func TestProgressNamespaces(t *testing.T) {
+	var (
+		now       = time.Now()
+		nowFn     = func() time.Time { return now }
+		ns        = ident.StringID("ns")
+		p         = NewProgress(nowFn)
+		blockSize = 2 * time.Hour
+		start     = now.Truncate(blockSize)
+		ranges    = xtime.Ranges{}.AddRange(xtime.Range{
+			Start: start,
+			End:   start.Add(2 * blockSize),
+		})
+	)
+
+	p.RequestShards("filesystem", ns, ShardTimeRanges{0: ranges, 1: ranges})
+
+	namespaces := p.Namespaces()
+	require.Equal(t, 1, len(namespaces))
+	require.Equal(t, 4*blockSize, namespaces[0].RangesRequested)
+	require.Equal(t, time.Duration(0), namespaces[0].RangesFulfilled)
+	require.True(t, namespaces[0].EstimatedRemaining < 0)
+
+	now = now.Add(time.Minute)
+	p.UpdateShard("filesystem", ns, 1, ShardProgressUpdate{
+		Fulfilled: xtime.Ranges{}.AddRange(xtime.Range{
+			Start: start,
+			End:   start.Add(blockSize),
+		}),
+		BytesRead:    1024,
+		SeriesLoaded: 10,
+	})
+
+	namespaces = p.Namespaces()
+	require.Equal(t, 1, len(namespaces))
+	require.Equal(t, "filesystem", namespaces[0].Bootstrapper)
+	require.Equal(t, "ns", namespaces[0].Namespace)
+	require.Equal(t, blockSize, namespaces[0].RangesFulfilled)
+	require.Equal(t, int64(1024), namespaces[0].BytesRead)
+	require.Equal(t, int64(10), namespaces[0].SeriesLoaded)
+	// A quarter of the ranges took a minute so the rest should take three.
+	require.Equal(t, 3*time.Minute, namespaces[0].EstimatedRemaining)
+	require.Equal(t, []ShardProgress{
+		{Shard: 0, RangesRequested: 2 * blockSize},
+		{
+			Shard:           1,
+			RangesRequested: 2 * blockSize,
+			RangesFulfilled: blockSize,
+			BytesRead:       1024,
+			SeriesLoaded:    10,
+		},
+	}, namespaces[0].Shards)
+
+	// Finishing leaves nothing remaining even though ranges were left for the
+	// next bootstrapper, and it stays that way as time passes.
+	p.Finish("filesystem", ns)
+	now = now.Add(time.Hour)
+	namespaces = p.Namespaces()
+	require.True(t, namespaces[0].Done)
+	require.Equal(t, time.Duration(0), namespaces[0].EstimatedRemaining)
+
+	// Requesting shards again resets the progress.
+	p.RequestShards("filesystem", ns, ShardTimeRanges{0: ranges})
+	namespaces = p.Namespaces()
+	require.Equal(t, 1, len(namespaces[0].Shards))
+	require.Equal(t, time.Duration(0), namespaces[0].RangesFulfilled)
+	require.False(t, namespaces[0].Done)
+	require.True(t, namespaces[0].EstimatedRemaining < 0)
+}
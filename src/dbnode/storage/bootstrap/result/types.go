@@ -160,4 +160,10 @@ type Options interface {
 
 	// IndexMutableSegmentAllocator returns the index mutable segment allocator.
 	IndexMutableSegmentAllocator() MutableSegmentAllocator
+
+	// SetProgress sets the bootstrap progress tracker.
+	SetProgress(value Progress) Options
+
+	// Progress returns the bootstrap progress tracker.
+	Progress() Progress
 }
@@ -37,6 +37,7 @@ import (
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
@@ -121,6 +122,7 @@ type options struct {
 	newEncoderFn                   encoding.NewEncoderFn
 	newDecoderFn                   encoding.NewDecoderFn
 	bootstrapProcessProvider       bootstrap.ProcessProvider
+	bootstrapProgress              result.Progress
 	persistManager                 persist.Manager
 	blockRetrieverManager          block.DatabaseBlockRetrieverManager
 	poolOpts                       pool.ObjectPoolOptions
@@ -172,6 +174,7 @@ func newOptions(poolOpts pool.ObjectPoolOptions) Options {
 		repairEnabled:            defaultRepairEnabled,
 		repairOpts:               repair.NewOptions(),
 		bootstrapProcessProvider: defaultBootstrapProcessProvider,
+		bootstrapProgress:        result.NewProgress(time.Now),
 		poolOpts:                 poolOpts,
 		contextPool: context.NewPool(context.NewOptions().
 			SetContextPoolOptions(poolOpts).
@@ -450,6 +453,16 @@ func (o *options) BootstrapProcessProvider() bootstrap.ProcessProvider {
 	return o.bootstrapProcessProvider
 }
 
+func (o *options) SetBootstrapProgress(value result.Progress) Options {
+	opts := *o
+	opts.bootstrapProgress = value
+	return &opts
+}
+
+func (o *options) BootstrapProgress() result.Progress {
+	return o.bootstrapProgress
+}
+
 func (o *options) SetPersistManager(value persist.Manager) Options {
 	opts := *o
 	opts.persistManager = value
@@ -793,6 +793,12 @@ type Options interface {
 	// BootstrapProcessProvider returns the bootstrap process provider for the database.
 	BootstrapProcessProvider() bootstrap.ProcessProvider
 
+	// SetBootstrapProgress sets the bootstrap progress tracker for the database.
+	SetBootstrapProgress(value result.Progress) Options
+
+	// BootstrapProgress returns the bootstrap progress tracker for the database.
+	BootstrapProgress() result.Progress
+
 	// SetPersistManager sets the persistence manager.
 	SetPersistManager(value persist.Manager) Options
 
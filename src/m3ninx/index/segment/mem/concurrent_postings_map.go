@@ -21,7 +21,9 @@
 package mem
 
 import (
+	"bytes"
 	"regexp"
+	"regexp/syntax"
 	"sync"
 
 	"github.com/m3db/m3/src/m3ninx/postings"
@@ -100,13 +102,21 @@ func (m *concurrentPostingsMap) Get(key []byte) (postings.List, bool) {
 // GetRegex returns the union of the postings lists whose keys match the
 // provided regexp.
 func (m *concurrentPostingsMap) GetRegex(re *regexp.Regexp) (postings.List, bool) {
-	var pl postings.MutableList
+	var (
+		pl     postings.MutableList
+		prefix = anchoredLiteralPrefix(re)
+	)
 
 	m.RLock()
 	for _, mapEntry := range m.postingsMap.Iter() {
+		// NB: keys without the literal prefix of an anchored regexp can be
+		// skipped without evaluating the regexp.
+		if !bytes.HasPrefix(mapEntry.Key(), prefix) {
+			continue
+		}
+
 		// TODO: Evaluate lock contention caused by holding on to the read lock while
 		// evaluating this predicate.
-		// TODO: Evaluate if performing a prefix match would speed up the common case.
 		if re.Match(mapEntry.Key()) {
 			if pl == nil {
 				pl = mapEntry.Value().Clone()
@@ -122,3 +132,32 @@ func (m *concurrentPostingsMap) GetRegex(re *regexp.Regexp) (postings.List, bool
 	}
 	return pl, true
 }
+
+// anchoredLiteralPrefix returns the literal prefix of every key matched by
+// the regexp if the regexp is anchored to the start of the key, as the
+// regexps compiled for the map-backed segment are.
+func anchoredLiteralPrefix(re *regexp.Regexp) []byte {
+	prefix, _ := re.LiteralPrefix()
+	if prefix == "" {
+		return nil
+	}
+
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+
+	for {
+		switch parsed.Op {
+		case syntax.OpBeginText:
+			return []byte(prefix)
+		case syntax.OpConcat, syntax.OpCapture:
+			if len(parsed.Sub) == 0 {
+				return nil
+			}
+			parsed = parsed.Sub[0]
+		default:
+			return nil
+		}
+	}
+}
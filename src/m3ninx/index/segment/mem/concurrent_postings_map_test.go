@@ -25,6 +25,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/m3db/m3/src/m3ninx/index"
 	sgmt "github.com/m3db/m3/src/m3ninx/index/segment"
 
 	"github.com/stretchr/testify/require"
@@ -91,6 +92,49 @@ func TestConcurrentPostingsMapKeys(t *testing.T) {
 	require.Equal(t, termPostings{"bar": []int{2}, "foo": []int{1, 3}, "baz": []int{4}}, keys)
 }
 
+func TestConcurrentPostingsMapGetRegexPrefix(t *testing.T) {
+	opts := NewOptions()
+	pm := newConcurrentPostingsMap(opts)
+
+	pm.Add([]byte("foo"), 1)
+	pm.Add([]byte("foobar"), 2)
+	pm.Add([]byte("xfoo"), 3)
+	pm.Add([]byte("bar"), 4)
+
+	compiled, err := index.CompileRegex([]byte("foo.*"))
+	require.NoError(t, err)
+	pl, ok := pm.GetRegex(compiled.Simple)
+	require.True(t, ok)
+	require.Equal(t, 2, pl.Len())
+	require.True(t, pl.Contains(1))
+	require.True(t, pl.Contains(2))
+
+	// Unanchored regexps may match keys which do not start with the prefix.
+	pl, ok = pm.GetRegex(regexp.MustCompile("foo"))
+	require.True(t, ok)
+	require.Equal(t, 3, pl.Len())
+	require.True(t, pl.Contains(3))
+}
+
+func TestAnchoredLiteralPrefix(t *testing.T) {
+	tests := []struct {
+		re       string
+		expected string
+	}{
+		{re: "^foo.*$", expected: "foo"},
+		{re: `\Afoo[0-9]`, expected: "foo"},
+		{re: "^.*foo", expected: ""},
+		{re: "^(?i)foo", expected: ""},
+		{re: "foo.*", expected: ""},
+		{re: "(^foo)|bar", expected: ""},
+	}
+
+	for _, test := range tests {
+		prefix := anchoredLiteralPrefix(regexp.MustCompile(test.re))
+		require.Equal(t, test.expected, string(prefix), test.re)
+	}
+}
+
 type termPostings map[string][]int
 
 func toTermPostings(t *testing.T, iter sgmt.TermsIterator) termPostings {
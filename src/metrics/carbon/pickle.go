@@ -0,0 +1,235 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/m3db/m3x/instrument"
+
+	"github.com/hydrogen18/stalecucumber"
+)
+
+const (
+	pickleHeaderSize = 4
+
+	// MaxPickleMessageSize is the largest pickled batch accepted, which
+	// matches the limit enforced by carbon itself.
+	MaxPickleMessageSize = 1 << 20 // 1MiB
+)
+
+var (
+	errPickleNotList     = errors.New("pickled batch is not a list")
+	errPickleInvalidItem = errors.New("invalid pickled metric, expected (path, (timestamp, value))")
+)
+
+// ParsePickle parses a pickled batch of carbon metrics, as sent by carbon-relay
+// using the pickle protocol without the length prefix, and returns the metrics
+// and number of malformed metrics. An error is returned if the batch itself
+// could not be unpickled.
+func ParsePickle(data []byte) ([]Metric, int, error) {
+	return parsePickle([]Metric{}, data)
+}
+
+func parsePickle(mets []Metric, data []byte) ([]Metric, int, error) {
+	unpickled, err := stalecucumber.Unpickle(bytes.NewReader(data))
+	if err != nil {
+		return mets, 0, err
+	}
+
+	items, ok := unpickled.([]interface{})
+	if !ok {
+		return mets, 0, errPickleNotList
+	}
+
+	var malformed int
+	for _, item := range items {
+		metric, err := parsePickleItem(item)
+		if err != nil {
+			malformed++
+			continue
+		}
+		mets = append(mets, metric)
+	}
+
+	return mets, malformed, nil
+}
+
+// parsePickleItem parses a single (path, (timestamp, value)) tuple, lists are
+// accepted in place of tuples since both unpickle the same way.
+func parsePickleItem(item interface{}) (Metric, error) {
+	tuple, ok := item.([]interface{})
+	if !ok || len(tuple) != 2 {
+		return Metric{}, errPickleInvalidItem
+	}
+
+	path, ok := tuple[0].(string)
+	if !ok || len(path) == 0 {
+		return Metric{}, errPickleInvalidItem
+	}
+	if !utf8.ValidString(path) {
+		return Metric{}, errNotUTF8
+	}
+
+	datapoint, ok := tuple[1].([]interface{})
+	if !ok || len(datapoint) != 2 {
+		return Metric{}, errPickleInvalidItem
+	}
+
+	timestamp, ok := pickleNumber(datapoint[0])
+	if !ok {
+		return Metric{}, errPickleInvalidItem
+	}
+
+	value, ok := pickleNumber(datapoint[1])
+	if !ok {
+		return Metric{}, errPickleInvalidItem
+	}
+
+	return Metric{
+		Name: []byte(path),
+		// Carbon truncates timestamps to whole seconds as well.
+		Time: time.Unix(int64(timestamp), 0),
+		Val:  value,
+	}, nil
+}
+
+// pickleNumber converts an unpickled number to a float64, numeric strings are
+// accepted as carbon calls float() on both the timestamp and the value.
+func pickleNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, true
+	case string:
+		if val := strings.ToLower(n); val == negativeNanStr || val == nanStr {
+			return mathNan, true
+		}
+		f, err := strconv.ParseFloat(n, floatBitSize)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// A PickleScanner is used to scan carbon metrics sent using the pickle protocol
+// from an underlying io.Reader. The protocol consists of batches of metrics,
+// each a pickled list of (path, (timestamp, value)) tuples prefixed by its
+// length as a 4 byte big endian unsigned integer.
+type PickleScanner struct {
+	r         io.Reader
+	header    [pickleHeaderSize]byte
+	buf       []byte
+	batch     []Metric
+	idx       int
+	timestamp time.Time
+	path      []byte
+	value     float64
+	err       error
+
+	// The number of malformed metrics encountered.
+	MalformedCount int
+
+	iOpts instrument.Options
+}
+
+// NewPickleScanner creates a new carbon pickle scanner.
+func NewPickleScanner(r io.Reader, iOpts instrument.Options) *PickleScanner {
+	return &PickleScanner{r: r, iOpts: iOpts}
+}
+
+// Scan scans for the next carbon metric. Malformed metrics, and batches that
+// cannot be unpickled, are skipped but counted.
+func (s *PickleScanner) Scan() bool {
+	for {
+		if s.idx < len(s.batch) {
+			metric := s.batch[s.idx]
+			s.idx++
+			s.path, s.timestamp, s.value = metric.Name, metric.Time, metric.Val
+			return true
+		}
+
+		if !s.scanBatch() {
+			return false
+		}
+	}
+}
+
+func (s *PickleScanner) scanBatch() bool {
+	s.batch, s.idx = s.batch[:0], 0
+	if _, err := io.ReadFull(s.r, s.header[:]); err != nil {
+		if err != io.EOF {
+			s.err = err
+		}
+		return false
+	}
+
+	size := binary.BigEndian.Uint32(s.header[:])
+	if size > MaxPickleMessageSize {
+		s.err = fmt.Errorf("pickled batch size %d exceeds max size %d",
+			size, MaxPickleMessageSize)
+		return false
+	}
+
+	if uint32(cap(s.buf)) < size {
+		s.buf = make([]byte, size)
+	}
+	s.buf = s.buf[:size]
+	if _, err := io.ReadFull(s.r, s.buf); err != nil {
+		s.err = err
+		return false
+	}
+
+	var (
+		malformed int
+		err       error
+	)
+	s.batch, malformed, err = parsePickle(s.batch, s.buf)
+	if err != nil {
+		s.iOpts.Logger().Errorf(
+			"error trying to unpickle malformed carbon batch of size: %d, err: %s",
+			size, err.Error())
+		malformed++
+	}
+	s.MalformedCount += malformed
+	return true
+}
+
+// Metric returns the path, timestamp, and value of the last parsed metric.
+func (s *PickleScanner) Metric() ([]byte, time.Time, float64) {
+	return s.path, s.timestamp, s.value
+}
+
+// Err returns any errors in the scan.
+func (s *PickleScanner) Err() error { return s.err }
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// pickle.dumps([
+	//     ('foo.bar.baz', (1428951394, 45565.02)),
+	//     ('foo.bar.float', (1428951395.7, 10)),
+	//     ('foo.bar.nan', (1428951396, float('nan'))),
+	// ], protocol=2)
+	testPickleBatch = []byte("\x80\x02\x5d\x71\x00\x28\x58\x0b\x00\x00\x00\x66\x6f\x6f\x2e\x62\x61\x72\x2e\x62\x61\x7a\x71\x01\x4a\x62\x11\x2c\x55\x47\x40\xe6\x3f\xa0\xa3\xd7\x0a\x3d\x86\x71\x02\x86\x71\x03\x58\x0d\x00\x00\x00\x66\x6f\x6f\x2e\x62\x61\x72\x2e\x66\x6c\x6f\x61\x74\x71\x04\x47\x41\xd5\x4b\x04\x58\xec\xcc\xcd\x4b\x0a\x86\x71\x05\x86\x71\x06\x58\x0b\x00\x00\x00\x66\x6f\x6f\x2e\x62\x61\x72\x2e\x6e\x61\x6e\x71\x07\x4a\x64\x11\x2c\x55\x47\x7f\xf8\x00\x00\x00\x00\x00\x00\x86\x71\x08\x86\x71\x09\x65\x2e")
+
+	// pickle.dumps([
+	//     ('foo.bar', (1, 1)),
+	//     'garbage',
+	//     ('foo.baz', (2,)),
+	//     (3, (3, 3)),
+	//     ('foo.qux', (4, '4.5')),
+	// ], protocol=2)
+	testPickleMalformedBatch = []byte("\x80\x02\x5d\x71\x00\x28\x58\x07\x00\x00\x00\x66\x6f\x6f\x2e\x62\x61\x72\x71\x01\x4b\x01\x4b\x01\x86\x71\x02\x86\x71\x03\x58\x07\x00\x00\x00\x67\x61\x72\x62\x61\x67\x65\x71\x04\x58\x07\x00\x00\x00\x66\x6f\x6f\x2e\x62\x61\x7a\x71\x05\x4b\x02\x85\x71\x06\x86\x71\x07\x4b\x03\x4b\x03\x4b\x03\x86\x71\x08\x86\x71\x09\x58\x07\x00\x00\x00\x66\x6f\x6f\x2e\x71\x75\x78\x71\x0a\x4b\x04\x58\x03\x00\x00\x00\x34\x2e\x35\x71\x0b\x86\x71\x0c\x86\x71\x0d\x65\x2e")
+
+	// pickle.dumps({'foo': 1}, protocol=2)
+	testPickleDict = []byte("\x80\x02\x7d\x71\x00\x58\x03\x00\x00\x00\x66\x6f\x6f\x71\x01\x4b\x01\x73\x2e")
+)
+
+func writePickleFrame(buf *bytes.Buffer, batch []byte) {
+	var header [pickleHeaderSize]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(batch)))
+	buf.Write(header[:])
+	buf.Write(batch)
+}
+
+func TestParsePickle(t *testing.T) {
+	mets, malformed, err := ParsePickle(testPickleBatch)
+	require.NoError(t, err)
+	assert.Equal(t, 0, malformed)
+	require.Equal(t, 3, len(mets))
+
+	assert.Equal(t, "foo.bar.baz", string(mets[0].Name))
+	assert.Equal(t, time.Unix(1428951394, 0), mets[0].Time)
+	assert.Equal(t, 45565.02, mets[0].Val)
+
+	assert.Equal(t, "foo.bar.float", string(mets[1].Name))
+	assert.Equal(t, time.Unix(1428951395, 0), mets[1].Time)
+	assert.Equal(t, 10.0, mets[1].Val)
+
+	assert.Equal(t, "foo.bar.nan", string(mets[2].Name))
+	assert.Equal(t, time.Unix(1428951396, 0), mets[2].Time)
+	assert.True(t, math.IsNaN(mets[2].Val))
+}
+
+func TestParsePickleMalformed(t *testing.T) {
+	mets, malformed, err := ParsePickle(testPickleMalformedBatch)
+	require.NoError(t, err)
+	assert.Equal(t, 3, malformed)
+	require.Equal(t, 2, len(mets))
+	assert.Equal(t, Metric{Name: []byte("foo.bar"), Time: time.Unix(1, 0), Val: 1}, mets[0])
+	assert.Equal(t, Metric{Name: []byte("foo.qux"), Time: time.Unix(4, 0), Val: 4.5}, mets[1])
+
+	_, _, err = ParsePickle(testPickleDict)
+	require.Error(t, err)
+
+	_, _, err = ParsePickle([]byte("garbage"))
+	require.Error(t, err)
+}
+
+func TestPickleScanner(t *testing.T) {
+	var buf bytes.Buffer
+	writePickleFrame(&buf, testPickleBatch)
+	writePickleFrame(&buf, []byte("garbage"))
+	writePickleFrame(&buf, testPickleMalformedBatch)
+
+	var names []string
+	s := NewPickleScanner(&buf, testIOpts)
+	for s.Scan() {
+		name, _, _ := s.Metric()
+		names = append(names, string(name))
+	}
+	require.NoError(t, s.Err())
+	assert.Equal(t, []string{
+		"foo.bar.baz", "foo.bar.float", "foo.bar.nan", "foo.bar", "foo.qux",
+	}, names)
+	assert.Equal(t, 4, s.MalformedCount)
+}
+
+func TestPickleScannerErrors(t *testing.T) {
+	// Truncated batch.
+	var buf bytes.Buffer
+	writePickleFrame(&buf, testPickleBatch)
+	s := NewPickleScanner(bytes.NewReader(buf.Bytes()[:buf.Len()-1]), testIOpts)
+	require.False(t, s.Scan())
+	require.Equal(t, io.ErrUnexpectedEOF, s.Err())
+
+	// Batch larger than the max size.
+	var header [pickleHeaderSize]byte
+	binary.BigEndian.PutUint32(header[:], MaxPickleMessageSize+1)
+	s = NewPickleScanner(bytes.NewReader(header[:]), testIOpts)
+	require.False(t, s.Scan())
+	require.Error(t, s.Err())
+}
@@ -0,0 +1,348 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package accounting attributes the cost of queries to their callers, logging
+// slow queries and optionally enforcing per caller limits.
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/stats"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/cost"
+	"github.com/m3db/m3x/instrument"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultCallerHeader is the default header identifying the caller.
+	DefaultCallerHeader = "M3-Caller"
+
+	// DefaultMaxCallers is the default bound on the number of callers
+	// accounted for separately.
+	DefaultMaxCallers = 100
+
+	unknownCaller  = "unknown"
+	overflowCaller = "other"
+)
+
+var (
+	errNoCallerHeader        = errors.New("no caller header set")
+	errNonPositiveMaxCallers = errors.New("max callers must be positive")
+	errNegativeSlowQuery     = errors.New("slow query threshold must not be negative")
+	errNoInstrumentOptions   = errors.New("no instrument options set")
+)
+
+// Options are the options for query accounting.
+type Options struct {
+	// CallerHeader is the request header identifying the caller.
+	CallerHeader string
+	// SlowQueryThreshold is the duration above which queries are logged,
+	// zero disables the slow query log.
+	SlowQueryThreshold time.Duration
+	// MaxCallers bounds the number of callers with their own metrics and
+	// limits, further callers are reported and limited together.
+	MaxCallers int
+	// DefaultLimit is the limit on datapoints held by in flight queries of
+	// a caller without a specific limit. Zero or negative implies no limit.
+	DefaultLimit int64
+	// Limits are per caller limits on datapoints held by in flight queries.
+	Limits map[string]int64
+	// InstrumentOptions are the instrument options.
+	InstrumentOptions instrument.Options
+}
+
+// Validate validates the options.
+func (o Options) Validate() error {
+	if o.CallerHeader == "" {
+		return errNoCallerHeader
+	}
+
+	if o.MaxCallers <= 0 {
+		return errNonPositiveMaxCallers
+	}
+
+	if o.SlowQueryThreshold < 0 {
+		return errNegativeSlowQuery
+	}
+
+	if o.InstrumentOptions == nil {
+		return errNoInstrumentOptions
+	}
+
+	return nil
+}
+
+// Accountant attributes the cost of queries to their callers.
+type Accountant struct {
+	opts  Options
+	scope tally.Scope
+	nowFn func() time.Time
+
+	mu               sync.Mutex
+	callers          map[string]*callerMetrics
+	overflow         *callerMetrics
+	enforcers        map[string]cost.Enforcer
+	defaultEnforcers int
+	overflowEnforcer cost.Enforcer
+}
+
+type callerMetrics struct {
+	queries     tally.Counter
+	errors      tally.Counter
+	slowQueries tally.Counter
+	series      tally.Counter
+	datapoints  tally.Counter
+	latency     tally.Timer
+}
+
+func newCallerMetrics(scope tally.Scope, caller string) *callerMetrics {
+	scope = scope.Tagged(map[string]string{"caller": caller})
+	return &callerMetrics{
+		queries:     scope.Counter("queries"),
+		errors:      scope.Counter("errors"),
+		slowQueries: scope.Counter("slow-queries"),
+		series:      scope.Counter("series"),
+		datapoints:  scope.Counter("datapoints"),
+		latency:     scope.Timer("latency"),
+	}
+}
+
+// NewAccountant returns a new query accountant.
+func NewAccountant(opts Options) (*Accountant, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	scope := opts.InstrumentOptions.MetricsScope()
+	a := &Accountant{
+		opts:      opts,
+		scope:     scope,
+		nowFn:     time.Now,
+		callers:   make(map[string]*callerMetrics),
+		overflow:  newCallerMetrics(scope, overflowCaller),
+		enforcers: make(map[string]cost.Enforcer),
+	}
+
+	if opts.DefaultLimit > 0 {
+		a.overflowEnforcer = a.newEnforcer(overflowCaller, opts.DefaultLimit)
+	}
+
+	return a, nil
+}
+
+// Caller returns the caller of the request.
+func (a *Accountant) Caller(r *http.Request) string {
+	caller := strings.TrimSpace(r.Header.Get(a.opts.CallerHeader))
+	if caller == "" {
+		return unknownCaller
+	}
+
+	return caller
+}
+
+func (a *Accountant) metrics(caller string) *callerMetrics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	metrics, ok := a.callers[caller]
+	if ok {
+		return metrics
+	}
+
+	if len(a.callers) >= a.opts.MaxCallers {
+		return a.overflow
+	}
+
+	metrics = newCallerMetrics(a.scope, caller)
+	a.callers[caller] = metrics
+	return metrics
+}
+
+// enforcer returns the enforcer limiting the datapoints held by the in
+// flight queries of the caller, or nil if the caller is not limited. Callers
+// with a specific limit always have their own enforcer, callers beyond the
+// bound that fall back to the default limit share the overflow enforcer.
+func (a *Accountant) enforcer(caller string) cost.Enforcer {
+	limit, specific := a.opts.Limits[caller]
+	if !specific {
+		limit = a.opts.DefaultLimit
+	}
+
+	if limit <= 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	enforcer, ok := a.enforcers[caller]
+	if ok {
+		return enforcer
+	}
+
+	if !specific {
+		if a.defaultEnforcers >= a.opts.MaxCallers {
+			return a.overflowEnforcer
+		}
+
+		a.defaultEnforcers++
+	}
+
+	enforcer = a.newEnforcer(caller, limit)
+	a.enforcers[caller] = enforcer
+	return enforcer
+}
+
+func (a *Accountant) newEnforcer(caller string, limit int64) cost.Enforcer {
+	limitOpts := cost.NewLimitManagerOptions().
+		SetDefaultLimit(cost.Limit{
+			Threshold: cost.Cost(limit),
+			Enabled:   true,
+		}).
+		SetInstrumentOptions(a.opts.InstrumentOptions)
+	enforcerOpts := cost.NewEnforcerOptions().
+		SetCostExceededMessage(
+			fmt.Sprintf("limits for caller %s exceeded", caller))
+	return cost.NewEnforcer(cost.NewStaticLimitManager(limitOpts),
+		cost.NewTracker(), enforcerOpts)
+}
+
+// Wrap wraps the handler to account the queries it serves.
+func (a *Accountant) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			caller = a.Caller(r)
+			usage  = &stats.Usage{}
+			ctx    = stats.NewUsageContext(r.Context(), usage)
+			query  string
+		)
+
+		if a.opts.SlowQueryThreshold > 0 {
+			// NB: read before serving the request, which may consume the body.
+			query = queryText(r)
+		}
+
+		if enforcer := a.enforcer(caller); enforcer != nil {
+			ctx = qcost.NewContextWithCallerEnforcer(ctx, enforcer)
+		}
+
+		statusWriter := &statusResponseWriter{ResponseWriter: w}
+		start := a.nowFn()
+		next.ServeHTTP(statusWriter, r.WithContext(ctx))
+		duration := a.nowFn().Sub(start)
+
+		a.record(r, caller, query, statusWriter.Status(), duration, usage)
+	})
+}
+
+func (a *Accountant) record(
+	r *http.Request,
+	caller, query string,
+	status int,
+	duration time.Duration,
+	usage *stats.Usage,
+) {
+	metrics := a.metrics(caller)
+	metrics.queries.Inc(1)
+	metrics.latency.Record(duration)
+	metrics.series.Inc(usage.Series())
+	metrics.datapoints.Inc(usage.Datapoints())
+	if status >= http.StatusBadRequest {
+		metrics.errors.Inc(1)
+	}
+
+	threshold := a.opts.SlowQueryThreshold
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	metrics.slowQueries.Inc(1)
+	logging.WithContext(r.Context()).Warn("slow query",
+		zap.String("caller", caller),
+		zap.String("query", query),
+		zap.String("url", r.URL.Path),
+		zap.Int("status", status),
+		zap.Duration("duration", duration),
+		zap.Int64("series", usage.Series()),
+		zap.Int64("fetchedDatapoints", usage.Datapoints()))
+}
+
+// queryText returns the query of the request, which is either a PromQL query
+// or one or more Graphite targets.
+func queryText(r *http.Request) string {
+	if query := r.FormValue("query"); query != "" {
+		return query
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return ""
+	}
+
+	return strings.Join(r.Form["target"], ", ")
+}
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer if it supports flushing, so that
+// streamed responses are not held back by the accounting wrapper.
+func (w *statusResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Status returns the status code written, which defaults to OK.
+func (w *statusResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+
+	return w.status
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package accounting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/stats"
+	"github.com/m3db/m3x/instrument"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestAccountant(
+	t *testing.T,
+	cfg Configuration,
+) (*Accountant, tally.TestScope) {
+	scope := tally.NewTestScope("", nil)
+	accountant, err := cfg.NewAccountant(
+		instrument.NewOptions().SetMetricsScope(scope))
+	require.NoError(t, err)
+	return accountant, scope
+}
+
+func newTestRequest(caller string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/query_range?query=up", nil)
+	if caller != "" {
+		req.Header.Set(DefaultCallerHeader, caller)
+	}
+
+	return req
+}
+
+func counterValue(scope tally.TestScope, name, caller string) int64 {
+	key := tally.KeyForPrefixedStringMap(name, map[string]string{"caller": caller})
+	counter, ok := scope.Snapshot().Counters()[key]
+	if !ok {
+		return 0
+	}
+
+	return counter.Value()
+}
+
+func TestAccountantRecordsCallers(t *testing.T) {
+	accountant, scope := newTestAccountant(t, Configuration{})
+
+	handler := accountant.Wrap(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			usage := stats.UsageFromContext(r.Context())
+			require.NotNil(t, usage)
+			assert.Nil(t, stats.FromContext(r.Context()))
+
+			enforcer := stats.WrapEnforcer(r.Context(), qcost.NoopChainedEnforcer())
+			enforcer.Add(10)
+			usage.AddSeries(2)
+
+			if r.Header.Get(DefaultCallerHeader) == "" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-a"))
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-a"))
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest(""))
+
+	assert.Equal(t, int64(2), counterValue(scope, "queries", "team-a"))
+	assert.Equal(t, int64(0), counterValue(scope, "errors", "team-a"))
+	assert.Equal(t, int64(4), counterValue(scope, "series", "team-a"))
+	assert.Equal(t, int64(20), counterValue(scope, "datapoints", "team-a"))
+
+	assert.Equal(t, int64(1), counterValue(scope, "queries", unknownCaller))
+	assert.Equal(t, int64(1), counterValue(scope, "errors", unknownCaller))
+}
+
+func TestAccountantMaxCallers(t *testing.T) {
+	accountant, scope := newTestAccountant(t, Configuration{MaxCallers: 1})
+
+	handler := accountant.Wrap(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-a"))
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-b"))
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-c"))
+
+	assert.Equal(t, int64(1), counterValue(scope, "queries", "team-a"))
+	assert.Equal(t, int64(0), counterValue(scope, "queries", "team-b"))
+	assert.Equal(t, int64(2), counterValue(scope, "queries", overflowCaller))
+}
+
+func TestAccountantDefaultMaxCallers(t *testing.T) {
+	accountant, _ := newTestAccountant(t, Configuration{})
+	assert.Equal(t, DefaultMaxCallers, accountant.opts.MaxCallers)
+}
+
+func TestAccountantSlowQueries(t *testing.T) {
+	accountant, scope := newTestAccountant(t, Configuration{
+		SlowQueryThreshold: time.Second,
+	})
+
+	now := time.Now()
+	accountant.nowFn = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	handler := accountant.Wrap(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-a"))
+
+	assert.Equal(t, int64(1), counterValue(scope, "slow-queries", "team-a"))
+}
+
+func TestAccountantCallerLimits(t *testing.T) {
+	accountant, _ := newTestAccountant(t, Configuration{
+		Limits: CallerLimitsConfiguration{
+			DefaultMaxFetchedDatapoints: 100,
+			MaxFetchedDatapoints:        map[string]int64{"team-a": 10},
+		},
+	})
+
+	var (
+		errs     []error
+		held     qcost.ChainedEnforcer
+		holdNext = true
+	)
+	handler := accountant.Wrap(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			enforcer := qcost.WithCallerEnforcer(r.Context(),
+				qcost.NoopChainedEnforcer())
+			errs = append(errs, enforcer.Add(8).Error)
+			if holdNext {
+				held = enforcer
+				holdNext = false
+				return
+			}
+
+			enforcer.Close()
+		}))
+
+	// The first query holds on to its datapoints so the second one for the
+	// same caller exceeds the limit, while other callers are unaffected.
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-a"))
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-a"))
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-b"))
+
+	require.Len(t, errs, 3)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+
+	// Once released, the caller can query again.
+	held.Close()
+	handler.ServeHTTP(httptest.NewRecorder(), newTestRequest("team-a"))
+	require.Len(t, errs, 4)
+	assert.NoError(t, errs[3])
+}
+
+func TestAccountantMaxCallersLimits(t *testing.T) {
+	accountant, _ := newTestAccountant(t, Configuration{
+		MaxCallers: 1,
+		Limits: CallerLimitsConfiguration{
+			DefaultMaxFetchedDatapoints: 10,
+			MaxFetchedDatapoints:        map[string]int64{"team-a": 10},
+		},
+	})
+
+	var errs []error
+	handler := accountant.Wrap(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			// Hold on to the datapoints so callers sharing an enforcer
+			// exceed the limit.
+			enforcer := qcost.WithCallerEnforcer(r.Context(),
+				qcost.NoopChainedEnforcer())
+			errs = append(errs, enforcer.Add(8).Error)
+		}))
+
+	// Callers with a specific limit do not count towards the bound, further
+	// callers on the default limit share the limit of the overflow caller.
+	for _, caller := range []string{"team-a", "team-b", "team-c", "team-d"} {
+		handler.ServeHTTP(httptest.NewRecorder(), newTestRequest(caller))
+	}
+
+	require.Len(t, errs, 4)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.NoError(t, errs[2])
+	assert.Error(t, errs[3])
+	assert.Len(t, accountant.enforcers, 2)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	opts := Options{
+		CallerHeader:      DefaultCallerHeader,
+		MaxCallers:        DefaultMaxCallers,
+		InstrumentOptions: instrument.NewOptions(),
+	}
+	require.NoError(t, opts.Validate())
+
+	invalid := opts
+	invalid.CallerHeader = ""
+	assert.Error(t, invalid.Validate())
+
+	invalid = opts
+	invalid.MaxCallers = 0
+	assert.Error(t, invalid.Validate())
+
+	invalid = opts
+	invalid.MaxCallers = -1
+	assert.Error(t, invalid.Validate())
+
+	invalid = opts
+	invalid.InstrumentOptions = nil
+	assert.Error(t, invalid.Validate())
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package accounting
+
+import (
+	"time"
+
+	"github.com/m3db/m3x/instrument"
+)
+
+// Configuration is the configuration for query accounting.
+type Configuration struct {
+	// CallerHeader is the request header identifying the caller, defaults
+	// to M3-Caller.
+	CallerHeader string `yaml:"callerHeader"`
+
+	// SlowQueryThreshold is the duration above which queries are logged,
+	// zero disables the slow query log.
+	SlowQueryThreshold time.Duration `yaml:"slowQueryThreshold"`
+
+	// MaxCallers bounds the number of callers reported and limited on their
+	// own, defaults to 100.
+	MaxCallers int `yaml:"maxCallers"`
+
+	// Limits are limits on the datapoints held by the in flight queries of
+	// each caller.
+	Limits CallerLimitsConfiguration `yaml:"limits"`
+}
+
+// CallerLimitsConfiguration is the configuration for per caller limits. Zero
+// or negative values imply no limit.
+type CallerLimitsConfiguration struct {
+	// DefaultMaxFetchedDatapoints applies to callers without a specific limit.
+	DefaultMaxFetchedDatapoints int64 `yaml:"defaultMaxFetchedDatapoints"`
+
+	// MaxFetchedDatapoints are the limits of specific callers.
+	MaxFetchedDatapoints map[string]int64 `yaml:"maxFetchedDatapoints"`
+}
+
+// NewAccountant creates a new query accountant from the configuration.
+func (c Configuration) NewAccountant(
+	instrumentOpts instrument.Options,
+) (*Accountant, error) {
+	callerHeader := c.CallerHeader
+	if callerHeader == "" {
+		callerHeader = DefaultCallerHeader
+	}
+
+	maxCallers := c.MaxCallers
+	if maxCallers == 0 {
+		maxCallers = DefaultMaxCallers
+	}
+
+	return NewAccountant(Options{
+		CallerHeader:       callerHeader,
+		SlowQueryThreshold: c.SlowQueryThreshold,
+		MaxCallers:         maxCallers,
+		DefaultLimit:       c.Limits.DefaultMaxFetchedDatapoints,
+		Limits:             c.Limits.MaxFetchedDatapoints,
+		InstrumentOptions:  instrumentOpts,
+	})
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package database
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	nchannel "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/node/channel"
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/placement"
+	"github.com/m3db/m3/src/query/util/logging"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"github.com/uber/tchannel-go"
+	"github.com/uber/tchannel-go/thrift"
+	"go.uber.org/zap"
+)
+
+const (
+	// BootstrapProgressURL is the url for the database bootstrap progress handler.
+	BootstrapProgressURL = handler.RoutePrefixV1 + "/database/bootstrap/progress"
+
+	// BootstrapProgressHTTPMethod is the HTTP method used with this resource.
+	BootstrapProgressHTTPMethod = http.MethodGet
+
+	bootstrapProgressChannelName = "m3coordinator-bootstrap-progress"
+	bootstrapProgressTimeout     = 10 * time.Second
+)
+
+var errNoPlacement = errors.New("no m3db placement exists")
+
+type nodeClientFn func(endpoint string) (rpc.TChanNode, error)
+
+type bootstrapProgressHandler struct {
+	sync.Mutex
+
+	placementGetHandler *placement.GetHandler
+	nodeClientFn        nodeClientFn
+	timeout             time.Duration
+}
+
+// BootstrapProgressResponse is the bootstrap progress of the m3db placement.
+type BootstrapProgressResponse struct {
+	Namespaces []NamespaceBootstrapProgress `json:"namespaces"`
+	Instances  []InstanceBootstrapProgress  `json:"instances"`
+}
+
+// NamespaceBootstrapProgress is the progress of a bootstrapper bootstrapping
+// a namespace summed over all the instances in the placement.
+type NamespaceBootstrapProgress struct {
+	Bootstrapper         string `json:"bootstrapper"`
+	Namespace            string `json:"namespace"`
+	RangesRequestedNanos int64  `json:"rangesRequestedNanos"`
+	RangesFulfilledNanos int64  `json:"rangesFulfilledNanos"`
+	BytesRead            int64  `json:"bytesRead"`
+	SeriesLoaded         int64  `json:"seriesLoaded"`
+	// EstimatedRemainingNanos is the estimate of the slowest instance and
+	// is negative while it can not be estimated for one of the instances.
+	EstimatedRemainingNanos int64 `json:"estimatedRemainingNanos"`
+	// Done is whether the bootstrapper finished on all the instances.
+	Done bool `json:"done"`
+}
+
+// InstanceBootstrapProgress is the bootstrap progress reported by an instance.
+type InstanceBootstrapProgress struct {
+	ID       string                            `json:"id"`
+	Endpoint string                            `json:"endpoint"`
+	Error    string                            `json:"error,omitempty"`
+	Progress *rpc.NodeBootstrapProgressResult_ `json:"progress,omitempty"`
+}
+
+// NewBootstrapProgressHandler returns a new instance of a database bootstrap
+// progress handler.
+func NewBootstrapProgressHandler(
+	client clusterclient.Client,
+) http.Handler {
+	return &bootstrapProgressHandler{
+		placementGetHandler: placement.NewGetHandler(
+			placement.HandlerOptions{ClusterClient: client}),
+		timeout: bootstrapProgressTimeout,
+	}
+}
+
+func (h *bootstrapProgressHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	currPlacement, _, err := h.placementGetHandler.Get(handler.M3DBServiceName, nil)
+	if err != nil {
+		logger.Error("unable to get placement", zap.Any("error", err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if currPlacement == nil {
+		xhttp.Error(w, errNoPlacement, http.StatusNotFound)
+		return
+	}
+
+	clientFn, err := h.clientFn()
+	if err != nil {
+		logger.Error("unable to create node client", zap.Any("error", err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var (
+		instances = currPlacement.Instances()
+		results   = make([]InstanceBootstrapProgress, len(instances))
+		wg        sync.WaitGroup
+	)
+	for i, instance := range instances {
+		i := i
+		results[i] = InstanceBootstrapProgress{
+			ID:       instance.ID(),
+			Endpoint: instance.Endpoint(),
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			progress, err := h.instanceProgress(clientFn, results[i].Endpoint)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Progress = progress
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
+	xhttp.WriteJSONResponse(w, BootstrapProgressResponse{
+		Namespaces: aggregateBootstrapProgress(results),
+		Instances:  results,
+	}, logger)
+}
+
+func (h *bootstrapProgressHandler) clientFn() (nodeClientFn, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.nodeClientFn != nil {
+		return h.nodeClientFn, nil
+	}
+
+	channel, err := tchannel.NewChannel(bootstrapProgressChannelName, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.nodeClientFn = func(endpoint string) (rpc.TChanNode, error) {
+		client := thrift.NewClient(channel, nchannel.ChannelName,
+			&thrift.ClientOptions{HostPort: endpoint})
+		return rpc.NewTChanNodeClient(client), nil
+	}
+	return h.nodeClientFn, nil
+}
+
+func (h *bootstrapProgressHandler) instanceProgress(
+	clientFn nodeClientFn,
+	endpoint string,
+) (*rpc.NodeBootstrapProgressResult_, error) {
+	client, err := clientFn(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	tctx, cancel := thrift.NewContext(h.timeout)
+	defer cancel()
+
+	return client.BootstrapProgress(tctx)
+}
+
+func aggregateBootstrapProgress(
+	instances []InstanceBootstrapProgress,
+) []NamespaceBootstrapProgress {
+	type namespaceKey struct {
+		bootstrapper string
+		namespace    string
+	}
+
+	byNamespace := make(map[namespaceKey]*NamespaceBootstrapProgress)
+	for _, instance := range instances {
+		if instance.Progress == nil {
+			continue
+		}
+		for _, ns := range instance.Progress.Namespaces {
+			key := namespaceKey{bootstrapper: ns.Bootstrapper, namespace: ns.NameSpace}
+			aggregated, ok := byNamespace[key]
+			if !ok {
+				aggregated = &NamespaceBootstrapProgress{
+					Bootstrapper: ns.Bootstrapper,
+					Namespace:    ns.NameSpace,
+					Done:         true,
+				}
+				byNamespace[key] = aggregated
+			}
+			aggregated.RangesRequestedNanos += ns.RangesRequestedNanos
+			aggregated.RangesFulfilledNanos += ns.RangesFulfilledNanos
+			aggregated.BytesRead += ns.BytesRead
+			aggregated.SeriesLoaded += ns.SeriesLoaded
+			aggregated.Done = aggregated.Done && ns.Done
+
+			switch {
+			case ns.Done:
+				// Finished instances have nothing remaining.
+			case aggregated.EstimatedRemainingNanos < 0:
+				// Already unknown.
+			case ns.EstimatedRemainingNanos < 0:
+				aggregated.EstimatedRemainingNanos = ns.EstimatedRemainingNanos
+			case ns.EstimatedRemainingNanos > aggregated.EstimatedRemainingNanos:
+				aggregated.EstimatedRemainingNanos = ns.EstimatedRemainingNanos
+			}
+		}
+	}
+
+	namespaces := make([]NamespaceBootstrapProgress, 0, len(byNamespace))
+	for _, ns := range byNamespace {
+		namespaces = append(namespaces, *ns)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		if namespaces[i].Bootstrapper != namespaces[j].Bootstrapper {
+			return namespaces[i].Bootstrapper < namespaces[j].Bootstrapper
+		}
+		return namespaces[i].Namespace < namespaces[j].Namespace
+	})
+	return namespaces
+}
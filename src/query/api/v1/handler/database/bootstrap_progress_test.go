@@ -0,0 +1,166 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapProgressAggregatesInstances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient, _, mockPlacementService := SetupDatabaseTest(t, ctrl)
+	handler := NewBootstrapProgressHandler(mockClient).(*bootstrapProgressHandler)
+
+	mockPlacementService.EXPECT().Placement().Return(
+		placement.NewPlacement().SetInstances([]placement.Instance{
+			placement.NewInstance().SetID("host_a").SetEndpoint("host_a:9000"),
+			placement.NewInstance().SetID("host_b").SetEndpoint("host_b:9000"),
+			placement.NewInstance().SetID("host_c").SetEndpoint("host_c:9000"),
+		}), nil)
+
+	progress := func(requested, fulfilled, remaining int64) *rpc.NodeBootstrapProgressResult_ {
+		return &rpc.NodeBootstrapProgressResult_{
+			Phases: []*rpc.BootstrapPhaseProgress{},
+			Namespaces: []*rpc.BootstrapNamespaceProgress{
+				{
+					Bootstrapper:            "peers",
+					NameSpace:               "metrics",
+					RangesRequestedNanos:    requested,
+					RangesFulfilledNanos:    fulfilled,
+					BytesRead:               100,
+					SeriesLoaded:            10,
+					EstimatedRemainingNanos: remaining,
+					Shards:                  []*rpc.BootstrapShardProgress{},
+				},
+			},
+		}
+	}
+
+	nodeA := rpc.NewMockTChanNode(ctrl)
+	nodeA.EXPECT().BootstrapProgress(gomock.Any()).Return(progress(4, 2, int64(time.Minute)), nil)
+	nodeB := rpc.NewMockTChanNode(ctrl)
+	nodeB.EXPECT().BootstrapProgress(gomock.Any()).Return(progress(4, 1, int64(time.Hour)), nil)
+	nodeC := rpc.NewMockTChanNode(ctrl)
+	nodeC.EXPECT().BootstrapProgress(gomock.Any()).Return(nil, errors.New("unreachable"))
+
+	nodes := map[string]rpc.TChanNode{
+		"host_a:9000": nodeA,
+		"host_b:9000": nodeB,
+		"host_c:9000": nodeC,
+	}
+	handler.nodeClientFn = func(endpoint string) (rpc.TChanNode, error) {
+		return nodes[endpoint], nil
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(BootstrapProgressHTTPMethod, BootstrapProgressURL, nil)
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body BootstrapProgressResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	assert.Equal(t, []NamespaceBootstrapProgress{
+		{
+			Bootstrapper:            "peers",
+			Namespace:               "metrics",
+			RangesRequestedNanos:    8,
+			RangesFulfilledNanos:    3,
+			BytesRead:               200,
+			SeriesLoaded:            20,
+			EstimatedRemainingNanos: int64(time.Hour),
+		},
+	}, body.Namespaces)
+
+	require.Equal(t, 3, len(body.Instances))
+	assert.Equal(t, "host_a", body.Instances[0].ID)
+	assert.NotNil(t, body.Instances[0].Progress)
+	assert.Equal(t, "host_c", body.Instances[2].ID)
+	assert.Equal(t, "unreachable", body.Instances[2].Error)
+	assert.Nil(t, body.Instances[2].Progress)
+}
+
+func TestBootstrapProgressNoPlacement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient, _, mockPlacementService := SetupDatabaseTest(t, ctrl)
+	handler := NewBootstrapProgressHandler(mockClient)
+
+	mockPlacementService.EXPECT().Placement().Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(BootstrapProgressHTTPMethod, BootstrapProgressURL, nil)
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestAggregateBootstrapProgressDone(t *testing.T) {
+	instance := func(done bool, remaining int64) InstanceBootstrapProgress {
+		return InstanceBootstrapProgress{
+			Progress: &rpc.NodeBootstrapProgressResult_{
+				Namespaces: []*rpc.BootstrapNamespaceProgress{
+					{
+						Bootstrapper:            "filesystem",
+						NameSpace:               "metrics",
+						EstimatedRemainingNanos: remaining,
+						Done:                    done,
+					},
+				},
+			},
+		}
+	}
+
+	// A finished instance does not make the estimate unknown.
+	namespaces := aggregateBootstrapProgress([]InstanceBootstrapProgress{
+		instance(true, 0),
+		instance(false, int64(time.Minute)),
+	})
+	require.Equal(t, 1, len(namespaces))
+	assert.False(t, namespaces[0].Done)
+	assert.Equal(t, int64(time.Minute), namespaces[0].EstimatedRemainingNanos)
+
+	// Once all instances finished there is nothing remaining.
+	namespaces = aggregateBootstrapProgress([]InstanceBootstrapProgress{
+		instance(true, 0),
+		instance(true, 0),
+	})
+	require.Equal(t, 1, len(namespaces))
+	assert.True(t, namespaces[0].Done)
+	assert.Equal(t, int64(0), namespaces[0].EstimatedRemainingNanos)
+}
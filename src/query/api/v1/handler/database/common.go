@@ -68,4 +68,8 @@ func RegisterRoutes(
 	r.HandleFunc(ConfigSetBootstrappersURL, wrapped(
 		NewConfigSetBootstrappersHandler(client)).ServeHTTP).
 		Methods(ConfigSetBootstrappersHTTPMethod)
+
+	r.HandleFunc(BootstrapProgressURL, wrapped(
+		NewBootstrapProgressHandler(client)).ServeHTTP).
+		Methods(BootstrapProgressHTTPMethod)
 }
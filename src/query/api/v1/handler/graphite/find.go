@@ -21,6 +21,7 @@
 package graphite
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"net/http"
@@ -48,14 +49,18 @@ var (
 
 type grahiteFindHandler struct {
 	storage storage.Storage
+	cache   *FindCache
 }
 
-// NewFindHandler returns a new instance of handler.
+// NewFindHandler returns a new instance of handler, find results are cached
+// in the given cache if it is not nil.
 func NewFindHandler(
 	storage storage.Storage,
+	cache *FindCache,
 ) http.Handler {
 	return &grahiteFindHandler{
 		storage: storage,
+		cache:   cache,
 	}
 }
 
@@ -110,6 +115,22 @@ func (h *grahiteFindHandler) ServeHTTP(
 		return
 	}
 
+	wildcards, rErr := parseFindWildcards(r)
+	if rErr != nil {
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	var cacheKey string
+	if h.cache != nil {
+		cacheKey = h.cache.key(raw, terminatedQuery.Start, terminatedQuery.End,
+			wildcards)
+		if result, ok := h.cache.get(ctx, cacheKey); ok {
+			w.Write(result)
+			return
+		}
+	}
+
 	var (
 		terminatedResult *storage.CompleteTagsResult
 		tErr             error
@@ -152,8 +173,18 @@ func (h *grahiteFindHandler) ServeHTTP(
 	}
 
 	// TODO: Support multiple result types
-	if err = findResultsJSON(w, prefix, seenMap); err != nil {
+	var buf bytes.Buffer
+	if err = findResultsJSON(&buf, prefix, seenMap, wildcards); err != nil {
 		logger.Error("unable to print find results", zap.Error(err))
 		xhttp.Error(w, err, http.StatusBadRequest)
+		return
 	}
+
+	// NB: empty results are not cached so that newly written series are
+	// found as soon as they are indexed.
+	if h.cache != nil && len(seenMap) > 0 {
+		h.cache.set(ctx, cacheKey, buf.Bytes())
+	}
+
+	w.Write(buf.Bytes())
 }
@@ -0,0 +1,106 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/query/cache"
+
+	"github.com/uber-go/tally"
+)
+
+var (
+	errInvalidFindCacheTTL = errors.New("find cache ttl must be positive")
+)
+
+// FindCache caches rendered find results for a short duration, since
+// dashboards issue the same find queries on every template variable
+// refresh for every viewer.
+type FindCache struct {
+	cache  cache.Cache
+	ttl    time.Duration
+	hits   tally.Counter
+	misses tally.Counter
+	errors tally.Counter
+}
+
+// NewFindCache returns a find results cache keeping results in the given
+// cache backend for the given ttl.
+func NewFindCache(
+	backend cache.Cache,
+	ttl time.Duration,
+	scope tally.Scope,
+) (*FindCache, error) {
+	if ttl <= 0 {
+		return nil, errInvalidFindCacheTTL
+	}
+
+	if scope == nil {
+		scope = tally.NoopScope
+	}
+
+	return &FindCache{
+		cache:  backend,
+		ttl:    ttl,
+		hits:   scope.Counter("hits"),
+		misses: scope.Counter("misses"),
+		errors: scope.Counter("errors"),
+	}, nil
+}
+
+// key returns the cache key of a find query. Like graphite-web, the time
+// range is rounded down to the cache ttl so that queries relative to now
+// share results until they expire.
+func (c *FindCache) key(
+	query string,
+	from time.Time,
+	until time.Time,
+	wildcards bool,
+) string {
+	return fmt.Sprintf("find:%s:%d:%d:%t", query,
+		from.Truncate(c.ttl).Unix(), until.Truncate(c.ttl).Unix(), wildcards)
+}
+
+func (c *FindCache) get(ctx context.Context, key string) ([]byte, bool) {
+	value, ok, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.errors.Inc(1)
+		return nil, false
+	}
+
+	if !ok {
+		c.misses.Inc(1)
+		return nil, false
+	}
+
+	c.hits.Inc(1)
+	return value, true
+}
+
+func (c *FindCache) set(ctx context.Context, key string, value []byte) {
+	if err := c.cache.Set(ctx, key, value, c.ttl); err != nil {
+		c.errors.Inc(1)
+	}
+}
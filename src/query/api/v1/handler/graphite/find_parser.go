@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/m3db/m3/src/query/errors"
@@ -139,39 +141,91 @@ func parseFindParamsToQueries(r *http.Request) (
 	return terminatedQuery, childQuery, query, nil
 }
 
+// parseFindWildcards parses whether a wildcard node should be added to the
+// find results.
+func parseFindWildcards(r *http.Request) (bool, *xhttp.ParseError) {
+	wildcardsString := r.FormValue("wildcards")
+	if len(wildcardsString) == 0 {
+		return false, nil
+	}
+
+	wildcards, err := strconv.ParseBool(wildcardsString)
+	if err != nil {
+		return false, xhttp.NewParseError(
+			fmt.Errorf("invalid 'wildcards': %s", wildcardsString),
+			http.StatusBadRequest)
+	}
+
+	return wildcards, nil
+}
+
+// findResultsJSON writes find results in the graphite-web treejson format;
+// results are sorted by name, and a node which is both a leaf and has
+// children is returned as a branch. If wildcards is set and there are
+// multiple results, a `*` node matching all of them is written first.
 func findResultsJSON(
 	w io.Writer,
 	prefix string,
 	tags map[string]bool,
+	wildcards bool,
 ) error {
-	jw := json.NewWriter(w)
-	jw.BeginArray()
-
+	values := make([]string, 0, len(tags))
+	allLeaves := true
 	for value, hasChildren := range tags {
-		leaf := 1
+		values = append(values, value)
 		if hasChildren {
-			leaf = 0
+			allLeaves = false
 		}
-		jw.BeginObject()
-
-		jw.BeginObjectField("id")
-		jw.WriteString(fmt.Sprintf("%s%s", prefix, value))
-
-		jw.BeginObjectField("text")
-		jw.WriteString(value)
+	}
 
-		jw.BeginObjectField("leaf")
-		jw.WriteInt(leaf)
+	sort.Strings(values)
 
-		jw.BeginObjectField("expandable")
-		jw.WriteInt(1 - leaf)
+	jw := json.NewWriter(w)
+	jw.BeginArray()
 
-		jw.BeginObjectField("allowChildren")
-		jw.WriteInt(1 - leaf)
+	if wildcards && len(values) > 1 {
+		writeFindNode(jw, prefix, "*", !allLeaves)
+	}
 
-		jw.EndObject()
+	for _, value := range values {
+		writeFindNode(jw, prefix, value, tags[value])
 	}
 
 	jw.EndArray()
 	return jw.Close()
 }
+
+func writeFindNode(
+	jw *json.Writer,
+	prefix string,
+	value string,
+	hasChildren bool,
+) {
+	leaf := 1
+	if hasChildren {
+		leaf = 0
+	}
+
+	jw.BeginObject()
+
+	jw.BeginObjectField("id")
+	jw.WriteString(fmt.Sprintf("%s%s", prefix, value))
+
+	jw.BeginObjectField("text")
+	jw.WriteString(value)
+
+	jw.BeginObjectField("leaf")
+	jw.WriteInt(leaf)
+
+	jw.BeginObjectField("expandable")
+	jw.WriteInt(1 - leaf)
+
+	jw.BeginObjectField("allowChildren")
+	jw.WriteInt(1 - leaf)
+
+	jw.BeginObjectField("context")
+	jw.BeginObject()
+	jw.EndObject()
+
+	jw.EndObject()
+}
@@ -25,12 +25,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
-	"sort"
-	"strings"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/util/logging"
@@ -126,6 +126,7 @@ func setupStorage(ctrl *gomock.Controller) storage.Storage {
 	// set up no children case
 	noChildrenMatcher := &completeTagQueryMatcher{
 		matchers: []models.Matcher{
+			{Type: models.MatchNotRegexp, Name: b("__gpath__"), Value: b(".+")},
 			{Type: models.MatchEqual, Name: b("__g0__"), Value: b("foo")},
 			{Type: models.MatchRegexp, Name: b("__g1__"), Value: b(`b[^\.]*`)},
 			{Type: models.MatchNotRegexp, Name: b("__g2__"), Value: b(".*")},
@@ -145,6 +146,7 @@ func setupStorage(ctrl *gomock.Controller) storage.Storage {
 	// set up children case
 	childrenMatcher := &completeTagQueryMatcher{
 		matchers: []models.Matcher{
+			{Type: models.MatchNotRegexp, Name: b("__gpath__"), Value: b(".+")},
 			{Type: models.MatchEqual, Name: b("__g0__"), Value: b("foo")},
 			{Type: models.MatchRegexp, Name: b("__g1__"), Value: b(`b[^\.]*`)},
 			{Type: models.MatchRegexp, Name: b("__g2__"), Value: b(".*")},
@@ -182,36 +184,22 @@ func (w *writer) Write(b []byte) (int, error) {
 }
 
 type result struct {
-	ID            string `json:"id"`
-	Text          string `json:"text"`
-	Leaf          int    `json:"leaf"`
-	Expandable    int    `json:"expandable"`
-	AllowChildren int    `json:"allowChildren"`
+	ID            string         `json:"id"`
+	Text          string         `json:"text"`
+	Leaf          int            `json:"leaf"`
+	Expandable    int            `json:"expandable"`
+	AllowChildren int            `json:"allowChildren"`
+	Context       map[string]int `json:"context"`
 }
 
 type results []result
 
-func (r results) Len() int      { return len(r) }
-func (r results) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
-func (r results) Less(i, j int) bool {
-	return strings.Compare(r[i].ID, r[j].ID) == -1
-}
-
-func TestFind(t *testing.T) {
-	logging.InitWithCores(nil)
-
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	// setup storage and handler
-	store := setupStorage(ctrl)
-	handler := NewFindHandler(store)
-
-	// execute the query
+func executeFind(t *testing.T, handler http.Handler, params string) results {
 	w := &writer{}
 	req := &http.Request{
 		URL: &url.URL{
-			RawQuery: fmt.Sprintf("query=foo.b*&from=%s&until=%s", from.s, until.s),
+			RawQuery: fmt.Sprintf("query=foo.b*&from=%s&until=%s%s",
+				from.s, until.s, params),
 		},
 	}
 
@@ -222,18 +210,93 @@ func TestFind(t *testing.T) {
 	r := make(results, 0)
 	decoder := json.NewDecoder(bytes.NewBufferString((w.results[0])))
 	require.NoError(t, decoder.Decode(&r))
-	sort.Sort(r)
+	return r
+}
+
+func makeNoChildrenResult(t string) result {
+	return result{ID: fmt.Sprintf("foo.%s", t), Text: t, Leaf: 1,
+		Expandable: 0, AllowChildren: 0, Context: map[string]int{}}
+}
+
+func makeWithChildrenResult(t string) result {
+	return result{ID: fmt.Sprintf("foo.%s", t), Text: t, Leaf: 0,
+		Expandable: 1, AllowChildren: 1, Context: map[string]int{}}
+}
+
+func TestFind(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-	makeNoChildrenResult := func(t string) result {
-		return result{ID: fmt.Sprintf("foo.%s", t), Text: t, Leaf: 1,
-			Expandable: 0, AllowChildren: 0}
+	// setup storage and handler
+	store := setupStorage(ctrl)
+	handler := NewFindHandler(store, nil)
+
+	// results are sorted by name, and nodes which are both leaves and
+	// have children are returned as branches
+	expected := results{
+		makeNoChildrenResult("bar"),
+		makeWithChildrenResult("baz"),
+		makeWithChildrenResult("bix"),
+		makeWithChildrenResult("bug"),
 	}
 
-	makeWithChildrenResult := func(t string) result {
-		return result{ID: fmt.Sprintf("foo.%s", t), Text: t, Leaf: 0,
-			Expandable: 1, AllowChildren: 1}
+	require.Equal(t, expected, executeFind(t, handler, ""))
+}
+
+func TestFindWildcards(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := setupStorage(ctrl)
+	handler := NewFindHandler(store, nil)
+
+	expected := results{
+		makeWithChildrenResult("*"),
+		makeNoChildrenResult("bar"),
+		makeWithChildrenResult("baz"),
+		makeWithChildrenResult("bix"),
+		makeWithChildrenResult("bug"),
 	}
 
+	require.Equal(t, expected, executeFind(t, handler, "&wildcards=1"))
+}
+
+func TestFindInvalidWildcards(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	handler := NewFindHandler(store, nil)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet,
+		FindURL+"?query=foo.b*&wildcards=maybe", nil)
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestFindCached(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backend, err := cache.NewLRUCache(1024 * 1024)
+	require.NoError(t, err)
+	findCache, err := NewFindCache(backend, time.Minute, nil)
+	require.NoError(t, err)
+
+	// NB: storage expects only a single call for each query, so subsequent
+	// finds must be served from the cache.
+	store := setupStorage(ctrl)
+	handler := NewFindHandler(store, findCache)
+
 	expected := results{
 		makeNoChildrenResult("bar"),
 		makeWithChildrenResult("baz"),
@@ -241,5 +304,26 @@ func TestFind(t *testing.T) {
 		makeWithChildrenResult("bug"),
 	}
 
-	require.Equal(t, expected, r)
+	require.Equal(t, expected, executeFind(t, handler, ""))
+	require.Equal(t, expected, executeFind(t, handler, ""))
+}
+
+func TestFindCacheKey(t *testing.T) {
+	findCache, err := NewFindCache(nil, time.Minute, nil)
+	require.NoError(t, err)
+
+	start := time.Date(2019, time.March, 1, 10, 0, 5, 0, time.UTC)
+	end := start.Add(time.Hour)
+	key := findCache.key("foo.*", start, end, false)
+
+	// times within the same ttl window share a key
+	require.Equal(t, key, findCache.key("foo.*", start.Add(30*time.Second),
+		end.Add(30*time.Second), false))
+	require.NotEqual(t, key, findCache.key("foo.*", start.Add(time.Minute),
+		end.Add(time.Minute), false))
+	require.NotEqual(t, key, findCache.key("foo.b*", start, end, false))
+	require.NotEqual(t, key, findCache.key("foo.*", start, end, true))
+
+	_, err = NewFindCache(nil, 0, nil)
+	require.Error(t, err)
 }
@@ -23,7 +23,6 @@ package graphite
 import (
 	"context"
 	"fmt"
-	"math"
 	"net/http"
 	"sort"
 	"sync"
@@ -91,6 +90,9 @@ func (h *renderHandler) serveHTTP(
 	r *http.Request,
 ) respError {
 	reqCtx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	// NB: targets often share path expressions, e.g. `a.*` and
+	// `sumSeries(a.*)`, which only need to be fetched once per request.
+	reqCtx = graphite.WithFetchCoalescing(reqCtx)
 	p, err := ParseRenderRequest(r)
 	if err != nil {
 		return respError{err: err, code: http.StatusBadRequest}
@@ -103,10 +105,11 @@ func (h *renderHandler) serveHTTP(
 	)
 
 	ctx := common.NewContext(common.ContextOptions{
-		Engine:  h.engine,
-		Start:   p.From,
-		End:     p.Until,
-		Timeout: p.Timeout,
+		Engine:        h.engine,
+		Start:         p.From,
+		End:           p.Until,
+		Timeout:       p.Timeout,
+		MaxDataPoints: p.MaxDataPoints,
 	})
 
 	// Set the request context.
@@ -145,18 +148,6 @@ func (h *renderHandler) serveHTTP(
 				return
 			}
 
-			for i, s := range targetSeries.Values {
-				if s.Len() <= int(p.MaxDataPoints) {
-					continue
-				}
-
-				var (
-					samplingMultiplier = math.Ceil(float64(s.Len()) / float64(p.MaxDataPoints))
-					newMillisPerStep   = int(samplingMultiplier * float64(s.MillisPerStep()))
-				)
-				targetSeries.Values[i] = ts.LTTB(s, s.StartTime(), s.EndTime(), newMillisPerStep)
-			}
-
 			mu.Lock()
 			results[i] = targetSeries
 			mu.Unlock()
@@ -194,6 +185,10 @@ func (h *renderHandler) serveHTTP(
 		SortApplied: true,
 	}
 
-	err = WriteRenderResponse(w, response, p.Format)
+	err = WriteRenderResponse(w, response, RenderResultsOptions{
+		Format:        p.Format,
+		MaxDataPoints: p.MaxDataPoints,
+		NoNullPoints:  p.NoNullPoints,
+	})
 	return respError{err: err, code: http.StatusOK}
 }
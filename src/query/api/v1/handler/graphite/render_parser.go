@@ -21,6 +21,8 @@
 package graphite
 
 import (
+	"bufio"
+	"encoding/csv"
 	"fmt"
 	"io"
 	"math"
@@ -33,6 +35,8 @@ import (
 	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/graphite/ts"
 	"github.com/m3db/m3/src/query/util/json"
+
+	msgpack "gopkg.in/vmihailenco/msgpack.v2"
 )
 
 const (
@@ -40,6 +44,12 @@ const (
 	queryRangeShiftThreshold = 55 * time.Minute
 	queryRangeShift          = 15 * time.Second
 	pickleFormat             = "pickle"
+	jsonFormat               = "json"
+	csvFormat                = "csv"
+	rawFormat                = "raw"
+	msgpackFormat            = "msgpack"
+	csvTimeFormat            = "2006-01-02 15:04:05"
+	rawNullValue             = "None"
 )
 
 var (
@@ -47,20 +57,72 @@ var (
 	errFromNotBeforeUntil = errors.NewInvalidParamsError(errors.New("'from' must come before 'until'"))
 )
 
+// RenderResultsOptions are the options for writing the results of a render call.
+type RenderResultsOptions struct {
+	Format        string
+	MaxDataPoints int64
+	NoNullPoints  bool
+}
+
+// renderedSeries is a series to be written along with how it was consolidated
+// to honor the maxDataPoints of the render request.
+type renderedSeries struct {
+	*ts.Series
+	valuesPerPoint        int
+	originalMillisPerStep int
+}
+
+// consolidateForMaxDataPoints downsamples the series so that it has at most
+// maxDataPoints values.
+func consolidateForMaxDataPoints(s *ts.Series, maxDataPoints int64) renderedSeries {
+	rendered := renderedSeries{
+		Series:                s,
+		valuesPerPoint:        1,
+		originalMillisPerStep: s.MillisPerStep(),
+	}
+
+	if int64(s.Len()) <= maxDataPoints {
+		return rendered
+	}
+
+	var (
+		samplingMultiplier = math.Ceil(float64(s.Len()) / float64(maxDataPoints))
+		newMillisPerStep   = int(samplingMultiplier * float64(s.MillisPerStep()))
+	)
+	rendered.Series = ts.LTTB(s, s.StartTime(), s.EndTime(), newMillisPerStep)
+	rendered.valuesPerPoint = int(samplingMultiplier)
+	return rendered
+}
+
 // WriteRenderResponse writes the response to a render request
 func WriteRenderResponse(
 	w http.ResponseWriter,
 	series ts.SeriesList,
-	format string,
+	opts RenderResultsOptions,
 ) error {
-	if format == pickleFormat {
+	rendered := make([]renderedSeries, 0, len(series.Values))
+	for _, s := range series.Values {
+		rendered = append(rendered, consolidateForMaxDataPoints(s, opts.MaxDataPoints))
+	}
+
+	switch opts.Format {
+	case pickleFormat:
 		w.Header().Set("Content-Type", "application/octet-stream")
-		return renderResultsPickle(w, series.Values)
+		return renderResultsPickle(w, rendered)
+	case csvFormat:
+		w.Header().Set("Content-Type", "text/csv")
+		return renderResultsCSV(w, rendered)
+	case rawFormat:
+		w.Header().Set("Content-Type", "text/plain")
+		return renderResultsRaw(w, rendered)
+	case msgpackFormat:
+		w.Header().Set("Content-Type", "application/x-msgpack")
+		return renderResultsMsgpack(w, rendered)
 	}
 
-	// NB: return json unless requesting specifically `pickleFormat`
+	// NB: return json unless requesting another specific format
 	w.Header().Set("Content-Type", "application/json")
-	return renderResultsJSON(w, series.Values)
+	return renderResultsJSON(w, rendered, opts)
 }
 
 const (
@@ -76,6 +138,7 @@ type RenderRequest struct {
 	From          time.Time
 	Until         time.Time
 	MaxDataPoints int64
+	NoNullPoints  bool
 	Compare       time.Duration
 	Timeout       time.Duration
 }
@@ -98,6 +161,14 @@ func ParseRenderRequest(r *http.Request) (RenderRequest, error) {
 		return p, errNoTarget
 	}
 
+	// NB: fall back to json for unknown formats, as graphite-web does.
+	p.Format = r.FormValue("format")
+	switch p.Format {
+	case pickleFormat, csvFormat, rawFormat, msgpackFormat:
+	default:
+		p.Format = jsonFormat
+	}
+
 	fromString, untilString := r.FormValue("from"), r.FormValue("until")
 	if len(fromString) == 0 {
 		fromString = "-30min"
@@ -163,6 +234,18 @@ func ParseRenderRequest(r *http.Request) (RenderRequest, error) {
 		p.MaxDataPoints = math.MaxInt64
 	}
 
+	// NB: like graphite-web, the presence of noNullPoints without a value enables it.
+	if _, ok := r.Form["noNullPoints"]; ok {
+		noNullPointsString := r.FormValue("noNullPoints")
+		p.NoNullPoints = true
+		if len(noNullPointsString) != 0 {
+			p.NoNullPoints, err = strconv.ParseBool(noNullPointsString)
+			if err != nil {
+				return p, errors.NewInvalidParamsError(fmt.Errorf("invalid 'noNullPoints': %s", noNullPointsString))
+			}
+		}
+	}
+
 	compareString := r.FormValue("compare")
 
 	if compareFrom, err := graphite.ParseTime(
@@ -194,10 +277,19 @@ func ParseRenderRequest(r *http.Request) (RenderRequest, error) {
 	return p, nil
 }
 
-func renderResultsJSON(w io.Writer, series []*ts.Series) error {
+func renderResultsJSON(
+	w io.Writer,
+	series []renderedSeries,
+	opts RenderResultsOptions,
+) error {
 	jw := json.NewWriter(w)
 	jw.BeginArray()
 	for _, s := range series {
+		// NB: graphite-web omits series without any points when skipping nulls.
+		if opts.NoNullPoints && s.AllNaN() {
+			continue
+		}
+
 		jw.BeginObject()
 		jw.BeginObjectField("target")
 		jw.WriteString(s.Name())
@@ -207,6 +299,10 @@ func renderResultsJSON(w io.Writer, series []*ts.Series) error {
 		if !s.AllNaN() {
 			for i := 0; i < s.Len(); i++ {
 				timestamp, val := s.StartTimeForStep(i), s.ValueAt(i)
+				if opts.NoNullPoints && math.IsNaN(val) {
+					continue
+				}
+
 				jw.BeginArray()
 				jw.WriteFloat64(val)
 				jw.WriteInt(int(timestamp.Unix()))
@@ -218,13 +314,25 @@ func renderResultsJSON(w io.Writer, series []*ts.Series) error {
 		jw.BeginObjectField("step_size_ms")
 		jw.WriteInt(s.MillisPerStep())
 
+		if s.valuesPerPoint > 1 {
+			jw.BeginObjectField("consolidation")
+			jw.BeginObject()
+			jw.BeginObjectField("max_data_points")
+			jw.WriteInt(int(opts.MaxDataPoints))
+			jw.BeginObjectField("values_per_point")
+			jw.WriteInt(s.valuesPerPoint)
+			jw.BeginObjectField("original_step_size_ms")
+			jw.WriteInt(s.originalMillisPerStep)
+			jw.EndObject()
+		}
+
 		jw.EndObject()
 	}
 	jw.EndArray()
 	return jw.Close()
 }
 
-func renderResultsPickle(w io.Writer, series []*ts.Series) error {
+func renderResultsPickle(w io.Writer, series []renderedSeries) error {
 	pw := pickle.NewWriter(w)
 	pw.BeginList()
 
@@ -256,3 +364,153 @@ func renderResultsPickle(w io.Writer, series []*ts.Series) error {
 
 	return pw.Close()
 }
+
+// formatRenderValue formats a value the way python formats floats, which is
+// how graphite-web writes values in the csv and raw formats.
+func formatRenderValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+
+	abs := math.Abs(v)
+	if abs >= 1e16 || (abs < 1e-4 && abs != 0) {
+		return strconv.FormatFloat(v, 'e', -1, 64)
+	}
+
+	formatted := strconv.FormatFloat(v, 'f', -1, 64)
+	if v == math.Trunc(v) {
+		formatted += ".0"
+	}
+
+	return formatted
+}
+
+// renderResultsCSV writes a row of series name, timestamp and value for each
+// datapoint, with an empty value for nulls.
+func renderResultsCSV(w io.Writer, series []renderedSeries) error {
+	cw := csv.NewWriter(w)
+	for _, s := range series {
+		for i := 0; i < s.Len(); i++ {
+			var (
+				timestamp = s.StartTimeForStep(i).UTC().Format(csvTimeFormat)
+				val       = s.ValueAt(i)
+				formatted string
+			)
+			if !math.IsNaN(val) {
+				formatted = formatRenderValue(val)
+			}
+
+			if err := cw.Write([]string{s.Name(), timestamp, formatted}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// renderResultsRaw writes a line per series of the form
+// `name,start,end,step|value,value,...`, with None for nulls.
+func renderResultsRaw(w io.Writer, series []renderedSeries) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range series {
+		fmt.Fprintf(bw, "%s,%d,%d,%d|", s.Name(), s.StartTime().Unix(),
+			s.EndTime().Unix(), s.MillisPerStep()/1000)
+		for i := 0; i < s.Len(); i++ {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+
+			if val := s.ValueAt(i); math.IsNaN(val) {
+				bw.WriteString(rawNullValue)
+			} else {
+				bw.WriteString(formatRenderValue(val))
+			}
+		}
+
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+// renderResultsMsgpack writes the series in the msgpack format, as a list of
+// maps with the same keys as graphite-web.
+func renderResultsMsgpack(w io.Writer, series []renderedSeries) error {
+	bw := bufio.NewWriter(w)
+	enc := msgpack.NewEncoder(bw)
+	if err := enc.EncodeArrayLen(len(series)); err != nil {
+		return err
+	}
+
+	for _, s := range series {
+		if err := encodeMsgpackSeries(enc, s); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func encodeMsgpackSeries(enc *msgpack.Encoder, s renderedSeries) error {
+	if err := enc.EncodeMapLen(7); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		key   string
+		value int64
+	}{
+		{key: "start", value: s.StartTime().Unix()},
+		{key: "end", value: s.EndTime().Unix()},
+		{key: "step", value: int64(s.MillisPerStep() / 1000)},
+		{key: "valuesPerPoint", value: int64(s.valuesPerPoint)},
+	} {
+		if err := enc.EncodeString(field.key); err != nil {
+			return err
+		}
+		if err := enc.EncodeInt64(field.value); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range []struct {
+		key   string
+		value string
+	}{
+		{key: "name", value: s.Name()},
+		{key: "pathExpression", value: s.Specification},
+	} {
+		if err := enc.EncodeString(field.key); err != nil {
+			return err
+		}
+		if err := enc.EncodeString(field.value); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.EncodeString("values"); err != nil {
+		return err
+	}
+	if err := enc.EncodeArrayLen(s.Len()); err != nil {
+		return err
+	}
+	for i := 0; i < s.Len(); i++ {
+		var err error
+		if val := s.ValueAt(i); math.IsNaN(val) {
+			err = enc.EncodeNil()
+		} else {
+			err = enc.EncodeFloat64(val)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
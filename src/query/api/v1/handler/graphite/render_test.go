@@ -23,6 +23,7 @@ package graphite
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -35,6 +36,7 @@ import (
 	"github.com/m3db/m3/src/query/ts"
 
 	"github.com/stretchr/testify/require"
+	msgpack "gopkg.in/vmihailenco/msgpack.v2"
 )
 
 func TestParseNoQuery(t *testing.T) {
@@ -137,8 +139,10 @@ func TestParseQueryResultsMaxDatapoints(t *testing.T) {
 	require.NoError(t, err)
 
 	expected := fmt.Sprintf(
-		`[{"target":"a","datapoints":[[4.000000,%d]],"step_size_ms":%d}]`,
-		start.Unix(), end.Sub(start)/time.Millisecond)
+		`[{"target":"a","datapoints":[[4.000000,%d]],"step_size_ms":%d,`+
+			`"consolidation":{"max_data_points":1,"values_per_point":%d,"original_step_size_ms":%d}}]`,
+		start.Unix(), end.Sub(start)/time.Millisecond,
+		end.Sub(start)/resolution, resolution/time.Millisecond)
 
 	require.Equal(t, expected, string(buf))
 }
@@ -188,3 +192,186 @@ func newGraphiteReadHTTPRequest(t *testing.T) *http.Request {
 	require.NoError(t, err)
 	return req
 }
+
+func TestParseQueryResultsFormats(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	resolution := 10 * time.Second
+	vals := ts.NewFixedStepValues(resolution, 3, 1.5, start)
+	vals.SetValueAt(1, math.NaN())
+	seriesList := ts.SeriesList{
+		ts.NewSeries([]byte("a"), vals, models.NewTags(0, nil)),
+	}
+	for _, series := range seriesList {
+		series.SetResolution(resolution)
+	}
+
+	tests := []struct {
+		format      string
+		contentType string
+		expected    string
+	}{
+		{
+			format:      "csv",
+			contentType: "text/csv",
+			expected: "a,2017-07-14 02:40:00,1.5\n" +
+				"a,2017-07-14 02:40:10,\n" +
+				"a,2017-07-14 02:40:20,1.5\n",
+		},
+		{
+			format:      "raw",
+			contentType: "text/plain",
+			expected:    "a,1500000000,1500000030,10|1.5,None,1.5\n",
+		},
+	}
+
+	for _, test := range tests {
+		mockStorage := mock.NewMockStorage()
+		mockStorage.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
+		handler := NewRenderHandler(mockStorage, nil)
+
+		req := newGraphiteReadHTTPRequest(t)
+		req.URL.RawQuery = fmt.Sprintf("target=foo.bar&from=%d&until=%d&format=%s",
+			start.Unix(), start.Unix()+30, test.format)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		res := recorder.Result()
+		require.Equal(t, 200, res.StatusCode, test.format)
+		require.Equal(t, test.contentType, res.Header.Get("Content-Type"), test.format)
+
+		buf, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, string(buf), test.format)
+	}
+}
+
+func TestParseQueryResultsMsgpack(t *testing.T) {
+	mockStorage := mock.NewMockStorage()
+	start := time.Unix(1500000000, 0)
+	resolution := 10 * time.Second
+	vals := ts.NewFixedStepValues(resolution, 2, 3, start)
+	vals.SetValueAt(1, math.NaN())
+	seriesList := ts.SeriesList{
+		ts.NewSeries([]byte("a"), vals, models.NewTags(0, nil)),
+	}
+	for _, series := range seriesList {
+		series.SetResolution(resolution)
+	}
+
+	mockStorage.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
+	handler := NewRenderHandler(mockStorage, nil)
+
+	req := newGraphiteReadHTTPRequest(t)
+	req.URL.RawQuery = fmt.Sprintf("target=foo.bar&from=%d&until=%d&format=msgpack",
+		start.Unix(), start.Unix()+20)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	require.Equal(t, 200, res.StatusCode)
+	require.Equal(t, "application/x-msgpack", res.Header.Get("Content-Type"))
+
+	var decoded []map[string]interface{}
+	require.NoError(t, msgpack.NewDecoder(res.Body).Decode(&decoded))
+	require.Equal(t, 1, len(decoded))
+
+	result := decoded[0]
+	require.Equal(t, "a", result["name"])
+	require.Equal(t, "foo.bar", result["pathExpression"])
+	require.EqualValues(t, 1500000000, result["start"])
+	require.EqualValues(t, 1500000020, result["end"])
+	require.EqualValues(t, 10, result["step"])
+	require.EqualValues(t, 1, result["valuesPerPoint"])
+	require.Equal(t, []interface{}{3.0, nil}, result["values"])
+}
+
+func TestParseQueryResultsNoNullPoints(t *testing.T) {
+	mockStorage := mock.NewMockStorage()
+	start := time.Unix(1500000000, 0)
+	resolution := 10 * time.Second
+	vals := ts.NewFixedStepValues(resolution, 3, 3, start)
+	vals.SetValueAt(1, math.NaN())
+	nullVals := ts.NewFixedStepValues(resolution, 3, math.NaN(), start)
+	seriesList := ts.SeriesList{
+		ts.NewSeries([]byte("a"), vals, models.NewTags(0, nil)),
+		ts.NewSeries([]byte("b"), nullVals, models.NewTags(0, nil)),
+	}
+	for _, series := range seriesList {
+		series.SetResolution(resolution)
+	}
+
+	mockStorage.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
+	handler := NewRenderHandler(mockStorage, nil)
+
+	req := newGraphiteReadHTTPRequest(t)
+	req.URL.RawQuery = fmt.Sprintf("target=foo.bar&from=%d&until=%d&noNullPoints",
+		start.Unix(), start.Unix()+30)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	require.Equal(t, 200, res.StatusCode)
+
+	buf, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	expected := fmt.Sprintf(
+		`[{"target":"a","datapoints":[[3.000000,%d],[3.000000,%d]],"step_size_ms":%d}]`,
+		start.Unix(), start.Unix()+20, resolution/time.Millisecond)
+
+	require.Equal(t, expected, string(buf))
+}
+
+func TestParseQueryUnknownFormatFallsBackToJSON(t *testing.T) {
+	mockStorage := mock.NewMockStorage()
+	start := time.Unix(1500000000, 0)
+	resolution := 10 * time.Second
+	vals := ts.NewFixedStepValues(resolution, 2, 3, start)
+	seriesList := ts.SeriesList{
+		ts.NewSeries([]byte("a"), vals, models.NewTags(0, nil)),
+	}
+	for _, series := range seriesList {
+		series.SetResolution(resolution)
+	}
+
+	mockStorage.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
+	handler := NewRenderHandler(mockStorage, nil)
+
+	req := newGraphiteReadHTTPRequest(t)
+	req.URL.RawQuery = fmt.Sprintf("target=foo.bar&from=%d&until=%d&format=png",
+		start.Unix(), start.Unix()+20)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	require.Equal(t, 200, res.StatusCode)
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	buf, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	expected := fmt.Sprintf(
+		`[{"target":"a","datapoints":[[3.000000,%d],[3.000000,%d]],"step_size_ms":%d}]`,
+		start.Unix(), start.Unix()+10, resolution/time.Millisecond)
+
+	require.Equal(t, expected, string(buf))
+}
+
+func TestFormatRenderValue(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{value: 1, expected: "1.0"},
+		{value: -2, expected: "-2.0"},
+		{value: 0, expected: "0.0"},
+		{value: 0.25, expected: "0.25"},
+		{value: 123456.5, expected: "123456.5"},
+		{value: 1e16, expected: "1e+16"},
+		{value: 1e-5, expected: "1e-05"},
+		{value: math.Inf(1), expected: "inf"},
+		{value: math.Inf(-1), expected: "-inf"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, formatRenderValue(test.value))
+	}
+}
@@ -0,0 +1,431 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/graphite/graphite"
+	graphiteStorage "github.com/m3db/m3/src/query/graphite/storage"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/json"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	// TagsURL is the url for listing graphite tags.
+	TagsURL = handler.RoutePrefixV1 + "/graphite/tags"
+
+	// TagValuesURL is the url for listing the values of a graphite tag.
+	TagValuesURL = TagsURL + "/{" + tagVar + "}"
+
+	// AutoCompleteTagsURL is the url for autocompleting graphite tags.
+	AutoCompleteTagsURL = TagsURL + "/autoComplete/tags"
+
+	// AutoCompleteValuesURL is the url for autocompleting graphite tag values.
+	AutoCompleteValuesURL = TagsURL + "/autoComplete/values"
+
+	tagVar           = "tag"
+	tagParam         = "tag"
+	filterParam      = "filter"
+	exprParam        = "expr"
+	tagPrefixParam   = "tagPrefix"
+	valuePrefixParam = "valuePrefix"
+	limitParam       = "limit"
+
+	defaultAutoCompleteLimit = 100
+
+	// NB: graphite path nodes are stored as reserved tags which are hidden
+	// from tag listings, with the path instead exposed as the name tag.
+	reservedTagPrefix = "__"
+)
+
+var (
+	// TagsHTTPMethods is the HTTP methods used with the tags resources.
+	TagsHTTPMethods = []string{http.MethodGet, http.MethodPost}
+
+	errNoTag = errors.New("no tag specified")
+
+	matchAnyValue = []byte(".+")
+)
+
+type tagsRequestType int
+
+const (
+	tagsRequest tagsRequestType = iota
+	tagValuesRequest
+	autoCompleteTagsRequest
+	autoCompleteValuesRequest
+)
+
+type graphiteTagsHandler struct {
+	storage     storage.Storage
+	requestType tagsRequestType
+}
+
+// NewTagsHandler returns a new instance of handler listing graphite tags.
+func NewTagsHandler(storage storage.Storage) http.Handler {
+	return &graphiteTagsHandler{storage: storage, requestType: tagsRequest}
+}
+
+// NewTagValuesHandler returns a new instance of handler listing the values
+// of a graphite tag.
+func NewTagValuesHandler(storage storage.Storage) http.Handler {
+	return &graphiteTagsHandler{storage: storage, requestType: tagValuesRequest}
+}
+
+// NewAutoCompleteTagsHandler returns a new instance of handler
+// autocompleting graphite tags.
+func NewAutoCompleteTagsHandler(storage storage.Storage) http.Handler {
+	return &graphiteTagsHandler{storage: storage, requestType: autoCompleteTagsRequest}
+}
+
+// NewAutoCompleteValuesHandler returns a new instance of handler
+// autocompleting graphite tag values.
+func NewAutoCompleteValuesHandler(storage storage.Storage) http.Handler {
+	return &graphiteTagsHandler{storage: storage, requestType: autoCompleteValuesRequest}
+}
+
+func (h *graphiteTagsHandler) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+	w.Header().Set("Content-Type", "application/json")
+
+	params, rErr := parseTagsParams(r, h.requestType)
+	if rErr != nil {
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	var (
+		results []string
+		err     error
+	)
+
+	if h.requestType == tagsRequest ||
+		h.requestType == autoCompleteTagsRequest {
+		results, err = h.completeTagNames(ctx, params)
+	} else {
+		results, err = h.completeTagValues(ctx, params)
+	}
+
+	if err != nil {
+		logger.Error("unable to complete tags", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	results = params.filterResults(results)
+	switch h.requestType {
+	case tagsRequest:
+		err = renderTagsResultsJSON(w, results)
+	case tagValuesRequest:
+		err = renderTagValuesResultsJSON(w, params.tag, results)
+	default:
+		err = renderAutoCompleteResultsJSON(w, results)
+	}
+
+	if err != nil {
+		logger.Error("unable to render tags results", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+	}
+}
+
+type tagsParams struct {
+	tag      string
+	matchers models.Matchers
+	filter   *regexp.Regexp
+	prefix   string
+	limit    int
+	start    time.Time
+	end      time.Time
+}
+
+// parseTagsParams parses the tag, tag expressions, filters and limits of a
+// tags request; tag expressions default to matching all graphite series.
+func parseTagsParams(
+	r *http.Request,
+	requestType tagsRequestType,
+) (tagsParams, *xhttp.ParseError) {
+	params := tagsParams{end: time.Now()}
+	switch requestType {
+	case tagValuesRequest:
+		params.tag = mux.Vars(r)[tagVar]
+	case autoCompleteValuesRequest:
+		params.tag = r.FormValue(tagParam)
+	}
+
+	if (requestType == tagValuesRequest ||
+		requestType == autoCompleteValuesRequest) && params.tag == "" {
+		return params, xhttp.NewParseError(errNoTag, http.StatusBadRequest)
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return params, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	if exprs := r.Form[exprParam]; len(exprs) > 0 {
+		matchers, err := graphiteStorage.TranslateTagExpressionsToMatchers(exprs)
+		if err != nil {
+			return params, xhttp.NewParseError(
+				fmt.Errorf("invalid '%s': %v", exprParam, err), http.StatusBadRequest)
+		}
+
+		params.matchers = matchers
+	} else {
+		params.matchers = models.Matchers{graphiteStorage.TaggedSeriesMatcher()}
+	}
+
+	switch requestType {
+	case tagsRequest, tagValuesRequest:
+		if filter := r.FormValue(filterParam); filter != "" {
+			re, err := regexp.Compile(filter)
+			if err != nil {
+				return params, xhttp.NewParseError(
+					fmt.Errorf("invalid '%s': %v", filterParam, err), http.StatusBadRequest)
+			}
+
+			params.filter = re
+		}
+	case autoCompleteTagsRequest:
+		params.prefix = r.FormValue(tagPrefixParam)
+		params.limit = defaultAutoCompleteLimit
+	case autoCompleteValuesRequest:
+		params.prefix = r.FormValue(valuePrefixParam)
+		params.limit = defaultAutoCompleteLimit
+	}
+
+	if limit := r.FormValue(limitParam); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return params, xhttp.NewParseError(
+				fmt.Errorf("invalid '%s': %v", limitParam, err), http.StatusBadRequest)
+		}
+
+		params.limit = n
+	}
+
+	return params, nil
+}
+
+// filterResults applies the filter, prefix and limit to the sorted results.
+func (p tagsParams) filterResults(results []string) []string {
+	filtered := results[:0]
+	for _, result := range results {
+		if p.limit > 0 && len(filtered) >= p.limit {
+			break
+		}
+
+		if p.filter != nil && !p.filter.MatchString(result) {
+			continue
+		}
+
+		if !strings.HasPrefix(result, p.prefix) {
+			continue
+		}
+
+		filtered = append(filtered, result)
+	}
+
+	return filtered
+}
+
+func (h *graphiteTagsHandler) completeTagNames(
+	ctx context.Context,
+	params tagsParams,
+) ([]string, error) {
+	query := &storage.CompleteTagsQuery{
+		CompleteNameOnly: true,
+		TagMatchers:      params.matchers,
+		Start:            params.start,
+		End:              params.end,
+	}
+
+	result, err := h.storage.CompleteTags(ctx, query, storage.NewFetchOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{graphite.NameTag}
+	for _, tag := range result.CompletedTags {
+		name := string(tag.Name)
+		if strings.HasPrefix(name, reservedTagPrefix) || name == graphite.NameTag {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (h *graphiteTagsHandler) completeTagValues(
+	ctx context.Context,
+	params tagsParams,
+) ([]string, error) {
+	if params.tag == graphite.NameTag {
+		return h.completePaths(ctx, params)
+	}
+
+	tag := []byte(params.tag)
+	matchers := make(models.Matchers, 0, len(params.matchers)+1)
+	matchers = append(matchers, params.matchers...)
+	matchers = append(matchers, models.Matcher{
+		Type:  models.MatchRegexp,
+		Name:  tag,
+		Value: matchAnyValue,
+	})
+
+	query := &storage.CompleteTagsQuery{
+		CompleteNameOnly: false,
+		FilterNameTags:   [][]byte{tag},
+		TagMatchers:      matchers,
+		Start:            params.start,
+		End:              params.end,
+	}
+
+	result, err := h.storage.CompleteTags(ctx, query, storage.NewFetchOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for _, completed := range result.CompletedTags {
+		if string(completed.Name) != params.tag {
+			continue
+		}
+
+		for _, value := range completed.Values {
+			values = append(values, string(value))
+		}
+	}
+
+	sort.Strings(values)
+	return values, nil
+}
+
+// completePaths returns the values of the name tag, which are not stored as
+// a tag but are rebuilt from the path nodes of each matching series.
+func (h *graphiteTagsHandler) completePaths(
+	ctx context.Context,
+	params tagsParams,
+) ([]string, error) {
+	query := &storage.FetchQuery{
+		TagMatchers: params.matchers,
+		Start:       params.start,
+		End:         params.end,
+	}
+
+	result, err := h.storage.SearchSeries(ctx, query, storage.NewFetchOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(result.Metrics))
+	paths := make([]string, 0, len(result.Metrics))
+	for _, metric := range result.Metrics {
+		var nodes []string
+		for i := 0; ; i++ {
+			node, ok := metric.Tags.Get(graphite.TagName(i))
+			if !ok {
+				break
+			}
+
+			nodes = append(nodes, string(node))
+		}
+
+		path := strings.Join(nodes, ".")
+		if _, ok := seen[path]; ok || len(path) == 0 {
+			continue
+		}
+
+		seen[path] = struct{}{}
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func renderTagsResultsJSON(w io.Writer, tags []string) error {
+	jw := json.NewWriter(w)
+	jw.BeginArray()
+	for _, tag := range tags {
+		jw.BeginObject()
+		jw.BeginObjectField("tag")
+		jw.WriteString(tag)
+		jw.EndObject()
+	}
+
+	jw.EndArray()
+	return jw.Close()
+}
+
+func renderTagValuesResultsJSON(w io.Writer, tag string, values []string) error {
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+
+	jw.BeginObjectField("tag")
+	jw.WriteString(tag)
+
+	jw.BeginObjectField("values")
+	jw.BeginArray()
+	for _, value := range values {
+		jw.BeginObject()
+		jw.BeginObjectField("value")
+		jw.WriteString(value)
+		jw.EndObject()
+	}
+
+	jw.EndArray()
+	jw.EndObject()
+	return jw.Close()
+}
+
+func renderAutoCompleteResultsJSON(w io.Writer, results []string) error {
+	jw := json.NewWriter(w)
+	jw.BeginArray()
+	for _, result := range results {
+		jw.WriteString(result)
+	}
+
+	jw.EndArray()
+	return jw.Close()
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/query/graphite/graphite"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveTags(t *testing.T, url, path string, h http.Handler) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.Handle(url, h)
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestTagsHandler(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			q *storage.CompleteTagsQuery,
+			_ *storage.FetchOptions,
+		) (*storage.CompleteTagsResult, error) {
+			assert.True(t, q.CompleteNameOnly)
+			assert.Equal(t, models.Matchers{
+				{Type: models.MatchRegexp, Name: b("__gpath__"), Value: b(".+")},
+			}, q.TagMatchers)
+			return &storage.CompleteTagsResult{
+				CompleteNameOnly: true,
+				CompletedTags: []storage.CompletedTag{
+					{Name: b("__g0__")},
+					{Name: b("host")},
+					{Name: b("__g1__")},
+					{Name: b("dc")},
+				},
+			}, nil
+		}).Times(2)
+
+	h := NewTagsHandler(store)
+	recorder := serveTags(t, TagsURL, TagsURL, h)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `[{"tag":"dc"},{"tag":"host"},{"tag":"name"}]`,
+		recorder.Body.String())
+
+	recorder = serveTags(t, TagsURL, TagsURL+"?filter=%5Eh", h)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `[{"tag":"host"}]`, recorder.Body.String())
+}
+
+func TestTagValuesHandler(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			q *storage.CompleteTagsQuery,
+			_ *storage.FetchOptions,
+		) (*storage.CompleteTagsResult, error) {
+			assert.False(t, q.CompleteNameOnly)
+			assert.Equal(t, bs("dc"), q.FilterNameTags)
+			assert.Equal(t, models.Matchers{
+				{Type: models.MatchRegexp, Name: b("__gpath__"), Value: b(".+")},
+				{Type: models.MatchEqual, Name: b("host"), Value: b("a")},
+				{Type: models.MatchRegexp, Name: b("dc"), Value: b(".+")},
+			}, q.TagMatchers)
+			return &storage.CompleteTagsResult{
+				CompletedTags: []storage.CompletedTag{
+					{Name: b("dc"), Values: bs("us-west", "us-east")},
+				},
+			}, nil
+		})
+
+	recorder := serveTags(t, TagValuesURL, TagsURL+"/dc?expr=host%3Da",
+		NewTagValuesHandler(store))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `{"tag":"dc","values":[{"value":"us-east"},{"value":"us-west"}]}`,
+		recorder.Body.String())
+}
+
+func TestAutoCompleteTagsHandler(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&storage.CompleteTagsResult{
+			CompleteNameOnly: true,
+			CompletedTags: []storage.CompletedTag{
+				{Name: b("__g0__")},
+				{Name: b("dc")},
+				{Name: b("datacenter")},
+				{Name: b("host")},
+			},
+		}, nil)
+
+	recorder := serveTags(t, AutoCompleteTagsURL,
+		AutoCompleteTagsURL+"?tagPrefix=d&limit=1&expr=host%3Da",
+		NewAutoCompleteTagsHandler(store))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `["datacenter"]`, recorder.Body.String())
+}
+
+func TestAutoCompleteValuesHandlerName(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metric := func(nodes ...string) models.Metric {
+		tags := models.NewTags(len(nodes)+1, nil)
+		for i, node := range nodes {
+			tags = tags.AddTag(models.Tag{
+				Name:  graphite.TagName(i),
+				Value: b(node),
+			})
+		}
+
+		tags = tags.AddTag(models.Tag{Name: b("dc"), Value: b("us-east")})
+		return models.Metric{Tags: tags}
+	}
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().SearchSeries(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&storage.SearchResults{
+			Metrics: models.Metrics{
+				metric("foo", "qux"),
+				metric("foo", "bar"),
+				metric("foo", "bar"),
+				metric("baz"),
+			},
+		}, nil)
+
+	recorder := serveTags(t, AutoCompleteValuesURL,
+		AutoCompleteValuesURL+"?tag=name&valuePrefix=foo.&expr=dc%3Dus-east",
+		NewAutoCompleteValuesHandler(store))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `["foo.bar","foo.qux"]`, recorder.Body.String())
+}
+
+func TestAutoCompleteValuesHandlerErrors(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewAutoCompleteValuesHandler(storage.NewMockStorage(ctrl))
+	for _, path := range []string{
+		AutoCompleteValuesURL,
+		AutoCompleteValuesURL + "?tag=dc&limit=x",
+		AutoCompleteValuesURL + "?tag=dc&expr=dc",
+		AutoCompleteValuesURL + "?tag=dc&expr=dc%3D",
+	} {
+		recorder := serveTags(t, AutoCompleteValuesURL, path, h)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, path)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+)
+
+var (
+	errMissingMeasurement = errors.New("missing measurement")
+	errMissingFields      = errors.New("missing fields")
+	errInvalidNumber      = errors.New("invalid number")
+	errNonFiniteNumber    = errors.New("NaN and Inf field values are not supported")
+)
+
+// point is a single parsed line of the line protocol, only the numeric
+// and boolean fields are retained since M3 does not store strings.
+type point struct {
+	measurement []byte
+	tags        []models.Tag
+	fields      []field
+	timestamp   time.Time
+}
+
+type field struct {
+	key   []byte
+	value float64
+}
+
+// parsePoints parses a line protocol body, returning every point that parsed
+// successfully along with the number of lines that were dropped because they
+// failed to parse and the first parse error.
+func parsePoints(
+	body []byte,
+	precision time.Duration,
+	now time.Time,
+) ([]point, int, error) {
+	var (
+		points   []point
+		firstErr error
+		dropped  int
+	)
+	for lineNum, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		p, err := parsePoint(line, precision, now)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("unable to parse line %d: %v", lineNum+1, err)
+			}
+			dropped++
+			continue
+		}
+
+		points = append(points, p)
+	}
+
+	return points, dropped, firstErr
+}
+
+// parsePoint parses a single line of the form:
+// measurement[,tag_key=tag_value...] field_key=field_value[,...] [timestamp]
+func parsePoint(line []byte, precision time.Duration, now time.Time) (point, error) {
+	var p point
+
+	// The measurement and tag set end at the first unescaped space.
+	seriesEnd := indexUnescaped(line, ' ', false)
+	if seriesEnd < 0 {
+		return p, errMissingFields
+	}
+
+	series := splitUnescaped(line[:seriesEnd], ',', false)
+	p.measurement = unescape(series[0])
+	if len(p.measurement) == 0 {
+		return p, errMissingMeasurement
+	}
+
+	for _, tag := range series[1:] {
+		kv := splitUnescaped(tag, '=', false)
+		if len(kv) != 2 || len(kv[0]) == 0 || len(kv[1]) == 0 {
+			return p, fmt.Errorf("invalid tag: %s", tag)
+		}
+
+		p.tags = append(p.tags, models.Tag{
+			Name:  unescape(kv[0]),
+			Value: unescape(kv[1]),
+		})
+	}
+
+	// The field set ends at the first unescaped space outside of a quoted
+	// string field value, anything after that is the timestamp.
+	rest := bytes.TrimLeft(line[seriesEnd:], " ")
+	fieldsEnd := indexUnescaped(rest, ' ', true)
+	fieldSet, timestamp := rest, []byte(nil)
+	if fieldsEnd >= 0 {
+		fieldSet = rest[:fieldsEnd]
+		timestamp = bytes.TrimSpace(rest[fieldsEnd:])
+	}
+
+	if len(fieldSet) == 0 {
+		return p, errMissingFields
+	}
+
+	for _, f := range splitUnescaped(fieldSet, ',', true) {
+		i := indexUnescaped(f, '=', false)
+		if i <= 0 || i == len(f)-1 {
+			return p, fmt.Errorf("invalid field: %s", f)
+		}
+
+		key, raw := unescape(f[:i]), f[i+1:]
+		if raw[0] == '"' {
+			// String fields can't be stored, so they are validated and skipped.
+			if len(raw) < 2 || raw[len(raw)-1] != '"' {
+				return p, fmt.Errorf("unterminated string field: %s", key)
+			}
+			continue
+		}
+
+		value, err := parseFieldValue(raw)
+		if err != nil {
+			return p, fmt.Errorf("invalid field %s: %v", key, err)
+		}
+
+		p.fields = append(p.fields, field{key: key, value: value})
+	}
+
+	if len(timestamp) == 0 {
+		p.timestamp = now.Truncate(precision)
+		return p, nil
+	}
+
+	t, err := strconv.ParseInt(string(timestamp), 10, 64)
+	if err != nil {
+		return p, fmt.Errorf("invalid timestamp: %s", timestamp)
+	}
+
+	p.timestamp = time.Unix(0, t*int64(precision))
+	return p, nil
+}
+
+// parseFieldValue parses an integer, unsigned integer, float or boolean field
+// value, booleans are stored as 1 and 0.
+func parseFieldValue(raw []byte) (float64, error) {
+	switch string(raw) {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+
+	switch raw[len(raw)-1] {
+	case 'i':
+		v, err := strconv.ParseInt(string(raw[:len(raw)-1]), 10, 64)
+		if err != nil {
+			return 0, errInvalidNumber
+		}
+		return float64(v), nil
+	case 'u':
+		v, err := strconv.ParseUint(string(raw[:len(raw)-1]), 10, 64)
+		if err != nil {
+			return 0, errInvalidNumber
+		}
+		return float64(v), nil
+	}
+
+	v, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return 0, errInvalidNumber
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errNonFiniteNumber
+	}
+
+	return v, nil
+}
+
+// indexUnescaped returns the index of the first occurrence of sep that is not
+// escaped with a backslash, and optionally not inside a double quoted string.
+func indexUnescaped(b []byte, sep byte, quoted bool) int {
+	inQuotes := false
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\\':
+			i++
+		case quoted && b[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && b[i] == sep:
+			return i
+		}
+	}
+
+	return -1
+}
+
+func splitUnescaped(b []byte, sep byte, quoted bool) [][]byte {
+	var parts [][]byte
+	for {
+		i := indexUnescaped(b, sep, quoted)
+		if i < 0 {
+			return append(parts, b)
+		}
+
+		parts = append(parts, b[:i])
+		b = b[i+1:]
+	}
+}
+
+// unescape removes the backslashes escaping commas, equals signs and spaces
+// in measurements, tag keys, tag values and field keys.
+func unescape(b []byte) []byte {
+	if bytes.IndexByte(b, '\\') < 0 {
+		return b
+	}
+
+	unescaped := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == '\\' && i+1 < len(b) {
+			switch b[i+1] {
+			case ',', '=', ' ':
+				i++
+			}
+		}
+		unescaped = append(unescaped, b[i])
+	}
+
+	return unescaped
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePoint(t *testing.T) {
+	now := time.Unix(1556000000, 123456789)
+	tests := []struct {
+		line     string
+		expected point
+	}{
+		{
+			line: "cpu value=1.5 1556000000000000000",
+			expected: point{
+				measurement: []byte("cpu"),
+				fields:      []field{{key: []byte("value"), value: 1.5}},
+				timestamp:   time.Unix(1556000000, 0),
+			},
+		},
+		{
+			line: "cpu,host=a,region=us-east idle=10i,busy=5u,up=true,down=F,msg=\"a b,c=d\" 1556000000000000000",
+			expected: point{
+				measurement: []byte("cpu"),
+				tags: []models.Tag{
+					{Name: []byte("host"), Value: []byte("a")},
+					{Name: []byte("region"), Value: []byte("us-east")},
+				},
+				fields: []field{
+					{key: []byte("idle"), value: 10},
+					{key: []byte("busy"), value: 5},
+					{key: []byte("up"), value: 1},
+					{key: []byte("down"), value: 0},
+				},
+				timestamp: time.Unix(1556000000, 0),
+			},
+		},
+		{
+			line: `disk\ io,path=/var\ log,a\=b=c\,d free\ bytes=-2e3`,
+			expected: point{
+				measurement: []byte("disk io"),
+				tags: []models.Tag{
+					{Name: []byte("path"), Value: []byte("/var log")},
+					{Name: []byte("a=b"), Value: []byte("c,d")},
+				},
+				fields:    []field{{key: []byte("free bytes"), value: -2000}},
+				timestamp: now,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			p, err := parsePoint([]byte(tt.line), time.Nanosecond, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+}
+
+func TestParsePointPrecision(t *testing.T) {
+	now := time.Unix(1556000000, 123456789)
+
+	p, err := parsePoint([]byte("cpu value=1 1556000000"), time.Second, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1556000000, 0), p.timestamp)
+
+	p, err = parsePoint([]byte("cpu value=1"), time.Millisecond, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1556000000, 123000000), p.timestamp)
+}
+
+func TestParsePointErrors(t *testing.T) {
+	lines := []string{
+		"cpu",
+		"cpu ",
+		",host=a value=1",
+		"cpu,host value=1",
+		"cpu,host= value=1",
+		"cpu value",
+		"cpu value=",
+		"cpu value=abc",
+		"cpu value=NaN",
+		"cpu value=1i2",
+		"cpu value=-1u",
+		"cpu msg=\"unterminated",
+		"cpu value=1 notatime",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			_, err := parsePoint([]byte(line), time.Nanosecond, time.Now())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	body := []byte(`# comment
+cpu value=1 1
+
+cpu value=oops 2
+mem used=3i 3
+bad
+`)
+
+	points, dropped, err := parsePoints(body, time.Nanosecond, time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 4")
+	assert.Equal(t, 2, dropped)
+	require.Len(t, points, 2)
+	assert.Equal(t, "cpu", string(points[0].measurement))
+	assert.Equal(t, "mem", string(points[1].measurement))
+}
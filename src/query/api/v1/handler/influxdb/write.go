@@ -0,0 +1,325 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// InfluxWriteURL is the url for the InfluxDB line protocol write handler,
+	// it is compatible with the InfluxDB v1 /write endpoint so clients such as
+	// Telegraf can be pointed at the /api/v1/influxdb prefix.
+	InfluxWriteURL = handler.RoutePrefixV1 + "/influxdb/write"
+
+	// InfluxWriteHTTPMethod is the HTTP method used with this resource.
+	InfluxWriteHTTPMethod = http.MethodPost
+
+	precisionParam = "precision"
+)
+
+var (
+	errNoDownsamplerAndWriter = errors.New("no ingest.DownsamplerAndWriter was set")
+	errBodyTooLarge           = errors.New("request body too large")
+)
+
+// WriteHandler represents a handler for the InfluxDB line protocol write endpoint.
+type WriteHandler struct {
+	downsamplerAndWriter ingest.DownsamplerAndWriter
+	tagOptions           models.TagOptions
+	maxBodyBytes         int64
+	metrics              writeMetrics
+	nowFn                func() time.Time
+}
+
+// NewWriteHandler returns a new instance of handler, request bodies larger
+// than maxBodyBytes after decompression are rejected.
+func NewWriteHandler(
+	downsamplerAndWriter ingest.DownsamplerAndWriter,
+	tagOptions models.TagOptions,
+	maxBodyBytes int64,
+	scope tally.Scope,
+) (http.Handler, error) {
+	if downsamplerAndWriter == nil {
+		return nil, errNoDownsamplerAndWriter
+	}
+
+	return &WriteHandler{
+		downsamplerAndWriter: downsamplerAndWriter,
+		tagOptions:           tagOptions,
+		maxBodyBytes:         maxBodyBytes,
+		metrics:              newWriteMetrics(scope),
+		nowFn:                time.Now,
+	}, nil
+}
+
+type writeMetrics struct {
+	writeSuccess      tally.Counter
+	writeErrorsServer tally.Counter
+	writeErrorsClient tally.Counter
+	droppedLines      tally.Counter
+}
+
+func newWriteMetrics(scope tally.Scope) writeMetrics {
+	return writeMetrics{
+		writeSuccess:      scope.Counter("write.success"),
+		writeErrorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("write.errors"),
+		writeErrorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("write.errors"),
+		droppedLines:      scope.Counter("write.dropped-lines"),
+	}
+}
+
+func (h *WriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The db query parameter is accepted for compatibility but ignored, the
+	// coordinator routes writes to namespaces based on its own configuration.
+	precision, unit, err := parsePrecision(r.URL.Query().Get(precisionParam))
+	if err != nil {
+		h.metrics.writeErrorsClient.Inc(1)
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	body, err := readBody(r, h.maxBodyBytes)
+	if err == errBodyTooLarge {
+		h.metrics.writeErrorsClient.Inc(1)
+		xhttp.Error(w, err, http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		h.metrics.writeErrorsClient.Inc(1)
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	points, dropped, parseErr := parsePoints(body, precision, h.nowFn())
+	if err := h.write(r.Context(), points, unit); err != nil {
+		h.metrics.writeErrorsServer.Inc(1)
+		logging.WithContext(r.Context()).Error("Write error", zap.Any("err", err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if parseErr != nil {
+		// Mirror InfluxDB partial writes: valid lines are written and the
+		// lines that failed to parse are reported back to the client.
+		h.metrics.writeErrorsClient.Inc(1)
+		h.metrics.droppedLines.Inc(int64(dropped))
+		xhttp.Error(w, fmt.Errorf("partial write: %v dropped=%d", parseErr, dropped),
+			http.StatusBadRequest)
+		return
+	}
+
+	h.metrics.writeSuccess.Inc(1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WriteHandler) write(ctx context.Context, points []point, unit xtime.Unit) error {
+	iter := newPointsIter(points, unit, h.tagOptions)
+	if len(iter.tags) == 0 {
+		return nil
+	}
+
+	return h.downsamplerAndWriter.WriteBatch(ctx, iter)
+}
+
+// parsePrecision returns the timestamp precision and matching time unit for
+// the precision query parameter, timestamps default to nanoseconds.
+func parsePrecision(precision string) (time.Duration, xtime.Unit, error) {
+	switch precision {
+	case "", "n", "ns":
+		return time.Nanosecond, xtime.Nanosecond, nil
+	case "u", "us", "µ":
+		return time.Microsecond, xtime.Microsecond, nil
+	case "ms":
+		return time.Millisecond, xtime.Millisecond, nil
+	case "s":
+		return time.Second, xtime.Second, nil
+	case "m":
+		return time.Minute, xtime.Second, nil
+	case "h":
+		return time.Hour, xtime.Second, nil
+	default:
+		return 0, xtime.None, fmt.Errorf("invalid precision: %s", precision)
+	}
+}
+
+// readBody reads the request body, returning errBodyTooLarge if it is larger
+// than maxBodyBytes after decompression.
+func readBody(r *http.Request, maxBodyBytes int64) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		defer gz.Close()
+		body = gz
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(body, maxBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > maxBodyBytes {
+		return nil, errBodyTooLarge
+	}
+
+	return data, nil
+}
+
+// metricName returns the metric name for a field of a measurement, the
+// name is sanitized to be a valid Prometheus metric name so that the
+// series can be queried with PromQL.
+func metricName(measurement, field []byte) []byte {
+	name := make([]byte, 0, len(measurement)+1+len(field))
+	name = append(name, measurement...)
+	name = append(name, '_')
+	name = append(name, field...)
+	return sanitize(name, true)
+}
+
+// sanitize replaces any characters that are not valid in a Prometheus metric
+// or label name with underscores, prefixing an underscore if the name starts
+// with a digit.
+func sanitize(name []byte, allowColons bool) []byte {
+	var sanitized []byte
+	for i, c := range name {
+		valid := c == '_' ||
+			(c >= 'a' && c <= 'z') ||
+			(c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9' && i > 0) ||
+			(c == ':' && allowColons)
+		if valid {
+			if sanitized != nil {
+				sanitized = append(sanitized, c)
+			}
+			continue
+		}
+
+		if sanitized == nil {
+			sanitized = make([]byte, 0, len(name)+1)
+			sanitized = append(sanitized, name[:i]...)
+		}
+
+		if c >= '0' && c <= '9' {
+			sanitized = append(sanitized, '_', c)
+			continue
+		}
+
+		sanitized = append(sanitized, '_')
+	}
+
+	if sanitized == nil {
+		return name
+	}
+
+	return sanitized
+}
+
+func newPointsIter(points []point, unit xtime.Unit, tagOpts models.TagOptions) *pointsIter {
+	// Construct the tags and datapoints upfront so that if the iterator
+	// is reset, we don't have to generate them twice.
+	var (
+		tags       = make([]models.Tags, 0, len(points))
+		datapoints = make([]ts.Datapoints, 0, len(points))
+	)
+	for _, p := range points {
+		pointTags := make([]models.Tag, 0, len(p.tags))
+		for _, tag := range p.tags {
+			pointTags = append(pointTags, models.Tag{
+				Name:  sanitize(tag.Name, false),
+				Value: tag.Value,
+			})
+		}
+
+		for _, f := range p.fields {
+			// Each field is written as its own series, so the tags are copied
+			// before the name is set to avoid sharing the underlying slice.
+			fieldTags := models.NewTags(len(pointTags)+1, tagOpts).
+				AddTags(pointTags).
+				SetName(metricName(p.measurement, f.key))
+			tags = append(tags, fieldTags)
+			datapoints = append(datapoints, ts.Datapoints{
+				{Timestamp: p.timestamp, Value: f.value},
+			})
+		}
+	}
+
+	return &pointsIter{
+		idx:        -1,
+		tags:       tags,
+		datapoints: datapoints,
+		unit:       unit,
+	}
+}
+
+type pointsIter struct {
+	idx        int
+	tags       []models.Tags
+	datapoints []ts.Datapoints
+	unit       xtime.Unit
+}
+
+func (i *pointsIter) Next() bool {
+	i.idx++
+	return i.idx < len(i.tags)
+}
+
+func (i *pointsIter) Current() (models.Tags, ts.Datapoints, xtime.Unit) {
+	if len(i.tags) == 0 || i.idx < 0 || i.idx >= len(i.tags) {
+		return models.EmptyTags(), nil, 0
+	}
+
+	return i.tags[i.idx], i.datapoints[i.idx], i.unit
+}
+
+func (i *pointsIter) Reset() error {
+	i.idx = -1
+	return nil
+}
+
+func (i *pointsIter) Error() error {
+	return nil
+}
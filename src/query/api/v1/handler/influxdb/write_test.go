@@ -0,0 +1,236 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	testingest "github.com/m3db/m3/src/query/test/ingest"
+	"github.com/m3db/m3/src/query/util/logging"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+const testMaxBodyBytes = 1 << 20
+
+func newTestWriteHandler(
+	t *testing.T,
+	ctrl *gomock.Controller,
+) (*WriteHandler, *[]testingest.WrittenDatapoint) {
+	mockDownsamplerAndWriter, written := testingest.NewRecordingDownsamplerAndWriter(t, ctrl)
+	h, err := NewWriteHandler(mockDownsamplerAndWriter,
+		models.NewTagOptions(), testMaxBodyBytes, tally.NoopScope)
+	require.NoError(t, err)
+
+	return h.(*WriteHandler), written
+}
+
+func TestInfluxWrite(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestWriteHandler(t, ctrl)
+
+	body := strings.NewReader("cpu,host=a,data.center=us-east idle=90,busy=10i 1556000000\n" +
+		"1disk free=true 1556000010\n")
+	req := httptest.NewRequest(InfluxWriteHTTPMethod,
+		InfluxWriteURL+"?db=telegraf&precision=s", body)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusNoContent, recorder.Code, recorder.Body.String())
+
+	assert.Equal(t, []testingest.WrittenDatapoint{
+		{
+			ID:        testingest.SeriesID("__name__", "cpu_idle", "host", "a", "data_center", "us-east"),
+			Timestamp: time.Unix(1556000000, 0),
+			Value:     90,
+			Unit:      xtime.Second,
+		},
+		{
+			ID:        testingest.SeriesID("__name__", "cpu_busy", "host", "a", "data_center", "us-east"),
+			Timestamp: time.Unix(1556000000, 0),
+			Value:     10,
+			Unit:      xtime.Second,
+		},
+		{
+			ID:        testingest.SeriesID("__name__", "_1disk_free"),
+			Timestamp: time.Unix(1556000010, 0),
+			Value:     1,
+			Unit:      xtime.Second,
+		},
+	}, *written)
+}
+
+func TestInfluxWriteGzip(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestWriteHandler(t, ctrl)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte("mem used=1.5 1556000000000000000"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	req := httptest.NewRequest(InfluxWriteHTTPMethod, InfluxWriteURL, &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusNoContent, recorder.Code, recorder.Body.String())
+
+	require.Len(t, *written, 1)
+	assert.Equal(t, testingest.SeriesID("__name__", "mem_used"), (*written)[0].ID)
+	assert.Equal(t, xtime.Nanosecond, (*written)[0].Unit)
+}
+
+func TestInfluxWriteBodyTooLarge(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestWriteHandler(t, ctrl)
+	h.maxBodyBytes = 16
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(strings.Repeat("mem used=1.5 1556000000000000000\n", 100)))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	for _, test := range []struct {
+		body     *bytes.Reader
+		encoding string
+	}{
+		{body: bytes.NewReader([]byte("mem used=1.5 1556000000000000000"))},
+		{body: bytes.NewReader(buf.Bytes()), encoding: "gzip"},
+	} {
+		req := httptest.NewRequest(InfluxWriteHTTPMethod, InfluxWriteURL, test.body)
+		if test.encoding != "" {
+			req.Header.Set("Content-Encoding", test.encoding)
+		}
+
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code, test.encoding)
+	}
+
+	assert.Len(t, *written, 0)
+}
+
+func TestInfluxWriteDefaultTimestamp(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestWriteHandler(t, ctrl)
+	h.nowFn = func() time.Time {
+		return time.Unix(1556000000, 123456789)
+	}
+
+	req := httptest.NewRequest(InfluxWriteHTTPMethod,
+		InfluxWriteURL+"?precision=ms", strings.NewReader("mem used=1"))
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusNoContent, recorder.Code, recorder.Body.String())
+
+	require.Len(t, *written, 1)
+	assert.Equal(t, time.Unix(1556000000, 123000000), (*written)[0].Timestamp)
+	assert.Equal(t, xtime.Millisecond, (*written)[0].Unit)
+}
+
+func TestInfluxWritePartial(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestWriteHandler(t, ctrl)
+
+	body := strings.NewReader("cpu idle=90 1\ncpu idle=oops 2\n")
+	req := httptest.NewRequest(InfluxWriteHTTPMethod, InfluxWriteURL, body)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "partial write")
+	assert.Contains(t, recorder.Body.String(), "dropped=1")
+
+	require.Len(t, *written, 1)
+	assert.Equal(t, float64(90), (*written)[0].Value)
+}
+
+func TestInfluxWriteInvalidRequest(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestWriteHandler(t, ctrl)
+
+	req := httptest.NewRequest(InfluxWriteHTTPMethod,
+		InfluxWriteURL+"?precision=d", strings.NewReader("cpu idle=90"))
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	req = httptest.NewRequest(InfluxWriteHTTPMethod,
+		InfluxWriteURL, strings.NewReader("cpu idle=90"))
+	req.Header.Set("Content-Encoding", "gzip")
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	assert.Len(t, *written, 0)
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowColons bool
+		expected    string
+	}{
+		{name: "valid_name:total", allowColons: true, expected: "valid_name:total"},
+		{name: "valid_name:total", allowColons: false, expected: "valid_name_total"},
+		{name: "disk.io-time", allowColons: true, expected: "disk_io_time"},
+		{name: "99th", allowColons: true, expected: "_99th"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(sanitize([]byte(tt.name), tt.allowColons)))
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package opentsdb implements the OpenTSDB HTTP put and query APIs so that
+// existing OpenTSDB producers and dashboards can be pointed at M3.
+package opentsdb
+
+import (
+	"net/http"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/util/json"
+)
+
+// routePrefix is the prefix of the OpenTSDB endpoints, clients are pointed at
+// it as they would be at the root of an OpenTSDB server.
+const routePrefix = handler.RoutePrefixV1 + "/opentsdb"
+
+// writeError writes an error in the format returned by OpenTSDB, which is what
+// OpenTSDB clients such as the Grafana datasource expect to parse.
+func writeError(w http.ResponseWriter, err error, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+	jw.BeginObjectField("error")
+	jw.BeginObject()
+	jw.BeginObjectField("code")
+	jw.WriteInt(code)
+	jw.BeginObjectField("message")
+	jw.WriteString(err.Error())
+	jw.EndObject()
+	jw.EndObject()
+	jw.Close()
+}
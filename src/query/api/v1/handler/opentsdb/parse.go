@@ -0,0 +1,704 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/temporal"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+const (
+	noneAggregator = "none"
+
+	// maxDefaultSteps is the number of steps a query without a downsample
+	// is split into, the step is never smaller than minDefaultStep.
+	maxDefaultSteps = 1000
+	minDefaultStep  = 10 * time.Second
+)
+
+var (
+	errMissingStart      = errors.New("missing start time")
+	errMissingQueries    = errors.New("missing queries")
+	errMissingAggregator = errors.New("missing aggregator")
+	errStartAfterEnd     = errors.New("start time must be before end time")
+
+	// absoluteTimeLayouts are the absolute time formats accepted by OpenTSDB.
+	absoluteTimeLayouts = []string{
+		"2006/01/02-15:04:05",
+		"2006/01/02 15:04:05",
+		"2006/01/02-15:04",
+		"2006/01/02 15:04",
+		"2006/01/02",
+	}
+
+	durationUnits = map[string]time.Duration{
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  24 * time.Hour,
+		"w":  7 * 24 * time.Hour,
+		"n":  30 * 24 * time.Hour,
+		"y":  365 * 24 * time.Hour,
+	}
+
+	// aggregators maps OpenTSDB aggregators to the aggregation functions used
+	// to aggregate series. Series are aligned to the query steps before being
+	// aggregated, so the interpolating and zero-if-missing variants share
+	// the same function.
+	aggregators = map[string]string{
+		"sum":    aggregation.SumType,
+		"zimsum": aggregation.SumType,
+		"avg":    aggregation.AverageType,
+		"min":    aggregation.MinType,
+		"mimmin": aggregation.MinType,
+		"max":    aggregation.MaxType,
+		"mimmax": aggregation.MaxType,
+		"count":  aggregation.CountType,
+		"dev":    aggregation.StandardDeviationType,
+	}
+
+	// downsampleAggregators maps OpenTSDB downsample aggregators to the
+	// temporal functions used to downsample each series.
+	downsampleAggregators = map[string]string{
+		"sum":    temporal.SumType,
+		"zimsum": temporal.SumType,
+		"avg":    temporal.AvgType,
+		"min":    temporal.MinType,
+		"mimmin": temporal.MinType,
+		"max":    temporal.MaxType,
+		"mimmax": temporal.MaxType,
+		"count":  temporal.CountType,
+		"dev":    temporal.StdDevType,
+		"last":   temporal.LastType,
+	}
+)
+
+// queryRequest is the body of a query request.
+type queryRequest struct {
+	Start        json.RawMessage `json:"start"`
+	End          json.RawMessage `json:"end"`
+	Queries      []subQuery      `json:"queries"`
+	MsResolution bool            `json:"msResolution"`
+}
+
+// subQuery is a single metric query of a query request.
+type subQuery struct {
+	Aggregator  string            `json:"aggregator"`
+	Metric      string            `json:"metric"`
+	Rate        bool              `json:"rate"`
+	RateOptions rateOptions       `json:"rateOptions"`
+	Downsample  string            `json:"downsample"`
+	Tags        map[string]string `json:"tags"`
+	Filters     []tagFilter       `json:"filters"`
+}
+
+// rateOptions are the options of a rate, resets are only detected for
+// counters and counterMax defaults to the maximum signed 64 bit integer.
+type rateOptions struct {
+	Counter    bool        `json:"counter"`
+	CounterMax json.Number `json:"counterMax"`
+	ResetValue json.Number `json:"resetValue"`
+	DropResets bool        `json:"dropResets"`
+}
+
+type tagFilter struct {
+	Type    string `json:"type"`
+	TagKey  string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
+
+type fillPolicy int
+
+const (
+	fillNone fillPolicy = iota
+	fillNaN
+	fillNull
+	fillZero
+)
+
+var fillPolicies = map[string]fillPolicy{
+	"none": fillNone,
+	"nan":  fillNaN,
+	"null": fillNull,
+	"zero": fillZero,
+}
+
+// downsample is a parsed downsample specifier of the form
+// <interval>-<aggregator>[-<fill policy>], for instance 1m-avg-zero.
+type downsample struct {
+	// interval is zero when downsampling the whole query range into a single
+	// value with the 0all interval.
+	interval   time.Duration
+	aggregator string
+	quantile   float64
+	fill       fillPolicy
+}
+
+// compiledQuery is a sub query compiled to a DAG, along with the parameters
+// required to execute it and render its results.
+type compiledQuery struct {
+	dag           *queryDAG
+	params        models.RequestParams
+	metric        string
+	aggregateTags []string
+	// offset is subtracted from result timestamps so that downsampled values
+	// are labelled with the start of their interval like OpenTSDB does.
+	offset       time.Duration
+	fill         fillPolicy
+	msResolution bool
+}
+
+// queryDAG is a sub query compiled to the DAG executed by the query engine.
+type queryDAG struct {
+	query string
+	nodes parser.Nodes
+	edges parser.Edges
+}
+
+func (q *queryDAG) DAG() (parser.Nodes, parser.Edges, error) {
+	return q.nodes, q.edges, nil
+}
+
+func (q *queryDAG) String() string {
+	return q.query
+}
+
+func (q *queryDAG) add(op parser.Params) {
+	node := parser.NewTransformFromOperation(op, len(q.nodes))
+	if len(q.nodes) > 0 {
+		q.edges = append(q.edges, parser.Edge{
+			ParentID: q.nodes[len(q.nodes)-1].ID,
+			ChildID:  node.ID,
+		})
+	}
+
+	q.nodes = append(q.nodes, node)
+}
+
+// compileQuery compiles a sub query to a DAG which fetches the matching
+// series, downsamples or calculates the rate of each series with a temporal
+// function and then aggregates them across series.
+func compileQuery(
+	q subQuery,
+	start, end time.Time,
+	tagOpts models.TagOptions,
+) (compiledQuery, error) {
+	if q.Metric == "" {
+		return compiledQuery{}, errMissingMetric
+	}
+
+	if q.Aggregator == "" {
+		return compiledQuery{}, errMissingAggregator
+	}
+
+	matchers, groupBy, filteredTags, err := queryMatchers(q, tagOpts)
+	if err != nil {
+		return compiledQuery{}, err
+	}
+
+	var ds downsample
+	if q.Downsample != "" {
+		if ds, err = parseDownsample(q.Downsample); err != nil {
+			return compiledQuery{}, err
+		}
+	}
+
+	result := compiledQuery{
+		params: models.RequestParams{
+			Start:      start,
+			End:        end,
+			IncludeEnd: true,
+			BlockType:  models.TypeSingleBlock,
+		},
+		metric: q.Metric,
+		fill:   ds.fill,
+	}
+
+	var window time.Duration
+	switch {
+	case q.Downsample == "":
+		result.params.Step = defaultStep(start, end)
+	case ds.interval == 0:
+		// Downsampling to a single value evaluates the temporal function once
+		// over the whole range at the end of the query.
+		window = end.Sub(start)
+		result.params.Start = end
+		result.params.Step = window
+		result.offset = window
+	default:
+		// Each step is evaluated at the end of the interval it covers, so the
+		// aligned range is shifted forward by an interval and the results are
+		// shifted back when rendered.
+		window = ds.interval
+		result.params.Start = alignToInterval(start, ds.interval).Add(ds.interval)
+		result.params.End = alignToInterval(end, ds.interval).Add(ds.interval)
+		result.params.Step = ds.interval
+		result.offset = ds.interval
+	}
+
+	temporalOp, err := temporalOperation(q, ds, window)
+	if err != nil {
+		return compiledQuery{}, err
+	}
+
+	result.dag = &queryDAG{query: describeQuery(q, matchers)}
+	fetch := functions.FetchOp{Name: q.Metric, Matchers: matchers}
+	if temporalOp != nil {
+		fetch.Range = window
+	}
+
+	result.dag.add(fetch)
+	if temporalOp != nil {
+		result.dag.add(temporalOp)
+	}
+
+	if q.Rate {
+		rate, err := newRateOp(q.RateOptions)
+		if err != nil {
+			return compiledQuery{}, err
+		}
+
+		result.dag.add(rate)
+	}
+
+	if q.Aggregator != noneAggregator {
+		aggOp, err := aggregationOperation(q.Aggregator, groupBy)
+		if err != nil {
+			return compiledQuery{}, err
+		}
+
+		result.dag.add(aggOp)
+		for _, tag := range filteredTags {
+			if !containsTag(groupBy, tag) {
+				result.aggregateTags = append(result.aggregateTags, tag)
+			}
+		}
+	}
+
+	result.params.Query = result.dag.query
+	return result, nil
+}
+
+// temporalOperation returns the temporal function which downsamples each
+// series, rates are calculated between the downsampled values afterwards.
+func temporalOperation(
+	q subQuery,
+	ds downsample,
+	window time.Duration,
+) (parser.Params, error) {
+	switch {
+	case q.Downsample == "":
+		return nil, nil
+	case ds.aggregator == temporal.QuantileType:
+		return temporal.NewQuantileOp([]interface{}{ds.quantile, window}, temporal.QuantileType)
+	default:
+		return temporal.NewAggOp([]interface{}{window}, ds.aggregator)
+	}
+}
+
+// newRateOp returns the operation calculating the rate of each series with
+// the given options.
+func newRateOp(opts rateOptions) (parser.Params, error) {
+	op := rateOp{
+		counter:    opts.Counter,
+		counterMax: defaultCounterMax,
+		dropResets: opts.DropResets,
+	}
+
+	if opts.CounterMax != "" {
+		v, err := opts.CounterMax.Float64()
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid counterMax: %s", opts.CounterMax)
+		}
+
+		op.counterMax = v
+	}
+
+	if opts.ResetValue != "" {
+		v, err := opts.ResetValue.Float64()
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid resetValue: %s", opts.ResetValue)
+		}
+
+		op.resetValue = v
+	}
+
+	return op, nil
+}
+
+func aggregationOperation(aggregator string, groupBy []string) (parser.Params, error) {
+	params := aggregation.NodeParams{
+		MatchingTags: make([][]byte, 0, len(groupBy)),
+	}
+	for _, tag := range groupBy {
+		params.MatchingTags = append(params.MatchingTags, []byte(tag))
+	}
+
+	if q, ok := parsePercentile(aggregator); ok {
+		params.Parameter = q
+		return aggregation.NewAggregationOp(aggregation.QuantileType, params)
+	}
+
+	opType, ok := aggregators[aggregator]
+	if !ok {
+		return nil, fmt.Errorf("unsupported aggregator: %s", aggregator)
+	}
+
+	return aggregation.NewAggregationOp(opType, params)
+}
+
+// queryMatchers returns the matchers for the metric and tag filters of a sub
+// query, along with the tags the results are grouped by and all the tags that
+// are filtered on.
+func queryMatchers(
+	q subQuery,
+	tagOpts models.TagOptions,
+) (models.Matchers, []string, []string, error) {
+	nameMatcher, err := models.NewMatcher(models.MatchEqual,
+		tagOpts.MetricName(), []byte(q.Metric))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	filters := make([]tagFilter, 0, len(q.Tags)+len(q.Filters))
+	for tag, value := range q.Tags {
+		// Filters specified with the legacy tags map always group by the tag.
+		filterType := "literal_or"
+		if strings.Contains(value, "*") {
+			filterType = "wildcard"
+		}
+
+		filters = append(filters, tagFilter{
+			Type:    filterType,
+			TagKey:  tag,
+			Filter:  value,
+			GroupBy: true,
+		})
+	}
+
+	// Sort the legacy tag filters so that the compiled query is deterministic.
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].TagKey < filters[j].TagKey
+	})
+	filters = append(filters, q.Filters...)
+
+	var (
+		matchers     = models.Matchers{nameMatcher}
+		groupBy      []string
+		filteredTags []string
+	)
+	for _, f := range filters {
+		filterMatchers, err := tagFilterMatchers(f)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		matchers = append(matchers, filterMatchers...)
+		if !containsTag(filteredTags, f.TagKey) {
+			filteredTags = append(filteredTags, f.TagKey)
+		}
+
+		if f.GroupBy && !containsTag(groupBy, f.TagKey) {
+			groupBy = append(groupBy, f.TagKey)
+		}
+	}
+
+	return matchers, groupBy, filteredTags, nil
+}
+
+// tagFilterMatchers converts an OpenTSDB tag filter to matchers. As with
+// OpenTSDB, series must have the filtered tag to match a filter, including
+// the negated filters.
+func tagFilterMatchers(f tagFilter) (models.Matchers, error) {
+	if f.TagKey == "" {
+		return nil, errors.New("missing filter tagk")
+	}
+
+	name := []byte(f.TagKey)
+	var (
+		matchType = models.MatchRegexp
+		value     string
+		negated   bool
+	)
+	switch f.Type {
+	case "literal_or", "iliteral_or", "not_literal_or", "not_iliteral_or":
+		literals := strings.Split(f.Filter, "|")
+		for i, literal := range literals {
+			literals[i] = regexp.QuoteMeta(literal)
+		}
+
+		value = strings.Join(literals, "|")
+		negated = strings.HasPrefix(f.Type, "not_")
+		if strings.HasSuffix(f.Type, "iliteral_or") {
+			value = "(?i)(?:" + value + ")"
+		} else if len(literals) == 1 {
+			matchType = models.MatchEqual
+			value = f.Filter
+		}
+	case "wildcard", "iwildcard":
+		parts := strings.Split(f.Filter, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+
+		value = strings.Join(parts, ".*")
+		if value == ".*" {
+			value = ".+"
+		}
+
+		if f.Type == "iwildcard" {
+			value = "(?i)(?:" + value + ")"
+		}
+	case "regexp":
+		// OpenTSDB regexps match anywhere in the tag value, whereas matchers
+		// are anchored to the whole value.
+		value = ".*(?:" + f.Filter + ").*"
+	default:
+		return nil, fmt.Errorf("unsupported filter type: %s", f.Type)
+	}
+
+	if negated {
+		if matchType == models.MatchEqual {
+			matchType = models.MatchNotEqual
+		} else {
+			matchType = models.MatchNotRegexp
+		}
+	}
+
+	matcher, err := models.NewMatcher(matchType, name, []byte(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s filter for %s: %v", f.Type, f.TagKey, err)
+	}
+
+	if !negated {
+		return models.Matchers{matcher}, nil
+	}
+
+	exists, err := models.NewMatcher(models.MatchRegexp, name, []byte(".+"))
+	if err != nil {
+		return nil, err
+	}
+
+	return models.Matchers{exists, matcher}, nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// describeQuery returns a description of a sub query in the format of the
+// OpenTSDB m query parameter, used to identify the query when tracing.
+func describeQuery(q subQuery, matchers models.Matchers) string {
+	parts := []string{q.Aggregator}
+	if q.Downsample != "" {
+		parts = append(parts, q.Downsample)
+	}
+
+	if q.Rate {
+		parts = append(parts, "rate")
+	}
+
+	tags := make([]string, 0, len(matchers))
+	for _, m := range matchers[1:] {
+		tags = append(tags, m.String())
+	}
+
+	return fmt.Sprintf("%s:%s{%s}", strings.Join(parts, ":"), q.Metric,
+		strings.Join(tags, ","))
+}
+
+// parseDownsample parses a downsample specifier such as 1m-avg or 1h-max-zero.
+func parseDownsample(s string) (downsample, error) {
+	var ds downsample
+	parts := strings.Split(s, "-")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ds, fmt.Errorf("invalid downsample: %s", s)
+	}
+
+	if !strings.HasSuffix(parts[0], "all") {
+		interval, err := parseDuration(parts[0])
+		if err != nil {
+			return ds, fmt.Errorf("invalid downsample interval: %s", parts[0])
+		}
+
+		ds.interval = interval
+	}
+
+	if q, ok := parsePercentile(parts[1]); ok {
+		ds.aggregator = temporal.QuantileType
+		ds.quantile = q
+	} else if ds.aggregator, ok = downsampleAggregators[parts[1]]; !ok {
+		return ds, fmt.Errorf("unsupported downsample aggregator: %s", parts[1])
+	}
+
+	if len(parts) == 3 {
+		fill, ok := fillPolicies[parts[2]]
+		if !ok {
+			return ds, fmt.Errorf("unsupported downsample fill policy: %s", parts[2])
+		}
+
+		ds.fill = fill
+	}
+
+	return ds, nil
+}
+
+// parsePercentile parses percentile aggregators such as p50 and p999.
+func parsePercentile(s string) (float64, bool) {
+	if len(s) < 2 || s[0] != 'p' {
+		return 0, false
+	}
+
+	digits := s[1:]
+	v, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return float64(v) / math.Pow10(len(digits)), true
+}
+
+// parseDuration parses OpenTSDB durations such as 30s, 5m or 1d.
+func parseDuration(s string) (time.Duration, error) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if i <= 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+
+	unit, ok := durationUnits[s[i:]]
+	if !ok {
+		return 0, fmt.Errorf("invalid duration unit: %s", s)
+	}
+
+	v, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+
+	return time.Duration(v) * unit, nil
+}
+
+// parseTime parses a query start or end time, which is either a timestamp
+// in seconds or milliseconds, a relative time such as 1h-ago or an absolute
+// time such as 2019/04/01-12:00:00. Absolute times are parsed as UTC.
+func parseTime(raw json.RawMessage, now time.Time) (time.Time, bool, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}, false, nil
+	}
+
+	s := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return time.Time{}, false, err
+		}
+	}
+
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if v > maxSecondsTimestamp {
+			return time.Unix(0, v*int64(time.Millisecond)), true, nil
+		}
+
+		return time.Unix(v, 0), true, nil
+	}
+
+	if strings.HasSuffix(s, "-ago") {
+		d, err := parseDuration(strings.TrimSuffix(s, "-ago"))
+		if err != nil {
+			return time.Time{}, false, err
+		}
+
+		return now.Add(-d), true, nil
+	}
+
+	for _, layout := range absoluteTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("invalid time: %s", s)
+}
+
+// parseQueryRange parses the start and end of a query, the end defaults to
+// the current time.
+func parseQueryRange(req queryRequest, now time.Time) (time.Time, time.Time, error) {
+	start, ok, err := parseTime(req.Start, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !ok {
+		return time.Time{}, time.Time{}, errMissingStart
+	}
+
+	end, ok, err := parseTime(req.End, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !ok {
+		end = now
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, errStartAfterEnd
+	}
+
+	return start, end, nil
+}
+
+// defaultStep returns the step of queries without a downsample.
+func defaultStep(start, end time.Time) time.Duration {
+	step := (end.Sub(start) / maxDefaultSteps).Truncate(time.Second)
+	if step < minDefaultStep {
+		return minDefaultStep
+	}
+
+	return step
+}
+
+// alignToInterval aligns a time to a multiple of the interval since the epoch.
+func alignToInterval(t time.Time, interval time.Duration) time.Time {
+	return t.Add(-time.Duration(t.UnixNano() % int64(interval)))
+}
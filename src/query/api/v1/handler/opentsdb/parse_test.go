@@ -0,0 +1,358 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/temporal"
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Unix(1556000000, 0)
+	tests := []struct {
+		raw      string
+		expected time.Time
+	}{
+		{raw: `1556000000`, expected: time.Unix(1556000000, 0)},
+		{raw: `"1556000000"`, expected: time.Unix(1556000000, 0)},
+		{raw: `1556000000123`, expected: time.Unix(1556000000, 123000000)},
+		{raw: `"1h-ago"`, expected: now.Add(-time.Hour)},
+		{raw: `"2d-ago"`, expected: now.Add(-48 * time.Hour)},
+		{raw: `"2019/04/23-06:13:20"`, expected: time.Unix(1556000000, 0).UTC()},
+		{raw: `"2019/04/23 06:13"`, expected: time.Unix(1555999980, 0).UTC()},
+		{raw: `"2019/04/23"`, expected: time.Unix(1555977600, 0).UTC()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			parsed, ok, err := parseTime(json.RawMessage(tt.raw), now)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.True(t, tt.expected.Equal(parsed), "%v != %v", tt.expected, parsed)
+		})
+	}
+
+	_, ok, err := parseTime(nil, now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, raw := range []string{`"1x-ago"`, `"yesterday"`, `1.5`, `{}`} {
+		_, _, err := parseTime(json.RawMessage(raw), now)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestParseQueryRange(t *testing.T) {
+	now := time.Unix(1556000000, 0)
+
+	start, end, err := parseQueryRange(queryRequest{
+		Start: json.RawMessage(`"1h-ago"`),
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), start)
+	assert.Equal(t, now, end)
+
+	_, _, err = parseQueryRange(queryRequest{}, now)
+	assert.Equal(t, errMissingStart, err)
+
+	_, _, err = parseQueryRange(queryRequest{
+		Start: json.RawMessage(`1556000000`),
+		End:   json.RawMessage(`1555000000`),
+	}, now)
+	assert.Equal(t, errStartAfterEnd, err)
+}
+
+func TestParseDownsample(t *testing.T) {
+	tests := []struct {
+		downsample string
+		expected   downsample
+	}{
+		{
+			downsample: "1m-avg",
+			expected:   downsample{interval: time.Minute, aggregator: temporal.AvgType},
+		},
+		{
+			downsample: "500ms-mimmax-nan",
+			expected: downsample{
+				interval:   500 * time.Millisecond,
+				aggregator: temporal.MaxType,
+				fill:       fillNaN,
+			},
+		},
+		{
+			downsample: "0all-sum-zero",
+			expected:   downsample{aggregator: temporal.SumType, fill: fillZero},
+		},
+		{
+			downsample: "1h-p999-null",
+			expected: downsample{
+				interval:   time.Hour,
+				aggregator: temporal.QuantileType,
+				quantile:   0.999,
+				fill:       fillNull,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.downsample, func(t *testing.T) {
+			ds, err := parseDownsample(tt.downsample)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ds)
+		})
+	}
+
+	for _, invalid := range []string{"1m", "1x-avg", "m-avg", "0m-avg", "1m-first", "1m-avg-foo", "1m-avg-zero-x"} {
+		_, err := parseDownsample(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTagFilterMatchers(t *testing.T) {
+	tests := []struct {
+		filter   tagFilter
+		expected []models.Matcher
+	}{
+		{
+			filter:   tagFilter{Type: "literal_or", TagKey: "host", Filter: "web01"},
+			expected: []models.Matcher{{Type: models.MatchEqual, Value: []byte("web01")}},
+		},
+		{
+			filter:   tagFilter{Type: "literal_or", TagKey: "host", Filter: "web01|web.02"},
+			expected: []models.Matcher{{Type: models.MatchRegexp, Value: []byte(`web01|web\.02`)}},
+		},
+		{
+			filter:   tagFilter{Type: "iliteral_or", TagKey: "host", Filter: "web01"},
+			expected: []models.Matcher{{Type: models.MatchRegexp, Value: []byte(`(?i)(?:web01)`)}},
+		},
+		{
+			filter: tagFilter{Type: "not_literal_or", TagKey: "host", Filter: "web01"},
+			expected: []models.Matcher{
+				{Type: models.MatchRegexp, Value: []byte(".+")},
+				{Type: models.MatchNotEqual, Value: []byte("web01")},
+			},
+		},
+		{
+			filter: tagFilter{Type: "not_iliteral_or", TagKey: "host", Filter: "a|b"},
+			expected: []models.Matcher{
+				{Type: models.MatchRegexp, Value: []byte(".+")},
+				{Type: models.MatchNotRegexp, Value: []byte("(?i)(?:a|b)")},
+			},
+		},
+		{
+			filter:   tagFilter{Type: "wildcard", TagKey: "host", Filter: "web*.com"},
+			expected: []models.Matcher{{Type: models.MatchRegexp, Value: []byte(`web.*\.com`)}},
+		},
+		{
+			filter:   tagFilter{Type: "wildcard", TagKey: "host", Filter: "*"},
+			expected: []models.Matcher{{Type: models.MatchRegexp, Value: []byte(".+")}},
+		},
+		{
+			filter:   tagFilter{Type: "iwildcard", TagKey: "host", Filter: "Web*"},
+			expected: []models.Matcher{{Type: models.MatchRegexp, Value: []byte("(?i)(?:Web.*)")}},
+		},
+		{
+			filter:   tagFilter{Type: "regexp", TagKey: "host", Filter: "^web[0-9]+"},
+			expected: []models.Matcher{{Type: models.MatchRegexp, Value: []byte(".*(?:^web[0-9]+).*")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter.Type+":"+tt.filter.Filter, func(t *testing.T) {
+			matchers, err := tagFilterMatchers(tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.expected), len(matchers))
+			for i, m := range matchers {
+				assert.Equal(t, "host", string(m.Name))
+				assert.Equal(t, tt.expected[i].Type, m.Type)
+				assert.Equal(t, string(tt.expected[i].Value), string(m.Value))
+			}
+		})
+	}
+
+	invalid := []tagFilter{
+		{Type: "literal_or", Filter: "web01"},
+		{Type: "not_key", TagKey: "host"},
+		{Type: "regexp", TagKey: "host", Filter: "web("},
+	}
+	for _, f := range invalid {
+		_, err := tagFilterMatchers(f)
+		assert.Error(t, err, f.Type)
+	}
+
+	assert.True(t, matchesValue(t, tagFilter{Type: "regexp", TagKey: "host", Filter: "eb0"}, "web01"))
+	assert.True(t, matchesValue(t, tagFilter{Type: "iwildcard", TagKey: "host", Filter: "WEB*"}, "web01"))
+	assert.False(t, matchesValue(t, tagFilter{Type: "wildcard", TagKey: "host", Filter: "WEB*"}, "web01"))
+}
+
+func matchesValue(t *testing.T, f tagFilter, value string) bool {
+	matchers, err := tagFilterMatchers(f)
+	require.NoError(t, err)
+	for _, m := range matchers {
+		if !m.Matches([]byte(value)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestCompileQuery(t *testing.T) {
+	start := time.Unix(1556000030, 0)
+	end := time.Unix(1556003630, 0)
+
+	q, err := compileQuery(subQuery{
+		Aggregator: "sum",
+		Metric:     "sys.cpu.user",
+		Downsample: "1m-avg-zero",
+		Tags:       map[string]string{"host": "*"},
+		Filters: []tagFilter{
+			{Type: "literal_or", TagKey: "dc", Filter: "lga"},
+		},
+	}, start, end, models.NewTagOptions())
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Unix(1556000040, 0), q.params.Start)
+	assert.Equal(t, time.Unix(1556003640, 0), q.params.End)
+	assert.Equal(t, time.Minute, q.params.Step)
+	assert.Equal(t, time.Minute, q.offset)
+	assert.Equal(t, fillZero, q.fill)
+	assert.Equal(t, "sys.cpu.user", q.metric)
+	assert.Equal(t, []string{"dc"}, q.aggregateTags)
+	assert.Equal(t, `sum:1m-avg-zero:sys.cpu.user{host=~".+",dc="lga"}`, q.params.Query)
+
+	nodes, edges, err := q.dag.DAG()
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+	require.Len(t, edges, 2)
+
+	fetch, ok := nodes[0].Op.(functions.FetchOp)
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, fetch.Range)
+	require.Len(t, fetch.Matchers, 3)
+	assert.Equal(t, "__name__", string(fetch.Matchers[0].Name))
+	assert.Equal(t, "sys.cpu.user", string(fetch.Matchers[0].Value))
+	assert.Equal(t, temporal.AvgType, nodes[1].Op.OpType())
+	assert.Equal(t, aggregation.SumType, nodes[2].Op.OpType())
+	assert.Equal(t, nodes[0].ID, edges[0].ParentID)
+	assert.Equal(t, nodes[1].ID, edges[0].ChildID)
+	assert.Equal(t, nodes[1].ID, edges[1].ParentID)
+	assert.Equal(t, nodes[2].ID, edges[1].ChildID)
+}
+
+func TestCompileQueryRate(t *testing.T) {
+	start := time.Unix(1556000000, 0)
+	end := start.Add(time.Hour)
+
+	q, err := compileQuery(subQuery{
+		Aggregator:  "none",
+		Metric:      "requests",
+		Rate:        true,
+		RateOptions: rateOptions{Counter: true},
+	}, start, end, models.NewTagOptions())
+	require.NoError(t, err)
+
+	nodes, _, err := q.dag.DAG()
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, time.Duration(0), nodes[0].Op.(functions.FetchOp).Range)
+	assert.Equal(t, rateOp{counter: true, counterMax: defaultCounterMax}, nodes[1].Op)
+	assert.Equal(t, minDefaultStep, q.params.Step)
+	assert.Equal(t, start, q.params.Start)
+	assert.Equal(t, end, q.params.End)
+	assert.Equal(t, time.Duration(0), q.offset)
+	assert.Empty(t, q.aggregateTags)
+
+	q, err = compileQuery(subQuery{
+		Aggregator: "p95",
+		Metric:     "temperature",
+		Downsample: "5m-max",
+		Rate:       true,
+	}, start, end, models.NewTagOptions())
+	require.NoError(t, err)
+
+	// Series are downsampled before the rate is calculated between the
+	// downsampled values.
+	nodes, _, err = q.dag.DAG()
+	require.NoError(t, err)
+	require.Len(t, nodes, 4)
+	assert.Equal(t, 5*time.Minute, nodes[0].Op.(functions.FetchOp).Range)
+	assert.Equal(t, temporal.MaxType, nodes[1].Op.OpType())
+	assert.Equal(t, rateType, nodes[2].Op.OpType())
+	assert.Equal(t, aggregation.QuantileType, nodes[3].Op.OpType())
+
+	_, err = compileQuery(subQuery{
+		Aggregator:  "sum",
+		Metric:      "requests",
+		Rate:        true,
+		RateOptions: rateOptions{Counter: true, CounterMax: "max"},
+	}, start, end, models.NewTagOptions())
+	assert.Error(t, err)
+}
+
+func TestCompileQueryDownsampleAll(t *testing.T) {
+	start := time.Unix(1556000000, 0)
+	end := start.Add(time.Hour)
+
+	q, err := compileQuery(subQuery{
+		Aggregator: "max",
+		Metric:     "temperature",
+		Downsample: "0all-max",
+	}, start, end, models.NewTagOptions())
+	require.NoError(t, err)
+
+	assert.Equal(t, end, q.params.Start)
+	assert.Equal(t, end, q.params.End)
+	assert.Equal(t, time.Hour, q.params.Step)
+	assert.Equal(t, time.Hour, q.offset)
+
+	nodes, _, err := q.dag.DAG()
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+	assert.Equal(t, time.Hour, nodes[0].Op.(functions.FetchOp).Range)
+	assert.Equal(t, temporal.MaxType, nodes[1].Op.OpType())
+}
+
+func TestCompileQueryErrors(t *testing.T) {
+	start := time.Unix(1556000000, 0)
+	end := start.Add(time.Hour)
+
+	invalid := []subQuery{
+		{Aggregator: "sum"},
+		{Metric: "cpu"},
+		{Metric: "cpu", Aggregator: "first"},
+		{Metric: "cpu", Aggregator: "sum", Downsample: "1m"},
+		{Metric: "cpu", Aggregator: "sum", Filters: []tagFilter{{Type: "unknown", TagKey: "host"}}},
+	}
+	for _, q := range invalid {
+		_, err := compileQuery(q, start, end, models.NewTagOptions())
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// PutURL is the url for the OpenTSDB put handler.
+	PutURL = routePrefix + "/api/put"
+
+	// PutHTTPMethod is the HTTP method used with this resource.
+	PutHTTPMethod = http.MethodPost
+
+	summaryParam = "summary"
+	detailsParam = "details"
+
+	// maxSecondsTimestamp is the largest timestamp OpenTSDB treats as being
+	// in seconds, anything larger is treated as being in milliseconds.
+	maxSecondsTimestamp = math.MaxUint32
+)
+
+var (
+	errNoDownsamplerAndWriter = errors.New("no ingest.DownsamplerAndWriter was set")
+	errEmptyBody              = errors.New("missing datapoints")
+	errMissingMetric          = errors.New("missing metric")
+	errMissingTags            = errors.New("at least one tag is required")
+	errEmptyTag               = errors.New("tag names and values must not be empty")
+	errInvalidTimestamp       = errors.New("invalid timestamp")
+	errInvalidValue           = errors.New("unable to parse value to a number")
+)
+
+// PutHandler represents a handler for the OpenTSDB put endpoint.
+type PutHandler struct {
+	downsamplerAndWriter ingest.DownsamplerAndWriter
+	tagOptions           models.TagOptions
+	metrics              putMetrics
+}
+
+// NewPutHandler returns a new instance of handler.
+func NewPutHandler(
+	downsamplerAndWriter ingest.DownsamplerAndWriter,
+	tagOptions models.TagOptions,
+	scope tally.Scope,
+) (http.Handler, error) {
+	if downsamplerAndWriter == nil {
+		return nil, errNoDownsamplerAndWriter
+	}
+
+	return &PutHandler{
+		downsamplerAndWriter: downsamplerAndWriter,
+		tagOptions:           tagOptions,
+		metrics:              newPutMetrics(scope),
+	}, nil
+}
+
+type putMetrics struct {
+	writeSuccess      tally.Counter
+	writeErrorsServer tally.Counter
+	writeErrorsClient tally.Counter
+	failedDatapoints  tally.Counter
+}
+
+func newPutMetrics(scope tally.Scope) putMetrics {
+	return putMetrics{
+		writeSuccess:      scope.Counter("write.success"),
+		writeErrorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("write.errors"),
+		writeErrorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("write.errors"),
+		failedDatapoints:  scope.Counter("write.failed-datapoints"),
+	}
+}
+
+// putDatapoint is a single datapoint of a put request, timestamps and values
+// may be sent either as JSON numbers or as strings.
+type putDatapoint struct {
+	Metric    string            `json:"metric"`
+	Timestamp json.Number       `json:"timestamp"`
+	Value     json.Number       `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// putError describes a datapoint that failed to be written.
+type putError struct {
+	Datapoint json.RawMessage `json:"datapoint"`
+	Error     string          `json:"error"`
+}
+
+// putResponse is the body returned when the summary or details parameters
+// are set.
+type putResponse struct {
+	Errors  []putError `json:"errors,omitempty"`
+	Failed  int        `json:"failed"`
+	Success int        `json:"success"`
+}
+
+type parsedDatapoint struct {
+	tags      models.Tags
+	datapoint ts.Datapoint
+	unit      xtime.Unit
+}
+
+func (h *PutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	raw, err := readDatapoints(r)
+	if err != nil {
+		h.metrics.writeErrorsClient.Inc(1)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var (
+		datapoints = make([]parsedDatapoint, 0, len(raw))
+		resp       putResponse
+	)
+	for _, rawDatapoint := range raw {
+		dp, err := h.parseDatapoint(rawDatapoint)
+		if err != nil {
+			resp.Failed++
+			resp.Errors = append(resp.Errors, putError{
+				Datapoint: rawDatapoint,
+				Error:     err.Error(),
+			})
+			continue
+		}
+
+		datapoints = append(datapoints, dp)
+	}
+
+	// Like OpenTSDB the valid datapoints are written even if some of the
+	// datapoints in the request are invalid.
+	if err := h.write(r.Context(), datapoints); err != nil {
+		h.metrics.writeErrorsServer.Inc(1)
+		logging.WithContext(r.Context()).Error("Write error", zap.Any("err", err))
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Success = len(datapoints)
+	if resp.Failed > 0 {
+		h.metrics.writeErrorsClient.Inc(1)
+		h.metrics.failedDatapoints.Inc(int64(resp.Failed))
+	} else {
+		h.metrics.writeSuccess.Inc(1)
+	}
+
+	query := r.URL.Query()
+	_, details := query[detailsParam]
+	_, summary := query[summaryParam]
+	if !details && !summary {
+		if resp.Failed > 0 {
+			writeError(w, fmt.Errorf("%d of %d datapoints failed to be written: %s",
+				resp.Failed, len(raw), resp.Errors[0].Error), http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !details {
+		resp.Errors = nil
+	} else if resp.Errors == nil {
+		resp.Errors = []putError{}
+	}
+
+	code := http.StatusOK
+	if resp.Failed > 0 {
+		code = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logging.WithContext(r.Context()).Error("unable to write put response",
+			zap.Error(err))
+	}
+}
+
+// readDatapoints reads the raw datapoints of the request body, which may be
+// either a single datapoint object or an array of datapoints.
+func readDatapoints(r *http.Request) ([]json.RawMessage, error) {
+	if r.Body == nil {
+		return nil, errEmptyBody
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, errEmptyBody
+	}
+
+	if body[0] != '[' {
+		return []json.RawMessage{body}, nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	if len(raw) == 0 {
+		return nil, errEmptyBody
+	}
+
+	return raw, nil
+}
+
+func (h *PutHandler) parseDatapoint(raw json.RawMessage) (parsedDatapoint, error) {
+	var dp putDatapoint
+	if err := json.Unmarshal(raw, &dp); err != nil {
+		return parsedDatapoint{}, err
+	}
+
+	if dp.Metric == "" {
+		return parsedDatapoint{}, errMissingMetric
+	}
+
+	if len(dp.Tags) == 0 {
+		return parsedDatapoint{}, errMissingTags
+	}
+
+	timestamp, unit, err := parseTimestamp(string(dp.Timestamp))
+	if err != nil {
+		return parsedDatapoint{}, err
+	}
+
+	value, err := strconv.ParseFloat(string(dp.Value), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return parsedDatapoint{}, errInvalidValue
+	}
+
+	tags := make([]models.Tag, 0, len(dp.Tags))
+	for name, value := range dp.Tags {
+		if name == "" || value == "" {
+			return parsedDatapoint{}, errEmptyTag
+		}
+
+		tags = append(tags, models.Tag{Name: []byte(name), Value: []byte(value)})
+	}
+
+	return parsedDatapoint{
+		tags: models.NewTags(len(tags)+1, h.tagOptions).
+			AddTags(tags).
+			SetName([]byte(dp.Metric)),
+		datapoint: ts.Datapoint{Timestamp: timestamp, Value: value},
+		unit:      unit,
+	}, nil
+}
+
+// parseTimestamp parses a put timestamp, which is in milliseconds if it does
+// not fit in 32 bits and in seconds otherwise.
+func parseTimestamp(s string) (time.Time, xtime.Unit, error) {
+	t, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || t <= 0 {
+		return time.Time{}, xtime.None, errInvalidTimestamp
+	}
+
+	if t > maxSecondsTimestamp {
+		return time.Unix(0, t*int64(time.Millisecond)), xtime.Millisecond, nil
+	}
+
+	return time.Unix(t, 0), xtime.Second, nil
+}
+
+func (h *PutHandler) write(ctx context.Context, datapoints []parsedDatapoint) error {
+	if len(datapoints) == 0 {
+		return nil
+	}
+
+	return h.downsamplerAndWriter.WriteBatch(ctx, &putIter{
+		idx:        -1,
+		datapoints: datapoints,
+	})
+}
+
+type putIter struct {
+	idx        int
+	datapoints []parsedDatapoint
+}
+
+func (i *putIter) Next() bool {
+	i.idx++
+	return i.idx < len(i.datapoints)
+}
+
+func (i *putIter) Current() (models.Tags, ts.Datapoints, xtime.Unit) {
+	if len(i.datapoints) == 0 || i.idx < 0 || i.idx >= len(i.datapoints) {
+		return models.EmptyTags(), nil, 0
+	}
+
+	dp := i.datapoints[i.idx]
+	return dp.tags, ts.Datapoints{dp.datapoint}, dp.unit
+}
+
+func (i *putIter) Reset() error {
+	i.idx = -1
+	return nil
+}
+
+func (i *putIter) Error() error {
+	return nil
+}
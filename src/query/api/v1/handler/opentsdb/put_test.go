@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	testingest "github.com/m3db/m3/src/query/test/ingest"
+	"github.com/m3db/m3/src/query/util/logging"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestPutHandler(
+	t *testing.T,
+	ctrl *gomock.Controller,
+) (http.Handler, *[]testingest.WrittenDatapoint) {
+	mockDownsamplerAndWriter, written := testingest.NewRecordingDownsamplerAndWriter(t, ctrl)
+	h, err := NewPutHandler(mockDownsamplerAndWriter,
+		models.NewTagOptions(), tally.NoopScope)
+	require.NoError(t, err)
+
+	return h, written
+}
+
+func executePut(h http.Handler, params, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(PutHTTPMethod, PutURL+params, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestPut(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestPutHandler(t, ctrl)
+
+	recorder := executePut(h, "", `[
+		{"metric": "sys.cpu.nice", "timestamp": 1556000000, "value": 18, "tags": {"host": "web01", "dc": "lga"}},
+		{"metric": "sys.cpu.nice", "timestamp": "1556000000500", "value": "1.5", "tags": {"host": "web02"}}
+	]`)
+	require.Equal(t, http.StatusNoContent, recorder.Code, recorder.Body.String())
+
+	assert.Equal(t, []testingest.WrittenDatapoint{
+		{
+			ID:        testingest.SeriesID("__name__", "sys.cpu.nice", "dc", "lga", "host", "web01"),
+			Timestamp: time.Unix(1556000000, 0),
+			Value:     18,
+			Unit:      xtime.Second,
+		},
+		{
+			ID:        testingest.SeriesID("__name__", "sys.cpu.nice", "host", "web02"),
+			Timestamp: time.Unix(1556000000, 500000000),
+			Value:     1.5,
+			Unit:      xtime.Millisecond,
+		},
+	}, *written)
+}
+
+func TestPutSingleDatapoint(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestPutHandler(t, ctrl)
+
+	recorder := executePut(h, "?summary",
+		`{"metric": "sys.cpu.nice", "timestamp": 1556000000, "value": -2e3, "tags": {"host": "web01"}}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"failed": 0, "success": 1}`, recorder.Body.String())
+
+	require.Len(t, *written, 1)
+	assert.Equal(t, float64(-2000), (*written)[0].Value)
+}
+
+func TestPutPartialFailure(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestPutHandler(t, ctrl)
+
+	body := `[
+		{"metric": "sys.cpu.nice", "timestamp": 1556000000, "value": 18, "tags": {"host": "web01"}},
+		{"metric": "sys.cpu.nice", "timestamp": 1556000000, "value": "NaN", "tags": {"host": "web01"}},
+		{"metric": "sys.cpu.nice", "timestamp": 1556000000, "value": 1, "tags": {}},
+		{"metric": "", "timestamp": 1556000000, "value": 1, "tags": {"host": "web01"}},
+		{"metric": "sys.cpu.nice", "timestamp": -1, "value": 1, "tags": {"host": "web01"}}
+	]`
+
+	recorder := executePut(h, "?details", body)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var resp putResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.Equal(t, 4, resp.Failed)
+	assert.Equal(t, 1, resp.Success)
+	require.Len(t, resp.Errors, 4)
+	assert.Equal(t, errMissingTags.Error(), resp.Errors[1].Error)
+	assert.Equal(t, errMissingMetric.Error(), resp.Errors[2].Error)
+	assert.Equal(t, errInvalidTimestamp.Error(), resp.Errors[3].Error)
+	assert.JSONEq(t, `{"metric": "sys.cpu.nice", "timestamp": -1, "value": 1, "tags": {"host": "web01"}}`,
+		string(resp.Errors[3].Datapoint))
+
+	recorder = executePut(h, "?summary", body)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{"failed": 4, "success": 1}`, recorder.Body.String())
+
+	recorder = executePut(h, "", body)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "4 of 5 datapoints failed to be written")
+
+	// The valid datapoint is written by each of the requests.
+	assert.Len(t, *written, 3)
+}
+
+func TestPutInvalidRequest(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, written := newTestPutHandler(t, ctrl)
+
+	for _, body := range []string{"", " ", "[]", "[{]"} {
+		recorder := executePut(h, "", body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, body)
+		assert.Contains(t, recorder.Body.String(), `"code":400`)
+	}
+
+	assert.Len(t, *written, 0)
+}
+
+func TestNewPutHandlerRequiresWriter(t *testing.T) {
+	_, err := NewPutHandler(nil, models.NewTagOptions(), tally.NoopScope)
+	assert.Equal(t, errNoDownsamplerAndWriter, err)
+}
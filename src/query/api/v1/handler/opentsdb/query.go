@@ -0,0 +1,287 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	xjson "github.com/m3db/m3/src/query/util/json"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// QueryURL is the url for the OpenTSDB query handler.
+	QueryURL = routePrefix + "/api/query"
+
+	// QueryHTTPMethod is the HTTP method used with this resource.
+	QueryHTTPMethod = http.MethodPost
+)
+
+// QueryHandler represents a handler for the OpenTSDB query endpoint.
+type QueryHandler struct {
+	engine      *executor.Engine
+	tagOpts     models.TagOptions
+	limitsCfg   *config.LimitsConfiguration
+	timeoutOpts *prometheus.TimeoutOpts
+	metrics     queryMetrics
+	nowFn       func() time.Time
+}
+
+// NewQueryHandler returns a new instance of handler.
+func NewQueryHandler(
+	engine *executor.Engine,
+	tagOpts models.TagOptions,
+	limitsCfg *config.LimitsConfiguration,
+	timeoutOpts *prometheus.TimeoutOpts,
+	scope tally.Scope,
+) http.Handler {
+	return &QueryHandler{
+		engine:      engine,
+		tagOpts:     tagOpts,
+		limitsCfg:   limitsCfg,
+		timeoutOpts: timeoutOpts,
+		metrics:     newQueryMetrics(scope),
+		nowFn:       time.Now,
+	}
+}
+
+type queryMetrics struct {
+	fetchSuccess      tally.Counter
+	fetchErrorsServer tally.Counter
+	fetchErrorsClient tally.Counter
+}
+
+func newQueryMetrics(scope tally.Scope) queryMetrics {
+	return queryMetrics{
+		fetchSuccess:      scope.Counter("fetch.success"),
+		fetchErrorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("fetch.errors"),
+		fetchErrorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("fetch.errors"),
+	}
+}
+
+// queryResult is the result of a compiled sub query.
+type queryResult struct {
+	query  compiledQuery
+	series []*ts.Series
+}
+
+func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+
+	queries, err := h.parseRequest(r)
+	if err != nil {
+		h.metrics.fetchErrorsClient.Inc(1)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	results := make([]queryResult, 0, len(queries))
+	for _, q := range queries {
+		series, err := native.ReadParsed(ctx, h.engine, q.dag, w, q.params)
+		if err != nil {
+			logger.Error("unable to fetch data", zap.Error(err))
+			h.metrics.fetchErrorsServer.Inc(1)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		results = append(results, queryResult{query: q, series: series})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := renderResultsJSON(w, results, h.tagOpts); err != nil {
+		logger.Error("unable to render results", zap.Error(err))
+		return
+	}
+
+	h.metrics.fetchSuccess.Inc(1)
+}
+
+func (h *QueryHandler) parseRequest(r *http.Request) ([]compiledQuery, error) {
+	timeout, err := prometheus.ParseRequestTimeout(r, h.timeoutOpts.FetchTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Body == nil {
+		return nil, errMissingQueries
+	}
+
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("unable to parse query: %v", err)
+	}
+
+	if len(req.Queries) == 0 {
+		return nil, errMissingQueries
+	}
+
+	now := h.nowFn()
+	start, end, err := parseQueryRange(req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]compiledQuery, 0, len(req.Queries))
+	for _, sq := range req.Queries {
+		q, err := compileQuery(sq, start, end, h.tagOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		q.params.Now = now
+		q.params.Timeout = timeout
+		if err := h.validateQuery(q); err != nil {
+			return nil, err
+		}
+
+		q.msResolution = req.MsResolution
+		queries = append(queries, q)
+	}
+
+	return queries, nil
+}
+
+func (h *QueryHandler) validateQuery(q compiledQuery) error {
+	// Impose the same limit on the number of steps as PromQL queries, to
+	// prevent fine grained downsamples over long ranges.
+	numSteps := int64(q.params.End.Sub(q.params.Start) / q.params.Step)
+	maxComputedDatapoints := h.limitsCfg.MaxComputedDatapoints()
+	if maxComputedDatapoints > 0 && numSteps > maxComputedDatapoints {
+		return fmt.Errorf(
+			"querying from %v to %v with downsample interval %v would result in too many "+
+				"datapoints (end - start / interval > %d). Either increase the downsample "+
+				"interval, decrease the time window, or increase the limit "+
+				"(`limits.maxComputedDatapoints`)",
+			q.params.Start, q.params.End, q.params.Step, maxComputedDatapoints,
+		)
+	}
+
+	return nil
+}
+
+// renderResultsJSON renders results in the format returned by OpenTSDB, with
+// the datapoints of each series as a map of timestamps to values.
+func renderResultsJSON(
+	w io.Writer,
+	results []queryResult,
+	tagOpts models.TagOptions,
+) error {
+	jw := xjson.NewWriter(w)
+	jw.BeginArray()
+	for _, result := range results {
+		q := result.query
+		for _, s := range result.series {
+			if !hasValues(s) {
+				continue
+			}
+
+			jw.BeginObject()
+			jw.BeginObjectField("metric")
+			jw.WriteString(q.metric)
+
+			jw.BeginObjectField("tags")
+			jw.BeginObject()
+			for _, t := range s.Tags.Tags {
+				if string(t.Name) == string(tagOpts.MetricName()) {
+					continue
+				}
+
+				jw.BeginObjectField(string(t.Name))
+				jw.WriteString(string(t.Value))
+			}
+			jw.EndObject()
+
+			jw.BeginObjectField("aggregateTags")
+			jw.BeginArray()
+			for _, t := range q.aggregateTags {
+				jw.WriteString(t)
+			}
+			jw.EndArray()
+
+			jw.BeginObjectField("dps")
+			jw.BeginObject()
+			vals := s.Values()
+			for i := 0; i < s.Len(); i++ {
+				dp := vals.DatapointAt(i)
+				if math.IsNaN(dp.Value) {
+					switch q.fill {
+					case fillNone:
+						continue
+					case fillZero:
+						dp.Value = 0
+					}
+				}
+
+				timestamp := dp.Timestamp.Add(-q.offset)
+				if q.msResolution {
+					jw.BeginObjectField(strconv.FormatInt(
+						timestamp.UnixNano()/int64(time.Millisecond), 10))
+				} else {
+					jw.BeginObjectField(strconv.FormatInt(timestamp.Unix(), 10))
+				}
+
+				// NaNs can't be represented in JSON so the nan fill policy
+				// renders missing values as nulls.
+				if math.IsNaN(dp.Value) {
+					jw.WriteNull()
+				} else {
+					jw.WriteFloat64(dp.Value)
+				}
+			}
+			jw.EndObject()
+
+			jw.EndObject()
+		}
+	}
+	jw.EndArray()
+
+	return jw.Close()
+}
+
+func hasValues(s *ts.Series) bool {
+	vals := s.Values()
+	for i := 0; i < s.Len(); i++ {
+		if !math.IsNaN(vals.ValueAt(i)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestQueryHandler(limitsCfg *config.LimitsConfiguration) http.Handler {
+	mockStorage := mock.NewMockStorage()
+	values, bounds := test.GenerateValuesAndBounds([][]float64{
+		{0, 1, math.NaN(), 3, 4},
+		{5, 6, 7, 8, 9},
+	}, &models.Bounds{
+		Start:    time.Unix(1556000000, 0),
+		Duration: 5 * time.Minute,
+		StepSize: time.Minute,
+	})
+	b := test.NewBlockFromValues(bounds, values)
+	mockStorage.SetFetchBlocksResult(block.Result{Blocks: []block.Block{b}}, nil)
+
+	return NewQueryHandler(
+		executor.NewEngine(mockStorage, tally.NewTestScope("test", nil), time.Minute, nil),
+		models.NewTagOptions(),
+		limitsCfg,
+		&prometheus.TimeoutOpts{FetchTimeout: 15 * time.Second},
+		tally.NoopScope,
+	)
+}
+
+func executeQuery(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(QueryHTTPMethod, QueryURL, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestQuery(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	h := newTestQueryHandler(&config.LimitsConfiguration{})
+	recorder := executeQuery(h, `{
+		"start": 1556000000,
+		"end": 1556000240,
+		"queries": [{"aggregator": "none", "metric": "sys.cpu.user"}]
+	}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	assert.JSONEq(t, `[
+		{
+			"metric": "sys.cpu.user",
+			"tags": {"dummy0": "dummy0"},
+			"aggregateTags": [],
+			"dps": {"1556000000": 0, "1556000060": 1, "1556000180": 3, "1556000240": 4}
+		},
+		{
+			"metric": "sys.cpu.user",
+			"tags": {"dummy1": "dummy1"},
+			"aggregateTags": [],
+			"dps": {"1556000000": 5, "1556000060": 6, "1556000120": 7, "1556000180": 8, "1556000240": 9}
+		}
+	]`, recorder.Body.String())
+}
+
+func TestQueryAggregated(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	h := newTestQueryHandler(&config.LimitsConfiguration{})
+	recorder := executeQuery(h, `{
+		"start": "1556000000",
+		"end": "1556000240",
+		"msResolution": true,
+		"queries": [{
+			"aggregator": "sum",
+			"metric": "sys.cpu.user",
+			"filters": [{"type": "wildcard", "tagk": "host", "filter": "*"}]
+		}]
+	}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	assert.JSONEq(t, `[
+		{
+			"metric": "sys.cpu.user",
+			"tags": {},
+			"aggregateTags": ["host"],
+			"dps": {
+				"1556000000000": 5,
+				"1556000060000": 7,
+				"1556000120000": 7,
+				"1556000180000": 11,
+				"1556000240000": 13
+			}
+		}
+	]`, recorder.Body.String())
+}
+
+func TestQueryInvalidRequest(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	h := newTestQueryHandler(&config.LimitsConfiguration{
+		PerQuery: config.PerQueryLimitsConfiguration{
+			PrivateMaxComputedDatapoints: 100,
+		},
+	})
+
+	bodies := []string{
+		``,
+		`{`,
+		`{"start": "1h-ago", "queries": []}`,
+		`{"queries": [{"aggregator": "sum", "metric": "cpu"}]}`,
+		`{"start": "1h-ago", "queries": [{"aggregator": "sum", "metric": "cpu", "downsample": "1m-first"}]}`,
+		`{"start": "1h-ago", "queries": [{"aggregator": "sum", "metric": "cpu", "downsample": "1s-avg"}]}`,
+	}
+	for _, body := range bodies {
+		recorder := executeQuery(h, body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, body)
+		assert.Contains(t, recorder.Body.String(), `"code":400`, body)
+	}
+}
+
+func TestRenderResultsJSON(t *testing.T) {
+	start := time.Unix(1556000060, 0)
+	values := ts.NewFixedStepValues(time.Minute, 3, math.NaN(), start)
+	values.SetValueAt(0, 1.5)
+	values.SetValueAt(2, 3)
+
+	tags := models.NewTags(2, models.NewTagOptions()).
+		AddTags([]models.Tag{{Name: []byte("host"), Value: []byte("web01")}}).
+		SetName([]byte("sys.cpu.user"))
+	series := []*ts.Series{
+		ts.NewSeries([]byte("sys.cpu.user"), values, tags),
+		ts.NewSeries([]byte("empty"), ts.NewFixedStepValues(time.Minute, 3, math.NaN(), start),
+			models.EmptyTags()),
+	}
+
+	tests := []struct {
+		fill     fillPolicy
+		expected string
+	}{
+		{fill: fillNone, expected: `{"1556000000": 1.5, "1556000120": 3}`},
+		{fill: fillNull, expected: `{"1556000000": 1.5, "1556000060": null, "1556000120": 3}`},
+		{fill: fillZero, expected: `{"1556000000": 1.5, "1556000060": 0, "1556000120": 3}`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		err := renderResultsJSON(&buf, []queryResult{{
+			query: compiledQuery{
+				metric:        "sys.cpu.user",
+				aggregateTags: []string{"dc"},
+				offset:        time.Minute,
+				fill:          tt.fill,
+			},
+			series: series,
+		}}, models.NewTagOptions())
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `[{
+			"metric": "sys.cpu.user",
+			"tags": {"host": "web01"},
+			"aggregateTags": ["dc"],
+			"dps": `+tt.expected+`
+		}]`, buf.String())
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+// rateType calculates the per second rate of change of each series between
+// consecutive values, after they have been downsampled.
+const rateType = "opentsdb_rate"
+
+// defaultCounterMax is the value counters roll over at unless specified,
+// which is the maximum value of the signed 64 bit integers OpenTSDB uses.
+const defaultCounterMax = float64(math.MaxInt64)
+
+// rateOp calculates rates the way OpenTSDB does: between each value and the
+// previous value of the series, treating decreases of counters as resets.
+type rateOp struct {
+	counter    bool
+	counterMax float64
+	// resetValue is the rate above which a rate across a counter reset is
+	// replaced with zero, it is disabled when zero.
+	resetValue float64
+	dropResets bool
+}
+
+// OpType for the operator
+func (o rateOp) OpType() string {
+	return rateType
+}
+
+// String representation
+func (o rateOp) String() string {
+	return fmt.Sprintf("type: %s, counter: %v", o.OpType(), o.counter)
+}
+
+// Node creates an execution node
+func (o rateOp) Node(controller *transform.Controller, _ transform.Options) transform.OpNode {
+	return &rateNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+// rate returns the rate between two values the given number of seconds
+// apart, and false if the value should be dropped.
+func (o rateOp) rate(prev, curr, seconds float64) (float64, bool) {
+	delta := curr - prev
+	if !o.counter || delta >= 0 {
+		return delta / seconds, true
+	}
+
+	if o.dropResets {
+		return 0, false
+	}
+
+	rate := (o.counterMax - prev + curr) / seconds
+	if o.resetValue > 0 && rate > o.resetValue {
+		return 0, true
+	}
+
+	return rate, true
+}
+
+type rateNode struct {
+	op         rateOp
+	controller *transform.Controller
+}
+
+func (n *rateNode) Params() parser.Params {
+	return n.op
+}
+
+// Process the block
+func (n *rateNode) Process(queryCtx *models.QueryContext, ID parser.NodeID, b block.Block) error {
+	return transform.ProcessSimpleBlock(n, n.controller, queryCtx, ID, b)
+}
+
+// ProcessBlock replaces each value of each series with the rate since the
+// previous value of the series, skipping steps without values.
+func (n *rateNode) ProcessBlock(queryCtx *models.QueryContext, ID parser.NodeID, b block.Block) (block.Block, error) {
+	seriesIter, err := b.SeriesIter()
+	if err != nil {
+		return nil, err
+	}
+
+	defer seriesIter.Close()
+
+	var (
+		meta     = seriesIter.Meta()
+		numSteps = meta.Bounds.Steps()
+		step     = meta.Bounds.StepSize.Seconds()
+		columns  = make([][]float64, numSteps)
+	)
+	for i := range columns {
+		columns[i] = make([]float64, 0, seriesIter.SeriesCount())
+	}
+
+	for seriesIter.Next() {
+		prevIdx := -1
+		values := seriesIter.Current().Values()
+		for i := 0; i < numSteps; i++ {
+			value := math.NaN()
+			if i < len(values) && !math.IsNaN(values[i]) {
+				if prevIdx >= 0 {
+					seconds := float64(i-prevIdx) * step
+					if rate, ok := n.op.rate(values[prevIdx], values[i], seconds); ok {
+						value = rate
+					}
+				}
+
+				prevIdx = i
+			}
+
+			columns[i] = append(columns[i], value)
+		}
+	}
+
+	if err := seriesIter.Err(); err != nil {
+		return nil, err
+	}
+
+	builder, err := n.controller.BlockBuilder(queryCtx, meta, seriesIter.SeriesMeta())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := builder.AddCols(numSteps); err != nil {
+		return nil, err
+	}
+
+	for i, column := range columns {
+		if err := builder.AppendValues(i, column); err != nil {
+			return nil, err
+		}
+	}
+
+	return builder.Build(), nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func processRateOp(t *testing.T, opts rateOptions, values [][]float64) [][]float64 {
+	op, err := newRateOp(opts)
+	require.NoError(t, err)
+
+	bounds := models.Bounds{
+		Start:    time.Now(),
+		Duration: time.Duration(len(values[0])) * 10 * time.Second,
+		StepSize: 10 * time.Second,
+	}
+
+	c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+	node := op.(rateOp).Node(c, transform.Options{})
+	err = node.Process(models.NoopQueryContext(), parser.NodeID(0),
+		test.NewBlockFromValues(bounds, values))
+	require.NoError(t, err)
+	return sink.Values
+}
+
+func TestRateBetweenConsecutiveValues(t *testing.T) {
+	nan := math.NaN()
+	actual := processRateOp(t, rateOptions{}, [][]float64{
+		{10, 20, nan, 60, 30},
+	})
+
+	// The rate after a missing value is calculated since the last value and
+	// decreases are negative rates unless the series is a counter.
+	test.EqualsWithNans(t, []float64{nan, 1, nan, 2, -3}, actual[0])
+}
+
+func TestRateCounterResets(t *testing.T) {
+	nan := math.NaN()
+	values := [][]float64{{10, 20, 5, 15}}
+
+	actual := processRateOp(t, rateOptions{Counter: true, CounterMax: "100"}, values)
+	test.EqualsWithNans(t, []float64{nan, 1, 8.5, 1}, actual[0])
+
+	actual = processRateOp(t, rateOptions{
+		Counter:    true,
+		CounterMax: "100",
+		ResetValue: "5",
+	}, values)
+	test.EqualsWithNans(t, []float64{nan, 1, 0, 1}, actual[0])
+
+	actual = processRateOp(t, rateOptions{Counter: true, DropResets: true}, values)
+	test.EqualsWithNans(t, []float64{nan, 1, nan, 1}, actual[0])
+}
+
+func TestNewRateOpInvalidOptions(t *testing.T) {
+	_, err := newRateOp(rateOptions{Counter: true, CounterMax: "-1"})
+	assert.Error(t, err)
+
+	_, err = newRateOp(rateOptions{Counter: true, ResetValue: "abc"})
+	assert.Error(t, err)
+}
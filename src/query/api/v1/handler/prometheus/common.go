@@ -38,13 +38,14 @@ import (
 
 	"github.com/golang/snappy"
 	"github.com/gorilla/mux"
-	"github.com/prometheus/prometheus/promql"
+	promql "github.com/prometheus/prometheus/promql/parser"
 )
 
 const (
 	// NameReplace is the parameter that gets replaced.
 	NameReplace         = "name"
 	queryParam          = "query"
+	matchParam          = "match[]"
 	filterNameTagsParam = "tag"
 	errFormatStr        = "error parsing param: %s, error: %v"
 
@@ -191,7 +192,7 @@ func ParseSeriesMatchQuery(
 	tagOptions models.TagOptions,
 ) ([]*storage.FetchQuery, *xhttp.ParseError) {
 	r.ParseForm()
-	matcherValues := r.Form["match[]"]
+	matcherValues := r.Form[matchParam]
 	if len(matcherValues) == 0 {
 		return nil, xhttp.NewParseError(errors.ErrInvalidMatchers, http.StatusBadRequest)
 	}
@@ -229,28 +230,130 @@ func ParseSeriesMatchQuery(
 	return queries, nil
 }
 
-// ParseTagValuesToQuery parses a tag values request to a complete tags query.
-func ParseTagValuesToQuery(
+// ParseLabelNamesToQueries parses a label names request to complete tags
+// queries, one per match[] selector, or a single query matching all series
+// if no selectors are given.
+func ParseLabelNamesToQueries(
 	r *http.Request,
-) (*storage.CompleteTagsQuery, error) {
+	tagOptions models.TagOptions,
+) ([]*storage.CompleteTagsQuery, *xhttp.ParseError) {
+	selectors, start, end, err := parseSelectorsAndRange(r, tagOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]*storage.CompleteTagsQuery, 0, len(selectors))
+	for _, matchers := range selectors {
+		queries = append(queries, &storage.CompleteTagsQuery{
+			CompleteNameOnly: true,
+			TagMatchers:      matchers,
+			Start:            start,
+			End:              end,
+		})
+	}
+
+	return queries, nil
+}
+
+// ParseTagValuesToQueries parses a tag values request to complete tags
+// queries, one per match[] selector, or a single query matching all series
+// with the tag if no selectors are given.
+func ParseTagValuesToQueries(
+	r *http.Request,
+	tagOptions models.TagOptions,
+) ([]*storage.CompleteTagsQuery, *xhttp.ParseError) {
 	vars := mux.Vars(r)
 	name, ok := vars[NameReplace]
 	if !ok || len(name) == 0 {
-		return nil, errors.ErrNoName
+		return nil, xhttp.NewParseError(errors.ErrNoName, http.StatusBadRequest)
+	}
+
+	selectors, start, end, err := parseSelectorsAndRange(r, tagOptions)
+	if err != nil {
+		return nil, err
 	}
 
 	nameBytes := []byte(name)
-	return &storage.CompleteTagsQuery{
-		CompleteNameOnly: false,
-		FilterNameTags:   [][]byte{nameBytes},
-		TagMatchers: models.Matchers{
-			models.Matcher{
-				Type:  models.MatchRegexp,
-				Name:  nameBytes,
-				Value: matchValues,
-			},
-		},
-	}, nil
+	queries := make([]*storage.CompleteTagsQuery, 0, len(selectors))
+	for _, matchers := range selectors {
+		tagMatchers := make(models.Matchers, 0, len(matchers)+1)
+		tagMatchers = append(tagMatchers, matchers...)
+		tagMatchers = append(tagMatchers, models.Matcher{
+			Type:  models.MatchRegexp,
+			Name:  nameBytes,
+			Value: matchValues,
+		})
+
+		queries = append(queries, &storage.CompleteTagsQuery{
+			CompleteNameOnly: false,
+			FilterNameTags:   [][]byte{nameBytes},
+			TagMatchers:      tagMatchers,
+			Start:            start,
+			End:              end,
+		})
+	}
+
+	return queries, nil
+}
+
+// parseSelectorsAndRange parses the optional match[] selectors along with
+// the start and end of a request. If no selectors are given a single empty
+// set of matchers is returned, which matches all series.
+func parseSelectorsAndRange(
+	r *http.Request,
+	tagOptions models.TagOptions,
+) ([]models.Matchers, time.Time, time.Time, *xhttp.ParseError) {
+	start, err := parseTimeWithDefault(r, "start", time.Time{})
+	if err != nil {
+		return nil, time.Time{}, time.Time{},
+			xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	end, err := parseTimeWithDefault(r, "end", time.Now())
+	if err != nil {
+		return nil, time.Time{}, time.Time{},
+			xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	selectors, parseErr := ParseMatchSelectors(r, tagOptions)
+	if parseErr != nil {
+		return nil, time.Time{}, time.Time{}, parseErr
+	}
+
+	if len(selectors) == 0 {
+		return []models.Matchers{nil}, start, end, nil
+	}
+
+	return selectors, start, end, nil
+}
+
+// ParseMatchSelectors parses the match[] series selectors of a request,
+// returning no matchers if no selectors are given.
+func ParseMatchSelectors(
+	r *http.Request,
+	tagOptions models.TagOptions,
+) ([]models.Matchers, *xhttp.ParseError) {
+	if err := r.ParseForm(); err != nil {
+		return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	matcherValues := r.Form[matchParam]
+	selectors := make([]models.Matchers, 0, len(matcherValues))
+	for _, s := range matcherValues {
+		promMatchers, err := promql.ParseMetricSelector(s)
+		if err != nil {
+			return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+		}
+
+		matchers, err := xpromql.LabelMatchersToModelMatcher(promMatchers, tagOptions)
+		if err != nil {
+			return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+		}
+
+		selectors = append(selectors, matchers)
+	}
+
+	return selectors, nil
 }
 
 func renderNameOnlyTagCompletionResultsJSON(
@@ -362,6 +465,35 @@ func RenderTagValuesResultsJSON(
 	return jw.Close()
 }
 
+// RenderLabelNamesResultsJSON renders label names results to json format.
+func RenderLabelNamesResultsJSON(
+	w io.Writer,
+	result *storage.CompleteTagsResult,
+) error {
+	if !result.CompleteNameOnly {
+		return errors.ErrWithNames
+	}
+
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+
+	jw.BeginObjectField("status")
+	jw.WriteString("success")
+
+	jw.BeginObjectField("data")
+	jw.BeginArray()
+
+	for _, tag := range result.CompletedTags {
+		jw.WriteString(string(tag.Name))
+	}
+
+	jw.EndArray()
+
+	jw.EndObject()
+
+	return jw.Close()
+}
+
 // RenderSeriesMatchResultsJSON renders series match results to json format.
 func RenderSeriesMatchResultsJSON(
 	w io.Writer,
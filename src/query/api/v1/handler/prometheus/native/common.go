@@ -181,6 +181,22 @@ func parseBlockType(r *http.Request) models.FetchedBlockType {
 
 // parseInstantaneousParams parses all params from the GET request
 func parseInstantaneousParams(r *http.Request, timeoutOpts *prometheus.TimeoutOpts) (models.RequestParams, *xhttp.ParseError) {
+	params, rErr := parseInstantParams(r, timeoutOpts)
+	if rErr != nil {
+		return params, rErr
+	}
+
+	query, err := parseQuery(r)
+	if err != nil {
+		return params, xhttp.NewParseError(fmt.Errorf(formatErrStr, queryParam, err), http.StatusBadRequest)
+	}
+	params.Query = query
+	return params, nil
+}
+
+// parseInstantParams parses the instant and timeout of a request evaluated
+// at a single point in time, which defaults to now.
+func parseInstantParams(r *http.Request, timeoutOpts *prometheus.TimeoutOpts) (models.RequestParams, *xhttp.ParseError) {
 	params := models.RequestParams{
 		Now:        time.Now(),
 		Step:       time.Second,
@@ -203,12 +219,6 @@ func parseInstantaneousParams(r *http.Request, timeoutOpts *prometheus.TimeoutOp
 
 	params.Start = instant
 	params.End = instant
-
-	query, err := parseQuery(r)
-	if err != nil {
-		return params, xhttp.NewParseError(fmt.Errorf(formatErrStr, queryParam, err), http.StatusBadRequest)
-	}
-	params.Query = query
 	params.Debug = parseDebugFlag(r)
 	params.BlockType = parseBlockType(r)
 	return params, nil
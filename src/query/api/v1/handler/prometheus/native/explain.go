@@ -0,0 +1,84 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/m3db/m3/src/query/stats"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"go.uber.org/zap"
+)
+
+const (
+	explainParam = "explain"
+
+	explainResultType = "explain"
+)
+
+type explainResponse struct {
+	Status string      `json:"status"`
+	Data   explainData `json:"data"`
+}
+
+type explainData struct {
+	ResultType   string       `json:"resultType"`
+	ResultSeries int          `json:"resultSeries"`
+	Explain      stats.Report `json:"explain"`
+}
+
+func parseExplainFlag(r *http.Request) bool {
+	var (
+		explain bool
+		err     error
+	)
+
+	// Skip explain if unable to parse explain param
+	explainVal := r.FormValue(explainParam)
+	if explainVal != "" {
+		explain, err = strconv.ParseBool(explainVal)
+		if err != nil {
+			logging.WithContext(r.Context()).Warn("unable to parse explain flag", zap.Error(err))
+		}
+	}
+
+	return explain
+}
+
+func renderExplainJSON(
+	w io.Writer,
+	series []*ts.Series,
+	report stats.Report,
+) error {
+	return json.NewEncoder(w).Encode(explainResponse{
+		Status: "success",
+		Data: explainData{
+			ResultType:   explainResultType,
+			ResultSeries: len(series),
+			Explain:      report,
+		},
+	})
+}
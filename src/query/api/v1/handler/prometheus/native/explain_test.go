@@ -0,0 +1,78 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromReadHandlerExplain(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	values, bounds := test.GenerateValuesAndBounds(nil, nil)
+
+	setup := newTestSetup()
+	b := test.NewBlockFromValues(bounds, values)
+	setup.Storage.SetFetchBlocksResult(block.Result{Blocks: []block.Block{b}}, nil)
+
+	params := defaultParams()
+	params.Add(explainParam, "true")
+
+	recorder := httptest.NewRecorder()
+	setup.Handler.ServeHTTP(recorder, newReadRequest(t, params))
+	require.Equal(t, 200, recorder.Code, recorder.Body.String())
+
+	var resp explainResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+
+	assert.Equal(t, "success", resp.Status)
+	assert.Equal(t, explainResultType, resp.Data.ResultType)
+	assert.Equal(t, 2, resp.Data.ResultSeries)
+
+	explain := resp.Data.Explain
+	assert.Equal(t, promQuery, explain.Query)
+	assert.NotEmpty(t, explain.LogicalPlan)
+	assert.NotEmpty(t, explain.PhysicalPlan)
+	require.Len(t, explain.Nodes, 1)
+	assert.Equal(t, functions.FetchType, explain.Nodes[0].Type)
+	assert.Equal(t, 1, explain.Nodes[0].Calls)
+}
+
+func TestParseExplainFlag(t *testing.T) {
+	params := defaultParams()
+	assert.False(t, parseExplainFlag(newReadRequest(t, params)))
+
+	params.Set(explainParam, "true")
+	assert.True(t, parseExplainFlag(newReadRequest(t, params)))
+
+	params.Set(explainParam, "invalid")
+	assert.False(t, parseExplainFlag(newReadRequest(t, params)))
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/errors"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+)
+
+const (
+	// FederateURL is the url for the federation handler, this matches the
+	// federation URL found on a Prometheus server.
+	FederateURL = "/federate"
+
+	// FederateHTTPMethod is the HTTP method used with this resource.
+	FederateHTTPMethod = http.MethodGet
+)
+
+// FederateHandler serves the latest sample of every series matching the
+// requested selectors in the Prometheus exposition format.
+type FederateHandler struct {
+	engine           *executor.Engine
+	tagOpts          models.TagOptions
+	lookbackDuration time.Duration
+	timeoutOpts      *prometheus.TimeoutOpts
+}
+
+// NewFederateHandler returns a new instance of handler.
+func NewFederateHandler(
+	engine *executor.Engine,
+	tagOpts models.TagOptions,
+	lookbackDuration time.Duration,
+	timeoutOpts *prometheus.TimeoutOpts,
+) *FederateHandler {
+	return &FederateHandler{
+		engine:           engine,
+		tagOpts:          tagOpts,
+		lookbackDuration: lookbackDuration,
+		timeoutOpts:      timeoutOpts,
+	}
+}
+
+func (h *FederateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+
+	selectors, rErr := prometheus.ParseMatchSelectors(r, h.tagOpts)
+	if rErr != nil {
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	if len(selectors) == 0 {
+		xhttp.Error(w, errors.ErrInvalidMatchers, http.StatusBadRequest)
+		return
+	}
+
+	params, rErr := parseInstantParams(r, h.timeoutOpts)
+	if rErr != nil {
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, params.Timeout)
+	defer cancel()
+
+	families, err := h.federate(ctx, selectors, params.Start)
+	if err != nil {
+		logger.Error("unable to fetch federated series", zap.Error(err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	format := expfmt.Negotiate(r.Header)
+	w.Header().Set("Content-Type", string(format))
+	encoder := expfmt.NewEncoder(w, format)
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			logger.Error("unable to encode federated series", zap.Error(err))
+			return
+		}
+	}
+}
+
+// federate fetches every selector over the lookback window ending at now and
+// returns the latest sample of each distinct series, grouped by metric name.
+func (h *FederateHandler) federate(
+	ctx context.Context,
+	selectors []models.Matchers,
+	now time.Time,
+) ([]*dto.MetricFamily, error) {
+	var (
+		seen     = make(map[string]struct{})
+		families = make(map[string]*dto.MetricFamily)
+	)
+
+	for _, matchers := range selectors {
+		result, err := h.fetch(ctx, &storage.FetchQuery{
+			TagMatchers: matchers,
+			Start:       now.Add(-h.lookbackDuration),
+			End:         now,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, series := range result.SeriesList {
+			id := string(series.Name())
+			if _, ok := seen[id]; ok {
+				continue
+			}
+
+			seen[id] = struct{}{}
+			dp, ok := latestDatapoint(series.Values(), now)
+			if !ok {
+				continue
+			}
+
+			name, metric := federatedMetric(series.Tags, h.tagOpts.MetricName(), dp)
+			if name == "" {
+				// Series without a metric name can not be exposed.
+				continue
+			}
+
+			family, ok := families[name]
+			if !ok {
+				family = &dto.MetricFamily{
+					Name: proto.String(name),
+					Type: dto.MetricType_UNTYPED.Enum(),
+				}
+				families[name] = family
+			}
+
+			family.Metric = append(family.Metric, metric)
+		}
+	}
+
+	sorted := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		sort.Sort(byLabels(family.Metric))
+		sorted = append(sorted, family)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+
+	return sorted, nil
+}
+
+// fetch fetches the series matching the query through the engine, so that
+// the fetch is subject to the same limits as instant queries.
+func (h *FederateHandler) fetch(
+	ctx context.Context,
+	query *storage.FetchQuery,
+) (*storage.FetchResult, error) {
+	// Results is closed by execute
+	results := make(chan *storage.QueryResult)
+	go h.engine.Execute(ctx, query, &executor.EngineOptions{}, results)
+
+	var (
+		fetchResult *storage.FetchResult
+		err         error
+	)
+	for result := range results {
+		if result.Err != nil {
+			err = result.Err
+			continue
+		}
+
+		fetchResult = result.FetchResult
+	}
+
+	return fetchResult, err
+}
+
+// latestDatapoint returns the last datapoint at or before now.
+func latestDatapoint(values ts.Values, now time.Time) (ts.Datapoint, bool) {
+	for i := values.Len() - 1; i >= 0; i-- {
+		dp := values.DatapointAt(i)
+		if !dp.Timestamp.After(now) {
+			return dp, true
+		}
+	}
+
+	return ts.Datapoint{}, false
+}
+
+func federatedMetric(
+	tags models.Tags,
+	metricName []byte,
+	dp ts.Datapoint,
+) (string, *dto.Metric) {
+	var (
+		name   string
+		labels = storage.TagsToPromLabels(tags)
+		pairs  = make([]*dto.LabelPair, 0, len(labels))
+	)
+
+	for _, l := range labels {
+		if bytes.Equal(l.Name, metricName) {
+			name = string(l.Value)
+			continue
+		}
+
+		pairs = append(pairs, &dto.LabelPair{
+			Name:  proto.String(string(l.Name)),
+			Value: proto.String(string(l.Value)),
+		})
+	}
+
+	return name, &dto.Metric{
+		Label: pairs,
+		Untyped: &dto.Untyped{
+			Value: proto.Float64(dp.Value),
+		},
+		TimestampMs: proto.Int64(storage.TimeToTimestamp(dp.Timestamp)),
+	}
+}
+
+// byLabels orders metrics by their (sorted) label pairs.
+type byLabels []*dto.Metric
+
+func (m byLabels) Len() int      { return len(m) }
+func (m byLabels) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m byLabels) Less(i, j int) bool {
+	a, b := m[i].Label, m[j].Label
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k].GetName() != b[k].GetName() {
+			return a[k].GetName() < b[k].GetName()
+		}
+
+		if a[k].GetValue() != b[k].GetValue() {
+			return a[k].GetValue() < b[k].GetValue()
+		}
+	}
+
+	return len(a) < len(b)
+}
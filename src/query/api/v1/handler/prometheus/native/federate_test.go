@@ -0,0 +1,162 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestFederateHandler(
+	store storage.Storage,
+	tagOpts models.TagOptions,
+) *FederateHandler {
+	engine := executor.NewEngine(store, tally.NewTestScope("test", nil),
+		time.Minute, nil)
+	return NewFederateHandler(engine, tagOpts, 5*time.Minute, timeoutOpts)
+}
+
+func federateSeries(
+	id string,
+	tags map[string]string,
+	dps ...ts.Datapoint,
+) *ts.Series {
+	modelTags := models.NewTags(len(tags), models.NewTagOptions())
+	for name, value := range tags {
+		modelTags = modelTags.AddTag(models.Tag{
+			Name:  []byte(name),
+			Value: []byte(value),
+		})
+	}
+
+	return ts.NewSeries([]byte(id), ts.Datapoints(dps), modelTags)
+}
+
+func TestFederateHandler(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	var (
+		now   = time.Unix(1000, 0)
+		store = mock.NewMockStorage()
+	)
+
+	store.SetFetchResult(&storage.FetchResult{
+		SeriesList: ts.SeriesList{
+			federateSeries("b", map[string]string{"__name__": "up", "job": "b"},
+				ts.Datapoint{Timestamp: now.Add(-30 * time.Second), Value: 1},
+				ts.Datapoint{Timestamp: now.Add(-10 * time.Second), Value: 2}),
+			federateSeries("a", map[string]string{"__name__": "up", "job": "a"},
+				ts.Datapoint{Timestamp: now.Add(-5 * time.Second), Value: 3}),
+			// Only has datapoints after the federation time.
+			federateSeries("c", map[string]string{"__name__": "other"},
+				ts.Datapoint{Timestamp: now.Add(10 * time.Second), Value: 4}),
+			// Has no metric name.
+			federateSeries("d", map[string]string{"job": "x"},
+				ts.Datapoint{Timestamp: now.Add(-time.Second), Value: 5}),
+		},
+	}, nil)
+
+	handler := newTestFederateHandler(store, models.NewTagOptions())
+
+	// Both selectors are served the same series, which must only be
+	// exposed once.
+	params := url.Values{
+		"match[]": []string{"up", `{job=~".+"}`},
+		"time":    []string{"1000"},
+	}
+	req := httptest.NewRequest(FederateHTTPMethod,
+		FederateURL+"?"+params.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/plain")
+
+	body, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	expected := "# TYPE up untyped\n" +
+		"up{job=\"a\"} 3 995000\n" +
+		"up{job=\"b\"} 2 990000\n"
+	assert.Equal(t, expected, string(body))
+}
+
+func TestFederateHandlerRequiresMatchers(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	handler := newTestFederateHandler(mock.NewMockStorage(),
+		models.NewTagOptions())
+
+	req := httptest.NewRequest(FederateHTTPMethod, FederateURL, nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestFederateHandlerCustomMetricName(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	var (
+		now     = time.Unix(1000, 0)
+		store   = mock.NewMockStorage()
+		tagOpts = models.NewTagOptions().SetMetricName([]byte("name"))
+	)
+
+	store.SetFetchResult(&storage.FetchResult{
+		SeriesList: ts.SeriesList{
+			federateSeries("a", map[string]string{"name": "up", "job": "a"},
+				ts.Datapoint{Timestamp: now.Add(-5 * time.Second), Value: 3}),
+		},
+	}, nil)
+
+	params := url.Values{
+		"match[]": []string{"up"},
+		"time":    []string{"1000"},
+	}
+	req := httptest.NewRequest(FederateHTTPMethod,
+		FederateURL+"?"+params.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	newTestFederateHandler(store, tagOpts).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	expected := "# TYPE up untyped\n" +
+		"up{job=\"a\"} 3 995000\n"
+	assert.Equal(t, expected, string(body))
+}
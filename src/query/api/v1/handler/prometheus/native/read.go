@@ -29,8 +29,10 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/stats"
 	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/util/httperrors"
 	"github.com/m3db/m3/src/query/util/logging"
@@ -67,6 +69,7 @@ type PromReadHandler struct {
 	promReadMetrics promReadMetrics
 	timeoutOps      *prometheus.TimeoutOpts
 	keepNans        bool
+	resultsCache    *cache.ResultsCache
 }
 
 type promReadMetrics struct {
@@ -111,6 +114,7 @@ func NewPromReadHandler(
 	scope tally.Scope,
 	timeoutOpts *prometheus.TimeoutOpts,
 	keepNans bool,
+	resultsCache *cache.ResultsCache,
 ) *PromReadHandler {
 	h := &PromReadHandler{
 		engine:          engine,
@@ -119,6 +123,7 @@ func NewPromReadHandler(
 		promReadMetrics: newPromReadMetrics(scope),
 		timeoutOps:      timeoutOpts,
 		keepNans:        keepNans,
+		resultsCache:    resultsCache,
 	}
 
 	h.promReadMetrics.maxDatapoints.Update(float64(limitsCfg.MaxComputedDatapoints()))
@@ -128,6 +133,12 @@ func NewPromReadHandler(
 func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	timer := h.promReadMetrics.fetchTimerSuccess.Start()
 
+	var queryStats *stats.QueryStats
+	if parseExplainFlag(r) {
+		queryStats = stats.NewQueryStats(r.FormValue(queryParam))
+		r = r.WithContext(stats.NewContext(r.Context(), queryStats))
+	}
+
 	result, params, respErr := h.ServeHTTPWithEngine(w, r, h.engine)
 	if respErr != nil {
 		httperrors.ErrorWithReqInfo(w, r, respErr.Code, respErr.Err)
@@ -135,6 +146,17 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if queryStats != nil {
+		queryStats.Finish()
+		if err := renderExplainJSON(w, result, queryStats.Report()); err != nil {
+			logging.WithContext(r.Context()).Error("unable to render explain", zap.Error(err))
+		}
+
+		h.promReadMetrics.fetchSuccess.Inc(1)
+		timer.Stop()
+		return
+	}
+
 	if params.FormatType == models.FormatM3QL {
 		renderM3QLResultsJSON(w, result, params)
 		h.promReadMetrics.fetchSuccess.Inc(1)
@@ -171,7 +193,21 @@ func (h *PromReadHandler) ServeHTTPWithEngine(
 		return nil, emptyReqParams, &RespError{Err: err, Code: http.StatusBadRequest}
 	}
 
-	result, err := read(ctx, engine, h.tagOpts, w, params)
+	var (
+		result []*ts.Series
+		err    error
+	)
+	// NB: explained queries bypass the results cache so that the reported
+	// plan and costs reflect a full execution of the query.
+	if h.resultsCache != nil && engine == h.engine && stats.FromContext(ctx) == nil {
+		result, err = h.resultsCache.Read(ctx, params,
+			func(ctx context.Context, params models.RequestParams) ([]*ts.Series, error) {
+				return read(ctx, engine, h.tagOpts, w, params)
+			})
+	} else {
+		result, err = read(ctx, engine, h.tagOpts, w, params)
+	}
+
 	if err != nil {
 		sp := opentracingutil.SpanFromContextOrNoop(ctx)
 		sp.LogFields(opentracinglog.Error(err))
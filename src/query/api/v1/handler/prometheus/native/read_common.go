@@ -31,6 +31,7 @@ import (
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/parser/promql"
 	"github.com/m3db/m3/src/query/ts"
 	opentracingutil "github.com/m3db/m3/src/query/util/opentracing"
@@ -44,6 +45,25 @@ func read(
 	tagOpts models.TagOptions,
 	w http.ResponseWriter,
 	params models.RequestParams,
+) ([]*ts.Series, error) {
+	// TODO: Capture timing
+	parser, err := promql.Parse(params.Query, tagOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReadParsed(reqCtx, engine, parser, w, params)
+}
+
+// ReadParsed executes a parsed query against the engine and returns the
+// resulting series, this allows query languages other than PromQL that
+// compile to the same DAG to share the read path.
+func ReadParsed(
+	reqCtx context.Context,
+	engine *executor.Engine,
+	parser parser.Parser,
+	w http.ResponseWriter,
+	params models.RequestParams,
 ) ([]*ts.Series, error) {
 	ctx, cancel := context.WithTimeout(reqCtx, params.Timeout)
 	defer cancel()
@@ -61,19 +81,16 @@ func read(
 	// Detect clients closing connections
 	handler.CloseWatcher(ctx, cancel, w)
 
-	// TODO: Capture timing
-	parser, err := promql.Parse(params.Query, tagOpts)
-	if err != nil {
-		return nil, err
-	}
-
 	// Results is closed by execute
 	results := make(chan executor.Query)
 	go engine.ExecuteExpr(ctx, parser, opts, params, results)
 	// Block slices are sorted by start time
 	// TODO: Pooling
 	sortedBlockList := make([]blockWithMeta, 0, initialBlockAlloc)
-	var processErr error
+	var (
+		processErr error
+		err        error
+	)
 	for result := range results {
 		if result.Err != nil {
 			processErr = result.Err
@@ -0,0 +1,160 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelNamesHandler(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var queries []*storage.CompleteTagsQuery
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			query *storage.CompleteTagsQuery,
+			_ *storage.FetchOptions,
+		) (*storage.CompleteTagsResult, error) {
+			queries = append(queries, query)
+			names := []storage.CompletedTag{{Name: []byte("__name__")}}
+			if len(queries) == 1 {
+				names = append(names, storage.CompletedTag{Name: []byte("job")})
+			} else {
+				names = append(names, storage.CompletedTag{Name: []byte("instance")})
+			}
+
+			return &storage.CompleteTagsResult{
+				CompleteNameOnly: true,
+				CompletedTags:    names,
+			}, nil
+		}).Times(2)
+
+	params := url.Values{}
+	params.Add("match[]", `up{job="a"}`)
+	params.Add("match[]", "down")
+	params.Add("start", "100")
+	params.Add("end", "200")
+	req := httptest.NewRequest(http.MethodGet, LabelNamesURL+"?"+params.Encode(), nil)
+
+	recorder := httptest.NewRecorder()
+	NewLabelNamesHandler(store, models.NewTagOptions()).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	assert.JSONEq(t, `{"status":"success","data":["__name__","instance","job"]}`,
+		recorder.Body.String())
+
+	require.Len(t, queries, 2)
+	for _, query := range queries {
+		assert.True(t, query.CompleteNameOnly)
+		assert.Equal(t, int64(100), query.Start.Unix())
+		assert.Equal(t, int64(200), query.End.Unix())
+	}
+
+	assert.Len(t, queries[0].TagMatchers, 2)
+	assert.Len(t, queries[1].TagMatchers, 1)
+}
+
+func TestLabelNamesHandlerNoMatchers(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			query *storage.CompleteTagsQuery,
+			_ *storage.FetchOptions,
+		) (*storage.CompleteTagsResult, error) {
+			assert.Len(t, query.TagMatchers, 0)
+			return &storage.CompleteTagsResult{CompleteNameOnly: true}, nil
+		})
+
+	req := httptest.NewRequest(http.MethodPost, LabelNamesURL, nil)
+	recorder := httptest.NewRecorder()
+	NewLabelNamesHandler(store, models.NewTagOptions()).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":[]}`, recorder.Body.String())
+}
+
+func TestTagValuesHandlerWithMatchers(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			query *storage.CompleteTagsQuery,
+			_ *storage.FetchOptions,
+		) (*storage.CompleteTagsResult, error) {
+			assert.Equal(t, [][]byte{[]byte("job")}, query.FilterNameTags)
+			require.Len(t, query.TagMatchers, 2)
+			assert.Equal(t, "__name__", string(query.TagMatchers[0].Name))
+			assert.Equal(t, "job", string(query.TagMatchers[1].Name))
+
+			return &storage.CompleteTagsResult{
+				CompletedTags: []storage.CompletedTag{{
+					Name:   []byte("job"),
+					Values: [][]byte{[]byte("a"), []byte("b")},
+				}},
+			}, nil
+		})
+
+	router := mux.NewRouter()
+	router.Handle(TagValuesURL, NewTagValuesHandler(store, models.NewTagOptions()))
+
+	params := url.Values{}
+	params.Add("match[]", "up")
+	target := "/api/v1/label/job/values?" + params.Encode()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":["a","b"]}`, recorder.Body.String())
+}
+
+func TestParseTagValuesToQueriesNoName(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/label//values", nil)
+	_, err := prometheus.ParseTagValuesToQueries(req, models.NewTagOptions())
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Code())
+}
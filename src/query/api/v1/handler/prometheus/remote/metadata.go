@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/json"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// MetadataURL is the url for metric metadata.
+	MetadataURL = handler.RoutePrefixV1 + "/metadata"
+
+	// MetadataHTTPMethod is the HTTP method used with this resource.
+	MetadataHTTPMethod = http.MethodGet
+
+	metadataMetricParam = "metric"
+	metadataLimitParam  = "limit"
+
+	// NB: metric types, help and units are not stored alongside series, so
+	// every metric is reported with an unknown type, matching how Prometheus
+	// reports metrics exposed without metadata.
+	unknownMetricType = "unknown"
+)
+
+var (
+	matchAllValues = []byte(".*")
+)
+
+// MetadataHandler represents a handler for the metric metadata endpoint.
+type MetadataHandler struct {
+	storage    storage.Storage
+	tagOptions models.TagOptions
+}
+
+// NewMetadataHandler returns a new instance of handler.
+func NewMetadataHandler(
+	storage storage.Storage,
+	tagOptions models.TagOptions,
+) http.Handler {
+	return &MetadataHandler{
+		storage:    storage,
+		tagOptions: tagOptions,
+	}
+}
+
+func (h *MetadataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+	w.Header().Set("Content-Type", "application/json")
+
+	limit, err := parseMetadataLimit(r)
+	if err != nil {
+		logger.Error("unable to parse metadata limit", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	metricName := h.tagOptions.MetricName()
+	matcher := models.Matcher{
+		Type:  models.MatchRegexp,
+		Name:  metricName,
+		Value: matchAllValues,
+	}
+	if metric := r.FormValue(metadataMetricParam); metric != "" {
+		matcher.Type = models.MatchEqual
+		matcher.Value = []byte(metric)
+	}
+
+	query := &storage.CompleteTagsQuery{
+		CompleteNameOnly: false,
+		FilterNameTags:   [][]byte{metricName},
+		TagMatchers:      models.Matchers{matcher},
+		End:              time.Now(),
+	}
+
+	result, err := completeTags(ctx, h.storage, []*storage.CompleteTagsQuery{query}, false)
+	if err != nil {
+		logger.Error("unable to get metric names", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := renderMetadataResultsJSON(w, result, limit); err != nil {
+		logger.Error("unable to render metadata", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+	}
+}
+
+// parseMetadataLimit parses the maximum number of metrics to return, with
+// zero or negative values implying no limit.
+func parseMetadataLimit(r *http.Request) (int, error) {
+	limit := r.FormValue(metadataLimitParam)
+	if limit == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(limit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid '%s': %v", metadataLimitParam, err)
+	}
+
+	return n, nil
+}
+
+func renderMetadataResultsJSON(
+	w io.Writer,
+	result *storage.CompleteTagsResult,
+	limit int,
+) error {
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+
+	jw.BeginObjectField("status")
+	jw.WriteString("success")
+
+	jw.BeginObjectField("data")
+	jw.BeginObject()
+
+	rendered := 0
+	for _, tag := range result.CompletedTags {
+		for _, value := range tag.Values {
+			if limit > 0 && rendered >= limit {
+				break
+			}
+
+			jw.BeginObjectField(string(value))
+			jw.BeginArray()
+			jw.BeginObject()
+			jw.BeginObjectField("type")
+			jw.WriteString(unknownMetricType)
+			jw.BeginObjectField("help")
+			jw.WriteString("")
+			jw.BeginObjectField("unit")
+			jw.WriteString("")
+			jw.EndObject()
+			jw.EndArray()
+			rendered++
+		}
+	}
+
+	jw.EndObject()
+
+	jw.EndObject()
+
+	return jw.Close()
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataHandler(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			query *storage.CompleteTagsQuery,
+			_ *storage.FetchOptions,
+		) (*storage.CompleteTagsResult, error) {
+			require.Len(t, query.TagMatchers, 1)
+			assert.Equal(t, models.MatchRegexp, query.TagMatchers[0].Type)
+
+			return &storage.CompleteTagsResult{
+				CompletedTags: []storage.CompletedTag{{
+					Name:   []byte("__name__"),
+					Values: [][]byte{[]byte("down"), []byte("up"), []byte("z")},
+				}},
+			}, nil
+		})
+
+	req := httptest.NewRequest(http.MethodGet, MetadataURL+"?limit=2", nil)
+	recorder := httptest.NewRecorder()
+	NewMetadataHandler(store, models.NewTagOptions()).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	assert.JSONEq(t, `{
+		"status": "success",
+		"data": {
+			"down": [{"type": "unknown", "help": "", "unit": ""}],
+			"up": [{"type": "unknown", "help": "", "unit": ""}]
+		}
+	}`, recorder.Body.String())
+}
+
+func TestMetadataHandlerSingleMetric(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			query *storage.CompleteTagsQuery,
+			_ *storage.FetchOptions,
+		) (*storage.CompleteTagsResult, error) {
+			require.Len(t, query.TagMatchers, 1)
+			assert.Equal(t, models.MatchEqual, query.TagMatchers[0].Type)
+			assert.Equal(t, "up", string(query.TagMatchers[0].Value))
+
+			return &storage.CompleteTagsResult{}, nil
+		})
+
+	req := httptest.NewRequest(http.MethodGet, MetadataURL+"?metric=up", nil)
+	recorder := httptest.NewRecorder()
+	NewMetadataHandler(store, models.NewTagOptions()).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":{}}`, recorder.Body.String())
+}
+
+func TestMetadataHandlerInvalidLimit(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	req := httptest.NewRequest(http.MethodGet, MetadataURL+"?limit=foo", nil)
+	recorder := httptest.NewRecorder()
+	NewMetadataHandler(store, models.NewTagOptions()).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
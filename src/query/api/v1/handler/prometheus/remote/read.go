@@ -30,7 +30,9 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/util/logging"
 	xhttp "github.com/m3db/m3/src/x/net/http"
 
@@ -50,17 +52,31 @@ const (
 
 // PromReadHandler represents a handler for prometheus read endpoint.
 type PromReadHandler struct {
-	engine          *executor.Engine
-	promReadMetrics promReadMetrics
-	timeoutOpts     *prometheus.TimeoutOpts
+	engine           *executor.Engine
+	querier          m3.Querier
+	tagOptions       models.TagOptions
+	promReadMetrics  promReadMetrics
+	timeoutOpts      *prometheus.TimeoutOpts
+	maxBytesPerFrame int
 }
 
-// NewPromReadHandler returns a new instance of handler.
-func NewPromReadHandler(engine *executor.Engine, scope tally.Scope, timeoutOpts *prometheus.TimeoutOpts) http.Handler {
+// NewPromReadHandler returns a new instance of handler. If a compressed
+// querier is provided the handler also serves streamed XOR chunk responses,
+// otherwise clients are always answered with sampled responses.
+func NewPromReadHandler(
+	engine *executor.Engine,
+	querier m3.Querier,
+	tagOptions models.TagOptions,
+	scope tally.Scope,
+	timeoutOpts *prometheus.TimeoutOpts,
+) http.Handler {
 	return &PromReadHandler{
-		engine:          engine,
-		promReadMetrics: newPromReadMetrics(scope),
-		timeoutOpts:     timeoutOpts,
+		engine:           engine,
+		querier:          querier,
+		tagOptions:       tagOptions,
+		promReadMetrics:  newPromReadMetrics(scope),
+		timeoutOpts:      timeoutOpts,
+		maxBytesPerFrame: defaultMaxBytesPerFrame,
 	}
 }
 
@@ -97,6 +113,18 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	responseType, err := negotiateResponseType(req.AcceptedResponseTypes, h.querier != nil)
+	if err != nil {
+		h.promReadMetrics.fetchErrorsClient.Inc(1)
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if responseType == prompb.ReadRequest_STREAMED_XOR_CHUNKS {
+		h.serveStreamed(ctx, w, req, timeout)
+		return
+	}
+
 	result, err := h.read(ctx, w, req, timeout)
 	if err != nil {
 		h.promReadMetrics.fetchErrorsServer.Inc(1)
@@ -131,6 +159,41 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.promReadMetrics.fetchSuccess.Inc(1)
 }
 
+func (h *PromReadHandler) serveStreamed(
+	reqCtx context.Context,
+	w http.ResponseWriter,
+	req *prompb.ReadRequest,
+	timeout time.Duration,
+) {
+	logger := logging.WithContext(reqCtx)
+	chunked, err := newChunkedWriter(w)
+	if err != nil {
+		h.promReadMetrics.fetchErrorsServer.Inc(1)
+		logger.Error("unable to stream read results", zap.Error(err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(reqCtx, timeout)
+	defer cancel()
+	// Detect clients closing connections
+	handler.CloseWatcher(ctx, cancel, w)
+
+	w.Header().Set("Content-Type", streamedContentType)
+	if err := h.readStreamed(ctx, chunked, req); err != nil {
+		h.promReadMetrics.fetchErrorsServer.Inc(1)
+		logger.Error("unable to stream read results", zap.Error(err))
+		if !chunked.written {
+			// Nothing has been sent yet so a proper error can still be returned,
+			// otherwise the client detects the truncated stream itself.
+			xhttp.Error(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.promReadMetrics.fetchSuccess.Inc(1)
+}
+
 func (h *PromReadHandler) parseRequest(
 	r *http.Request,
 ) (*prompb.ReadRequest, *xhttp.ParseError) {
@@ -0,0 +1,283 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/storage"
+
+	"github.com/prometheus/tsdb/chunkenc"
+)
+
+const (
+	// streamedContentType is the content type of streamed remote read
+	// responses, as understood by Prometheus remote read clients.
+	streamedContentType = "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse"
+
+	// maxSamplesPerChunk mirrors the number of samples Prometheus cuts
+	// its own XOR chunks at.
+	maxSamplesPerChunk = 120
+
+	// defaultMaxBytesPerFrame bounds the encoded chunk bytes buffered for a
+	// single frame, long series are split across several frames.
+	defaultMaxBytesPerFrame = 1024 * 1024
+)
+
+var (
+	castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+	errStreamingNotSupported = errors.New("response writer does not support streaming")
+	errNoSupportedResponse   = errors.New("none of the accepted response types are supported")
+)
+
+// negotiateResponseType picks the first accepted response type that can be
+// served, defaulting to sampled responses for clients that do not negotiate.
+func negotiateResponseType(
+	accepted []prompb.ReadRequest_ResponseType,
+	streamingEnabled bool,
+) (prompb.ReadRequest_ResponseType, error) {
+	if len(accepted) == 0 {
+		return prompb.ReadRequest_SAMPLES, nil
+	}
+
+	for _, t := range accepted {
+		switch t {
+		case prompb.ReadRequest_SAMPLES:
+			return t, nil
+		case prompb.ReadRequest_STREAMED_XOR_CHUNKS:
+			if streamingEnabled {
+				return t, nil
+			}
+		}
+	}
+
+	return 0, errNoSupportedResponse
+}
+
+// chunkedWriter writes length delimited, checksummed ChunkedReadResponse
+// frames, flushing each frame to the client as soon as it is written.
+type chunkedWriter struct {
+	writer  io.Writer
+	flusher http.Flusher
+	crc     hash.Hash32
+	buf     []byte
+	written bool
+}
+
+func newChunkedWriter(w http.ResponseWriter) (*chunkedWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errStreamingNotSupported
+	}
+
+	return &chunkedWriter{
+		writer:  w,
+		flusher: flusher,
+		crc:     crc32.New(castagnoliTable),
+	}, nil
+}
+
+// Write marshals the message into a buffer reused across frames and writes
+// it as <uvarint size><big endian crc32 castagnoli><message>.
+func (w *chunkedWriter) Write(msg *prompb.ChunkedReadResponse) error {
+	size := msg.Size()
+	frameSize := binary.MaxVarintLen64 + crc32.Size + size
+	if cap(w.buf) < frameSize {
+		w.buf = make([]byte, frameSize)
+	}
+
+	buf := w.buf[:frameSize]
+	n := binary.PutUvarint(buf, uint64(size))
+	dataStart := n + crc32.Size
+	if _, err := msg.MarshalTo(buf[dataStart:]); err != nil {
+		return err
+	}
+
+	w.crc.Reset()
+	if _, err := w.crc.Write(buf[dataStart : dataStart+size]); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint32(buf[n:], w.crc.Sum32())
+	w.written = true
+	if _, err := w.writer.Write(buf[:dataStart+size]); err != nil {
+		return err
+	}
+
+	w.flusher.Flush()
+	return nil
+}
+
+func (h *PromReadHandler) readStreamed(
+	ctx context.Context,
+	w *chunkedWriter,
+	r *prompb.ReadRequest,
+) error {
+	fetchOpts, closer := h.engine.NewFetchOptions(ctx)
+	defer closer()
+
+	for i, promQuery := range r.Queries {
+		query, err := storage.PromReadQueryToM3(promQuery)
+		if err != nil {
+			return err
+		}
+
+		if err := h.streamQuery(ctx, w, int64(i), query, fetchOpts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// streamQuery fetches the series matching the query in a single fetch and
+// streams them one at a time. The fetched series are held compressed and are
+// only decoded as they are streamed, so that a single frame is held in memory
+// on top of the compressed series regardless of how many series match.
+func (h *PromReadHandler) streamQuery(
+	ctx context.Context,
+	w *chunkedWriter,
+	queryIndex int64,
+	query *storage.FetchQuery,
+	fetchOpts *storage.FetchOptions,
+) error {
+	iters, cleanup, err := h.querier.FetchCompressed(ctx, query, fetchOpts)
+	if err != nil {
+		return err
+	}
+
+	// NB: cleanup closes the iterators and returns them to their pools.
+	defer cleanup()
+
+	for _, iter := range iters.Iters() {
+		if err := h.streamSingleSeries(w, queryIndex, iter, fetchOpts.Enforcer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// streamSingleSeries transcodes a single series into XOR chunks, writing a
+// frame whenever the buffered chunks exceed the frame size so that at most
+// one frame worth of chunks is held in memory at any point.
+func (h *PromReadHandler) streamSingleSeries(
+	w *chunkedWriter,
+	queryIndex int64,
+	iter encoding.SeriesIterator,
+	enforcer cost.ChainedEnforcer,
+) error {
+	metric, err := storage.FromM3IdentToMetric(iter.ID(), iter.Tags(), h.tagOptions)
+	if err != nil {
+		return err
+	}
+
+	var (
+		series = &prompb.ChunkedSeries{
+			Labels: storage.TagsToPromLabels(metric.Tags),
+		}
+		resp = &prompb.ChunkedReadResponse{
+			ChunkedSeries: []*prompb.ChunkedSeries{series},
+			QueryIndex:    queryIndex,
+		}
+		frameBytes int
+		chunk      *chunkenc.XORChunk
+		appender   chunkenc.Appender
+		minTime    int64
+		maxTime    int64
+	)
+
+	cutChunk := func() {
+		data := chunk.Bytes()
+		series.Chunks = append(series.Chunks, &prompb.Chunk{
+			MinTimeMs: minTime,
+			MaxTimeMs: maxTime,
+			Type:      prompb.Chunk_XOR,
+			Data:      data,
+		})
+		frameBytes += len(data)
+		chunk = nil
+	}
+
+	writeFrame := func() error {
+		if err := w.Write(resp); err != nil {
+			return err
+		}
+
+		series.Chunks = series.Chunks[:0]
+		frameBytes = 0
+		return nil
+	}
+
+	for iter.Next() {
+		if r := enforcer.Add(1); r.Error != nil {
+			return r.Error
+		}
+
+		dp, _, _ := iter.Current()
+		t := storage.TimeToTimestamp(dp.Timestamp)
+		if chunk == nil {
+			chunk = chunkenc.NewXORChunk()
+			if appender, err = chunk.Appender(); err != nil {
+				return err
+			}
+
+			minTime = t
+		}
+
+		appender.Append(t, dp.Value)
+		maxTime = t
+		if chunk.NumSamples() < maxSamplesPerChunk {
+			continue
+		}
+
+		cutChunk()
+		if frameBytes >= h.maxBytesPerFrame {
+			if err := writeFrame(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if chunk != nil {
+		cutChunk()
+	}
+
+	if len(series.Chunks) == 0 {
+		return nil
+	}
+
+	return writeFrame()
+}
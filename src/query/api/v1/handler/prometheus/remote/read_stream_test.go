@@ -0,0 +1,328 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote/test"
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	m3storage "github.com/m3db/m3/src/query/storage/m3"
+	querytest "github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/cost"
+
+	"github.com/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type fakeQuerier struct {
+	iters   []encoding.SeriesIterator
+	fetches int
+}
+
+func (q *fakeQuerier) FetchCompressed(
+	_ context.Context,
+	_ *storage.FetchQuery,
+	_ *storage.FetchOptions,
+) (encoding.SeriesIterators, m3storage.Cleanup, error) {
+	q.fetches++
+	iters := encoding.NewSeriesIterators(q.iters, nil)
+	return iters, func() error {
+		iters.Close()
+		return nil
+	}, nil
+}
+
+func (q *fakeQuerier) SearchCompressed(
+	_ context.Context,
+	_ *storage.FetchQuery,
+	_ *storage.FetchOptions,
+) ([]m3storage.MultiTagResult, m3storage.Cleanup, error) {
+	return nil, nil, errors.New("search not expected")
+}
+
+func newStreamedTestEnforcer(limit cost.Cost) qcost.ChainedEnforcer {
+	enforcer, err := qcost.NewChainedEnforcer(qcost.GlobalLevel, []cost.Enforcer{
+		cost.NewEnforcer(
+			cost.NewStaticLimitManager(cost.NewLimitManagerOptions()),
+			cost.NewTracker(),
+			nil,
+		),
+		cost.NewEnforcer(
+			cost.NewStaticLimitManager(cost.NewLimitManagerOptions().
+				SetDefaultLimit(cost.Limit{Threshold: limit, Enabled: true})),
+			cost.NewTracker(),
+			nil,
+		),
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return enforcer
+}
+
+func streamedReadHandler(iters ...encoding.SeriesIterator) *PromReadHandler {
+	return streamedReadHandlerWithEnforcer(qcost.NoopChainedEnforcer(), iters...)
+}
+
+func streamedReadHandlerWithEnforcer(
+	enforcer qcost.ChainedEnforcer,
+	iters ...encoding.SeriesIterator,
+) *PromReadHandler {
+	scope := tally.NewTestScope("", nil)
+	engine := executor.NewEngine(nil, scope, time.Minute, enforcer)
+	return NewPromReadHandler(engine, &fakeQuerier{iters: iters},
+		models.NewTagOptions(), scope, timeoutOpts).(*PromReadHandler)
+}
+
+func streamedReadRequest(t *testing.T) *http.Request {
+	promReq := test.GeneratePromReadRequest()
+	promReq.AcceptedResponseTypes = []prompb.ReadRequest_ResponseType{
+		prompb.ReadRequest_STREAMED_XOR_CHUNKS,
+		prompb.ReadRequest_SAMPLES,
+	}
+
+	return httptest.NewRequest(PromReadHTTPMethod, PromReadURL,
+		test.GeneratePromReadRequestBody(t, promReq))
+}
+
+func readFrames(t *testing.T, r io.Reader) []*prompb.ChunkedReadResponse {
+	var (
+		reader = bufio.NewReader(r)
+		frames []*prompb.ChunkedReadResponse
+	)
+	for {
+		size, err := binary.ReadUvarint(reader)
+		if err == io.EOF {
+			return frames
+		}
+		require.NoError(t, err)
+
+		var checksum [crc32.Size]byte
+		_, err = io.ReadFull(reader, checksum[:])
+		require.NoError(t, err)
+
+		data := make([]byte, size)
+		_, err = io.ReadFull(reader, data)
+		require.NoError(t, err)
+		require.Equal(t, binary.BigEndian.Uint32(checksum[:]),
+			crc32.Checksum(data, castagnoliTable))
+
+		var frame prompb.ChunkedReadResponse
+		require.NoError(t, frame.Unmarshal(data))
+		frames = append(frames, &frame)
+	}
+}
+
+func chunkSamples(t *testing.T, chunk *prompb.Chunk) []int64 {
+	require.Equal(t, prompb.Chunk_XOR, chunk.Type)
+	c, err := chunkenc.FromData(chunkenc.EncXOR, chunk.Data)
+	require.NoError(t, err)
+
+	var (
+		it         = c.Iterator()
+		timestamps []int64
+	)
+	for it.Next() {
+		ts, _ := it.At()
+		timestamps = append(timestamps, ts)
+	}
+
+	require.NoError(t, it.Err())
+	require.Equal(t, chunk.MinTimeMs, timestamps[0])
+	require.Equal(t, chunk.MaxTimeMs, timestamps[len(timestamps)-1])
+	return timestamps
+}
+
+func TestNegotiateResponseType(t *testing.T) {
+	var (
+		samples  = prompb.ReadRequest_SAMPLES
+		streamed = prompb.ReadRequest_STREAMED_XOR_CHUNKS
+	)
+
+	tests := []struct {
+		accepted  []prompb.ReadRequest_ResponseType
+		streaming bool
+		expected  prompb.ReadRequest_ResponseType
+		err       bool
+	}{
+		{accepted: nil, streaming: true, expected: samples},
+		{accepted: []prompb.ReadRequest_ResponseType{streamed, samples}, streaming: true, expected: streamed},
+		{accepted: []prompb.ReadRequest_ResponseType{streamed, samples}, streaming: false, expected: samples},
+		{accepted: []prompb.ReadRequest_ResponseType{samples, streamed}, streaming: true, expected: samples},
+		{accepted: []prompb.ReadRequest_ResponseType{streamed}, streaming: false, err: true},
+	}
+
+	for _, tt := range tests {
+		actual, err := negotiateResponseType(tt.accepted, tt.streaming)
+		if tt.err {
+			assert.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, actual)
+	}
+}
+
+func TestPromReadStreamed(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	iter, err := querytest.BuildTestSeriesIterator()
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	streamedReadHandler(iter).ServeHTTP(recorder, streamedReadRequest(t))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, streamedContentType, recorder.Header().Get("Content-Type"))
+	assert.True(t, recorder.Flushed)
+
+	frames := readFrames(t, recorder.Body)
+	require.Len(t, frames, 1)
+	require.Len(t, frames[0].ChunkedSeries, 1)
+	assert.Equal(t, int64(0), frames[0].QueryIndex)
+
+	series := frames[0].ChunkedSeries[0]
+	labels := make(map[string]string, len(series.Labels))
+	for _, l := range series.Labels {
+		labels[string(l.Name)] = string(l.Value)
+	}
+
+	assert.Equal(t, querytest.TestTags, labels)
+	require.Len(t, series.Chunks, 1)
+	assert.Len(t, chunkSamples(t, series.Chunks[0]), 58)
+}
+
+func TestPromReadStreamedFetchesOnce(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	var (
+		start = time.Now().Truncate(time.Hour)
+		dps   = []querytest.Datapoint{{Value: 1}, {Value: 2, Offset: time.Second}}
+		iters []encoding.SeriesIterator
+	)
+	for _, id := range []string{"a", "b", "c"} {
+		iter, _, err := querytest.BuildCustomIterator([][]querytest.Datapoint{dps},
+			map[string]string{"id": id}, id, "namespace", start,
+			time.Hour, time.Second)
+		require.NoError(t, err)
+		iters = append(iters, iter)
+	}
+
+	handler := streamedReadHandler(iters...)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, streamedReadRequest(t))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// All matching series are streamed from a single fetch.
+	assert.Equal(t, 1, handler.querier.(*fakeQuerier).fetches)
+
+	frames := readFrames(t, recorder.Body)
+	require.Len(t, frames, 3)
+	for i, id := range []string{"a", "b", "c"} {
+		require.Len(t, frames[i].ChunkedSeries, 1)
+		series := frames[i].ChunkedSeries[0]
+		require.Len(t, series.Labels, 1)
+		assert.Equal(t, id, string(series.Labels[0].Value))
+		require.Len(t, series.Chunks, 1)
+		assert.Len(t, chunkSamples(t, series.Chunks[0]), 2)
+	}
+}
+
+func TestPromReadStreamedSplitsChunksAndFrames(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	var (
+		numPoints = 2*maxSamplesPerChunk + 10
+		dps       = make([]querytest.Datapoint, 0, numPoints)
+	)
+	for i := 0; i < numPoints; i++ {
+		dps = append(dps, querytest.Datapoint{
+			Value:  float64(i),
+			Offset: time.Duration(i) * time.Second,
+		})
+	}
+
+	start := time.Now().Truncate(time.Hour)
+	iter, _, err := querytest.BuildCustomIterator([][]querytest.Datapoint{dps},
+		map[string]string{"foo": "bar"}, "id", "namespace", start,
+		time.Hour, time.Second)
+	require.NoError(t, err)
+
+	handler := streamedReadHandler(iter)
+	// Force every chunk to be written in its own frame.
+	handler.maxBytesPerFrame = 1
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, streamedReadRequest(t))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	frames := readFrames(t, recorder.Body)
+	require.Len(t, frames, 3)
+
+	var timestamps []int64
+	for _, frame := range frames {
+		require.Len(t, frame.ChunkedSeries, 1)
+		require.Len(t, frame.ChunkedSeries[0].Chunks, 1)
+		timestamps = append(timestamps,
+			chunkSamples(t, frame.ChunkedSeries[0].Chunks[0])...)
+	}
+
+	require.Len(t, timestamps, numPoints)
+	startMs := storage.TimeToTimestamp(start)
+	for i, ts := range timestamps {
+		assert.Equal(t, startMs+int64(i)*1000, ts)
+	}
+}
+
+func TestPromReadStreamedEnforcesLimits(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	iter, err := querytest.BuildTestSeriesIterator()
+	require.NoError(t, err)
+
+	// The series has more datapoints than the per query limit allows.
+	handler := streamedReadHandlerWithEnforcer(newStreamedTestEnforcer(10), iter)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, streamedReadRequest(t))
+
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "exceeded")
+}
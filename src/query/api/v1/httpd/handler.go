@@ -35,15 +35,18 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler"
 	"github.com/m3db/m3/src/query/api/v1/handler/database"
 	"github.com/m3db/m3/src/query/api/v1/handler/graphite"
+	"github.com/m3db/m3/src/query/api/v1/handler/influxdb"
 	m3json "github.com/m3db/m3/src/query/api/v1/handler/json"
 	"github.com/m3db/m3/src/query/api/v1/handler/namespace"
 	"github.com/m3db/m3/src/query/api/v1/handler/openapi"
+	"github.com/m3db/m3/src/query/api/v1/handler/opentsdb"
 	"github.com/m3db/m3/src/query/api/v1/handler/placement"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/validator"
 	"github.com/m3db/m3/src/query/api/v1/handler/topic"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/cost"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
@@ -52,6 +55,7 @@ import (
 	"github.com/m3db/m3/src/query/util/logging"
 	xhttp "github.com/m3db/m3/src/x/net/http"
 	"github.com/m3db/m3/src/x/net/http/cors"
+	"github.com/m3db/m3x/instrument"
 
 	"github.com/gorilla/mux"
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
@@ -65,8 +69,10 @@ const (
 )
 
 var (
-	remoteSource = map[string]string{"source": "remote"}
-	nativeSource = map[string]string{"source": "native"}
+	remoteSource   = map[string]string{"source": "remote"}
+	nativeSource   = map[string]string{"source": "native"}
+	influxSource   = map[string]string{"source": "influxdb"}
+	opentsdbSource = map[string]string{"source": "opentsdb"}
 
 	defaultTimeout = 30 * time.Second
 )
@@ -78,6 +84,7 @@ type Handler struct {
 	storage              storage.Storage
 	downsamplerAndWriter ingest.DownsamplerAndWriter
 	engine               *executor.Engine
+	querier              m3.Querier
 	clusters             m3.Clusters
 	clusterClient        clusterclient.Client
 	config               config.Configuration
@@ -99,6 +106,7 @@ func NewHandler(
 	downsamplerAndWriter ingest.DownsamplerAndWriter,
 	tagOptions models.TagOptions,
 	engine *executor.Engine,
+	querier m3.Querier,
 	m3dbClusters m3.Clusters,
 	clusterClient clusterclient.Client,
 	cfg config.Configuration,
@@ -127,6 +135,7 @@ func NewHandler(
 		storage:              downsamplerAndWriter.Storage(),
 		downsamplerAndWriter: downsamplerAndWriter,
 		engine:               engine,
+		querier:              querier,
 		clusters:             m3dbClusters,
 		clusterClient:        clusterClient,
 		config:               cfg,
@@ -163,13 +172,28 @@ func (h *Handler) RegisterRoutes() error {
 	wrapped := logging.WithResponseTimeAndPanicErrorLogging
 	panicOnly := logging.WithPanicErrorResponder
 
+	// Wrap query requests with per caller accounting if enabled.
+	accounted := wrapped
+	if accountingCfg := h.config.Accounting; accountingCfg != nil {
+		accountant, err := accountingCfg.NewAccountant(
+			instrument.NewOptions().SetMetricsScope(h.scope.SubScope("accounting")))
+		if err != nil {
+			return err
+		}
+
+		accounted = func(next http.Handler) http.Handler {
+			return wrapped(accountant.Wrap(next))
+		}
+	}
+
 	h.router.HandleFunc(openapi.URL,
 		wrapped(&openapi.DocHandler{}).ServeHTTP,
 	).Methods(openapi.HTTPMethod)
 	h.router.PathPrefix(openapi.StaticURLPrefix).Handler(wrapped(openapi.StaticHandler()))
 
 	// Prometheus remote read/write endpoints
-	promRemoteReadHandler := remote.NewPromReadHandler(h.engine, h.scope.Tagged(remoteSource), h.timeoutOpts)
+	promRemoteReadHandler := remote.NewPromReadHandler(h.engine, h.querier,
+		h.tagOptions, h.scope.Tagged(remoteSource), h.timeoutOpts)
 	promRemoteWriteHandler, err := remote.NewPromWriteHandler(
 		h.downsamplerAndWriter,
 		h.tagOptions,
@@ -179,6 +203,15 @@ func (h *Handler) RegisterRoutes() error {
 		return err
 	}
 
+	var resultsCache *cache.ResultsCache
+	if resultsCfg := h.config.Cache.Results; resultsCfg != nil {
+		resultsCache, err = resultsCfg.NewResultsCache(h.tagOptions,
+			h.scope.SubScope("results-cache"))
+		if err != nil {
+			return err
+		}
+	}
+
 	nativePromReadHandler := native.NewPromReadHandler(
 		h.engine,
 		h.tagOptions,
@@ -186,20 +219,25 @@ func (h *Handler) RegisterRoutes() error {
 		h.scope.Tagged(nativeSource),
 		h.timeoutOpts,
 		h.config.ResultOptions.KeepNans,
+		resultsCache,
 	)
 
 	h.router.HandleFunc(remote.PromReadURL,
-		wrapped(promRemoteReadHandler).ServeHTTP,
+		accounted(promRemoteReadHandler).ServeHTTP,
 	).Methods(remote.PromReadHTTPMethod)
 	h.router.HandleFunc(remote.PromWriteURL,
 		panicOnly(promRemoteWriteHandler).ServeHTTP,
 	).Methods(remote.PromWriteHTTPMethod)
 	h.router.HandleFunc(native.PromReadURL,
-		wrapped(nativePromReadHandler).ServeHTTP,
+		accounted(nativePromReadHandler).ServeHTTP,
 	).Methods(native.PromReadHTTPMethod)
 	h.router.HandleFunc(native.PromReadInstantURL,
-		wrapped(native.NewPromReadInstantHandler(h.engine, h.tagOptions, h.timeoutOpts)).ServeHTTP,
+		accounted(native.NewPromReadInstantHandler(h.engine, h.tagOptions, h.timeoutOpts)).ServeHTTP,
 	).Methods(native.PromReadInstantHTTPMethod)
+	h.router.HandleFunc(native.FederateURL,
+		accounted(native.NewFederateHandler(h.engine, h.tagOptions,
+			*h.config.LookbackDuration, h.timeoutOpts)).ServeHTTP,
+	).Methods(native.FederateHTTPMethod)
 
 	// Native M3 search and write endpoints
 	h.router.HandleFunc(handler.SearchURL,
@@ -209,13 +247,59 @@ func (h *Handler) RegisterRoutes() error {
 		wrapped(m3json.NewWriteJSONHandler(h.storage)).ServeHTTP,
 	).Methods(m3json.JSONWriteHTTPMethod)
 
+	// InfluxDB line protocol write endpoint
+	influxWriteHandler, err := influxdb.NewWriteHandler(
+		h.downsamplerAndWriter,
+		h.tagOptions,
+		h.config.InfluxDB.MaxBodyBytesOrDefault(),
+		h.scope.Tagged(influxSource),
+	)
+	if err != nil {
+		return err
+	}
+
+	h.router.HandleFunc(influxdb.InfluxWriteURL,
+		panicOnly(influxWriteHandler).ServeHTTP,
+	).Methods(influxdb.InfluxWriteHTTPMethod)
+
+	// OpenTSDB put and query endpoints
+	opentsdbPutHandler, err := opentsdb.NewPutHandler(
+		h.downsamplerAndWriter,
+		h.tagOptions,
+		h.scope.Tagged(opentsdbSource),
+	)
+	if err != nil {
+		return err
+	}
+
+	h.router.HandleFunc(opentsdb.PutURL,
+		panicOnly(opentsdbPutHandler).ServeHTTP,
+	).Methods(opentsdb.PutHTTPMethod)
+	h.router.HandleFunc(opentsdb.QueryURL,
+		accounted(opentsdb.NewQueryHandler(
+			h.engine,
+			h.tagOptions,
+			&h.config.Limits,
+			h.timeoutOpts,
+			h.scope.Tagged(opentsdbSource),
+		)).ServeHTTP,
+	).Methods(opentsdb.QueryHTTPMethod)
+
 	// Tag completion endpoints
 	h.router.HandleFunc(native.CompleteTagsURL,
 		wrapped(native.NewCompleteTagsHandler(h.storage)).ServeHTTP,
 	).Methods(native.CompleteTagsHTTPMethod)
 	h.router.HandleFunc(remote.TagValuesURL,
-		wrapped(remote.NewTagValuesHandler(h.storage)).ServeHTTP,
+		wrapped(remote.NewTagValuesHandler(h.storage, h.tagOptions)).ServeHTTP,
 	).Methods(remote.TagValuesHTTPMethod)
+	h.router.HandleFunc(remote.LabelNamesURL,
+		wrapped(remote.NewLabelNamesHandler(h.storage, h.tagOptions)).ServeHTTP,
+	).Methods(remote.LabelNamesHTTPMethods...)
+
+	// Metric metadata endpoint
+	h.router.HandleFunc(remote.MetadataURL,
+		wrapped(remote.NewMetadataHandler(h.storage, h.tagOptions)).ServeHTTP,
+	).Methods(remote.MetadataHTTPMethod)
 
 	// Series match endpoints
 	h.router.HandleFunc(remote.PromSeriesMatchURL,
@@ -228,14 +312,49 @@ func (h *Handler) RegisterRoutes() error {
 	).Methods(validator.PromDebugHTTPMethod)
 
 	// Graphite endpoints
+	findCacheCfg := h.config.Cache.GraphiteFindCacheConfiguration()
+	if err := findCacheCfg.Validate(); err != nil {
+		return err
+	}
+
+	var findCache *graphite.FindCache
+	if ttl := findCacheCfg.TTLOrDefault(); ttl > 0 {
+		backend, err := cache.NewLRUCache(findCacheCfg.MaxBytesOrDefault())
+		if err != nil {
+			return err
+		}
+
+		findCache, err = graphite.NewFindCache(backend, ttl,
+			h.scope.SubScope("graphite-find-cache"))
+		if err != nil {
+			return err
+		}
+	}
+
 	h.router.HandleFunc(graphite.ReadURL,
-		wrapped(graphite.NewRenderHandler(h.storage, h.enforcer)).ServeHTTP,
+		accounted(graphite.NewRenderHandler(h.storage, h.enforcer)).ServeHTTP,
 	).Methods(graphite.ReadHTTPMethods...)
 
 	h.router.HandleFunc(graphite.FindURL,
-		wrapped(graphite.NewFindHandler(h.storage)).ServeHTTP,
+		wrapped(graphite.NewFindHandler(h.storage, findCache)).ServeHTTP,
 	).Methods(graphite.FindHTTPMethods...)
 
+	h.router.HandleFunc(graphite.TagsURL,
+		wrapped(graphite.NewTagsHandler(h.storage)).ServeHTTP,
+	).Methods(graphite.TagsHTTPMethods...)
+
+	h.router.HandleFunc(graphite.AutoCompleteTagsURL,
+		wrapped(graphite.NewAutoCompleteTagsHandler(h.storage)).ServeHTTP,
+	).Methods(graphite.TagsHTTPMethods...)
+
+	h.router.HandleFunc(graphite.AutoCompleteValuesURL,
+		wrapped(graphite.NewAutoCompleteValuesHandler(h.storage)).ServeHTTP,
+	).Methods(graphite.TagsHTTPMethods...)
+
+	h.router.HandleFunc(graphite.TagValuesURL,
+		wrapped(graphite.NewTagValuesHandler(h.storage)).ServeHTTP,
+	).Methods(graphite.TagsHTTPMethods...)
+
 	if h.clusterClient != nil {
 		placementOpts := placement.HandlerOptions{
 			ClusterClient:       h.clusterClient,
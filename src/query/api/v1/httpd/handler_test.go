@@ -32,7 +32,9 @@ import (
 	dbconfig "github.com/m3db/m3/src/cmd/services/m3dbnode/config"
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/query/api/v1/handler/influxdb"
 	m3json "github.com/m3db/m3/src/query/api/v1/handler/json"
+	"github.com/m3db/m3/src/query/api/v1/handler/opentsdb"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote"
 	"github.com/m3db/m3/src/query/executor"
@@ -69,6 +71,7 @@ func setupHandler(store storage.Storage) (*Handler, error) {
 			time.Minute, nil),
 		nil,
 		nil,
+		nil,
 		config.Configuration{LookbackDuration: &defaultLookbackDuration},
 		nil,
 		nil,
@@ -86,7 +89,7 @@ func TestHandlerFetchTimeoutError(t *testing.T) {
 	dbconfig := &dbconfig.DBConfiguration{Client: client.Configuration{FetchTimeout: &negValue}}
 	engine := executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil)
 	cfg := config.Configuration{LookbackDuration: &defaultLookbackDuration}
-	_, err := NewHandler(downsamplerAndWriter, makeTagOptions(), engine, nil, nil,
+	_, err := NewHandler(downsamplerAndWriter, makeTagOptions(), engine, nil, nil, nil,
 		cfg, dbconfig, nil, tally.NewTestScope("", nil))
 
 	require.Error(t, err)
@@ -104,7 +107,7 @@ func TestHandlerFetchTimeout(t *testing.T) {
 	engine := executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil)
 	cfg := config.Configuration{LookbackDuration: &defaultLookbackDuration}
 	h, err := NewHandler(downsamplerAndWriter, makeTagOptions(), engine,
-		nil, nil, cfg, dbconfig, nil, tally.NewTestScope("", nil))
+		nil, nil, nil, cfg, dbconfig, nil, tally.NewTestScope("", nil))
 	require.NoError(t, err)
 	assert.Equal(t, 4*time.Minute, h.timeoutOpts.FetchTimeout)
 }
@@ -187,6 +190,38 @@ func TestJSONWritePost(t *testing.T) {
 	require.Equal(t, res.Code, http.StatusBadRequest, "Empty request")
 }
 
+func TestInfluxWriteGet(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	req, _ := http.NewRequest("GET", influxdb.InfluxWriteURL, nil)
+	res := httptest.NewRecorder()
+	ctrl := gomock.NewController(t)
+	storage, _ := m3.NewStorageAndSession(t, ctrl)
+
+	h, err := setupHandler(storage)
+	require.NoError(t, err, "unable to setup handler")
+	h.RegisterRoutes()
+	h.Router().ServeHTTP(res, req)
+	require.Equal(t, res.Code, http.StatusMethodNotAllowed, "GET method not defined")
+}
+
+func TestOpenTSDBGet(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	for _, url := range []string{opentsdb.PutURL, opentsdb.QueryURL} {
+		req, _ := http.NewRequest("GET", url, nil)
+		res := httptest.NewRecorder()
+		ctrl := gomock.NewController(t)
+		storage, _ := m3.NewStorageAndSession(t, ctrl)
+
+		h, err := setupHandler(storage)
+		require.NoError(t, err, "unable to setup handler")
+		h.RegisterRoutes()
+		h.Router().ServeHTTP(res, req)
+		require.Equal(t, res.Code, http.StatusMethodNotAllowed, "GET method not defined")
+	}
+}
+
 func TestRoutesGet(t *testing.T) {
 	logging.InitWithCores(nil)
 
@@ -0,0 +1,142 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	defaultLRUMaxBytes = 256 * 1024 * 1024
+)
+
+var (
+	errMultipleBackends = errors.New("results cache must configure at most one of lru and memcached")
+)
+
+// ResultsConfiguration configures the range query results cache.
+type ResultsConfiguration struct {
+	// SplitInterval is the interval ranges are split into and cached by.
+	SplitInterval *time.Duration `yaml:"splitInterval"`
+
+	// BufferPast is how far in the past an interval must end to be cached.
+	BufferPast *time.Duration `yaml:"bufferPast"`
+
+	// TTL is how long cached intervals are kept.
+	TTL *time.Duration `yaml:"ttl"`
+
+	// LRU configures an in-process LRU cache, the default backend.
+	LRU *LRUConfiguration `yaml:"lru"`
+
+	// Memcached configures a memcached cache.
+	Memcached *MemcachedConfiguration `yaml:"memcached"`
+}
+
+// LRUConfiguration configures an in-process LRU cache.
+type LRUConfiguration struct {
+	// MaxBytes is the maximum size of the cached keys and values.
+	MaxBytes int `yaml:"maxBytes"`
+}
+
+// MemcachedConfiguration configures a memcached cache.
+type MemcachedConfiguration struct {
+	// Servers are the memcached server addresses.
+	Servers []string `yaml:"servers" validate:"nonzero"`
+
+	// Timeout is the timeout of a single cache operation.
+	Timeout *time.Duration `yaml:"timeout"`
+
+	// MaxIdleConns is the maximum number of idle connections per server.
+	MaxIdleConns *int `yaml:"maxIdleConns"`
+}
+
+// Validate validates the results cache configuration.
+func (c ResultsConfiguration) Validate() error {
+	if c.LRU != nil && c.Memcached != nil {
+		return errMultipleBackends
+	}
+
+	if c.SplitInterval != nil && *c.SplitInterval <= 0 {
+		return errInvalidSplitInterval
+	}
+
+	return nil
+}
+
+// NewCache returns the configured cache backend.
+func (c ResultsConfiguration) NewCache() (Cache, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.Memcached != nil {
+		opts := MemcachedOptions{Servers: c.Memcached.Servers}
+		if c.Memcached.Timeout != nil {
+			opts.Timeout = *c.Memcached.Timeout
+		}
+		if c.Memcached.MaxIdleConns != nil {
+			opts.MaxIdleConns = *c.Memcached.MaxIdleConns
+		}
+		return NewMemcachedCache(opts)
+	}
+
+	maxBytes := defaultLRUMaxBytes
+	if c.LRU != nil {
+		maxBytes = c.LRU.MaxBytes
+	}
+	return NewLRUCache(maxBytes)
+}
+
+// NewResultsCache returns a results cache using the configured backend.
+func (c ResultsConfiguration) NewResultsCache(
+	tagOpts models.TagOptions,
+	scope tally.Scope,
+) (*ResultsCache, error) {
+	backend, err := c.NewCache()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := ResultsOptions{
+		Cache:         backend,
+		SplitInterval: defaultSplitInterval,
+		BufferPast:    defaultBufferPast,
+		TTL:           defaultResultsTTL,
+		TagOptions:    tagOpts,
+		Scope:         scope,
+	}
+	if c.SplitInterval != nil {
+		opts.SplitInterval = *c.SplitInterval
+	}
+	if c.BufferPast != nil {
+		opts.BufferPast = *c.BufferPast
+	}
+	if c.TTL != nil {
+		opts.TTL = *c.TTL
+	}
+
+	return NewResultsCache(opts)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// lruCache is an in-process cache evicting the least recently used values
+// once the total size of the cached values exceeds the max size.
+type lruCache struct {
+	sync.Mutex
+
+	maxBytes  int
+	bytes     int
+	nowFn     func() time.Time
+	evictList *list.List
+	items     map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+// NewLRUCache returns an in-process LRU cache holding at most maxBytes of
+// keys and values.
+func NewLRUCache(maxBytes int) (Cache, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("must provide a positive size, instead got: %d", maxBytes)
+	}
+
+	return &lruCache{
+		maxBytes:  maxBytes,
+		nowFn:     time.Now,
+		evictList: list.New(),
+		items:     make(map[string]*list.Element),
+	}, nil
+}
+
+func (c *lruCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	ent := elem.Value.(*lruEntry)
+	if !ent.expireAt.IsZero() && !c.nowFn().Before(ent.expireAt) {
+		c.removeElement(elem)
+		return nil, false, nil
+	}
+
+	c.evictList.MoveToFront(elem)
+	return ent.value, true, nil
+}
+
+func (c *lruCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	size := len(key) + len(value)
+	if size > c.maxBytes {
+		// Never cache values which would evict everything else.
+		return nil
+	}
+
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = c.nowFn().Add(ttl)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+
+	c.items[key] = c.evictList.PushFront(&lruEntry{
+		key:      key,
+		value:    value,
+		expireAt: expireAt,
+	})
+	c.bytes += size
+
+	for c.bytes > c.maxBytes {
+		c.removeElement(c.evictList.Back())
+	}
+
+	return nil
+}
+
+func (c *lruCache) Close() error {
+	c.Lock()
+	defer c.Unlock()
+
+	c.evictList.Init()
+	c.items = make(map[string]*list.Element)
+	c.bytes = 0
+	return nil
+}
+
+func (c *lruCache) removeElement(elem *list.Element) {
+	ent := elem.Value.(*lruEntry)
+	c.evictList.Remove(elem)
+	delete(c.items, ent.key)
+	c.bytes -= len(ent.key) + len(ent.value)
+}
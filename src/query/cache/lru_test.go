@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewLRUCache(10)
+	require.NoError(t, err)
+
+	// Each entry is 4 bytes, so only two fit.
+	require.NoError(t, c.Set(ctx, "a", []byte("aaa"), 0))
+	require.NoError(t, c.Set(ctx, "b", []byte("bbb"), 0))
+
+	// Touch a so that b is evicted.
+	_, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(ctx, "c", []byte("ccc"), 0))
+
+	value, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []byte("aaa"), value)
+
+	_, ok, err = c.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = c.Get(ctx, "c")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewLRUCache(100)
+	require.NoError(t, err)
+
+	now := time.Now()
+	c.(*lruCache).nowFn = func() time.Time { return now }
+	require.NoError(t, c.Set(ctx, "a", []byte("a"), time.Minute))
+
+	_, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok, err = c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.(*lruCache).bytes)
+}
+
+func TestLRUCacheSkipsOversizedValues(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewLRUCache(4)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("aaaa"), 0))
+	_, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = NewLRUCache(0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,336 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxMemcachedKeyLen is the maximum length of a memcached key.
+	maxMemcachedKeyLen = 250
+
+	// maxMemcachedRelativeExpiry is the largest expiry memcached treats as
+	// relative, larger expiries are treated as unix timestamps.
+	maxMemcachedRelativeExpiry = 30 * 24 * time.Hour
+
+	defaultMemcachedTimeout      = 100 * time.Millisecond
+	defaultMemcachedMaxIdleConns = 16
+)
+
+var (
+	errNoMemcachedServers = errors.New("memcached cache requires at least one server")
+
+	crlf      = []byte("\r\n")
+	respEnd   = []byte("END")
+	respValue = []byte("VALUE ")
+	respStore = []byte("STORED")
+)
+
+// MemcachedOptions are the options of a memcached cache.
+type MemcachedOptions struct {
+	// Servers are the addresses of the memcached servers, keys are
+	// distributed across servers by hash.
+	Servers []string
+	// Timeout is the timeout of a single cache operation.
+	Timeout time.Duration
+	// MaxIdleConns is the maximum number of idle connections per server.
+	MaxIdleConns int
+}
+
+type memcachedCache struct {
+	timeout time.Duration
+	pools   []*memcachedConnPool
+}
+
+// NewMemcachedCache returns a cache backed by memcached servers speaking
+// the memcached text protocol.
+func NewMemcachedCache(opts MemcachedOptions) (Cache, error) {
+	if len(opts.Servers) == 0 {
+		return nil, errNoMemcachedServers
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultMemcachedTimeout
+	}
+
+	if opts.MaxIdleConns <= 0 {
+		opts.MaxIdleConns = defaultMemcachedMaxIdleConns
+	}
+
+	pools := make([]*memcachedConnPool, 0, len(opts.Servers))
+	for _, server := range opts.Servers {
+		pools = append(pools, &memcachedConnPool{
+			address: server,
+			timeout: opts.Timeout,
+			idle:    make(chan *memcachedConn, opts.MaxIdleConns),
+		})
+	}
+
+	return &memcachedCache{
+		timeout: opts.Timeout,
+		pools:   pools,
+	}, nil
+}
+
+func (c *memcachedCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	key = memcachedKey(key)
+	var (
+		value []byte
+		found bool
+	)
+	err := c.do(ctx, key, func(conn *memcachedConn) error {
+		if _, err := fmt.Fprintf(conn.rw, "get %s\r\n", key); err != nil {
+			return err
+		}
+
+		if err := conn.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := conn.readLine()
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(line, respEnd) {
+			return nil
+		}
+
+		if !bytes.HasPrefix(line, respValue) {
+			return memcachedError(line)
+		}
+
+		// VALUE <key> <flags> <bytes>
+		fields := bytes.Fields(line)
+		if len(fields) < 4 {
+			return fmt.Errorf("malformed memcached response: %q", line)
+		}
+
+		size, err := strconv.Atoi(string(fields[3]))
+		if err != nil {
+			return fmt.Errorf("malformed memcached value size: %q", line)
+		}
+
+		data := make([]byte, size+len(crlf))
+		if _, err := io.ReadFull(conn.rw, data); err != nil {
+			return err
+		}
+
+		if !bytes.HasSuffix(data, crlf) {
+			return fmt.Errorf("malformed memcached value for key %s", key)
+		}
+
+		line, err = conn.readLine()
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(line, respEnd) {
+			return memcachedError(line)
+		}
+
+		value, found = data[:size], true
+		return nil
+	})
+
+	return value, found, err
+}
+
+func (c *memcachedCache) Set(
+	ctx context.Context,
+	key string,
+	value []byte,
+	ttl time.Duration,
+) error {
+	key = memcachedKey(key)
+	var expiry int64
+	if ttl > maxMemcachedRelativeExpiry {
+		expiry = time.Now().Add(ttl).Unix()
+	} else if ttl > 0 {
+		expiry = int64(ttl / time.Second)
+		if expiry == 0 {
+			expiry = 1
+		}
+	}
+
+	return c.do(ctx, key, func(conn *memcachedConn) error {
+		if _, err := fmt.Fprintf(conn.rw, "set %s 0 %d %d\r\n", key, expiry, len(value)); err != nil {
+			return err
+		}
+
+		if _, err := conn.rw.Write(value); err != nil {
+			return err
+		}
+
+		if _, err := conn.rw.Write(crlf); err != nil {
+			return err
+		}
+
+		if err := conn.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := conn.readLine()
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(line, respStore) {
+			return memcachedError(line)
+		}
+
+		return nil
+	})
+}
+
+func (c *memcachedCache) Close() error {
+	for _, pool := range c.pools {
+		pool.close()
+	}
+	return nil
+}
+
+// do runs the operation on a connection to the server owning the key, the
+// connection is only reused if the operation succeeds.
+func (c *memcachedCache) do(
+	ctx context.Context,
+	key string,
+	fn func(conn *memcachedConn) error,
+) error {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	pool := c.pools[int(h.Sum32()%uint32(len(c.pools)))]
+
+	conn, err := pool.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	if err := conn.conn.SetDeadline(deadline); err != nil {
+		conn.conn.Close()
+		return err
+	}
+
+	if err := fn(conn); err != nil {
+		conn.conn.Close()
+		return err
+	}
+
+	pool.put(conn)
+	return nil
+}
+
+// memcachedKey returns a key valid for memcached, keys which are too long
+// or contain whitespace or control characters are hashed.
+func memcachedKey(key string) string {
+	valid := len(key) <= maxMemcachedKeyLen
+	for i := 0; valid && i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			valid = false
+		}
+	}
+
+	if valid {
+		return key
+	}
+
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func memcachedError(line []byte) error {
+	return fmt.Errorf("memcached error: %s", line)
+}
+
+type memcachedConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+func (c *memcachedConn) readLine() ([]byte, error) {
+	line, err := c.rw.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(line, crlf), nil
+}
+
+type memcachedConnPool struct {
+	address string
+	timeout time.Duration
+	idle    chan *memcachedConn
+}
+
+func (p *memcachedConnPool) get(ctx context.Context) (*memcachedConn, error) {
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &memcachedConn{
+		conn: conn,
+		rw:   bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)),
+	}, nil
+}
+
+func (p *memcachedConnPool) put(conn *memcachedConn) {
+	select {
+	case p.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+func (p *memcachedConnPool) close() {
+	for {
+		select {
+		case conn := <-p.idle:
+			conn.conn.Close()
+		default:
+			return
+		}
+	}
+}
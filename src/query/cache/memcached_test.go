@@ -0,0 +1,196 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memcachedStub is a minimal in-memory server speaking the get and set
+// commands of the memcached text protocol.
+type memcachedStub struct {
+	sync.Mutex
+
+	listener net.Listener
+	values   map[string][]byte
+	expiries map[string]int64
+}
+
+func newMemcachedStub(t *testing.T) *memcachedStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &memcachedStub{
+		listener: listener,
+		values:   make(map[string][]byte),
+		expiries: make(map[string]int64),
+	}
+	go s.serve()
+	return s
+}
+
+func (s *memcachedStub) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *memcachedStub) close() {
+	s.listener.Close()
+}
+
+func (s *memcachedStub) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *memcachedStub) handle(conn net.Conn) {
+	defer conn.Close()
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "get":
+			s.Lock()
+			value, ok := s.values[fields[1]]
+			s.Unlock()
+			if ok {
+				fmt.Fprintf(rw, "VALUE %s 0 %d\r\n%s\r\n", fields[1], len(value), value)
+			}
+			fmt.Fprint(rw, "END\r\n")
+		case "set":
+			size, _ := strconv.Atoi(fields[4])
+			expiry, _ := strconv.ParseInt(fields[3], 10, 64)
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(rw, data); err != nil {
+				return
+			}
+
+			s.Lock()
+			s.values[fields[1]] = data[:size]
+			s.expiries[fields[1]] = expiry
+			s.Unlock()
+			fmt.Fprint(rw, "STORED\r\n")
+		default:
+			fmt.Fprint(rw, "ERROR\r\n")
+		}
+
+		if err := rw.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func TestMemcachedCacheGetSet(t *testing.T) {
+	stub := newMemcachedStub(t)
+	defer stub.close()
+
+	c, err := NewMemcachedCache(MemcachedOptions{
+		Servers: []string{stub.addr()},
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	_, ok, err := c.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	value := []byte("value\r\nwith\r\nnewlines")
+	require.NoError(t, c.Set(ctx, "key", value, time.Minute))
+
+	result, ok, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, value, result)
+
+	stub.Lock()
+	assert.Equal(t, int64(60), stub.expiries["key"])
+	stub.Unlock()
+}
+
+func TestMemcachedCacheHashesInvalidKeys(t *testing.T) {
+	stub := newMemcachedStub(t)
+	defer stub.close()
+
+	c, err := NewMemcachedCache(MemcachedOptions{Servers: []string{stub.addr()}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	key := "sum(rate(foo[5m])) by (bar)" + strings.Repeat("x", maxMemcachedKeyLen)
+	require.NoError(t, c.Set(ctx, key, []byte("a"), 0))
+
+	result, ok, err := c.Get(ctx, key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []byte("a"), result)
+
+	stub.Lock()
+	for stored := range stub.values {
+		assert.Equal(t, memcachedKey(key), stored)
+		assert.Len(t, stored, 40)
+	}
+	stub.Unlock()
+}
+
+func TestMemcachedCacheUnreachable(t *testing.T) {
+	stub := newMemcachedStub(t)
+	addr := stub.addr()
+	stub.close()
+
+	c, err := NewMemcachedCache(MemcachedOptions{Servers: []string{addr}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, _, err = c.Get(context.Background(), "key")
+	assert.Error(t, err)
+
+	_, err = NewMemcachedCache(MemcachedOptions{})
+	assert.Equal(t, errNoMemcachedServers, err)
+}
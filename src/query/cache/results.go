@@ -0,0 +1,443 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	defaultSplitInterval = 24 * time.Hour
+	defaultBufferPast    = 10 * time.Minute
+	defaultResultsTTL    = 7 * 24 * time.Hour
+
+	resultsKeyPrefix = "m3query_range"
+)
+
+var (
+	errNoCache              = errors.New("results cache requires a cache backend")
+	errNoTagOptions         = errors.New("results cache requires tag options")
+	errInvalidSplitInterval = errors.New("results cache split interval must be positive")
+)
+
+// ReadFn executes a range query.
+type ReadFn func(ctx context.Context, params models.RequestParams) ([]*ts.Series, error)
+
+// ResultsOptions are the options of a results cache.
+type ResultsOptions struct {
+	// Cache is the cache backend.
+	Cache Cache
+	// SplitInterval is the interval ranges are split into, only whole
+	// intervals are cached.
+	SplitInterval time.Duration
+	// BufferPast is how far in the past an interval must end to be
+	// considered immutable and so cacheable.
+	BufferPast time.Duration
+	// TTL is how long cached intervals are kept.
+	TTL time.Duration
+	// TagOptions are the tag options of cached series.
+	TagOptions models.TagOptions
+	// Scope is the metrics scope.
+	Scope tally.Scope
+	// NowFn returns the current time.
+	NowFn func() time.Time
+}
+
+// Validate validates the results options.
+func (o ResultsOptions) Validate() error {
+	if o.Cache == nil {
+		return errNoCache
+	}
+	if o.TagOptions == nil {
+		return errNoTagOptions
+	}
+	if o.SplitInterval <= 0 {
+		return errInvalidSplitInterval
+	}
+	return nil
+}
+
+type resultsMetrics struct {
+	hits       tally.Counter
+	misses     tally.Counter
+	bypassed   tally.Counter
+	errors     tally.Counter
+	executions tally.Counter
+}
+
+func newResultsMetrics(scope tally.Scope) resultsMetrics {
+	return resultsMetrics{
+		hits:       scope.Counter("hits"),
+		misses:     scope.Counter("misses"),
+		bypassed:   scope.Counter("bypassed"),
+		errors:     scope.Counter("errors"),
+		executions: scope.Counter("executions"),
+	}
+}
+
+// ResultsCache caches range query results. Ranges are split into step
+// aligned intervals, intervals which ended longer than the buffer past ago
+// are immutable and cached keyed by the normalized query and step, so only
+// the missing intervals and the recent tail of a range are executed.
+type ResultsCache struct {
+	opts    ResultsOptions
+	metrics resultsMetrics
+}
+
+// NewResultsCache returns a new results cache.
+func NewResultsCache(opts ResultsOptions) (*ResultsCache, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.Scope == nil {
+		opts.Scope = tally.NoopScope
+	}
+
+	if opts.NowFn == nil {
+		opts.NowFn = time.Now
+	}
+
+	return &ResultsCache{
+		opts:    opts,
+		metrics: newResultsMetrics(opts.Scope),
+	}, nil
+}
+
+// interval is a split interval of a range query.
+type interval struct {
+	start     time.Time
+	end       time.Time
+	cacheable bool
+	key       string
+	cached    *cachedResult
+}
+
+// Read returns the results of the range query, reading immutable intervals
+// from the cache and executing the rest with the read function.
+func (c *ResultsCache) Read(
+	ctx context.Context,
+	params models.RequestParams,
+	read ReadFn,
+) ([]*ts.Series, error) {
+	query, ok := c.normalizedQuery(params)
+	if !ok {
+		c.metrics.bypassed.Inc(1)
+		return read(ctx, params)
+	}
+
+	var (
+		step      = params.Step
+		end       = params.ExclusiveEnd()
+		cutoff    = c.opts.NowFn().Add(-c.opts.BufferPast)
+		intervals []*interval
+	)
+	for start := alignDown(params.Start, c.opts.SplitInterval); start.Before(end); {
+		next := start.Add(c.opts.SplitInterval)
+		iv := &interval{
+			start:     start,
+			end:       next,
+			cacheable: !next.After(cutoff),
+		}
+		if iv.cacheable {
+			iv.key = fmt.Sprintf("%s:%s:%d:%d:%d", resultsKeyPrefix, query,
+				int64(step), int64(c.opts.SplitInterval), start.UnixNano())
+			iv.cached = c.get(ctx, iv.key)
+		}
+
+		intervals = append(intervals, iv)
+		start = next
+	}
+
+	merger := newSeriesMerger(params.Start, end, step)
+	for i := 0; i < len(intervals); {
+		iv := intervals[i]
+		if iv.cached != nil {
+			c.metrics.hits.Inc(1)
+			merger.addCached(iv.cached, c.opts.TagOptions)
+			i++
+			continue
+		}
+
+		// Execute consecutive missing intervals with a single query.
+		j := i
+		for j < len(intervals) && intervals[j].cached == nil {
+			if intervals[j].cacheable {
+				c.metrics.misses.Inc(1)
+			}
+			j++
+		}
+
+		run := intervals[i:j]
+		runParams := params
+		runParams.IncludeEnd = false
+		runParams.Start = run[0].start
+		if !run[0].cacheable && runParams.Start.Before(params.Start) {
+			runParams.Start = params.Start
+		}
+
+		last := run[len(run)-1]
+		if last.cacheable {
+			runParams.End = last.end
+		} else {
+			runParams.End = params.End
+			runParams.IncludeEnd = params.IncludeEnd
+		}
+
+		c.metrics.executions.Inc(1)
+		result, err := read(ctx, runParams)
+		if err != nil {
+			return nil, err
+		}
+
+		merger.addSeries(result)
+		for _, iv := range run {
+			if iv.cacheable {
+				c.set(ctx, iv.key, newCachedResult(result, iv.start, iv.end, step))
+			}
+		}
+
+		i = j
+	}
+
+	return merger.seriesList(), nil
+}
+
+// normalizedQuery returns the normalized query if the request can be
+// served from the cache.
+func (c *ResultsCache) normalizedQuery(params models.RequestParams) (string, bool) {
+	step := params.Step
+	if step <= 0 || c.opts.SplitInterval%step != 0 ||
+		params.Start.UnixNano()%int64(step) != 0 {
+		return "", false
+	}
+
+	parser, err := promql.Parse(params.Query, c.opts.TagOptions)
+	if err != nil {
+		return "", false
+	}
+
+	return parser.String(), true
+}
+
+func (c *ResultsCache) get(ctx context.Context, key string) *cachedResult {
+	b, ok, err := c.opts.Cache.Get(ctx, key)
+	if err != nil {
+		c.metrics.errors.Inc(1)
+		return nil
+	}
+
+	if !ok {
+		return nil
+	}
+
+	var result cachedResult
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&result); err != nil {
+		c.metrics.errors.Inc(1)
+		return nil
+	}
+
+	return &result
+}
+
+func (c *ResultsCache) set(ctx context.Context, key string, result cachedResult) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(result); err != nil {
+		c.metrics.errors.Inc(1)
+		return
+	}
+
+	if err := c.opts.Cache.Set(ctx, key, buf.Bytes(), c.opts.TTL); err != nil {
+		c.metrics.errors.Inc(1)
+	}
+}
+
+// cachedResult is the cached result of a single interval, values are
+// stored for every step of the interval.
+type cachedResult struct {
+	Start  int64
+	Step   int64
+	Series []cachedSeries
+}
+
+type cachedSeries struct {
+	Name   []byte
+	Tags   []cachedTag
+	Values []float64
+}
+
+type cachedTag struct {
+	Name  []byte
+	Value []byte
+}
+
+func newCachedResult(
+	seriesList []*ts.Series,
+	start time.Time,
+	end time.Time,
+	step time.Duration,
+) cachedResult {
+	result := cachedResult{
+		Start: start.UnixNano(),
+		Step:  int64(step),
+	}
+
+	numSteps := int(end.Sub(start) / step)
+	for _, series := range seriesList {
+		var (
+			values = series.Values()
+			cached = cachedSeries{Name: series.Name()}
+			found  = false
+		)
+		for i := 0; i < values.Len(); i++ {
+			dp := values.DatapointAt(i)
+			if math.IsNaN(dp.Value) || dp.Timestamp.Before(start) || !dp.Timestamp.Before(end) {
+				continue
+			}
+
+			if !found {
+				found = true
+				cached.Values = make([]float64, numSteps)
+				for j := range cached.Values {
+					cached.Values[j] = math.NaN()
+				}
+			}
+
+			cached.Values[int(dp.Timestamp.Sub(start)/step)] = dp.Value
+		}
+
+		if !found {
+			continue
+		}
+
+		cached.Tags = make([]cachedTag, 0, len(series.Tags.Tags))
+		for _, tag := range series.Tags.Tags {
+			cached.Tags = append(cached.Tags, cachedTag{Name: tag.Name, Value: tag.Value})
+		}
+
+		result.Series = append(result.Series, cached)
+	}
+
+	return result
+}
+
+// seriesMerger merges partial series results onto the step grid of the
+// requested range.
+type seriesMerger struct {
+	start    time.Time
+	step     time.Duration
+	numSteps int
+	order    []string
+	series   map[string]*ts.Series
+	values   map[string]ts.FixedResolutionMutableValues
+}
+
+func newSeriesMerger(start, end time.Time, step time.Duration) *seriesMerger {
+	return &seriesMerger{
+		start:    start,
+		step:     step,
+		numSteps: int(end.Sub(start) / step),
+		series:   make(map[string]*ts.Series),
+		values:   make(map[string]ts.FixedResolutionMutableValues),
+	}
+}
+
+func (m *seriesMerger) valuesFor(name []byte, tags models.Tags) ts.FixedResolutionMutableValues {
+	id := string(tags.ID())
+	if values, ok := m.values[id]; ok {
+		return values
+	}
+
+	values := ts.NewFixedStepValues(m.step, m.numSteps, math.NaN(), m.start)
+	m.order = append(m.order, id)
+	m.values[id] = values
+	m.series[id] = ts.NewSeries(name, values, tags)
+	return values
+}
+
+func (m *seriesMerger) set(values ts.FixedResolutionMutableValues, t time.Time, v float64) {
+	if t.Before(m.start) {
+		return
+	}
+
+	idx := int(t.Sub(m.start) / m.step)
+	if idx < m.numSteps {
+		values.SetValueAt(idx, v)
+	}
+}
+
+func (m *seriesMerger) addSeries(seriesList []*ts.Series) {
+	for _, series := range seriesList {
+		values := m.valuesFor(series.Name(), series.Tags)
+		dps := series.Values()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.DatapointAt(i)
+			if !math.IsNaN(dp.Value) {
+				m.set(values, dp.Timestamp, dp.Value)
+			}
+		}
+	}
+}
+
+func (m *seriesMerger) addCached(result *cachedResult, tagOpts models.TagOptions) {
+	var (
+		start = time.Unix(0, result.Start)
+		step  = time.Duration(result.Step)
+	)
+	for _, series := range result.Series {
+		tags := models.NewTags(len(series.Tags), tagOpts)
+		for _, tag := range series.Tags {
+			tags = tags.AddTagWithoutNormalizing(models.Tag{Name: tag.Name, Value: tag.Value})
+		}
+
+		values := m.valuesFor(series.Name, tags)
+		for i, v := range series.Values {
+			if !math.IsNaN(v) {
+				m.set(values, start.Add(time.Duration(i)*step), v)
+			}
+		}
+	}
+}
+
+func (m *seriesMerger) seriesList() []*ts.Series {
+	result := make([]*ts.Series, 0, len(m.order))
+	for _, id := range m.order {
+		result = append(result, m.series[id])
+	}
+	return result
+}
+
+func alignDown(t time.Time, d time.Duration) time.Time {
+	nanos := t.UnixNano()
+	return time.Unix(0, nanos-nanos%int64(d))
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testReader struct {
+	tagOpts models.TagOptions
+	calls   []models.RequestParams
+}
+
+// read returns a single series whose values are the unix timestamps of
+// each step.
+func (r *testReader) read(_ context.Context, params models.RequestParams) ([]*ts.Series, error) {
+	r.calls = append(r.calls, params)
+	numSteps := int(params.ExclusiveEnd().Sub(params.Start) / params.Step)
+	values := ts.NewFixedStepValues(params.Step, numSteps, 0, params.Start)
+	for i := 0; i < numSteps; i++ {
+		values.SetValueAt(i, float64(params.Start.Add(time.Duration(i)*params.Step).Unix()))
+	}
+
+	tags := models.NewTags(2, r.tagOpts).
+		AddTag(models.Tag{Name: []byte("__name__"), Value: []byte("foo")}).
+		AddTag(models.Tag{Name: []byte("job"), Value: []byte("api")})
+	return []*ts.Series{ts.NewSeries([]byte("foo"), values, tags)}, nil
+}
+
+func newTestResultsCache(t *testing.T, now time.Time) *ResultsCache {
+	backend, err := NewLRUCache(1024 * 1024)
+	require.NoError(t, err)
+
+	c, err := NewResultsCache(ResultsOptions{
+		Cache:         backend,
+		SplitInterval: time.Hour,
+		BufferPast:    10 * time.Minute,
+		TagOptions:    models.NewTagOptions(),
+		NowFn:         func() time.Time { return now },
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func assertTimestampValues(t *testing.T, params models.RequestParams, series []*ts.Series) {
+	require.Len(t, series, 1)
+	values := series[0].Values()
+	numSteps := int(params.ExclusiveEnd().Sub(params.Start) / params.Step)
+	require.Equal(t, numSteps, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		dp := values.DatapointAt(i)
+		assert.Equal(t, float64(dp.Timestamp.Unix()), dp.Value)
+	}
+
+	assert.Equal(t, params.Start, values.DatapointAt(0).Timestamp)
+	job, ok := series[0].Tags.Get([]byte("job"))
+	require.True(t, ok)
+	assert.Equal(t, "api", string(job))
+}
+
+func TestResultsCacheExecutesOnlyTail(t *testing.T) {
+	var (
+		now    = time.Date(2019, 1, 1, 12, 30, 0, 0, time.UTC)
+		c      = newTestResultsCache(t, now)
+		reader = &testReader{tagOpts: models.NewTagOptions()}
+		ctx    = context.Background()
+		params = models.RequestParams{
+			Query:      "sum(rate(foo[1m]))  by (job)",
+			Start:      time.Date(2019, 1, 1, 9, 0, 0, 0, time.UTC),
+			End:        now,
+			Step:       time.Minute,
+			IncludeEnd: true,
+		}
+	)
+
+	result, err := c.Read(ctx, params, reader.read)
+	require.NoError(t, err)
+	assertTimestampValues(t, params, result)
+	require.Len(t, reader.calls, 1)
+	assert.True(t, params.Start.Equal(reader.calls[0].Start))
+
+	// The second read only executes the interval which is not yet immutable,
+	// differently formatted queries share cached intervals.
+	params.Query = "sum(rate(foo[1m])) by (job)"
+	result, err = c.Read(ctx, params, reader.read)
+	require.NoError(t, err)
+	assertTimestampValues(t, params, result)
+	require.Len(t, reader.calls, 2)
+
+	tail := reader.calls[1]
+	assert.True(t, time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC).Equal(tail.Start))
+	assert.True(t, now.Equal(tail.End))
+	assert.True(t, tail.IncludeEnd)
+}
+
+func TestResultsCachePartialInterval(t *testing.T) {
+	var (
+		now    = time.Date(2019, 1, 1, 12, 30, 0, 0, time.UTC)
+		c      = newTestResultsCache(t, now)
+		reader = &testReader{tagOpts: models.NewTagOptions()}
+		ctx    = context.Background()
+		params = models.RequestParams{
+			Query: "foo",
+			Start: time.Date(2019, 1, 1, 9, 30, 0, 0, time.UTC),
+			End:   time.Date(2019, 1, 1, 10, 30, 0, 0, time.UTC),
+			Step:  time.Minute,
+		}
+	)
+
+	// Whole intervals are executed so they can be cached.
+	result, err := c.Read(ctx, params, reader.read)
+	require.NoError(t, err)
+	assertTimestampValues(t, params, result)
+	require.Len(t, reader.calls, 1)
+	assert.True(t, time.Date(2019, 1, 1, 9, 0, 0, 0, time.UTC).Equal(reader.calls[0].Start))
+	assert.True(t, time.Date(2019, 1, 1, 11, 0, 0, 0, time.UTC).Equal(reader.calls[0].End))
+	assert.False(t, reader.calls[0].IncludeEnd)
+
+	// A request inside the cached intervals is served from the cache.
+	params.Start = time.Date(2019, 1, 1, 9, 45, 0, 0, time.UTC)
+	result, err = c.Read(ctx, params, reader.read)
+	require.NoError(t, err)
+	assertTimestampValues(t, params, result)
+	assert.Len(t, reader.calls, 1)
+}
+
+func TestResultsCacheBypassesUnalignedRequests(t *testing.T) {
+	var (
+		now    = time.Date(2019, 1, 1, 12, 30, 0, 0, time.UTC)
+		c      = newTestResultsCache(t, now)
+		reader = &testReader{tagOpts: models.NewTagOptions()}
+		params = models.RequestParams{
+			Query: "foo",
+			Start: time.Date(2019, 1, 1, 9, 0, 30, 0, time.UTC),
+			End:   time.Date(2019, 1, 1, 10, 0, 30, 0, time.UTC),
+			Step:  time.Minute,
+		}
+	)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Read(context.Background(), params, reader.read)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, reader.calls, 2)
+	assert.Equal(t, params, reader.calls[1])
+}
+
+func TestResultsConfigurationNewCache(t *testing.T) {
+	cfg := ResultsConfiguration{}
+	backend, err := cfg.NewCache()
+	require.NoError(t, err)
+	assert.IsType(t, &lruCache{}, backend)
+
+	cfg = ResultsConfiguration{Memcached: &MemcachedConfiguration{Servers: []string{"localhost:11211"}}}
+	backend, err = cfg.NewCache()
+	require.NoError(t, err)
+	assert.IsType(t, &memcachedCache{}, backend)
+
+	cfg.LRU = &LRUConfiguration{MaxBytes: 1}
+	_, err = cfg.NewCache()
+	assert.Equal(t, errMultipleBackends, err)
+}
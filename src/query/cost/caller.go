@@ -0,0 +1,107 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cost
+
+import (
+	"context"
+	"sync"
+
+	"github.com/m3db/m3/src/x/cost"
+)
+
+type callerEnforcerKeyType int
+
+const callerEnforcerKey callerEnforcerKeyType = iota
+
+// NewContextWithCallerEnforcer returns a context carrying an enforcer which
+// accounts the cost of all in flight queries of a single caller.
+func NewContextWithCallerEnforcer(
+	ctx context.Context,
+	enforcer cost.Enforcer,
+) context.Context {
+	return context.WithValue(ctx, callerEnforcerKey, enforcer)
+}
+
+// WithCallerEnforcer returns a per query enforcer which additionally accounts
+// its cost against the caller enforcer carried by the context, if any. The
+// cost is released from the caller enforcer once the query enforcer is closed.
+func WithCallerEnforcer(
+	ctx context.Context,
+	enforcer ChainedEnforcer,
+) ChainedEnforcer {
+	if ctx == nil {
+		return enforcer
+	}
+
+	caller, ok := ctx.Value(callerEnforcerKey).(cost.Enforcer)
+	if !ok || caller == nil {
+		return enforcer
+	}
+
+	return &callerEnforcer{
+		ChainedEnforcer: enforcer,
+		caller:          caller,
+	}
+}
+
+type callerEnforcer struct {
+	ChainedEnforcer
+
+	caller cost.Enforcer
+
+	mu          sync.Mutex
+	outstanding cost.Cost
+}
+
+func (e *callerEnforcer) Add(c cost.Cost) cost.Report {
+	r := e.ChainedEnforcer.Add(c)
+
+	e.mu.Lock()
+	e.outstanding += c
+	e.mu.Unlock()
+
+	callerR := e.caller.Add(c)
+	if r.Error == nil && callerR.Error != nil {
+		r.Error = callerR.Error
+	}
+
+	return r
+}
+
+func (e *callerEnforcer) Child(resourceName string) ChainedEnforcer {
+	return &callerEnforcer{
+		ChainedEnforcer: e.ChainedEnforcer.Child(resourceName),
+		caller:          e.caller,
+	}
+}
+
+func (e *callerEnforcer) Close() {
+	e.mu.Lock()
+	released := e.outstanding
+	e.outstanding = 0
+	e.mu.Unlock()
+
+	if released != 0 {
+		e.caller.Add(-released)
+	}
+
+	e.ChainedEnforcer.Close()
+}
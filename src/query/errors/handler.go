@@ -42,4 +42,6 @@ var (
 	ErrNamesOnly = errors.New("can not render label values; result has label names only")
 	// ErrMultipleResults is returned when there are multiple label values results
 	ErrMultipleResults = errors.New("can not render label values; multiple results detected")
+	// ErrWithNames is returned when label names results contain label values
+	ErrWithNames = errors.New("can not render label names; result has label values")
 )
@@ -27,7 +27,9 @@ import (
 	qcost "github.com/m3db/m3/src/query/cost"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/stats"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 	"github.com/m3db/m3/src/query/util/opentracing"
 
 	"github.com/uber-go/tally"
@@ -40,6 +42,7 @@ type Engine struct {
 	globalEnforcer   qcost.ChainedEnforcer
 	store            storage.Storage
 	lookbackDuration time.Duration
+	timeSharding     *timeshard.Options
 }
 
 // EngineOptions can be used to pass custom flags to engine
@@ -66,6 +69,20 @@ func NewEngine(store storage.Storage, scope tally.Scope, lookbackDuration time.D
 	}
 }
 
+// NewEngineWithTimeSharding returns a new instance of QueryExecutor which
+// splits long range fetches into concurrently fetched time shards.
+func NewEngineWithTimeSharding(
+	store storage.Storage,
+	scope tally.Scope,
+	lookbackDuration time.Duration,
+	factory qcost.ChainedEnforcer,
+	timeSharding *timeshard.Options,
+) *Engine {
+	engine := NewEngine(store, scope, lookbackDuration, factory)
+	engine.timeSharding = timeSharding
+	return engine
+}
+
 type engineMetrics struct {
 	all       *counterWithDecrement
 	compiling *counterWithDecrement
@@ -120,8 +137,9 @@ func (e *Engine) Execute(
 	results chan *storage.QueryResult,
 ) {
 	defer close(results)
-	fetchOpts := storage.NewFetchOptions()
-	fetchOpts.Limit = 0
+	fetchOpts, closer := e.NewFetchOptions(ctx)
+	defer closer()
+
 	result, err := e.store.Fetch(ctx, query, fetchOpts)
 	if err != nil {
 		results <- &storage.QueryResult{Err: err}
@@ -131,6 +149,18 @@ func (e *Engine) Execute(
 	results <- &storage.QueryResult{FetchResult: result}
 }
 
+// NewFetchOptions returns the options for fetching a single query directly
+// from storage, enforcing the engine's limits and those of the caller carried
+// by the context. The returned closer releases the cost of the query.
+func (e *Engine) NewFetchOptions(ctx context.Context) (*storage.FetchOptions, func()) {
+	perQueryEnforcer := qcost.WithCallerEnforcer(ctx,
+		e.globalEnforcer.Child(qcost.QueryLevel))
+	fetchOpts := storage.NewFetchOptions()
+	fetchOpts.Enforcer = stats.WrapEnforcer(ctx, perQueryEnforcer)
+	fetchOpts.Scope = e.costScope
+	return fetchOpts, perQueryEnforcer.Close
+}
+
 // ExecuteExpr runs the query DAG and closes the results channel once done
 // nolint: unparam
 func (e *Engine) ExecuteExpr(
@@ -142,8 +172,10 @@ func (e *Engine) ExecuteExpr(
 ) {
 	defer close(results)
 
-	perQueryEnforcer := e.globalEnforcer.Child(qcost.QueryLevel)
+	perQueryEnforcer := qcost.WithCallerEnforcer(ctx,
+		e.globalEnforcer.Child(qcost.QueryLevel))
 	defer perQueryEnforcer.Close()
+	enforcer := stats.WrapEnforcer(ctx, perQueryEnforcer)
 	req := newRequest(e, params)
 
 	nodes, edges, err := req.compile(ctx, parser)
@@ -171,7 +203,7 @@ func (e *Engine) ExecuteExpr(
 	result := state.resultNode
 	results <- Query{Result: result}
 
-	if err := state.Execute(models.NewQueryContext(ctx, e.costScope, perQueryEnforcer)); err != nil {
+	if err := state.Execute(models.NewQueryContext(ctx, e.costScope, enforcer)); err != nil {
 		result.abort(err)
 	} else {
 		result.done()
@@ -0,0 +1,493 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/functions/utils"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/prometheus/prometheus/model/labels"
+	pql "github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+// The PromQL compatibility tests evaluate the test files in testdata, which
+// are taken from the PromQL test files of Prometheus and use their format:
+//
+//   load <interval>
+//     <series> <values>...
+//   clear
+//   eval[_fail|_ordered] instant at <time> <query>
+//     <series> <value>
+//
+// M3 does not distinguish NaN samples from missing ones, so series with a NaN
+// value are only compared when the test file expects them, and it keeps the
+// metric name of function results, so metric names are not compared.
+
+const (
+	promqlTestLookback = 5 * time.Minute
+	promqlTestEpsilon  = 0.000001
+)
+
+var (
+	promqlTestStart = time.Unix(0, 0)
+
+	patSpace       = regexp.MustCompile(`[\t ]+`)
+	patLoad        = regexp.MustCompile(`^load\s+(.+?)$`)
+	patEvalInstant = regexp.MustCompile(`^eval(?:_(fail|ordered))?\s+instant\s+(?:at\s+(.+?))?\s+(.+)$`)
+)
+
+func TestPromQLCompatibility(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.test"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+			runPromQLTest(t, string(content))
+		})
+	}
+}
+
+func runPromQLTest(t *testing.T, content string) {
+	var (
+		store  = newPromQLTestStorage()
+		engine = NewEngine(store, tally.NoopScope, promqlTestLookback, nil)
+		lines  = strings.Split(content, "\n")
+	)
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// The indented lines following a command belong to it.
+		start := i
+		var body []string
+		for i+1 < len(lines) && isPromQLTestBody(lines[i+1]) {
+			i++
+			body = append(body, strings.TrimSpace(lines[i]))
+		}
+
+		switch {
+		case line == "clear":
+			store.clear()
+
+		case patLoad.MatchString(line):
+			interval, err := time.ParseDuration(patLoad.FindStringSubmatch(line)[1])
+			require.NoError(t, err, "line %d", start+1)
+			for _, series := range body {
+				require.NoError(t, store.load(interval, series), "line %d", start+1)
+			}
+
+		case patEvalInstant.MatchString(line):
+			parts := patEvalInstant.FindStringSubmatch(line)
+			at, err := time.ParseDuration(parts[2])
+			require.NoError(t, err, "line %d", start+1)
+			t.Run(fmt.Sprintf("line %d: %s", start+1, parts[3]), func(t *testing.T) {
+				evalPromQLTest(t, engine, parts[3], promqlTestStart.Add(at),
+					parts[1] == "fail", body)
+			})
+
+		default:
+			t.Fatalf("line %d: unknown command: %s", start+1, line)
+		}
+	}
+}
+
+func isPromQLTestBody(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && trimmed != line && !strings.HasPrefix(trimmed, "#")
+}
+
+func evalPromQLTest(
+	t *testing.T,
+	engine *Engine,
+	query string,
+	at time.Time,
+	shouldFail bool,
+	expectedLines []string,
+) {
+	actual, err := evalPromQLInstant(engine, query, at)
+	if shouldFail {
+		require.Error(t, err)
+		return
+	}
+
+	require.NoError(t, err)
+	expected := make(map[string]float64, len(expectedLines))
+	for _, line := range expectedLines {
+		key, value, err := parsePromQLTestSample(line)
+		require.NoError(t, err)
+		expected[key] = value
+	}
+
+	for key, value := range actual {
+		if _, ok := expected[key]; !ok && math.IsNaN(value) {
+			delete(actual, key)
+		}
+	}
+
+	require.Equal(t, len(expected), len(actual),
+		"expected %v, got %v", expected, actual)
+	for key, value := range expected {
+		actualValue, ok := actual[key]
+		require.True(t, ok, "missing series %s in %v", key, actual)
+		if math.IsNaN(value) {
+			assert.True(t, math.IsNaN(actualValue), "series %s", key)
+		} else if value != actualValue {
+			assert.InEpsilon(t, value, actualValue, promqlTestEpsilon, "series %s", key)
+		}
+	}
+}
+
+// evalPromQLInstant evaluates an instant query, returning the value of each
+// resulting series at the instant keyed by its labels.
+func evalPromQLInstant(
+	engine *Engine,
+	query string,
+	at time.Time,
+) (map[string]float64, error) {
+	parser, err := promql.Parse(query, models.NewTagOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	params := models.RequestParams{
+		Start:      at,
+		End:        at,
+		Now:        at,
+		Step:       time.Second,
+		Query:      query,
+		Timeout:    time.Minute,
+		IncludeEnd: true,
+	}
+
+	results := make(chan Query)
+	go engine.ExecuteExpr(context.Background(), parser, &EngineOptions{}, params, results)
+
+	var (
+		values  = make(map[string]float64)
+		evalErr error
+	)
+
+	// Drain every result so that the execution is not blocked on errors.
+	for result := range results {
+		if result.Err != nil {
+			evalErr = result.Err
+			continue
+		}
+
+		for blockResult := range result.Result.ResultChan() {
+			if blockResult.Err != nil {
+				evalErr = blockResult.Err
+				continue
+			}
+
+			if err := addPromQLTestValues(values, blockResult.Block); err != nil {
+				evalErr = err
+			}
+
+			blockResult.Block.Close()
+		}
+	}
+
+	if evalErr != nil {
+		return nil, evalErr
+	}
+
+	return values, nil
+}
+
+func addPromQLTestValues(values map[string]float64, b block.Block) error {
+	iter, err := b.SeriesIter()
+	if err != nil {
+		return err
+	}
+
+	metas := utils.FlattenMetadata(iter.Meta(), iter.SeriesMeta())
+	for i := 0; iter.Next(); i++ {
+		series := iter.Current().Values()
+		if len(series) == 0 {
+			continue
+		}
+
+		key := promQLTestKey(metas[i].Tags)
+		if _, ok := values[key]; ok {
+			return fmt.Errorf("duplicate series in result: %s", key)
+		}
+
+		values[key] = series[len(series)-1]
+	}
+
+	return iter.Err()
+}
+
+// promQLTestKey formats tags like Prometheus does labels, without the metric
+// name.
+func promQLTestKey(tags models.Tags) string {
+	pairs := make([]string, 0, len(tags.Tags))
+	for _, tag := range tags.Tags {
+		if string(tag.Name) == labels.MetricName {
+			continue
+		}
+
+		pairs = append(pairs, fmt.Sprintf("%s=%q", tag.Name, tag.Value))
+	}
+
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// parsePromQLTestSample parses an expected sample, which is a series and its
+// value or only a value for scalar results.
+func parsePromQLTestSample(line string) (string, float64, error) {
+	if value, err := parsePromQLTestValue(line); err == nil {
+		return promQLTestKey(models.EmptyTags()), value, nil
+	}
+
+	tags, rest, err := parsePromQLTestSeries(line)
+	if err != nil {
+		return "", 0, err
+	}
+
+	value, err := parsePromQLTestValue(rest)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return promQLTestKey(tags), value, nil
+}
+
+// parsePromQLTestSeries parses the series at the start of the line, returning
+// its tags and the rest of the line.
+func parsePromQLTestSeries(line string) (models.Tags, string, error) {
+	end := strings.LastIndex(line, "}") + 1
+	if end == 0 {
+		if end = strings.IndexAny(line, " \t"); end < 0 {
+			end = len(line)
+		}
+	}
+
+	metric, err := pql.ParseMetric(line[:end])
+	if err != nil {
+		return models.Tags{}, "", err
+	}
+
+	tags := models.NewTags(len(metric), models.NewTagOptions())
+	for _, l := range metric {
+		tags = tags.AddTag(models.Tag{Name: []byte(l.Name), Value: []byte(l.Value)})
+	}
+
+	return tags, strings.TrimSpace(line[end:]), nil
+}
+
+func parsePromQLTestValue(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+
+// parsePromQLTestSequence expands the values of a loaded series, where "_"
+// omits a sample, "_xn" omits n samples and "a+bxn" expands to the n+1
+// samples a, a+b, ..., a+nb.
+func parsePromQLTestSequence(s string) ([]*float64, error) {
+	var values []*float64
+	for _, item := range patSpace.Split(strings.TrimSpace(s), -1) {
+		if item == "_" {
+			values = append(values, nil)
+			continue
+		}
+
+		if value, err := parsePromQLTestValue(item); err == nil {
+			values = append(values, &value)
+			continue
+		}
+
+		idx := strings.LastIndex(item, "x")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid sequence value: %s", item)
+		}
+
+		times, err := strconv.Atoi(item[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequence value: %s", item)
+		}
+
+		item = item[:idx]
+		if item == "_" {
+			for i := 0; i < times; i++ {
+				values = append(values, nil)
+			}
+			continue
+		}
+
+		// The increment is signed, so split at the last sign that is not
+		// part of the exponent of the initial value.
+		split := strings.LastIndexAny(item, "+-")
+		for split > 0 && (item[split-1] == 'e' || item[split-1] == 'E') {
+			split = strings.LastIndexAny(item[:split], "+-")
+		}
+
+		if split <= 0 {
+			return nil, fmt.Errorf("invalid sequence value: %s", item)
+		}
+
+		initial, err := parsePromQLTestValue(item[:split])
+		if err != nil {
+			return nil, err
+		}
+
+		increment, err := parsePromQLTestValue(item[split:])
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i <= times; i++ {
+			value := initial + float64(i)*increment
+			values = append(values, &value)
+		}
+	}
+
+	return values, nil
+}
+
+// promqlTestStorage is a storage holding the series loaded by a test file,
+// which fetches the series matching a query as the M3 storage does.
+type promqlTestStorage struct {
+	mock.Storage
+
+	sync.RWMutex
+	series ts.SeriesList
+}
+
+func newPromQLTestStorage() *promqlTestStorage {
+	return &promqlTestStorage{Storage: mock.NewMockStorage()}
+}
+
+func (s *promqlTestStorage) clear() {
+	s.Lock()
+	s.series = nil
+	s.Unlock()
+}
+
+func (s *promqlTestStorage) load(interval time.Duration, line string) error {
+	tags, rest, err := parsePromQLTestSeries(line)
+	if err != nil {
+		return err
+	}
+
+	values, err := parsePromQLTestSequence(rest)
+	if err != nil {
+		return err
+	}
+
+	datapoints := make(ts.Datapoints, 0, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		datapoints = append(datapoints, ts.Datapoint{
+			Timestamp: promqlTestStart.Add(time.Duration(i) * interval),
+			Value:     *value,
+		})
+	}
+
+	s.Lock()
+	s.series = append(s.series, ts.NewSeries(tags.ID(), datapoints, tags))
+	s.Unlock()
+	return nil
+}
+
+func (s *promqlTestStorage) Fetch(
+	_ context.Context,
+	query *storage.FetchQuery,
+	_ *storage.FetchOptions,
+) (*storage.FetchResult, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	result := &storage.FetchResult{}
+	for _, series := range s.series {
+		if !promQLTestMatches(series.Tags, query.TagMatchers) {
+			continue
+		}
+
+		var datapoints ts.Datapoints
+		for _, dp := range series.Values().Datapoints() {
+			if !dp.Timestamp.Before(query.Start) && !dp.Timestamp.After(query.End) {
+				datapoints = append(datapoints, dp)
+			}
+		}
+
+		result.SeriesList = append(result.SeriesList,
+			ts.NewSeries(series.Name(), datapoints, series.Tags))
+	}
+
+	return result, nil
+}
+
+func (s *promqlTestStorage) FetchBlocks(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (block.Result, error) {
+	result, err := s.Fetch(ctx, query, options)
+	if err != nil {
+		return block.Result{}, err
+	}
+
+	return storage.FetchResultToBlockResult(result, query,
+		promqlTestLookback, options.Enforcer)
+}
+
+func promQLTestMatches(tags models.Tags, matchers models.Matchers) bool {
+	for _, matcher := range matchers {
+		value, _ := tags.Get(matcher.Name)
+		if !matcher.Matches(value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/plan"
+	"github.com/m3db/m3/src/query/stats"
 	"github.com/m3db/m3/src/query/util/logging"
 	"github.com/m3db/m3/src/query/util/opentracing"
 
@@ -106,10 +107,15 @@ func (r *Request) plan(ctx context.Context, nodes parser.Nodes, edges parser.Edg
 		return plan.PhysicalPlan{}, err
 	}
 
+	pp.TimeSharding = r.engine.timeSharding
 	if r.params.Debug {
 		logging.WithContext(ctx).Info("physical plan", zap.String("plan", pp.String()))
 	}
 
+	if s := stats.FromContext(ctx); s != nil {
+		s.SetPlans(lp.String(), pp.String())
+	}
+
 	return pp, nil
 }
 
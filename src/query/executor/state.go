@@ -24,6 +24,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/executor/transform"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
@@ -102,6 +103,17 @@ type ScalarParams interface {
 	) parser.Source
 }
 
+// SubqueryParams are defined by subqueries, which are sources evaluating
+// the DAG of an inner expression
+type SubqueryParams interface {
+	parser.Params
+	Node(
+		controller *transform.Controller,
+		options transform.Options,
+		evaluate transform.SubqueryEvaluateFn,
+	) parser.Source
+}
+
 // GenerateExecutionState creates an execution state from the physical plan
 func GenerateExecutionState(
 	pplan plan.PhysicalPlan,
@@ -119,9 +131,10 @@ func GenerateExecutionState(
 	}
 
 	options := transform.Options{
-		TimeSpec:  pplan.TimeSpec,
-		Debug:     pplan.Debug,
-		BlockType: pplan.BlockType,
+		TimeSpec:     pplan.TimeSpec,
+		Debug:        pplan.Debug,
+		BlockType:    pplan.BlockType,
+		TimeSharding: pplan.TimeSharding,
 	}
 
 	controller, err := state.createNode(step, options)
@@ -150,14 +163,22 @@ func (s *ExecutionState) createNode(
 	sourceParams, ok := step.Transform.Op.(SourceParams)
 	if ok {
 		source, controller := CreateSource(step.ID(), sourceParams, s.storage, options)
-		s.sources = append(s.sources, source)
+		s.sources = append(s.sources, newStatsSource(source, step.ID(), sourceParams.OpType()))
+		return controller, nil
+	}
+
+	subqueryParams, ok := step.Transform.Op.(SubqueryParams)
+	if ok {
+		controller := &transform.Controller{ID: step.ID()}
+		source := subqueryParams.Node(controller, options, s.evaluateSubquery)
+		s.sources = append(s.sources, newStatsSource(source, step.ID(), subqueryParams.OpType()))
 		return controller, nil
 	}
 
 	scalarParams, ok := step.Transform.Op.(ScalarParams)
 	if ok {
 		source, controller := CreateScalarSource(step.ID(), scalarParams, options)
-		s.sources = append(s.sources, source)
+		s.sources = append(s.sources, newStatsSource(source, step.ID(), scalarParams.OpType()))
 		return controller, nil
 	}
 
@@ -167,6 +188,7 @@ func (s *ExecutionState) createNode(
 	}
 
 	transformNode, controller := CreateTransform(step.ID(), transformParams, options)
+	transformNode = newStatsNode(transformNode, step.ID(), transformParams.OpType())
 	for _, parentID := range step.Parents {
 		parentStep, ok := s.plan.Step(parentID)
 		if !ok {
@@ -184,6 +206,72 @@ func (s *ExecutionState) createNode(
 	return controller, nil
 }
 
+// evaluateSubquery plans and executes the DAG of a subquery's inner
+// expression, returning the resulting blocks once execution completes.
+func (s *ExecutionState) evaluateSubquery(
+	queryCtx *models.QueryContext,
+	nodes parser.Nodes,
+	edges parser.Edges,
+	timeSpec transform.TimeSpec,
+) ([]block.Block, error) {
+	lp, err := plan.NewLogicalPlan(nodes, edges)
+	if err != nil {
+		return nil, err
+	}
+
+	pp, err := plan.NewPhysicalPlan(lp, s.storage, models.RequestParams{
+		Start:     timeSpec.Start,
+		End:       timeSpec.End,
+		Now:       timeSpec.Now,
+		Step:      timeSpec.Step,
+		Debug:     s.plan.Debug,
+		BlockType: s.plan.BlockType,
+	}, s.plan.LookbackDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := GenerateExecutionState(pp, s.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	result := state.resultNode
+	go func() {
+		if err := state.Execute(queryCtx); err != nil {
+			result.abort(err)
+		} else {
+			result.done()
+		}
+	}()
+
+	var (
+		blocks   []block.Block
+		firstErr error
+	)
+	// Drain all results so the execution is not blocked on the result channel
+	for r := range result.ResultChan() {
+		if r.Err != nil {
+			if firstErr == nil {
+				firstErr = r.Err
+			}
+			continue
+		}
+
+		blocks = append(blocks, r.Block)
+	}
+
+	if firstErr != nil {
+		for _, b := range blocks {
+			b.Close()
+		}
+
+		return nil, firstErr
+	}
+
+	return blocks, nil
+}
+
 // Execute the sources in parallel and return the first error
 func (s *ExecutionState) Execute(queryCtx *models.QueryContext) error {
 	requests := make([]execution.Request, len(s.sources))
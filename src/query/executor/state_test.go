@@ -26,6 +26,7 @@ import (
 
 	"github.com/m3db/m3/src/query/functions"
 	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/subquery"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/plan"
@@ -118,3 +119,38 @@ func TestMultipleSources(t *testing.T) {
 	require.Len(t, state.sources, 2)
 	assert.Contains(t, state.String(), "sources")
 }
+
+func TestSubquerySource(t *testing.T) {
+	fetchTransform := parser.NewTransformFromOperation(functions.FetchOp{}, 1)
+	op, err := subquery.NewSubqueryOp(parser.Nodes{fetchTransform}, parser.Edges{},
+		time.Hour, time.Minute, 0)
+	require.NoError(t, err)
+	subqueryTransform := parser.NewTransformFromOperation(op, 1)
+	agg, err := aggregation.NewAggregationOp(aggregation.CountType, aggregation.NodeParams{})
+	require.NoError(t, err)
+	countTransform := parser.NewTransformFromOperation(agg, 2)
+	transforms := parser.Nodes{subqueryTransform, countTransform}
+	edges := parser.Edges{
+		parser.Edge{
+			ParentID: subqueryTransform.ID,
+			ChildID:  countTransform.ID,
+		},
+	}
+
+	lp, err := plan.NewLogicalPlan(transforms, edges)
+	require.NoError(t, err)
+	store := mock.NewMockStorage()
+	now := time.Now()
+	p, err := plan.NewPhysicalPlan(lp, store, models.RequestParams{
+		Start: now.Add(-time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+	}, defaultLookbackDuration)
+	require.NoError(t, err)
+	state, err := GenerateExecutionState(p, store)
+	require.NoError(t, err)
+	require.Len(t, state.sources, 1)
+	err = state.Execute(models.NoopQueryContext())
+	assert.NoError(t, err)
+}
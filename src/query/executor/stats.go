@@ -0,0 +1,101 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/stats"
+)
+
+// statsNode records per node timings into the query stats when the query
+// is being explained, and is a passthrough otherwise.
+type statsNode struct {
+	transform.OpNode
+
+	id     parser.NodeID
+	opType string
+}
+
+func newStatsNode(
+	node transform.OpNode,
+	id parser.NodeID,
+	opType string,
+) transform.OpNode {
+	return &statsNode{
+		OpNode: node,
+		id:     id,
+		opType: opType,
+	}
+}
+
+func (n *statsNode) Process(
+	queryCtx *models.QueryContext,
+	ID parser.NodeID,
+	b block.Block,
+) error {
+	s := stats.FromContext(queryCtx.Ctx)
+	if s == nil {
+		return n.OpNode.Process(queryCtx, ID, b)
+	}
+
+	start := time.Now()
+	err := n.OpNode.Process(queryCtx, ID, b)
+	s.RecordNode(string(n.id), n.opType, string(ID), time.Since(start))
+	return err
+}
+
+// statsSource records the execution time of a source node into the query
+// stats when the query is being explained.
+type statsSource struct {
+	parser.Source
+
+	id     parser.NodeID
+	opType string
+}
+
+func newStatsSource(
+	source parser.Source,
+	id parser.NodeID,
+	opType string,
+) parser.Source {
+	return &statsSource{
+		Source: source,
+		id:     id,
+		opType: opType,
+	}
+}
+
+func (n *statsSource) Execute(queryCtx *models.QueryContext) error {
+	s := stats.FromContext(queryCtx.Ctx)
+	if s == nil {
+		return n.Source.Execute(queryCtx)
+	}
+
+	start := time.Now()
+	err := n.Source.Execute(queryCtx)
+	s.RecordNode(string(n.id), n.opType, "", time.Since(start))
+	return err
+}
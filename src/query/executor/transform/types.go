@@ -26,13 +26,15 @@ import (
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 )
 
 // Options to create transform nodes
 type Options struct {
-	TimeSpec  TimeSpec
-	Debug     bool
-	BlockType models.FetchedBlockType
+	TimeSpec     TimeSpec
+	Debug        bool
+	BlockType    models.FetchedBlockType
+	TimeSharding *timeshard.Options
 }
 
 // OpNode represents the execution node
@@ -84,6 +86,15 @@ type StepNode interface {
 	ProcessStep(step block.Step) (block.Step, error)
 }
 
+// SubqueryEvaluateFn evaluates the DAG of the inner expression of a subquery
+// over the given time spec and returns the resulting blocks.
+type SubqueryEvaluateFn func(
+	queryCtx *models.QueryContext,
+	nodes parser.Nodes,
+	edges parser.Edges,
+	timeSpec TimeSpec,
+) ([]block.Block, error)
+
 // BoundOp is implements by operations which have bounds
 type BoundOp interface {
 	Bounds() BoundSpec
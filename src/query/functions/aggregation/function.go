@@ -41,6 +41,8 @@ const (
 	StandardVarianceType = "var"
 	// CountType counts all non nan elements in a list of series
 	CountType = "count"
+	// GroupType returns 1 for any group containing non nan elements in a list of series
+	GroupType = "group"
 )
 
 func sumAndCount(values []float64, bucket []int) (float64, float64) {
@@ -135,3 +137,13 @@ func countFn(values []float64, bucket []int) float64 {
 	_, count := sumAndCount(values, bucket)
 	return count
 }
+
+func groupFn(values []float64, bucket []int) float64 {
+	for _, idx := range bucket {
+		if !math.IsNaN(values[idx]) {
+			return 1
+		}
+	}
+
+	return math.NaN()
+}
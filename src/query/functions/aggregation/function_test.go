@@ -48,6 +48,7 @@ var fnTest = []struct {
 			{StandardDeviationType, stddevFn, []float64{}},
 			{StandardVarianceType, varianceFn, []float64{}},
 			{CountType, countFn, []float64{}},
+			{GroupType, groupFn, []float64{}},
 		},
 	},
 	{
@@ -59,6 +60,7 @@ var fnTest = []struct {
 			{StandardDeviationType, stddevFn, []float64{0}},
 			{StandardVarianceType, varianceFn, []float64{0}},
 			{CountType, countFn, []float64{1}},
+			{GroupType, groupFn, []float64{1}},
 		},
 	},
 	{
@@ -70,6 +72,7 @@ var fnTest = []struct {
 			{StandardDeviationType, stddevFn, []float64{0.55}},
 			{StandardVarianceType, varianceFn, []float64{0.3025}},
 			{CountType, countFn, []float64{2}},
+			{GroupType, groupFn, []float64{1}},
 		},
 	},
 	{
@@ -81,6 +84,7 @@ var fnTest = []struct {
 			{StandardDeviationType, stddevFn, []float64{0, 0}},
 			{StandardVarianceType, varianceFn, []float64{0, 0}},
 			{CountType, countFn, []float64{1, 1}},
+			{GroupType, groupFn, []float64{1, 1}},
 		},
 	},
 	{
@@ -92,6 +96,7 @@ var fnTest = []struct {
 			{StandardDeviationType, stddevFn, []float64{2}},
 			{StandardVarianceType, varianceFn, []float64{4}},
 			{CountType, countFn, []float64{6}},
+			{GroupType, groupFn, []float64{1}},
 		},
 	},
 	{
@@ -106,6 +111,7 @@ var fnTest = []struct {
 			{StandardDeviationType, stddevFn, []float64{2, 36.73403}},
 			{StandardVarianceType, varianceFn, []float64{4, 1349.38889}},
 			{CountType, countFn, []float64{6, 6}},
+			{GroupType, groupFn, []float64{1, 1}},
 		},
 	},
 	{
@@ -119,6 +125,7 @@ var fnTest = []struct {
 			{StandardDeviationType, stddevFn, []float64{2.44949}},
 			{StandardVarianceType, varianceFn, []float64{6}},
 			{CountType, countFn, []float64{4}},
+			{GroupType, groupFn, []float64{1}},
 		},
 	},
 	{
@@ -132,6 +139,7 @@ var fnTest = []struct {
 			{StandardDeviationType, stddevFn, []float64{math.NaN()}},
 			{StandardVarianceType, varianceFn, []float64{math.NaN()}},
 			{CountType, countFn, []float64{0}},
+			{GroupType, groupFn, []float64{math.NaN()}},
 		},
 	},
 	{
@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 	"github.com/m3db/m3/src/query/util/logging"
 	"github.com/m3db/m3/src/query/util/opentracing"
 
@@ -50,12 +51,13 @@ type FetchOp struct {
 // FetchNode is the execution node
 // TODO: Make FetchNode private
 type FetchNode struct {
-	debug      bool
-	blockType  models.FetchedBlockType
-	op         FetchOp
-	controller *transform.Controller
-	storage    storage.Storage
-	timespec   transform.TimeSpec
+	debug        bool
+	blockType    models.FetchedBlockType
+	op           FetchOp
+	controller   *transform.Controller
+	storage      storage.Storage
+	timespec     transform.TimeSpec
+	timeSharding *timeshard.Options
 }
 
 // OpType for the operator
@@ -79,12 +81,13 @@ func (o FetchOp) String() string {
 // Node creates an execution node
 func (o FetchOp) Node(controller *transform.Controller, storage storage.Storage, options transform.Options) parser.Source {
 	return &FetchNode{
-		op:         o,
-		controller: controller,
-		storage:    storage,
-		timespec:   options.TimeSpec,
-		debug:      options.Debug,
-		blockType:  options.BlockType,
+		op:           o,
+		controller:   controller,
+		storage:      storage,
+		timespec:     options.TimeSpec,
+		debug:        options.Debug,
+		blockType:    options.BlockType,
+		timeSharding: options.TimeSharding,
 	}
 }
 
@@ -102,6 +105,7 @@ func (n *FetchNode) fetch(queryCtx *models.QueryContext) (block.Result, error) {
 	opts.BlockType = n.blockType
 	opts.Scope = queryCtx.Scope
 	opts.Enforcer = queryCtx.Enforcer
+	opts.TimeSharding = n.timeSharding
 
 	return n.storage.FetchBlocks(ctx, &storage.FetchQuery{
 		Start:       startTime,
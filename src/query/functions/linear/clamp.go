@@ -33,31 +33,56 @@ const (
 
 	// ClampMaxType ensures all values except NaNs are lesser than or equal to provided argument
 	ClampMaxType = "clamp_max"
+
+	// ClampType ensures all values except NaNs are between the provided minimum and
+	// maximum arguments, all values become NaN if the minimum exceeds the maximum
+	ClampType = "clamp"
 )
 
 type clampOp struct {
 	opType string
-	scalar float64
+	min    float64
+	max    float64
 }
 
 // NewClampOp creates a new clamp op based on the type and arguments
 func NewClampOp(args []interface{}, optype string) (BaseOp, error) {
-	if len(args) != 1 {
-		return emptyOp, fmt.Errorf("invalid number of args for clamp: %d", len(args))
+	numArgs := 1
+	switch optype {
+	case ClampMinType, ClampMaxType:
+	case ClampType:
+		numArgs = 2
+	default:
+		return emptyOp, fmt.Errorf("unknown clamp type: %s", optype)
 	}
 
-	if optype != ClampMinType && optype != ClampMaxType {
-		return emptyOp, fmt.Errorf("unknown clamp type: %s", optype)
+	if len(args) != numArgs {
+		return emptyOp, fmt.Errorf("invalid number of args for %s: %d", optype, len(args))
 	}
 
-	scalar, ok := args[0].(float64)
-	if !ok {
-		return emptyOp, fmt.Errorf("unable to cast to scalar argument: %v", args[0])
+	scalars := make([]float64, 0, len(args))
+	for _, arg := range args {
+		scalar, ok := arg.(float64)
+		if !ok {
+			return emptyOp, fmt.Errorf("unable to cast to scalar argument: %v", arg)
+		}
+
+		scalars = append(scalars, scalar)
 	}
 
 	spec := clampOp{
 		opType: optype,
-		scalar: scalar,
+		min:    math.Inf(-1),
+		max:    math.Inf(1),
+	}
+
+	switch optype {
+	case ClampMinType:
+		spec.min = scalars[0]
+	case ClampMaxType:
+		spec.max = scalars[0]
+	default:
+		spec.min, spec.max = scalars[0], scalars[1]
 	}
 
 	return BaseOp{
@@ -69,25 +94,27 @@ func NewClampOp(args []interface{}, optype string) (BaseOp, error) {
 func makeClampProcessor(spec clampOp) makeProcessor {
 	clampOp := spec
 	return func(op BaseOp, controller *transform.Controller) Processor {
-		fn := math.Min
-		if op.operatorType == ClampMinType {
-			fn = math.Max
-		}
-
-		return &clampNode{op: clampOp, controller: controller, clampFn: fn}
+		return &clampNode{op: clampOp, controller: controller}
 	}
 }
 
 type clampNode struct {
 	op         clampOp
-	clampFn    func(x, y float64) float64
 	controller *transform.Controller
 }
 
 func (c *clampNode) Process(values []float64) []float64 {
-	scalar := c.op.scalar
+	min, max := c.op.min, c.op.max
+	if min > max {
+		for i := range values {
+			values[i] = math.NaN()
+		}
+
+		return values
+	}
+
 	for i := range values {
-		values[i] = c.clampFn(values[i], scalar)
+		values[i] = math.Max(min, math.Min(max, values[i]))
 	}
 
 	return values
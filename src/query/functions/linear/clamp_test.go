@@ -79,3 +79,48 @@ func TestClampMax(t *testing.T) {
 	assert.Len(t, sink.Values, 2)
 	test.EqualsWithNans(t, expected, sink.Values)
 }
+
+func TestClamp(t *testing.T) {
+	values, bounds := test.GenerateValuesAndBounds(nil, nil)
+	values[0][0] = math.NaN()
+
+	block := test.NewBlockFromValues(bounds, values)
+	c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+	op, err := NewClampOp([]interface{}{2.0, 6.0}, ClampType)
+	require.NoError(t, err)
+	node := op.Node(c, transform.Options{})
+	err = node.Process(models.NoopQueryContext(), parser.NodeID(0), block)
+	require.NoError(t, err)
+	expected := expectedClampVals(expectedClampVals(values, 2.0, math.Max), 6.0, math.Min)
+	assert.Len(t, sink.Values, 2)
+	test.EqualsWithNans(t, expected, sink.Values)
+}
+
+func TestClampMinGreaterThanMax(t *testing.T) {
+	values, bounds := test.GenerateValuesAndBounds(nil, nil)
+
+	block := test.NewBlockFromValues(bounds, values)
+	c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+	op, err := NewClampOp([]interface{}{6.0, 2.0}, ClampType)
+	require.NoError(t, err)
+	node := op.Node(c, transform.Options{})
+	err = node.Process(models.NoopQueryContext(), parser.NodeID(0), block)
+	require.NoError(t, err)
+	require.Len(t, sink.Values, 2)
+	for _, series := range sink.Values {
+		for _, v := range series {
+			assert.True(t, math.IsNaN(v))
+		}
+	}
+}
+
+func TestClampInvalidArgs(t *testing.T) {
+	_, err := NewClampOp([]interface{}{2.0}, ClampType)
+	assert.Error(t, err)
+
+	_, err = NewClampOp([]interface{}{2.0, 3.0}, ClampMinType)
+	assert.Error(t, err)
+
+	_, err = NewClampOp([]interface{}{2.0}, "clamp_foo")
+	assert.Error(t, err)
+}
@@ -58,6 +58,44 @@ const (
 
 	// Log10Type calculates the decimal logarithm for all values in the timeseries
 	Log10Type = "log10"
+
+	// SgnType returns the sign of all values in the timeseries, 1 for
+	// positive values, -1 for negative values and 0 for zero
+	SgnType = "sgn"
+
+	// DegType converts all values in the timeseries from radians to degrees
+	DegType = "deg"
+
+	// RadType converts all values in the timeseries from degrees to radians
+	RadType = "rad"
+
+	// The following calculate the trigonometric function of the same name
+	// for all values in the timeseries, with angles given in radians
+
+	// AcosType calculates the arccosine
+	AcosType = "acos"
+	// AcoshType calculates the inverse hyperbolic cosine
+	AcoshType = "acosh"
+	// AsinType calculates the arcsine
+	AsinType = "asin"
+	// AsinhType calculates the inverse hyperbolic sine
+	AsinhType = "asinh"
+	// AtanType calculates the arctangent
+	AtanType = "atan"
+	// AtanhType calculates the inverse hyperbolic tangent
+	AtanhType = "atanh"
+	// CosType calculates the cosine
+	CosType = "cos"
+	// CoshType calculates the hyperbolic cosine
+	CoshType = "cosh"
+	// SinType calculates the sine
+	SinType = "sin"
+	// SinhType calculates the hyperbolic sine
+	SinhType = "sinh"
+	// TanType calculates the tangent
+	TanType = "tan"
+	// TanhType calculates the hyperbolic tangent
+	TanhType = "tanh"
 )
 
 var (
@@ -70,9 +108,35 @@ var (
 		LnType:    math.Log,
 		Log2Type:  math.Log2,
 		Log10Type: math.Log10,
+		SgnType:   sgn,
+		DegType:   func(x float64) float64 { return x * 180 / math.Pi },
+		RadType:   func(x float64) float64 { return x * math.Pi / 180 },
+		AcosType:  math.Acos,
+		AcoshType: math.Acosh,
+		AsinType:  math.Asin,
+		AsinhType: math.Asinh,
+		AtanType:  math.Atan,
+		AtanhType: math.Atanh,
+		CosType:   math.Cos,
+		CoshType:  math.Cosh,
+		SinType:   math.Sin,
+		SinhType:  math.Sinh,
+		TanType:   math.Tan,
+		TanhType:  math.Tanh,
 	}
 )
 
+func sgn(x float64) float64 {
+	if x < 0 {
+		return -1
+	} else if x > 0 {
+		return 1
+	}
+
+	// Zero and NaN are returned as is
+	return x
+}
+
 // NewMathOp creates a new math op based on the type
 func NewMathOp(optype string) (BaseOp, error) {
 	if _, ok := mathFuncs[optype]; !ok {
@@ -211,6 +211,71 @@ func TestSqrtWithSomeValues(t *testing.T) {
 	test.EqualsWithNans(t, expected, sink.Values)
 }
 
+func TestSgnWithSomeValues(t *testing.T) {
+	v := [][]float64{
+		{0, math.NaN(), -2, 3, 4},
+		{math.NaN(), -6, 7, 0, -9},
+	}
+
+	values, bounds := test.GenerateValuesAndBounds(v, nil)
+	block := test.NewBlockFromValues(bounds, values)
+	c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+	op, err := NewMathOp(SgnType)
+	require.NoError(t, err)
+	node := op.Node(c, transform.Options{})
+	err = node.Process(models.NoopQueryContext(), parser.NodeID(0), block)
+	require.NoError(t, err)
+	expected := [][]float64{
+		{0, math.NaN(), -1, 1, 1},
+		{math.NaN(), -1, 1, 0, -1},
+	}
+	assert.Len(t, sink.Values, 2)
+	test.EqualsWithNans(t, expected, sink.Values)
+}
+
+func TestTrigonometricFuncs(t *testing.T) {
+	v := [][]float64{
+		{0, math.NaN(), 0.5, 1, -1},
+		{math.NaN(), 0.25, -0.5, 0, 0.75},
+	}
+
+	tests := []struct {
+		opType string
+		fn     func(x float64) float64
+	}{
+		{AcosType, math.Acos},
+		{AcoshType, math.Acosh},
+		{AsinType, math.Asin},
+		{AsinhType, math.Asinh},
+		{AtanType, math.Atan},
+		{AtanhType, math.Atanh},
+		{CosType, math.Cos},
+		{CoshType, math.Cosh},
+		{SinType, math.Sin},
+		{SinhType, math.Sinh},
+		{TanType, math.Tan},
+		{TanhType, math.Tanh},
+		{DegType, func(x float64) float64 { return x * 180 / math.Pi }},
+		{RadType, func(x float64) float64 { return x * math.Pi / 180 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.opType, func(t *testing.T) {
+			values, bounds := test.GenerateValuesAndBounds(v, nil)
+			block := test.NewBlockFromValues(bounds, values)
+			c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+			op, err := NewMathOp(tt.opType)
+			require.NoError(t, err)
+			node := op.Node(c, transform.Options{})
+			err = node.Process(models.NoopQueryContext(), parser.NodeID(0), block)
+			require.NoError(t, err)
+			expected := expectedMathVals(v, tt.fn)
+			assert.Len(t, sink.Values, 2)
+			test.EqualsWithNans(t, expected, sink.Values)
+		})
+	}
+}
+
 func TestNonExistentFunc(t *testing.T) {
 	_, err := NewMathOp("nonexistent_func")
 	require.Error(t, err)
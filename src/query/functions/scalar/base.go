@@ -39,6 +39,9 @@ const (
 	// TimeType returns the number of seconds since January 1, 1970 UTC.
 	// Note that this does not actually return the current time, but the time at which the expression is to be evaluated.
 	TimeType = "time"
+
+	// VectorType returns the scalar as a vector without any labels.
+	VectorType = "vector"
 )
 
 type baseOp struct {
@@ -67,7 +70,7 @@ func (o baseOp) Node(
 
 // NewScalarOp creates a new scalar op
 func NewScalarOp(fn block.ScalarFunc, opType string) (parser.Params, error) {
-	if opType != ScalarType && opType != TimeType {
+	if opType != ScalarType && opType != TimeType && opType != VectorType {
 		return nil, fmt.Errorf("unknown scalar type: %s", opType)
 	}
 
@@ -61,3 +61,29 @@ func TestScalarTime(t *testing.T) {
 		}
 	}
 }
+
+func TestScalarVector(t *testing.T) {
+	_, bounds := test.GenerateValuesAndBounds(nil, nil)
+	c, sink := executor.NewControllerWithSink(parser.NodeID(0))
+	op, err := NewScalarOp(func(_ time.Time) float64 { return 2.5 }, VectorType)
+	require.NoError(t, err)
+
+	node := op.(*baseOp).Node(c, transform.Options{
+		TimeSpec: transform.TimeSpec{
+			Start: bounds.Start,
+			End:   bounds.End(),
+			Step:  bounds.StepSize,
+		},
+	})
+	err = node.Execute(models.NoopQueryContext())
+	require.NoError(t, err)
+	require.Len(t, sink.Values, 1)
+	for _, val := range sink.Values[0] {
+		assert.Equal(t, 2.5, val)
+	}
+}
+
+func TestUnknownScalarType(t *testing.T) {
+	_, err := NewScalarOp(func(_ time.Time) float64 { return 0 }, "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package subquery
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/functions/utils"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"go.uber.org/zap"
+)
+
+// SubqueryType evaluates an inner expression over a range at a given step.
+const SubqueryType = "subquery"
+
+// Op stores required properties for a subquery
+type Op struct {
+	nodes  parser.Nodes
+	edges  parser.Edges
+	rng    time.Duration
+	step   time.Duration
+	offset time.Duration
+}
+
+// NewSubqueryOp creates a new subquery op which evaluates the DAG of the
+// inner expression every step, a zero step uses the step of the query.
+func NewSubqueryOp(
+	nodes parser.Nodes,
+	edges parser.Edges,
+	rng time.Duration,
+	step time.Duration,
+	offset time.Duration,
+) (parser.Params, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("subquery requires an inner expression")
+	}
+
+	if rng <= 0 {
+		return nil, fmt.Errorf("subquery range must be positive, got: %v", rng)
+	}
+
+	if step < 0 {
+		return nil, fmt.Errorf("subquery step must not be negative, got: %v", step)
+	}
+
+	return Op{
+		nodes:  nodes,
+		edges:  edges,
+		rng:    rng,
+		step:   step,
+		offset: offset,
+	}, nil
+}
+
+// OpType for the operator
+func (o Op) OpType() string {
+	return SubqueryType
+}
+
+// Bounds returns the bounds for the spec
+func (o Op) Bounds() transform.BoundSpec {
+	return transform.BoundSpec{
+		Range:  o.rng,
+		Offset: o.offset,
+	}
+}
+
+// String representation
+func (o Op) String() string {
+	return fmt.Sprintf("type: %s, range: %v, step: %v, offset: %v, nodes: %v",
+		o.OpType(), o.rng, o.step, o.offset, o.nodes)
+}
+
+// Node creates an execution node
+func (o Op) Node(
+	controller *transform.Controller,
+	options transform.Options,
+	evaluate transform.SubqueryEvaluateFn,
+) parser.Source {
+	return &baseNode{
+		op:         o,
+		controller: controller,
+		evaluate:   evaluate,
+		timespec:   options.TimeSpec,
+		debug:      options.Debug,
+	}
+}
+
+// baseNode is the execution node
+type baseNode struct {
+	op         Op
+	controller *transform.Controller
+	evaluate   transform.SubqueryEvaluateFn
+	timespec   transform.TimeSpec
+	debug      bool
+}
+
+// innerTimeSpec returns the time spec to evaluate the inner expression at,
+// timestamps are aligned to multiples of the subquery step and shifted back
+// by the offset.
+func (n *baseNode) innerTimeSpec() transform.TimeSpec {
+	step := n.op.step
+	if step <= 0 {
+		step = n.timespec.Step
+	}
+
+	return transform.TimeSpec{
+		Start: alignUp(n.timespec.Start.Add(-1*n.op.offset), step),
+		End:   alignUp(n.timespec.End.Add(-1*n.op.offset), step),
+		Now:   n.timespec.Now,
+		Step:  step,
+	}
+}
+
+// alignUp returns the first multiple of the step since the epoch at or
+// after the given time.
+func alignUp(t time.Time, step time.Duration) time.Time {
+	nanos := t.UnixNano()
+	aligned := nanos - nanos%int64(step)
+	if aligned < nanos {
+		aligned += int64(step)
+	}
+
+	return time.Unix(0, aligned)
+}
+
+// Execute runs the subquery node operation
+func (n *baseNode) Execute(queryCtx *models.QueryContext) error {
+	innerSpec := n.innerTimeSpec()
+	blocks, err := n.evaluate(queryCtx, n.op.nodes, n.op.edges, innerSpec)
+	if err != nil {
+		return err
+	}
+
+	seriesList, err := blocksToSeriesList(blocks, n.op.offset)
+	for _, b := range blocks {
+		b.Close()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// The evaluated points are treated as raw datapoints, so that the
+	// temporal functions consuming the subquery see a range of values
+	// at every step of the query, the lookback must cover a full step so
+	// that no evaluated points are considered stale.
+	lookback := innerSpec.Step
+	if n.timespec.Step > lookback {
+		lookback = n.timespec.Step
+	}
+
+	result, err := storage.FetchResultToBlockResult(
+		&storage.FetchResult{SeriesList: seriesList},
+		&storage.FetchQuery{
+			Start:    n.timespec.Start,
+			End:      n.timespec.End,
+			Interval: n.timespec.Step,
+		},
+		lookback,
+		queryCtx.Enforcer,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, block := range result.Blocks {
+		if n.debug {
+			// Ignore any errors
+			iter, _ := block.StepIter()
+			if iter != nil {
+				logging.WithContext(queryCtx.Ctx).Info("subquery node", zap.Any("meta", iter.Meta()))
+			}
+		}
+
+		if err := n.controller.Process(queryCtx, block); err != nil {
+			block.Close()
+			// Fail on first error
+			return err
+		}
+
+		block.Close()
+	}
+
+	return nil
+}
+
+type seriesEntry struct {
+	meta       block.SeriesMeta
+	datapoints ts.Datapoints
+}
+
+// blocksToSeriesList converts the consolidated blocks of the inner expression
+// to series, merging series spread across multiple blocks.
+func blocksToSeriesList(
+	blocks []block.Block,
+	offset time.Duration,
+) (ts.SeriesList, error) {
+	var (
+		entries []seriesEntry
+		indexes = make(map[string]int)
+	)
+
+	for _, b := range blocks {
+		iter, err := b.SeriesIter()
+		if err != nil {
+			return nil, err
+		}
+
+		meta := iter.Meta()
+		seriesMetas := utils.FlattenMetadata(meta, iter.SeriesMeta())
+		for i := 0; iter.Next(); i++ {
+			id := string(seriesMetas[i].Tags.ID())
+			idx, ok := indexes[id]
+			if !ok {
+				idx = len(entries)
+				indexes[id] = idx
+				entries = append(entries, seriesEntry{meta: seriesMetas[i]})
+			}
+
+			for step, value := range iter.Current().Values() {
+				if math.IsNaN(value) {
+					continue
+				}
+
+				t, err := meta.Bounds.TimeForIndex(step)
+				if err != nil {
+					iter.Close()
+					return nil, err
+				}
+
+				entries[idx].datapoints = append(entries[idx].datapoints, ts.Datapoint{
+					Timestamp: t.Add(offset),
+					Value:     value,
+				})
+			}
+		}
+
+		err = iter.Err()
+		iter.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seriesList := make(ts.SeriesList, 0, len(entries))
+	for _, entry := range entries {
+		seriesList = append(seriesList, ts.NewSeries(entry.meta.Name,
+			entry.datapoints, entry.meta.Tags))
+	}
+
+	return seriesList, nil
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package subquery
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/functions/utils"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type datapointsSink struct {
+	datapoints [][]ts.Datapoint
+	meta       block.Metadata
+}
+
+func (s *datapointsSink) Process(
+	_ *models.QueryContext,
+	_ parser.NodeID,
+	b block.Block,
+) error {
+	unconsolidated, err := b.Unconsolidated()
+	if err != nil {
+		return err
+	}
+
+	iter, err := unconsolidated.SeriesIter()
+	if err != nil {
+		return err
+	}
+
+	s.meta = iter.Meta()
+	for iter.Next() {
+		var datapoints []ts.Datapoint
+		for _, dps := range iter.Current().Datapoints() {
+			datapoints = append(datapoints, dps...)
+		}
+		s.datapoints = append(s.datapoints, datapoints)
+	}
+
+	return iter.Err()
+}
+
+func testNodes() parser.Nodes {
+	return parser.Nodes{parser.NewTransformFromOperation(
+		utils.StaticParams("fetch"), 0)}
+}
+
+func TestNewSubqueryOpErrors(t *testing.T) {
+	_, err := NewSubqueryOp(nil, nil, time.Hour, time.Minute, 0)
+	assert.Error(t, err)
+
+	_, err = NewSubqueryOp(testNodes(), nil, 0, time.Minute, 0)
+	assert.Error(t, err)
+
+	_, err = NewSubqueryOp(testNodes(), nil, time.Hour, -time.Minute, 0)
+	assert.Error(t, err)
+}
+
+func TestSubqueryInnerTimeSpec(t *testing.T) {
+	start := time.Unix(3600, 0)
+	op, err := NewSubqueryOp(testNodes(), nil, 10*time.Minute, 2*time.Minute, time.Minute)
+	require.NoError(t, err)
+
+	node := op.(Op).Node(&transform.Controller{}, transform.Options{
+		TimeSpec: transform.TimeSpec{
+			Start: start,
+			End:   start.Add(10 * time.Minute),
+			Step:  time.Minute,
+		},
+	}, nil).(*baseNode)
+
+	// Start is shifted back by the offset and aligned up to the step
+	assert.Equal(t, transform.TimeSpec{
+		Start: start,
+		End:   start.Add(10 * time.Minute),
+		Step:  2 * time.Minute,
+	}, node.innerTimeSpec())
+
+	// Zero step uses the step of the query
+	op, err = NewSubqueryOp(testNodes(), nil, 10*time.Minute, 0, 0)
+	require.NoError(t, err)
+	node = op.(Op).Node(&transform.Controller{}, transform.Options{
+		TimeSpec: transform.TimeSpec{
+			Start: start,
+			End:   start.Add(10 * time.Minute),
+			Step:  time.Minute,
+		},
+	}, nil).(*baseNode)
+	assert.Equal(t, time.Minute, node.innerTimeSpec().Step)
+}
+
+func TestSubqueryExecute(t *testing.T) {
+	var (
+		start   = time.Unix(3600, 0)
+		end     = start.Add(10 * time.Minute)
+		offset  = time.Minute
+		nan     = math.NaN()
+		evalled bool
+	)
+
+	evaluate := func(
+		_ *models.QueryContext,
+		_ parser.Nodes,
+		_ parser.Edges,
+		timeSpec transform.TimeSpec,
+	) ([]block.Block, error) {
+		evalled = true
+		return []block.Block{test.NewBlockFromValues(timeSpec.Bounds(),
+			[][]float64{{1, 2, nan, 4, 5}})}, nil
+	}
+
+	op, err := NewSubqueryOp(testNodes(), nil, 4*time.Minute, 2*time.Minute, offset)
+	require.NoError(t, err)
+
+	sink := &datapointsSink{}
+	controller := &transform.Controller{ID: parser.NodeID("0")}
+	controller.AddTransform(sink)
+
+	node := op.(Op).Node(controller, transform.Options{
+		TimeSpec: transform.TimeSpec{
+			Start: start,
+			End:   end,
+			Step:  time.Minute,
+		},
+	}, evaluate)
+	require.NoError(t, node.Execute(models.NoopQueryContext()))
+	require.True(t, evalled)
+
+	assert.Equal(t, models.Bounds{
+		Start:    start,
+		Duration: 10 * time.Minute,
+		StepSize: time.Minute,
+	}, sink.meta.Bounds)
+
+	// Points are evaluated every two minutes and shifted forward by the offset
+	require.Len(t, sink.datapoints, 1)
+	assert.Equal(t, []ts.Datapoint{
+		{Timestamp: start.Add(offset), Value: 1},
+		{Timestamp: start.Add(2*time.Minute + offset), Value: 2},
+		{Timestamp: start.Add(6*time.Minute + offset), Value: 4},
+		{Timestamp: start.Add(8*time.Minute + offset), Value: 5},
+	}, sink.datapoints[0])
+}
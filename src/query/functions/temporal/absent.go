@@ -0,0 +1,125 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+// AbsentType returns a series with the value 1 for each step at which none of
+// the selected series has values in the specified interval.
+const AbsentType = "absent_over_time"
+
+// NewAbsentOp creates a new absent_over_time operation, applied to the result
+// of present_over_time over the selected series. As the output series does
+// not come from any input series, it is tagged with the tags of the equality
+// matchers of the selector.
+func NewAbsentOp(tags models.Tags) transform.Params {
+	return absentOp{tags: tags}
+}
+
+type absentOp struct {
+	tags models.Tags
+}
+
+// OpType for the operator
+func (o absentOp) OpType() string {
+	return AbsentType
+}
+
+// String representation
+func (o absentOp) String() string {
+	return fmt.Sprintf("type: %s", o.OpType())
+}
+
+// Node creates an execution node
+func (o absentOp) Node(controller *transform.Controller, _ transform.Options) transform.OpNode {
+	return &absentNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+type absentNode struct {
+	op         absentOp
+	controller *transform.Controller
+}
+
+func (n *absentNode) Params() parser.Params {
+	return n.op
+}
+
+// Process the block
+func (n *absentNode) Process(queryCtx *models.QueryContext, ID parser.NodeID, b block.Block) error {
+	return transform.ProcessSimpleBlock(n, n.controller, queryCtx, ID, b)
+}
+
+// ProcessBlock collapses the input series into a single series which is 1 at
+// the steps where no input series has a value.
+func (n *absentNode) ProcessBlock(queryCtx *models.QueryContext, ID parser.NodeID, b block.Block) (block.Block, error) {
+	stepIter, err := b.StepIter()
+	if err != nil {
+		return nil, err
+	}
+
+	meta := stepIter.Meta()
+	meta.Tags = models.EmptyTags()
+	seriesMetas := []block.SeriesMeta{{
+		Tags: n.op.tags,
+		Name: []byte(AbsentType),
+	}}
+
+	builder, err := n.controller.BlockBuilder(queryCtx, meta, seriesMetas)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := builder.AddCols(stepIter.StepCount()); err != nil {
+		return nil, err
+	}
+
+	absentValues := make([]float64, 1)
+	for index := 0; stepIter.Next(); index++ {
+		absentValues[0] = 1
+		for _, v := range stepIter.Current().Values() {
+			if !math.IsNaN(v) {
+				absentValues[0] = math.NaN()
+				break
+			}
+		}
+
+		if err := builder.AppendValues(index, absentValues); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = stepIter.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
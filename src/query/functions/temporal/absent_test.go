@@ -0,0 +1,83 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func processAbsentOp(t *testing.T, b block.Block) *executor.SinkNode {
+	tags := test.StringTagsToTags(test.StringTags{{N: "job", V: "api"}})
+	op := NewAbsentOp(tags)
+	assert.Equal(t, AbsentType, op.OpType())
+
+	c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+	node := op.Node(c, transform.Options{})
+	err := node.Process(models.NoopQueryContext(), parser.NodeID(0), b)
+	require.NoError(t, err)
+	require.Len(t, sink.Metas, 1)
+	assert.Equal(t, tags, sink.Metas[0].Tags)
+	return sink
+}
+
+func TestAbsentNoSeries(t *testing.T) {
+	bounds := models.Bounds{
+		Start:    time.Now(),
+		Duration: 5 * time.Minute,
+		StepSize: time.Minute,
+	}
+
+	builder := block.NewColumnBlockBuilder(models.NoopQueryContext(),
+		block.Metadata{Bounds: bounds}, nil)
+	require.NoError(t, builder.AddCols(bounds.Steps()))
+
+	sink := processAbsentOp(t, builder.Build())
+	assert.Equal(t, [][]float64{{1, 1, 1, 1, 1}}, sink.Values)
+}
+
+func TestAbsentSomeSeriesPresent(t *testing.T) {
+	bounds := models.Bounds{
+		Start:    time.Now(),
+		Duration: 5 * time.Minute,
+		StepSize: time.Minute,
+	}
+
+	nan := math.NaN()
+	b := test.NewBlockFromValues(bounds, [][]float64{
+		{1, nan, nan, nan, nan},
+		{nan, nan, 1, nan, nan},
+	})
+
+	sink := processAbsentOp(t, b)
+	test.EqualsWithNans(t, [][]float64{{nan, 1, nan, 1, 1}}, sink.Values)
+}
@@ -34,11 +34,14 @@
 		ReadResponse
 		Query
 		QueryResult
+		ChunkedReadResponse
 		Sample
 		TimeSeries
 		Label
 		Labels
 		LabelMatcher
+		Chunk
+		ChunkedSeries
 */
 package prompb
 
@@ -59,6 +62,31 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 
+type ReadRequest_ResponseType int32
+
+const (
+	// Server will return a single ReadResponse message with matched series
+	// that includes list of raw samples.
+	ReadRequest_SAMPLES ReadRequest_ResponseType = 0
+	// Server will stream a delimited ChunkedReadResponse message that
+	// contains XOR encoded chunks for a single series.
+	ReadRequest_STREAMED_XOR_CHUNKS ReadRequest_ResponseType = 1
+)
+
+var ReadRequest_ResponseType_name = map[int32]string{
+	0: "SAMPLES",
+	1: "STREAMED_XOR_CHUNKS",
+}
+var ReadRequest_ResponseType_value = map[string]int32{
+	"SAMPLES":             0,
+	"STREAMED_XOR_CHUNKS": 1,
+}
+
+func (x ReadRequest_ResponseType) String() string {
+	return proto.EnumName(ReadRequest_ResponseType_name, int32(x))
+}
+func (ReadRequest_ResponseType) EnumDescriptor() ([]byte, []int) { return fileDescriptorRemote, []int{1, 0} }
+
 type WriteRequest struct {
 	Timeseries []*TimeSeries `protobuf:"bytes,1,rep,name=timeseries" json:"timeseries,omitempty"`
 }
@@ -77,6 +105,9 @@ func (m *WriteRequest) GetTimeseries() []*TimeSeries {
 
 type ReadRequest struct {
 	Queries []*Query `protobuf:"bytes,1,rep,name=queries" json:"queries,omitempty"`
+	// accepted_response_types allows negotiating the content type of the
+	// response, server picks the first supported type in the list.
+	AcceptedResponseTypes []ReadRequest_ResponseType `protobuf:"varint,2,rep,packed,name=accepted_response_types,json=acceptedResponseTypes,enum=prometheus.ReadRequest_ResponseType" json:"accepted_response_types,omitempty"`
 }
 
 func (m *ReadRequest) Reset()                    { *m = ReadRequest{} }
@@ -91,6 +122,13 @@ func (m *ReadRequest) GetQueries() []*Query {
 	return nil
 }
 
+func (m *ReadRequest) GetAcceptedResponseTypes() []ReadRequest_ResponseType {
+	if m != nil {
+		return m.AcceptedResponseTypes
+	}
+	return nil
+}
+
 type ReadResponse struct {
 	// In same order as the request's queries.
 	Results []*QueryResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
@@ -156,12 +194,43 @@ func (m *QueryResult) GetTimeseries() []*TimeSeries {
 	return nil
 }
 
+// ChunkedReadResponse is a response when response_type equals
+// STREAMED_XOR_CHUNKS. We strictly stream full series after series,
+// optionally split by time, so each message has a single series.
+type ChunkedReadResponse struct {
+	ChunkedSeries []*ChunkedSeries `protobuf:"bytes,1,rep,name=chunked_series,json=chunkedSeries" json:"chunked_series,omitempty"`
+	// query_index represents an index of the query from ReadRequest.queries
+	// these chunks relates to.
+	QueryIndex int64 `protobuf:"varint,2,opt,name=query_index,json=queryIndex,proto3" json:"query_index,omitempty"`
+}
+
+func (m *ChunkedReadResponse) Reset()                    { *m = ChunkedReadResponse{} }
+func (m *ChunkedReadResponse) String() string            { return proto.CompactTextString(m) }
+func (*ChunkedReadResponse) ProtoMessage()               {}
+func (*ChunkedReadResponse) Descriptor() ([]byte, []int) { return fileDescriptorRemote, []int{5} }
+
+func (m *ChunkedReadResponse) GetChunkedSeries() []*ChunkedSeries {
+	if m != nil {
+		return m.ChunkedSeries
+	}
+	return nil
+}
+
+func (m *ChunkedReadResponse) GetQueryIndex() int64 {
+	if m != nil {
+		return m.QueryIndex
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*WriteRequest)(nil), "prometheus.WriteRequest")
 	proto.RegisterType((*ReadRequest)(nil), "prometheus.ReadRequest")
 	proto.RegisterType((*ReadResponse)(nil), "prometheus.ReadResponse")
 	proto.RegisterType((*Query)(nil), "prometheus.Query")
 	proto.RegisterType((*QueryResult)(nil), "prometheus.QueryResult")
+	proto.RegisterType((*ChunkedReadResponse)(nil), "prometheus.ChunkedReadResponse")
+	proto.RegisterEnum("prometheus.ReadRequest_ResponseType", ReadRequest_ResponseType_name, ReadRequest_ResponseType_value)
 }
 func (m *WriteRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
@@ -220,6 +289,23 @@ func (m *ReadRequest) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.AcceptedResponseTypes) > 0 {
+		dAtA2 := make([]byte, len(m.AcceptedResponseTypes)*10)
+		var j1 int
+		for _, num := range m.AcceptedResponseTypes {
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintRemote(dAtA, i, uint64(j1))
+		i += copy(dAtA[i:], dAtA2[:j1])
+	}
 	return i, nil
 }
 
@@ -323,6 +409,41 @@ func (m *QueryResult) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *ChunkedReadResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ChunkedReadResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ChunkedSeries) > 0 {
+		for _, msg := range m.ChunkedSeries {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintRemote(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.QueryIndex != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintRemote(dAtA, i, uint64(m.QueryIndex))
+	}
+	return i, nil
+}
+
 func encodeVarintRemote(dAtA []byte, offset int, v uint64) int {
 	for v >= 1<<7 {
 		dAtA[offset] = uint8(v&0x7f | 0x80)
@@ -353,6 +474,13 @@ func (m *ReadRequest) Size() (n int) {
 			n += 1 + l + sovRemote(uint64(l))
 		}
 	}
+	if len(m.AcceptedResponseTypes) > 0 {
+		l = 0
+		for _, e := range m.AcceptedResponseTypes {
+			l += sovRemote(uint64(e))
+		}
+		n += 1 + sovRemote(uint64(l)) + l
+	}
 	return n
 }
 
@@ -398,6 +526,21 @@ func (m *QueryResult) Size() (n int) {
 	return n
 }
 
+func (m *ChunkedReadResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.ChunkedSeries) > 0 {
+		for _, e := range m.ChunkedSeries {
+			l = e.Size()
+			n += 1 + l + sovRemote(uint64(l))
+		}
+	}
+	if m.QueryIndex != 0 {
+		n += 1 + sovRemote(uint64(m.QueryIndex))
+	}
+	return n
+}
+
 func sovRemote(x uint64) (n int) {
 	for {
 		n++
@@ -552,6 +695,68 @@ func (m *ReadRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType == 0 {
+				var v ReadRequest_ResponseType
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRemote
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= (ReadRequest_ResponseType(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.AcceptedResponseTypes = append(m.AcceptedResponseTypes, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRemote
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthRemote
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v ReadRequest_ResponseType
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRemote
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= (ReadRequest_ResponseType(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.AcceptedResponseTypes = append(m.AcceptedResponseTypes, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field AcceptedResponseTypes", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRemote(dAtA[iNdEx:])
@@ -854,6 +1059,106 @@ func (m *QueryResult) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *ChunkedReadResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRemote
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ChunkedReadResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ChunkedReadResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkedSeries", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRemote
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRemote
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChunkedSeries = append(m.ChunkedSeries, &ChunkedSeries{})
+			if err := m.ChunkedSeries[len(m.ChunkedSeries)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueryIndex", wireType)
+			}
+			m.QueryIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRemote
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.QueryIndex |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRemote(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRemote
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipRemote(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -964,26 +1269,34 @@ func init() {
 }
 
 var fileDescriptorRemote = []byte{
-	// 328 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x91, 0xbf, 0x4a, 0xc3, 0x50,
-	0x14, 0xc6, 0x8d, 0xc5, 0x56, 0x4e, 0x8b, 0xd4, 0x0c, 0x1a, 0x1c, 0x82, 0x64, 0x2a, 0x28, 0xb9,
-	0x68, 0xc5, 0xc1, 0xc5, 0x3f, 0xa0, 0x93, 0x1d, 0xbc, 0x16, 0x04, 0x97, 0x92, 0x34, 0x87, 0x36,
-	0xd0, 0x9b, 0xa4, 0xf7, 0x9c, 0x0c, 0x7d, 0x0b, 0x17, 0xdf, 0xc9, 0xd1, 0x47, 0x90, 0xf8, 0x22,
-	0x92, 0x1b, 0x62, 0x23, 0x6e, 0x5d, 0x32, 0x9c, 0xef, 0x77, 0xbe, 0xfc, 0xb8, 0x07, 0x6e, 0x66,
-	0x31, 0xcf, 0xf3, 0xd0, 0x9f, 0xa6, 0x4a, 0xa8, 0x61, 0x14, 0x0a, 0x35, 0x14, 0xa4, 0xa7, 0x62,
-	0x99, 0xa3, 0x5e, 0x89, 0x19, 0x26, 0xa8, 0x03, 0xc6, 0x48, 0x64, 0x3a, 0xe5, 0xb4, 0xfc, 0xaa,
-	0x2c, 0x14, 0x1a, 0x55, 0xca, 0xe8, 0x9b, 0x99, 0x0d, 0xe5, 0x10, 0x79, 0x8e, 0x39, 0x1d, 0x5d,
-	0x6f, 0xd2, 0xc6, 0xab, 0x0c, 0xa9, 0x2a, 0xf3, 0x1e, 0xa0, 0xf7, 0xa2, 0x63, 0x46, 0x89, 0xcb,
-	0x1c, 0x89, 0xed, 0x4b, 0x00, 0x8e, 0x15, 0x12, 0xea, 0x18, 0xc9, 0xb1, 0x8e, 0x5b, 0x83, 0xee,
-	0xf9, 0x81, 0xbf, 0xfe, 0xa3, 0x3f, 0x8e, 0x15, 0x3e, 0x9b, 0x54, 0x36, 0x48, 0xef, 0x0a, 0xba,
-	0x12, 0x83, 0xa8, 0xae, 0x39, 0x81, 0x4e, 0xa9, 0xb0, 0xee, 0xd8, 0x6f, 0x76, 0x3c, 0x95, 0x76,
-	0xb2, 0x26, 0xbc, 0x5b, 0xe8, 0x55, 0xbb, 0x94, 0xa5, 0x09, 0xa1, 0x7d, 0x06, 0x1d, 0x8d, 0x94,
-	0x2f, 0xb8, 0x5e, 0x3e, 0xfc, 0xbf, 0x6c, 0x72, 0x59, 0x73, 0xde, 0xbb, 0x05, 0x3b, 0x26, 0xb0,
-	0x4f, 0xc1, 0x26, 0x0e, 0x34, 0x4f, 0x8c, 0x1c, 0x07, 0x2a, 0x9b, 0xa8, 0xb2, 0xc7, 0x1a, 0xb4,
-	0x64, 0xdf, 0x24, 0xe3, 0x3a, 0x18, 0x91, 0x3d, 0x80, 0x3e, 0x26, 0xd1, 0x5f, 0x76, 0xdb, 0xb0,
-	0x7b, 0x98, 0x44, 0x4d, 0xf2, 0x02, 0x76, 0x55, 0xc0, 0xd3, 0x39, 0x6a, 0x72, 0x5a, 0xc6, 0xca,
-	0x69, 0x5a, 0x3d, 0x06, 0x21, 0x2e, 0x46, 0x15, 0x20, 0x7f, 0x49, 0xef, 0x1e, 0xba, 0x0d, 0xdf,
-	0x4d, 0x5f, 0xf7, 0xce, 0xf9, 0x28, 0x5c, 0xeb, 0xb3, 0x70, 0xad, 0xaf, 0xc2, 0xb5, 0xde, 0xbe,
-	0xdd, 0xad, 0xd7, 0x76, 0x75, 0xcb, 0xb0, 0x6d, 0xce, 0x38, 0xfc, 0x09, 0x00, 0x00, 0xff, 0xff,
-	0x6c, 0xdc, 0xb6, 0x36, 0x57, 0x02, 0x00, 0x00,
+	// 452 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9d, 0x52, 0xcb, 0x4e, 0xdb, 0x40,
+	0x14, 0xc5, 0x44, 0x25, 0xe8, 0x9a, 0x46, 0xe9, 0x44, 0x6d, 0x4c, 0x17, 0x50, 0x59, 0x5d, 0x44,
+	0x6a, 0x15, 0x8b, 0x87, 0xba, 0x85, 0x14, 0x82, 0x5a, 0x41, 0xfa, 0x18, 0xa7, 0x02, 0x55, 0x48,
+	0x96, 0x1f, 0x57, 0xc4, 0x02, 0x3f, 0x98, 0x19, 0x4b, 0xf0, 0x17, 0x6c, 0xf8, 0x27, 0x56, 0x88,
+	0x4f, 0x40, 0xf0, 0x23, 0x8c, 0xc7, 0x31, 0x0c, 0x62, 0xc7, 0x62, 0x46, 0x9e, 0x73, 0xce, 0x3d,
+	0x73, 0xe6, 0xfa, 0xc2, 0xe6, 0x51, 0x2c, 0x26, 0x45, 0xd0, 0x0f, 0xb3, 0xc4, 0x49, 0xd6, 0xa2,
+	0x40, 0x6e, 0x0e, 0x67, 0xa1, 0x73, 0x5a, 0x20, 0x3b, 0x77, 0x8e, 0x30, 0x45, 0xe6, 0x0b, 0x8c,
+	0x9c, 0x9c, 0x65, 0x22, 0x2b, 0xf7, 0x24, 0x0f, 0x1c, 0x86, 0x49, 0x26, 0xb0, 0xaf, 0x30, 0x02,
+	0x25, 0x88, 0x62, 0x82, 0x05, 0xff, 0xb8, 0xf1, 0x1a, 0x37, 0x71, 0x9e, 0x23, 0xaf, 0xcc, 0xec,
+	0x1d, 0x58, 0xd8, 0x67, 0xb1, 0x40, 0x8a, 0xb2, 0x84, 0x0b, 0xf2, 0x0d, 0x40, 0xc4, 0x09, 0x72,
+	0x64, 0x31, 0x72, 0xcb, 0xf8, 0xd4, 0xe8, 0x99, 0xab, 0x1f, 0xfa, 0x4f, 0x37, 0xf6, 0xc7, 0x92,
+	0x75, 0x15, 0x4b, 0x35, 0xa5, 0x7d, 0x6d, 0x80, 0x49, 0xd1, 0x8f, 0x6a, 0x9f, 0x2f, 0xd0, 0x2c,
+	0x33, 0x3c, 0x99, 0xbc, 0xd3, 0x4d, 0xfe, 0x96, 0xf1, 0x68, 0xad, 0x20, 0x87, 0xd0, 0xf5, 0xc3,
+	0x10, 0x73, 0x99, 0xd4, 0x63, 0xc8, 0xf3, 0x2c, 0xe5, 0xe8, 0xa9, 0x94, 0xd6, 0xac, 0x2c, 0x6e,
+	0xad, 0x7e, 0xd6, 0x8b, 0xb5, 0x6b, 0xe4, 0x77, 0xa5, 0x1e, 0x4b, 0x31, 0x7d, 0x5f, 0x9b, 0xe8,
+	0x28, 0xb7, 0xd7, 0x61, 0x41, 0x07, 0x88, 0x09, 0x4d, 0x77, 0x30, 0xfa, 0xb3, 0x37, 0x74, 0xdb,
+	0x33, 0xa4, 0x0b, 0x1d, 0x77, 0x4c, 0x87, 0x83, 0xd1, 0x70, 0xdb, 0x3b, 0xf8, 0x4d, 0xbd, 0xad,
+	0x1f, 0xff, 0x7e, 0xed, 0xba, 0x6d, 0xc3, 0x1e, 0x94, 0x55, 0xfe, 0xa3, 0x15, 0x59, 0x81, 0xa6,
+	0x8c, 0x56, 0x9c, 0x88, 0xfa, 0x41, 0xdd, 0x97, 0x0f, 0x52, 0x3c, 0xad, 0x75, 0xf6, 0xa5, 0x01,
+	0x6f, 0x14, 0x41, 0xbe, 0x02, 0xe1, 0xc2, 0x67, 0xc2, 0x53, 0x1d, 0x13, 0x7e, 0x92, 0x7b, 0x49,
+	0xe9, 0x63, 0xf4, 0x1a, 0xb4, 0xad, 0x98, 0x71, 0x4d, 0x8c, 0x38, 0xe9, 0x41, 0x1b, 0xd3, 0xe8,
+	0xb9, 0x76, 0x56, 0x69, 0x5b, 0x12, 0xd7, 0x95, 0xeb, 0x30, 0x9f, 0xf8, 0x22, 0x9c, 0x20, 0xe3,
+	0x56, 0x43, 0xa5, 0xb2, 0xf4, 0x54, 0x7b, 0x7e, 0x80, 0x27, 0xa3, 0x4a, 0x40, 0x1f, 0x95, 0xf6,
+	0x10, 0x4c, 0x2d, 0xef, 0xab, 0x7f, 0xf9, 0x19, 0x74, 0xb6, 0x26, 0x45, 0x7a, 0x5c, 0xf6, 0x5b,
+	0x6b, 0xd4, 0x26, 0xb4, 0xc2, 0x0a, 0xf6, 0x9e, 0x59, 0x2e, 0xea, 0x96, 0xd3, 0xc2, 0xa9, 0xeb,
+	0xdb, 0x50, 0x3f, 0x92, 0x65, 0x30, 0xd5, 0xfc, 0x7a, 0x71, 0x1a, 0xe1, 0xd9, 0xf4, 0xe9, 0xa0,
+	0xa0, 0x9f, 0x25, 0xf2, 0xdd, 0xba, 0xba, 0x5b, 0x32, 0x6e, 0xe4, 0xba, 0x95, 0xeb, 0xe2, 0x7e,
+	0x69, 0xe6, 0xff, 0x5c, 0x35, 0xda, 0xc1, 0x9c, 0x9a, 0xea, 0xb5, 0x07, 0x76, 0x35, 0x7d, 0x4a,
+	0x66, 0x03, 0x00, 0x00,
 }
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/query/graphite/context"
+	"github.com/m3db/m3/src/query/graphite/storage"
 )
 
 // contextBase are the real content of a Context, minus the lock so that we
@@ -54,6 +55,11 @@ type contextBase struct {
 	// specify zero to indicate default timeout or a positive value
 	Timeout time.Duration
 
+	// MaxDataPoints is the max number of datapoints each series is
+	// consolidated to when rendered, zero if unbounded; it allows fetching
+	// data at a coarser resolution.
+	MaxDataPoints int64
+
 	parent         *Context
 	reqCtx         ctx.Context
 	storageContext context.Context
@@ -67,10 +73,11 @@ type Context struct {
 
 // ContextOptions provides the options to create the context with
 type ContextOptions struct {
-	Start   time.Time
-	End     time.Time
-	Engine  QueryEngine
-	Timeout time.Duration
+	Start         time.Time
+	End           time.Time
+	Engine        QueryEngine
+	Timeout       time.Duration
+	MaxDataPoints int64
 }
 
 // TimeRangeAdjustment is an applied time range adjustment.
@@ -92,6 +99,7 @@ func NewContext(options ContextOptions) *Context {
 			Engine:         options.Engine,
 			storageContext: context.New(),
 			Timeout:        options.Timeout,
+			MaxDataPoints:  options.MaxDataPoints,
 		},
 	}
 }
@@ -99,6 +107,18 @@ func NewContext(options ContextOptions) *Context {
 // TracingEnabled checks whether tracing is enabled for this context.
 func (c *Context) TracingEnabled() bool { return c.Trace != nil }
 
+// FetchOptions returns the options to fetch data for this context with.
+func (c *Context) FetchOptions() storage.FetchOptions {
+	return storage.FetchOptions{
+		StartTime: c.StartTime,
+		EndTime:   c.EndTime,
+		DataOptions: storage.DataOptions{
+			Timeout:       c.Timeout,
+			MaxDataPoints: c.MaxDataPoints,
+		},
+	}
+}
+
 // ChildContextOptions is a set of options to pass when creating a child context.
 type ChildContextOptions struct {
 	adjustment struct {
@@ -20,7 +20,11 @@
 
 package graphite
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 const (
 	// graphiteFormat is the format for graphite metric tag names, which will be
@@ -38,6 +42,23 @@ const (
 
 	// MatchAllPattern that is used to match all metrics.
 	MatchAllPattern = ".*"
+
+	// TaggedNameSeparator separates the path of a graphite tagged series name
+	// from its tags, and the tags from each other.
+	// NB: cpu.load;dc=us-east;host=a is the series cpu.load with tags dc and host.
+	TaggedNameSeparator = ";"
+
+	// TagValueSeparator separates tag names from their values in graphite
+	// tagged series names.
+	TagValueSeparator = "="
+
+	// NameTag is the tag that refers to the path of a graphite tagged series.
+	NameTag = "name"
+
+	// TaggedPathTag is the reserved tag holding the path of a graphite tagged
+	// series, which tells tagged series apart from series only identified by
+	// their path nodes.
+	TaggedPathTag = "__gpath__"
 )
 
 var (
@@ -65,3 +86,46 @@ func TagName(idx int) []byte {
 func generateTagName(idx int) []byte {
 	return []byte(fmt.Sprintf(graphiteFormat, idx))
 }
+
+// ParseTaggedName splits a graphite tagged series name into its path and its
+// tags, which include the path as the name tag. Names without tags are
+// returned as is with only the name tag.
+func ParseTaggedName(name string) (string, map[string]string) {
+	parts := strings.Split(name, TaggedNameSeparator)
+	path := parts[0]
+	tags := make(map[string]string, len(parts))
+	for _, part := range parts[1:] {
+		idx := strings.Index(part, TagValueSeparator)
+		if idx <= 0 {
+			continue
+		}
+
+		tags[part[:idx]] = part[idx+1:]
+	}
+
+	tags[NameTag] = path
+	return path, tags
+}
+
+// FormatTaggedName formats a path and tags as a graphite tagged series name,
+// with tags sorted by name. Any name tag is ignored in favor of the path.
+func FormatTaggedName(path string, tags map[string]string) string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		if name != NameTag {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(path)
+	for _, name := range names {
+		b.WriteString(TaggedNameSeparator)
+		b.WriteString(name)
+		b.WriteString(TagValueSeparator)
+		b.WriteString(tags[name])
+	}
+
+	return b.String()
+}
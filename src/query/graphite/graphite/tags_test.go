@@ -33,3 +33,27 @@ func TestTagName(t *testing.T) {
 		require.Equal(t, expected, TagName(i))
 	}
 }
+
+func TestParseTaggedName(t *testing.T) {
+	path, tags := ParseTaggedName("cpu.load;host=a;dc=us-east")
+	require.Equal(t, "cpu.load", path)
+	require.Equal(t, map[string]string{
+		"name": "cpu.load",
+		"dc":   "us-east",
+		"host": "a",
+	}, tags)
+
+	path, tags = ParseTaggedName("cpu.load")
+	require.Equal(t, "cpu.load", path)
+	require.Equal(t, map[string]string{"name": "cpu.load"}, tags)
+}
+
+func TestFormatTaggedName(t *testing.T) {
+	name := FormatTaggedName("cpu.load", map[string]string{
+		"name": "ignored",
+		"host": "a",
+		"dc":   "us-east",
+	})
+	require.Equal(t, "cpu.load;dc=us-east;host=a", name)
+	require.Equal(t, "cpu.load", FormatTaggedName("cpu.load", nil))
+}
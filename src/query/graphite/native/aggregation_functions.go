@@ -23,10 +23,12 @@ package native
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/m3db/m3/src/query/graphite/common"
 	"github.com/m3db/m3/src/query/graphite/errors"
+	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/graphite/ts"
 )
 
@@ -274,6 +276,67 @@ func groupByNode(ctx *common.Context, series singlePathSpec, node int, fname str
 	return r, nil
 }
 
+// groupByTags takes a serieslist and maps a callback to subgroups within as
+// defined by the values of the given tags. The resulting series are named
+// using graphite tagged names, i.e. `sum;dc=us-east;host=a`, with the "name"
+// tag as the path if it is one of the grouping tags, or the callback
+// otherwise.
+func groupByTags(ctx *common.Context, series singlePathSpec, fname string, tags ...string) (ts.SeriesList, error) {
+	if len(tags) == 0 {
+		err := errors.NewInvalidParamsError(errors.New("groupByTags requires at least one tag"))
+		return ts.SeriesList{}, err
+	}
+
+	if fname == "" {
+		fname = "sum"
+	}
+
+	f, fexists := summarizeFuncs[fname]
+	if !fexists {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf("invalid func %s", fname))
+	}
+
+	metaSeries := make(map[string][]*ts.Series)
+	for _, s := range series.Values {
+		path, seriesTags := graphite.ParseTaggedName(s.Name())
+		groupTags := make(map[string]string, len(tags))
+		name := fname
+		for _, tag := range tags {
+			if tag == graphite.NameTag {
+				name = path
+				continue
+			}
+
+			groupTags[tag] = seriesTags[tag]
+		}
+
+		key := graphite.FormatTaggedName(name, groupTags)
+		metaSeries[key] = append(metaSeries[key], s)
+	}
+
+	keys := make([]string, 0, len(metaSeries))
+	for key := range metaSeries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	newSeries := make([]*ts.Series, 0, len(metaSeries))
+	for _, key := range keys {
+		seriesList := ts.SeriesList{Values: metaSeries[key]}
+		output, err := combineSeries(ctx, multiplePathSpecs(seriesList), key, f.consolidationFunc)
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+		output.Values[0].Specification = f.specificationFunc(seriesList)
+		newSeries = append(newSeries, output.Values...)
+	}
+
+	r := ts.SeriesList(series)
+	r.Values = newSeries
+	r.SortApplied = false
+	return r, nil
+}
+
 // combineSeries combines multiple series into a single series using a
 // consolidation func.  If the series use different time intervals, the
 // coarsest time will apply.
@@ -414,3 +477,394 @@ func countSeries(ctx *common.Context, seriesList multiplePathSpecs) (ts.SeriesLi
 	r.Values = count.Values
 	return r, nil
 }
+
+// aggFunc reduces a set of values, which may include NaNs, to a single value.
+// NaN is returned if there are no values to reduce.
+type aggFunc func(values []float64) float64
+
+var (
+	aggFuncs = map[string]aggFunc{
+		"average":  aggAverage,
+		"avg":      aggAverage,
+		"avg_zero": aggAverageZero,
+		"median":   aggMedian,
+		"sum":      aggSum,
+		"total":    aggSum,
+		"min":      aggMin,
+		"max":      aggMax,
+		"diff":     aggDiff,
+		"stddev":   aggStdDev,
+		"count":    aggCount,
+		"range":    aggRange,
+		"rangeOf":  aggRange,
+		"multiply": aggMultiply,
+		"last":     aggLast,
+		"current":  aggLast,
+	}
+)
+
+// getAggFunc returns the aggregation function with the given graphite name.
+func getAggFunc(fname string) (aggFunc, error) {
+	f, ok := aggFuncs[fname]
+	if !ok {
+		return nil, errors.NewInvalidParamsError(fmt.Errorf("unsupported aggregation function %s", fname))
+	}
+
+	return f, nil
+}
+
+// xFilesFactorMet returns true if the ratio of non-null values to all values
+// is at least the given xFilesFactor, as graphite requires before aggregating.
+func xFilesFactorMet(nonNull, total int, xFilesFactor float64) bool {
+	if nonNull == 0 || total == 0 {
+		return false
+	}
+
+	return float64(nonNull)/float64(total) >= xFilesFactor
+}
+
+func safeValues(values []float64) []float64 {
+	safe := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			safe = append(safe, v)
+		}
+	}
+
+	return safe
+}
+
+func aggSum(values []float64) float64 {
+	sum, count := 0.0, 0
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += v
+			count++
+		}
+	}
+
+	if count == 0 {
+		return math.NaN()
+	}
+
+	return sum
+}
+
+func aggAverage(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	return aggSum(safe) / float64(len(safe))
+}
+
+func aggAverageZero(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += v
+		}
+	}
+
+	return sum / float64(len(values))
+}
+
+func aggMedian(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	sort.Float64s(safe)
+	mid := len(safe) / 2
+	if len(safe)%2 == 0 {
+		return (safe[mid-1] + safe[mid]) / 2
+	}
+
+	return safe[mid]
+}
+
+func aggMin(values []float64) float64 {
+	min := math.NaN()
+	for _, v := range values {
+		if !math.IsNaN(v) && (math.IsNaN(min) || v < min) {
+			min = v
+		}
+	}
+
+	return min
+}
+
+func aggMax(values []float64) float64 {
+	max := math.NaN()
+	for _, v := range values {
+		if !math.IsNaN(v) && (math.IsNaN(max) || v > max) {
+			max = v
+		}
+	}
+
+	return max
+}
+
+func aggDiff(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	diff := safe[0]
+	for _, v := range safe[1:] {
+		diff -= v
+	}
+
+	return diff
+}
+
+func aggStdDev(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	avg := aggSum(safe) / float64(len(safe))
+	variance := 0.0
+	for _, v := range safe {
+		variance += (v - avg) * (v - avg)
+	}
+
+	return math.Sqrt(variance / float64(len(safe)))
+}
+
+func aggCount(values []float64) float64 {
+	return float64(len(safeValues(values)))
+}
+
+func aggRange(values []float64) float64 {
+	return aggMax(values) - aggMin(values)
+}
+
+func aggMultiply(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	// NB: graphite multiplies to null if any of the values are null.
+	product := 1.0
+	for _, v := range values {
+		product *= v
+	}
+
+	return product
+}
+
+func aggLast(values []float64) float64 {
+	for i := len(values) - 1; i >= 0; i-- {
+		if !math.IsNaN(values[i]) {
+			return values[i]
+		}
+	}
+
+	return math.NaN()
+}
+
+// seriesReducer returns a series reducer applying the aggregation function to
+// all values of a series.
+func seriesReducer(f aggFunc) ts.SeriesReducer {
+	return func(series *ts.Series) float64 {
+		values := make([]float64, series.Len())
+		for i := range values {
+			values[i] = series.ValueAt(i)
+		}
+
+		return f(values)
+	}
+}
+
+// aggregate aggregates a series list into a single series using the given
+// aggregation function, i.e. `aggregate(host.cpu-[0-7].cpu-user.value, "sum")`.
+// Steps with a ratio of non-null values below the xFilesFactor are null.
+func aggregate(
+	ctx *common.Context,
+	series singlePathSpec,
+	fname string,
+	xFilesFactor float64,
+) (ts.SeriesList, error) {
+	f, err := getAggFunc(fname)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	if len(series.Values) == 0 {
+		return ts.SeriesList(series), nil
+	}
+
+	normalized, start, _, millisPerStep, err := common.Normalize(ctx, ts.SeriesList(series))
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	numSteps := normalized.Values[0].Len()
+	vals := ts.NewValues(ctx, millisPerStep, numSteps)
+	row := make([]float64, len(normalized.Values))
+	for i := 0; i < numSteps; i++ {
+		nonNull := 0
+		for j, s := range normalized.Values {
+			row[j] = math.NaN()
+			if i < s.Len() {
+				row[j] = s.ValueAt(i)
+			}
+
+			if !math.IsNaN(row[j]) {
+				nonNull++
+			}
+		}
+
+		if xFilesFactorMet(nonNull, len(row), xFilesFactor) {
+			vals.SetValueAt(i, f(row))
+		}
+	}
+
+	name := wrapPathExpr(fname+"Series", ts.SeriesList(series))
+	result := ts.NewSeries(ctx, name, start, vals)
+	return ts.SeriesList{Values: []*ts.Series{result}}, nil
+}
+
+// applyByNode groups the series by their path up to and including the given
+// node, and evaluates the template function for each group with every `%`
+// replaced by the group's path, optionally renaming the results with newName
+// in which `%` is also replaced by the group's path.
+func applyByNode(
+	ctx *common.Context,
+	series singlePathSpec,
+	nodeNum int,
+	templateFunction string,
+	newName string,
+) (ts.SeriesList, error) {
+	prefixes := make(map[string]struct{}, len(series.Values))
+	for _, s := range series.Values {
+		parts := strings.Split(s.Name(), ".")
+		if nodeNum < 0 || nodeNum >= len(parts) {
+			err := errors.NewInvalidParamsError(fmt.Errorf(
+				"could not apply to %s by node %d; not enough parts", s.Name(), nodeNum))
+			return ts.SeriesList{}, err
+		}
+
+		prefixes[strings.Join(parts[:nodeNum+1], ".")] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(prefixes))
+	for prefix := range prefixes {
+		sorted = append(sorted, prefix)
+	}
+	sort.Strings(sorted)
+
+	results := make([]*ts.Series, 0, len(sorted))
+	for _, prefix := range sorted {
+		expr, err := compile(strings.Replace(templateFunction, "%", prefix, -1))
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		applied, err := expr.Execute(ctx)
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		for _, s := range applied.Values {
+			if newName != "" {
+				s = s.RenamedTo(strings.Replace(newName, "%", prefix, -1))
+			}
+
+			s.Specification = prefix
+			results = append(results, s)
+		}
+	}
+
+	r := ts.SeriesList(series)
+	r.Values = results
+	r.SortApplied = false
+	return r, nil
+}
+
+// divideSeriesLists divides each series of the dividend list by the series
+// at the same position of the divisor list.
+func divideSeriesLists(
+	ctx *common.Context,
+	dividendSeriesList, divisorSeriesList singlePathSpec,
+) (ts.SeriesList, error) {
+	if len(dividendSeriesList.Values) != len(divisorSeriesList.Values) {
+		err := errors.NewInvalidParamsError(fmt.Errorf(
+			"divideSeriesLists arguments must have the same length but have %d and %d",
+			len(dividendSeriesList.Values), len(divisorSeriesList.Values)))
+		return ts.SeriesList{}, err
+	}
+
+	results := make([]*ts.Series, 0, len(dividendSeriesList.Values))
+	for i, dividend := range dividendSeriesList.Values {
+		quotient, err := divideSeries(ctx,
+			singlePathSpec{Values: []*ts.Series{dividend}},
+			singlePathSpec{Values: []*ts.Series{divisorSeriesList.Values[i]}})
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		results = append(results, quotient.Values...)
+	}
+
+	r := ts.SeriesList(dividendSeriesList)
+	r.Values = results
+	return r, nil
+}
+
+// safePow raises x to the power of y, returning NaN rather than an infinite
+// or complex result, or if either value is NaN.
+func safePow(x, y float64) float64 {
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return math.NaN()
+	}
+
+	v := math.Pow(x, y)
+	if math.IsInf(v, 0) {
+		return math.NaN()
+	}
+
+	return v
+}
+
+// powSeries raises the first series to the power of the second series, and
+// that result to the power of the third series, and so on at each datapoint.
+func powSeries(ctx *common.Context, series multiplePathSpecs) (ts.SeriesList, error) {
+	if len(series.Values) == 0 {
+		return ts.SeriesList(series), nil
+	}
+
+	normalized, start, _, millisPerStep, err := common.Normalize(ctx, ts.SeriesList(series))
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	names := make([]string, 0, len(normalized.Values))
+	for _, s := range normalized.Values {
+		names = append(names, s.Name())
+	}
+
+	numSteps := normalized.Values[0].Len()
+	vals := ts.NewValues(ctx, millisPerStep, numSteps)
+	for i := 0; i < numSteps; i++ {
+		v := normalized.Values[0].ValueAt(i)
+		for _, s := range normalized.Values[1:] {
+			v = safePow(v, s.ValueAt(i))
+		}
+
+		vals.SetValueAt(i, v)
+	}
+
+	name := fmt.Sprintf("powSeries(%s)", strings.Join(names, ","))
+	result := ts.NewSeries(ctx, name, start, vals)
+	return ts.SeriesList{Values: []*ts.Series{result}}, nil
+}
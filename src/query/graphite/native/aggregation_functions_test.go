@@ -137,18 +137,16 @@ type mockEngine struct {
 	fn func(
 		ctx context.Context,
 		query string,
-		start, end time.Time,
-		timeout time.Duration,
+		opts storage.FetchOptions,
 	) (*storage.FetchResult, error)
 }
 
 func (e mockEngine) FetchByQuery(
 	ctx context.Context,
 	query string,
-	start, end time.Time,
-	timeout time.Duration,
+	opts storage.FetchOptions,
 ) (*storage.FetchResult, error) {
-	return e.fn(ctx, query, start, end, timeout)
+	return e.fn(ctx, query, opts)
 }
 
 func TestVariadicSumSeries(t *testing.T) {
@@ -159,19 +157,18 @@ func TestVariadicSumSeries(t *testing.T) {
 	ctx.Engine = mockEngine{fn: func(
 		ctx context.Context,
 		query string,
-		start, end time.Time,
-		timeout time.Duration,
+		opts storage.FetchOptions,
 	) (*storage.FetchResult, error) {
 		switch query {
 		case "foo.bar.*":
 			return storage.NewFetchResult(ctx, []*ts.Series{
-				ts.NewSeries(ctx, "foo.bar.a", start, ts.NewConstantValues(ctx, 1, 3, 1000)),
-				ts.NewSeries(ctx, "foo.bar.b", start, ts.NewConstantValues(ctx, 2, 3, 1000)),
+				ts.NewSeries(ctx, "foo.bar.a", opts.StartTime, ts.NewConstantValues(ctx, 1, 3, 1000)),
+				ts.NewSeries(ctx, "foo.bar.b", opts.StartTime, ts.NewConstantValues(ctx, 2, 3, 1000)),
 			}), nil
 		case "foo.baz.*":
 			return storage.NewFetchResult(ctx, []*ts.Series{
-				ts.NewSeries(ctx, "foo.baz.a", start, ts.NewConstantValues(ctx, 3, 3, 1000)),
-				ts.NewSeries(ctx, "foo.baz.b", start, ts.NewConstantValues(ctx, 4, 3, 1000)),
+				ts.NewSeries(ctx, "foo.baz.a", opts.StartTime, ts.NewConstantValues(ctx, 3, 3, 1000)),
+				ts.NewSeries(ctx, "foo.baz.b", opts.StartTime, ts.NewConstantValues(ctx, 4, 3, 1000)),
 			}), nil
 		}
 		return nil, fmt.Errorf("unexpected query: %s", query)
@@ -395,6 +392,71 @@ func TestGroupByNode(t *testing.T) {
 	}
 }
 
+func TestGroupByTags(t *testing.T) {
+	var (
+		start, _ = time.Parse(time.RFC1123, "Mon, 27 Jul 2015 19:41:19 GMT")
+		end, _   = time.Parse(time.RFC1123, "Mon, 27 Jul 2015 19:43:19 GMT")
+		ctx      = common.NewContext(common.ContextOptions{Start: start, End: end})
+		inputs   = []*ts.Series{
+			ts.NewSeries(ctx, "cpu.load;dc=us-east;host=a", start,
+				ts.NewConstantValues(ctx, 2, 12, 10000)),
+			ts.NewSeries(ctx, "cpu.load;dc=us-east;host=b", start,
+				ts.NewConstantValues(ctx, 4, 12, 10000)),
+			ts.NewSeries(ctx, "cpu.load;dc=us-west;host=c", start,
+				ts.NewConstantValues(ctx, 6, 12, 10000)),
+			ts.NewSeries(ctx, "cpu.idle;dc=us-west;host=c", start,
+				ts.NewConstantValues(ctx, 8, 12, 10000)),
+		}
+	)
+	defer ctx.Close()
+
+	type result struct {
+		name      string
+		sumOfVals float64
+	}
+
+	tests := []struct {
+		fname           string
+		tags            []string
+		expectedResults []result
+	}{
+		{"sum", []string{"dc"}, []result{
+			{"sum;dc=us-east", (2 + 4) * 12},
+			{"sum;dc=us-west", (6 + 8) * 12},
+		}},
+		{"max", []string{"name", "dc"}, []result{
+			{"cpu.idle;dc=us-west", 8 * 12},
+			{"cpu.load;dc=us-east", 4 * 12},
+			{"cpu.load;dc=us-west", 6 * 12},
+		}},
+		{"min", []string{"missing"}, []result{
+			{"min;missing=", 2 * 12},
+		}},
+	}
+
+	for _, test := range tests {
+		outSeries, err := groupByTags(ctx, singlePathSpec{
+			Values: inputs,
+		}, test.fname, test.tags...)
+		require.NoError(t, err)
+		require.Equal(t, len(test.expectedResults), len(outSeries.Values))
+
+		for i, expected := range test.expectedResults {
+			series := outSeries.Values[i]
+			assert.Equal(t, expected.name, series.Name(),
+				"wrong name for %v %s (%d)", test.tags, test.fname, i)
+			assert.Equal(t, expected.sumOfVals, series.SafeSum(),
+				"wrong result for %v %s (%d)", test.tags, test.fname, i)
+		}
+	}
+
+	_, err := groupByTags(ctx, singlePathSpec{Values: inputs}, "sum")
+	require.Error(t, err)
+
+	_, err = groupByTags(ctx, singlePathSpec{Values: inputs}, "unknown", "dc")
+	require.Error(t, err)
+}
+
 func TestWeightedAverage(t *testing.T) {
 	ctx, _ := newConsolidationTestSeries()
 	defer ctx.Close()
@@ -453,3 +515,74 @@ func TestCountSeries(t *testing.T) {
 	common.CompareOutputsAndExpected(t, input[1].MillisPerStep(), input[1].StartTime(),
 		[]common.TestSeries{expected}, results.Values)
 }
+
+func TestAggFuncs(t *testing.T) {
+	nan := math.NaN()
+	values := []float64{4, nan, 1, 3}
+	tests := []struct {
+		fname    string
+		expected float64
+	}{
+		{"average", 8.0 / 3},
+		{"avg_zero", 2},
+		{"median", 3},
+		{"sum", 8},
+		{"min", 1},
+		{"max", 4},
+		{"diff", 0},
+		{"stddev", math.Sqrt(14.0 / 9)},
+		{"count", 3},
+		{"range", 3},
+		{"multiply", nan},
+		{"last", 3},
+	}
+
+	for _, test := range tests {
+		f, err := getAggFunc(test.fname)
+		require.NoError(t, err)
+
+		actual := f(values)
+		if math.IsNaN(test.expected) {
+			assert.True(t, math.IsNaN(actual), test.fname)
+		} else {
+			assert.InDelta(t, test.expected, actual, 1e-9, test.fname)
+		}
+	}
+
+	_, err := getAggFunc("bogus")
+	require.Error(t, err)
+}
+
+func TestXFilesFactorMet(t *testing.T) {
+	assert.True(t, xFilesFactorMet(1, 2, 0.5))
+	assert.False(t, xFilesFactorMet(1, 3, 0.5))
+	assert.True(t, xFilesFactorMet(1, 3, 0))
+	assert.False(t, xFilesFactorMet(0, 3, 0))
+	assert.False(t, xFilesFactorMet(0, 0, 0))
+}
+
+func TestAggregateInvalidFunction(t *testing.T) {
+	ctx, input := newConsolidationTestSeries()
+	defer ctx.Close()
+
+	_, err := aggregate(ctx, singlePathSpec(ts.SeriesList{Values: input}), "bogus", 0)
+	require.Error(t, err)
+}
+
+func TestDivideSeriesListsMismatchedLengths(t *testing.T) {
+	ctx, input := newConsolidationTestSeries()
+	defer ctx.Close()
+
+	_, err := divideSeriesLists(ctx,
+		singlePathSpec(ts.SeriesList{Values: input[:2]}),
+		singlePathSpec(ts.SeriesList{Values: input[:1]}))
+	require.Error(t, err)
+}
+
+func TestApplyByNodeNotEnoughNodes(t *testing.T) {
+	ctx, input := newConsolidationTestSeries()
+	defer ctx.Close()
+
+	_, err := applyByNode(ctx, singlePathSpec(ts.SeriesList{Values: input}), 2, "sumSeries(%)", "")
+	require.Error(t, err)
+}
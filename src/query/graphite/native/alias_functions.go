@@ -21,7 +21,12 @@
 package native
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/m3db/m3/src/query/graphite/common"
+	"github.com/m3db/m3/src/query/graphite/errors"
+	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/graphite/ts"
 )
 
@@ -47,3 +52,48 @@ func aliasByNode(ctx *common.Context, seriesList singlePathSpec, nodes ...int) (
 func aliasSub(ctx *common.Context, input singlePathSpec, search, replace string) (ts.SeriesList, error) {
 	return common.AliasSub(ctx, ts.SeriesList(input), search, replace)
 }
+
+// aliasByTags renames a time series using the values of the given tags, or of
+// the given nodes of the series path when a tag is given as a number.
+func aliasByTags(ctx *common.Context, seriesList singlePathSpec, tags ...genericInterface) (ts.SeriesList, error) {
+	renamed := make([]*ts.Series, 0, len(seriesList.Values))
+	for _, series := range seriesList.Values {
+		name := series.Name()
+		left := strings.LastIndex(name, "(") + 1
+		name = name[left:]
+		if right := strings.IndexAny(name, ",)"); right != -1 {
+			name = name[:right]
+		}
+
+		path, seriesTags := graphite.ParseTaggedName(name)
+		nameParts := strings.Split(path, ".")
+		newNameParts := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			switch t := tag.(type) {
+			case float64:
+				node := int(t)
+				if node < 0 {
+					node += len(nameParts)
+				}
+				if node < 0 || node >= len(nameParts) {
+					continue
+				}
+				newNameParts = append(newNameParts, nameParts[node])
+			case string:
+				if value, ok := seriesTags[t]; ok {
+					newNameParts = append(newNameParts, value)
+				}
+			default:
+				err := errors.NewInvalidParamsError(fmt.Errorf(
+					"tags must be strings or node numbers, received %v", tag))
+				return ts.SeriesList{}, err
+			}
+		}
+
+		newName := strings.Join(newNameParts, ".")
+		renamed = append(renamed, series.RenamedTo(newName))
+	}
+
+	seriesList.Values = renamed
+	return ts.SeriesList(seriesList), nil
+}
@@ -194,6 +194,32 @@ func TestAliasByNode(t *testing.T) {
 	assert.Equal(t, "P75", results.Values[2].Name())
 }
 
+func TestAliasByTags(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	now := time.Now()
+	values := ts.NewConstantValues(ctx, 10.0, 1000, 10)
+
+	series := []*ts.Series{
+		ts.NewSeries(ctx, "cpu.load;dc=us-east;host=a", now, values),
+		ts.NewSeries(ctx, "sumSeries(cpu.idle;dc=us-west)", now, values),
+	}
+
+	results, err := aliasByTags(ctx, singlePathSpec{
+		Values: series,
+	}, "host", 1.0, "dc", "name")
+	require.NoError(t, err)
+	require.Equal(t, len(series), results.Len())
+	assert.Equal(t, "a.load.us-east.cpu.load", results.Values[0].Name())
+	assert.Equal(t, "idle.us-west.cpu.idle", results.Values[1].Name())
+
+	_, err = aliasByTags(ctx, singlePathSpec{
+		Values: series,
+	}, true)
+	require.Error(t, err)
+}
+
 func TestAliasByNodeWithComposition(t *testing.T) {
 	ctx := common.NewTestContext()
 	defer ctx.Close()
@@ -27,11 +27,14 @@ import (
 	"math/rand"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/m3db/m3/src/query/graphite/common"
 	"github.com/m3db/m3/src/query/graphite/errors"
+	"github.com/m3db/m3/src/query/graphite/graphite"
+	"github.com/m3db/m3/src/query/graphite/storage"
 	"github.com/m3db/m3/src/query/graphite/ts"
 )
 
@@ -554,12 +557,14 @@ func lowestCurrent(_ *common.Context, input singlePathSpec, n int) (ts.SeriesLis
 // windowSizeFunc calculates window size for moving average calculation
 type windowSizeFunc func(stepSize int) int
 
-// movingAverage calculates the moving average of a metric (or metrics) over a time interval.
-func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue genericInterface) (*binaryContextShifter, error) {
-	if len(input.Values) == 0 {
-		return nil, nil
-	}
-
+// parseWindowSize parses the window size of a moving function, which is
+// either an interval string or a number of points, returning the duration of
+// the window, a function computing the number of points in the window for a
+// given step size, and the window size formatted for use in series names.
+func parseWindowSize(
+	input singlePathSpec,
+	windowSizeValue genericInterface,
+) (time.Duration, windowSizeFunc, string, error) {
 	var delta time.Duration
 	var wf windowSizeFunc
 	var ws string
@@ -568,13 +573,13 @@ func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue ge
 	case string:
 		interval, err := common.ParseInterval(windowSizeValue)
 		if err != nil {
-			return nil, err
+			return 0, nil, "", err
 		}
 		if interval <= 0 {
 			err := errors.NewInvalidParamsError(fmt.Errorf(
 				"windowSize must be positive but instead is %v",
 				interval))
-			return nil, err
+			return 0, nil, "", err
 		}
 		wf = func(stepSize int) int { return int(int64(delta/time.Millisecond) / int64(stepSize)) }
 		ws = fmt.Sprintf("%q", windowSizeValue)
@@ -585,7 +590,7 @@ func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue ge
 			err := errors.NewInvalidParamsError(fmt.Errorf(
 				"windowSize must be positive but instead is %d",
 				windowSizeInt))
-			return nil, err
+			return 0, nil, "", err
 		}
 		wf = func(_ int) int { return windowSizeInt }
 		ws = fmt.Sprintf("%d", windowSizeInt)
@@ -598,6 +603,20 @@ func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue ge
 		err := errors.NewInvalidParamsError(fmt.Errorf(
 			"windowSize must be either a string or an int but instead is a %T",
 			windowSizeValue))
+		return 0, nil, "", err
+	}
+
+	return delta, wf, ws, nil
+}
+
+// movingAverage calculates the moving average of a metric (or metrics) over a time interval.
+func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue genericInterface) (*binaryContextShifter, error) {
+	if len(input.Values) == 0 {
+		return nil, nil
+	}
+
+	delta, wf, ws, err := parseWindowSize(input, windowSizeValue)
+	if err != nil {
 		return nil, err
 	}
 
@@ -679,6 +698,129 @@ func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue ge
 	}, nil
 }
 
+// movingWindow calculates a moving aggregate of a metric (or metrics) over a
+// time interval or a number of points, using the given aggregation function.
+// Points whose window has a ratio of non-null values below the xFilesFactor
+// are null.
+func movingWindow(
+	ctx *common.Context,
+	input singlePathSpec,
+	windowSizeValue genericInterface,
+	fname string,
+	xFilesFactor float64,
+) (*binaryContextShifter, error) {
+	if len(input.Values) == 0 {
+		return nil, nil
+	}
+
+	f, err := getAggFunc(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	delta, wf, ws, err := parseWindowSize(input, windowSizeValue)
+	if err != nil {
+		return nil, err
+	}
+
+	contextShiftingFn := func(c *common.Context) *common.Context {
+		opts := common.NewChildContextOptions()
+		opts.AdjustTimeRange(0, 0, delta, 0)
+		childCtx := c.NewChildContext(opts)
+		return childCtx
+	}
+
+	funcName := "moving" + strings.ToUpper(fname[:1]) + strings.ToLower(fname[1:])
+	bootstrapStartTime, bootstrapEndTime := ctx.StartTime.Add(-delta), ctx.StartTime
+	transformerFn := func(bootstrapped, original ts.SeriesList) (ts.SeriesList, error) {
+		bootstrapList, err := combineBootstrapWithOriginal(ctx,
+			bootstrapStartTime, bootstrapEndTime,
+			bootstrapped, singlePathSpec(original))
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		results := make([]*ts.Series, 0, original.Len())
+		for i, bootstrap := range bootstrapList.Values {
+			series := original.Values[i]
+			stepSize := series.MillisPerStep()
+			windowPoints := wf(stepSize)
+			if windowPoints == 0 {
+				err := errors.NewInvalidParamsError(fmt.Errorf(
+					"windowSize should not be smaller than stepSize, windowSize=%v, stepSize=%d",
+					windowSizeValue, stepSize))
+				return ts.SeriesList{}, err
+			}
+
+			numSteps := series.Len()
+			offset := bootstrap.Len() - numSteps
+			vals := ts.NewValues(ctx, series.MillisPerStep(), numSteps)
+			window := make([]float64, windowPoints)
+			for i := 0; i < numSteps; i++ {
+				// skip if the number of points received is less than the number of points
+				// in the lookback window.
+				if offset < windowPoints {
+					continue
+				}
+
+				nonNull := 0
+				for j := range window {
+					window[j] = bootstrap.ValueAt(i + offset - windowPoints + j)
+					if !math.IsNaN(window[j]) {
+						nonNull++
+					}
+				}
+
+				if xFilesFactorMet(nonNull, windowPoints, xFilesFactor) {
+					vals.SetValueAt(i, f(window))
+				}
+			}
+
+			name := fmt.Sprintf("%s(%s,%s)", funcName, series.Name(), ws)
+			newSeries := ts.NewSeries(ctx, name, series.StartTime(), vals)
+			results = append(results, newSeries)
+		}
+
+		original.Values = results
+		return original, nil
+	}
+
+	return &binaryContextShifter{
+		ContextShiftFunc:  contextShiftingFn,
+		BinaryTransformer: transformerFn,
+	}, nil
+}
+
+// movingSum calculates the moving sum of a metric (or metrics) over a time interval.
+func movingSum(
+	ctx *common.Context,
+	input singlePathSpec,
+	windowSize genericInterface,
+	xFilesFactor float64,
+) (*binaryContextShifter, error) {
+	return movingWindow(ctx, input, windowSize, "sum", xFilesFactor)
+}
+
+// movingMin calculates the moving minimum of a metric (or metrics) over a time interval.
+func movingMin(
+	ctx *common.Context,
+	input singlePathSpec,
+	windowSize genericInterface,
+	xFilesFactor float64,
+) (*binaryContextShifter, error) {
+	return movingWindow(ctx, input, windowSize, "min", xFilesFactor)
+}
+
+// movingMax calculates the moving maximum of a metric (or metrics) over a time interval.
+func movingMax(
+	ctx *common.Context,
+	input singlePathSpec,
+	windowSize genericInterface,
+	xFilesFactor float64,
+) (*binaryContextShifter, error) {
+	return movingWindow(ctx, input, windowSize, "max", xFilesFactor)
+}
+
 // totalFunc takes an index and returns a total value for that index
 type totalFunc func(int) float64
 
@@ -1817,16 +1959,560 @@ func threshold(ctx *common.Context, value float64, label string, color string) (
 	return ts.SeriesList{Values: []*ts.Series{series}}, nil
 }
 
+// seriesByTag returns the series matching all of the given graphite tag
+// expressions, i.e. seriesByTag('name=cpu.load', 'dc=~us-.*').
+func seriesByTag(ctx *common.Context, tagExpressions ...string) (ts.SeriesList, error) {
+	query := storage.SeriesByTagQuery(tagExpressions)
+	result, err := ctx.Engine.FetchByQuery(ctx, query, ctx.FetchOptions())
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	for _, r := range result.SeriesList {
+		r.Specification = query
+	}
+	return ts.SeriesList{Values: result.SeriesList}, nil
+}
+
+// delay shifts all samples later by an integer number of steps, filling the
+// first steps with nulls. Negative steps shift samples earlier, filling the
+// last steps with nulls. This can be used for custom derivative calculations.
+func delay(ctx *common.Context, input singlePathSpec, steps int) (ts.SeriesList, error) {
+	results := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		outvals := ts.NewValues(ctx, series.MillisPerStep(), series.Len())
+		// NB: negative steps shift values back in time.
+		for i := 0; i < series.Len(); i++ {
+			if j := i - steps; j >= 0 && j < series.Len() {
+				outvals.SetValueAt(i, series.ValueAt(j))
+			}
+		}
+
+		newName := fmt.Sprintf("delay(%s,%d)", series.Name(), steps)
+		results = append(results, ts.NewSeries(ctx, newName, series.StartTime(), outvals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// highest takes one metric or a wildcard seriesList followed by an integer n
+// and an aggregation function, and draws only the n metrics with the highest
+// aggregated value for the time period specified.
+func highest(_ *common.Context, input singlePathSpec, n int, fname string) (ts.SeriesList, error) {
+	f, err := getAggFunc(fname)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	return takeByFunction(input, n, seriesReducer(f), ts.Descending)
+}
+
+// lowest takes one metric or a wildcard seriesList followed by an integer n
+// and an aggregation function, and draws only the n metrics with the lowest
+// aggregated value for the time period specified.
+func lowest(_ *common.Context, input singlePathSpec, n int, fname string) (ts.SeriesList, error) {
+	f, err := getAggFunc(fname)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	return takeByFunction(input, n, seriesReducer(f), ts.Ascending)
+}
+
+// floorDiv returns the quotient of n and m rounded towards negative infinity.
+// prerequisite: m is nonzero.
+func floorDiv(n, m int64) int64 {
+	quotient := n / m
+	if (n%m != 0) && ((n < 0) != (m < 0)) {
+		quotient--
+	}
+	return quotient
+}
+
+// integralByInterval shows the sum over time, like integral, except that the
+// sum is reset to zero at the start of every interval, i.e. every day for
+// `integralByInterval(metric, "1d")`. Intervals are aligned to the start time
+// of the query.
+func integralByInterval(
+	ctx *common.Context,
+	input singlePathSpec,
+	intervalUnit string,
+) (ts.SeriesList, error) {
+	interval, err := common.ParseInterval(intervalUnit)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	intervalMillis := int64(interval / time.Millisecond)
+	if intervalMillis < 0 {
+		intervalMillis = -intervalMillis
+	}
+	if intervalMillis == 0 {
+		err := errors.NewInvalidParamsError(fmt.Errorf(
+			"invalid integralByInterval parameter intervalUnit: %s", intervalUnit))
+		return ts.SeriesList{}, err
+	}
+
+	startMillis := ctx.StartTime.UnixNano() / int64(time.Millisecond)
+	results := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		var (
+			stepMillis    = int64(series.MillisPerStep())
+			currentMillis = series.StartTime().UnixNano() / int64(time.Millisecond)
+			current       float64
+			outvals       = ts.NewValues(ctx, series.MillisPerStep(), series.Len())
+		)
+		for i := 0; i < series.Len(); i++ {
+			elapsed := currentMillis - startMillis
+			if floorDiv(elapsed, intervalMillis) != floorDiv(elapsed-stepMillis, intervalMillis) {
+				current = 0
+			}
+
+			// NB: nulls keep the current value since the value may just have been reset.
+			if v := series.ValueAt(i); !math.IsNaN(v) {
+				current += v
+			}
+
+			outvals.SetValueAt(i, current)
+			currentMillis += stepMillis
+		}
+
+		newName := fmt.Sprintf("integralByInterval(%s,'%s')", series.Name(), intervalUnit)
+		results = append(results, ts.NewSeries(ctx, newName, series.StartTime(), outvals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// interpolate fills gaps of nulls between two non-null values with linearly
+// interpolated values. Gaps of more than limit nulls are left as nulls.
+func interpolate(ctx *common.Context, input singlePathSpec, limit float64) (ts.SeriesList, error) {
+	results := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		consecutiveNaNs := 0
+		numSteps := series.Len()
+		outvals := ts.NewValues(ctx, series.MillisPerStep(), numSteps)
+		for i := 0; i < numSteps; i++ {
+			value := series.ValueAt(i)
+			outvals.SetValueAt(i, value)
+			// NB: nothing can be interpolated before the first value.
+			if i == 0 {
+				continue
+			}
+
+			if math.IsNaN(value) {
+				consecutiveNaNs++
+				continue
+			}
+
+			if consecutiveNaNs == 0 {
+				continue
+			}
+
+			lastIndex := i - consecutiveNaNs - 1
+			lastValue := outvals.ValueAt(lastIndex)
+			if !math.IsNaN(lastValue) && float64(consecutiveNaNs) <= limit {
+				for j := lastIndex + 1; j < i; j++ {
+					step := (value - lastValue) / float64(consecutiveNaNs+1)
+					outvals.SetValueAt(j, lastValue+float64(j-lastIndex)*step)
+				}
+			}
+			consecutiveNaNs = 0
+		}
+
+		newName := fmt.Sprintf("interpolate(%s)", series.Name())
+		results = append(results, ts.NewSeries(ctx, newName, series.StartTime(), outvals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// linearRegressionAnalysis returns the factor and offset of the least squares
+// line through the non-null values of a series, in terms of unix seconds, and
+// false if no such line exists.
+func linearRegressionAnalysis(series *ts.Series) (float64, float64, bool) {
+	var n, sumI, sumV, sumII, sumIV float64
+	for i := 0; i < series.Len(); i++ {
+		v := series.ValueAt(i)
+		if math.IsNaN(v) {
+			continue
+		}
+
+		fi := float64(i)
+		n++
+		sumI += fi
+		sumV += v
+		sumII += fi * fi
+		sumIV += fi * v
+	}
+
+	denominator := n*sumII - sumI*sumI
+	if denominator == 0 {
+		return 0, 0, false
+	}
+
+	stepSecs := float64(series.MillisPerStep()) / 1000
+	startSecs := float64(series.StartTime().UnixNano()) / float64(time.Second)
+	factor := (n*sumIV - sumI*sumV) / denominator / stepSecs
+	offset := (sumII*sumV-sumIV*sumI)/denominator - factor*startSecs
+	return factor, offset, true
+}
+
+// linearRegression graphs the linear regression function by the least squares
+// method. The regression is calculated over the time range between
+// startSourceAt and endSourceAt, which default to the time range of the query.
+func linearRegression(
+	ctx *common.Context,
+	input singlePathSpec,
+	startSourceAt, endSourceAt string,
+) (ts.SeriesList, error) {
+	var (
+		sourceCtx = ctx
+		sources   = ts.SeriesList(input)
+		now       = time.Now()
+	)
+	if startSourceAt != "" || endSourceAt != "" {
+		sourceStart, sourceEnd := ctx.StartTime, ctx.EndTime
+		if startSourceAt != "" {
+			t, err := graphite.ParseTime(startSourceAt, now, 0)
+			if err != nil {
+				return ts.SeriesList{}, err
+			}
+			sourceStart = t
+		}
+		if endSourceAt != "" {
+			t, err := graphite.ParseTime(endSourceAt, now, 0)
+			if err != nil {
+				return ts.SeriesList{}, err
+			}
+			sourceEnd = t
+		}
+
+		opts := common.NewChildContextOptions()
+		opts.AdjustTimeRange(sourceStart.Sub(ctx.StartTime), sourceEnd.Sub(ctx.EndTime), 0, 0)
+		sourceCtx = ctx.NewChildContext(opts)
+
+		sources.Values = make([]*ts.Series, 0, len(input.Values))
+		for _, series := range input.Values {
+			expr, err := compile(series.Specification)
+			if err != nil {
+				return ts.SeriesList{}, err
+			}
+
+			source, err := expr.Execute(sourceCtx)
+			if err != nil {
+				return ts.SeriesList{}, err
+			}
+			sources.Values = append(sources.Values, source.Values...)
+		}
+	}
+
+	results := make([]*ts.Series, 0, len(input.Values))
+	for i, series := range input.Values {
+		if i >= len(sources.Values) {
+			break
+		}
+
+		factor, offset, ok := linearRegressionAnalysis(sources.Values[i])
+		if !ok {
+			continue
+		}
+
+		var (
+			startSecs = float64(series.StartTime().UnixNano()) / float64(time.Second)
+			stepSecs  = float64(series.MillisPerStep()) / 1000
+			outvals   = ts.NewValues(ctx, series.MillisPerStep(), series.Len())
+		)
+		for j := 0; j < series.Len(); j++ {
+			outvals.SetValueAt(j, offset+(startSecs+float64(j)*stepSecs)*factor)
+		}
+
+		newName := fmt.Sprintf("linearRegression(%s, %d, %d)", series.Name(),
+			sourceCtx.StartTime.Unix(), sourceCtx.EndTime.Unix())
+		results = append(results, ts.NewSeries(ctx, newName, series.StartTime(), outvals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// minMax applies min-max normalization to each series, scaling its values to
+// between 0 and 1.
+func minMax(ctx *common.Context, input singlePathSpec) (ts.SeriesList, error) {
+	results := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		var (
+			min     = series.SafeMin()
+			max     = series.SafeMax()
+			outvals = ts.NewValues(ctx, series.MillisPerStep(), series.Len())
+		)
+		for i := 0; i < series.Len(); i++ {
+			v := series.ValueAt(i)
+			if math.IsNaN(v) {
+				continue
+			}
+
+			if max == min {
+				outvals.SetValueAt(i, 0)
+			} else {
+				outvals.SetValueAt(i, (v-min)/(max-min))
+			}
+		}
+
+		newName := fmt.Sprintf("minMax(%s)", series.Name())
+		results = append(results, ts.NewSeries(ctx, newName, series.StartTime(), outvals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// pow raises each element of a collection of time series to the power of a given value
+func pow(ctx *common.Context, input singlePathSpec, factor float64) (ts.SeriesList, error) {
+	return transform(
+		ctx,
+		input,
+		func(fname string) string {
+			newName := fmt.Sprintf("%s,%s", fname, formatPythonFloat(factor))
+			return fmt.Sprintf(wrappingFmt, "pow", newName)
+		},
+		func(v float64) float64 { return safePow(v, factor) },
+	)
+}
+
+// formatPythonFloat formats a float the way graphite-web names series with
+// float arguments, i.e. always with a decimal point.
+func formatPythonFloat(v float64) string {
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if strings.ContainsAny(s, ".eIN") {
+		return s
+	}
+
+	return s + ".0"
+}
+
+// sigmoid applies the sigmoid function 1 / (1 + exp(-x)) to each element of
+// a collection of time series.
+func sigmoid(ctx *common.Context, input singlePathSpec) (ts.SeriesList, error) {
+	return transform(ctx, input,
+		func(fname string) string { return fmt.Sprintf(wrappingFmt, "sigmoid", fname) },
+		common.MaintainNaNTransformer(func(v float64) float64 {
+			exp := math.Exp(-v)
+			if math.IsInf(exp, 0) {
+				return math.NaN()
+			}
+			return 1 / (1 + exp)
+		}))
+}
+
+// sortByMinima sorts timeseries by the lowest value across the time period
+// specified, dropping series whose maximum value is not positive.
+func sortByMinima(_ *common.Context, series singlePathSpec) (ts.SeriesList, error) {
+	positive := make([]*ts.Series, 0, len(series.Values))
+	for _, s := range series.Values {
+		if s.SafeMax() > 0 {
+			positive = append(positive, s)
+		}
+	}
+
+	filtered := singlePathSpec(series)
+	filtered.Values = positive
+	return takeByFunction(filtered, len(positive), ts.SeriesReducerMin.Reducer(), ts.Ascending)
+}
+
+// timeSlice takes one metric or a wildcard metric, followed by a quoted
+// string with the time to start the line and another quoted string with the
+// time to end the line, and nulls all values outside of that time range.
+func timeSlice(
+	ctx *common.Context,
+	input singlePathSpec,
+	startSliceAt, endSliceAt string,
+) (ts.SeriesList, error) {
+	now := time.Now()
+	start, err := graphite.ParseTime(startSliceAt, now, 0)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	end, err := graphite.ParseTime(endSliceAt, now, 0)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	results := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		outvals := ts.NewValues(ctx, series.MillisPerStep(), series.Len())
+		for i := 0; i < series.Len(); i++ {
+			t := series.StartTimeForStep(i)
+			if t.Before(start) || t.After(end) {
+				continue
+			}
+
+			outvals.SetValueAt(i, series.ValueAt(i))
+		}
+
+		newName := fmt.Sprintf("timeSlice(%s, %d, %d)", series.Name(), start.Unix(), end.Unix())
+		results = append(results, ts.NewSeries(ctx, newName, series.StartTime(), outvals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// timeStack takes one metric or a wildcard seriesList, followed by a quoted
+// string with the length of time, and draws the series shifted back by
+// multiples of that time, from timeShiftStart up to but excluding
+// timeShiftEnd, stacked on top of each other.
+func timeStack(
+	ctx *common.Context,
+	input singlePathSpec,
+	timeShiftUnit string,
+	timeShiftStart, timeShiftEnd int,
+) (ts.SeriesList, error) {
+	if timeShiftEnd < timeShiftStart {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf(
+			"invalid timeStack parameters timeShiftStart %d after timeShiftEnd %d",
+			timeShiftStart, timeShiftEnd))
+	}
+
+	if len(input.Values) == 0 {
+		return ts.SeriesList(input), nil
+	}
+
+	// NB: if no sign is given, a minus sign is implied which shifts back in time.
+	if !(strings.HasPrefix(timeShiftUnit, "+") || strings.HasPrefix(timeShiftUnit, "-")) {
+		timeShiftUnit = "-" + timeShiftUnit
+	}
+
+	delta, err := common.ParseInterval(timeShiftUnit)
+	if err != nil {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf(
+			"invalid timeStack parameter timeShiftUnit %s: %v", timeShiftUnit, err))
+	}
+
+	expr, err := compile(input.Values[0].Specification)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	results := make([]*ts.Series, 0, len(input.Values)*(timeShiftEnd-timeShiftStart))
+	for shift := timeShiftStart; shift < timeShiftEnd; shift++ {
+		innerDelta := delta * time.Duration(shift)
+		opts := common.NewChildContextOptions()
+		opts.AdjustTimeRange(innerDelta, innerDelta, 0, 0)
+
+		shifted, err := expr.Execute(ctx.NewChildContext(opts))
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		for _, series := range shifted.Values {
+			newName := fmt.Sprintf("timeShift(%s, %s, %d)", series.Name(), timeShiftUnit, shift)
+			results = append(results, series.Shift(-innerDelta).RenamedTo(newName))
+		}
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// useSeriesAbove compares the maximum of each series against the given value.
+// If the series maximum is greater than value, the regular expression search
+// and replace is applied against the series name to plot a related metric,
+// i.e. `useSeriesAbove(ganglia.metric1.reqs,10,"reqs","time")`.
+func useSeriesAbove(
+	ctx *common.Context,
+	input singlePathSpec,
+	value float64,
+	search, replace string,
+) (ts.SeriesList, error) {
+	above, err := aboveByFunction(ctx, input, ts.SeriesReducerMax.Reducer(), value)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	renamed, err := common.AliasSub(ctx, above, search, replace)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	results := make([]*ts.Series, 0, len(renamed.Values))
+	for _, series := range renamed.Values {
+		expr, err := compile(series.Name())
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		related, err := expr.Execute(ctx)
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		if len(related.Values) > 0 {
+			results = append(results, related.Values[0])
+		}
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// verticalLine draws a vertical line at the designated timestamp with an
+// optional label.
+func verticalLine(ctx *common.Context, timestamp, label, color string) (ts.SeriesList, error) {
+	t, err := graphite.ParseTime(timestamp, time.Now(), 0)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	if t.Before(ctx.StartTime) || t.After(ctx.EndTime) {
+		err := errors.NewInvalidParamsError(fmt.Errorf(
+			"verticalLine timestamp %s is outside of the range %v to %v",
+			timestamp, ctx.StartTime, ctx.EndTime))
+		return ts.SeriesList{}, err
+	}
+
+	vals := ts.NewValues(ctx, int(time.Second/time.Millisecond), 2)
+	vals.SetValueAt(0, 1)
+	vals.SetValueAt(1, 1)
+
+	name := label
+	if name == "" {
+		name = fmt.Sprintf("verticalLine(%s)", timestamp)
+	}
+
+	series := ts.NewSeries(ctx, name, t, vals)
+	return ts.SeriesList{Values: []*ts.Series{series}}, nil
+}
+
 func init() {
 	// functions - in alpha ordering
 	MustRegisterFunction(absolute)
+	MustRegisterFunction(aggregate).WithDefaultParams(map[uint8]interface{}{
+		3: 0.0, // xFilesFactor
+	})
 	MustRegisterFunction(aggregateLine).WithDefaultParams(map[uint8]interface{}{
 		2: "avg", // f
 	})
 	MustRegisterFunction(alias)
 	MustRegisterFunction(aliasByMetric)
 	MustRegisterFunction(aliasByNode)
+	MustRegisterFunction(aliasByTags)
 	MustRegisterFunction(aliasSub)
+	MustRegisterFunction(applyByNode).WithDefaultParams(map[uint8]interface{}{
+		4: "", // newName
+	})
 	MustRegisterFunction(asPercent).WithDefaultParams(map[uint8]interface{}{
 		2: []*ts.Series(nil), // total
 	})
@@ -1843,13 +2529,20 @@ func init() {
 	MustRegisterFunction(dashed).WithDefaultParams(map[uint8]interface{}{
 		2: 5.0, // dashLength
 	})
+	MustRegisterFunction(delay)
 	MustRegisterFunction(derivative)
 	MustRegisterFunction(diffSeries)
 	MustRegisterFunction(divideSeries)
+	MustRegisterFunction(divideSeriesLists)
 	MustRegisterFunction(exclude)
 	MustRegisterFunction(fallbackSeries)
 	MustRegisterFunction(group)
 	MustRegisterFunction(groupByNode)
+	MustRegisterFunction(groupByTags)
+	MustRegisterFunction(highest).WithDefaultParams(map[uint8]interface{}{
+		2: 1,         // n
+		3: "average", // fname
+	})
 	MustRegisterFunction(highestAverage)
 	MustRegisterFunction(highestCurrent)
 	MustRegisterFunction(highestMax)
@@ -1859,24 +2552,50 @@ func init() {
 	MustRegisterFunction(holtWintersForecast)
 	MustRegisterFunction(identity)
 	MustRegisterFunction(integral)
+	MustRegisterFunction(integralByInterval)
+	MustRegisterFunction(interpolate).WithDefaultParams(map[uint8]interface{}{
+		2: math.Inf(1), // limit
+	})
 	MustRegisterFunction(isNonNull)
 	MustRegisterFunction(keepLastValue).WithDefaultParams(map[uint8]interface{}{
 		2: -1, // limit
 	})
 	MustRegisterFunction(legendValue)
 	MustRegisterFunction(limit)
+	MustRegisterFunction(linearRegression).WithDefaultParams(map[uint8]interface{}{
+		2: "", // startSourceAt
+		3: "", // endSourceAt
+	})
 	MustRegisterFunction(logarithm).WithDefaultParams(map[uint8]interface{}{
 		2: 10, // base
 	})
+	MustRegisterFunction(lowest).WithDefaultParams(map[uint8]interface{}{
+		2: 1,         // n
+		3: "average", // fname
+	})
 	MustRegisterFunction(lowestAverage)
 	MustRegisterFunction(lowestCurrent)
 	MustRegisterFunction(maxSeries)
 	MustRegisterFunction(maximumAbove)
+	MustRegisterFunction(minMax)
 	MustRegisterFunction(minSeries)
 	MustRegisterFunction(minimumAbove)
 	MustRegisterFunction(mostDeviant)
 	MustRegisterFunction(movingAverage)
+	MustRegisterFunction(movingMax).WithDefaultParams(map[uint8]interface{}{
+		3: 0.0, // xFilesFactor
+	})
 	MustRegisterFunction(movingMedian)
+	MustRegisterFunction(movingMin).WithDefaultParams(map[uint8]interface{}{
+		3: 0.0, // xFilesFactor
+	})
+	MustRegisterFunction(movingSum).WithDefaultParams(map[uint8]interface{}{
+		3: 0.0, // xFilesFactor
+	})
+	MustRegisterFunction(movingWindow).WithDefaultParams(map[uint8]interface{}{
+		3: "average", // fname
+		4: 0.0,       // xFilesFactor
+	})
 	MustRegisterFunction(multiplySeries)
 	MustRegisterFunction(nonNegativeDerivative).WithDefaultParams(map[uint8]interface{}{
 		2: math.NaN(), // maxValue
@@ -1890,6 +2609,8 @@ func init() {
 	MustRegisterFunction(perSecond).WithDefaultParams(map[uint8]interface{}{
 		2: math.NaN(), // maxValue
 	})
+	MustRegisterFunction(pow)
+	MustRegisterFunction(powSeries)
 	MustRegisterFunction(rangeOfSeries)
 	MustRegisterFunction(randomWalkFunction).WithDefaultParams(map[uint8]interface{}{
 		2: 60, // step
@@ -1901,7 +2622,10 @@ func init() {
 	MustRegisterFunction(removeEmptySeries)
 	MustRegisterFunction(scale)
 	MustRegisterFunction(scaleToSeconds)
+	MustRegisterFunction(seriesByTag)
+	MustRegisterFunction(sigmoid)
 	MustRegisterFunction(sortByMaxima)
+	MustRegisterFunction(sortByMinima)
 	MustRegisterFunction(sortByName)
 	MustRegisterFunction(sortByTotal)
 	MustRegisterFunction(squareRoot)
@@ -1930,9 +2654,22 @@ func init() {
 	MustRegisterFunction(timeShift).WithDefaultParams(map[uint8]interface{}{
 		3: true, // resetEnd
 	})
+	MustRegisterFunction(timeSlice).WithDefaultParams(map[uint8]interface{}{
+		3: "now", // endSliceAt
+	})
+	MustRegisterFunction(timeStack).WithDefaultParams(map[uint8]interface{}{
+		2: "1d", // timeShiftUnit
+		3: 0,    // timeShiftStart
+		4: 7,    // timeShiftEnd
+	})
 	MustRegisterFunction(transformNull).WithDefaultParams(map[uint8]interface{}{
 		2: 0.0, // defaultValue
 	})
+	MustRegisterFunction(useSeriesAbove)
+	MustRegisterFunction(verticalLine).WithDefaultParams(map[uint8]interface{}{
+		2: "", // label
+		3: "", // color
+	})
 	MustRegisterFunction(weightedAverage)
 
 	// alias functions - in alpha ordering
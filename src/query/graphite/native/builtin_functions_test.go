@@ -1398,6 +1398,37 @@ func generateSeriesList(ctx *common.Context, start time.Time, inputs []common.Te
 	return tSeriesList
 }
 
+func TestSeriesByTag(t *testing.T) {
+	expr, err := compile("seriesByTag('name=cpu.*', 'dc=~us-.*')")
+	require.NoError(t, err)
+
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	ctx.Engine = mockEngine{fn: func(
+		ctx xctx.Context,
+		query string,
+		opts storage.FetchOptions,
+	) (*storage.FetchResult, error) {
+		if query != "seriesByTag('name=cpu.*','dc=~us-.*')" {
+			return nil, fmt.Errorf("unexpected query: %s", query)
+		}
+
+		return storage.NewFetchResult(ctx, []*ts.Series{
+			ts.NewSeries(ctx, "cpu.load;dc=us-east", opts.StartTime, ts.NewConstantValues(ctx, 1, 3, 1000)),
+		}), nil
+	}}
+
+	r, err := expr.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, r.Len())
+	assert.Equal(t, "cpu.load;dc=us-east", r.Values[0].Name())
+	assert.Equal(t, "seriesByTag('name=cpu.*','dc=~us-.*')", r.Values[0].Specification)
+
+	_, err = compile("seriesByTag()")
+	require.Error(t, err)
+}
+
 func TestScaleToSeconds(t *testing.T) {
 	ctx := common.NewTestContext()
 	defer ctx.Close()
@@ -2866,3 +2897,81 @@ func TestFunctionsRegistered(t *testing.T) {
 		assert.NotNil(t, findFunction(fname), "could not find function: %s", fname)
 	}
 }
+
+func TestDelayNegativeSteps(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{Name: "foo", Data: []float64{1, 2, 3}},
+	}, 10000)
+	outputs, err := delay(ctx, singlePathSpec(ts.SeriesList{Values: input}), -1)
+	require.NoError(t, err)
+	common.CompareOutputsAndExpected(t, 10000, ctx.StartTime, []common.TestSeries{
+		{Name: "delay(foo,-1)", Data: []float64{2, 3, math.NaN()}},
+	}, outputs.Values)
+}
+
+func TestTimeStackInvalidRange(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{Name: "foo", Data: []float64{1, 2, 3}},
+	}, 10000)
+	_, err := timeStack(ctx, singlePathSpec(ts.SeriesList{Values: input}), "1d", 2, 0)
+	require.Error(t, err)
+}
+
+func TestIntegralByIntervalInvalidInterval(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{Name: "foo", Data: []float64{1, 2, 3}},
+	}, 10000)
+	for _, interval := range []string{"0s", "bogus"} {
+		_, err := integralByInterval(ctx, singlePathSpec(ts.SeriesList{Values: input}), interval)
+		require.Error(t, err, interval)
+	}
+}
+
+func TestInterpolateLimit(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	nan := math.NaN()
+	start := ctx.StartTime
+	input := generateSeriesList(ctx, start, []common.TestSeries{
+		{Name: "foo", Data: []float64{nan, 1, nan, 3, nan, nan, nan, 7, nan}},
+	}, 10000)
+	output, err := interpolate(ctx, singlePathSpec(ts.SeriesList{Values: input}), 2)
+	require.NoError(t, err)
+	common.CompareOutputsAndExpected(t, 10000, start, []common.TestSeries{
+		{Name: "interpolate(foo)", Data: []float64{nan, 1, 2, 3, nan, nan, nan, 7, nan}},
+	}, output.Values)
+}
+
+func TestMovingWindowInvalidFunction(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{Name: "foo", Data: []float64{1, 2, 3}},
+	}, 10000)
+	_, err := movingWindow(ctx, singlePathSpec(ts.SeriesList{Values: input}), 2.0, "bogus", 0)
+	require.Error(t, err)
+}
+
+func TestVerticalLineOutOfRange(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	before := fmt.Sprintf("%d", ctx.StartTime.Add(-time.Hour).Unix())
+	_, err := verticalLine(ctx, before, "", "")
+	require.Error(t, err)
+
+	after := fmt.Sprintf("%d", ctx.EndTime.Add(time.Hour).Unix())
+	_, err = verticalLine(ctx, after, "", "")
+	require.Error(t, err)
+}
@@ -21,8 +21,6 @@
 package native
 
 import (
-	"time"
-
 	"github.com/m3db/m3/src/query/graphite/context"
 	"github.com/m3db/m3/src/query/graphite/storage"
 )
@@ -43,20 +41,9 @@ func NewEngine(store storage.Storage) *Engine {
 func (e *Engine) FetchByQuery(
 	ctx context.Context,
 	query string,
-	start, end time.Time,
-	timeout time.Duration,
+	opts storage.FetchOptions,
 ) (*storage.FetchResult, error) {
-	return e.storage.FetchByQuery(
-		ctx,
-		query,
-		storage.FetchOptions{
-			StartTime: start,
-			EndTime:   end,
-			DataOptions: storage.DataOptions{
-				Timeout: timeout,
-			},
-		},
-	)
+	return e.storage.FetchByQuery(ctx, query, opts)
 }
 
 // Compile compiles an expression from an expression string
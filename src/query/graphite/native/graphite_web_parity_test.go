@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/graphite/common"
+	xctx "github.com/m3db/m3/src/query/graphite/context"
+	"github.com/m3db/m3/src/query/graphite/storage"
+	"github.com/m3db/m3/src/query/graphite/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	parityFixturesPath = "testdata/graphite_web_parity.json"
+	parityTolerance    = 1e-9
+)
+
+// parityFixtures are graphite-web render results for a set of targets over
+// a shared set of stored series, used to check that functions match the
+// graphite-web semantics. The expected results are recorded from the
+// graphite-web version given by testdata/record_graphite_web_parity.py.
+type parityFixtures struct {
+	GraphiteWebVersion string                           `json:"graphiteWebVersion"`
+	From               int64                            `json:"from"`
+	Until              int64                            `json:"until"`
+	Series             map[string][]parityFixtureSeries `json:"series"`
+	Cases              []parityFixtureCase              `json:"cases"`
+}
+
+// parityFixtureSeries is a stored series returned for a fetch.
+type parityFixtureSeries struct {
+	Name   string     `json:"name"`
+	Start  int64      `json:"start"`
+	Step   int64      `json:"step"`
+	Values []*float64 `json:"values"`
+}
+
+// parityFixtureCase is a target along with the graphite-web render output for
+// it in the JSON format.
+type parityFixtureCase struct {
+	Target   string                 `json:"target"`
+	Expected []parityRenderedSeries `json:"expected"`
+}
+
+// parityRenderedSeries is a series in the graphite-web JSON render format.
+type parityRenderedSeries struct {
+	Target     string        `json:"target"`
+	Datapoints [][2]*float64 `json:"datapoints"`
+}
+
+// parityStorage serves the fixture series for the queries they are keyed by,
+// restricted to the fetched time range.
+type parityStorage struct {
+	series map[string][]parityFixtureSeries
+}
+
+func (s *parityStorage) FetchByQuery(
+	ctx xctx.Context, query string, opts storage.FetchOptions,
+) (*storage.FetchResult, error) {
+	var (
+		start  = opts.StartTime.Unix()
+		end    = opts.EndTime.Unix()
+		result []*ts.Series
+	)
+	for _, fixture := range s.series[query] {
+		first := (start - fixture.Start + fixture.Step - 1) / fixture.Step
+		if first < 0 {
+			first = 0
+		}
+
+		var values []*float64
+		for i := first; i < int64(len(fixture.Values)); i++ {
+			if fixture.Start+i*fixture.Step >= end {
+				break
+			}
+			values = append(values, fixture.Values[i])
+		}
+
+		vals := ts.NewValues(ctx, int(fixture.Step*1000), len(values))
+		for i, v := range values {
+			if v != nil {
+				vals.SetValueAt(i, *v)
+			}
+		}
+
+		seriesStart := time.Unix(fixture.Start+first*fixture.Step, 0)
+		result = append(result, ts.NewSeries(ctx, fixture.Name, seriesStart, vals))
+	}
+
+	return storage.NewFetchResult(ctx, result), nil
+}
+
+func TestGraphiteWebParity(t *testing.T) {
+	data, err := ioutil.ReadFile(parityFixturesPath)
+	require.NoError(t, err)
+
+	var fixtures parityFixtures
+	require.NoError(t, json.Unmarshal(data, &fixtures))
+	require.NotEmpty(t, fixtures.GraphiteWebVersion)
+
+	engine := NewEngine(&parityStorage{series: fixtures.Series})
+	for _, test := range fixtures.Cases {
+		ctx := common.NewContext(common.ContextOptions{
+			Start:  time.Unix(fixtures.From, 0),
+			End:    time.Unix(fixtures.Until, 0),
+			Engine: engine,
+		})
+
+		expr, err := engine.Compile(test.Target)
+		require.NoError(t, err, test.Target)
+
+		results, err := expr.Execute(ctx)
+		require.NoError(t, err, test.Target)
+		require.Equal(t, len(test.Expected), len(results.Values), test.Target)
+
+		for i, expected := range test.Expected {
+			actual := results.Values[i]
+			assert.Equal(t, expected.Target, actual.Name(), test.Target)
+
+			require.Equal(t, len(expected.Datapoints), actual.Len(), test.Target)
+			for j, dp := range expected.Datapoints {
+				timestamp := time.Unix(int64(*dp[1]), 0)
+				assert.True(t, timestamp.Equal(actual.StartTimeForStep(j)),
+					"%s: expected step %d of %s at %v, got %v",
+					test.Target, j, expected.Target, timestamp, actual.StartTimeForStep(j))
+
+				value := actual.ValueAt(j)
+				if dp[0] == nil {
+					assert.True(t, math.IsNaN(value),
+						"%s: expected null at step %d of %s, got %v",
+						test.Target, j, expected.Target, value)
+					continue
+				}
+
+				assert.InDelta(t, *dp[0], value, parityTolerance,
+					"%s: unexpected value at step %d of %s",
+					test.Target, j, expected.Target)
+			}
+		}
+
+		require.NoError(t, ctx.Close())
+	}
+}
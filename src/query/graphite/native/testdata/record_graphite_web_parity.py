@@ -0,0 +1,153 @@
+#!/usr/bin/env python3
+"""Records the expected results in graphite_web_parity.json from graphite-web.
+
+Renders every case target with graphite-web, pinned to GRAPHITE_WEB_VERSION,
+over the fixture series and rewrites the expected results of the cases. The
+fixture series are served to graphite-web by a storage finder which restricts
+them to the fetched range the same way the parity test storage does.
+
+Usage:
+    python3 -m venv /tmp/graphite-parity
+    GRAPHITE_NO_PREFIX=True /tmp/graphite-parity/bin/pip install \\
+        graphite-web==1.1.10 'Django<3.1'
+    /tmp/graphite-parity/bin/python record_graphite_web_parity.py
+"""
+
+import json
+import os
+import re
+import sys
+
+GRAPHITE_WEB_VERSION = "1.1.10"
+
+FIXTURES_PATH = os.path.join(
+    os.path.dirname(os.path.abspath(__file__)), "graphite_web_parity.json")
+
+LOCAL_SETTINGS = """
+SECRET_KEY = "graphite-web-parity"
+ALLOWED_HOSTS = ["*"]
+DATABASES = {
+    "default": {"ENGINE": "django.db.backends.sqlite3", "NAME": ":memory:"},
+}
+STORAGE_FINDERS = ("record_graphite_web_parity.FixtureFinder",)
+TIME_ZONE = "UTC"
+"""
+
+
+def load_fixtures():
+    with open(FIXTURES_PATH) as f:
+        return json.load(f)
+
+
+def fixture_series():
+    series = {}
+    for fixtures in load_fixtures()["series"].values():
+        for s in fixtures:
+            series[s["name"]] = s
+    return series
+
+
+def install_local_settings():
+    import graphite
+
+    path = os.path.join(os.path.dirname(graphite.__file__), "local_settings.py")
+    with open(path, "w") as f:
+        f.write(LOCAL_SETTINGS)
+
+
+def matches(pattern, name):
+    import fnmatch
+
+    parts, name_parts = pattern.split("."), name.split(".")
+    if len(parts) != len(name_parts):
+        return False
+    return all(fnmatch.fnmatchcase(n, p) for p, n in zip(parts, name_parts))
+
+
+# NB: the finder is only defined when imported by graphite-web, which happens
+# once the local settings referencing it are installed.
+if __name__ != "__main__":
+    from graphite.finders.utils import BaseFinder
+    from graphite.intervals import Interval, IntervalSet
+    from graphite.node import LeafNode
+    from graphite.readers.utils import BaseReader
+
+    class FixtureReader(BaseReader):
+        __slots__ = ("series",)
+
+        def __init__(self, series):
+            self.series = series
+
+        def get_intervals(self):
+            s = self.series
+            end = s["start"] + s["step"] * len(s["values"])
+            return IntervalSet([Interval(s["start"], end)])
+
+        def fetch(self, startTime, endTime, now=None, requestContext=None):
+            s = self.series
+            step = s["step"]
+            first = max(0, -((s["start"] - int(startTime)) // step))
+            values = []
+            for i in range(first, len(s["values"])):
+                if s["start"] + i * step >= int(endTime):
+                    break
+                values.append(s["values"][i])
+
+            start = s["start"] + first * step
+            return (start, start + len(values) * step, step), values
+
+    class FixtureFinder(BaseFinder):
+        def find_nodes(self, query):
+            for name, series in sorted(fixture_series().items()):
+                if matches(query.pattern, name):
+                    yield LeafNode(name, FixtureReader(series))
+
+
+def collapse_datapoints(text):
+    return re.sub(
+        r"\[\n\s+(\S+),\n\s+(\S+)\n\s+\]", r"[\1, \2]", text)
+
+
+def record():
+    import pkg_resources
+
+    version = pkg_resources.get_distribution("graphite-web").version
+    if version != GRAPHITE_WEB_VERSION:
+        sys.exit("expected graphite-web %s, found %s" % (
+            GRAPHITE_WEB_VERSION, version))
+
+    install_local_settings()
+    sys.path.insert(0, os.path.dirname(os.path.abspath(__file__)))
+    os.environ.setdefault("DJANGO_SETTINGS_MODULE", "graphite.settings")
+
+    import django
+
+    django.setup()
+
+    from django.test import Client
+
+    fixtures = load_fixtures()
+    client = Client()
+    for case in fixtures["cases"]:
+        resp = client.get("/render", {
+            "target": case["target"],
+            "from": fixtures["from"],
+            "until": fixtures["until"],
+            "format": "json",
+        })
+        if resp.status_code != 200:
+            sys.exit("rendering %s failed: %d %s" % (
+                case["target"], resp.status_code, resp.content))
+
+        case["expected"] = [
+            {"target": s["target"], "datapoints": s["datapoints"]}
+            for s in json.loads(resp.content)
+        ]
+
+    fixtures["graphiteWebVersion"] = GRAPHITE_WEB_VERSION
+    with open(FIXTURES_PATH, "w") as f:
+        f.write(collapse_datapoints(json.dumps(fixtures, indent=2)) + "\n")
+
+
+if __name__ == "__main__":
+    record()
@@ -0,0 +1,112 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/m3db/m3/src/query/storage"
+)
+
+type fetchCoalescingKey struct{}
+
+// WithFetchCoalescing returns a request context in which identical fetches
+// share a single result for the lifetime of the request, so that the same
+// path expression used by several targets of a render request is only
+// fetched once even if the fetches do not overlap. Such fetches also share
+// the fetches in flight for other requests with fetch coalescing enabled,
+// which are charged to the request that issued them.
+func WithFetchCoalescing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fetchCoalescingKey{}, newFetchCoalescer(true))
+}
+
+func fetchCoalescerFromContext(ctx context.Context) (*fetchCoalescer, bool) {
+	c, ok := ctx.Value(fetchCoalescingKey{}).(*fetchCoalescer)
+	return c, ok
+}
+
+type fetchFn func(ctx context.Context) (*storage.FetchResult, error)
+
+// fetchCoalescer shares the result of a fetch with every identical fetch
+// issued while it is in flight, and for the lifetime of the coalescer if
+// successful results are retained.
+type fetchCoalescer struct {
+	sync.Mutex
+
+	retain bool
+	calls  map[string]*fetchCall
+}
+
+type fetchCall struct {
+	done      chan struct{}
+	result    *storage.FetchResult
+	err       error
+	abandoned bool
+}
+
+func newFetchCoalescer(retain bool) *fetchCoalescer {
+	return &fetchCoalescer{
+		retain: retain,
+		calls:  make(map[string]*fetchCall),
+	}
+}
+
+// fetch returns the result of the fetch with the given key, calling fn only
+// if no identical fetch is in flight or retained.
+func (c *fetchCoalescer) fetch(
+	ctx context.Context,
+	key string,
+	fn fetchFn,
+) (*storage.FetchResult, error) {
+	c.Lock()
+	if call, ok := c.calls[key]; ok {
+		c.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if call.abandoned && ctx.Err() == nil {
+			// NB: the caller that issued the fetch timed out or went away, which
+			// says nothing about whether this caller's fetch would succeed.
+			return fn(ctx)
+		}
+
+		return call.result, call.err
+	}
+
+	call := &fetchCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.Unlock()
+
+	call.result, call.err = fn(ctx)
+	call.abandoned = ctx.Err() != nil
+	if !c.retain || call.err != nil {
+		c.Lock()
+		delete(c.calls, key)
+		c.Unlock()
+	}
+
+	close(call.done)
+	return call.result, call.err
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m3db/m3/src/query/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCoalescerSharesInflightFetches(t *testing.T) {
+	var (
+		c       = newFetchCoalescer(false)
+		calls   int32
+		started = make(chan struct{})
+		release = make(chan struct{})
+		result  = &storage.FetchResult{}
+	)
+
+	fn := func(context.Context) (*storage.FetchResult, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+		}
+		return result, nil
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r, err := c.fetch(context.Background(), "key", fn)
+		require.NoError(t, err)
+		assert.True(t, r == result)
+	}()
+
+	<-started
+
+	// NB: an identical fetch waits on the fetch in flight rather than calling
+	// fn, so with a cancelled context it returns without a result.
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.fetch(cancelled, "key", fn)
+	require.Equal(t, context.Canceled, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	close(release)
+	wg.Wait()
+
+	// Fetches which are no longer in flight are not retained.
+	_, err = c.fetch(context.Background(), "key", fn)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestFetchCoalescerRetainsFetches(t *testing.T) {
+	var (
+		c     = newFetchCoalescer(true)
+		calls int
+	)
+
+	fn := func(context.Context) (*storage.FetchResult, error) {
+		calls++
+		return &storage.FetchResult{}, nil
+	}
+
+	first, err := c.fetch(context.Background(), "key", fn)
+	require.NoError(t, err)
+	second, err := c.fetch(context.Background(), "key", fn)
+	require.NoError(t, err)
+	assert.True(t, first == second)
+	assert.Equal(t, 1, calls)
+
+	_, err = c.fetch(context.Background(), "other", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestFetchCoalescerRefetchesAbandonedFetches(t *testing.T) {
+	var (
+		c       = newFetchCoalescer(false)
+		started = make(chan struct{})
+		result  = &storage.FetchResult{}
+	)
+
+	abandonedCtx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := c.fetch(abandonedCtx, "key",
+			func(ctx context.Context) (*storage.FetchResult, error) {
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+		require.Error(t, err)
+	}()
+
+	<-started
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r, err := c.fetch(context.Background(), "key",
+			func(context.Context) (*storage.FetchResult, error) {
+				return result, nil
+			})
+		require.NoError(t, err)
+		assert.True(t, r == result)
+	}()
+
+	cancel()
+	wg.Wait()
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/graphite/ts"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/stats"
 	"github.com/m3db/m3/src/query/storage"
 	m3ts "github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/util/logging"
@@ -45,6 +46,7 @@ var (
 type m3WrappedStore struct {
 	m3       storage.Storage
 	enforcer cost.ChainedEnforcer
+	inflight *fetchCoalescer
 }
 
 // NewM3WrappedStorage creates a graphite storage wrapper around an m3query
@@ -57,14 +59,33 @@ func NewM3WrappedStorage(
 		enforcer = cost.NoopChainedEnforcer()
 	}
 
-	return &m3WrappedStore{m3: m3storage, enforcer: enforcer}
+	return &m3WrappedStore{
+		m3:       m3storage,
+		enforcer: enforcer,
+		inflight: newFetchCoalescer(false),
+	}
 }
 
 // TranslateQueryToMatchersWithTerminator converts a graphite query to tag
-// matcher pairs, and adds a terminator matcher to the end.
+// matcher pairs, and adds a terminator matcher to the end. Graphite tagged
+// series are excluded, as they are only matched by seriesByTag queries.
 func TranslateQueryToMatchersWithTerminator(
 	query string,
 ) (models.Matchers, error) {
+	pathMatchers, err := translatePathToMatchers(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// NB: the terminator is kept last as callers rely on its position.
+	matchers := make(models.Matchers, 0, len(pathMatchers)+1)
+	matchers = append(matchers, untaggedSeriesMatcher())
+	return append(matchers, pathMatchers...), nil
+}
+
+// translatePathToMatchers converts a graphite path to tag matcher pairs, and
+// adds a terminator matcher to the end.
+func translatePathToMatchers(query string) (models.Matchers, error) {
 	metricLength := graphite.CountMetricParts(query)
 	// Add space for a terminator character.
 	matchersLength := metricLength + 1
@@ -96,8 +117,21 @@ func GetQueryTerminatorTagName(query string) []byte {
 	return graphite.TagName(metricLength)
 }
 
+func translateQueryToMatchers(query string) (models.Matchers, error) {
+	if IsSeriesByTagQuery(query) {
+		tagExpressions, err := ParseSeriesByTagQuery(query)
+		if err != nil {
+			return nil, err
+		}
+
+		return TranslateTagExpressionsToMatchers(tagExpressions)
+	}
+
+	return TranslateQueryToMatchersWithTerminator(query)
+}
+
 func translateQuery(query string, opts FetchOptions) (*storage.FetchQuery, error) {
-	matchers, err := TranslateQueryToMatchersWithTerminator(query)
+	matchers, err := translateQueryToMatchers(query)
 	if err != nil {
 		return nil, err
 	}
@@ -159,20 +193,31 @@ func (s *m3WrappedStore) FetchByQuery(
 		}, nil
 	}
 
-	m3ctx, cancel := context.WithTimeout(ctx.RequestContext(), opts.Timeout)
-	defer cancel()
-	fetchOptions := storage.NewFetchOptions()
-	perQueryEnforcer := s.enforcer.Child(cost.QueryLevel)
-	defer perQueryEnforcer.Close()
+	// NB: identical fetches issued by requests with fetch coalescing enabled
+	// share a single fetch from M3, both within a request for its lifetime
+	// and across concurrent requests while the fetch is in flight. A fetch
+	// shared across requests runs under the context, enforcer and timeout of
+	// the request that issued it, so its cost is charged to that caller only.
+	maxResolution := fetchMaxResolution(opts)
+	key := fmt.Sprintf("%s:%d:%d:%d:%d", query, opts.StartTime.UnixNano(),
+		opts.EndTime.UnixNano(), maxResolution, opts.Timeout)
+	fetch := func(reqCtx context.Context) (*storage.FetchResult, error) {
+		return s.fetch(reqCtx, m3query, maxResolution, opts.Timeout)
+	}
 
-	fetchOptions.Enforcer = perQueryEnforcer
-	fetchOptions.FanoutOptions = &storage.FanoutOptions{
-		FanoutUnaggregated:        storage.FanoutForceDisable,
-		FanoutAggregated:          storage.FanoutDefault,
-		FanoutAggregatedOptimized: storage.FanoutForceDisable,
+	var (
+		reqCtx   = ctx.RequestContext()
+		m3result *storage.FetchResult
+	)
+	if requestFetches, ok := fetchCoalescerFromContext(reqCtx); ok {
+		m3result, err = requestFetches.fetch(reqCtx, key,
+			func(reqCtx context.Context) (*storage.FetchResult, error) {
+				return s.inflight.fetch(reqCtx, key, fetch)
+			})
+	} else {
+		m3result, err = fetch(reqCtx)
 	}
 
-	m3result, err := s.m3.Fetch(m3ctx, m3query, fetchOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -185,3 +230,39 @@ func (s *m3WrappedStore) FetchByQuery(
 
 	return NewFetchResult(ctx, series), nil
 }
+
+func (s *m3WrappedStore) fetch(
+	reqCtx context.Context,
+	query *storage.FetchQuery,
+	maxResolution time.Duration,
+	timeout time.Duration,
+) (*storage.FetchResult, error) {
+	m3ctx, cancel := context.WithTimeout(reqCtx, timeout)
+	defer cancel()
+	fetchOptions := storage.NewFetchOptions()
+	perQueryEnforcer := cost.WithCallerEnforcer(m3ctx,
+		s.enforcer.Child(cost.QueryLevel))
+	defer perQueryEnforcer.Close()
+
+	fetchOptions.Enforcer = stats.WrapEnforcer(m3ctx, perQueryEnforcer)
+	fetchOptions.FanoutOptions = &storage.FanoutOptions{
+		FanoutUnaggregated:        storage.FanoutForceDisable,
+		FanoutAggregated:          storage.FanoutDefault,
+		FanoutAggregatedOptimized: storage.FanoutForceDisable,
+	}
+	fetchOptions.MaxResolution = maxResolution
+
+	return s.m3.Fetch(m3ctx, query, fetchOptions)
+}
+
+// fetchMaxResolution returns the coarsest resolution at which the fetch range
+// still has at least the max datapoints requested, so that series can be read
+// from coarser namespaces without lowering the resolution they are rendered
+// at.
+func fetchMaxResolution(opts FetchOptions) time.Duration {
+	if opts.MaxDataPoints <= 0 {
+		return 0
+	}
+
+	return opts.EndTime.Sub(opts.StartTime) / time.Duration(opts.MaxDataPoints)
+}
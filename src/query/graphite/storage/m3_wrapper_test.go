@@ -23,6 +23,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -60,6 +61,7 @@ func TestTranslateQuery(t *testing.T) {
 	assert.Equal(t, query, translated.Raw)
 	matchers := translated.TagMatchers
 	expected := models.Matchers{
+		{Type: models.MatchNotRegexp, Name: []byte("__gpath__"), Value: []byte(".+")},
 		{Type: models.MatchEqual, Name: graphite.TagName(0), Value: []byte("foo")},
 		{Type: models.MatchRegexp, Name: graphite.TagName(1), Value: []byte("ba[rz]")},
 		{Type: models.MatchRegexp, Name: graphite.TagName(2), Value: []byte(`q[^\.]*x`)},
@@ -196,6 +198,154 @@ func TestFetchByQuery(t *testing.T) {
 	assert.Equal(t, childEnforcer, store.LastFetchOptions().Enforcer)
 }
 
+func TestFetchByQueryMaxResolution(t *testing.T) {
+	store := mock.NewMockStorage()
+	store.SetFetchResult(&storage.FetchResult{}, nil)
+
+	wrapper := NewM3WrappedStorage(store, nil)
+	ctx := xctx.New()
+	ctx.SetRequestContext(context.TODO())
+	end := time.Now()
+	opts := FetchOptions{
+		StartTime: end.Add(-time.Hour),
+		EndTime:   end,
+		DataOptions: DataOptions{
+			Timeout:       time.Minute,
+			MaxDataPoints: 360,
+		},
+	}
+
+	_, err := wrapper.FetchByQuery(ctx, "a.b", opts)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, store.LastFetchOptions().MaxResolution)
+
+	opts.MaxDataPoints = 0
+	_, err = wrapper.FetchByQuery(ctx, "a.b", opts)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), store.LastFetchOptions().MaxResolution)
+}
+
+func TestFetchByQueryCoalescesRequestFetches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Now().Add(-time.Hour)
+	resolution := 10 * time.Second
+	vals := m3ts.NewFixedStepValues(resolution, 3, 3, start)
+	series := m3ts.NewSeries([]byte("a"), vals, models.NewTags(0, nil))
+	series.SetResolution(resolution)
+
+	// NB: only a single fetch is expected for both identical fetches.
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&storage.FetchResult{SeriesList: m3ts.SeriesList{series}}, nil)
+
+	wrapper := NewM3WrappedStorage(store, nil)
+	ctx := xctx.New()
+	ctx.SetRequestContext(WithFetchCoalescing(context.TODO()))
+	opts := FetchOptions{
+		StartTime: start,
+		EndTime:   start.Add(30 * time.Second),
+		DataOptions: DataOptions{
+			Timeout: time.Minute,
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := wrapper.FetchByQuery(ctx, "a.*", opts)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(result.SeriesList))
+		assert.Equal(t, "a", result.SeriesList[0].Name())
+		assert.Equal(t, []float64{3, 3, 3}, result.SeriesList[0].SafeValues())
+	}
+}
+
+func TestFetchByQueryCoalescesInflightFetchesAcrossRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	// NB: only the first request fetches from M3.
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			context.Context,
+			*storage.FetchQuery,
+			*storage.FetchOptions,
+		) (*storage.FetchResult, error) {
+			close(started)
+			<-release
+			return &storage.FetchResult{}, nil
+		})
+
+	wrapper := NewM3WrappedStorage(store, nil)
+	start := time.Now().Add(-time.Hour)
+	opts := FetchOptions{
+		StartTime: start,
+		EndTime:   start.Add(30 * time.Second),
+		DataOptions: DataOptions{
+			Timeout: time.Minute,
+		},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ctx := xctx.New()
+		ctx.SetRequestContext(WithFetchCoalescing(context.TODO()))
+		_, err := wrapper.FetchByQuery(ctx, "a.*", opts)
+		require.NoError(t, err)
+	}()
+
+	<-started
+
+	// NB: another request waits on the fetch in flight rather than fetching
+	// itself, so with a cancelled context it returns without a result.
+	reqCtx, cancel := context.WithCancel(WithFetchCoalescing(context.TODO()))
+	cancel()
+	ctx := xctx.New()
+	ctx.SetRequestContext(reqCtx)
+	_, err := wrapper.FetchByQuery(ctx, "a.*", opts)
+	require.Equal(t, context.Canceled, err)
+
+	close(release)
+	wg.Wait()
+}
+
+func TestFetchByQueryDoesNotRetainFetchesAcrossRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// NB: fetches of different requests are only shared while in flight, so
+	// fetches issued one after the other are not shared.
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&storage.FetchResult{}, nil).
+		Times(2)
+
+	wrapper := NewM3WrappedStorage(store, nil)
+	start := time.Now().Add(-time.Hour)
+	opts := FetchOptions{
+		StartTime: start,
+		EndTime:   start.Add(30 * time.Second),
+		DataOptions: DataOptions{
+			Timeout: time.Minute,
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		ctx := xctx.New()
+		ctx.SetRequestContext(WithFetchCoalescing(context.TODO()))
+		_, err := wrapper.FetchByQuery(ctx, "a.*", opts)
+		require.NoError(t, err)
+	}
+}
+
 func TestFetchByInvalidQuery(t *testing.T) {
 	logging.InitWithCores(nil)
 	store := mock.NewMockStorage()
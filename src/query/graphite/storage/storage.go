@@ -37,7 +37,8 @@ type FetchOptions struct {
 
 // DataOptions provide data context
 type DataOptions struct {
-	Timeout time.Duration // Whether to use a custom timeout, zero if no or positive if yes
+	Timeout       time.Duration // Whether to use a custom timeout, zero if no or positive if yes
+	MaxDataPoints int64         // The max datapoints results are consolidated to, zero if unbounded
 }
 
 // Storage provides an interface for retrieving timeseries values or names based upon
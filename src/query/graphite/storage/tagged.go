@@ -0,0 +1,241 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/m3db/m3/src/query/graphite/graphite"
+	"github.com/m3db/m3/src/query/models"
+)
+
+const (
+	seriesByTagPrefix = "seriesByTag("
+	seriesByTagSuffix = ")"
+)
+
+var (
+	errNoTagExpressions       = errors.New("no tag expressions given")
+	errNoPositiveTagMatch     = errors.New("at least one tag expression must match a non-empty value")
+	errInvalidSeriesByTagArgs = errors.New("invalid seriesByTag arguments")
+
+	anyValue   = []byte(".+")
+	taggedPath = []byte(graphite.TaggedPathTag)
+)
+
+// IsSeriesByTagQuery returns true if the query fetches series by tag
+// expressions rather than by path.
+func IsSeriesByTagQuery(query string) bool {
+	return strings.HasPrefix(query, seriesByTagPrefix) &&
+		strings.HasSuffix(query, seriesByTagSuffix)
+}
+
+// SeriesByTagQuery formats the tag expressions as a seriesByTag query.
+func SeriesByTagQuery(tagExpressions []string) string {
+	quoted := make([]string, 0, len(tagExpressions))
+	for _, expr := range tagExpressions {
+		quoted = append(quoted, "'"+expr+"'")
+	}
+
+	return seriesByTagPrefix + strings.Join(quoted, ",") + seriesByTagSuffix
+}
+
+// ParseSeriesByTagQuery returns the tag expressions of a seriesByTag query.
+func ParseSeriesByTagQuery(query string) ([]string, error) {
+	if !IsSeriesByTagQuery(query) {
+		return nil, fmt.Errorf("not a seriesByTag query: %s", query)
+	}
+
+	args := query[len(seriesByTagPrefix) : len(query)-len(seriesByTagSuffix)]
+	var exprs []string
+	for {
+		args = strings.TrimSpace(args)
+		if len(args) == 0 {
+			return nil, errInvalidSeriesByTagArgs
+		}
+
+		quote := args[0]
+		if quote != '\'' && quote != '"' {
+			return nil, errInvalidSeriesByTagArgs
+		}
+
+		end := strings.IndexByte(args[1:], quote)
+		if end < 0 {
+			return nil, errInvalidSeriesByTagArgs
+		}
+
+		exprs = append(exprs, args[1:end+1])
+		args = strings.TrimSpace(args[end+2:])
+		if len(args) == 0 {
+			return exprs, nil
+		}
+
+		if args[0] != ',' {
+			return nil, errInvalidSeriesByTagArgs
+		}
+
+		args = args[1:]
+	}
+}
+
+// TaggedSeriesMatcher returns a matcher for graphite tagged series only.
+func TaggedSeriesMatcher() models.Matcher {
+	return models.Matcher{
+		Type:  models.MatchRegexp,
+		Name:  taggedPath,
+		Value: anyValue,
+	}
+}
+
+// untaggedSeriesMatcher returns a matcher for series only identified by their
+// path nodes, excluding graphite tagged series.
+func untaggedSeriesMatcher() models.Matcher {
+	return models.Matcher{
+		Type:  models.MatchNotRegexp,
+		Name:  taggedPath,
+		Value: anyValue,
+	}
+}
+
+// TranslateTagExpressionsToMatchers converts graphite tag expressions, such
+// as `name=foo.*`, `dc=us-east`, `host!=a` or `env=~prod.*`, to tag matchers
+// for graphite tagged series. Expressions on the name tag match the whole
+// series path, so `name=foo.bar` only matches the series with that exact path.
+func TranslateTagExpressionsToMatchers(
+	tagExpressions []string,
+) (models.Matchers, error) {
+	if len(tagExpressions) == 0 {
+		return nil, errNoTagExpressions
+	}
+
+	var (
+		matchers = models.Matchers{TaggedSeriesMatcher()}
+		positive bool
+	)
+
+	for _, expr := range tagExpressions {
+		name, op, value, err := parseTagExpression(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		if name == graphite.NameTag {
+			// NB: the path of tagged series is also stored whole so that it can
+			// be matched exactly, by regexp or negated.
+			name = graphite.TaggedPathTag
+		}
+
+		m, matchesValue, err := tagExpressionToMatcher(name, op, value)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, m)
+		positive = positive || matchesValue
+	}
+
+	if !positive {
+		return nil, errNoPositiveTagMatch
+	}
+
+	return matchers, nil
+}
+
+// parseTagExpression splits a tag expression into its name, operator and
+// value.
+func parseTagExpression(expr string) (string, string, string, error) {
+	idx := strings.IndexByte(expr, '=')
+	if idx < 0 {
+		return "", "", "", fmt.Errorf("invalid tag expression: %s", expr)
+	}
+
+	var (
+		name  = expr[:idx]
+		value = expr[idx+1:]
+		op    = "="
+	)
+
+	if strings.HasSuffix(name, "!") {
+		name = name[:len(name)-1]
+		op = "!="
+	}
+
+	if strings.HasPrefix(value, "~") {
+		value = value[1:]
+		op += "~"
+	}
+
+	if len(name) == 0 {
+		return "", "", "", fmt.Errorf("invalid tag expression: %s", expr)
+	}
+
+	return name, op, value, nil
+}
+
+// tagExpressionToMatcher returns the matcher for the tag expression, and
+// whether the matcher requires the tag to be present with a non-empty value.
+func tagExpressionToMatcher(
+	name, op, value string,
+) (models.Matcher, bool, error) {
+	var (
+		matchType    models.MatchType
+		matchValue   = []byte(value)
+		matchesValue bool
+	)
+
+	switch op {
+	case "=":
+		if len(value) == 0 {
+			// NB: an empty value matches series without the tag.
+			matchType, matchValue = models.MatchNotRegexp, anyValue
+		} else {
+			matchType, matchesValue = models.MatchEqual, true
+		}
+	case "!=":
+		if len(value) == 0 {
+			matchType, matchValue, matchesValue = models.MatchRegexp, anyValue, true
+		} else {
+			matchType = models.MatchNotEqual
+		}
+	case "=~":
+		// NB: graphite regexp tag expressions are only anchored at the start.
+		matchType, matchesValue = models.MatchRegexp, true
+		matchValue = []byte("(?:" + value + ").*")
+	case "!=~":
+		matchType = models.MatchNotRegexp
+		matchValue = []byte("(?:" + value + ").*")
+	}
+
+	if matchType == models.MatchRegexp || matchType == models.MatchNotRegexp {
+		if _, err := regexp.Compile(string(matchValue)); err != nil {
+			return models.Matcher{}, false, err
+		}
+	}
+
+	return models.Matcher{
+		Type:  matchType,
+		Name:  []byte(name),
+		Value: matchValue,
+	}, matchesValue, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesByTagQuery(t *testing.T) {
+	query := SeriesByTagQuery([]string{"name=foo.*", "dc=~us-.*"})
+	assert.Equal(t, "seriesByTag('name=foo.*','dc=~us-.*')", query)
+	assert.True(t, IsSeriesByTagQuery(query))
+	assert.False(t, IsSeriesByTagQuery("foo.bar.*"))
+
+	exprs, err := ParseSeriesByTagQuery(query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name=foo.*", "dc=~us-.*"}, exprs)
+
+	exprs, err = ParseSeriesByTagQuery(`seriesByTag( "a=b" , 'c!=d' )`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a=b", "c!=d"}, exprs)
+}
+
+func TestParseSeriesByTagQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"foo.bar",
+		"seriesByTag()",
+		"seriesByTag(a=b)",
+		"seriesByTag('a=b)",
+		"seriesByTag('a=b' 'c=d')",
+		"seriesByTag('a=b',)",
+	} {
+		_, err := ParseSeriesByTagQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestTranslateTagExpressionsToMatchers(t *testing.T) {
+	matchers, err := TranslateTagExpressionsToMatchers([]string{
+		"name=foo.b*",
+		"dc=us-east",
+		"host!=a",
+		"env=~prod|stage",
+		"role!=~db",
+		"rack=",
+		"zone!=",
+	})
+	require.NoError(t, err)
+
+	expected := models.Matchers{
+		{Type: models.MatchRegexp, Name: []byte("__gpath__"), Value: []byte(".+")},
+		{Type: models.MatchEqual, Name: []byte("__gpath__"), Value: []byte("foo.b*")},
+		{Type: models.MatchEqual, Name: []byte("dc"), Value: []byte("us-east")},
+		{Type: models.MatchNotEqual, Name: []byte("host"), Value: []byte("a")},
+		{Type: models.MatchRegexp, Name: []byte("env"), Value: []byte("(?:prod|stage).*")},
+		{Type: models.MatchNotRegexp, Name: []byte("role"), Value: []byte("(?:db).*")},
+		{Type: models.MatchNotRegexp, Name: []byte("rack"), Value: []byte(".+")},
+		{Type: models.MatchRegexp, Name: []byte("zone"), Value: []byte(".+")},
+	}
+
+	assert.Equal(t, expected, matchers)
+}
+
+func TestTranslateNameTagExpressionsToMatchers(t *testing.T) {
+	matchers, err := TranslateTagExpressionsToMatchers([]string{
+		"name=~foo\\.ba[rz]",
+		"name!=foo.qux",
+		"name!=~foo\\.bug",
+	})
+	require.NoError(t, err)
+
+	expected := models.Matchers{
+		{Type: models.MatchRegexp, Name: []byte("__gpath__"), Value: []byte(".+")},
+		{Type: models.MatchRegexp, Name: []byte("__gpath__"), Value: []byte(`(?:foo\.ba[rz]).*`)},
+		{Type: models.MatchNotEqual, Name: []byte("__gpath__"), Value: []byte("foo.qux")},
+		{Type: models.MatchNotRegexp, Name: []byte("__gpath__"), Value: []byte(`(?:foo\.bug).*`)},
+	}
+
+	assert.Equal(t, expected, matchers)
+}
+
+func TestTranslateTagExpressionsToMatchersErrors(t *testing.T) {
+	for _, exprs := range [][]string{
+		nil,
+		{"dc"},
+		{"=us-east"},
+		{"name!=foo"},
+		{"dc=~(us"},
+		{"name=~(foo"},
+		{"dc!=us-east", "host="},
+	} {
+		_, err := TranslateTagExpressionsToMatchers(exprs)
+		assert.Error(t, err, exprs)
+	}
+}
+
+func TestTranslateSeriesByTagQuery(t *testing.T) {
+	translated, err := translateQuery("seriesByTag('dc=us-east')", FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, models.Matchers{
+		{Type: models.MatchRegexp, Name: []byte("__gpath__"), Value: []byte(".+")},
+		{Type: models.MatchEqual, Name: []byte("dc"), Value: []byte("us-east")},
+	}, translated.TagMatchers)
+}
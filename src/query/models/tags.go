@@ -251,16 +251,49 @@ func (t Tags) prependMetaLen() (int, []int) {
 
 func (t Tags) graphiteID() []byte {
 	// TODO: pool these bytes.
-	id := make([]byte, t.idLenGraphite())
-	idx := 0
-	lastIndex := len(t.Tags) - 1
-	for _, tag := range t.Tags[:lastIndex] {
-		idx += copy(id[idx:], tag.Value)
-		id[idx] = graphiteSep
-		idx++
+	id := make([]byte, 0, t.idLenGraphite())
+	numTagged := 0
+	for _, tag := range t.Tags {
+		if !isGraphitePathTag(tag.Name) {
+			if !isGraphiteReservedTag(tag.Name) {
+				numTagged++
+			}
+
+			continue
+		}
+
+		if len(id) > 0 {
+			id = append(id, graphiteSep)
+		}
+
+		id = append(id, tag.Value...)
+	}
+
+	if numTagged == 0 {
+		return id
+	}
+
+	// Any tags besides the path and reserved tags are appended as in graphite
+	// tagged series names, i.e. `path;tag1=value1;tag2=value2` with tags sorted
+	// by name.
+	tagged := make([]Tag, 0, numTagged)
+	for _, tag := range t.Tags {
+		if !isGraphitePathTag(tag.Name) && !isGraphiteReservedTag(tag.Name) {
+			tagged = append(tagged, tag)
+		}
+	}
+
+	sort.Slice(tagged, func(i, j int) bool {
+		return bytes.Compare(tagged[i].Name, tagged[j].Name) < 0
+	})
+
+	for _, tag := range tagged {
+		id = append(id, graphiteTag)
+		id = append(id, tag.Name...)
+		id = append(id, eq)
+		id = append(id, tag.Value...)
 	}
 
-	copy(id[idx:], t.Tags[lastIndex].Value)
 	return id
 }
 
@@ -268,11 +301,39 @@ func (t Tags) idLenGraphite() int {
 	idLen := t.Len() - 1 // account for separators
 	for _, tag := range t.Tags {
 		idLen += len(tag.Value)
+		if !isGraphitePathTag(tag.Name) {
+			// account for the name and equals sign
+			idLen += len(tag.Name) + 1
+		}
 	}
 
 	return idLen
 }
 
+// isGraphitePathTag returns true if the tag name is one of the `__gN__` names
+// holding the nodes of a graphite path.
+func isGraphitePathTag(name []byte) bool {
+	if len(name) < 6 || !bytes.HasPrefix(name, graphitePathTagPrefix) ||
+		!bytes.HasSuffix(name, graphitePathTagSuffix) {
+		return false
+	}
+
+	for _, c := range name[3 : len(name)-2] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isGraphiteReservedTag returns true if the tag name is reserved, such as the
+// tag holding the path of a graphite tagged series, and so is not part of
+// graphite series names.
+func isGraphiteReservedTag(name []byte) bool {
+	return bytes.HasPrefix(name, graphiteReservedTagPrefix)
+}
+
 func (t Tags) tagSubset(keys [][]byte, include bool) Tags {
 	tags := NewTags(t.Len(), t.Opts)
 	for _, tag := range t.Tags {
@@ -509,3 +509,17 @@ func TestSerializedLength(t *testing.T) {
 	assert.False(t, escaping.escapeName)
 	assert.True(t, escaping.escapeValue)
 }
+
+func TestTaggedIDGraphite(t *testing.T) {
+	opts := NewTagOptions().SetIDSchemeType(TypeGraphite)
+	tags := NewTags(5, opts).AddTags([]Tag{
+		{Name: []byte("host"), Value: []byte("a")},
+		{Name: []byte("__g1__"), Value: []byte("cpu")},
+		{Name: []byte("dc"), Value: []byte("us-east")},
+		{Name: []byte("__g0__"), Value: []byte("system")},
+		{Name: []byte("__gpath__"), Value: []byte("system.cpu")},
+	})
+
+	actual := tags.ID()
+	assert.Equal(t, []byte("system.cpu;dc=us-east;host=a"), actual)
+}
@@ -27,6 +27,7 @@ import (
 // Separators for tags.
 const (
 	graphiteSep  = byte('.')
+	graphiteTag  = byte(';')
 	sep          = byte(',')
 	finish       = byte('!')
 	eq           = byte('=')
@@ -34,6 +35,13 @@ const (
 	rightBracket = byte('}')
 )
 
+var (
+	graphitePathTagPrefix = []byte("__g")
+	graphitePathTagSuffix = []byte("__")
+
+	graphiteReservedTagPrefix = []byte("__")
+)
+
 // IDSchemeType determines the scheme for generating
 // series IDs based on their tags.
 type IDSchemeType uint16
@@ -0,0 +1,222 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/binary"
+	"github.com/m3db/m3/src/query/functions/linear"
+	"github.com/m3db/m3/src/query/functions/scalar"
+	"github.com/m3db/m3/src/query/functions/tag"
+	"github.com/m3db/m3/src/query/functions/temporal"
+	"github.com/m3db/m3/src/query/functions/unconsolidated"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailedParseFunctions(t *testing.T) {
+	for _, q := range []string{
+		"sgn(up, 1)",
+		"clamp(up, 1)",
+		"last_over_time()",
+		"last_over_time(up)",
+		"pi(1)",
+		"sgn(up",
+		"unknown_function(up)",
+		"group by (job) (up",
+	} {
+		t.Run(q, func(t *testing.T) {
+			_, err := Parse(q, models.NewTagOptions())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAtModifierNotSupported(t *testing.T) {
+	for _, q := range []string{
+		"up @ 100",
+		"rate(up[5m] @ end())",
+		"max_over_time(up[1h:1m] @ 100)",
+	} {
+		t.Run(q, func(t *testing.T) {
+			p, err := Parse(q, models.NewTagOptions())
+			require.NoError(t, err)
+			_, _, err = p.DAG()
+			assert.Equal(t, errAtModifierNotSupported, err)
+		})
+	}
+}
+
+// functionCompatibilityTests covers the functions and aggregations of
+// Prometheus 2.35, with the type of the final transform each compiles to.
+var functionCompatibilityTests = []struct {
+	q            string
+	expectedType string
+}{
+	{"abs(up)", linear.AbsType},
+	{"absent(up)", linear.AbsentType},
+	{"absent_over_time(up[5m])", temporal.AbsentType},
+	{"acos(up)", linear.AcosType},
+	{"acosh(up)", linear.AcoshType},
+	{"asin(up)", linear.AsinType},
+	{"asinh(up)", linear.AsinhType},
+	{"atan(up)", linear.AtanType},
+	{"atanh(up)", linear.AtanhType},
+	{"avg_over_time(up[5m])", temporal.AvgType},
+	{"ceil(up)", linear.CeilType},
+	{"changes(up[5m])", temporal.ChangesType},
+	{"clamp(up, 0, 1)", linear.ClampType},
+	{"clamp_max(up, 1)", linear.ClampMaxType},
+	{"clamp_min(up, 1)", linear.ClampMinType},
+	{"cos(up)", linear.CosType},
+	{"cosh(up)", linear.CoshType},
+	{"count_over_time(up[5m])", temporal.CountType},
+	{"day_of_month(up)", linear.DayOfMonthType},
+	{"day_of_week(up)", linear.DayOfWeekType},
+	{"days_in_month(up)", linear.DaysInMonthType},
+	{"deg(up)", linear.DegType},
+	{"delta(up[5m])", temporal.DeltaType},
+	{"deriv(up[5m])", temporal.DerivType},
+	{"exp(up)", linear.ExpType},
+	{"floor(up)", linear.FloorType},
+	{"histogram_quantile(0.9, up)", linear.HistogramQuantileType},
+	{"holt_winters(up[5m], 0.5, 0.5)", temporal.HoltWintersType},
+	{"hour(up)", linear.HourType},
+	{"idelta(up[5m])", temporal.IDeltaType},
+	{"increase(up[5m])", temporal.IncreaseType},
+	{"irate(up[5m])", temporal.IRateType},
+	{`label_join(up, "a", ",", "b", "c")`, tag.TagJoinType},
+	{`label_replace(up, "a", "$1", "b", "(.*)")`, tag.TagReplaceType},
+	{"last_over_time(up[5m])", temporal.LastType},
+	{"ln(up)", linear.LnType},
+	{"log10(up)", linear.Log10Type},
+	{"log2(up)", linear.Log2Type},
+	{"max_over_time(up[5m])", temporal.MaxType},
+	{"min_over_time(up[5m])", temporal.MinType},
+	{"minute(up)", linear.MinuteType},
+	{"month(up)", linear.MonthType},
+	{"up * pi()", binary.MultiplyType},
+	{"predict_linear(up[5m], 60)", temporal.PredictLinearType},
+	{"present_over_time(up[5m])", temporal.PresentType},
+	{"quantile_over_time(0.9, up[5m])", temporal.QuantileType},
+	{"rad(up)", linear.RadType},
+	{"rate(up[5m])", temporal.RateType},
+	{"resets(up[5m])", temporal.ResetsType},
+	{"round(up, 10)", linear.RoundType},
+	{"scalar(up)", functions.FetchType},
+	{"sgn(up)", linear.SgnType},
+	{"sin(up)", linear.SinType},
+	{"sinh(up)", linear.SinhType},
+	{"sort(up)", functions.FetchType},
+	{"sort_desc(up)", functions.FetchType},
+	{"sqrt(up)", linear.SqrtType},
+	{"stddev_over_time(up[5m])", temporal.StdDevType},
+	{"stdvar_over_time(up[5m])", temporal.StdVarType},
+	{"sum_over_time(up[5m])", temporal.SumType},
+	{"tan(up)", linear.TanType},
+	{"tanh(up)", linear.TanhType},
+	{"time()", scalar.TimeType},
+	{"timestamp(up)", unconsolidated.TimestampType},
+	{"vector(1)", scalar.VectorType},
+	{"vector(time())", scalar.TimeType},
+	{"year(up)", linear.YearType},
+
+	{"avg(up)", aggregation.AverageType},
+	{"bottomk(2, up)", aggregation.BottomKType},
+	{`count_values("value", up)`, aggregation.CountValuesType},
+	{"count(up)", aggregation.CountType},
+	{"group(up)", aggregation.GroupType},
+	{"group by (job) (up)", aggregation.GroupType},
+	{"group(up) by (job)", aggregation.GroupType},
+	{"max(up)", aggregation.MaxType},
+	{"min(up)", aggregation.MinType},
+	{"quantile(0.9, up)", aggregation.QuantileType},
+	{"stddev(up)", aggregation.StandardDeviationType},
+	{"stdvar(up)", aggregation.StandardVarianceType},
+	{"sum(up)", aggregation.SumType},
+	{"topk(2, up)", aggregation.TopKType},
+
+	{"last_over_time(rate(up[1m])[5m:1m])", temporal.LastType},
+	{"sgn(sum(rate(up[5m])) by (job))", linear.SgnType},
+	{"clamp(up, -1, scalar(vector(1)))", linear.ClampType},
+}
+
+func TestFunctionCompatibility(t *testing.T) {
+	for _, tt := range functionCompatibilityTests {
+		t.Run(tt.q, func(t *testing.T) {
+			p, err := Parse(tt.q, models.NewTagOptions())
+			require.NoError(t, err)
+			transforms, _, err := p.DAG()
+			require.NoError(t, err)
+			require.NotEmpty(t, transforms)
+			assert.Equal(t, tt.expectedType, transforms[len(transforms)-1].Op.OpType())
+		})
+	}
+}
+
+func TestAbsentOverTimeParses(t *testing.T) {
+	q := `clamp(absent_over_time(up{job="api",job="web",env="prod",le=~"1.*"}[10m]), 0, 1)`
+	p, err := Parse(q, models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 4)
+	fetch, ok := transforms[0].Op.(functions.FetchOp)
+	require.True(t, ok)
+	assert.Equal(t, "up", fetch.Name)
+	assert.Equal(t, 10*time.Minute, fetch.Range)
+	assert.Equal(t, temporal.PresentType, transforms[1].Op.OpType())
+	assert.Equal(t, temporal.AbsentType, transforms[2].Op.OpType())
+	assert.Equal(t, linear.ClampType, transforms[3].Op.OpType())
+	assert.Equal(t, parser.Edges{
+		{ParentID: transforms[0].ID, ChildID: transforms[1].ID},
+		{ParentID: transforms[1].ID, ChildID: transforms[2].ID},
+		{ParentID: transforms[2].ID, ChildID: transforms[3].ID},
+	}, edges)
+}
+
+func TestPiParses(t *testing.T) {
+	p, err := Parse("clamp(up, 0, pi())", models.NewTagOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "clamp(up, 0, pi())", p.String())
+	transforms, _, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 2)
+	assert.Equal(t, linear.ClampType, transforms[1].Op.OpType())
+}
+
+func TestVectorParses(t *testing.T) {
+	p, err := Parse("up + vector(1)", models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 3)
+	assert.Equal(t, scalar.VectorType, transforms[1].Op.OpType())
+	assert.Equal(t, binary.PlusType, transforms[2].Op.OpType())
+	assert.Len(t, edges, 2, "vector should not be a child of the fetch")
+}
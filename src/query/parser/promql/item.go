@@ -1,101 +0,0 @@
-// Copyright (c) 2018 Uber Technologies, Inc.
-//
-// Permission is hereby granted, free of charge, to any person obtaining a copy
-// of this software and associated documentation files (the "Software"), to deal
-// in the Software without restriction, including without limitation the rights
-// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
-// copies of the Software, and to permit persons to whom the Software is
-// furnished to do so, subject to the following conditions:
-//
-// The above copyright notice and this permission notice shall be included in
-// all copies or substantial portions of the Software.
-//
-// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
-// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
-// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
-// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
-// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
-// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
-// THE SOFTWARE.
-
-package promql
-
-// ItemType which maps to ItemType in Prometheus. Note that this is temporary and
-// once we decide we want to use this approach then we will just expose the item types in Prometheus code base
-type ItemType int
-
-// nolint
-const (
-	itemError ItemType = iota // Error occurred, value is error message
-	itemEOF
-	itemComment
-	itemIdentifier
-	itemMetricIdentifier
-	itemLeftParen
-	itemRightParen
-	itemLeftBrace
-	itemRightBrace
-	itemLeftBracket
-	itemRightBracket
-	itemComma
-	itemAssign
-	itemSemicolon
-	itemString
-	itemNumber
-	itemDuration
-	itemBlank
-	itemTimes
-
-	operatorsStart
-	// Operators.
-	itemSUB
-	itemADD
-	itemMUL
-	itemMOD
-	itemDIV
-	itemLAND
-	itemLOR
-	itemLUnless
-	itemEQL
-	itemNEQ
-	itemLTE
-	itemLSS
-	itemGTE
-	itemGTR
-	itemEQLRegex
-	itemNEQRegex
-	itemPOW
-	operatorsEnd
-
-	aggregatorsStart
-	// Aggregators.
-	itemAvg
-	itemCount
-	itemSum
-	itemMin
-	itemMax
-	itemStddev
-	itemStdvar
-	itemTopK
-	itemBottomK
-	itemCountValues
-	itemQuantile
-	aggregatorsEnd
-
-	keywordsStart
-	// Keywords.
-	itemAlert
-	itemIf
-	itemFor
-	itemLabels
-	itemAnnotations
-	itemOffset
-	itemBy
-	itemWithout
-	itemOn
-	itemIgnoring
-	itemGroupLeft
-	itemGroupRight
-	itemBool
-	keywordsEnd
-)
@@ -22,14 +22,30 @@ package promql
 
 import (
 	"fmt"
+	"math"
 
+	"github.com/m3db/m3/src/query/functions/linear"
 	"github.com/m3db/m3/src/query/functions/scalar"
+	"github.com/m3db/m3/src/query/functions/subquery"
+	"github.com/m3db/m3/src/query/functions/temporal"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 
-	pql "github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/model/labels"
+	pql "github.com/prometheus/prometheus/promql/parser"
 )
 
+// piFunction is the pi function, which is resolved to a constant.
+const piFunction = "pi"
+
+// passthroughFunctions are the functions which do not add a transform, as
+// they leave the values of their argument unchanged.
+var passthroughFunctions = map[string]struct{}{
+	linear.SortType:     struct{}{},
+	linear.SortDescType: struct{}{},
+	scalar.ScalarType:   struct{}{},
+}
+
 type promParser struct {
 	expr    pql.Expr
 	tagOpts models.TagOptions
@@ -124,7 +140,23 @@ func (p *parseState) walk(node pql.Node) error {
 		p.transforms = append(p.transforms, parser.NewTransformFromOperation(operation, p.transformLen()))
 		return nil
 
+	case *pql.SubqueryExpr:
+		return p.walkSubquery(n)
+
 	case *pql.Call:
+		name := n.Func.Name
+		switch name {
+		case piFunction:
+			return p.walk(&pql.NumberLiteral{Val: math.Pi})
+		case temporal.AbsentType:
+			return p.walkAbsentOverTime(n)
+		}
+
+		if name == scalar.VectorType && isTimeCall(n.Args[0]) {
+			// Evaluate the time at each step rather than resolving a constant
+			name = scalar.TimeType
+		}
+
 		expressions := n.Args
 		argTypes := n.Func.ArgTypes
 		argValues := make([]interface{}, 0, len(expressions))
@@ -141,7 +173,10 @@ func (p *parseState) walk(node pql.Node) error {
 			} else if argType == pql.ValueTypeString {
 				stringValues = append(stringValues, expr.(*pql.StringLiteral).Val)
 			} else {
-				if e, ok := expr.(*pql.MatrixSelector); ok {
+				switch e := expr.(type) {
+				case *pql.MatrixSelector:
+					argValues = append(argValues, e.Range)
+				case *pql.SubqueryExpr:
 					argValues = append(argValues, e.Range)
 				}
 
@@ -160,24 +195,7 @@ func (p *parseState) walk(node pql.Node) error {
 			}
 		}
 
-		op, ok, err := NewFunctionExpr(n.Func.Name, argValues, stringValues)
-		if err != nil {
-			return err
-		}
-
-		if !ok {
-			return nil
-		}
-
-		opTransform := parser.NewTransformFromOperation(op, p.transformLen())
-		if op.OpType() != scalar.TimeType {
-			p.edges = append(p.edges, parser.Edge{
-				ParentID: p.lastTransformID(),
-				ChildID:  opTransform.ID,
-			})
-		}
-		p.transforms = append(p.transforms, opTransform)
-		return nil
+		return p.addFunction(name, argValues, stringValues)
 
 	case *pql.BinaryExpr:
 		err := p.walk(n.LHS)
@@ -227,3 +245,115 @@ func (p *parseState) walk(node pql.Node) error {
 		return fmt.Errorf("promql.Walk: unhandled node type %T, %v", node, node)
 	}
 }
+
+// addFunction adds the transform for a function applied to the last transform.
+func (p *parseState) addFunction(
+	name string,
+	argValues []interface{},
+	stringValues []string,
+) error {
+	op, ok, err := NewFunctionExpr(name, argValues, stringValues)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		if _, ok := passthroughFunctions[name]; !ok {
+			return fmt.Errorf("function not supported: %s", name)
+		}
+
+		return nil
+	}
+
+	opTransform := parser.NewTransformFromOperation(op, p.transformLen())
+	if opType := op.OpType(); opType != scalar.TimeType && opType != scalar.VectorType {
+		p.edges = append(p.edges, parser.Edge{
+			ParentID: p.lastTransformID(),
+			ChildID:  opTransform.ID,
+		})
+	}
+	p.transforms = append(p.transforms, opTransform)
+	return nil
+}
+
+// walkAbsentOverTime walks an absent_over_time call, which unlike the other
+// temporal functions does not apply to each selected series but returns a
+// series when none of them has values, tagged like the series it selected.
+func (p *parseState) walkAbsentOverTime(n *pql.Call) error {
+	matrix, ok := n.Args[0].(*pql.MatrixSelector)
+	if !ok {
+		return fmt.Errorf("expected range vector selector in call to %s, got %v",
+			temporal.AbsentType, n.Args[0])
+	}
+
+	vectorSelector, err := matrixVectorSelector(matrix)
+	if err != nil {
+		return err
+	}
+
+	if err := p.walk(matrix); err != nil {
+		return err
+	}
+
+	if err := p.addFunction(temporal.PresentType, []interface{}{matrix.Range}, nil); err != nil {
+		return err
+	}
+
+	// Labels matched more than once are ambiguous so are left out, as done by
+	// Prometheus.
+	matched := make(map[string]int, len(vectorSelector.LabelMatchers))
+	for _, m := range vectorSelector.LabelMatchers {
+		matched[m.Name]++
+	}
+
+	tags := models.NewTags(len(vectorSelector.LabelMatchers), p.tagOpts)
+	for _, m := range vectorSelector.LabelMatchers {
+		if m.Type == labels.MatchEqual && m.Name != promDefaultName && matched[m.Name] == 1 {
+			tags = tags.AddTag(models.Tag{Name: []byte(m.Name), Value: []byte(m.Value)})
+		}
+	}
+
+	opTransform := parser.NewTransformFromOperation(temporal.NewAbsentOp(tags), p.transformLen())
+	p.edges = append(p.edges, parser.Edge{
+		ParentID: p.lastTransformID(),
+		ChildID:  opTransform.ID,
+	})
+	p.transforms = append(p.transforms, opTransform)
+	return nil
+}
+
+func isTimeCall(expr pql.Expr) bool {
+	for {
+		paren, ok := expr.(*pql.ParenExpr)
+		if !ok {
+			break
+		}
+
+		expr = paren.Expr
+	}
+
+	call, ok := expr.(*pql.Call)
+	return ok && call.Func.Name == scalar.TimeType
+}
+
+// walkSubquery compiles the inner expression of a subquery into its own DAG
+// which the subquery evaluates at its step to produce a range of values.
+func (p *parseState) walkSubquery(n *pql.SubqueryExpr) error {
+	if n.Timestamp != nil || n.StartOrEnd != 0 {
+		return errAtModifierNotSupported
+	}
+
+	inner := &parseState{tagOpts: p.tagOpts}
+	if err := inner.walk(n.Expr); err != nil {
+		return err
+	}
+
+	op, err := subquery.NewSubqueryOp(inner.transforms, inner.edges,
+		n.Range, n.Step, n.OriginalOffset)
+	if err != nil {
+		return err
+	}
+
+	p.transforms = append(p.transforms, parser.NewTransformFromOperation(op, p.transformLen()))
+	return nil
+}
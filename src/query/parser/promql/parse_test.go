@@ -22,12 +22,15 @@ package promql
 
 import (
 	"testing"
+	"time"
 
+	"github.com/m3db/m3/src/query/executor/transform"
 	"github.com/m3db/m3/src/query/functions"
 	"github.com/m3db/m3/src/query/functions/aggregation"
 	"github.com/m3db/m3/src/query/functions/binary"
 	"github.com/m3db/m3/src/query/functions/linear"
 	"github.com/m3db/m3/src/query/functions/scalar"
+	"github.com/m3db/m3/src/query/functions/subquery"
 	"github.com/m3db/m3/src/query/functions/tag"
 	"github.com/m3db/m3/src/query/functions/temporal"
 	"github.com/m3db/m3/src/query/models"
@@ -365,3 +368,100 @@ func TestFailedTemporalParse(t *testing.T) {
 	_, err := Parse(q, models.NewTagOptions())
 	require.Error(t, err)
 }
+
+func TestSubqueryParses(t *testing.T) {
+	q := "max_over_time(rate(http_requests_total[5m])[1h:1m] offset 10m)"
+	p, err := Parse(q, models.NewTagOptions())
+	require.NoError(t, err)
+	assert.Equal(t, q, p.String())
+
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 2)
+	assert.Equal(t, subquery.SubqueryType, transforms[0].Op.OpType())
+	assert.Equal(t, parser.NodeID("0"), transforms[0].ID)
+	assert.Equal(t, transform.BoundSpec{
+		Range:  time.Hour,
+		Offset: 10 * time.Minute,
+	}, transforms[0].Op.(transform.BoundOp).Bounds())
+	assert.Equal(t, temporal.MaxType, transforms[1].Op.OpType())
+	assert.Equal(t, parser.NodeID("1"), transforms[1].ID)
+	require.Len(t, edges, 1)
+	assert.Equal(t, parser.NodeID("0"), edges[0].ParentID)
+	assert.Equal(t, parser.NodeID("1"), edges[0].ChildID)
+}
+
+func TestNestedSubqueryParses(t *testing.T) {
+	q := "max_over_time(deriv(rate(http_requests_total[1m])[5m:1m])[10m:])"
+	p, err := Parse(q, models.NewTagOptions())
+	require.NoError(t, err)
+
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 2)
+	assert.Equal(t, subquery.SubqueryType, transforms[0].Op.OpType())
+	assert.Equal(t, temporal.MaxType, transforms[1].Op.OpType())
+	assert.Len(t, edges, 1)
+}
+
+func TestSubqueryBounds(t *testing.T) {
+	tests := []struct {
+		q        string
+		expected transform.BoundSpec
+	}{
+		{
+			q:        "max_over_time(up[1h:1m])",
+			expected: transform.BoundSpec{Range: time.Hour},
+		},
+		{
+			q:        `max_over_time(up{job="a:b[1m:]"}[1h:])`,
+			expected: transform.BoundSpec{Range: time.Hour},
+		},
+		{
+			q: "min_over_time((up + 1)[30m:5m] offset 1h)",
+			expected: transform.BoundSpec{
+				Range:  30 * time.Minute,
+				Offset: time.Hour,
+			},
+		},
+		{
+			q:        "max_over_time(sum(rate(up[5m])) by (job)[1h:1m])",
+			expected: transform.BoundSpec{Range: time.Hour},
+		},
+		{
+			q:        "max_over_time(sum by (job) (rate(up[5m]))[1h:1m])",
+			expected: transform.BoundSpec{Range: time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			p, err := Parse(tt.q, models.NewTagOptions())
+			require.NoError(t, err)
+
+			transforms, _, err := p.DAG()
+			require.NoError(t, err)
+			require.Len(t, transforms, 2)
+			assert.Equal(t, subquery.SubqueryType, transforms[0].Op.OpType())
+			assert.Equal(t, tt.expected, transforms[0].Op.(transform.BoundOp).Bounds())
+		})
+	}
+}
+
+func TestFailedSubqueryParse(t *testing.T) {
+	for _, q := range []string{
+		"max_over_time(http_requests_total[5m][1h:1m])",
+		"max_over_time(rate(http_requests_total[5m])[1h:1x])",
+		"max_over_time(rate(http_requests_total[5m])[1h:1m:1s])",
+		"max_over_time([1h:1m])",
+		"max_over_time(rate(http_requests_total[5m])[1x:1m])",
+	} {
+		t.Run(q, func(t *testing.T) {
+			p, err := Parse(q, models.NewTagOptions())
+			if err == nil {
+				_, _, err = p.DAG()
+			}
+			require.Error(t, err)
+		})
+	}
+}
@@ -26,7 +26,7 @@ import (
 
 	"github.com/m3db/m3/src/query/functions/binary"
 
-	pql "github.com/prometheus/prometheus/promql"
+	pql "github.com/prometheus/prometheus/promql/parser"
 )
 
 var (
@@ -102,6 +102,8 @@ func resolveScalarArgumentWithNesting(expr pql.Expr, nesting int) (float64, int,
 			}
 
 			return resolveScalarArgumentWithNesting(n.Args[0], nesting-1)
+		} else if n.Func.Name == piFunction {
+			return math.Pi, nesting, nil
 		}
 
 		return 0, 0, nil
@@ -21,6 +21,7 @@
 package promql
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -36,15 +37,21 @@ import (
 	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/parser/common"
 
-	"github.com/prometheus/prometheus/pkg/labels"
-	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/model/labels"
+	promql "github.com/prometheus/prometheus/promql/parser"
 )
 
+var errAtModifierNotSupported = errors.New("@ modifier not supported")
+
 // NewSelectorFromVector creates a new fetchop
 func NewSelectorFromVector(
 	n *promql.VectorSelector,
 	tagOpts models.TagOptions,
 ) (parser.Params, error) {
+	if n.Timestamp != nil || n.StartOrEnd != 0 {
+		return nil, errAtModifierNotSupported
+	}
+
 	matchers, err := LabelMatchersToModelMatcher(n.LabelMatchers, tagOpts)
 	if err != nil {
 		return nil, err
@@ -52,7 +59,7 @@ func NewSelectorFromVector(
 
 	return functions.FetchOp{
 		Name:     n.Name,
-		Offset:   n.Offset,
+		Offset:   n.OriginalOffset,
 		Matchers: matchers,
 	}, nil
 }
@@ -62,17 +69,30 @@ func NewSelectorFromMatrix(
 	n *promql.MatrixSelector,
 	tagOpts models.TagOptions,
 ) (parser.Params, error) {
-	matchers, err := LabelMatchersToModelMatcher(n.LabelMatchers, tagOpts)
+	vectorSelector, err := matrixVectorSelector(n)
 	if err != nil {
 		return nil, err
 	}
 
-	return functions.FetchOp{
-		Name:     n.Name,
-		Offset:   n.Offset,
-		Matchers: matchers,
-		Range:    n.Range,
-	}, nil
+	op, err := NewSelectorFromVector(vectorSelector, tagOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch := op.(functions.FetchOp)
+	fetch.Range = n.Range
+	return fetch, nil
+}
+
+// matrixVectorSelector returns the vector selector of a matrix selector.
+func matrixVectorSelector(n *promql.MatrixSelector) (*promql.VectorSelector, error) {
+	vectorSelector, ok := n.VectorSelector.(*promql.VectorSelector)
+	if !ok {
+		return nil, fmt.Errorf("expected vector selector in range selector, got %v",
+			n.VectorSelector)
+	}
+
+	return vectorSelector, nil
 }
 
 // NewAggregationOperator creates a new aggregation operator based on the type
@@ -113,28 +133,30 @@ func NewAggregationOperator(expr *promql.AggregateExpr) (parser.Params, error) {
 
 func getAggOpType(opType promql.ItemType) string {
 	switch opType {
-	case promql.ItemType(itemSum):
+	case promql.SUM:
 		return aggregation.SumType
-	case promql.ItemType(itemMin):
+	case promql.MIN:
 		return aggregation.MinType
-	case promql.ItemType(itemMax):
+	case promql.MAX:
 		return aggregation.MaxType
-	case promql.ItemType(itemAvg):
+	case promql.AVG:
 		return aggregation.AverageType
-	case promql.ItemType(itemStddev):
+	case promql.STDDEV:
 		return aggregation.StandardDeviationType
-	case promql.ItemType(itemStdvar):
+	case promql.STDVAR:
 		return aggregation.StandardVarianceType
-	case promql.ItemType(itemCount):
+	case promql.COUNT:
 		return aggregation.CountType
+	case promql.GROUP:
+		return aggregation.GroupType
 
-	case promql.ItemType(itemTopK):
+	case promql.TOPK:
 		return aggregation.TopKType
-	case promql.ItemType(itemBottomK):
+	case promql.BOTTOMK:
 		return aggregation.BottomKType
-	case promql.ItemType(itemQuantile):
+	case promql.QUANTILE:
 		return aggregation.QuantileType
-	case promql.ItemType(itemCountValues):
+	case promql.COUNT_VALUES:
 		return aggregation.CountValuesType
 	default:
 		return common.UnknownOpType
@@ -177,7 +199,11 @@ func NewFunctionExpr(
 
 	switch name {
 	case linear.AbsType, linear.CeilType, linear.ExpType, linear.FloorType, linear.LnType,
-		linear.Log10Type, linear.Log2Type, linear.SqrtType:
+		linear.Log10Type, linear.Log2Type, linear.SqrtType, linear.SgnType,
+		linear.DegType, linear.RadType, linear.AcosType, linear.AcoshType,
+		linear.AsinType, linear.AsinhType, linear.AtanType, linear.AtanhType,
+		linear.CosType, linear.CoshType, linear.SinType, linear.SinhType,
+		linear.TanType, linear.TanhType:
 		p, err = linear.NewMathOp(name)
 		return p, true, err
 
@@ -185,7 +211,7 @@ func NewFunctionExpr(
 		p = linear.NewAbsentOp()
 		return p, true, err
 
-	case linear.ClampMinType, linear.ClampMaxType:
+	case linear.ClampMinType, linear.ClampMaxType, linear.ClampType:
 		p, err = linear.NewClampOp(argValues, name)
 		return p, true, err
 
@@ -208,7 +234,7 @@ func NewFunctionExpr(
 
 	case temporal.AvgType, temporal.CountType, temporal.MinType,
 		temporal.MaxType, temporal.SumType, temporal.StdDevType,
-		temporal.StdVarType:
+		temporal.StdVarType, temporal.LastType, temporal.PresentType:
 		p, err = temporal.NewAggOp(argValues, name)
 		return p, true, err
 
@@ -247,6 +273,19 @@ func NewFunctionExpr(
 		p, err = scalar.NewScalarOp(func(t time.Time) float64 { return float64(t.Unix()) }, scalar.TimeType)
 		return p, true, err
 
+	case scalar.VectorType:
+		if len(argValues) != 1 {
+			return nil, false, fmt.Errorf("invalid number of args for %s: %d", name, len(argValues))
+		}
+
+		val, ok := argValues[0].(float64)
+		if !ok {
+			return nil, false, fmt.Errorf("unable to cast to scalar argument: %v", argValues[0])
+		}
+
+		p, err = scalar.NewScalarOp(func(_ time.Time) float64 { return val }, scalar.VectorType)
+		return p, true, err
+
 	default:
 		// TODO: handle other types
 		return nil, false, fmt.Errorf("function not supported: %s", name)
@@ -255,37 +294,37 @@ func NewFunctionExpr(
 
 func getBinaryOpType(opType promql.ItemType) string {
 	switch opType {
-	case promql.ItemType(itemLAND):
+	case promql.LAND:
 		return binary.AndType
-	case promql.ItemType(itemLOR):
+	case promql.LOR:
 		return binary.OrType
-	case promql.ItemType(itemLUnless):
+	case promql.LUNLESS:
 		return binary.UnlessType
 
-	case promql.ItemType(itemADD):
+	case promql.ADD:
 		return binary.PlusType
-	case promql.ItemType(itemSUB):
+	case promql.SUB:
 		return binary.MinusType
-	case promql.ItemType(itemMUL):
+	case promql.MUL:
 		return binary.MultiplyType
-	case promql.ItemType(itemDIV):
+	case promql.DIV:
 		return binary.DivType
-	case promql.ItemType(itemPOW):
+	case promql.POW:
 		return binary.ExpType
-	case promql.ItemType(itemMOD):
+	case promql.MOD:
 		return binary.ModType
 
-	case promql.ItemType(itemEQL):
+	case promql.EQLC:
 		return binary.EqType
-	case promql.ItemType(itemNEQ):
+	case promql.NEQ:
 		return binary.NotEqType
-	case promql.ItemType(itemGTR):
+	case promql.GTR:
 		return binary.GreaterType
-	case promql.ItemType(itemLSS):
+	case promql.LSS:
 		return binary.LesserType
-	case promql.ItemType(itemGTE):
+	case promql.GTE:
 		return binary.GreaterEqType
-	case promql.ItemType(itemLTE):
+	case promql.LTE:
 		return binary.LesserEqType
 
 	default:
@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 )
 
 // PhysicalPlan represents the physical plan
@@ -39,6 +40,7 @@ type PhysicalPlan struct {
 	Debug            bool
 	BlockType        models.FetchedBlockType
 	LookbackDuration time.Duration
+	TimeSharding     *timeshard.Options
 }
 
 // ResultOp is resonsible for delivering results to the clients
@@ -0,0 +1,277 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+)
+
+const (
+	// alertNameLabel is the label holding the name of the alerting rule.
+	alertNameLabel = "alertname"
+
+	// resolvedRetention is how long resolved alerts are kept, and so
+	// resent, to make sure the resolution reaches the receiver.
+	resolvedRetention = 15 * time.Minute
+)
+
+// AlertState is the state of an alert.
+type AlertState int
+
+const (
+	// StateInactive is the state of an alert which is no longer active.
+	StateInactive AlertState = iota
+	// StatePending is the state of an active alert which has not been
+	// active for the hold duration of its rule yet.
+	StatePending
+	// StateFiring is the state of an alert which has been active for the
+	// hold duration of its rule.
+	StateFiring
+)
+
+func (s AlertState) String() string {
+	switch s {
+	case StateInactive:
+		return "inactive"
+	case StatePending:
+		return "pending"
+	case StateFiring:
+		return "firing"
+	}
+	return "unknown"
+}
+
+// Alert is an instance of an alerting rule for a single series.
+type Alert struct {
+	State       AlertState
+	Labels      map[string]string
+	Annotations map[string]string
+	Value       float64
+	ActiveAt    time.Time
+	FiredAt     time.Time
+	ResolvedAt  time.Time
+	LastSentAt  time.Time
+}
+
+func (a *Alert) needsSending(t time.Time, resendDelay time.Duration) bool {
+	if a.State == StatePending {
+		return false
+	}
+
+	// Send resolved alerts immediately.
+	if a.ResolvedAt.After(a.LastSentAt) {
+		return true
+	}
+
+	return a.LastSentAt.Add(resendDelay).Before(t) ||
+		a.LastSentAt.Add(resendDelay).Equal(t)
+}
+
+// alertingRule fires an alert for every series returned by its expression
+// once the series has been returned for the hold duration.
+type alertingRule struct {
+	sync.Mutex
+
+	name         string
+	expr         string
+	holdDuration time.Duration
+	labels       map[string]string
+	annotations  map[string]string
+
+	// active maps the fingerprint of the alert labels to the alert.
+	active map[string]*Alert
+}
+
+func newAlertingRule(r Rule) *alertingRule {
+	return &alertingRule{
+		name:         r.Alert,
+		expr:         r.Expr,
+		holdDuration: r.For,
+		labels:       r.Labels,
+		annotations:  r.Annotations,
+		active:       make(map[string]*Alert),
+	}
+}
+
+func (r *alertingRule) eval(
+	ctx context.Context,
+	t time.Time,
+	query QueryFunc,
+	tagOpts models.TagOptions,
+) error {
+	vector, err := query(ctx, r.expr, t)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	seen := make(map[string]struct{}, len(vector))
+	for _, sample := range vector {
+		labels, annotations, err := r.expand(sample, tagOpts)
+		if err != nil {
+			return err
+		}
+
+		fp := fingerprint(labels)
+		seen[fp] = struct{}{}
+		if alert, ok := r.active[fp]; ok && alert.State != StateInactive {
+			alert.Value = sample.Value
+			alert.Annotations = annotations
+			continue
+		}
+
+		r.active[fp] = &Alert{
+			State:       StatePending,
+			Labels:      labels,
+			Annotations: annotations,
+			Value:       sample.Value,
+			ActiveAt:    t,
+		}
+	}
+
+	for fp, alert := range r.active {
+		if _, ok := seen[fp]; !ok {
+			// Pending alerts are dropped, firing alerts are resolved and
+			// kept around so that the resolution is sent.
+			if alert.State == StatePending ||
+				(!alert.ResolvedAt.IsZero() && t.Sub(alert.ResolvedAt) > resolvedRetention) {
+				delete(r.active, fp)
+			}
+
+			if alert.State == StateFiring {
+				alert.State = StateInactive
+				alert.ResolvedAt = t
+			}
+
+			continue
+		}
+
+		if alert.State == StatePending && t.Sub(alert.ActiveAt) >= r.holdDuration {
+			alert.State = StateFiring
+			alert.FiredAt = t
+		}
+	}
+
+	return nil
+}
+
+// expand returns the labels and annotations of the alert for a sample, the
+// metric name is dropped and the rule labels override the sample labels.
+func (r *alertingRule) expand(
+	sample Sample,
+	tagOpts models.TagOptions,
+) (map[string]string, map[string]string, error) {
+	sampleLabels := make(map[string]string, sample.Tags.Len())
+	metricName := string(tagOpts.MetricName())
+	for _, tag := range sample.Tags.Tags {
+		name := string(tag.Name)
+		if name == metricName {
+			continue
+		}
+
+		sampleLabels[name] = string(tag.Value)
+	}
+
+	labels := make(map[string]string, len(sampleLabels)+len(r.labels)+1)
+	for name, value := range sampleLabels {
+		labels[name] = value
+	}
+
+	for name, text := range r.labels {
+		value, err := expandTemplate(text, sampleLabels, sample.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		labels[name] = value
+	}
+
+	labels[alertNameLabel] = r.name
+	annotations := make(map[string]string, len(r.annotations))
+	for name, text := range r.annotations {
+		value, err := expandTemplate(text, labels, sample.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		annotations[name] = value
+	}
+
+	return labels, annotations, nil
+}
+
+// alertsToSend returns copies of the alerts which need to be sent to the
+// notifier at the given time and marks them as sent.
+func (r *alertingRule) alertsToSend(t time.Time, resendDelay time.Duration) []Alert {
+	r.Lock()
+	defer r.Unlock()
+
+	var alerts []Alert
+	for _, alert := range r.active {
+		if !alert.needsSending(t, resendDelay) {
+			continue
+		}
+
+		alert.LastSentAt = t
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts
+}
+
+// Alerts returns copies of the active alerts of the rule.
+func (r *alertingRule) Alerts() []Alert {
+	r.Lock()
+	defer r.Unlock()
+
+	alerts := make([]Alert, 0, len(r.active))
+	for _, alert := range r.active {
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts
+}
+
+func fingerprint(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	var b bytes.Buffer
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(0xff)
+		b.WriteString(labels[name])
+		b.WriteByte(0xff)
+	}
+
+	return b.String()
+}
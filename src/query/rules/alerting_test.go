@@ -0,0 +1,134 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertingRuleLifecycle(t *testing.T) {
+	tagOpts := models.NewTagOptions()
+	query := &staticQueryFunc{results: map[string]Vector{}}
+	rule := newAlertingRule(Rule{
+		Alert:  "HighErrorRate",
+		Expr:   "errors > 1",
+		For:    time.Minute,
+		Labels: map[string]string{"severity": "page"},
+		Annotations: map[string]string{
+			"summary": "{{ $labels.job }} has {{ $value }} errors",
+		},
+	})
+
+	var (
+		ctx   = context.Background()
+		start = time.Now().Truncate(time.Minute)
+		delay = time.Minute
+	)
+	query.results["errors > 1"] = Vector{
+		{Tags: newTestTags(tagOpts, "__name__", "errors", "job", "api"), Value: 3},
+	}
+
+	// Alert becomes pending.
+	require.NoError(t, rule.eval(ctx, start, query.query, tagOpts))
+	alerts := rule.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StatePending, alerts[0].State)
+	assert.Equal(t, map[string]string{
+		"alertname": "HighErrorRate",
+		"job":       "api",
+		"severity":  "page",
+	}, alerts[0].Labels)
+	assert.Equal(t, "api has 3 errors", alerts[0].Annotations["summary"])
+	assert.Len(t, rule.alertsToSend(start, delay), 0)
+
+	// Alert is still pending before the hold duration.
+	require.NoError(t, rule.eval(ctx, start.Add(30*time.Second), query.query, tagOpts))
+	assert.Equal(t, StatePending, rule.Alerts()[0].State)
+
+	// Alert fires after the hold duration.
+	firedAt := start.Add(time.Minute)
+	require.NoError(t, rule.eval(ctx, firedAt, query.query, tagOpts))
+	alerts = rule.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateFiring, alerts[0].State)
+	assert.Equal(t, start, alerts[0].ActiveAt)
+	assert.Equal(t, firedAt, alerts[0].FiredAt)
+	require.Len(t, rule.alertsToSend(firedAt, delay), 1)
+
+	// Firing alerts are only resent after the resend delay.
+	assert.Len(t, rule.alertsToSend(firedAt.Add(30*time.Second), delay), 0)
+	assert.Len(t, rule.alertsToSend(firedAt.Add(time.Minute), delay), 1)
+
+	// Alert resolves once the series is no longer returned.
+	resolvedAt := firedAt.Add(2 * time.Minute)
+	query.results["errors > 1"] = nil
+	require.NoError(t, rule.eval(ctx, resolvedAt, query.query, tagOpts))
+	alerts = rule.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateInactive, alerts[0].State)
+	assert.Equal(t, resolvedAt, alerts[0].ResolvedAt)
+
+	toSend := rule.alertsToSend(resolvedAt, delay)
+	require.Len(t, toSend, 1)
+	assert.Equal(t, StateInactive, toSend[0].State)
+
+	// Resolved alerts are dropped after the retention.
+	require.NoError(t, rule.eval(ctx, resolvedAt.Add(resolvedRetention+time.Minute),
+		query.query, tagOpts))
+	assert.Len(t, rule.Alerts(), 0)
+}
+
+func TestAlertingRulePendingDropped(t *testing.T) {
+	tagOpts := models.NewTagOptions()
+	query := &staticQueryFunc{results: map[string]Vector{
+		"up == 0": {{Tags: newTestTags(tagOpts, "instance", "a"), Value: 0}},
+	}}
+	rule := newAlertingRule(Rule{Alert: "InstanceDown", Expr: "up == 0", For: time.Hour})
+
+	now := time.Now()
+	require.NoError(t, rule.eval(context.Background(), now, query.query, tagOpts))
+	require.Len(t, rule.Alerts(), 1)
+
+	query.results["up == 0"] = nil
+	require.NoError(t, rule.eval(context.Background(), now.Add(time.Minute),
+		query.query, tagOpts))
+	assert.Len(t, rule.Alerts(), 0)
+}
+
+func TestAlertingRuleFiresImmediatelyWithoutHold(t *testing.T) {
+	tagOpts := models.NewTagOptions()
+	query := &staticQueryFunc{results: map[string]Vector{
+		"up == 0": {{Tags: newTestTags(tagOpts, "instance", "a"), Value: 0}},
+	}}
+	rule := newAlertingRule(Rule{Alert: "InstanceDown", Expr: "up == 0"})
+
+	require.NoError(t, rule.eval(context.Background(), time.Now(), query.query, tagOpts))
+	alerts := rule.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateFiring, alerts[0].State)
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3x/instrument"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultQueryTimeout        = time.Minute
+	defaultAlertmanagerTimeout = 10 * time.Second
+)
+
+var (
+	errNoKVStore = errors.New("rules KV key is configured but no KV store is available")
+)
+
+// Configuration configures the evaluation of recording and alerting rules.
+type Configuration struct {
+	// RuleFiles are paths of rule files in the Prometheus rule file format.
+	RuleFiles []string `yaml:"ruleFiles"`
+
+	// KV configures loading rule groups from a KV key, the groups are
+	// reloaded whenever the key changes.
+	KV *KVConfiguration `yaml:"kv"`
+
+	// EvaluationInterval is the interval of groups which do not set one.
+	EvaluationInterval *time.Duration `yaml:"evaluationInterval"`
+
+	// QueryTimeout is the timeout of a single rule evaluation.
+	QueryTimeout *time.Duration `yaml:"queryTimeout"`
+
+	// Alertmanager configures where alerts are sent.
+	Alertmanager *AlertmanagerConfiguration `yaml:"alertmanager"`
+}
+
+// KVConfiguration configures loading rule groups from a KV key holding a
+// string proto with the rule file contents.
+type KVConfiguration struct {
+	Key string `yaml:"key" validate:"nonzero"`
+}
+
+// AlertmanagerConfiguration configures an Alertmanager compatible webhook.
+type AlertmanagerConfiguration struct {
+	// URL is the URL alerts are posted to, e.g.
+	// http://alertmanager:9093/api/v1/alerts.
+	URL string `yaml:"url" validate:"nonzero"`
+
+	// Timeout is the timeout of a single notification.
+	Timeout *time.Duration `yaml:"timeout"`
+
+	// GeneratorURL is the URL alerts link back to.
+	GeneratorURL string `yaml:"generatorURL"`
+}
+
+// EvaluationIntervalOrDefault returns the configured evaluation interval
+// or the default.
+func (c Configuration) EvaluationIntervalOrDefault() time.Duration {
+	if c.EvaluationInterval != nil {
+		return *c.EvaluationInterval
+	}
+	return defaultEvaluationInterval
+}
+
+// QueryTimeoutOrDefault returns the configured query timeout or the default.
+func (c Configuration) QueryTimeoutOrDefault() time.Duration {
+	if c.QueryTimeout != nil {
+		return *c.QueryTimeout
+	}
+	return defaultQueryTimeout
+}
+
+// NewManager creates and starts a rules manager evaluating the rule groups
+// of the configured rule files and KV key. The KV store is only required
+// when a KV key is configured.
+func (c Configuration) NewManager(
+	queryFn QueryFunc,
+	appender storage.Appender,
+	tagOpts models.TagOptions,
+	store kv.Store,
+	instrumentOpts instrument.Options,
+) (*Manager, error) {
+	var notifier Notifier
+	if am := c.Alertmanager; am != nil {
+		timeout := defaultAlertmanagerTimeout
+		if am.Timeout != nil {
+			timeout = *am.Timeout
+		}
+
+		// Alerts stay valid for a few evaluations so a single missed
+		// notification does not resolve them.
+		validFor := 3 * c.EvaluationIntervalOrDefault()
+		if validFor < 3*defaultResendDelay {
+			validFor = 3 * defaultResendDelay
+		}
+
+		notifier = NewWebhookNotifier(am.URL, am.GeneratorURL, timeout, validFor)
+	}
+
+	fileGroups, err := ParseRuleGroupsFiles(c.RuleFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := NewManager(ManagerOptions{
+		QueryFunc:          queryFn,
+		Appender:           appender,
+		Notifier:           notifier,
+		TagOptions:         tagOpts,
+		EvaluationInterval: c.EvaluationIntervalOrDefault(),
+		InstrumentOptions:  instrumentOpts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.KV == nil {
+		if err := manager.Update(fileGroups); err != nil {
+			manager.Close()
+			return nil, err
+		}
+
+		return manager, nil
+	}
+
+	if store == nil {
+		manager.Close()
+		return nil, errNoKVStore
+	}
+
+	if err := manager.watchKV(store, c.KV.Key, fileGroups); err != nil {
+		manager.Close()
+		return nil, err
+	}
+
+	return manager, nil
+}
+
+// watchKV evaluates the rule groups of the KV key merged with the static
+// groups, updating them whenever the key changes.
+func (m *Manager) watchKV(store kv.Store, key string, static RuleGroups) error {
+	update := func(value kv.Value) error {
+		if value == nil {
+			return m.Update(static)
+		}
+
+		var proto commonpb.StringProto
+		if err := value.Unmarshal(&proto); err != nil {
+			return err
+		}
+
+		groups, err := ParseRuleGroups([]byte(proto.Value))
+		if err != nil {
+			return err
+		}
+
+		return m.Update(static.Merge(groups))
+	}
+
+	// Eagerly load the current value so rules are evaluated from startup
+	// even if the watch fires later.
+	value, err := store.Get(key)
+	if err != nil && err != kv.ErrNotFound {
+		return err
+	}
+
+	if err == kv.ErrNotFound {
+		value = nil
+	}
+
+	if err := update(value); err != nil {
+		return err
+	}
+
+	watch, err := store.Watch(key)
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	m.kvWatch = watch
+	m.Unlock()
+
+	go func() {
+		for range watch.C() {
+			if err := update(watch.Get()); err != nil {
+				m.logger.Error("could not update rule groups from KV",
+					zap.String("key", key), zap.Error(err))
+			}
+		}
+	}()
+
+	return nil
+}
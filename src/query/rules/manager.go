@@ -0,0 +1,412 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/instrument"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultEvaluationInterval = time.Minute
+	defaultResendDelay        = time.Minute
+)
+
+var (
+	errNoQueryFunc          = errors.New("rules manager requires a query function")
+	errNoAppender           = errors.New("rules manager requires an appender")
+	errNoTagOptions         = errors.New("rules manager requires tag options")
+	errNoInstrumentOptions  = errors.New("rules manager requires instrument options")
+	errInvalidEvalInterval  = errors.New("rules evaluation interval must be positive")
+	errRulesManagerIsClosed = errors.New("rules manager is closed")
+)
+
+// ManagerOptions are the options of a rules manager.
+type ManagerOptions struct {
+	// QueryFunc evaluates rule expressions.
+	QueryFunc QueryFunc
+	// Appender writes the results of recording rules.
+	Appender storage.Appender
+	// Notifier receives alerts, alerts are only tracked when not set.
+	Notifier Notifier
+	// TagOptions are the tag options of the recorded series.
+	TagOptions models.TagOptions
+	// EvaluationInterval is the interval of groups which do not set one.
+	EvaluationInterval time.Duration
+	// ResendDelay is how often firing alerts are resent to the notifier.
+	ResendDelay time.Duration
+	// NowFn returns the current time, defaults to time.Now.
+	NowFn clock.NowFn
+	// InstrumentOptions are the instrument options.
+	InstrumentOptions instrument.Options
+}
+
+// Validate validates the manager options.
+func (o ManagerOptions) Validate() error {
+	if o.QueryFunc == nil {
+		return errNoQueryFunc
+	}
+	if o.Appender == nil {
+		return errNoAppender
+	}
+	if o.TagOptions == nil {
+		return errNoTagOptions
+	}
+	if o.InstrumentOptions == nil {
+		return errNoInstrumentOptions
+	}
+	if o.EvaluationInterval <= 0 {
+		return errInvalidEvalInterval
+	}
+	return nil
+}
+
+type managerMetrics struct {
+	evaluations    tally.Counter
+	evalFailures   tally.Counter
+	evalDuration   tally.Timer
+	missedIters    tally.Counter
+	notifications  tally.Counter
+	notifyFailures tally.Counter
+}
+
+func newManagerMetrics(scope tally.Scope) managerMetrics {
+	return managerMetrics{
+		evaluations:    scope.Counter("evaluations"),
+		evalFailures:   scope.Counter("evaluation-failures"),
+		evalDuration:   scope.Timer("evaluation-duration"),
+		missedIters:    scope.Counter("missed-iterations"),
+		notifications:  scope.Counter("notifications"),
+		notifyFailures: scope.Counter("notification-failures"),
+	}
+}
+
+// Manager evaluates rule groups on their intervals, recording the results
+// of recording rules and sending the alerts of alerting rules.
+type Manager struct {
+	sync.Mutex
+
+	opts    ManagerOptions
+	logger  *zap.Logger
+	metrics managerMetrics
+	groups  map[string]*group
+	kvWatch kv.ValueWatch
+	closed  bool
+}
+
+// NewManager returns a new rules manager with no rule groups.
+func NewManager(opts ManagerOptions) (*Manager, error) {
+	if opts.ResendDelay <= 0 {
+		opts.ResendDelay = defaultResendDelay
+	}
+
+	if opts.NowFn == nil {
+		opts.NowFn = time.Now
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		opts:    opts,
+		logger:  opts.InstrumentOptions.ZapLogger(),
+		metrics: newManagerMetrics(opts.InstrumentOptions.MetricsScope()),
+		groups:  make(map[string]*group),
+	}, nil
+}
+
+// Update replaces the rule groups being evaluated. Alert state is kept for
+// alerting rules which are unchanged.
+func (m *Manager) Update(groups RuleGroups) error {
+	if err := groups.Validate(); err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if m.closed {
+		return errRulesManagerIsClosed
+	}
+
+	newGroups := make(map[string]*group, len(groups.Groups))
+	for _, g := range groups.Groups {
+		newGroup := newGroup(g, m)
+		if oldGroup, ok := m.groups[g.Name]; ok {
+			oldGroup.stop()
+			newGroup.copyState(oldGroup)
+		}
+
+		newGroups[g.Name] = newGroup
+	}
+
+	for name, oldGroup := range m.groups {
+		if _, ok := newGroups[name]; !ok {
+			oldGroup.stop()
+		}
+	}
+
+	m.groups = newGroups
+	for _, g := range m.groups {
+		g.start()
+	}
+
+	m.logger.Info("updated rule groups", zap.Int("groups", len(m.groups)))
+	return nil
+}
+
+// Alerts returns the active alerts of all alerting rules.
+func (m *Manager) Alerts() []Alert {
+	m.Lock()
+	defer m.Unlock()
+
+	var alerts []Alert
+	for _, g := range m.groups {
+		for _, r := range g.alertingRules {
+			alerts = append(alerts, r.Alerts()...)
+		}
+	}
+
+	return alerts
+}
+
+// Close stops the evaluation of all rule groups.
+func (m *Manager) Close() error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.closed {
+		return errRulesManagerIsClosed
+	}
+
+	m.closed = true
+	if m.kvWatch != nil {
+		m.kvWatch.Close()
+	}
+
+	for _, g := range m.groups {
+		g.stop()
+	}
+
+	m.groups = nil
+	return nil
+}
+
+type evaluator interface {
+	eval(ctx context.Context, t time.Time) error
+}
+
+// group is a rule group being evaluated, rules are evaluated in order so
+// that later rules may use the series recorded by earlier rules.
+type group struct {
+	name          string
+	interval      time.Duration
+	rules         []evaluator
+	alertingRules []*alertingRule
+	manager       *Manager
+
+	doneCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newGroup(g RuleGroup, m *Manager) *group {
+	interval := g.Interval
+	if interval <= 0 {
+		interval = m.opts.EvaluationInterval
+	}
+
+	result := &group{
+		name:     g.Name,
+		interval: interval,
+		rules:    make([]evaluator, 0, len(g.Rules)),
+		manager:  m,
+		doneCh:   make(chan struct{}),
+	}
+
+	for _, r := range g.Rules {
+		if r.Record != "" {
+			result.rules = append(result.rules, &recordingEvaluator{
+				rule: newRecordingRule(r),
+				opts: m.opts,
+			})
+			continue
+		}
+
+		rule := newAlertingRule(r)
+		result.alertingRules = append(result.alertingRules, rule)
+		result.rules = append(result.rules, &alertingEvaluator{
+			rule: rule,
+			opts: m.opts,
+		})
+	}
+
+	return result
+}
+
+// copyState carries over the active alerts of identical alerting rules.
+func (g *group) copyState(from *group) {
+	for _, r := range g.alertingRules {
+		for _, old := range from.alertingRules {
+			if r.name != old.name || r.expr != old.expr {
+				continue
+			}
+
+			old.Lock()
+			for fp, alert := range old.active {
+				r.active[fp] = alert
+			}
+			old.Unlock()
+			break
+		}
+	}
+}
+
+func (g *group) start() {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.run()
+	}()
+}
+
+func (g *group) stop() {
+	close(g.doneCh)
+	g.wg.Wait()
+}
+
+func (g *group) run() {
+	// Align evaluations to the interval so that recorded series have
+	// consistent timestamps across restarts.
+	nowFn := g.manager.opts.NowFn
+	now := nowFn()
+	next := now.Truncate(g.interval).Add(g.interval)
+	select {
+	case <-time.After(next.Sub(now)):
+	case <-g.doneCh:
+		return
+	}
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	evalTime := next
+	for {
+		g.evalAndNotify(evalTime)
+
+		select {
+		case <-ticker.C:
+		case <-g.doneCh:
+			return
+		}
+
+		now := nowFn()
+		missed := int64(now.Sub(evalTime)/g.interval) - 1
+		if missed > 0 {
+			g.manager.metrics.missedIters.Inc(missed)
+		}
+
+		evalTime = now.Truncate(g.interval)
+	}
+}
+
+func (g *group) evalAndNotify(t time.Time) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g.eval(ctx, t)
+	g.notify(ctx, t)
+}
+
+func (g *group) eval(ctx context.Context, t time.Time) {
+	var (
+		metrics = g.manager.metrics
+		logger  = g.manager.logger
+		nowFn   = g.manager.opts.NowFn
+	)
+	for i, r := range g.rules {
+		start := nowFn()
+		err := r.eval(ctx, t)
+		metrics.evalDuration.Record(nowFn().Sub(start))
+		metrics.evaluations.Inc(1)
+		if err != nil {
+			metrics.evalFailures.Inc(1)
+			logger.Error("rule evaluation failed",
+				zap.String("group", g.name),
+				zap.Int("rule", i),
+				zap.Error(err))
+		}
+	}
+}
+
+func (g *group) notify(ctx context.Context, t time.Time) {
+	notifier := g.manager.opts.Notifier
+	if notifier == nil {
+		return
+	}
+
+	var alerts []Alert
+	for _, r := range g.alertingRules {
+		alerts = append(alerts, r.alertsToSend(t, g.manager.opts.ResendDelay)...)
+	}
+
+	if len(alerts) == 0 {
+		return
+	}
+
+	metrics := g.manager.metrics
+	metrics.notifications.Inc(int64(len(alerts)))
+	if err := notifier.Send(ctx, alerts); err != nil {
+		metrics.notifyFailures.Inc(int64(len(alerts)))
+		g.manager.logger.Error("alert notification failed",
+			zap.String("group", g.name),
+			zap.Error(err))
+	}
+}
+
+type recordingEvaluator struct {
+	rule *recordingRule
+	opts ManagerOptions
+}
+
+func (e *recordingEvaluator) eval(ctx context.Context, t time.Time) error {
+	return e.rule.eval(ctx, t, e.opts.QueryFunc, e.opts.Appender, e.opts.TagOptions)
+}
+
+type alertingEvaluator struct {
+	rule *alertingRule
+	opts ManagerOptions
+}
+
+func (e *alertingEvaluator) eval(ctx context.Context, t time.Time) error {
+	return e.rule.eval(ctx, t, e.opts.QueryFunc, e.opts.TagOptions)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/instrument"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingNotifier struct {
+	sync.Mutex
+	alerts []Alert
+}
+
+func (n *recordingNotifier) Send(_ context.Context, alerts []Alert) error {
+	n.Lock()
+	defer n.Unlock()
+	n.alerts = append(n.alerts, alerts...)
+	return nil
+}
+
+func newTestManager(
+	t *testing.T,
+	query QueryFunc,
+	notifier Notifier,
+	nowFn clock.NowFn,
+) (*Manager, mock.Storage) {
+	store := mock.NewMockStorage()
+	manager, err := NewManager(ManagerOptions{
+		QueryFunc:          query,
+		Appender:           store,
+		Notifier:           notifier,
+		TagOptions:         models.NewTagOptions(),
+		EvaluationInterval: time.Hour,
+		NowFn:              nowFn,
+		InstrumentOptions:  instrument.NewOptions(),
+	})
+	require.NoError(t, err)
+	return manager, store
+}
+
+func TestManagerOptionsValidate(t *testing.T) {
+	_, err := NewManager(ManagerOptions{})
+	assert.Equal(t, errNoQueryFunc, err)
+}
+
+func TestGroupEvalAndNotify(t *testing.T) {
+	tagOpts := models.NewTagOptions()
+	query := &staticQueryFunc{results: map[string]Vector{
+		"sum(up)": {{Tags: models.NewTags(0, tagOpts), Value: 0}},
+		"up:sum == 0": {
+			{Tags: newTestTags(tagOpts, "__name__", "up:sum"), Value: 0},
+		},
+	}}
+	notifier := &recordingNotifier{}
+	now := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+	manager, store := newTestManager(t, query.query, notifier, func() time.Time {
+		return now
+	})
+
+	groups, err := ParseRuleGroups([]byte(`
+groups:
+  - name: up
+    rules:
+      - record: up:sum
+        expr: sum(up)
+      - alert: AllDown
+        expr: up:sum == 0
+`))
+	require.NoError(t, err)
+
+	g := newGroup(groups.Groups[0], manager)
+	assert.Equal(t, time.Hour, g.interval)
+
+	g.evalAndNotify(now)
+
+	require.Len(t, store.Writes(), 1)
+	name, ok := store.Writes()[0].Tags.Name()
+	require.True(t, ok)
+	assert.Equal(t, "up:sum", string(name))
+
+	require.Len(t, notifier.alerts, 1)
+	assert.Equal(t, StateFiring, notifier.alerts[0].State)
+	assert.Equal(t, "AllDown", notifier.alerts[0].Labels[alertNameLabel])
+}
+
+func TestManagerUpdateKeepsAlertState(t *testing.T) {
+	tagOpts := models.NewTagOptions()
+	query := &staticQueryFunc{results: map[string]Vector{
+		"up == 0": {{Tags: newTestTags(tagOpts, "instance", "a"), Value: 0}},
+	}}
+	manager, _ := newTestManager(t, query.query, nil, nil)
+
+	groups, err := ParseRuleGroups([]byte(`
+groups:
+  - name: up
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+`))
+	require.NoError(t, err)
+	require.NoError(t, manager.Update(groups))
+
+	manager.Lock()
+	manager.groups["up"].eval(context.Background(), manager.opts.NowFn())
+	manager.Unlock()
+	require.Len(t, manager.Alerts(), 1)
+
+	require.NoError(t, manager.Update(groups))
+	assert.Len(t, manager.Alerts(), 1)
+
+	require.NoError(t, manager.Close())
+	assert.Equal(t, errRulesManagerIsClosed, manager.Update(groups))
+}
+
+func TestManagerWatchKV(t *testing.T) {
+	var (
+		store     = mem.NewStore()
+		key       = "rules"
+		evalTime  = time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+		evaluated = make(chan string, 16)
+	)
+	_, err := store.Set(key, &commonpb.StringProto{Value: `
+groups:
+  - name: first
+    rules:
+      - record: a
+        expr: b
+`})
+	require.NoError(t, err)
+
+	// NB: the clock is a millisecond short of the next evaluation so each
+	// group is evaluated right after it starts.
+	query := func(_ context.Context, expr string, at time.Time) (Vector, error) {
+		if at.Equal(evalTime) {
+			evaluated <- expr
+		}
+		return nil, nil
+	}
+	manager, _ := newTestManager(t, query, nil, func() time.Time {
+		return evalTime.Add(-time.Millisecond)
+	})
+	defer manager.Close()
+
+	require.NoError(t, manager.watchKV(store, key, RuleGroups{}))
+	assert.Equal(t, "b", <-evaluated)
+
+	_, err = store.Set(key, &commonpb.StringProto{Value: `
+groups:
+  - name: second
+    rules:
+      - record: a
+        expr: c
+`})
+	require.NoError(t, err)
+
+	// NB: the first group may be evaluated again as the watch also fires for
+	// the value loaded at startup.
+	for expr := range evaluated {
+		if expr == "c" {
+			break
+		}
+		assert.Equal(t, "b", expr)
+	}
+
+	manager.Lock()
+	defer manager.Unlock()
+	require.Len(t, manager.groups, 1)
+	assert.NotNil(t, manager.groups["second"])
+}
+
+func TestConfigurationNewManagerKVRequiresStore(t *testing.T) {
+	cfg := Configuration{KV: &KVConfiguration{Key: "rules"}}
+	query := &staticQueryFunc{}
+	_, err := cfg.NewManager(query.query, mock.NewMockStorage(),
+		models.NewTagOptions(), nil, instrument.NewOptions())
+	assert.Equal(t, errNoKVStore, err)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	contentTypeJSON = "application/json"
+
+	// maxErrorBodyLen is the maximum length of an error response body
+	// included in a notification error.
+	maxErrorBodyLen = 1024
+)
+
+// Notifier sends alerts to an alert receiver.
+type Notifier interface {
+	// Send sends the alerts.
+	Send(ctx context.Context, alerts []Alert) error
+}
+
+// notifierAlert is the Alertmanager API representation of an alert.
+type notifierAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt,omitempty"`
+	EndsAt       time.Time         `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+type webhookNotifier struct {
+	url          string
+	generatorURL string
+	client       *http.Client
+	validFor     time.Duration
+}
+
+// NewWebhookNotifier returns a notifier which posts alerts to an
+// Alertmanager compatible webhook URL. Firing alerts are sent with an end
+// time validFor after the time they are sent, so that the receiver
+// resolves them if they stop being resent.
+func NewWebhookNotifier(
+	url string,
+	generatorURL string,
+	timeout time.Duration,
+	validFor time.Duration,
+) Notifier {
+	return &webhookNotifier{
+		url:          url,
+		generatorURL: generatorURL,
+		client:       &http.Client{Timeout: timeout},
+		validFor:     validFor,
+	}
+}
+
+func (n *webhookNotifier) Send(ctx context.Context, alerts []Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	payload := make([]notifierAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		a := notifierAlert{
+			Labels:       alert.Labels,
+			Annotations:  alert.Annotations,
+			StartsAt:     alert.FiredAt,
+			GeneratorURL: n.generatorURL,
+		}
+		if alert.State == StateInactive {
+			a.EndsAt = alert.ResolvedAt
+		} else {
+			a.EndsAt = now.Add(n.validFor)
+		}
+
+		payload = append(payload, a)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentTypeJSON)
+	resp, err := n.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLen))
+		return fmt.Errorf("alert notification failed with status %d: %s",
+			resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	// Drain the body so the connection can be reused.
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifierSend(t *testing.T) {
+	var received []notifierAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, contentTypeJSON, r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	var (
+		firedAt    = time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		resolvedAt = time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+		validFor   = 3 * time.Minute
+	)
+	notifier := NewWebhookNotifier(server.URL, "http://m3query", time.Second, validFor)
+	err := notifier.Send(context.Background(), []Alert{
+		{
+			State:       StateFiring,
+			Labels:      map[string]string{"alertname": "a"},
+			Annotations: map[string]string{"summary": "firing"},
+			FiredAt:     firedAt,
+		},
+		{
+			State:      StateInactive,
+			Labels:     map[string]string{"alertname": "b"},
+			FiredAt:    firedAt,
+			ResolvedAt: resolvedAt,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, map[string]string{"alertname": "a"}, received[0].Labels)
+	assert.Equal(t, map[string]string{"summary": "firing"}, received[0].Annotations)
+	assert.True(t, firedAt.Equal(received[0].StartsAt))
+	assert.True(t, received[0].EndsAt.After(time.Now()))
+	assert.Equal(t, "http://m3query", received[0].GeneratorURL)
+
+	assert.Equal(t, map[string]string{"alertname": "b"}, received[1].Labels)
+	assert.True(t, resolvedAt.Equal(received[1].EndsAt))
+}
+
+func TestWebhookNotifierSendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad alerts", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, "", time.Second, time.Minute)
+	err := notifier.Send(context.Background(), []Alert{{State: StateFiring}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad alerts")
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+)
+
+// Sample is a single series value of an instant query result.
+type Sample struct {
+	Tags  models.Tags
+	Value float64
+}
+
+// Vector is the result of an instant query.
+type Vector []Sample
+
+// QueryFunc evaluates an instant query at the given time.
+type QueryFunc func(ctx context.Context, query string, t time.Time) (Vector, error)
+
+// EngineQueryFunc returns a QueryFunc which evaluates instant queries
+// using the query engine.
+func EngineQueryFunc(
+	engine *executor.Engine,
+	tagOpts models.TagOptions,
+	timeout time.Duration,
+) QueryFunc {
+	return func(ctx context.Context, query string, t time.Time) (Vector, error) {
+		params := models.RequestParams{
+			Start:      t,
+			End:        t,
+			Now:        t,
+			Timeout:    timeout,
+			Step:       time.Second,
+			Query:      query,
+			IncludeEnd: true,
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		parser, err := promql.Parse(query, tagOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		// Results is closed by execute
+		results := make(chan executor.Query)
+		go engine.ExecuteExpr(ctx, parser, &executor.EngineOptions{}, params, results)
+
+		var (
+			samples    = make(map[string]Sample)
+			order      []string
+			processErr error
+		)
+		for result := range results {
+			if result.Err != nil {
+				processErr = result.Err
+				continue
+			}
+
+			for blkResult := range result.Result.ResultChan() {
+				if blkResult.Err != nil {
+					if processErr == nil {
+						processErr = blkResult.Err
+					}
+					continue
+				}
+
+				if processErr == nil {
+					order, processErr = addBlockSamples(blkResult.Block, samples, order)
+				}
+
+				blkResult.Block.Close()
+			}
+		}
+
+		if processErr != nil {
+			return nil, processErr
+		}
+
+		vector := make(Vector, 0, len(order))
+		for _, id := range order {
+			vector = append(vector, samples[id])
+		}
+
+		return vector, nil
+	}
+}
+
+// addBlockSamples adds the latest value of each series in the block to the
+// samples, blocks are not guaranteed to arrive in time order so the value
+// of the latest step seen for a series wins.
+func addBlockSamples(
+	b block.Block,
+	samples map[string]Sample,
+	order []string,
+) ([]string, error) {
+	iter, err := b.SeriesIter()
+	if err != nil {
+		return order, err
+	}
+
+	var (
+		meta       = iter.Meta()
+		seriesMeta = iter.SeriesMeta()
+		idx        = 0
+	)
+	for iter.Next() {
+		if idx >= len(seriesMeta) {
+			return order, fmt.Errorf("series index %d out of range of series meta", idx)
+		}
+
+		series := iter.Current()
+		value := math.NaN()
+		for i := series.Len() - 1; i >= 0; i-- {
+			if v := series.ValueAtStep(i); !math.IsNaN(v) {
+				value = v
+				break
+			}
+		}
+
+		if !math.IsNaN(value) {
+			tags := seriesMeta[idx].Tags.Clone().AddTags(meta.Tags.Tags)
+			id := string(tags.ID())
+			if _, ok := samples[id]; !ok {
+				order = append(order, id)
+			}
+
+			samples[id] = Sample{Tags: tags, Value: value}
+		}
+
+		idx++
+	}
+
+	return order, iter.Err()
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// recordingRule writes the result of its expression as new series named
+// after the rule.
+type recordingRule struct {
+	name   string
+	expr   string
+	labels map[string]string
+}
+
+func newRecordingRule(r Rule) *recordingRule {
+	return &recordingRule{
+		name:   r.Record,
+		expr:   r.Expr,
+		labels: r.Labels,
+	}
+}
+
+func (r *recordingRule) eval(
+	ctx context.Context,
+	t time.Time,
+	query QueryFunc,
+	appender storage.Appender,
+	tagOpts models.TagOptions,
+) error {
+	vector, err := query(ctx, r.expr, t)
+	if err != nil {
+		return err
+	}
+
+	var multiErr error
+	for _, sample := range vector {
+		tags := r.tags(sample.Tags, tagOpts)
+		if err := appender.Write(ctx, &storage.WriteQuery{
+			Tags: tags,
+			Datapoints: ts.Datapoints{
+				{
+					Timestamp: t,
+					Value:     sample.Value,
+				},
+			},
+			Unit: xtime.Millisecond,
+			Attributes: storage.Attributes{
+				MetricsType: storage.UnaggregatedMetricsType,
+			},
+		}); err != nil && multiErr == nil {
+			multiErr = err
+		}
+	}
+
+	return multiErr
+}
+
+// tags returns the tags of the recorded series, the metric name is replaced
+// by the rule name and the rule labels override any existing tags.
+func (r *recordingRule) tags(sampleTags models.Tags, tagOpts models.TagOptions) models.Tags {
+	tags := models.NewTags(sampleTags.Len()+len(r.labels)+1, tagOpts).
+		AddTags(sampleTags.Clone().Tags).
+		SetName([]byte(r.name))
+	for name, value := range r.labels {
+		tags = tags.AddOrUpdateTag(models.Tag{
+			Name:  []byte(name),
+			Value: []byte(value),
+		})
+	}
+
+	return tags
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingRuleEval(t *testing.T) {
+	tagOpts := models.NewTagOptions()
+	query := &staticQueryFunc{
+		results: map[string]Vector{
+			"sum(rate(http_requests_total[5m])) by (job)": {
+				{Tags: newTestTags(tagOpts, "job", "api"), Value: 1.5},
+				{Tags: newTestTags(tagOpts, "job", "web", "team", "web"), Value: 2},
+			},
+		},
+	}
+
+	rule := newRecordingRule(Rule{
+		Record: "job:http_requests:rate5m",
+		Expr:   "sum(rate(http_requests_total[5m])) by (job)",
+		Labels: map[string]string{"team": "infra"},
+	})
+
+	store := mock.NewMockStorage()
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, rule.eval(context.Background(), now, query.query, store, tagOpts))
+
+	writes := store.Writes()
+	require.Len(t, writes, 2)
+	for i, job := range []string{"api", "web"} {
+		write := writes[i]
+		name, ok := write.Tags.Name()
+		require.True(t, ok)
+		assert.Equal(t, "job:http_requests:rate5m", string(name))
+
+		value, ok := write.Tags.Get([]byte("job"))
+		require.True(t, ok)
+		assert.Equal(t, job, string(value))
+
+		value, ok = write.Tags.Get([]byte("team"))
+		require.True(t, ok)
+		assert.Equal(t, "infra", string(value))
+
+		require.Len(t, write.Datapoints, 1)
+		assert.Equal(t, now, write.Datapoints[0].Timestamp)
+		assert.Equal(t, storage.UnaggregatedMetricsType, write.Attributes.MetricsType)
+	}
+
+	assert.Equal(t, 1.5, writes[0].Datapoints[0].Value)
+	assert.Equal(t, 2.0, writes[1].Datapoints[0].Value)
+}
+
+func TestRecordingRuleEvalQueryError(t *testing.T) {
+	query := &staticQueryFunc{err: errors.New("query failed")}
+	rule := newRecordingRule(Rule{Record: "a", Expr: "b"})
+
+	store := mock.NewMockStorage()
+	err := rule.eval(context.Background(), time.Now(), query.query, store,
+		models.NewTagOptions())
+	require.Error(t, err)
+	assert.Len(t, store.Writes(), 0)
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+
+	"github.com/prometheus/common/model"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var (
+	errNoGroupName        = errors.New("rule group name must not be empty")
+	errNoRuleExpr         = errors.New("rule expression must not be empty")
+	errRecordAndAlert     = errors.New("rule must set exactly one of record or alert")
+	errNegativeInterval   = errors.New("rule group interval must not be negative")
+	errForOnRecordingRule = errors.New("recording rule must not set for")
+)
+
+// RuleGroups is a set of rule groups in the Prometheus rule file format.
+type RuleGroups struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a named group of rules evaluated in order on an interval.
+type RuleGroup struct {
+	// Name is the unique name of the group.
+	Name string `yaml:"name"`
+
+	// Interval is how often the rules of the group are evaluated, the
+	// default evaluation interval is used when not set.
+	Interval time.Duration `yaml:"interval"`
+
+	// Rules are the recording and alerting rules of the group.
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is either a recording rule, which records the result of its
+// expression as a new series, or an alerting rule, which fires an alert
+// for every series its expression returns.
+type Rule struct {
+	// Record is the metric name of the series a recording rule writes.
+	Record string `yaml:"record"`
+
+	// Alert is the name of the alert an alerting rule fires.
+	Alert string `yaml:"alert"`
+
+	// Expr is the PromQL expression of the rule.
+	Expr string `yaml:"expr"`
+
+	// For is how long an alert must be pending before it fires.
+	For time.Duration `yaml:"for"`
+
+	// Labels are added to, or override, the labels of the rule results.
+	Labels map[string]string `yaml:"labels"`
+
+	// Annotations are templated informational labels of an alert.
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// ParseRuleGroups parses and validates rule groups in the Prometheus
+// rule file format.
+func ParseRuleGroups(b []byte) (RuleGroups, error) {
+	var groups RuleGroups
+	if err := yaml.UnmarshalStrict(b, &groups); err != nil {
+		return RuleGroups{}, err
+	}
+
+	if err := groups.Validate(); err != nil {
+		return RuleGroups{}, err
+	}
+
+	return groups, nil
+}
+
+// ParseRuleGroupsFiles parses and validates the rule groups of each file,
+// returning the groups of all files combined.
+func ParseRuleGroupsFiles(paths []string) (RuleGroups, error) {
+	var result RuleGroups
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return RuleGroups{}, err
+		}
+
+		groups, err := ParseRuleGroups(b)
+		if err != nil {
+			return RuleGroups{}, fmt.Errorf("invalid rule file %s: %v", path, err)
+		}
+
+		result.Groups = append(result.Groups, groups.Groups...)
+	}
+
+	if err := result.Validate(); err != nil {
+		return RuleGroups{}, err
+	}
+
+	return result, nil
+}
+
+// Merge returns the groups of both sets combined.
+func (g RuleGroups) Merge(other RuleGroups) RuleGroups {
+	groups := make([]RuleGroup, 0, len(g.Groups)+len(other.Groups))
+	groups = append(groups, g.Groups...)
+	groups = append(groups, other.Groups...)
+	return RuleGroups{Groups: groups}
+}
+
+// Validate validates the rule groups.
+func (g RuleGroups) Validate() error {
+	names := make(map[string]struct{}, len(g.Groups))
+	for _, group := range g.Groups {
+		if group.Name == "" {
+			return errNoGroupName
+		}
+
+		if _, ok := names[group.Name]; ok {
+			return fmt.Errorf("duplicate rule group name: %s", group.Name)
+		}
+
+		names[group.Name] = struct{}{}
+		if group.Interval < 0 {
+			return errNegativeInterval
+		}
+
+		for i, rule := range group.Rules {
+			if err := rule.Validate(); err != nil {
+				return fmt.Errorf("invalid rule %d in group %s: %v", i, group.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the rule.
+func (r Rule) Validate() error {
+	if (r.Record == "") == (r.Alert == "") {
+		return errRecordAndAlert
+	}
+
+	if r.Expr == "" {
+		return errNoRuleExpr
+	}
+
+	if _, err := promql.Parse(r.Expr, models.NewTagOptions()); err != nil {
+		return fmt.Errorf("invalid expression %q: %v", r.Expr, err)
+	}
+
+	if r.Record != "" {
+		if !model.IsValidMetricName(model.LabelValue(r.Record)) {
+			return fmt.Errorf("invalid recording rule name: %s", r.Record)
+		}
+
+		if r.For != 0 {
+			return errForOnRecordingRule
+		}
+
+		if len(r.Annotations) > 0 {
+			return fmt.Errorf("recording rule %s must not set annotations", r.Record)
+		}
+	}
+
+	for name, value := range r.Labels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name: %s", name)
+		}
+
+		if err := validateTemplate(value); err != nil {
+			return fmt.Errorf("invalid template for label %s: %v", name, err)
+		}
+	}
+
+	for name, value := range r.Annotations {
+		if err := validateTemplate(value); err != nil {
+			return fmt.Errorf("invalid template for annotation %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRuleFile = `
+groups:
+  - name: example
+    interval: 30s
+    rules:
+      - record: job:http_requests:rate5m
+        expr: sum(rate(http_requests_total[5m])) by (job)
+        labels:
+          team: infra
+      - alert: HighErrorRate
+        expr: job:http_requests:rate5m > 0.5
+        for: 10m
+        labels:
+          severity: page
+        annotations:
+          summary: "High request rate for {{ $labels.job }}: {{ $value }}"
+`
+
+func TestParseRuleGroups(t *testing.T) {
+	groups, err := ParseRuleGroups([]byte(testRuleFile))
+	require.NoError(t, err)
+
+	expected := RuleGroups{
+		Groups: []RuleGroup{
+			{
+				Name:     "example",
+				Interval: 30 * time.Second,
+				Rules: []Rule{
+					{
+						Record: "job:http_requests:rate5m",
+						Expr:   "sum(rate(http_requests_total[5m])) by (job)",
+						Labels: map[string]string{"team": "infra"},
+					},
+					{
+						Alert:  "HighErrorRate",
+						Expr:   "job:http_requests:rate5m > 0.5",
+						For:    10 * time.Minute,
+						Labels: map[string]string{"severity": "page"},
+						Annotations: map[string]string{
+							"summary": "High request rate for {{ $labels.job }}: {{ $value }}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, groups)
+}
+
+func TestParseRuleGroupsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{
+			name: "unknown field",
+			in:   "groups:\n  - name: a\n    unknown: b\n",
+		},
+		{
+			name: "missing group name",
+			in:   "groups:\n  - rules:\n      - record: a\n        expr: b\n",
+		},
+		{
+			name: "duplicate group name",
+			in:   "groups:\n  - name: a\n  - name: a\n",
+		},
+		{
+			name: "record and alert",
+			in:   "groups:\n  - name: a\n    rules:\n      - record: a\n        alert: b\n        expr: c\n",
+		},
+		{
+			name: "missing expr",
+			in:   "groups:\n  - name: a\n    rules:\n      - record: a\n",
+		},
+		{
+			name: "invalid expr",
+			in:   "groups:\n  - name: a\n    rules:\n      - record: a\n        expr: sum(\n",
+		},
+		{
+			name: "invalid record name",
+			in:   "groups:\n  - name: a\n    rules:\n      - record: a-b\n        expr: c\n",
+		},
+		{
+			name: "for on recording rule",
+			in:   "groups:\n  - name: a\n    rules:\n      - record: a\n        expr: b\n        for: 1m\n",
+		},
+		{
+			name: "invalid template",
+			in: "groups:\n  - name: a\n    rules:\n      - alert: a\n        expr: b\n" +
+				"        annotations:\n          summary: \"{{ $labels.job \"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuleGroups([]byte(tt.in))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseRuleGroupsFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	first := filepath.Join(dir, "first.yml")
+	second := filepath.Join(dir, "second.yml")
+	require.NoError(t, ioutil.WriteFile(first, []byte(testRuleFile), 0644))
+	require.NoError(t, ioutil.WriteFile(second,
+		[]byte("groups:\n  - name: other\n    rules:\n      - record: a\n        expr: b\n"), 0644))
+
+	groups, err := ParseRuleGroupsFiles([]string{first, second})
+	require.NoError(t, err)
+	require.Len(t, groups.Groups, 2)
+	assert.Equal(t, "example", groups.Groups[0].Name)
+	assert.Equal(t, "other", groups.Groups[1].Name)
+
+	// Group names must be unique across files.
+	_, err = ParseRuleGroupsFiles([]string{first, first})
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"bytes"
+	"strconv"
+	"text/template"
+)
+
+// templateDefs makes the labels and value of a rule result available to
+// label and annotation templates as $labels and $value.
+const templateDefs = "{{$labels := .Labels}}{{$value := .Value}}"
+
+type templateData struct {
+	Labels map[string]string
+	Value  float64
+}
+
+var templateFuncs = template.FuncMap{
+	"humanize": func(v float64) string {
+		return strconv.FormatFloat(v, 'g', 4, 64)
+	},
+}
+
+func newTemplate(text string) (*template.Template, error) {
+	return template.New("").
+		Option("missingkey=zero").
+		Funcs(templateFuncs).
+		Parse(templateDefs + text)
+}
+
+func validateTemplate(text string) error {
+	_, err := newTemplate(text)
+	return err
+}
+
+// expandTemplate expands the template text with the labels and value of a
+// rule result.
+func expandTemplate(text string, labels map[string]string, value float64) (string, error) {
+	tmpl, err := newTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData{Labels: labels, Value: value}); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
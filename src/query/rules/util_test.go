@@ -0,0 +1,57 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+)
+
+func newTestTags(tagOpts models.TagOptions, nameValues ...string) models.Tags {
+	tags := models.NewTags(len(nameValues)/2, tagOpts)
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		tags = tags.AddTag(models.Tag{
+			Name:  []byte(nameValues[i]),
+			Value: []byte(nameValues[i+1]),
+		})
+	}
+
+	return tags
+}
+
+// staticQueryFunc returns a query func which returns the vector set for the
+// queried expression.
+type staticQueryFunc struct {
+	results map[string]Vector
+	err     error
+	queries []time.Time
+}
+
+func (q *staticQueryFunc) query(_ context.Context, query string, t time.Time) (Vector, error) {
+	q.queries = append(q.queries, t)
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return q.results[query], nil
+}
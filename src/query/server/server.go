@@ -34,6 +34,7 @@ import (
 
 	clusterclient "github.com/m3db/m3/src/cluster/client"
 	etcdclient "github.com/m3db/m3/src/cluster/client/etcd"
+	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
 	ingestcarbon "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/carbon"
@@ -42,16 +43,19 @@ import (
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/policy"
+	nsHandler "github.com/m3db/m3/src/query/api/v1/handler/namespace"
 	"github.com/m3db/m3/src/query/api/v1/httpd"
 	m3dbcluster "github.com/m3db/m3/src/query/cluster/m3db"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/policy/filter"
 	"github.com/m3db/m3/src/query/pools"
+	"github.com/m3db/m3/src/query/rules"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/fanout"
 	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/storage/remote"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 	"github.com/m3db/m3/src/query/stores/m3db"
 	tsdbRemote "github.com/m3db/m3/src/query/tsdb/remote"
 	"github.com/m3db/m3/src/query/util/logging"
@@ -176,6 +180,7 @@ func Run(runOpts RunOptions) {
 	defer buildReporter.Stop()
 	var (
 		backendStorage storage.Storage
+		querier        m3.Querier
 		clusterClient  clusterclient.Client
 		downsampler    downsample.Downsampler
 		enabled        bool
@@ -231,7 +236,7 @@ func Run(runOpts RunOptions) {
 		}
 
 		var cleanup cleanupFn
-		backendStorage, clusterClient, downsampler, cleanup, err = newM3DBStorage(
+		backendStorage, querier, clusterClient, downsampler, cleanup, err = newM3DBStorage(
 			runOpts,
 			cfg,
 			tagOptions,
@@ -253,7 +258,16 @@ func Run(runOpts RunOptions) {
 		logger.Fatal("unable to setup perQueryEnforcer", zap.Error(err))
 	}
 
-	engine := executor.NewEngine(backendStorage, scope.SubScope("engine"), *cfg.LookbackDuration, perQueryEnforcer)
+	var timeSharding *timeshard.Options
+	if cfg.TimeSharding != nil {
+		timeSharding, err = cfg.TimeSharding.NewOptions()
+		if err != nil {
+			logger.Fatal("unable to setup time sharding", zap.Error(err))
+		}
+	}
+
+	engine := executor.NewEngineWithTimeSharding(backendStorage, scope.SubScope("engine"),
+		*cfg.LookbackDuration, perQueryEnforcer, timeSharding)
 
 	downsamplerAndWriter, err := newDownsamplerAndWriter(backendStorage, downsampler)
 	if err != nil {
@@ -261,7 +275,7 @@ func Run(runOpts RunOptions) {
 	}
 
 	handler, err := httpd.NewHandler(downsamplerAndWriter, tagOptions, engine,
-		m3dbClusters, clusterClient, cfg, runOpts.DBConfig, perQueryEnforcer, scope)
+		querier, m3dbClusters, clusterClient, cfg, runOpts.DBConfig, perQueryEnforcer, scope)
 	if err != nil {
 		logger.Fatal("unable to set up handlers", zap.Error(err))
 	}
@@ -320,7 +334,13 @@ func Run(runOpts RunOptions) {
 
 	if cfg.Carbon != nil && cfg.Carbon.Ingester != nil {
 		startCarbonIngestion(
-			cfg.Carbon, instrumentOptions, logger, m3dbClusters, downsamplerAndWriter)
+			cfg.Carbon, instrumentOptions, logger, m3dbClusters, clusterClient, downsamplerAndWriter)
+	}
+
+	if cfg.Rules != nil {
+		rulesManager := startRules(cfg.Rules, engine, backendStorage,
+			tagOptions, clusterClient, instrumentOptions, logger)
+		defer rulesManager.Close()
 	}
 
 	var interruptCh <-chan error = make(chan error)
@@ -357,7 +377,7 @@ func newM3DBStorage(
 	instrumentOptions instrument.Options,
 	readWorkerPool xsync.PooledWorkerPool,
 	writeWorkerPool xsync.PooledWorkerPool,
-) (storage.Storage, clusterclient.Client, downsample.Downsampler, cleanupFn, error) {
+) (storage.Storage, m3.Querier, clusterclient.Client, downsample.Downsampler, cleanupFn, error) {
 	var (
 		clusterClient       clusterclient.Client
 		clusterClientWaitCh <-chan struct{}
@@ -390,12 +410,12 @@ func newM3DBStorage(
 			)
 			clusterClient, err = etcdclient.NewConfigServiceClient(clusterSvcClientOpts)
 			if err != nil {
-				return nil, nil, nil, nil, errors.Wrap(err, "unable to create cluster management etcd client")
+				return nil, nil, nil, nil, nil, errors.Wrap(err, "unable to create cluster management etcd client")
 			}
 		}
 	}
 
-	fanoutStorage, storageCleanup, err := newStorages(
+	fanoutStorage, querier, storageCleanup, err := newStorages(
 		logger,
 		clusters,
 		cfg,
@@ -405,7 +425,7 @@ func newM3DBStorage(
 		writeWorkerPool,
 	)
 	if err != nil {
-		return nil, nil, nil, nil, errors.Wrap(err, "unable to set up storages")
+		return nil, nil, nil, nil, nil, errors.Wrap(err, "unable to set up storages")
 	}
 
 	var (
@@ -417,7 +437,7 @@ func newM3DBStorage(
 			zap.Int("numAggregatedClusterNamespaces", n))
 		autoMappingRules, err := newDownsamplerAutoMappingRules(namespaces)
 		if err != nil {
-			return nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, err
 		}
 
 		newDownsamplerFn := func() (downsample.Downsampler, error) {
@@ -437,7 +457,7 @@ func newM3DBStorage(
 			// Otherwise we already have a client and can immediately construct the downsampler
 			downsampler, err = newDownsamplerFn()
 			if err != nil {
-				return nil, nil, nil, nil, err
+				return nil, nil, nil, nil, nil, err
 			}
 		}
 	}
@@ -458,7 +478,7 @@ func newM3DBStorage(
 		return lastErr
 	}
 
-	return fanoutStorage, clusterClient, downsampler, cleanup, nil
+	return fanoutStorage, querier, clusterClient, downsampler, cleanup, nil
 }
 
 func newDownsampler(
@@ -612,19 +632,19 @@ func newStorages(
 	poolWrapper *pools.PoolWrapper,
 	readWorkerPool xsync.PooledWorkerPool,
 	writeWorkerPool xsync.PooledWorkerPool,
-) (storage.Storage, cleanupFn, error) {
+) (storage.Storage, m3.Querier, cleanupFn, error) {
 	cleanup := func() error { return nil }
 
 	// Setup query conversion cache.
 	conversionCacheConfig := cfg.Cache.QueryConversionCacheConfiguration()
 	if err := conversionCacheConfig.Validate(); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	conversionCacheSize := conversionCacheConfig.SizeOrDefault()
 	conversionLRU, err := storage.NewQueryConversionLRU(conversionCacheSize)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	localStorage, err := m3.NewStorage(
@@ -636,7 +656,7 @@ func newStorages(
 		storage.NewQueryConversionCache(conversionLRU),
 	)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	stores := []storage.Storage{localStorage}
@@ -645,7 +665,7 @@ func newStorages(
 		logger.Info("rpc enabled")
 		server, err := startGrpcServer(logger, localStorage, poolWrapper, cfg.RPC)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		cleanup = func() error {
@@ -660,7 +680,7 @@ func newStorages(
 			readWorkerPool,
 		)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		if enabled {
@@ -712,8 +732,19 @@ func newStorages(
 		completeTagsFilter = filter.CompleteTagsAllowNone
 	}
 
+	// Compressed reads go straight to the local storage and bypass the fanout,
+	// so they are only exposed when the fanout would read locally anyway.
+	var querier m3.Querier
+	if !remoteEnabled {
+		switch cfg.Filter.Read {
+		case config.FilterRemoteOnly, config.FilterAllowNone:
+		default:
+			querier = localStorage
+		}
+	}
+
 	fanoutStorage := fanout.NewStorage(stores, readFilter, writeFilter, completeTagsFilter)
-	return fanoutStorage, cleanup, nil
+	return fanoutStorage, querier, cleanup, nil
 }
 
 func remoteClient(
@@ -772,6 +803,7 @@ func startCarbonIngestion(
 	iOpts instrument.Options,
 	logger *zap.Logger,
 	m3dbClusters m3.Clusters,
+	clusterClient clusterclient.Client,
 	downsamplerAndWriter ingest.DownsamplerAndWriter,
 ) {
 	ingesterCfg := cfg.Ingester
@@ -806,13 +838,35 @@ func startCarbonIngestion(
 		logger.Fatal("carbon ingestion is only supported when connecting to M3DB clusters directly")
 	}
 
-	// Validate provided rules.
+	// Load rules from graphite storage conf files if provided, they are applied
+	// after any rules from the config.
 	var (
 		clusterNamespaces = m3dbClusters.ClusterNamespaces()
-		rules             = ingestcarbon.CarbonIngesterRules{
-			Rules: ingesterCfg.RulesOrDefault(clusterNamespaces),
-		}
+		rules             ingestcarbon.CarbonIngesterRules
 	)
+	if ingesterCfg.StorageSchemasFile != "" {
+		importedRules, err := ingestcarbon.LoadStorageConfRules(
+			ingesterCfg.StorageSchemasFile, ingesterCfg.StorageAggregationFile)
+		if err != nil {
+			logger.Fatal("unable to import carbon ingestion rules from graphite storage conf files",
+				zap.String("storageSchemasFile", ingesterCfg.StorageSchemasFile),
+				zap.String("storageAggregationFile", ingesterCfg.StorageAggregationFile),
+				zap.Error(err))
+		}
+
+		logger.Info("imported carbon ingestion rules from graphite storage conf files",
+			zap.String("storageSchemasFile", ingesterCfg.StorageSchemasFile),
+			zap.String("storageAggregationFile", ingesterCfg.StorageAggregationFile),
+			zap.Int("numRules", len(importedRules)))
+		validateCarbonStorageConfNamespaces(importedRules, m3dbClusters, clusterClient, logger)
+
+		rules.Rules = append(rules.Rules, ingesterCfg.Rules...)
+		rules.Rules = append(rules.Rules, importedRules...)
+	} else {
+		rules.Rules = ingesterCfg.RulesOrDefault(clusterNamespaces)
+	}
+
+	// Validate provided rules.
 	for _, rule := range rules.Rules {
 		// Sort so we can detect duplicates.
 		sort.Slice(rule.Policies, func(i, j int) bool {
@@ -856,26 +910,33 @@ func startCarbonIngestion(
 		return
 	}
 
-	if len(ingesterCfg.Rules) == 0 {
+	if len(ingesterCfg.Rules) == 0 && ingesterCfg.StorageSchemasFile == "" {
 		logger.Info("no carbon ingestion rules were provided, all carbon metrics will be written to all aggregated M3DB namespaces")
 	}
 
-	// Create ingester.
-	ingester, err := ingestcarbon.NewIngester(
-		downsamplerAndWriter, rules, ingestcarbon.Options{
-			Debug:             ingesterCfg.Debug,
-			InstrumentOptions: carbonIOpts,
-			WorkerPool:        workerPool,
-		})
-	if err != nil {
-		logger.Fatal("unable to create carbon ingester", zap.Error(err))
+	// Create an ingester per protocol so that each is tagged in the metrics,
+	// they all share the same rules and worker pool.
+	newIngester := func(protocol ingestcarbon.Protocol) ingestcarbon.Ingester {
+		ingester, err := ingestcarbon.NewIngester(
+			downsamplerAndWriter, rules, ingestcarbon.Options{
+				Debug:             ingesterCfg.Debug,
+				InstrumentOptions: carbonIOpts,
+				WorkerPool:        workerPool,
+				Protocol:          protocol,
+			})
+		if err != nil {
+			logger.Fatal("unable to create carbon ingester",
+				zap.String("protocol", protocol.String()), zap.Error(err))
+		}
+		return ingester
 	}
 
-	// Start server.
+	// Start servers.
 	var (
 		serverOpts          = xserver.NewOptions().SetInstrumentOptions(carbonIOpts)
 		carbonListenAddress = ingesterCfg.ListenAddressOrDefault()
-		carbonServer        = xserver.NewServer(carbonListenAddress, ingester, serverOpts)
+		carbonServer        = xserver.NewServer(carbonListenAddress,
+			newIngester(ingestcarbon.PlaintextProtocol), serverOpts)
 	)
 	if strings.TrimSpace(carbonListenAddress) == "" {
 		logger.Fatal("no listen address specified for carbon ingester")
@@ -888,6 +949,90 @@ func startCarbonIngestion(
 			zap.String("listenAddress", carbonListenAddress), zap.Error(err))
 	}
 	logger.Info("started carbon ingestion server", zap.String("listenAddress", carbonListenAddress))
+
+	if pickleListenAddress := strings.TrimSpace(ingesterCfg.PickleListenAddress); pickleListenAddress != "" {
+		pickleServer := xserver.NewServer(pickleListenAddress,
+			newIngester(ingestcarbon.PickleProtocol), serverOpts)
+
+		logger.Info("starting carbon pickle ingestion server", zap.String("listenAddress", pickleListenAddress))
+		if err := pickleServer.ListenAndServe(); err != nil {
+			logger.Fatal("unable to start carbon pickle ingestion server at listen address",
+				zap.String("listenAddress", pickleListenAddress), zap.Error(err))
+		}
+		logger.Info("started carbon pickle ingestion server", zap.String("listenAddress", pickleListenAddress))
+	}
+
+	if udpListenAddress := strings.TrimSpace(ingesterCfg.UDPListenAddress); udpListenAddress != "" {
+		udpServer := ingestcarbon.NewUDPServer(udpListenAddress,
+			newIngester(ingestcarbon.UDPProtocol), carbonIOpts)
+
+		logger.Info("starting carbon UDP ingestion server", zap.String("listenAddress", udpListenAddress))
+		if err := udpServer.ListenAndServe(); err != nil {
+			logger.Fatal("unable to start carbon UDP ingestion server at listen address",
+				zap.String("listenAddress", udpListenAddress), zap.Error(err))
+		}
+		logger.Info("started carbon UDP ingestion server", zap.String("listenAddress", udpListenAddress))
+	}
+}
+
+// validateCarbonStorageConfNamespaces validates that each of the storage policies
+// required by the rules imported from graphite storage conf files has an aggregated
+// namespace in the cluster config that also exists in the namespace KV with at
+// least the required retention.
+func validateCarbonStorageConfNamespaces(
+	rules []config.CarbonIngesterRuleConfiguration,
+	m3dbClusters m3.Clusters,
+	clusterClient clusterclient.Client,
+	logger *zap.Logger,
+) {
+	if clusterClient == nil {
+		logger.Warn("no cluster client configured, skipping validation of the namespaces " +
+			"required by the imported carbon ingestion rules")
+		return
+	}
+
+	store, err := clusterClient.KV()
+	if err != nil {
+		logger.Fatal("unable to get KV store to validate carbon ingestion namespaces", zap.Error(err))
+	}
+
+	metadatas, _, err := nsHandler.Metadata(store)
+	if err != nil {
+		logger.Fatal("unable to get namespaces to validate carbon ingestion namespaces", zap.Error(err))
+	}
+
+	retentions := make(map[string]time.Duration, len(metadatas))
+	for _, md := range metadatas {
+		retentions[md.ID().String()] = md.Options().RetentionOptions().RetentionPeriod()
+	}
+
+	for _, policy := range ingestcarbon.RequiredStoragePolicies(rules) {
+		fields := []zap.Field{
+			zap.String("resolution", policy.Resolution.String()),
+			zap.String("retention", policy.Retention.String()),
+		}
+
+		ns, ok := m3dbClusters.AggregatedClusterNamespace(m3.RetentionResolution{
+			Resolution: policy.Resolution,
+			Retention:  policy.Retention,
+		})
+		if !ok {
+			logger.Fatal("no aggregated M3DB namespace configured for imported carbon ingestion rules", fields...)
+		}
+
+		id := ns.NamespaceID().String()
+		fields = append(fields, zap.String("namespace", id))
+		retention, ok := retentions[id]
+		if !ok {
+			logger.Fatal("aggregated M3DB namespace for imported carbon ingestion rules does not exist", fields...)
+		}
+		if retention < policy.Retention {
+			logger.Fatal("aggregated M3DB namespace for imported carbon ingestion rules has insufficient retention",
+				append(fields, zap.Duration("namespaceRetention", retention))...)
+		}
+
+		logger.Info("validated aggregated M3DB namespace for imported carbon ingestion rules", fields...)
+	}
 }
 
 func newDownsamplerAndWriter(storage storage.Storage, downsampler downsample.Downsampler) (ingest.DownsamplerAndWriter, error) {
@@ -905,3 +1050,38 @@ func newDownsamplerAndWriter(storage storage.Storage, downsampler downsample.Dow
 
 	return ingest.NewDownsamplerAndWriter(storage, downsampler, downAndWriteWorkerPool), nil
 }
+
+func startRules(
+	cfg *rules.Configuration,
+	engine *executor.Engine,
+	appender storage.Appender,
+	tagOptions models.TagOptions,
+	clusterClient clusterclient.Client,
+	iOpts instrument.Options,
+	logger *zap.Logger,
+) *rules.Manager {
+	logger.Info("rules evaluation enabled, configuring rules manager")
+
+	var store kv.Store
+	if cfg.KV != nil {
+		if clusterClient == nil {
+			logger.Fatal("loading rules from KV requires a cluster client")
+		}
+
+		var err error
+		store, err = clusterClient.KV()
+		if err != nil {
+			logger.Fatal("unable to get KV store for rules", zap.Error(err))
+		}
+	}
+
+	queryFn := rules.EngineQueryFunc(engine, tagOptions, cfg.QueryTimeoutOrDefault())
+	rulesIOpts := iOpts.SetMetricsScope(iOpts.MetricsScope().SubScope("rules"))
+	manager, err := cfg.NewManager(queryFn, appender, tagOptions, store, rulesIOpts)
+	if err != nil {
+		logger.Fatal("unable to start rules manager", zap.Error(err))
+	}
+
+	logger.Info("started rules manager")
+	return manager
+}
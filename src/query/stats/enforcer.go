@@ -0,0 +1,131 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package stats
+
+import (
+	"math"
+
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/x/cost"
+)
+
+type costStats struct {
+	fetched      float64
+	computed     float64
+	peak         float64
+	limit        float64
+	limitEnabled bool
+}
+
+// CostReport describes the datapoints accounted by the query enforcer.
+// Fetched datapoints are those decoded from storage, computed datapoints are
+// those materialized into blocks while processing the query, and the peak is
+// the highest number of datapoints held by the query at any time.
+type CostReport struct {
+	FetchedDatapoints  float64 `json:"fetchedDatapoints"`
+	ComputedDatapoints float64 `json:"computedDatapoints"`
+	PeakDatapoints     float64 `json:"peakDatapoints"`
+	Limit              float64 `json:"limit,omitempty"`
+	LimitEnabled       bool    `json:"limitEnabled"`
+}
+
+func (c costStats) report() CostReport {
+	r := CostReport{
+		FetchedDatapoints:  c.fetched,
+		ComputedDatapoints: c.computed,
+		PeakDatapoints:     c.peak,
+		LimitEnabled:       c.limitEnabled,
+	}
+
+	if c.limitEnabled && !math.IsInf(c.limit, 0) {
+		r.Limit = c.limit
+	}
+
+	return r
+}
+
+// WrapEnforcer returns a per query enforcer which records the cost it
+// accounts, including the cost accounted by its children, into the stats.
+// Since enforcers release their cost when closed, the totals are collected
+// as costs are added rather than read back once the query completes.
+func (s *QueryStats) WrapEnforcer(enforcer qcost.ChainedEnforcer) qcost.ChainedEnforcer {
+	if s == nil {
+		return enforcer
+	}
+
+	limit := enforcer.Limit()
+	s.Lock()
+	s.cost.limit = float64(limit.Threshold)
+	s.cost.limitEnabled = limit.Enabled
+	s.Unlock()
+
+	return &statsEnforcer{
+		ChainedEnforcer: enforcer,
+		stats:           s,
+	}
+}
+
+type statsEnforcer struct {
+	qcost.ChainedEnforcer
+
+	stats *QueryStats
+	// root is the per query enforcer, nil for the per query enforcer itself.
+	root *statsEnforcer
+}
+
+func (e *statsEnforcer) Add(c cost.Cost) cost.Report {
+	r := e.ChainedEnforcer.Add(c)
+	root := e
+	if e.root != nil {
+		root = e.root
+	}
+
+	current, _ := root.ChainedEnforcer.State()
+
+	e.stats.Lock()
+	if c > 0 {
+		if e.root == nil {
+			e.stats.cost.fetched += float64(c)
+		} else {
+			e.stats.cost.computed += float64(c)
+		}
+	}
+
+	if float64(current.Cost) > e.stats.cost.peak {
+		e.stats.cost.peak = float64(current.Cost)
+	}
+	e.stats.Unlock()
+
+	return r
+}
+
+func (e *statsEnforcer) Child(resourceName string) qcost.ChainedEnforcer {
+	root := e
+	if e.root != nil {
+		root = e.root
+	}
+
+	return &statsEnforcer{
+		ChainedEnforcer: e.ChainedEnforcer.Child(resourceName),
+		stats:           e.stats,
+		root:            root,
+	}
+}
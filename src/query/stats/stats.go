@@ -0,0 +1,345 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package stats collects the plan, timings and fetch statistics of a single
+// query execution so that they can be explained back to the user.
+package stats
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type statsKeyType int
+
+const statsKey statsKeyType = iota
+
+// NewContext returns a context carrying the given query stats.
+func NewContext(ctx context.Context, s *QueryStats) context.Context {
+	return context.WithValue(ctx, statsKey, s)
+}
+
+// FromContext returns the query stats carried by the context, or nil if the
+// query is not being explained. All QueryStats methods are safe to call on
+// a nil receiver.
+func FromContext(ctx context.Context) *QueryStats {
+	if ctx == nil {
+		return nil
+	}
+
+	s, _ := ctx.Value(statsKey).(*QueryStats)
+	return s
+}
+
+// QueryStats collects statistics for a single query execution. It is safe
+// for concurrent use.
+type QueryStats struct {
+	sync.Mutex
+
+	query        string
+	logicalPlan  string
+	physicalPlan string
+	nodes        map[string]*nodeStats
+	namespaces   map[string]*namespaceStats
+	cost         costStats
+	nowFn        func() time.Time
+	start        time.Time
+	end          time.Time
+}
+
+type nodeStats struct {
+	id       string
+	opType   string
+	calls    int
+	duration time.Duration
+	// children is the time spent in downstream nodes called synchronously
+	// from this node, used to compute the exclusive duration.
+	children time.Duration
+}
+
+type namespaceStats struct {
+	namespace     string
+	fetches       int
+	series        int
+	datapoints    int64
+	indexDuration time.Duration
+	fetchDuration time.Duration
+}
+
+// NewQueryStats returns a new query stats collector for the query.
+func NewQueryStats(query string) *QueryStats {
+	return newQueryStats(query, time.Now)
+}
+
+func newQueryStats(query string, nowFn func() time.Time) *QueryStats {
+	return &QueryStats{
+		query:      query,
+		nodes:      make(map[string]*nodeStats),
+		namespaces: make(map[string]*namespaceStats),
+		nowFn:      nowFn,
+		start:      nowFn(),
+	}
+}
+
+// SetPlans records the logical and physical plan of the query.
+func (s *QueryStats) SetPlans(logicalPlan, physicalPlan string) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	s.logicalPlan = logicalPlan
+	s.physicalPlan = physicalPlan
+	s.Unlock()
+}
+
+// RecordNode records a call into an execution node, called from the node
+// with parentID, which took the given inclusive duration.
+func (s *QueryStats) RecordNode(
+	id, opType, parentID string,
+	duration time.Duration,
+) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	node := s.node(id)
+	node.opType = opType
+	node.calls++
+	node.duration += duration
+	if parentID != "" && parentID != id {
+		s.node(parentID).children += duration
+	}
+	s.Unlock()
+}
+
+func (s *QueryStats) node(id string) *nodeStats {
+	node, ok := s.nodes[id]
+	if !ok {
+		node = &nodeStats{id: id}
+		s.nodes[id] = node
+	}
+
+	return node
+}
+
+// RecordIndexQuery records the time taken to resolve the series IDs matching
+// the query from the index of a namespace.
+func (s *QueryStats) RecordIndexQuery(namespace string, duration time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	s.namespace(namespace).indexDuration += duration
+	s.Unlock()
+}
+
+// RecordFetch records a fetch of series from a namespace, including both
+// the index query and the retrieval of the compressed series data.
+func (s *QueryStats) RecordFetch(
+	namespace string,
+	series int,
+	duration time.Duration,
+) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	ns := s.namespace(namespace)
+	ns.fetches++
+	ns.series += series
+	ns.fetchDuration += duration
+	s.Unlock()
+}
+
+// DatapointCounter returns a counter for datapoints decoded from a
+// namespace, which is cheap enough to be incremented on every datapoint.
+func (s *QueryStats) DatapointCounter(namespace string) *Counter {
+	if s == nil {
+		return nil
+	}
+
+	s.Lock()
+	counter := (*Counter)(&s.namespace(namespace).datapoints)
+	s.Unlock()
+	return counter
+}
+
+func (s *QueryStats) namespace(namespace string) *namespaceStats {
+	ns, ok := s.namespaces[namespace]
+	if !ok {
+		ns = &namespaceStats{namespace: namespace}
+		s.namespaces[namespace] = ns
+	}
+
+	return ns
+}
+
+// Finish marks the query as complete, fixing the total duration.
+func (s *QueryStats) Finish() {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	if s.end.IsZero() {
+		s.end = s.nowFn()
+	}
+	s.Unlock()
+}
+
+// Counter is a counter safe for concurrent use. A nil counter discards
+// any increments.
+type Counter int64
+
+// Inc increments the counter.
+func (c *Counter) Inc(n int64) {
+	if c == nil {
+		return
+	}
+
+	atomic.AddInt64((*int64)(c), n)
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() int64 {
+	if c == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64((*int64)(c))
+}
+
+// Report is the explanation of a query execution.
+type Report struct {
+	Query        string            `json:"query"`
+	LogicalPlan  string            `json:"logicalPlan"`
+	PhysicalPlan string            `json:"physicalPlan"`
+	DurationMs   float64           `json:"durationMs"`
+	Nodes        []NodeReport      `json:"nodes"`
+	Namespaces   []NamespaceReport `json:"namespaces"`
+	Cost         CostReport        `json:"cost"`
+}
+
+// NodeReport describes the time spent in an execution node. Durations are
+// inclusive of downstream nodes invoked synchronously by the node, while
+// the exclusive duration only accounts for the node itself. Work deferred
+// by lazy nodes is accounted to the node consuming their output.
+type NodeReport struct {
+	ID                  string  `json:"id"`
+	Type                string  `json:"type"`
+	Calls               int     `json:"calls"`
+	DurationMs          float64 `json:"durationMs"`
+	ExclusiveDurationMs float64 `json:"exclusiveDurationMs"`
+}
+
+// NamespaceReport describes the series and datapoints fetched from a
+// namespace. The fetch duration includes the index query, the index
+// duration measures the index query alone.
+type NamespaceReport struct {
+	Namespace       string  `json:"namespace"`
+	Fetches         int     `json:"fetches"`
+	Series          int     `json:"series"`
+	Datapoints      int64   `json:"datapoints"`
+	IndexDurationMs float64 `json:"indexDurationMs"`
+	FetchDurationMs float64 `json:"fetchDurationMs"`
+}
+
+// Report returns the explanation of the query as collected so far.
+func (s *QueryStats) Report() Report {
+	if s == nil {
+		return Report{}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	end := s.end
+	if end.IsZero() {
+		end = s.nowFn()
+	}
+
+	report := Report{
+		Query:        s.query,
+		LogicalPlan:  s.logicalPlan,
+		PhysicalPlan: s.physicalPlan,
+		DurationMs:   toMillis(end.Sub(s.start)),
+		Nodes:        make([]NodeReport, 0, len(s.nodes)),
+		Namespaces:   make([]NamespaceReport, 0, len(s.namespaces)),
+		Cost:         s.cost.report(),
+	}
+
+	for _, node := range s.nodes {
+		// Only report nodes that were executed, parents of the result node
+		// are registered through their children.
+		if node.calls == 0 {
+			continue
+		}
+
+		exclusive := node.duration - node.children
+		if exclusive < 0 {
+			exclusive = 0
+		}
+
+		report.Nodes = append(report.Nodes, NodeReport{
+			ID:                  node.id,
+			Type:                node.opType,
+			Calls:               node.calls,
+			DurationMs:          toMillis(node.duration),
+			ExclusiveDurationMs: toMillis(exclusive),
+		})
+	}
+
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		a, b := report.Nodes[i].ID, report.Nodes[j].ID
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+
+		return a < b
+	})
+
+	for _, ns := range s.namespaces {
+		report.Namespaces = append(report.Namespaces, NamespaceReport{
+			Namespace:       ns.namespace,
+			Fetches:         ns.fetches,
+			Series:          ns.series,
+			Datapoints:      atomic.LoadInt64(&ns.datapoints),
+			IndexDurationMs: toMillis(ns.indexDuration),
+			FetchDurationMs: toMillis(ns.fetchDuration),
+		})
+	}
+
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+	})
+
+	return report
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
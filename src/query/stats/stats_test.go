@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/x/cost"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStats() *QueryStats {
+	now := time.Unix(1000, 0)
+	return newQueryStats("up", func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+}
+
+func TestContext(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+
+	s := NewQueryStats("up")
+	assert.Equal(t, s, FromContext(NewContext(context.Background(), s)))
+}
+
+func TestNilStats(t *testing.T) {
+	var s *QueryStats
+	s.SetPlans("logical", "physical")
+	s.RecordNode("1", "sum", "0", time.Second)
+	s.RecordIndexQuery("ns", time.Second)
+	s.RecordFetch("ns", 1, time.Second)
+	s.DatapointCounter("ns").Inc(1)
+	s.Finish()
+	assert.Equal(t, Report{}, s.Report())
+
+	enforcer := qcost.NoopChainedEnforcer()
+	assert.Equal(t, enforcer, s.WrapEnforcer(enforcer))
+}
+
+func TestReport(t *testing.T) {
+	s := newTestStats()
+	s.SetPlans("logical", "physical")
+
+	s.RecordNode("1", "sum", "0", 3*time.Millisecond)
+	s.RecordNode("0", "fetch", "", 5*time.Millisecond)
+	s.RecordNode("10", "abs", "1", time.Millisecond)
+	s.RecordNode("1", "sum", "0", time.Millisecond)
+
+	s.RecordIndexQuery("unagg", 2*time.Millisecond)
+	s.RecordFetch("unagg", 3, 4*time.Millisecond)
+	s.RecordFetch("agg", 1, time.Millisecond)
+	counter := s.DatapointCounter("unagg")
+	counter.Inc(2)
+	counter.Inc(3)
+	s.Finish()
+
+	report := s.Report()
+	assert.Equal(t, "up", report.Query)
+	assert.Equal(t, "logical", report.LogicalPlan)
+	assert.Equal(t, "physical", report.PhysicalPlan)
+	assert.Equal(t, float64(1000), report.DurationMs)
+
+	assert.Equal(t, []NodeReport{
+		{ID: "0", Type: "fetch", Calls: 1, DurationMs: 5, ExclusiveDurationMs: 1},
+		{ID: "1", Type: "sum", Calls: 2, DurationMs: 4, ExclusiveDurationMs: 3},
+		{ID: "10", Type: "abs", Calls: 1, DurationMs: 1, ExclusiveDurationMs: 1},
+	}, report.Nodes)
+
+	assert.Equal(t, []NamespaceReport{
+		{Namespace: "agg", Fetches: 1, Series: 1, FetchDurationMs: 1},
+		{
+			Namespace:       "unagg",
+			Fetches:         1,
+			Series:          3,
+			Datapoints:      5,
+			IndexDurationMs: 2,
+			FetchDurationMs: 4,
+		},
+	}, report.Namespaces)
+}
+
+func TestWrapEnforcer(t *testing.T) {
+	global, err := qcost.NewChainedEnforcer(qcost.GlobalLevel, []cost.Enforcer{
+		cost.NewEnforcer(
+			cost.NewStaticLimitManager(cost.NewLimitManagerOptions()),
+			cost.NewTracker(),
+			nil,
+		),
+		cost.NewEnforcer(
+			cost.NewStaticLimitManager(cost.NewLimitManagerOptions().
+				SetDefaultLimit(cost.Limit{Threshold: 100, Enabled: true})),
+			cost.NewTracker(),
+			nil,
+		),
+		cost.NewEnforcer(
+			cost.NewStaticLimitManager(cost.NewLimitManagerOptions()),
+			cost.NewTracker(),
+			nil,
+		),
+	})
+	require.NoError(t, err)
+
+	s := newTestStats()
+	query := global.Child(qcost.QueryLevel)
+	enforcer := s.WrapEnforcer(query)
+
+	enforcer.Add(10)
+	child := enforcer.Child(qcost.BlockLevel)
+	child.Add(5)
+	child.Close()
+	enforcer.Add(2)
+	enforcer.Close()
+
+	assert.Equal(t, CostReport{
+		FetchedDatapoints:  12,
+		ComputedDatapoints: 5,
+		PeakDatapoints:     15,
+		Limit:              100,
+		LimitEnabled:       true,
+	}, s.Report().Cost)
+}
+
+func TestUsage(t *testing.T) {
+	var nilUsage *Usage
+	nilUsage.AddSeries(1)
+	assert.Equal(t, int64(0), nilUsage.Series())
+	assert.Equal(t, int64(0), nilUsage.Datapoints())
+	assert.Nil(t, UsageFromContext(context.Background()))
+
+	u := &Usage{}
+	ctx := NewUsageContext(context.Background(), u)
+	assert.Equal(t, u, UsageFromContext(ctx))
+
+	enforcer := WrapEnforcer(ctx, qcost.NoopChainedEnforcer())
+	enforcer.Add(10)
+	child := enforcer.Child(qcost.BlockLevel)
+	child.Add(5)
+	child.Close()
+	enforcer.Add(-4)
+	enforcer.Close()
+	u.AddSeries(2)
+
+	assert.Equal(t, int64(2), u.Series())
+	assert.Equal(t, int64(10), u.Datapoints())
+}
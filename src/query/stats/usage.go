@@ -0,0 +1,116 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package stats
+
+import (
+	"context"
+	"sync/atomic"
+
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/x/cost"
+)
+
+type usageKeyType int
+
+const usageKey usageKeyType = iota
+
+// NewUsageContext returns a context carrying the given usage.
+func NewUsageContext(ctx context.Context, u *Usage) context.Context {
+	return context.WithValue(ctx, usageKey, u)
+}
+
+// UsageFromContext returns the usage carried by the context, or nil if the
+// usage of the request is not being collected. All Usage methods are safe to
+// call on a nil receiver.
+func UsageFromContext(ctx context.Context) *Usage {
+	if ctx == nil {
+		return nil
+	}
+
+	u, _ := ctx.Value(usageKey).(*Usage)
+	return u
+}
+
+// Usage counts the series and datapoints fetched by the queries of a request.
+// Unlike the query stats which are only collected when a query is explained,
+// it is cheap enough to be collected for every request. It is safe for
+// concurrent use.
+type Usage struct {
+	series     int64
+	datapoints int64
+}
+
+// AddSeries adds fetched series.
+func (u *Usage) AddSeries(n int) {
+	if u == nil {
+		return
+	}
+
+	atomic.AddInt64(&u.series, int64(n))
+}
+
+// Series returns the number of series fetched.
+func (u *Usage) Series() int64 {
+	if u == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&u.series)
+}
+
+// Datapoints returns the number of datapoints fetched.
+func (u *Usage) Datapoints() int64 {
+	if u == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&u.datapoints)
+}
+
+// WrapEnforcer returns a per query enforcer which records the cost it
+// accounts into the usage and query stats carried by the context, if any.
+func WrapEnforcer(
+	ctx context.Context,
+	enforcer qcost.ChainedEnforcer,
+) qcost.ChainedEnforcer {
+	enforcer = FromContext(ctx).WrapEnforcer(enforcer)
+	if u := UsageFromContext(ctx); u != nil {
+		enforcer = &usageEnforcer{ChainedEnforcer: enforcer, usage: u}
+	}
+
+	return enforcer
+}
+
+// usageEnforcer counts the datapoints fetched by a query, which are those
+// accounted by the per query enforcer itself rather than its children.
+type usageEnforcer struct {
+	qcost.ChainedEnforcer
+
+	usage *Usage
+}
+
+func (e *usageEnforcer) Add(c cost.Cost) cost.Report {
+	if c > 0 {
+		atomic.AddInt64(&e.usage.datapoints, int64(c))
+	}
+
+	return e.ChainedEnforcer.Add(c)
+}
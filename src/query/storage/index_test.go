@@ -111,23 +111,45 @@ func TestFetchQueryToM3Query(t *testing.T) {
 		},
 		{
 			name:     "regexp match",
-			expected: "regexp(t1, v1)",
+			expected: "regexp(t1, v.*)",
 			matchers: models.Matchers{
 				{
 					Type:  models.MatchRegexp,
 					Name:  []byte("t1"),
-					Value: []byte("v1"),
+					Value: []byte("v.*"),
+				},
+			},
+		},
+		{
+			name:     "regexp match literal alternatives",
+			expected: "disjunction(term(t1, v1), term(t1, v2))",
+			matchers: models.Matchers{
+				{
+					Type:  models.MatchRegexp,
+					Name:  []byte("t1"),
+					Value: []byte("v(1|2)"),
 				},
 			},
 		},
 		{
 			name:     "regexp match negated",
-			expected: "negation(regexp(t1, v1))",
+			expected: "negation(regexp(t1, v.*))",
 			matchers: models.Matchers{
 				{
 					Type:  models.MatchNotRegexp,
 					Name:  []byte("t1"),
-					Value: []byte("v1"),
+					Value: []byte("v.*"),
+				},
+			},
+		},
+		{
+			name:     "regexp match negated literal alternatives",
+			expected: "negation(disjunction(term(t1, v1), term(t1, v2)))",
+			matchers: models.Matchers{
+				{
+					Type:  models.MatchNotRegexp,
+					Name:  []byte("t1"),
+					Value: []byte("v(1|2)"),
 				},
 			},
 		},
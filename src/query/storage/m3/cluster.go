@@ -44,6 +44,12 @@ var (
 	}
 )
 
+const (
+	// defaultClusterNamespaceBlockSize is the block size assumed for cluster
+	// namespaces which do not specify one, the default namespace block size.
+	defaultClusterNamespaceBlockSize = 2 * time.Hour
+)
+
 // Clusters is a flattened collection of local storage clusters and namespaces.
 type Clusters interface {
 	io.Closer
@@ -79,6 +85,7 @@ type ClusterNamespaceOptions struct {
 	// Note: Don't allow direct access, as we want to provide defaults
 	// and/or error if call to access a field is not relevant/correct.
 	attributes storage.Attributes
+	blockSize  time.Duration
 	downsample *ClusterNamespaceDownsampleOptions
 }
 
@@ -87,6 +94,14 @@ func (o ClusterNamespaceOptions) Attributes() storage.Attributes {
 	return o.attributes
 }
 
+// BlockSize returns the block size of the cluster namespace.
+func (o ClusterNamespaceOptions) BlockSize() time.Duration {
+	if o.blockSize <= 0 {
+		return defaultClusterNamespaceBlockSize
+	}
+	return o.blockSize
+}
+
 // DownsampleOptions returns the downsample options for a cluster namespace,
 // which is only valid if the namespace is an aggregated cluster namespace.
 func (o ClusterNamespaceOptions) DownsampleOptions() (
@@ -149,6 +164,7 @@ type UnaggregatedClusterNamespaceDefinition struct {
 	NamespaceID ident.ID
 	Session     client.Session
 	Retention   time.Duration
+	BlockSize   time.Duration
 }
 
 // Validate will validate the cluster namespace definition.
@@ -173,6 +189,7 @@ type AggregatedClusterNamespaceDefinition struct {
 	Session     client.Session
 	Retention   time.Duration
 	Resolution  time.Duration
+	BlockSize   time.Duration
 	Downsample  *ClusterNamespaceDownsampleOptions
 }
 
@@ -321,6 +338,7 @@ func newUnaggregatedClusterNamespace(
 				MetricsType: storage.UnaggregatedMetricsType,
 				Retention:   def.Retention,
 			},
+			blockSize: def.BlockSize,
 		},
 		session: def.Session,
 	}, nil
@@ -344,6 +362,7 @@ func newAggregatedClusterNamespace(
 				Retention:   def.Retention,
 				Resolution:  def.Resolution,
 			},
+			blockSize:  def.BlockSize,
 			downsample: def.Downsample,
 		},
 		session: def.Session,
@@ -72,12 +72,96 @@ func resolveUnaggregatedNamespaceForQuery(
 
 // resolveClusterNamespacesForQuery returns the namespaces that need to be
 // fanned out to depending on the query time and the namespaces configured.
+// If a max resolution is set, only the coarsest namespace that covers the
+// query range with a resolution no coarser than the max resolution and any
+// coarser namespaces are fanned out to.
 func resolveClusterNamespacesForQuery(
 	now time.Time,
 	clusters Clusters,
 	start time.Time,
 	end time.Time,
 	opts *storage.FanoutOptions,
+	maxResolution time.Duration,
+) (queryFanoutType, ClusterNamespaces, error) {
+	fanout, namespaces, err := resolveClusterNamespacesForRange(now, clusters,
+		start, end, opts)
+	if err != nil || maxResolution <= 0 {
+		return fanout, namespaces, err
+	}
+
+	if fanout == namespaceCoversAllQueryRange && len(namespaces) == 1 &&
+		namespaces[0] == clusters.UnaggregatedClusterNamespace() &&
+		opts.FanoutAggregated != storage.FanoutForceDisable {
+		// The unaggregated namespace short circuits resolution, prefer a
+		// complete aggregated namespace coarse enough to satisfy the query.
+		var r reusedAggregatedNamespaceSlices
+		r = aggregatedNamespaces(clusters.ClusterNamespaces(), r,
+			coversRangeFilter(now, start), opts)
+		if ns, ok := coarsestWithinResolution(now, start, r.completeAggregated,
+			maxResolution); ok {
+			return namespaceCoversAllQueryRange, ClusterNamespaces{ns}, nil
+		}
+		return fanout, namespaces, nil
+	}
+
+	chosen, ok := coarsestWithinResolution(now, start, namespaces, maxResolution)
+	if !ok {
+		return fanout, namespaces, nil
+	}
+
+	// NB: the chosen namespace covers the whole query range, so results are
+	// deduplicated by resolution rather than by retention.
+	chosenResolution := chosen.Options().Attributes().Resolution
+	result := make(ClusterNamespaces, 0, len(namespaces))
+	for _, n := range namespaces {
+		if n.Options().Attributes().Resolution >= chosenResolution {
+			result = append(result, n)
+		}
+	}
+	return namespaceCoversAllQueryRange, result, nil
+}
+
+// coarsestWithinResolution returns the namespace with the coarsest resolution
+// no coarser than the max resolution that covers the query range.
+func coarsestWithinResolution(
+	now time.Time,
+	start time.Time,
+	namespaces ClusterNamespaces,
+	maxResolution time.Duration,
+) (ClusterNamespace, bool) {
+	var (
+		covers = coversRangeFilter(now, start)
+		result ClusterNamespace
+		found  bool
+	)
+	for _, n := range namespaces {
+		resolution := n.Options().Attributes().Resolution
+		if resolution > maxResolution || !covers(n) {
+			continue
+		}
+		if !found || resolution > result.Options().Attributes().Resolution {
+			result = n
+			found = true
+		}
+	}
+	return result, found
+}
+
+// coversRangeFilter returns a filter that includes only namespaces whose
+// retention covers the query range.
+func coversRangeFilter(now, start time.Time) func(ClusterNamespace) bool {
+	return func(namespace ClusterNamespace) bool {
+		clusterStart := now.Add(-1 * namespace.Options().Attributes().Retention)
+		return !clusterStart.After(start)
+	}
+}
+
+func resolveClusterNamespacesForRange(
+	now time.Time,
+	clusters Clusters,
+	start time.Time,
+	end time.Time,
+	opts *storage.FanoutOptions,
 ) (queryFanoutType, ClusterNamespaces, error) {
 	// First check if the unaggregated cluster can fully satisfy the query range.
 	// If so, return it and shortcircuit, as unaggregated will necessarily have
@@ -104,15 +188,11 @@ func resolveClusterNamespacesForQuery(
 	//
 	// NB: if fanout aggregation is forced on, the filter instead forces clusters
 	// that do not cover the range to be set as partially aggregated.
-	coversRangeFilter := func(namespace ClusterNamespace) bool {
-		// Include only if can fulfill the entire time range of the query
-		clusterStart := now.Add(-1 * namespace.Options().Attributes().Retention)
-		return !clusterStart.After(start)
-	}
+	coversRange := coversRangeFilter(now, start)
 
 	// Filter aggregated namespaces by filter function and options.
 	var r reusedAggregatedNamespaceSlices
-	r = aggregatedNamespaces(clusters.ClusterNamespaces(), r, coversRangeFilter, opts)
+	r = aggregatedNamespaces(clusters.ClusterNamespaces(), r, coversRange, opts)
 
 	// If any of the aggregated clusters have a complete set of metrics, use
 	// those that have the smallest resolutions, supplemented by lower resolution
@@ -154,7 +234,7 @@ func resolveClusterNamespacesForQuery(
 		// If any namespace currently in contention does not cover the entire query
 		// range, set query fanout type to namespaceCoversPartialQueryRange.
 		for _, n := range result {
-			if !coversRangeFilter(n) {
+			if !coversRange(n) {
 				return namespaceCoversPartialQueryRange, result, nil
 			}
 		}
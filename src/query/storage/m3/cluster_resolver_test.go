@@ -64,7 +64,7 @@ func TestFanoutUnaggregatedDisableReturnsAggregatedNamespaces(t *testing.T) {
 	start := time.Now()
 	end := start.Add(time.Hour * 24 * -90)
 	_, clusters, err := resolveClusterNamespacesForQuery(start,
-		store.clusters, start, end, opts)
+		store.clusters, start, end, opts, 0)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(clusters))
 	assert.Equal(t, "metrics_aggregated_1m:30d", clusters[0].NamespaceID().String())
@@ -83,7 +83,7 @@ func TestFanoutUnaggregatedEnabledReturnsUnaggregatedNamespaces(t *testing.T) {
 	start := time.Now()
 	end := start.Add(time.Hour * 24 * -90)
 	_, clusters, err := resolveClusterNamespacesForQuery(start,
-		store.clusters, start, end, opts)
+		store.clusters, start, end, opts, 0)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(clusters))
 	assert.Equal(t, "metrics_unaggregated", clusters[0].NamespaceID().String())
@@ -104,7 +104,7 @@ func TestGraphitePath(t *testing.T) {
 	start := time.Now()
 	end := start.Add(time.Second * -30)
 	_, clusters, err := resolveClusterNamespacesForQuery(start,
-		store.clusters, start, end, opts)
+		store.clusters, start, end, opts, 0)
 	require.NoError(t, err)
 	require.Equal(t, 4, len(clusters))
 	expected := []string{"metrics_aggregated_1m:30d", "metrics_aggregated_5m:90d",
@@ -318,7 +318,7 @@ func TestResolveClusterNamespacesForQueryWithOptions(t *testing.T) {
 		}
 
 		fanoutType, clusters, err := resolveClusterNamespacesForQuery(now,
-			clusters, start, end, tt.opts)
+			clusters, start, end, tt.opts, 0)
 		if tt.expectedErr != nil {
 			assert.Equal(t, tt.expectedErr, err)
 			assert.Nil(t, clusters)
@@ -388,7 +388,7 @@ func TestLongUnaggregatedRetention(t *testing.T) {
 	}
 
 	fanoutType, ns, err := resolveClusterNamespacesForQuery(now,
-		clusters, start, end, opts)
+		clusters, start, end, opts, 0)
 
 	require.NoError(t, err)
 	actualNames := make([]string, len(ns))
@@ -436,7 +436,7 @@ func TestExampleCase(t *testing.T) {
 	for i := 27; i < 17520; i++ {
 		start := now.Add(time.Hour * -1 * time.Duration(i))
 		fanoutType, clusters, err := resolveClusterNamespacesForQuery(now,
-			ns, start, end, &storage.FanoutOptions{})
+			ns, start, end, &storage.FanoutOptions{}, 0)
 
 		require.NoError(t, err)
 		actualNames := make([]string, len(clusters))
@@ -451,3 +451,84 @@ func TestExampleCase(t *testing.T) {
 		assert.Equal(t, namespaceCoversPartialQueryRange, fanoutType)
 	}
 }
+
+func TestResolveClusterNamespacesForQueryMaxResolution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockSession(ctrl)
+	clusters, err := NewClusters(
+		UnaggregatedClusterNamespaceDefinition{
+			NamespaceID: ident.StringID("metrics_10s_48h"),
+			Retention:   48 * time.Hour,
+			Session:     session,
+		}, AggregatedClusterNamespaceDefinition{
+			NamespaceID: ident.StringID("metrics_1m_720h"),
+			Retention:   720 * time.Hour,
+			Resolution:  time.Minute,
+			Downsample:  &ClusterNamespaceDownsampleOptions{All: true},
+			Session:     session,
+		}, AggregatedClusterNamespaceDefinition{
+			NamespaceID: ident.StringID("metrics_10m_8760h"),
+			Retention:   8760 * time.Hour,
+			Resolution:  10 * time.Minute,
+			Downsample:  &ClusterNamespaceDownsampleOptions{All: true},
+			Session:     session,
+		}, AggregatedClusterNamespaceDefinition{
+			NamespaceID: ident.StringID("metrics_1h_8760h"),
+			Retention:   8760 * time.Hour,
+			Resolution:  time.Hour,
+			Downsample:  &ClusterNamespaceDownsampleOptions{All: false},
+			Session:     session,
+		},
+	)
+	require.NoError(t, err)
+
+	var (
+		now  = time.Now()
+		opts = &storage.FanoutOptions{
+			FanoutAggregatedOptimized: storage.FanoutForceDisable,
+			FanoutUnaggregated:        storage.FanoutForceDisable,
+		}
+		names = func(namespaces ClusterNamespaces) []string {
+			result := make([]string, 0, len(namespaces))
+			for _, n := range namespaces {
+				result = append(result, n.NamespaceID().String())
+			}
+			sort.Strings(result)
+			return result
+		}
+	)
+
+	// Without a max resolution every aggregated namespace is fanned out to.
+	start := now.Add(-24 * time.Hour)
+	_, namespaces, err := resolveClusterNamespacesForQuery(now, clusters,
+		start, now, opts, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metrics_10m_8760h", "metrics_1h_8760h",
+		"metrics_1m_720h"}, names(namespaces))
+
+	// The coarsest namespace satisfying the max resolution and any coarser
+	// namespaces are fanned out to.
+	fanoutType, namespaces, err := resolveClusterNamespacesForQuery(now, clusters,
+		start, now, opts, 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, namespaceCoversAllQueryRange, fanoutType)
+	assert.Equal(t, []string{"metrics_10m_8760h", "metrics_1h_8760h"}, names(namespaces))
+
+	// Namespaces that do not cover the range are never chosen.
+	start = now.Add(-1000 * time.Hour)
+	_, namespaces, err = resolveClusterNamespacesForQuery(now, clusters,
+		start, now, opts, 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metrics_10m_8760h", "metrics_1h_8760h",
+		"metrics_1m_720h"}, names(namespaces))
+
+	// A complete aggregated namespace is preferred over the unaggregated
+	// namespace when it satisfies the max resolution.
+	start = now.Add(-24 * time.Hour)
+	_, namespaces, err = resolveClusterNamespacesForQuery(now, clusters,
+		start, now, &storage.FanoutOptions{}, 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metrics_10m_8760h"}, names(namespaces))
+}
@@ -83,6 +83,10 @@ type ClusterStaticNamespaceConfiguration struct {
 	// Resolution is the frequency of which values are stored by the namespace.
 	Resolution time.Duration `yaml:"resolution" validate:"min=0"`
 
+	// BlockSize is the block size of the namespace, defaults to the default
+	// namespace block size of two hours.
+	BlockSize time.Duration `yaml:"blockSize" validate:"min=0"`
+
 	// Downsample is the configuration for downsampling options to use with
 	// the namespace.
 	Downsample *DownsampleClusterStaticNamespaceConfiguration `yaml:"downsample"`
@@ -281,6 +285,7 @@ func (c ClustersStaticConfiguration) NewClusters(
 		NamespaceID: ident.StringID(unaggregatedClusterNamespaceCfg.namespace.Namespace),
 		Session:     unaggregatedClusterNamespaceCfg.result.session,
 		Retention:   unaggregatedClusterNamespaceCfg.namespace.Retention,
+		BlockSize:   unaggregatedClusterNamespaceCfg.namespace.BlockSize,
 	}
 
 	for i, cfg := range aggregatedClusterNamespacesCfgs {
@@ -301,6 +306,7 @@ func (c ClustersStaticConfiguration) NewClusters(
 				Session:     cfg.result.session,
 				Retention:   n.Retention,
 				Resolution:  n.Resolution,
+				BlockSize:   n.BlockSize,
 				Downsample:  &downsampleOpts,
 			}
 			aggregatedClusterNamespaces = append(aggregatedClusterNamespaces, def)
@@ -23,6 +23,7 @@ package m3
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/query/storage"
@@ -38,6 +39,7 @@ type multiResult struct {
 	finalResult    encoding.MutableSeriesIterators
 	dedupeMap      map[string]multiResultSeries
 	err            xerrors.MultiError
+	maxResolution  time.Duration
 
 	pools encoding.IteratorPools
 }
@@ -45,10 +47,12 @@ type multiResult struct {
 func newMultiFetchResult(
 	fanout queryFanoutType,
 	pools encoding.IteratorPools,
+	maxResolution time.Duration,
 ) MultiFetchResult {
 	return &multiResult{
-		fanout: fanout,
-		pools:  pools,
+		fanout:        fanout,
+		pools:         pools,
+		maxResolution: maxResolution,
 	}
 }
 
@@ -206,14 +210,16 @@ func (r *multiResult) addOrUpdateDedupeMap(
 		switch r.fanout {
 		case namespaceCoversAllQueryRange:
 			// Already exists and resolution of result we are adding is not as precise
-			existsBetter = existing.attrs.Resolution <= attrs.Resolution
+			existsBetter = r.equalOrBetterResolution(existing.attrs.Resolution,
+				attrs.Resolution)
 		case namespaceCoversPartialQueryRange:
 			// Already exists and either has longer retention, or the same retention
 			// and result we are adding is not as precise
 			existsLongerRetention := existing.attrs.Retention > attrs.Retention
 			existsSameRetentionEqualOrBetterResolution :=
 				existing.attrs.Retention == attrs.Retention &&
-					existing.attrs.Resolution <= attrs.Resolution
+					r.equalOrBetterResolution(existing.attrs.Resolution,
+						attrs.Resolution)
 			existsBetter = existsLongerRetention || existsSameRetentionEqualOrBetterResolution
 		default:
 			r.err = r.err.Add(fmt.Errorf("unknown query fanout type: %d", r.fanout))
@@ -231,3 +237,26 @@ func (r *multiResult) addOrUpdateDedupeMap(
 		}
 	}
 }
+
+// equalOrBetterResolution returns whether the existing resolution is at
+// least as good as the added resolution. The most granular resolution is
+// best, unless a max resolution is set in which case the coarsest resolution
+// no coarser than the max resolution is best.
+func (r *multiResult) equalOrBetterResolution(
+	existing time.Duration,
+	added time.Duration,
+) bool {
+	if r.maxResolution > 0 {
+		existingSatisfies := existing <= r.maxResolution
+		addedSatisfies := added <= r.maxResolution
+		if existingSatisfies && addedSatisfies {
+			return existing >= added
+		}
+
+		if existingSatisfies != addedSatisfies {
+			return existingSatisfies
+		}
+	}
+
+	return existing <= added
+}
@@ -78,11 +78,25 @@ func generateIteratorPools(ctrl *gomock.Controller) encoding.IteratorPools {
 }
 
 func TestMultiResult(t *testing.T) {
-	testMultiResult(t, namespaceCoversPartialQueryRange, long)
-	testMultiResult(t, namespaceCoversAllQueryRange, unaggregated)
+	testMultiResult(t, namespaceCoversPartialQueryRange, 0, long)
+	testMultiResult(t, namespaceCoversAllQueryRange, 0, unaggregated)
 }
 
-func testMultiResult(t *testing.T, fanoutType queryFanoutType, expected string) {
+func TestMultiResultMaxResolution(t *testing.T) {
+	// Prefer the coarsest resolution no coarser than the max resolution.
+	testMultiResult(t, namespaceCoversAllQueryRange, 5*time.Minute, short)
+	testMultiResult(t, namespaceCoversAllQueryRange, time.Hour, long)
+	testMultiResult(t, namespaceCoversAllQueryRange, time.Minute, unaggregated)
+	// Longer retention still wins for partial ranges.
+	testMultiResult(t, namespaceCoversPartialQueryRange, 5*time.Minute, long)
+}
+
+func testMultiResult(
+	t *testing.T,
+	fanoutType queryFanoutType,
+	maxResolution time.Duration,
+	expected string,
+) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -117,7 +131,7 @@ func testMultiResult(t *testing.T, fanoutType queryFanoutType, expected string)
 	}
 
 	pools := generateIteratorPools(ctrl)
-	r := newMultiFetchResult(fanoutType, pools)
+	r := newMultiFetchResult(fanoutType, pools, maxResolution)
 
 	for _, ns := range namespaces {
 		iters := generateSeriesIterators(ctrl, ns.ns)
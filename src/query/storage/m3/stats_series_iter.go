@@ -0,0 +1,66 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/query/stats"
+)
+
+// statsSeriesIter wraps a series iterator to count the datapoints decoded
+// from each namespace when a query is being explained.
+type statsSeriesIter struct {
+	encoding.SeriesIterator
+
+	datapoints *stats.Counter
+}
+
+// wrapStatsSeriesIters wraps the series iterators in place to record their
+// decoded datapoints into the query stats, if present.
+func wrapStatsSeriesIters(
+	queryStats *stats.QueryStats,
+	iters []encoding.SeriesIterator,
+) {
+	if queryStats == nil {
+		return
+	}
+
+	for i, iter := range iters {
+		var namespace string
+		if ns := iter.Namespace(); ns != nil {
+			namespace = ns.String()
+		}
+
+		iters[i] = &statsSeriesIter{
+			SeriesIterator: iter,
+			datapoints:     queryStats.DatapointCounter(namespace),
+		}
+	}
+}
+
+func (it *statsSeriesIter) Next() bool {
+	if !it.SeriesIterator.Next() {
+		return false
+	}
+
+	it.datapoints.Inc(1)
+	return true
+}
@@ -33,7 +33,9 @@ import (
 	"github.com/m3db/m3/src/query/cost"
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/stats"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/ts/m3db"
 	"github.com/m3db/m3/src/query/ts/m3db/consolidators"
@@ -112,6 +114,7 @@ func (s *m3storage) Fetch(
 		enforcer = cost.NoopChainedEnforcer()
 	}
 
+	wrapStatsSeriesIters(stats.FromContext(ctx), iters.Iters())
 	fetchResult, err := storage.SeriesIteratorsToFetchResult(
 		iters,
 		s.readWorkerPool,
@@ -157,7 +160,7 @@ func (s *m3storage) FetchBlocks(
 			SetSplitSeriesByBlock(true)
 	}
 
-	raw, _, err := s.FetchCompressed(ctx, query, options)
+	raw, err := s.fetchBlocksCompressed(ctx, query, options)
 	if err != nil {
 		return block.Result{}, err
 	}
@@ -182,6 +185,8 @@ func (s *m3storage) FetchBlocks(
 		iters[i] = NewAccountedSeriesIter(iter, enforcer, options.Scope)
 	}
 
+	wrapStatsSeriesIters(stats.FromContext(ctx), iters)
+
 	blocks, err := m3db.ConvertM3DBSeriesIterators(
 		raw,
 		bounds,
@@ -216,24 +221,124 @@ func (s *m3storage) FetchCompressed(
 	return iters, accumulator.Close, nil
 }
 
+// fetches compressed series for blocks, splitting the fetch into time shards
+// if enabled. The series remain encoded, and are owned by the blocks.
+func (s *m3storage) fetchBlocksCompressed(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (encoding.SeriesIterators, error) {
+	if options.TimeSharding == nil {
+		raw, _, err := s.FetchCompressed(ctx, query, options)
+		return raw, err
+	}
+
+	// NB: namespaces are resolved once for the whole range so that every
+	// shard is fetched from the same namespaces and shards can be aligned
+	// to their blocks.
+	fanout, namespaces, err := s.resolveNamespaces(query, options)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := options.TimeSharding.Shards(query.Start, query.End,
+		namespacesBlockSize(namespaces))
+	if len(shards) == 0 {
+		accumulator, err := s.fetchCompressedFromNamespaces(ctx, query,
+			options, fanout, namespaces)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := accumulator.FinalResult()
+		if err != nil {
+			accumulator.Close()
+			return nil, err
+		}
+
+		return raw, nil
+	}
+
+	var (
+		accumulators = make([]MultiFetchResult, len(shards))
+		results      = make([]encoding.SeriesIterators, len(shards))
+		errs         = make([]error, len(shards))
+		wg           sync.WaitGroup
+	)
+	for i, shard := range shards {
+		i, shardQuery := i, *query
+		shardQuery.Start, shardQuery.End = shard.Start, shard.End
+		wg.Add(1)
+		s.readWorkerPool.Go(func() {
+			defer wg.Done()
+			accumulators[i], errs[i] = s.fetchCompressedFromNamespaces(ctx,
+				&shardQuery, options, fanout, namespaces)
+			if errs[i] == nil {
+				results[i], errs[i] = accumulators[i].FinalResult()
+			}
+		})
+	}
+
+	wg.Wait()
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		for _, accumulator := range accumulators {
+			if accumulator != nil {
+				accumulator.Close()
+			}
+		}
+
+		return nil, err
+	}
+
+	return timeshard.MergeShards(results), nil
+}
+
+// namespacesBlockSize returns the smallest block size which is a multiple of
+// the block sizes of all the namespaces, so that time shards aligned to it
+// do not split the blocks of any of them.
+func namespacesBlockSize(namespaces ClusterNamespaces) time.Duration {
+	var blockSize time.Duration
+	for _, namespace := range namespaces {
+		nsBlockSize := namespace.Options().BlockSize()
+		if blockSize == 0 {
+			blockSize = nsBlockSize
+			continue
+		}
+
+		a, b := blockSize, nsBlockSize
+		for b != 0 {
+			a, b = b, a%b
+		}
+		blockSize = blockSize / a * nsBlockSize
+	}
+
+	return blockSize
+}
+
 // fetches compressed series, returning a MultiFetchResult accumulator
 func (s *m3storage) fetchCompressed(
 	ctx context.Context,
 	query *storage.FetchQuery,
 	options *storage.FetchOptions,
 ) (MultiFetchResult, error) {
-	// Check if the query was interrupted.
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-	}
-
-	m3query, err := storage.FetchQueryToM3Query(query, s.conversionCache)
+	fanout, namespaces, err := s.resolveNamespaces(query, options)
 	if err != nil {
 		return nil, err
 	}
 
+	return s.fetchCompressedFromNamespaces(ctx, query, options, fanout,
+		namespaces)
+}
+
+// resolves the cluster namespaces to fetch the query from
+func (s *m3storage) resolveNamespaces(
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (queryFanoutType, ClusterNamespaces, error) {
 	// NB(r): Since we don't use a single index we fan out to each
 	// cluster that can completely fulfill this range and then prefer the
 	// highest resolution (most fine grained) results.
@@ -244,8 +349,36 @@ func (s *m3storage) fetchCompressed(
 		query.Start,
 		query.End,
 		options.FanoutOptions,
+		options.MaxResolution,
 	)
+	if err != nil {
+		return namespaceInvalid, nil, err
+	}
+
+	if len(namespaces) == 0 {
+		return namespaceInvalid, nil, errNoNamespacesConfigured
+	}
 
+	return fanout, namespaces, nil
+}
+
+// fetches compressed series from the given namespaces, returning a
+// MultiFetchResult accumulator
+func (s *m3storage) fetchCompressedFromNamespaces(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+	fanout queryFanoutType,
+	namespaces ClusterNamespaces,
+) (MultiFetchResult, error) {
+	// Check if the query was interrupted.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	m3query, err := storage.FetchQueryToM3Query(query, s.conversionCache)
 	if err != nil {
 		return nil, err
 	}
@@ -254,16 +387,16 @@ func (s *m3storage) fetchCompressed(
 		opts = storage.FetchOptionsToM3Options(options, query)
 		wg   sync.WaitGroup
 	)
-	if len(namespaces) == 0 {
-		return nil, errNoNamespacesConfigured
-	}
-
 	pools, err := namespaces[0].Session().IteratorPools()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve iterator pools: %v", err)
 	}
 
-	result := newMultiFetchResult(fanout, pools)
+	result := newMultiFetchResult(fanout, pools, options.MaxResolution)
+	var (
+		queryStats = stats.FromContext(ctx)
+		usage      = stats.UsageFromContext(ctx)
+	)
 	for _, namespace := range namespaces {
 		namespace := namespace // Capture var)
 
@@ -271,7 +404,29 @@ func (s *m3storage) fetchCompressed(
 		go func() {
 			session := namespace.Session()
 			ns := namespace.NamespaceID()
+			if queryStats != nil {
+				// NB: FetchTagged does not expose the time spent querying the
+				// index, so when explaining a query the index is queried on its
+				// own first to measure it separately.
+				start := time.Now()
+				idsIter, _, err := session.FetchTaggedIDs(ns, m3query, opts)
+				if err == nil {
+					idsIter.Finalize()
+				}
+
+				queryStats.RecordIndexQuery(ns.String(), time.Since(start))
+			}
+
+			start := time.Now()
 			iters, _, err := session.FetchTagged(ns, m3query, opts)
+			var series int
+			if iters != nil {
+				series = iters.Len()
+			}
+
+			usage.AddSeries(series)
+			queryStats.RecordFetch(ns.String(), series, time.Since(start))
+
 			// Ignore error from getting iterator pools, since operation
 			// will not be dramatically impacted if pools is nil
 			result.Add(namespace.Options().Attributes(), iters, err)
@@ -30,8 +30,10 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 	"github.com/m3db/m3/src/query/test/seriesiter"
 	"github.com/m3db/m3/src/query/ts"
 	bytetest "github.com/m3db/m3/src/x/test"
@@ -119,13 +121,16 @@ func setup(
 }
 
 func newTestStorage(t *testing.T, clusters Clusters) storage.Storage {
+	readPool, err := sync.NewPooledWorkerPool(10, sync.NewPooledWorkerPoolOptions())
+	require.NoError(t, err)
+	readPool.Init()
 	writePool, err := sync.NewPooledWorkerPool(10, sync.NewPooledWorkerPoolOptions())
 	require.NoError(t, err)
 	writePool.Init()
 	opts := models.NewTagOptions().SetMetricName([]byte("name"))
 	queryCache, err := storage.NewQueryConversionLRU(100)
 	require.NoError(t, err)
-	storage, err := NewStorage(clusters, nil, writePool, opts, time.Minute, storage.NewQueryConversionCache(queryCache))
+	storage, err := NewStorage(clusters, readPool, writePool, opts, time.Minute, storage.NewQueryConversionCache(queryCache))
 	require.NoError(t, err)
 	return storage
 }
@@ -300,6 +305,74 @@ func buildFetchOpts() *storage.FetchOptions {
 	return opts
 }
 
+func TestLocalFetchBlocksCompressedTimeSharded(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{T: t})
+	defer ctrl.Finish()
+
+	session := client.NewMockSession(ctrl)
+	clusters, err := NewClusters(UnaggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("metrics_unaggregated"),
+		Session:     session,
+		Retention:   test1MonthRetention,
+		BlockSize:   4 * time.Hour,
+	})
+	require.NoError(t, err)
+	store := newTestStorage(t, clusters).(*m3storage)
+
+	starts := make(chan time.Time, 4)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ ident.ID, _ index.Query, opts index.QueryOptions) (encoding.SeriesIterators, bool, error) {
+			starts <- opts.StartInclusive
+			return encoding.EmptySeriesIterators, true, nil
+		}).Times(3)
+	session.EXPECT().IteratorPools().
+		Return(newTestIteratorPools(ctrl), nil).AnyTimes()
+
+	// Shards are sized by the namespace block size rather than a fixed one
+	end := time.Now().Truncate(24 * time.Hour)
+	query := newFetchReq()
+	query.Start, query.End = end.Add(-24*time.Hour), end
+	opts := buildFetchOpts()
+	opts.TimeSharding = &timeshard.Options{BlocksPerShard: 2}
+
+	iters, err := store.fetchBlocksCompressed(context.TODO(), query, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 0, iters.Len())
+
+	close(starts)
+	seen := make(map[time.Time]struct{})
+	for start := range starts {
+		seen[start] = struct{}{}
+	}
+	assert.Equal(t, map[time.Time]struct{}{
+		query.Start:                     {},
+		query.Start.Add(8 * time.Hour):  {},
+		query.Start.Add(16 * time.Hour): {},
+	}, seen)
+}
+
+func TestNamespacesBlockSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clusters, err := NewClusters(UnaggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("metrics_unaggregated"),
+		Session:     client.NewMockSession(ctrl),
+		Retention:   test1MonthRetention,
+	}, AggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("metrics_aggregated_1m:90d"),
+		Session:     client.NewMockSession(ctrl),
+		Retention:   test3MonthRetention,
+		Resolution:  time.Minute,
+		BlockSize:   3 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	namespaces := clusters.ClusterNamespaces()
+	assert.Equal(t, 2*time.Hour, namespacesBlockSize(namespaces[:1]))
+	assert.Equal(t, 6*time.Hour, namespacesBlockSize(namespaces))
+}
+
 func TestLocalReadExceedsUnaggregatedRetentionWithinAggregatedRetention(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,212 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"bytes"
+
+	"github.com/m3db/m3/src/m3ninx/idx"
+)
+
+// maxRegexpLiterals is the maximum number of literal values a regexp is
+// expanded to before it is evaluated as a regexp instead.
+const maxRegexpLiterals = 1024
+
+var regexpMetaChars = []byte(`\.+*?()|[]{}^$`)
+
+// regexpQuery returns an index query for a regexp matcher. Regexps which
+// only match a small set of literal values, such as the alternations and
+// character ranges generated for graphite globs like `foo.{bar,baz}[12]`,
+// are looked up as a disjunction of terms rather than matched against every
+// term of the field.
+func regexpQuery(name, pattern []byte) (idx.Query, error) {
+	literals, ok := regexpLiterals(pattern)
+	if !ok {
+		return idx.NewRegexpQuery(name, pattern)
+	}
+
+	if len(literals) == 1 {
+		return idx.NewTermQuery(name, literals[0]), nil
+	}
+
+	queries := make([]idx.Query, 0, len(literals))
+	for _, literal := range literals {
+		queries = append(queries, idx.NewTermQuery(name, literal))
+	}
+
+	return idx.NewDisjunctionQuery(queries...), nil
+}
+
+// regexpLiterals returns every value matched by the fully anchored regexp
+// if it is composed only of literals, non nested groups of literal
+// alternatives and character classes, e.g. `a(b|c)[1-2]` expands to
+// [ab1 ab2 ac1 ac2]. It returns false for any other regexp, or if the regexp
+// matches either the empty value or more than maxRegexpLiterals values.
+func regexpLiterals(pattern []byte) ([][]byte, bool) {
+	if len(pattern) == 0 {
+		return nil, false
+	}
+
+	var (
+		results [][]byte
+		depth   int
+		start   int
+	)
+
+	for i := 0; i <= len(pattern); i++ {
+		if i < len(pattern) {
+			switch pattern[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+
+			if pattern[i] != '|' || depth != 0 {
+				continue
+			}
+		}
+
+		literals, ok := branchLiterals(pattern[start:i])
+		if !ok || len(results)+len(literals) > maxRegexpLiterals {
+			return nil, false
+		}
+
+		results = append(results, literals...)
+		start = i + 1
+	}
+
+	return results, true
+}
+
+// branchLiterals returns every value matched by a single branch of a top
+// level alternation.
+func branchLiterals(branch []byte) ([][]byte, bool) {
+	literals := [][]byte{nil}
+	for i := 0; i < len(branch); {
+		var alternatives [][]byte
+		switch branch[i] {
+		case '(':
+			end := bytes.IndexByte(branch[i:], ')')
+			if end < 0 {
+				return nil, false
+			}
+
+			for _, alternative := range bytes.Split(branch[i+1:i+end], []byte("|")) {
+				if bytes.IndexAny(alternative, string(regexpMetaChars)) >= 0 {
+					return nil, false
+				}
+
+				alternatives = append(alternatives, alternative)
+			}
+
+			i += end + 1
+
+		case '[':
+			end := bytes.IndexByte(branch[i:], ']')
+			if end < 0 {
+				return nil, false
+			}
+
+			chars, ok := charClassLiterals(branch[i+1 : i+end])
+			if !ok {
+				return nil, false
+			}
+
+			alternatives = chars
+			i += end + 1
+
+		default:
+			end := bytes.IndexAny(branch[i:], string(regexpMetaChars))
+			if end == 0 {
+				return nil, false
+			}
+
+			if end < 0 {
+				end = len(branch) - i
+			}
+
+			alternatives = [][]byte{branch[i : i+end]}
+			i += end
+		}
+
+		if len(literals)*len(alternatives) > maxRegexpLiterals {
+			return nil, false
+		}
+
+		expanded := make([][]byte, 0, len(literals)*len(alternatives))
+		for _, literal := range literals {
+			for _, alternative := range alternatives {
+				value := make([]byte, 0, len(literal)+len(alternative))
+				value = append(value, literal...)
+				value = append(value, alternative...)
+				expanded = append(expanded, value)
+			}
+		}
+
+		literals = expanded
+	}
+
+	for _, literal := range literals {
+		if len(literal) == 0 {
+			return nil, false
+		}
+	}
+
+	return literals, true
+}
+
+// charClassLiterals returns the characters matched by the contents of a
+// character class made up of ASCII characters and ranges; negated classes,
+// escapes and named classes are not expanded.
+func charClassLiterals(class []byte) ([][]byte, bool) {
+	if len(class) == 0 || class[0] == '^' {
+		return nil, false
+	}
+
+	var chars [][]byte
+	for i := 0; i < len(class); i++ {
+		lo := class[i]
+		if lo == '\\' || lo == '[' || lo >= 0x80 {
+			return nil, false
+		}
+
+		hi := lo
+		if i+2 < len(class) && class[i+1] == '-' {
+			hi = class[i+2]
+			if hi == '\\' || hi == '[' || hi >= 0x80 || hi < lo {
+				return nil, false
+			}
+
+			i += 2
+		}
+
+		if len(chars)+int(hi-lo)+1 > maxRegexpLiterals {
+			return nil, false
+		}
+
+		for c := int(lo); c <= int(hi); c++ {
+			chars = append(chars, []byte{byte(c)})
+		}
+	}
+
+	return chars, true
+}
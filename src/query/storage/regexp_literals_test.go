@@ -0,0 +1,97 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexpLiterals(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "foo", expected: []string{"foo"}},
+		{pattern: "foo|bar", expected: []string{"foo", "bar"}},
+		{pattern: "(foo|bar)", expected: []string{"foo", "bar"}},
+		{pattern: "a(b|c)d", expected: []string{"abd", "acd"}},
+		{pattern: "a(b|c)(d|e)", expected: []string{"abd", "abe", "acd", "ace"}},
+		{pattern: "host[1-3]", expected: []string{"host1", "host2", "host3"}},
+		{pattern: "[ab-c]x", expected: []string{"ax", "bx", "cx"}},
+		{pattern: "[-a]", expected: []string{"-", "a"}},
+		{pattern: "x|(y|z)w", expected: []string{"x", "yw", "zw"}},
+		{pattern: "sérvice", expected: []string{"sérvice"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			literals, ok := regexpLiterals([]byte(test.pattern))
+			require.True(t, ok)
+
+			actual := make([]string, 0, len(literals))
+			for _, literal := range literals {
+				actual = append(actual, string(literal))
+			}
+
+			assert.Equal(t, test.expected, actual)
+
+			// Every literal must be matched by the anchored regexp.
+			re := regexp.MustCompile("^(?:" + test.pattern + ")$")
+			for _, literal := range actual {
+				assert.True(t, re.MatchString(literal), literal)
+			}
+		})
+	}
+}
+
+func TestRegexpLiteralsNotExpanded(t *testing.T) {
+	patterns := []string{
+		"",
+		".*",
+		"foo.*",
+		`foo\.bar`,
+		"foo[^a]",
+		"foo[[:alpha:]]",
+		"(a|(b|c))",
+		"(?i)foo",
+		"(a|)",
+		"a|",
+		"a{2}",
+		"^foo$",
+		"(a|b",
+		"a)",
+		"[b-a]",
+		"[]",
+		"[é]",
+		"[0-9][0-9][0-9][0-9]",
+		"x(" + strings.Repeat("a|", maxRegexpLiterals) + "a)",
+	}
+
+	for _, pattern := range patterns {
+		_, ok := regexpLiterals([]byte(pattern))
+		assert.False(t, ok, pattern)
+	}
+}
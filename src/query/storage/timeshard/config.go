@@ -0,0 +1,72 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package timeshard
+
+import (
+	"time"
+)
+
+const (
+	defaultBlocksPerShard = 12
+)
+
+// Configuration configures splitting long range fetches into time shards
+// which are fetched concurrently.
+type Configuration struct {
+	// Enabled enables time sharding.
+	Enabled bool `yaml:"enabled"`
+
+	// BlocksPerShard is the number of namespace blocks in a shard, shards
+	// are sized by the block size of the namespaces a fetch is served from.
+	BlocksPerShard *int `yaml:"blocksPerShard"`
+
+	// MinRange is the smallest range which is sharded, defaults to the
+	// duration of two shards.
+	MinRange *time.Duration `yaml:"minRange"`
+}
+
+// NewOptions returns the options for the configuration, or nil if time
+// sharding is not enabled.
+func (c Configuration) NewOptions() (*Options, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	blocksPerShard := defaultBlocksPerShard
+	if c.BlocksPerShard != nil {
+		blocksPerShard = *c.BlocksPerShard
+	}
+
+	var minRange time.Duration
+	if c.MinRange != nil {
+		minRange = *c.MinRange
+	}
+
+	opts := &Options{
+		BlocksPerShard: blocksPerShard,
+		MinRange:       minRange,
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package timeshard
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// MergeShards joins the series iterators fetched for each shard, in time
+// order, into a single iterator per series. The returned iterators take
+// ownership of the iterators of the shards.
+func MergeShards(shards []encoding.SeriesIterators) encoding.SeriesIterators {
+	var (
+		indexes = make(map[string]int)
+		grouped [][]encoding.SeriesIterator
+	)
+	for _, shard := range shards {
+		if shard == nil {
+			continue
+		}
+
+		for _, iter := range shard.Iters() {
+			id := iter.ID().String()
+			idx, ok := indexes[id]
+			if !ok {
+				idx = len(grouped)
+				indexes[id] = idx
+				grouped = append(grouped, nil)
+			}
+
+			grouped[idx] = append(grouped[idx], iter)
+		}
+	}
+
+	iters := make([]encoding.SeriesIterator, 0, len(grouped))
+	for _, shardIters := range grouped {
+		iters = append(iters, NewSeriesIterator(shardIters))
+	}
+
+	return encoding.NewSeriesIterators(iters, nil)
+}
+
+type seriesIterator struct {
+	encoding.SeriesIterator
+
+	iters []encoding.SeriesIterator
+	idx   int
+	err   error
+}
+
+// NewSeriesIterator returns an iterator over the datapoints of the iterators
+// of consecutive shards of a single series. The ID, namespace and tags are
+// those of the first iterator.
+func NewSeriesIterator(iters []encoding.SeriesIterator) encoding.SeriesIterator {
+	if len(iters) == 1 {
+		return iters[0]
+	}
+
+	return &seriesIterator{
+		SeriesIterator: iters[0],
+		iters:          iters,
+	}
+}
+
+func (it *seriesIterator) Next() bool {
+	for it.err == nil && it.idx < len(it.iters) {
+		iter := it.iters[it.idx]
+		if iter.Next() {
+			return true
+		}
+
+		it.err = iter.Err()
+		if it.err == nil {
+			it.idx++
+		}
+	}
+
+	return false
+}
+
+func (it *seriesIterator) Current() (ts.Datapoint, xtime.Unit, ts.Annotation) {
+	return it.iters[it.idx].Current()
+}
+
+func (it *seriesIterator) Err() error {
+	return it.err
+}
+
+func (it *seriesIterator) End() time.Time {
+	return it.iters[len(it.iters)-1].End()
+}
+
+func (it *seriesIterator) Reset(opts encoding.SeriesIteratorOptions) {
+	for _, iter := range it.iters[1:] {
+		iter.Close()
+	}
+
+	it.SeriesIterator.Reset(opts)
+	it.iters = it.iters[:1]
+	it.idx = 0
+	it.err = nil
+}
+
+func (it *seriesIterator) SetIterateEqualTimestampStrategy(
+	strategy encoding.IterateEqualTimestampStrategy,
+) {
+	for _, iter := range it.iters {
+		iter.SetIterateEqualTimestampStrategy(strategy)
+	}
+}
+
+func (it *seriesIterator) Replicas() []encoding.MultiReaderIterator {
+	var replicas []encoding.MultiReaderIterator
+	for _, iter := range it.iters {
+		replicas = append(replicas, iter.Replicas()...)
+	}
+
+	return replicas
+}
+
+func (it *seriesIterator) Close() {
+	for _, iter := range it.iters {
+		iter.Close()
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package timeshard
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestShardIterator(
+	t *testing.T,
+	id string,
+	start time.Time,
+	blockSize time.Duration,
+	values ...float64,
+) encoding.SeriesIterator {
+	encoder := m3tsz.NewEncoder(start, checked.NewBytes(nil, nil), true, encoding.NewOptions())
+	for i, v := range values {
+		dp := ts.Datapoint{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: v}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+
+	iterAlloc := func(r io.Reader) encoding.ReaderIterator {
+		return m3tsz.NewReaderIterator(r, m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions())
+	}
+
+	multiReader := encoding.NewMultiReaderIterator(iterAlloc, nil)
+	multiReader.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(
+		[][]xio.BlockReader{{{
+			SegmentReader: xio.NewSegmentReader(encoder.Discard()),
+			Start:         start,
+			BlockSize:     blockSize,
+		}}},
+	))
+
+	return encoding.NewSeriesIterator(encoding.SeriesIteratorOptions{
+		ID:             ident.StringID(id),
+		Namespace:      ident.StringID("ns"),
+		Tags:           ident.NewTagsIterator(ident.NewTags(ident.StringTag("id", id))),
+		StartInclusive: start,
+		EndExclusive:   start.Add(blockSize),
+		Replicas:       []encoding.MultiReaderIterator{multiReader},
+	}, nil)
+}
+
+func readValues(t *testing.T, iter encoding.SeriesIterator) []float64 {
+	var values []float64
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		values = append(values, dp.Value)
+	}
+
+	require.NoError(t, iter.Err())
+	return values
+}
+
+func TestMergeShards(t *testing.T) {
+	var (
+		start     = time.Unix(0, 0)
+		blockSize = time.Hour
+		next      = start.Add(blockSize)
+	)
+
+	shards := []encoding.SeriesIterators{
+		encoding.NewSeriesIterators([]encoding.SeriesIterator{
+			newTestShardIterator(t, "a", start, blockSize, 1, 2),
+			newTestShardIterator(t, "b", start, blockSize, 10),
+		}, nil),
+		encoding.NewSeriesIterators([]encoding.SeriesIterator{
+			newTestShardIterator(t, "c", next, blockSize, 100),
+			newTestShardIterator(t, "a", next, blockSize, 3, 4),
+		}, nil),
+	}
+
+	merged := MergeShards(shards)
+	defer merged.Close()
+
+	iters := merged.Iters()
+	require.Len(t, iters, 3)
+
+	assert.Equal(t, "a", iters[0].ID().String())
+	assert.Equal(t, start, iters[0].Start())
+	assert.Equal(t, next.Add(blockSize), iters[0].End())
+	assert.Len(t, iters[0].Replicas(), 2)
+	assert.Equal(t, []float64{1, 2, 3, 4}, readValues(t, iters[0]))
+
+	assert.Equal(t, "b", iters[1].ID().String())
+	assert.Equal(t, []float64{10}, readValues(t, iters[1]))
+
+	assert.Equal(t, "c", iters[2].ID().String())
+	assert.Equal(t, next, iters[2].Start())
+	assert.Equal(t, []float64{100}, readValues(t, iters[2]))
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package timeshard splits fetches over long ranges into time shards which
+// are fetched concurrently, the series fetched for each shard are joined back
+// together in time order without being decoded.
+package timeshard
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errInvalidBlocksPerShard = errors.New("time shard blocks per shard must be positive")
+	errInvalidMinRange       = errors.New("time shard min range must not be negative")
+)
+
+// Options are the options for splitting fetches into time shards.
+type Options struct {
+	// BlocksPerShard is the number of namespace blocks in a shard.
+	BlocksPerShard int
+	// MinRange is the smallest range which is split into shards, when zero
+	// it is the duration of two shards.
+	MinRange time.Duration
+}
+
+// Validate validates the options.
+func (o Options) Validate() error {
+	if o.BlocksPerShard <= 0 {
+		return errInvalidBlocksPerShard
+	}
+	if o.MinRange < 0 {
+		return errInvalidMinRange
+	}
+	return nil
+}
+
+// Shard is the time range of a shard, the end is exclusive.
+type Shard struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Shards returns the shards a fetch over the given range of namespaces with
+// the given block size is split into, shard boundaries are aligned to the
+// shard duration so that shards do not split namespace blocks. Returns nil if
+// the range should not be sharded.
+func (o Options) Shards(start, end time.Time, blockSize time.Duration) []Shard {
+	shardDuration := time.Duration(o.BlocksPerShard) * blockSize
+	if shardDuration <= 0 {
+		return nil
+	}
+
+	minRange := o.MinRange
+	if minRange == 0 {
+		minRange = 2 * shardDuration
+	}
+	if end.Sub(start) < minRange {
+		return nil
+	}
+
+	var shards []Shard
+	boundary := start.Truncate(shardDuration).Add(shardDuration)
+	for start.Before(end) {
+		next := boundary
+		if next.After(end) {
+			next = end
+		}
+
+		shards = append(shards, Shard{Start: start, End: next})
+		start = next
+		boundary = boundary.Add(shardDuration)
+	}
+
+	if len(shards) < 2 {
+		return nil
+	}
+
+	return shards
+}
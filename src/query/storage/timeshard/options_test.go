@@ -0,0 +1,81 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package timeshard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	assert.Error(t, Options{}.Validate())
+	assert.Error(t, Options{BlocksPerShard: 2, MinRange: -time.Hour}.Validate())
+	assert.NoError(t, Options{BlocksPerShard: 2}.Validate())
+}
+
+func TestOptionsShards(t *testing.T) {
+	opts := Options{
+		BlocksPerShard: 2,
+		MinRange:       3 * time.Hour,
+	}
+
+	start := time.Unix(0, 0).Add(30 * time.Minute)
+	assert.Nil(t, opts.Shards(start, start.Add(2*time.Hour), time.Hour))
+
+	end := start.Add(5 * time.Hour)
+	shards := opts.Shards(start, end, time.Hour)
+	require.Len(t, shards, 3)
+	assert.Equal(t, Shard{Start: start, End: time.Unix(0, 0).Add(2 * time.Hour)}, shards[0])
+	assert.Equal(t, Shard{
+		Start: time.Unix(0, 0).Add(2 * time.Hour),
+		End:   time.Unix(0, 0).Add(4 * time.Hour),
+	}, shards[1])
+	assert.Equal(t, Shard{Start: time.Unix(0, 0).Add(4 * time.Hour), End: end}, shards[2])
+}
+
+func TestOptionsShardsWithinSingleShard(t *testing.T) {
+	opts := Options{
+		BlocksPerShard: 24,
+		MinRange:       time.Hour,
+	}
+
+	start := time.Unix(0, 0)
+	assert.Nil(t, opts.Shards(start, start.Add(12*time.Hour), time.Hour))
+}
+
+func TestOptionsShardsSizedByBlockSize(t *testing.T) {
+	opts := Options{BlocksPerShard: 2}
+
+	// Defaults to splitting ranges of at least two shards
+	start := time.Unix(0, 0)
+	assert.Nil(t, opts.Shards(start, start.Add(3*time.Hour), time.Hour))
+	assert.Len(t, opts.Shards(start, start.Add(4*time.Hour), time.Hour), 2)
+
+	// Larger namespace blocks make for larger shards
+	shards := opts.Shards(start, start.Add(24*time.Hour), 4*time.Hour)
+	require.Len(t, shards, 3)
+	for _, shard := range shards {
+		assert.Equal(t, 8*time.Hour, shard.End.Sub(shard.Start))
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/cost"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage/timeshard"
 	"github.com/m3db/m3/src/query/ts"
 	xtime "github.com/m3db/m3x/time"
 
@@ -94,6 +95,13 @@ type FetchOptions struct {
 	Enforcer cost.ChainedEnforcer
 	// Scope is used to report metrics about the fetch.
 	Scope tally.Scope
+	// MaxResolution is the coarsest resolution the results are needed at, if
+	// set series are read from the coarsest namespace with a resolution no
+	// coarser than it rather than the most granular namespace.
+	MaxResolution time.Duration
+	// TimeSharding if set splits long range fetches into time shards which
+	// are fetched concurrently.
+	TimeSharding *timeshard.Options
 }
 
 // FanoutOptions describes which namespaces should be fanned out to for
@@ -0,0 +1,85 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/models"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// WrittenDatapoint is a datapoint written to a recording
+// DownsamplerAndWriter.
+type WrittenDatapoint struct {
+	ID        string
+	Timestamp time.Time
+	Value     float64
+	Unit      xtime.Unit
+}
+
+// NewRecordingDownsamplerAndWriter returns a mock DownsamplerAndWriter which
+// records the datapoints of the batches written to it, each series in a batch
+// must have a single datapoint.
+func NewRecordingDownsamplerAndWriter(
+	t *testing.T,
+	ctrl *gomock.Controller,
+) (*ingest.MockDownsamplerAndWriter, *[]WrittenDatapoint) {
+	var written []WrittenDatapoint
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().
+		WriteBatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, iter ingest.DownsampleAndWriteIter) error {
+			for iter.Next() {
+				tags, dps, unit := iter.Current()
+				require.Len(t, dps, 1)
+				written = append(written, WrittenDatapoint{
+					ID:        string(tags.ID()),
+					Timestamp: dps[0].Timestamp,
+					Value:     dps[0].Value,
+					Unit:      unit,
+				})
+			}
+			return iter.Error()
+		}).
+		AnyTimes()
+
+	return mockDownsamplerAndWriter, &written
+}
+
+// SeriesID returns the ID of the series with the given tag name and value
+// pairs, with the default tag options.
+func SeriesID(tags ...string) string {
+	modelTags := models.NewTags(len(tags)/2, models.NewTagOptions())
+	for i := 0; i < len(tags); i += 2 {
+		modelTags = modelTags.AddTag(models.Tag{
+			Name:  []byte(tags[i]),
+			Value: []byte(tags[i+1]),
+		})
+	}
+	return string(modelTags.ID())
+}
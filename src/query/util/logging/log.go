@@ -221,6 +221,12 @@ func (w *responseWrittenResponseWriter) WriteHeader(statusCode int) {
 	w.writer.WriteHeader(statusCode)
 }
 
+func (w *responseWrittenResponseWriter) Flush() {
+	if flusher, ok := w.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // WithResponseTimeAndPanicErrorLogging wraps around the given handler,
 // providing panic recovery and response time logging.
 func WithResponseTimeAndPanicErrorLogging(next http.Handler) http.Handler {